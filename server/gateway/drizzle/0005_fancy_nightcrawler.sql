@@ -0,0 +1,5 @@
+ALTER TABLE "users" DROP CONSTRAINT "users_email_unique";--> statement-breakpoint
+ALTER TABLE "users" DROP CONSTRAINT "users_username_unique";--> statement-breakpoint
+ALTER TABLE "users" ADD COLUMN "tenant_id" varchar(64) DEFAULT 'default' NOT NULL;--> statement-breakpoint
+ALTER TABLE "users" ADD CONSTRAINT "users_tenant_email_unique" UNIQUE("tenant_id","email");--> statement-breakpoint
+ALTER TABLE "users" ADD CONSTRAINT "users_tenant_username_unique" UNIQUE("tenant_id","username");
\ No newline at end of file
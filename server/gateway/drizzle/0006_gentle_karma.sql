@@ -0,0 +1 @@
+ALTER TABLE "users" ADD COLUMN "username_changed_at" timestamp;
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX "idx_users_tenant_created_id" ON "users" USING btree ("tenant_id","created_at","id");
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE "users" ADD COLUMN "last_login_ip" varchar(45);--> statement-breakpoint
+ALTER TABLE "users" ADD COLUMN "last_login_user_agent" text;
\ No newline at end of file
@@ -0,0 +1 @@
+ALTER TABLE "users" ADD COLUMN "must_reset_password" boolean DEFAULT false NOT NULL;
\ No newline at end of file
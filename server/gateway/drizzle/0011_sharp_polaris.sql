@@ -0,0 +1 @@
+ALTER TABLE "users" ADD COLUMN "locale" varchar(35);
\ No newline at end of file
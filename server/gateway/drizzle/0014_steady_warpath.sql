@@ -0,0 +1,2 @@
+ALTER TABLE "users" ADD COLUMN "scheduled_deletion_at" timestamp;--> statement-breakpoint
+CREATE INDEX "idx_users_scheduled_deletion_at" ON "users" USING btree ("scheduled_deletion_at") WHERE "users"."scheduled_deletion_at" IS NOT NULL;
\ No newline at end of file
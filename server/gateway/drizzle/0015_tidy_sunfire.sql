@@ -0,0 +1,2 @@
+CREATE INDEX "idx_sessions_expires_at" ON "sessions" USING btree ("expires_at");--> statement-breakpoint
+CREATE INDEX "idx_access_tokens_expires_at" ON "access_tokens" USING btree ("expires_at");
\ No newline at end of file
@@ -0,0 +1 @@
+CREATE INDEX "idx_users_tenant_lower_username" ON "users" USING btree ("tenant_id",lower("username"));
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE "sessions" ADD COLUMN "last_seen_at" timestamp DEFAULT now();--> statement-breakpoint
+CREATE INDEX "idx_sessions_last_seen_at" ON "sessions" USING btree ("last_seen_at");
\ No newline at end of file
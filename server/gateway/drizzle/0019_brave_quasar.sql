@@ -0,0 +1,2 @@
+CREATE INDEX "idx_users_tenant_last_login_id" ON "users" USING btree ("tenant_id",COALESCE("last_login", 'epoch'::timestamp),"id");--> statement-breakpoint
+CREATE INDEX "idx_users_tenant_username_id" ON "users" USING btree ("tenant_id","username" COLLATE "C","id");
\ No newline at end of file
@@ -1,3 +1,4 @@
+import { sql } from 'drizzle-orm';
 import {
   pgTable,
   uuid,
@@ -8,6 +9,8 @@ import {
   jsonb,
   integer,
   primaryKey,
+  unique,
+  index,
 } from 'drizzle-orm/pg-core';
 
 // ========================================================
@@ -24,25 +27,130 @@ export const roles = pgTable('roles', {
 });
 
 // Bảng Users: Tài khoản hệ thống
-export const users = pgTable('users', {
-  id: uuid('id').defaultRandom().primaryKey(),
-  roleId: uuid('role_id')
-    .references(() => roles.id)
-    .notNull(), // Link sang bảng Roles
+export const users = pgTable(
+  'users',
+  {
+    id: uuid('id').defaultRandom().primaryKey(),
+    roleId: uuid('role_id')
+      .references(() => roles.id)
+      .notNull(), // Link sang bảng Roles
 
-  email: varchar('email', { length: 255 }).notNull().unique(),
-  username: varchar('username', { length: 50 }).notNull().unique(),
-  password: text('password').notNull(), // Hash bcrypt
+    email: varchar('email', { length: 255 }).notNull(),
+    username: varchar('username', { length: 50 }).notNull(),
+    password: text('password').notNull(), // Hash bcrypt
 
-  fullName: text('full_name').notNull(),
-  phone: varchar('phone', { length: 20 }),
-  avatar: text('avatar'), // URL ảnh đại diện
+    fullName: text('full_name').notNull(),
+    phone: varchar('phone', { length: 20 }),
+    avatar: text('avatar'), // URL ảnh đại diện
 
-  isActive: boolean('is_active').default(true),
-  lastLogin: timestamp('last_login'),
+    isActive: boolean('is_active').default(true),
+    lastLogin: timestamp('last_login'),
 
+    createdAt: timestamp('created_at').defaultNow(),
+    updatedAt: timestamp('updated_at').defaultNow(),
+    passwordChangedAt: timestamp('password_changed_at').defaultNow(), // Dùng cho chính sách hết hạn mật khẩu
+    tenantId: varchar('tenant_id', { length: 64 }).notNull().default('default'), // Tenant sở hữu tài khoản (chế độ multi-tenant)
+    usernameChangedAt: timestamp('username_changed_at'), // Lần đổi username gần nhất, dùng cho thời gian chờ giữa hai lần đổi
+    tokenVersion: integer('token_version').notNull().default(0), // Tăng lên để vô hiệu hóa mọi access token đã cấp
+    lastLoginIp: varchar('last_login_ip', { length: 45 }), // Địa chỉ IP của lần đăng nhập gần nhất
+    lastLoginUserAgent: text('last_login_user_agent'), // User agent (thiết bị/trình duyệt) của lần đăng nhập gần nhất
+    mustResetPassword: boolean('must_reset_password').notNull().default(false), // Admin bắt buộc đặt lại mật khẩu (tài khoản bị lộ), chặn đăng nhập cho đến khi đặt lại
+    locale: varchar('locale', { length: 35 }), // Ngôn ngữ ưa thích (thẻ BCP 47, vd: vi, en-US) dùng cho email và thông báo
+    scheduledDeletionAt: timestamp('scheduled_deletion_at'), // Thời điểm xóa vĩnh viễn tài khoản theo yêu cầu người dùng, có thể hủy trước thời điểm này
+  },
+  (t) => ({
+    // Email/username chỉ cần duy nhất trong phạm vi một tenant
+    tenantEmail: unique('users_tenant_email_unique').on(t.tenantId, t.email),
+    tenantUsername: unique('users_tenant_username_unique').on(t.tenantId, t.username),
+    // Tra cứu username không phân biệt hoa thường (AUTH_USERNAME_CASE=lower)
+    tenantLowerUsername: index('idx_users_tenant_lower_username').on(t.tenantId, sql`lower(${t.username})`),
+    // Lọc theo tenant và phân trang keyset của ListUsers
+    tenantCreatedId: index('idx_users_tenant_created_id').on(t.tenantId, t.createdAt, t.id),
+    // Phân trang keyset của ListUsers theo lần đăng nhập gần nhất (chưa đăng nhập xếp như epoch)
+    tenantLastLoginId: index('idx_users_tenant_last_login_id').on(
+      t.tenantId,
+      sql`COALESCE(${t.lastLogin}, 'epoch'::timestamp)`,
+      t.id,
+    ),
+    // Phân trang keyset của ListUsers theo username, so sánh theo byte
+    tenantUsernameId: index('idx_users_tenant_username_id').on(t.tenantId, sql`${t.username} COLLATE "C"`, t.id),
+    // Tìm tài khoản đến hạn xóa vĩnh viễn
+    scheduledDeletionAt: index('idx_users_scheduled_deletion_at')
+      .on(t.scheduledDeletionAt)
+      .where(sql`${t.scheduledDeletionAt} IS NOT NULL`),
+  }),
+);
+
+// Bảng UserRoles: Vai trò bổ sung của user (ngoài role chính users.role_id)
+export const userRoles = pgTable(
+  'user_roles',
+  {
+    userId: uuid('user_id')
+      .references(() => users.id)
+      .notNull(),
+    roleId: uuid('role_id')
+      .references(() => roles.id)
+      .notNull(),
+    createdAt: timestamp('created_at').defaultNow(),
+  },
+  (t) => ({
+    pk: primaryKey({ columns: [t.userId, t.roleId] }),
+    roleId: index('idx_user_roles_role_id').on(t.roleId),
+  }),
+);
+
+// Bảng Sessions: Phiên đăng nhập (mỗi refresh token family), nonce được xoay vòng khi refresh
+export const sessions = pgTable(
+  'sessions',
+  {
+    id: uuid('id').defaultRandom().primaryKey(),
+    userId: uuid('user_id')
+      .references(() => users.id)
+      .notNull(),
+    refreshNonce: varchar('refresh_nonce', { length: 64 }).notNull(),
+    expiresAt: timestamp('expires_at').notNull(),
+    revokedAt: timestamp('revoked_at'),
+    createdAt: timestamp('created_at').defaultNow(),
+    updatedAt: timestamp('updated_at').defaultNow(),
+    lastSeenAt: timestamp('last_seen_at').defaultNow(), // Lần đăng nhập/refresh/xác thực access token gần nhất, dùng cho AUTH_SESSION_IDLE_TIMEOUT
+  },
+  (t) => ({
+    userId: index('idx_sessions_user_id').on(t.userId),
+    // Dọn dẹp phiên đã hết hạn
+    expiresAt: index('idx_sessions_expires_at').on(t.expiresAt),
+    // Dọn dẹp phiên không hoạt động quá AUTH_SESSION_IDLE_TIMEOUT
+    lastSeenAt: index('idx_sessions_last_seen_at').on(t.lastSeenAt),
+  }),
+);
+
+// Bảng Access Tokens: Claims của opaque access token (AUTH_TOKEN_TYPE=opaque), khóa là hash SHA-256 của token
+export const accessTokens = pgTable(
+  'access_tokens',
+  {
+    tokenHash: varchar('token_hash', { length: 64 }).primaryKey(),
+    userId: uuid('user_id')
+      .references(() => users.id, { onDelete: 'cascade' })
+      .notNull(),
+    claims: jsonb('claims').notNull(),
+    expiresAt: timestamp('expires_at').notNull(),
+    createdAt: timestamp('created_at').defaultNow(),
+  },
+  (t) => ({
+    userId: index('idx_access_tokens_user_id').on(t.userId),
+    // Dọn dẹp access token đã hết hạn
+    expiresAt: index('idx_access_tokens_expires_at').on(t.expiresAt),
+  }),
+);
+
+// Bảng Password Reset Codes: Mã OTP đặt lại mật khẩu đang chờ của user (AUTH_RESET_TOKEN_TYPE=otp), chỉ lưu HMAC của mã
+export const passwordResetCodes = pgTable('password_reset_codes', {
+  userId: uuid('user_id')
+    .references(() => users.id, { onDelete: 'cascade' })
+    .primaryKey(),
+  codeHash: varchar('code_hash', { length: 64 }).notNull(),
+  attempts: integer('attempts').notNull().default(0),
+  expiresAt: timestamp('expires_at').notNull(),
   createdAt: timestamp('created_at').defaultNow(),
-  updatedAt: timestamp('updated_at').defaultNow(),
 });
 
 // Bảng Resources: Danh sách tài nguyên (để phân quyền động)
@@ -91,7 +199,7 @@ export const classes = pgTable('classes', {
   code: varchar('code', { length: 50 }).notNull().unique(), // Mã lớp: INT3306_1
   name: varchar('name', { length: 200 }).notNull(),
   semester: varchar('semester', { length: 20 }), // Học kỳ: 2024_1
-  lecturerId: uuid('lecturer_id').references(() => users.id), // Giảng viên phụ trách
+  lecturerId: uuid('lecturer_id').references(() => users.id, { onDelete: 'set null' }), // Giảng viên phụ trách, bỏ trống khi tài khoản bị xóa
   isActive: boolean('is_active').default(true),
   createdAt: timestamp('created_at').defaultNow(),
 });
@@ -103,7 +211,7 @@ export const enrollments = pgTable('enrollments', {
     .references(() => classes.id)
     .notNull(),
   studentId: uuid('student_id')
-    .references(() => users.id)
+    .references(() => users.id, { onDelete: 'cascade' })
     .notNull(),
   joinedAt: timestamp('joined_at').defaultNow(),
 });
@@ -124,7 +232,7 @@ export const problems = pgTable('problems', {
   initSchemaSql: text('init_schema_sql').notNull(), // Script tạo bảng mẫu cho câu hỏi này
   correctQuery: text('correct_query').notNull(), // Câu SQL đáp án
 
-  createdBy: uuid('created_by').references(() => users.id),
+  createdBy: uuid('created_by').references(() => users.id, { onDelete: 'set null' }), // Bỏ trống khi tài khoản bị xóa, câu hỏi vẫn được giữ
   createdAt: timestamp('created_at').defaultNow(),
 });
 
@@ -162,7 +270,7 @@ export const assignmentProblems = pgTable(
 export const submissions = pgTable('submissions', {
   id: uuid('id').defaultRandom().primaryKey(),
   userId: uuid('user_id')
-    .references(() => users.id)
+    .references(() => users.id, { onDelete: 'cascade' })
     .notNull(),
   problemId: uuid('problem_id')
     .references(() => problems.id)
@@ -3,9 +3,16 @@ package main
 import (
 	"go.uber.org/fx"
 
+	"worker/internal/adapter/captcha"
+	"worker/internal/adapter/claims"
 	grpcadapter "worker/internal/adapter/grpc"
+	"worker/internal/adapter/hasher"
+	"worker/internal/adapter/httpserver"
 	"worker/internal/adapter/logger"
+	"worker/internal/adapter/notifier"
+	"worker/internal/adapter/storage/memory"
 	"worker/internal/adapter/storage/postgres"
+	"worker/internal/adapter/storage/s3"
 	"worker/internal/config"
 	"worker/internal/core/services"
 )
@@ -17,12 +24,35 @@ func main() {
 		logger.Module,
 
 		// Storage modules (adapters)
-		postgres.Module,
+		storageModule(),
+		s3.Module,
+		memory.Module,
+
+		// Security integrations (adapters)
+		captcha.Module,
+		hasher.Module,
+
+		// Account event notifications (adapters)
+		notifier.Module,
+
+		// Access token custom claims (adapters)
+		claims.Module,
 
 		// Core business logic
 		services.Module,
 
 		// Transport layer (gRPC)
 		grpcadapter.Module,
+
+		// Transport layer (HTTP)
+		httpserver.Module,
 	).Run()
 }
+
+// storageModule returns the repositories of STORAGE_BACKEND: PostgreSQL, or the in-memory store for tests and demos
+func storageModule() fx.Option {
+	if config.StorageBackend() == config.StorageBackendMemory {
+		return memory.StorageModule
+	}
+	return postgres.Module
+}
@@ -0,0 +1,23 @@
+package captcha
+
+import (
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/config"
+	"worker/internal/core/ports"
+)
+
+// Module provides CAPTCHA verification dependencies
+var Module = fx.Module("captcha",
+	fx.Provide(NewVerifier),
+)
+
+// NewVerifier returns a verifier for the configured provider, or a no-op verifier when CAPTCHA is disabled
+func NewVerifier(cfg *config.CaptchaConfig, logger *zap.Logger) ports.CaptchaVerifier {
+	if !cfg.Enabled() {
+		return NoopVerifier{}
+	}
+	logger.Info("✅ CAPTCHA verification enabled", zap.String("provider", cfg.Provider))
+	return NewSiteVerifier(cfg)
+}
@@ -0,0 +1,89 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure SiteVerifier implements ports.CaptchaVerifier
+var _ ports.CaptchaVerifier = (*SiteVerifier)(nil)
+
+// siteverifyURLs are the verification endpoints of the supported providers
+var siteverifyURLs = map[string]string{
+	config.CaptchaProviderRecaptcha: "https://www.google.com/recaptcha/api/siteverify",
+	config.CaptchaProviderHcaptcha:  "https://api.hcaptcha.com/siteverify",
+}
+
+// maxResponseSize bounds the siteverify response read from the provider
+const maxResponseSize = 64 * 1024
+
+// SiteVerifier implements ports.CaptchaVerifier with the siteverify API shared by reCAPTCHA and hCaptcha
+type SiteVerifier struct {
+	endpoint   string
+	secret     string
+	httpClient *http.Client
+}
+
+// NewSiteVerifier creates a verifier for the configured provider
+func NewSiteVerifier(cfg *config.CaptchaConfig) *SiteVerifier {
+	return &SiteVerifier{
+		endpoint:   siteverifyURLs[cfg.Provider],
+		secret:     cfg.Secret,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// siteverifyResponse is the part of the provider's answer the worker uses
+type siteverifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+}
+
+// Verify asks the provider whether the token was solved
+// remoteIP is optional and only forwarded as a hint to the provider
+func (v *SiteVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	if token == "" {
+		return domain.ErrCaptchaInvalid
+	}
+
+	form := url.Values{}
+	form.Set("secret", v.secret)
+	form.Set("response", token)
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("captcha siteverify: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("captcha siteverify: unexpected status %d", resp.StatusCode)
+	}
+
+	var result siteverifyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseSize)).Decode(&result); err != nil {
+		return fmt.Errorf("captcha siteverify: decode response: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("%w: %s", domain.ErrCaptchaInvalid, strings.Join(result.ErrorCodes, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// newTestVerifier returns a SiteVerifier whose siteverify endpoint is answered by handler
+func newTestVerifier(t *testing.T, handler http.HandlerFunc) *SiteVerifier {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	v := NewSiteVerifier(&config.CaptchaConfig{Provider: config.CaptchaProviderHcaptcha, Secret: "test-secret", Timeout: time.Second})
+	v.endpoint = server.URL
+	return v
+}
+
+func TestSiteVerifierRequest(t *testing.T) {
+	var form map[string]string
+	v := newTestVerifier(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse form: %v", err)
+		}
+		form = map[string]string{}
+		for key := range r.PostForm {
+			form[key] = r.PostForm.Get(key)
+		}
+		w.Write([]byte(`{"success": true}`))
+	})
+
+	if err := v.Verify(context.Background(), "solved", "10.0.0.1"); err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if form["secret"] != "test-secret" || form["response"] != "solved" || form["remoteip"] != "10.0.0.1" {
+		t.Fatalf("siteverify form = %v", form)
+	}
+
+	// The client IP is only a hint, it is left out when unknown
+	if err := v.Verify(context.Background(), "solved", ""); err != nil {
+		t.Fatalf("verify without an IP: %v", err)
+	}
+	if _, ok := form["remoteip"]; ok {
+		t.Fatalf("siteverify form = %v, want no remoteip", form)
+	}
+}
+
+func TestSiteVerifierRejects(t *testing.T) {
+	v := newTestVerifier(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"success": false, "error-codes": ["invalid-input-response"]}`))
+	})
+
+	err := v.Verify(context.Background(), "unsolved", "")
+	if !errors.Is(err, domain.ErrCaptchaInvalid) {
+		t.Fatalf("verify = %v, want ErrCaptchaInvalid", err)
+	}
+}
+
+func TestSiteVerifierMissingToken(t *testing.T) {
+	called := false
+	v := newTestVerifier(t, func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.Write([]byte(`{"success": true}`))
+	})
+
+	if err := v.Verify(context.Background(), "", ""); !errors.Is(err, domain.ErrCaptchaInvalid) {
+		t.Fatalf("verify = %v, want ErrCaptchaInvalid", err)
+	}
+	if called {
+		t.Fatal("a missing token was sent to the provider")
+	}
+}
+
+func TestSiteVerifierProviderFailure(t *testing.T) {
+	// A provider that cannot answer is not the client's fault, so it is not reported as an invalid token
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{name: "error status", handler: func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}},
+		{name: "malformed response", handler: func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`<html>`))
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := newTestVerifier(t, tt.handler)
+			err := v.Verify(context.Background(), "solved", "")
+			if err == nil || errors.Is(err, domain.ErrCaptchaInvalid) {
+				t.Fatalf("verify = %v, want a provider error", err)
+			}
+		})
+	}
+}
+
+func TestSiteVerifierTimeout(t *testing.T) {
+	release := make(chan struct{})
+	v := newTestVerifier(t, func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	})
+	t.Cleanup(func() { close(release) }) // Runs before the server's cleanup, which waits for its handlers
+	v.httpClient.Timeout = 50 * time.Millisecond
+
+	err := v.Verify(context.Background(), "solved", "")
+	if err == nil || errors.Is(err, domain.ErrCaptchaInvalid) {
+		t.Fatalf("verify = %v, want a timeout error", err)
+	}
+}
+
+func TestNewVerifier(t *testing.T) {
+	if _, ok := NewVerifier(&config.CaptchaConfig{}, zap.NewNop()).(NoopVerifier); !ok {
+		t.Fatal("disabled CAPTCHA does not use the no-op verifier")
+	}
+	if err := (NoopVerifier{}).Verify(context.Background(), "", ""); err != nil {
+		t.Fatalf("no-op verify: %v", err)
+	}
+
+	cfg := &config.CaptchaConfig{Provider: config.CaptchaProviderRecaptcha, Secret: "test-secret", Timeout: time.Second}
+	v, ok := NewVerifier(cfg, zap.NewNop()).(*SiteVerifier)
+	if !ok || v.endpoint != siteverifyURLs[config.CaptchaProviderRecaptcha] {
+		t.Fatalf("recaptcha verifier = %#v", v)
+	}
+}
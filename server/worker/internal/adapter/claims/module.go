@@ -0,0 +1,18 @@
+package claims
+
+import (
+	"go.uber.org/fx"
+
+	"worker/internal/core/ports"
+)
+
+// Module provides the access token claims enricher
+// Integrators adding their own claims replace this module with one providing their ports.ClaimsEnricher
+var Module = fx.Module("claims",
+	fx.Provide(NewEnricher),
+)
+
+// NewEnricher returns the no-op enricher
+func NewEnricher() ports.ClaimsEnricher {
+	return NoopEnricher{}
+}
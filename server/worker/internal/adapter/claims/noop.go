@@ -0,0 +1,24 @@
+package claims
+
+import (
+	"context"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/ports"
+)
+
+// Ensure NoopEnricher implements ports.ClaimsEnricher
+var _ ports.ClaimsEnricher = NoopEnricher{}
+
+// NoopEnricher adds no custom claims, used unless an integrator provides an enricher
+type NoopEnricher struct{}
+
+// Enrich returns no claims
+func (NoopEnricher) Enrich(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow, roles []string) (map[string]any, error) {
+	return nil, nil
+}
+
+// Surface reports custom claims as they are
+func (NoopEnricher) Surface(ctx context.Context, claims map[string]any) map[string]any {
+	return claims
+}
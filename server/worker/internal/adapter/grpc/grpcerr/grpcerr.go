@@ -0,0 +1,44 @@
+// Package grpcerr builds gRPC status errors carrying a machine-readable reason.
+//
+// Every status gets a google.rpc.ErrorInfo detail whose Reason is the stable domain
+// error code (e.g. USER_NOT_FOUND), so clients can switch on it instead of parsing
+// messages. Errors about a single request field also carry a google.rpc.BadRequest.
+package grpcerr
+
+import (
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Domain identifies this service in ErrorInfo details
+const Domain = "worker-auth-service"
+
+// New creates a status error with an ErrorInfo detail, plus a BadRequest field violation when field is set
+func New(code codes.Code, reason, message, field string) error {
+	st := status.New(code, message)
+
+	info := &errdetails.ErrorInfo{
+		Reason: reason,
+		Domain: Domain,
+	}
+	if field != "" {
+		info.Metadata = map[string]string{"field": field}
+	}
+
+	var withDetails *status.Status
+	var err error
+	if field == "" {
+		withDetails, err = st.WithDetails(info)
+	} else {
+		withDetails, err = st.WithDetails(info, &errdetails.BadRequest{
+			FieldViolations: []*errdetails.BadRequest_FieldViolation{
+				{Field: field, Description: message},
+			},
+		})
+	}
+	if err != nil {
+		return st.Err()
+	}
+	return withDetails.Err()
+}
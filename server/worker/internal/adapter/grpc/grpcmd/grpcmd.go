@@ -0,0 +1,168 @@
+// Package grpcmd reads the incoming gRPC metadata the worker relies on (forwarded client details,
+// correlation ID, tenant, API version and bearer token) through typed accessors, so interceptors and
+// handlers do not each re-parse metadata.MD.
+//
+// Values are trusted as is: the worker is only reachable through the gateway.
+package grpcmd
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"worker/internal/common/correlation"
+)
+
+// Metadata keys the gateway sets
+const (
+	ForwardedForMetadataKey       = "x-forwarded-for"
+	ForwardedUserAgentMetadataKey = "x-forwarded-user-agent"
+	ClientCountryMetadataKey      = "x-client-country" // ISO 3166-1 alpha-2, e.g. from a CDN's geo-IP header
+	ForwardedLanguageMetadataKey  = "x-forwarded-accept-language"
+	TenantMetadataKey             = "x-tenant-id"
+	APIVersionMetadataKey         = "x-api-version" // API version the client was built against
+	AuthorizationMetadataKey      = "authorization"
+)
+
+// maxUserAgentLength bounds the stored user agent so callers cannot bloat the users table
+const maxUserAgentLength = 512
+
+// maxAcceptLanguageLength bounds the accept-language value handed to the locale matcher
+const maxAcceptLanguageLength = 256
+
+// Request is the metadata and peer of one incoming call
+// The zero value is a call without metadata; accessors never fail, missing or malformed values read as ""
+type Request struct {
+	md   metadata.MD
+	peer *peer.Peer
+}
+
+type contextKey struct{}
+
+// WithRequest returns a copy of ctx carrying the request's metadata, read once by the Metadata interceptor
+func WithRequest(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, newRequest(ctx))
+}
+
+// FromContext returns the request stored by WithRequest, or reads it from ctx when none was stored
+func FromContext(ctx context.Context) *Request {
+	if r, ok := ctx.Value(contextKey{}).(*Request); ok {
+		return r
+	}
+	return newRequest(ctx)
+}
+
+func newRequest(ctx context.Context) *Request {
+	md, _ := metadata.FromIncomingContext(ctx)
+	p, _ := peer.FromContext(ctx)
+	return &Request{md: md, peer: p}
+}
+
+// First returns the first non-empty, trimmed value of the given keys
+func (r *Request) First(keys ...string) string {
+	for _, key := range keys {
+		for _, value := range r.md.Get(key) {
+			if value = strings.TrimSpace(value); value != "" {
+				return value
+			}
+		}
+	}
+	return ""
+}
+
+// Single returns the value of a key that may be sent at most once
+// ok is false when the key is absent; a repeated key is an error naming it
+func (r *Request) Single(key string) (value string, ok bool, err error) {
+	values := r.md.Get(key)
+	switch len(values) {
+	case 0:
+		return "", false, nil
+	case 1:
+		return values[0], true, nil
+	}
+	return "", true, fmt.Errorf("%s must be sent once, got %d values", key, len(values))
+}
+
+// ClientIP returns the original client of x-forwarded-for (its first entry), otherwise the peer address
+func (r *Request) ClientIP() string {
+	first, _, _ := strings.Cut(r.First(ForwardedForMetadataKey), ",")
+	if ip := net.ParseIP(strings.TrimSpace(first)); ip != nil {
+		return ip.String()
+	}
+	return r.PeerIP()
+}
+
+// PeerIP returns the IP address of the connection's remote end, or "" when unknown
+func (r *Request) PeerIP() string {
+	if r.peer == nil || r.peer.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(r.peer.Addr.String())
+	if err != nil {
+		return ""
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
+
+// Peer returns the connection's peer, or nil when unknown
+func (r *Request) Peer() *peer.Peer {
+	return r.peer
+}
+
+// UserAgent returns the forwarded user agent, otherwise the caller's own, cut to maxUserAgentLength
+func (r *Request) UserAgent() string {
+	userAgent := r.First(ForwardedUserAgentMetadataKey, "user-agent")
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	return userAgent
+}
+
+// Country returns the upper-cased ISO 3166-1 alpha-2 code of x-client-country, or "" when it is not one
+func (r *Request) Country() string {
+	value := r.First(ClientCountryMetadataKey)
+	if len(value) != 2 {
+		return ""
+	}
+	code := strings.ToUpper(value)
+	for i := 0; i < len(code); i++ {
+		if code[i] < 'A' || code[i] > 'Z' {
+			return ""
+		}
+	}
+	return code
+}
+
+// AcceptLanguage returns the forwarded accept-language, otherwise the caller's own, or "" when overlong
+func (r *Request) AcceptLanguage() string {
+	value := r.First(ForwardedLanguageMetadataKey, "accept-language")
+	if len(value) > maxAcceptLanguageLength {
+		return ""
+	}
+	return value
+}
+
+// CorrelationID returns the caller's x-correlation-id, or "" when absent or not correlation.IsValid
+func (r *Request) CorrelationID() string {
+	values := r.md.Get(correlation.MetadataKey)
+	if len(values) == 0 || !correlation.IsValid(values[0]) {
+		return ""
+	}
+	return values[0]
+}
+
+// BearerToken returns the token of an "authorization: Bearer <token>" entry, or ""
+func (r *Request) BearerToken() string {
+	scheme, token, ok := strings.Cut(r.First(AuthorizationMetadataKey), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
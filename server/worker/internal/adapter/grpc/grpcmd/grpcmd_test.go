@@ -0,0 +1,167 @@
+package grpcmd
+
+import (
+	"context"
+	"net"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// requestOf returns the request of a call carrying md from a peer at addr, or no peer when addr is ""
+func requestOf(t *testing.T, md metadata.MD, addr string) *Request {
+	t.Helper()
+	ctx := context.Background()
+	if md != nil {
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+	if addr != "" {
+		tcpAddr, err := net.ResolveTCPAddr("tcp", addr)
+		if err != nil {
+			t.Fatalf("resolve %s: %v", addr, err)
+		}
+		ctx = peer.NewContext(ctx, &peer.Peer{Addr: tcpAddr})
+	}
+	return FromContext(ctx)
+}
+
+func TestAccessors(t *testing.T) {
+	tests := []struct {
+		name string
+		md   metadata.MD
+		read func(*Request) string
+		want string
+	}{
+		{name: "client IP absent", md: metadata.MD{}, read: (*Request).ClientIP},
+		{name: "client IP forwarded", md: metadata.Pairs(ForwardedForMetadataKey, "203.0.113.7, 10.0.0.1"), read: (*Request).ClientIP, want: "203.0.113.7"},
+		{name: "client IP forwarded IPv6", md: metadata.Pairs(ForwardedForMetadataKey, "2001:db8::1"), read: (*Request).ClientIP, want: "2001:db8::1"},
+		{name: "client IP malformed", md: metadata.Pairs(ForwardedForMetadataKey, "not-an-ip"), read: (*Request).ClientIP},
+
+		{name: "user agent absent", md: metadata.MD{}, read: (*Request).UserAgent},
+		{name: "user agent own", md: metadata.Pairs("user-agent", "grpc-go/1.60"), read: (*Request).UserAgent, want: "grpc-go/1.60"},
+		{name: "user agent forwarded", md: metadata.Pairs("user-agent", "grpc-go/1.60", ForwardedUserAgentMetadataKey, " Mozilla/5.0 "), read: (*Request).UserAgent, want: "Mozilla/5.0"},
+		{name: "user agent blank forwarded", md: metadata.Pairs("user-agent", "grpc-go/1.60", ForwardedUserAgentMetadataKey, "  "), read: (*Request).UserAgent, want: "grpc-go/1.60"},
+		{name: "user agent overlong", md: metadata.Pairs("user-agent", strings.Repeat("a", maxUserAgentLength+10)), read: (*Request).UserAgent, want: strings.Repeat("a", maxUserAgentLength)},
+
+		{name: "country absent", md: metadata.MD{}, read: (*Request).Country},
+		{name: "country", md: metadata.Pairs(ClientCountryMetadataKey, "vn"), read: (*Request).Country, want: "VN"},
+		{name: "country too long", md: metadata.Pairs(ClientCountryMetadataKey, "VNM"), read: (*Request).Country},
+		{name: "country not letters", md: metadata.Pairs(ClientCountryMetadataKey, "V1"), read: (*Request).Country},
+
+		{name: "accept-language absent", md: metadata.MD{}, read: (*Request).AcceptLanguage},
+		{name: "accept-language forwarded", md: metadata.Pairs("accept-language", "en", ForwardedLanguageMetadataKey, "vi"), read: (*Request).AcceptLanguage, want: "vi"},
+		{name: "accept-language overlong", md: metadata.Pairs("accept-language", strings.Repeat("en,", 100)), read: (*Request).AcceptLanguage},
+
+		{name: "correlation ID absent", md: metadata.MD{}, read: (*Request).CorrelationID},
+		{name: "correlation ID", md: metadata.Pairs("x-correlation-id", "req-42"), read: (*Request).CorrelationID, want: "req-42"},
+		{name: "correlation ID with spaces", md: metadata.Pairs("x-correlation-id", "req 42"), read: (*Request).CorrelationID},
+		{name: "correlation ID overlong", md: metadata.Pairs("x-correlation-id", strings.Repeat("a", 129)), read: (*Request).CorrelationID},
+
+		{name: "bearer token absent", md: metadata.MD{}, read: (*Request).BearerToken},
+		{name: "bearer token", md: metadata.Pairs(AuthorizationMetadataKey, "Bearer abc.def"), read: (*Request).BearerToken, want: "abc.def"},
+		{name: "bearer token lower-case scheme", md: metadata.Pairs(AuthorizationMetadataKey, "bearer  abc.def "), read: (*Request).BearerToken, want: "abc.def"},
+		{name: "bearer token other scheme", md: metadata.Pairs(AuthorizationMetadataKey, "Basic YWxpY2U6cHc="), read: (*Request).BearerToken},
+		{name: "bearer token without scheme", md: metadata.Pairs(AuthorizationMetadataKey, "abc.def"), read: (*Request).BearerToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.read(requestOf(t, tt.md, "")); got != tt.want {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWithoutMetadata(t *testing.T) {
+	// A call without metadata or peer reads as empty everywhere rather than failing
+	r := requestOf(t, nil, "")
+	for name, got := range map[string]string{
+		"client IP":       r.ClientIP(),
+		"peer IP":         r.PeerIP(),
+		"user agent":      r.UserAgent(),
+		"country":         r.Country(),
+		"accept-language": r.AcceptLanguage(),
+		"correlation ID":  r.CorrelationID(),
+		"bearer token":    r.BearerToken(),
+		"first":           r.First(TenantMetadataKey),
+	} {
+		if got != "" {
+			t.Errorf("%s = %q, want empty", name, got)
+		}
+	}
+	if r.Peer() != nil {
+		t.Errorf("peer = %v, want nil", r.Peer())
+	}
+	if _, ok, err := r.Single(TenantMetadataKey); ok || err != nil {
+		t.Errorf("single = %t, %v; want absent", ok, err)
+	}
+
+	var zero Request
+	if zero.BearerToken() != "" || zero.PeerIP() != "" {
+		t.Error("zero Request reads a value")
+	}
+}
+
+func TestClientIPFallsBackToPeer(t *testing.T) {
+	if got := requestOf(t, metadata.MD{}, "192.0.2.10:51234").ClientIP(); got != "192.0.2.10" {
+		t.Fatalf("client IP = %q, want the peer", got)
+	}
+	if got := requestOf(t, metadata.Pairs(ForwardedForMetadataKey, "garbage"), "[2001:db8::2]:443").ClientIP(); got != "2001:db8::2" {
+		t.Fatalf("client IP = %q, want the peer over a malformed x-forwarded-for", got)
+	}
+	if got := requestOf(t, metadata.Pairs(ForwardedForMetadataKey, "203.0.113.7"), "192.0.2.10:51234").ClientIP(); got != "203.0.113.7" {
+		t.Fatalf("client IP = %q, want the forwarded client over the peer", got)
+	}
+}
+
+func TestFirst(t *testing.T) {
+	r := requestOf(t, metadata.Pairs("a", " ", "b", "", "b", " two ", "c", "three"), "")
+	if got := r.First("missing", "a", "b", "c"); got != "two" {
+		t.Fatalf("first = %q, want the first non-blank value", got)
+	}
+	if got := r.First("a"); got != "" {
+		t.Fatalf("first of a blank key = %q, want empty", got)
+	}
+}
+
+func TestSingle(t *testing.T) {
+	tests := []struct {
+		name    string
+		md      metadata.MD
+		want    string
+		wantOK  bool
+		wantErr bool
+	}{
+		{name: "absent", md: metadata.MD{}},
+		{name: "once", md: metadata.Pairs(TenantMetadataKey, "acme"), want: "acme", wantOK: true},
+		{name: "empty once", md: metadata.Pairs(TenantMetadataKey, ""), wantOK: true},
+		{name: "repeated", md: metadata.Pairs(TenantMetadataKey, "acme", TenantMetadataKey, "globex"), wantOK: true, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := requestOf(t, tt.md, "").Single(TenantMetadataKey)
+			if got != tt.want || ok != tt.wantOK || (err != nil) != tt.wantErr {
+				t.Fatalf("single = %q, %t, %v; want %q, %t, error %t", got, ok, err, tt.want, tt.wantOK, tt.wantErr)
+			}
+			if tt.wantErr && !strings.Contains(err.Error(), TenantMetadataKey) {
+				t.Fatalf("error %q does not name the key", err)
+			}
+		})
+	}
+}
+
+func TestWithRequest(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthorizationMetadataKey, "Bearer stored"))
+	ctx = WithRequest(ctx)
+
+	// The stored request is what later readers see, even if the incoming metadata is replaced afterwards
+	replaced := metadata.NewIncomingContext(ctx, metadata.Pairs(AuthorizationMetadataKey, "Bearer replaced"))
+	if got := FromContext(replaced).BearerToken(); got != "stored" {
+		t.Fatalf("bearer token = %q, want the stored request's", got)
+	}
+	if FromContext(ctx) != FromContext(replaced) {
+		t.Fatal("FromContext re-read a stored request")
+	}
+}
@@ -2,7 +2,15 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"strings"
 
+	"github.com/google/uuid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/adapter/grpc/grpcmd"
 	"worker/internal/core/domain"
 	"worker/internal/core/ports"
 	pb "worker/pb"
@@ -11,23 +19,28 @@ import (
 // AuthHandler implements the gRPC AuthServiceServer interface
 type AuthHandler struct {
 	pb.UnimplementedAuthServiceServer
-	authService ports.AuthService
+	authService   ports.AuthService
+	healthService ports.HealthService
 }
 
 // NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(authService ports.AuthService) *AuthHandler {
+func NewAuthHandler(authService ports.AuthService, healthService ports.HealthService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:   authService,
+		healthService: healthService,
 	}
 }
 
 // Register handles user registration
 func (h *AuthHandler) Register(ctx context.Context, req *pb.RegisterRequest) (*pb.RegisterResponse, error) {
 	result, err := h.authService.Register(ctx, &domain.RegisterRequest{
-		Username: req.Username,
-		Email:    req.Email,
-		Password: req.Password,
-		FullName: req.FullName,
+		Username:     req.Username,
+		Email:        req.Email,
+		Password:     req.Password,
+		FullName:     req.FullName,
+		Phone:        req.Phone,
+		Locale:       req.Locale,
+		CaptchaToken: req.CaptchaToken,
 	})
 	if err != nil {
 		return &pb.RegisterResponse{
@@ -36,6 +49,15 @@ func (h *AuthHandler) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 		}, MapDomainErrorToGRPC(err)
 	}
 
+	if result.PendingApproval {
+		return &pb.RegisterResponse{
+			Success:         true,
+			Message:         "User registered, waiting for an administrator to approve the account",
+			User:            MapUserRowToProto(result.User),
+			PendingApproval: true,
+		}, nil
+	}
+
 	return &pb.RegisterResponse{
 		Success: true,
 		Message: "User registered successfully",
@@ -46,10 +68,20 @@ func (h *AuthHandler) Register(ctx context.Context, req *pb.RegisterRequest) (*p
 // Login handles user login
 func (h *AuthHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.LoginResponse, error) {
 	result, err := h.authService.Login(ctx, &domain.LoginRequest{
-		Identifier: req.Username,
-		Password:   req.Password,
+		Identifier:   loginIdentifier(req),
+		Password:     req.Password,
+		CaptchaToken: req.CaptchaToken,
 	})
 	if err != nil {
+		// Expired passwords are a normal outcome for the client, not a transport error
+		if errors.Is(err, domain.ErrPasswordExpired) && result != nil {
+			return &pb.LoginResponse{
+				Success:             false,
+				Message:             err.Error(),
+				PasswordExpired:     true,
+				PasswordChangeToken: result.PasswordChangeToken,
+			}, nil
+		}
 		return &pb.LoginResponse{
 			Success: false,
 			Message: err.Error(),
@@ -57,14 +89,25 @@ func (h *AuthHandler) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Logi
 	}
 
 	return &pb.LoginResponse{
-		Success:      true,
-		Message:      "Login successful",
-		AccessToken:  result.AccessToken,
-		RefreshToken: result.RefreshToken,
-		User:         MapUserRowToProto(result.User),
+		Success:              true,
+		Message:              "Login successful",
+		AccessToken:          result.AccessToken,
+		RefreshToken:         result.RefreshToken,
+		User:                 MapUserRowToProto(result.User),
+		AccessTokenExpiresAt: result.AccessTokenExpiresAt.Unix(),
 	}, nil
 }
 
+// loginIdentifier returns the email or username a LoginRequest signs in with
+// identifier (field 4) is read first; the deprecated username (field 1) carried the same
+// email-or-username value before it and is still honored for older clients
+func loginIdentifier(req *pb.LoginRequest) string {
+	if req.Identifier != "" {
+		return req.Identifier
+	}
+	return req.Username
+}
+
 // RefreshToken handles token refresh
 func (h *AuthHandler) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequest) (*pb.RefreshTokenResponse, error) {
 	result, err := h.authService.RefreshAccessToken(ctx, req.RefreshToken)
@@ -76,16 +119,29 @@ func (h *AuthHandler) RefreshToken(ctx context.Context, req *pb.RefreshTokenRequ
 	}
 
 	return &pb.RefreshTokenResponse{
-		Success:     true,
-		Message:     "Token refreshed successfully",
-		AccessToken: result.AccessToken,
+		Success:              true,
+		Message:              "Token refreshed successfully",
+		AccessToken:          result.AccessToken,
+		RefreshToken:         result.RefreshToken,
+		AccessTokenExpiresAt: result.AccessTokenExpiresAt.Unix(),
 	}, nil
 }
 
 // ValidateToken validates an access token
+// With skip_user_lookup the token is validated from its claims alone, see ValidateAccessTokenClaims
 func (h *AuthHandler) ValidateToken(ctx context.Context, req *pb.ValidateTokenRequest) (*pb.ValidateTokenResponse, error) {
-	result, err := h.authService.ValidateAccessToken(ctx, req.AccessToken)
+	validate := h.authService.ValidateAccessToken
+	if req.SkipUserLookup {
+		validate = h.authService.ValidateAccessTokenClaims
+	}
+	result, err := validate(ctx, req.AccessToken)
 	if err != nil {
+		// A failed lookup (AUTH_PERMISSIONS_FAIL_MODE=closed) says nothing about the token itself,
+		// so it is reported as an error the caller can retry rather than as an invalid token; so is a lookup cut short
+		if errors.Is(err, domain.ErrDatabaseOperation) || errors.Is(err, domain.ErrDatabaseUnavailable) ||
+			errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+			return nil, MapDomainErrorToGRPC(err)
+		}
 		return &pb.ValidateTokenResponse{
 			Valid:   false,
 			Message: err.Error(),
@@ -98,7 +154,613 @@ func (h *AuthHandler) ValidateToken(ctx context.Context, req *pb.ValidateTokenRe
 		User: &pb.User{
 			Id:          result.UserID,
 			Email:       result.Email,
+			RoleCode:    result.Role,
 			Permissions: result.Permissions,
 		},
+		CustomClaims: mapCustomClaimsToProto(result.Claims),
+		ExpiresAt:    timeToUnix(result.ExpiresAt),
+	}, nil
+}
+
+// ValidateTokensBatch validates several access tokens in one call, results in request order
+// Like ValidateToken, invalid tokens are reported per token and only failed lookups fail the call
+func (h *AuthHandler) ValidateTokensBatch(ctx context.Context, req *pb.ValidateTokensBatchRequest) (*pb.ValidateTokensBatchResponse, error) {
+	validations, err := h.authService.ValidateAccessTokens(ctx, req.AccessTokens)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+
+	results := make([]*pb.ValidateTokenResponse, len(validations))
+	for i, validation := range validations {
+		if validation.Err != nil {
+			results[i] = &pb.ValidateTokenResponse{
+				Valid:   false,
+				Message: validation.Err.Error(),
+			}
+			continue
+		}
+		results[i] = &pb.ValidateTokenResponse{
+			Valid:   validation.Result.Valid,
+			Message: "Token is valid",
+			User: &pb.User{
+				Id:          validation.Result.UserID,
+				Email:       validation.Result.Email,
+				RoleCode:    validation.Result.Role,
+				Permissions: validation.Result.Permissions,
+			},
+			CustomClaims: mapCustomClaimsToProto(validation.Result.Claims),
+			ExpiresAt:    timeToUnix(validation.Result.ExpiresAt),
+		}
+	}
+	return &pb.ValidateTokensBatchResponse{Results: results}, nil
+}
+
+// ChangePassword changes the caller's password and revokes all of their sessions
+func (h *AuthHandler) ChangePassword(ctx context.Context, req *pb.ChangePasswordRequest) (*pb.ChangePasswordResponse, error) {
+	if err := h.authService.ChangePassword(ctx, req.Identifier, req.Token, req.CurrentPassword, req.NewPassword); err != nil {
+		return &pb.ChangePasswordResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.ChangePasswordResponse{
+		Success: true,
+		Message: "Password changed successfully, please log in again",
+	}, nil
+}
+
+// ChangeUsername renames the caller
+// Access tokens issued before the rename carry the old username until they expire
+func (h *AuthHandler) ChangeUsername(ctx context.Context, req *pb.ChangeUsernameRequest) (*pb.ChangeUsernameResponse, error) {
+	userID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	username, err := h.authService.ChangeUsername(ctx, userID, req.NewUsername)
+	if err != nil {
+		return &pb.ChangeUsernameResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.ChangeUsernameResponse{
+		Success:  true,
+		Message:  "Username changed successfully",
+		Username: username,
+	}, nil
+}
+
+// IntrospectToken returns the claim set of an access or refresh token (RFC 7662 style)
+// Inactive tokens are reported with active=false rather than an error.
+// Like RFC 7662 §2.1 asks, only authenticated callers may introspect: the caller must present an allowlisted
+// client certificate, so the method is refused altogether unless mTLS is enabled (GRPC_TLS_CLIENT_CA_FILE)
+func (h *AuthHandler) IntrospectToken(ctx context.Context, req *pb.IntrospectTokenRequest) (*pb.IntrospectTokenResponse, error) {
+	if _, ok := domain.ServiceIdentityFromContext(ctx); !ok {
+		return nil, grpcerr.New(codes.Unauthenticated, domain.CodePermissionDenied,
+			"token introspection requires an allowlisted client certificate", "")
+	}
+
+	result, err := h.authService.IntrospectToken(ctx, req.Token, req.TokenTypeHint)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+
+	if !result.Active {
+		return &pb.IntrospectTokenResponse{Active: false}, nil
+	}
+
+	return &pb.IntrospectTokenResponse{
+		Active:    true,
+		TokenType: result.TokenType,
+		Sub:       result.Subject,
+		Username:  result.Username,
+		Role:      result.Role,
+		Roles:     result.Roles,
+		Scope:     strings.Join(result.Permissions, " "),
+		Iat:       result.IssuedAt.Unix(),
+		Exp:       result.ExpiresAt.Unix(),
+		Iss:       result.Issuer,
+		TenantId:  result.TenantID,
+	}, nil
+}
+
+// GetMyPermissions returns the effective permissions of the caller
+func (h *AuthHandler) GetMyPermissions(ctx context.Context, req *pb.GetMyPermissionsRequest) (*pb.GetMyPermissionsResponse, error) {
+	page, err := h.authService.ListMyPermissions(ctx, req.AccessToken, req.Cursor, req.PageSize)
+	if err != nil {
+		return &pb.GetMyPermissionsResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.GetMyPermissionsResponse{
+		Success:     true,
+		Message:     "Permissions retrieved successfully",
+		Permissions: page.Permissions,
+		NextCursor:  page.NextCursor,
+		Total:       int32(page.Total),
+	}, nil
+}
+
+// CheckPermission answers whether the caller may perform an action, for authorization delegation
+// A denied check is a successful call with allowed=false
+func (h *AuthHandler) CheckPermission(ctx context.Context, req *pb.CheckPermissionRequest) (*pb.CheckPermissionResponse, error) {
+	decision, err := h.authService.CheckPermission(ctx, req.AccessToken, req.Permission)
+	if err != nil {
+		return &pb.CheckPermissionResponse{
+			Allowed: false,
+			Reason:  err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.CheckPermissionResponse{
+		Allowed:           decision.Allowed,
+		Reason:            decision.Reason,
+		MatchedPermission: decision.MatchedPermission,
+	}, nil
+}
+
+// GetDBStats returns database connection pool statistics for monitoring
+func (h *AuthHandler) GetDBStats(ctx context.Context, req *pb.GetDBStatsRequest) (*pb.GetDBStatsResponse, error) {
+	stats, err := h.authService.GetDBStats(ctx, req.AccessToken)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.GetDBStatsResponse{
+		AcquiredConns:        stats.AcquiredConns,
+		IdleConns:            stats.IdleConns,
+		TotalConns:           stats.TotalConns,
+		MaxConns:             stats.MaxConns,
+		AcquireCount:         stats.AcquireCount,
+		WaitCount:            stats.EmptyAcquireCount,
+		WaitDurationMs:       stats.EmptyAcquireWait.Milliseconds(),
+		AcquireDurationMs:    stats.AcquireDuration.Milliseconds(),
+		CanceledAcquireCount: stats.CanceledAcquires,
+	}, nil
+}
+
+// GetStats returns user aggregates of the caller's tenant for reporting
+func (h *AuthHandler) GetStats(ctx context.Context, req *pb.GetStatsRequest) (*pb.GetStatsResponse, error) {
+	stats, err := h.authService.GetStats(ctx, req.AccessToken, req.Days)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+	return MapUserStatsToProto(stats), nil
+}
+
+// GetHealth reports the readiness of every dependency
+// It answers even when dependencies are down, the status carries the outcome
+func (h *AuthHandler) GetHealth(ctx context.Context, req *pb.GetHealthRequest) (*pb.GetHealthResponse, error) {
+	return MapHealthReportToProto(h.healthService.GetHealth(ctx)), nil
+}
+
+// Ping echoes the request's message with the server time and version; it needs no token
+func (h *AuthHandler) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	pong := h.healthService.Ping(ctx)
+	return &pb.PingResponse{
+		Message:      req.Message,
+		ServerTimeMs: pong.ServerTime.UnixMilli(),
+		Version:      pong.Version,
+	}, nil
+}
+
+// ListRoles returns every role with its permissions
+func (h *AuthHandler) ListRoles(ctx context.Context, req *pb.ListRolesRequest) (*pb.ListRolesResponse, error) {
+	roles, err := h.authService.ListRoles(ctx, req.AccessToken)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+
+	resp := &pb.ListRolesResponse{Roles: make([]*pb.Role, len(roles))}
+	for i := range roles {
+		resp.Roles[i] = MapRoleToProto(&roles[i])
+	}
+	return resp, nil
+}
+
+// GetRole returns a role by code with its permissions
+func (h *AuthHandler) GetRole(ctx context.Context, req *pb.GetRoleRequest) (*pb.GetRoleResponse, error) {
+	role, err := h.authService.GetRole(ctx, req.AccessToken, req.Code)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+	return &pb.GetRoleResponse{Role: MapRoleToProto(role)}, nil
+}
+
+// GrantPermission grants a permission to a role
+func (h *AuthHandler) GrantPermission(ctx context.Context, req *pb.GrantPermissionRequest) (*pb.GrantPermissionResponse, error) {
+	if err := h.authService.GrantPermission(ctx, req.AccessToken, req.RoleCode, req.Permission); err != nil {
+		return &pb.GrantPermissionResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.GrantPermissionResponse{
+		Success: true,
+		Message: "Permission granted successfully",
+	}, nil
+}
+
+// RevokePermission revokes a permission from a role
+func (h *AuthHandler) RevokePermission(ctx context.Context, req *pb.RevokePermissionRequest) (*pb.RevokePermissionResponse, error) {
+	if err := h.authService.RevokePermission(ctx, req.AccessToken, req.RoleCode, req.Permission); err != nil {
+		return &pb.RevokePermissionResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.RevokePermissionResponse{
+		Success: true,
+		Message: "Permission revoked successfully",
+	}, nil
+}
+
+// GetAvatarUploadURL returns a presigned URL for uploading the caller's avatar
+func (h *AuthHandler) GetAvatarUploadURL(ctx context.Context, req *pb.GetAvatarUploadURLRequest) (*pb.GetAvatarUploadURLResponse, error) {
+	userID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := h.authService.GetAvatarUploadURL(ctx, userID, req.ContentType, req.ContentLength)
+	if err != nil {
+		return &pb.GetAvatarUploadURLResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.GetAvatarUploadURLResponse{
+		Success:      true,
+		Message:      "Upload URL created successfully",
+		UploadUrl:    result.UploadURL,
+		ObjectKey:    result.ObjectKey,
+		PublicUrl:    result.PublicURL,
+		ExpiresAt:    result.ExpiresAt.Unix(),
+		MaxSizeBytes: result.MaxSize,
+	}, nil
+}
+
+// ConfirmAvatar verifies the uploaded avatar and sets it on the caller
+func (h *AuthHandler) ConfirmAvatar(ctx context.Context, req *pb.ConfirmAvatarRequest) (*pb.ConfirmAvatarResponse, error) {
+	userID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	avatarURL, err := h.authService.ConfirmAvatar(ctx, userID, req.ObjectKey)
+	if err != nil {
+		return &pb.ConfirmAvatarResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.ConfirmAvatarResponse{
+		Success:   true,
+		Message:   "Avatar updated successfully",
+		AvatarUrl: avatarURL,
 	}, nil
 }
+
+// WatchRevocations streams session revocations until the client disconnects
+// A subscriber that falls behind is disconnected with ResourceExhausted, since it missed events.
+// Events name every revoked user and session, so like IntrospectToken the stream requires an allowlisted
+// client certificate and is refused altogether unless mTLS is enabled (GRPC_TLS_CLIENT_CA_FILE)
+func (h *AuthHandler) WatchRevocations(req *pb.WatchRevocationsRequest, stream grpc.ServerStreamingServer[pb.RevocationEvent]) error {
+	ctx := stream.Context()
+	if _, ok := domain.ServiceIdentityFromContext(ctx); !ok {
+		return grpcerr.New(codes.Unauthenticated, domain.CodePermissionDenied,
+			"watching revocations requires an allowlisted client certificate", "")
+	}
+	events := h.authService.WatchRevocations(ctx)
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return grpcerr.New(codes.ResourceExhausted, "SUBSCRIBER_TOO_SLOW",
+					"revocation stream fell behind, reconnect and drop cached validation results", "")
+			}
+			if err := stream.Send(&pb.RevocationEvent{
+				SessionId: event.SessionID,
+				UserId:    event.UserID,
+				Reason:    event.Reason,
+				RevokedAt: event.RevokedAt.Unix(),
+			}); err != nil {
+				return err
+			}
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// ListUsers returns one page of the caller's tenant users
+func (h *AuthHandler) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	query, err := mapListUsersRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := h.authService.ListUsers(ctx, req.AccessToken, query)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+
+	users := make([]*pb.User, len(page.Users))
+	for i := range page.Users {
+		users[i] = MapListUsersRowToProto(&page.Users[i], page.RolePermissions[page.Users[i].RoleID])
+	}
+	return &pb.ListUsersResponse{
+		Users:      users,
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// RevokeAllUserTokens signs a user out everywhere, for incident response
+func (h *AuthHandler) RevokeAllUserTokens(ctx context.Context, req *pb.RevokeAllUserTokensRequest) (*pb.RevokeAllUserTokensResponse, error) {
+	callerID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidArgument, "user_id must be a UUID", "user_id")
+	}
+
+	if err := h.authService.RevokeAllUserTokens(ctx, callerID, targetID); err != nil {
+		return &pb.RevokeAllUserTokensResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.RevokeAllUserTokensResponse{
+		Success: true,
+		Message: "User signed out of all sessions",
+	}, nil
+}
+
+// ForcePasswordReset locks a compromised account until its password is reset, for incident response
+func (h *AuthHandler) ForcePasswordReset(ctx context.Context, req *pb.ForcePasswordResetRequest) (*pb.ForcePasswordResetResponse, error) {
+	callerID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidArgument, "user_id must be a UUID", "user_id")
+	}
+
+	reset, err := h.authService.ForcePasswordReset(ctx, callerID, targetID)
+	if err != nil {
+		return &pb.ForcePasswordResetResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.ForcePasswordResetResponse{
+		Success:             true,
+		Message:             "Password reset required, user signed out of all sessions",
+		ResetToken:          reset.Token,
+		ResetTokenExpiresAt: reset.ExpiresAt.Unix(),
+	}, nil
+}
+
+// SetUserActive activates or deactivates a user, the caller needs users:UPDATE
+func (h *AuthHandler) SetUserActive(ctx context.Context, req *pb.SetUserActiveRequest) (*pb.SetUserActiveResponse, error) {
+	callerID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidArgument, "user_id must be a UUID", "user_id")
+	}
+
+	if err := h.authService.SetUserActive(ctx, callerID, targetID, req.Active); err != nil {
+		return &pb.SetUserActiveResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	message := "User activated"
+	if !req.Active {
+		message = "User deactivated and signed out of all sessions"
+	}
+	return &pb.SetUserActiveResponse{
+		Success: true,
+		Message: message,
+	}, nil
+}
+
+// ListPendingApprovals lists the accounts waiting for an admin, the caller needs users:READ
+func (h *AuthHandler) ListPendingApprovals(ctx context.Context, req *pb.ListPendingApprovalsRequest) (*pb.ListPendingApprovalsResponse, error) {
+	page, err := h.authService.ListPendingApprovals(ctx, req.AccessToken, req.Cursor, req.PageSize)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+
+	users := make([]*pb.User, len(page.Users))
+	for i := range page.Users {
+		users[i] = MapListUsersRowToProto(&page.Users[i], page.RolePermissions[page.Users[i].RoleID])
+	}
+	return &pb.ListPendingApprovalsResponse{
+		Users:      users,
+		NextCursor: page.NextCursor,
+	}, nil
+}
+
+// ApproveUser activates a pending account, the caller needs users:UPDATE
+func (h *AuthHandler) ApproveUser(ctx context.Context, req *pb.ApproveUserRequest) (*pb.ApproveUserResponse, error) {
+	callerID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidArgument, "user_id must be a UUID", "user_id")
+	}
+
+	if err := h.authService.ApproveUser(ctx, callerID, targetID, req.RoleCode); err != nil {
+		return &pb.ApproveUserResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.ApproveUserResponse{
+		Success: true,
+		Message: "User approved",
+	}, nil
+}
+
+// RejectUser rejects a pending account, the caller needs users:DELETE
+func (h *AuthHandler) RejectUser(ctx context.Context, req *pb.RejectUserRequest) (*pb.RejectUserResponse, error) {
+	callerID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidArgument, "user_id must be a UUID", "user_id")
+	}
+
+	if err := h.authService.RejectUser(ctx, callerID, targetID, req.Reason); err != nil {
+		return &pb.RejectUserResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.RejectUserResponse{
+		Success: true,
+		Message: "User rejected, the account will be erased after the deletion grace period",
+	}, nil
+}
+
+// PurgeUser permanently erases a user, the caller needs users:DELETE
+func (h *AuthHandler) PurgeUser(ctx context.Context, req *pb.PurgeUserRequest) (*pb.PurgeUserResponse, error) {
+	callerID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	targetID, err := uuid.Parse(req.UserId)
+	if err != nil {
+		return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidArgument, "user_id must be a UUID", "user_id")
+	}
+
+	if err := h.authService.PurgeUser(ctx, callerID, targetID, req.Confirmation); err != nil {
+		return &pb.PurgeUserResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.PurgeUserResponse{
+		Success: true,
+		Message: "User permanently deleted",
+	}, nil
+}
+
+// ScheduleDeletion schedules the current user's account for deletion
+func (h *AuthHandler) ScheduleDeletion(ctx context.Context, req *pb.ScheduleDeletionRequest) (*pb.ScheduleDeletionResponse, error) {
+	userID, err := h.authenticate(ctx, req.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	deletionAt, err := h.authService.ScheduleDeletion(ctx, userID)
+	if err != nil {
+		return &pb.ScheduleDeletionResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.ScheduleDeletionResponse{
+		Success:    true,
+		Message:    "Account scheduled for deletion",
+		DeletionAt: deletionAt.Unix(),
+	}, nil
+}
+
+// CancelDeletion restores an account scheduled for deletion
+func (h *AuthHandler) CancelDeletion(ctx context.Context, req *pb.CancelDeletionRequest) (*pb.CancelDeletionResponse, error) {
+	err := h.authService.CancelDeletion(ctx, &domain.LoginRequest{
+		Identifier:   req.Username,
+		Password:     req.Password,
+		CaptchaToken: req.CaptchaToken,
+	})
+	if err != nil {
+		return &pb.CancelDeletionResponse{
+			Success: false,
+			Message: err.Error(),
+		}, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.CancelDeletionResponse{
+		Success: true,
+		Message: "Account deletion cancelled",
+	}, nil
+}
+
+// CheckAvailabilityBatch reports which emails and usernames are still free, results in request order
+func (h *AuthHandler) CheckAvailabilityBatch(ctx context.Context, req *pb.CheckAvailabilityBatchRequest) (*pb.CheckAvailabilityBatchResponse, error) {
+	batch, err := h.authService.CheckAvailabilityBatch(ctx, req.AccessToken, req.Emails, req.Usernames)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+
+	return &pb.CheckAvailabilityBatchResponse{
+		Emails:    mapAvailabilityToProto(batch.Emails),
+		Usernames: mapAvailabilityToProto(batch.Usernames),
+	}, nil
+}
+
+// DecodeToken returns the unverified claims of a JWT, for support debugging
+func (h *AuthHandler) DecodeToken(ctx context.Context, req *pb.DecodeTokenRequest) (*pb.DecodeTokenResponse, error) {
+	decoded, err := h.authService.DecodeToken(ctx, req.AccessToken, req.Token)
+	if err != nil {
+		return nil, MapDomainErrorToGRPC(err)
+	}
+
+	return mapDecodedTokenToProto(decoded), nil
+}
+
+// authenticate validates the access token and returns the caller's user ID
+// Without an access_token field the "authorization: Bearer" metadata is used
+func (h *AuthHandler) authenticate(ctx context.Context, accessToken string) (uuid.UUID, error) {
+	if accessToken == "" {
+		accessToken = grpcmd.FromContext(ctx).BearerToken()
+	}
+	result, err := h.authService.ValidateAccessToken(ctx, accessToken)
+	if err != nil {
+		return uuid.Nil, MapDomainErrorToGRPC(err)
+	}
+
+	userID, err := uuid.Parse(result.UserID)
+	if err != nil {
+		return uuid.Nil, grpcerr.New(codes.Unauthenticated, domain.CodeInvalidToken, "invalid token subject", "")
+	}
+	return userID, nil
+}
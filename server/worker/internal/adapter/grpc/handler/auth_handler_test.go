@@ -0,0 +1,268 @@
+package handler
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+	pb "worker/pb"
+)
+
+// introspectingService reports every token as an active access token
+type introspectingService struct {
+	ports.AuthService
+}
+
+func (introspectingService) IntrospectToken(ctx context.Context, token, tokenTypeHint string) (*domain.IntrospectionResult, error) {
+	return &domain.IntrospectionResult{Active: true, TokenType: domain.TokenTypeAccess, Subject: "alice"}, nil
+}
+
+func TestIntrospectTokenRequiresServiceIdentity(t *testing.T) {
+	h := NewAuthHandler(introspectingService{}, nil)
+	req := &pb.IntrospectTokenRequest{Token: "token"}
+
+	// Without mTLS no caller carries a service identity, so introspection is refused
+	_, err := h.IntrospectToken(context.Background(), req)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("introspect without a service identity: %v, want Unauthenticated", err)
+	}
+
+	resp, err := h.IntrospectToken(domain.WithServiceIdentity(context.Background(), "gateway"), req)
+	if err != nil {
+		t.Fatalf("introspect as gateway: %v", err)
+	}
+	if !resp.Active || resp.Sub != "alice" {
+		t.Fatalf("introspect as gateway = %+v, want alice active", resp)
+	}
+}
+
+// revokingService publishes one revocation event to every watcher
+type revokingService struct {
+	ports.AuthService
+}
+
+func (revokingService) WatchRevocations(ctx context.Context) <-chan domain.RevocationEvent {
+	events := make(chan domain.RevocationEvent, 1)
+	events <- domain.RevocationEvent{SessionID: "session-1", UserID: "user-1", Reason: domain.RevocationReasonAdminRevoked, RevokedAt: time.Unix(1700000000, 0)}
+	return events
+}
+
+// revocationStream is a WatchRevocations stream that records sent events and ends after the first one
+type revocationStream struct {
+	grpc.ServerStream
+	ctx    context.Context
+	cancel context.CancelFunc
+	sent   []*pb.RevocationEvent
+}
+
+func newRevocationStream(ctx context.Context) *revocationStream {
+	ctx, cancel := context.WithCancel(ctx)
+	return &revocationStream{ctx: ctx, cancel: cancel}
+}
+
+func (s *revocationStream) Context() context.Context { return s.ctx }
+
+func (s *revocationStream) Send(event *pb.RevocationEvent) error {
+	s.sent = append(s.sent, event)
+	s.cancel()
+	return nil
+}
+
+func TestWatchRevocationsRequiresServiceIdentity(t *testing.T) {
+	h := NewAuthHandler(revokingService{}, nil)
+
+	// Without mTLS no caller carries a service identity, so the stream is refused before any event is sent
+	stream := newRevocationStream(context.Background())
+	err := h.WatchRevocations(&pb.WatchRevocationsRequest{}, stream)
+	if status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("watch without a service identity: %v, want Unauthenticated", err)
+	}
+	if len(stream.sent) != 0 {
+		t.Fatalf("unauthenticated watcher received %d events", len(stream.sent))
+	}
+
+	stream = newRevocationStream(domain.WithServiceIdentity(context.Background(), "gateway"))
+	if err := h.WatchRevocations(&pb.WatchRevocationsRequest{}, stream); err != nil {
+		t.Fatalf("watch as gateway: %v", err)
+	}
+	if len(stream.sent) != 1 || stream.sent[0].SessionId != "session-1" {
+		t.Fatalf("watch as gateway sent %v, want the session-1 event", stream.sent)
+	}
+}
+
+// failingValidation fails every token validation with err
+type failingValidation struct {
+	ports.AuthService
+	err error
+}
+
+func (s failingValidation) ValidateAccessToken(ctx context.Context, tokenString string) (*domain.ValidateTokenResult, error) {
+	return nil, s.err
+}
+
+func TestValidateTokenLookupFailure(t *testing.T) {
+	// A lookup that failed (AUTH_PERMISSIONS_FAIL_MODE=closed) is an error the caller can retry
+	h := NewAuthHandler(failingValidation{err: domain.NewAuthError(domain.ErrDatabaseOperation, "failed to resolve permissions", domain.CodeInternalError)}, nil)
+	resp, err := h.ValidateToken(context.Background(), &pb.ValidateTokenRequest{AccessToken: "token"})
+	if status.Code(err) != codes.Internal {
+		t.Fatalf("validate with a failed lookup = %+v, %v; want Internal", resp, err)
+	}
+
+	// As is a lookup the request's deadline cut short, which keeps its code so the caller can tell it apart
+	h = NewAuthHandler(failingValidation{err: domain.NewAuthError(context.DeadlineExceeded, "failed to resolve permissions: deadline exceeded", domain.CodeDeadlineExceeded)}, nil)
+	resp, err = h.ValidateToken(context.Background(), &pb.ValidateTokenRequest{AccessToken: "token"})
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("validate with a timed out lookup = %+v, %v; want DeadlineExceeded", resp, err)
+	}
+
+	// A bad token is an answer, not an error
+	h = NewAuthHandler(failingValidation{err: domain.NewAuthError(domain.ErrInvalidToken, "invalid token", domain.CodeInvalidToken)}, nil)
+	resp, err = h.ValidateToken(context.Background(), &pb.ValidateTokenRequest{AccessToken: "token"})
+	if err != nil || resp.Valid {
+		t.Fatalf("validate an invalid token = %+v, %v; want an invalid response", resp, err)
+	}
+
+	// So is a refresh token sent by mistake, whose message tells the client what went wrong
+	wrongType := domain.NewAuthError(domain.ErrWrongTokenType, "a refresh token was sent where an access token is expected", domain.CodeWrongTokenType)
+	h = NewAuthHandler(failingValidation{err: wrongType}, nil)
+	resp, err = h.ValidateToken(context.Background(), &pb.ValidateTokenRequest{AccessToken: "refresh-token"})
+	if err != nil || resp.Valid || resp.Message != wrongType.Error() {
+		t.Fatalf("validate a refresh token = %+v, %v; want an invalid response saying %q", resp, err, wrongType.Error())
+	}
+}
+
+// batchValidation answers a batch validation with validations, or fails it with err
+type batchValidation struct {
+	ports.AuthService
+	validations []domain.TokenValidation
+	err         error
+}
+
+func (s batchValidation) ValidateAccessTokens(ctx context.Context, tokens []string) ([]domain.TokenValidation, error) {
+	return s.validations, s.err
+}
+
+func TestValidateTokensBatch(t *testing.T) {
+	expiresAt := time.Unix(1700000000, 0)
+	h := NewAuthHandler(batchValidation{validations: []domain.TokenValidation{
+		{Result: &domain.ValidateTokenResult{Valid: true, UserID: "user-1", Email: "alice@example.com", Role: "STUDENT", Permissions: []string{}, ExpiresAt: expiresAt}},
+		{Err: domain.NewAuthError(domain.ErrTokenExpired, "token has expired", domain.CodeTokenExpired)},
+	}}, nil)
+
+	resp, err := h.ValidateTokensBatch(context.Background(), &pb.ValidateTokensBatchRequest{AccessTokens: []string{"valid", "expired"}})
+	if err != nil {
+		t.Fatalf("validate batch: %v", err)
+	}
+	if len(resp.Results) != 2 {
+		t.Fatalf("got %d results, want 2", len(resp.Results))
+	}
+	if valid := resp.Results[0]; !valid.Valid || valid.User.Id != "user-1" || valid.User.Email != "alice@example.com" ||
+		valid.User.RoleCode != "STUDENT" || valid.ExpiresAt != expiresAt.Unix() {
+		t.Fatalf("first result = %+v, want user-1 valid", valid)
+	}
+	// Invalid tokens are answered per token, not as an error of the call
+	if invalid := resp.Results[1]; invalid.Valid || invalid.User != nil || invalid.Message != "token has expired" {
+		t.Fatalf("second result = %+v, want the expired token invalid", invalid)
+	}
+
+	h = NewAuthHandler(batchValidation{err: domain.NewFieldError(domain.ErrBatchTooLarge, "access_tokens", "at most 100 tokens can be validated at once")}, nil)
+	if _, err := h.ValidateTokensBatch(context.Background(), &pb.ValidateTokensBatchRequest{}); status.Code(err) != codes.InvalidArgument {
+		t.Fatalf("validate too large a batch = %v, want InvalidArgument", err)
+	}
+}
+
+// recordingLogin records the identifier of login attempts and refuses them
+type recordingLogin struct {
+	ports.AuthService
+	identifiers []string
+}
+
+func (s *recordingLogin) Login(ctx context.Context, req *domain.LoginRequest) (*ports.AuthResponse, error) {
+	s.identifiers = append(s.identifiers, req.Identifier)
+	return nil, domain.NewAuthError(domain.ErrInvalidCredentials, "invalid credentials", domain.CodeInvalidCredentials)
+}
+
+func TestLoginIdentifier(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *pb.LoginRequest
+		want string
+	}{
+		{name: "identifier only", req: &pb.LoginRequest{Identifier: "alice@example.com"}, want: "alice@example.com"},
+		// Older clients still sign in through the deprecated field
+		{name: "username only", req: &pb.LoginRequest{Username: "alice"}, want: "alice"},
+		{name: "both", req: &pb.LoginRequest{Identifier: "alice@example.com", Username: "bob"}, want: "alice@example.com"},
+		{name: "neither", req: &pb.LoginRequest{}, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := &recordingLogin{}
+			h := NewAuthHandler(service, nil)
+			tt.req.Password = "Correct-Horse-9-Battery"
+			if _, err := h.Login(context.Background(), tt.req); status.Code(err) != codes.Unauthenticated {
+				t.Fatalf("login = %v, want the service's Unauthenticated", err)
+			}
+			if len(service.identifiers) != 1 || service.identifiers[0] != tt.want {
+				t.Fatalf("logged in as %q, want %q", service.identifiers, tt.want)
+			}
+		})
+	}
+}
+
+// pongService answers Ping with a fixed pong; it has no dependencies to probe
+type pongService struct {
+	ports.HealthService
+	pong domain.Pong
+}
+
+func (s pongService) Ping(ctx context.Context) *domain.Pong {
+	return &s.pong
+}
+
+func TestPingWithoutToken(t *testing.T) {
+	serverTime := time.UnixMilli(1700000000123)
+	// No auth service at all: Ping must not need one
+	h := NewAuthHandler(nil, pongService{pong: domain.Pong{Version: "v1.2.3", ServerTime: serverTime}})
+
+	resp, err := h.Ping(context.Background(), &pb.PingRequest{Message: "hello"})
+	if err != nil {
+		t.Fatalf("ping: %v", err)
+	}
+	if resp.Message != "hello" || resp.ServerTimeMs != serverTime.UnixMilli() || resp.Version != "v1.2.3" {
+		t.Fatalf("pong = %+v, want hello echoed with the server time and version", resp)
+	}
+}
+
+// pagedPermissions answers every permission listing with page
+type pagedPermissions struct {
+	ports.AuthService
+	page domain.PermissionPage
+}
+
+func (s pagedPermissions) ListMyPermissions(ctx context.Context, accessToken, cursor string, pageSize int32) (*domain.PermissionPage, error) {
+	return &s.page, nil
+}
+
+func TestGetMyPermissionsPage(t *testing.T) {
+	page := domain.PermissionPage{
+		Permissions: []string{"roles:READ", "roles:UPDATE"},
+		NextCursor:  "roles:UPDATE",
+		Total:       6,
+	}
+	h := NewAuthHandler(pagedPermissions{page: page}, nil)
+
+	resp, err := h.GetMyPermissions(context.Background(), &pb.GetMyPermissionsRequest{AccessToken: "token", PageSize: 2})
+	if err != nil {
+		t.Fatalf("get my permissions: %v", err)
+	}
+	if !resp.Success || !slices.Equal(resp.Permissions, page.Permissions) || resp.NextCursor != page.NextCursor || resp.Total != 6 {
+		t.Fatalf("response = %+v, want the page %+v", resp, page)
+	}
+}
@@ -1,12 +1,20 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
+	"worker/internal/adapter/grpc/grpcerr"
 	"worker/internal/adapter/storage/postgres/sqlc"
 	"worker/internal/common/utils"
 	"worker/internal/core/domain"
+	"worker/internal/core/ports"
 	pb "worker/pb"
 )
 
@@ -21,38 +29,288 @@ func MapUserRowToProto(user *sqlc.GetUserByEmailOrUsernameRow) *pb.User {
 	}
 
 	return &pb.User{
-		Id:       user.ID.String(),
-		Username: user.Username,
-		Email:    user.Email,
-		FullName: user.FullName,
-		RoleId:   user.RoleID.String(),
-		RoleName: utils.PtrStringValue(user.RoleName),
-		RoleCode: utils.PtrStringValue(user.RoleCode),
+		Id:                  user.ID.String(),
+		Username:            user.Username,
+		Email:               user.Email,
+		FullName:            user.FullName,
+		RoleId:              user.RoleID.String(),
+		RoleName:            utils.PtrStringValue(user.RoleName),
+		RoleCode:            utils.PtrStringValue(user.RoleCode),
+		Avatar:              utils.PtrStringValue(user.Avatar),
+		TenantId:            user.TenantID,
+		LastLoginIp:         utils.PtrStringValue(user.LastLoginIp),
+		LastLoginUserAgent:  utils.PtrStringValue(user.LastLoginUserAgent),
+		Locale:              utils.PtrStringValue(user.Locale),
+		DeletionScheduledAt: timestampToUnix(user.ScheduledDeletionAt),
+	}
+}
+
+// MapListUsersRowToProto converts sqlc.ListUsersRow to protobuf User with the permissions of its primary role
+func MapListUsersRowToProto(user *sqlc.ListUsersRow, permissions []string) *pb.User {
+	return &pb.User{
+		Id:                  user.ID.String(),
+		Username:            user.Username,
+		Email:               user.Email,
+		FullName:            user.FullName,
+		RoleId:              user.RoleID.String(),
+		RoleName:            utils.PtrStringValue(user.RoleName),
+		RoleCode:            utils.PtrStringValue(user.RoleCode),
+		Permissions:         permissions,
+		Avatar:              utils.PtrStringValue(user.Avatar),
+		TenantId:            user.TenantID,
+		LastLoginIp:         utils.PtrStringValue(user.LastLoginIp),
+		LastLoginUserAgent:  utils.PtrStringValue(user.LastLoginUserAgent),
+		Locale:              utils.PtrStringValue(user.Locale),
+		DeletionScheduledAt: timestampToUnix(user.ScheduledDeletionAt),
+	}
+}
+
+// timestampToUnix returns t in Unix seconds, or 0 when t is NULL
+func timestampToUnix(t pgtype.Timestamp) int64 {
+	if !t.Valid {
+		return 0
+	}
+	return t.Time.Unix()
+}
+
+// MapUserStatsToProto converts ports.UserStats to a protobuf GetStatsResponse
+func MapUserStatsToProto(stats *ports.UserStats) *pb.GetStatsResponse {
+	resp := &pb.GetStatsResponse{
+		TotalUsers:          stats.TotalUsers,
+		UsersByRole:         make([]*pb.RoleUserCount, len(stats.UsersByRole)),
+		RegistrationsPerDay: make([]*pb.DailyRegistrations, len(stats.RegistrationsPerDay)),
+		Since:               stats.Since.Unix(),
+	}
+	for i, row := range stats.UsersByRole {
+		resp.UsersByRole[i] = &pb.RoleUserCount{
+			RoleCode:  row.RoleCode,
+			RoleName:  row.RoleName,
+			UserCount: row.UserCount,
+		}
+	}
+	for i, row := range stats.RegistrationsPerDay {
+		resp.RegistrationsPerDay[i] = &pb.DailyRegistrations{
+			Day:   row.Day.Time.Format(time.DateOnly),
+			Count: row.Registrations,
+		}
+	}
+	return resp
+}
+
+// healthStatuses maps domain health statuses to protobuf
+var healthStatuses = map[string]pb.HealthStatus{
+	domain.HealthUp:   pb.HealthStatus_HEALTH_STATUS_UP,
+	domain.HealthDown: pb.HealthStatus_HEALTH_STATUS_DOWN,
+}
+
+// MapHealthReportToProto converts domain.HealthReport to a protobuf GetHealthResponse
+func MapHealthReportToProto(report *domain.HealthReport) *pb.GetHealthResponse {
+	resp := &pb.GetHealthResponse{
+		Status:       healthStatuses[report.Status],
+		Version:      report.Version,
+		Dependencies: make([]*pb.DependencyHealth, len(report.Dependencies)),
+		CheckedAt:    report.CheckedAt.Unix(),
+	}
+	for i, dep := range report.Dependencies {
+		resp.Dependencies[i] = &pb.DependencyHealth{
+			Name:      dep.Name,
+			Status:    healthStatuses[dep.Status],
+			Detail:    dep.Detail,
+			LatencyMs: dep.Latency.Milliseconds(),
+		}
+	}
+	return resp
+}
+
+// MapRoleToProto converts ports.RoleDetails to a protobuf Role
+func MapRoleToProto(role *ports.RoleDetails) *pb.Role {
+	resp := &pb.Role{
+		Id:          role.Role.ID.String(),
+		Code:        role.Role.Code,
+		Name:        role.Role.Name,
+		Description: utils.PtrStringValue(role.Role.Description),
+		IsDefault:   role.IsDefault,
+		Permissions: make([]*pb.Permission, len(role.Permissions)),
+	}
+	for i, grant := range role.Permissions {
+		resp.Permissions[i] = &pb.Permission{
+			ResourceCode: grant.ResourceCode,
+			ResourceName: grant.ResourceName,
+			Actions:      grant.Actions,
+		}
+	}
+	return resp
+}
+
+// userSortFields maps the protobuf sort fields to domain sort fields
+var userSortFields = map[pb.UserSortField]string{
+	pb.UserSortField_USER_SORT_FIELD_UNSPECIFIED: domain.UserSortCreatedAt,
+	pb.UserSortField_USER_SORT_FIELD_CREATED_AT:  domain.UserSortCreatedAt,
+	pb.UserSortField_USER_SORT_FIELD_LAST_LOGIN:  domain.UserSortLastLogin,
+	pb.UserSortField_USER_SORT_FIELD_USERNAME:    domain.UserSortUsername,
+}
+
+// mapCustomClaimsToProto JSON-encodes the custom claims of a validated token
+// A value that cannot be encoded is left out rather than failing the validation
+func mapCustomClaimsToProto(claims map[string]any) map[string]string {
+	if len(claims) == 0 {
+		return nil
 	}
+	encoded := make(map[string]string, len(claims))
+	for name, value := range claims {
+		if b, err := json.Marshal(value); err == nil {
+			encoded[name] = string(b)
+		}
+	}
+	return encoded
+}
+
+// mapAvailabilityToProto converts availability check results to protobuf
+func mapAvailabilityToProto(results []domain.Availability) []*pb.Availability {
+	availability := make([]*pb.Availability, len(results))
+	for i, result := range results {
+		availability[i] = &pb.Availability{
+			Value:     result.Value,
+			Available: result.Available,
+		}
+	}
+	return availability
+}
+
+// mapDecodedTokenToProto converts a domain.DecodedToken to a protobuf DecodeTokenResponse
+func mapDecodedTokenToProto(decoded *domain.DecodedToken) *pb.DecodeTokenResponse {
+	return &pb.DecodeTokenResponse{
+		Algorithm: decoded.Algorithm,
+		UnverifiedClaims: &pb.UnverifiedClaims{
+			Sub:      decoded.Subject,
+			Username: decoded.Username,
+			Role:     decoded.Role,
+			Roles:    decoded.Roles,
+			Iat:      timeToUnix(decoded.IssuedAt),
+			Exp:      timeToUnix(decoded.ExpiresAt),
+			Nbf:      timeToUnix(decoded.NotBefore),
+			Iss:      decoded.Issuer,
+			Aud:      decoded.Audience,
+			Jti:      decoded.ID,
+			TenantId: decoded.TenantID,
+			Sid:      decoded.SessionID,
+		},
+	}
+}
+
+// timeToUnix returns t in Unix seconds, or 0 when t is the zero time
+func timeToUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.Unix()
+}
+
+// mapListUsersRequest converts a protobuf ListUsersRequest to a domain.UserListQuery
+// Enum values unknown to this server are rejected with InvalidArgument
+func mapListUsersRequest(req *pb.ListUsersRequest) (*domain.UserListQuery, error) {
+	sortField, ok := userSortFields[req.SortField]
+	if !ok {
+		return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidArgument, "unknown sort_field", "sort_field")
+	}
+
+	query := &domain.UserListQuery{
+		Cursor:    req.Cursor,
+		PageSize:  req.PageSize,
+		SortField: sortField,
+	}
+
+	switch req.SortDirection {
+	case pb.SortDirection_SORT_DIRECTION_UNSPECIFIED, pb.SortDirection_SORT_DIRECTION_ASC:
+	case pb.SortDirection_SORT_DIRECTION_DESC:
+		query.Descending = true
+	default:
+		return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidArgument, "unknown sort_direction", "sort_direction")
+	}
+
+	switch req.Active {
+	case pb.ActiveFilter_ACTIVE_FILTER_ANY:
+	case pb.ActiveFilter_ACTIVE_FILTER_ACTIVE, pb.ActiveFilter_ACTIVE_FILTER_INACTIVE:
+		active := req.Active == pb.ActiveFilter_ACTIVE_FILTER_ACTIVE
+		query.IsActive = &active
+	default:
+		return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidArgument, "unknown active filter", "active")
+	}
+	return query, nil
+}
+
+// grpcCodes maps domain error codes to gRPC status codes
+// Codes missing from the table are reported as codes.Internal
+var grpcCodes = map[string]codes.Code{
+	domain.CodeUserNotFound:          codes.NotFound,
+	domain.CodeUserAlreadyExists:     codes.AlreadyExists,
+	domain.CodeUserInactive:          codes.Unauthenticated,
+	domain.CodeUserPendingApproval:   codes.Unauthenticated,
+	domain.CodeUserNotPending:        codes.FailedPrecondition,
+	domain.CodeUserReferenced:        codes.FailedPrecondition,
+	domain.CodeTooManySessions:       codes.ResourceExhausted,
+	domain.CodeUsernameChangeTooSoon: codes.FailedPrecondition,
+	domain.CodeInvalidCredentials:    codes.Unauthenticated,
+	domain.CodeLoginChallenged:       codes.FailedPrecondition,
+	domain.CodeLoginDenied:           codes.PermissionDenied,
+	domain.CodeLoginIdentifier:       codes.InvalidArgument,
+	domain.CodeCaptchaInvalid:        codes.InvalidArgument,
+	domain.CodeIncorrectPassword:     codes.Unauthenticated,
+	domain.CodeInvalidToken:          codes.Unauthenticated,
+	domain.CodeTokenExpired:          codes.Unauthenticated,
+	domain.CodeWrongTokenType:        codes.InvalidArgument,
+	domain.CodeSessionRevoked:        codes.Unauthenticated,
+	domain.CodeTokenReused:           codes.Unauthenticated,
+	domain.CodePasswordExpired:       codes.FailedPrecondition,
+	domain.CodePasswordResetRequired: codes.FailedPrecondition,
+	domain.CodeWeakPassword:          codes.InvalidArgument,
+	domain.CodePasswordReused:        codes.InvalidArgument,
+	domain.CodeResetCodeLocked:       codes.PermissionDenied,
+	domain.CodePermissionDenied:      codes.PermissionDenied,
+	domain.CodeResourceNotFound:      codes.NotFound,
+	domain.CodePermissionNotGranted:  codes.FailedPrecondition,
+	domain.CodeConfirmationMismatch:  codes.FailedPrecondition,
+	domain.CodeRateLimited:           codes.ResourceExhausted,
+	domain.CodeDeletionScheduled:     codes.FailedPrecondition,
+	domain.CodeDeletionNotScheduled:  codes.FailedPrecondition,
+	domain.CodeRoleNotFound:          codes.NotFound,
+	domain.CodeRoleNotAssigned:       codes.FailedPrecondition,
+	domain.CodeDefaultRoleNotFound:   codes.FailedPrecondition,
+	domain.CodeLastRole:              codes.FailedPrecondition,
+	domain.CodeRoleMissing:           codes.FailedPrecondition,
+	domain.CodeInvalidArgument:       codes.InvalidArgument,
+	domain.CodeTenantRequired:        codes.InvalidArgument,
+	domain.CodeInvalidTenant:         codes.InvalidArgument,
+	domain.CodeObjectNotFound:        codes.FailedPrecondition,
+	domain.CodeDatabaseUnavailable:   codes.Unavailable,
+	domain.CodeDeadlineExceeded:      codes.DeadlineExceeded,
+	domain.CodeCanceled:              codes.Canceled,
+	domain.CodeInternalError:         codes.Internal,
 }
 
 // MapDomainErrorToGRPC converts domain errors to gRPC status errors
+// The domain error code is attached as the ErrorInfo reason; context errors map to Canceled / DeadlineExceeded,
+// whether bare or reported by a repository call the context cut short (domain.CodeDeadlineExceeded / CodeCanceled)
 func MapDomainErrorToGRPC(err error) error {
 	if err == nil {
 		return nil
 	}
 
 	// Check for AuthError type
-	if authErr, ok := err.(*domain.AuthError); ok {
-		switch authErr.Code {
-		case domain.CodeUserNotFound:
-			return status.Error(codes.NotFound, authErr.Message)
-		case domain.CodeUserAlreadyExists:
-			return status.Error(codes.AlreadyExists, authErr.Message)
-		case domain.CodeInvalidCredentials, domain.CodeIncorrectPassword:
-			return status.Error(codes.Unauthenticated, authErr.Message)
-		case domain.CodeInvalidToken, domain.CodeTokenExpired:
-			return status.Error(codes.Unauthenticated, authErr.Message)
-		default:
-			return status.Error(codes.Internal, authErr.Message)
+	var authErr *domain.AuthError
+	if errors.As(err, &authErr) {
+		code, ok := grpcCodes[authErr.Code]
+		if !ok {
+			code = codes.Internal
 		}
+		return grpcerr.New(code, authErr.Code, authErr.Message, authErr.Field)
+	}
+
+	// The client cancelled or its deadline passed; not a server failure
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
 	}
 
-	// Default to internal error for unknown error types
+	// Unknown error types may wrap internal details; interceptor.Sanitize decides what the client sees
 	return status.Error(codes.Internal, err.Error())
 }
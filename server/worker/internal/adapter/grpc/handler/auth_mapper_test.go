@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+func TestMapDomainErrorToGRPC(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want codes.Code
+	}{
+		{
+			name: "default role not seeded",
+			err:  domain.NewAuthError(domain.ErrDefaultRoleNotFound, "no default role is configured", domain.CodeDefaultRoleNotFound),
+			want: codes.FailedPrecondition,
+		},
+		{
+			name: "database error",
+			err:  domain.NewAuthError(domain.ErrDatabaseOperation, "failed to assign default role", domain.CodeInternalError),
+			want: codes.Internal,
+		},
+		{
+			name: "captcha rejected",
+			err:  domain.NewAuthError(domain.ErrCaptchaInvalid, "captcha verification failed", domain.CodeCaptchaInvalid).WithField("captcha_token"),
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "captcha provider unreachable",
+			err:  domain.NewAuthError(domain.ErrCaptchaInvalid, "failed to verify captcha", domain.CodeInternalError),
+			want: codes.Internal,
+		},
+		{
+			name: "password reset required",
+			err:  domain.NewAuthError(domain.ErrPasswordResetRequired, "password must be reset", domain.CodePasswordResetRequired),
+			want: codes.FailedPrecondition,
+		},
+		{
+			name: "client cancelled",
+			err:  context.Canceled,
+			want: codes.Canceled,
+		},
+		{
+			name: "deadline exceeded",
+			err:  fmt.Errorf("create user: %w", context.DeadlineExceeded),
+			want: codes.DeadlineExceeded,
+		},
+		{
+			name: "wrapped client cancelled",
+			err:  fmt.Errorf("list users: %w", context.Canceled),
+			want: codes.Canceled,
+		},
+		{
+			name: "query deadline exceeded",
+			err:  domain.NewAuthError(context.DeadlineExceeded, "failed to find user: deadline exceeded", domain.CodeDeadlineExceeded),
+			want: codes.DeadlineExceeded,
+		},
+		{
+			name: "query cancelled",
+			err:  domain.NewAuthError(context.Canceled, "failed to find user: request cancelled", domain.CodeCanceled),
+			want: codes.Canceled,
+		},
+		{
+			name: "login identifier not accepted",
+			err:  domain.NewAuthError(domain.ErrLoginIdentifier, "sign in with your email address", domain.CodeLoginIdentifier),
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "role not found",
+			err:  domain.NewAuthError(domain.ErrRoleNotFound, `role "DEAN" not found`, domain.CodeRoleNotFound),
+			want: codes.NotFound,
+		},
+		{
+			name: "reset code locked",
+			err:  domain.NewAuthError(domain.ErrResetCodeLocked, "password reset code is locked after too many attempts", domain.CodeResetCodeLocked),
+			want: codes.PermissionDenied,
+		},
+		{
+			name: "wrong token type",
+			err:  domain.NewAuthError(domain.ErrWrongTokenType, "a refresh token was sent where an access token is expected", domain.CodeWrongTokenType),
+			want: codes.InvalidArgument,
+		},
+		{
+			name: "unmapped code",
+			err:  domain.NewAuthError(errors.New("boom"), "boom", "NO_SUCH_CODE"),
+			want: codes.Internal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(MapDomainErrorToGRPC(tt.err)); got != tt.want {
+				t.Fatalf("MapDomainErrorToGRPC(%v) = %s, want %s", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMapContextErrorsToGRPC(t *testing.T) {
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancelExpired()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name       string
+		err        error
+		want       codes.Code
+		wantReason string
+	}{
+		{name: "expired context", err: expired.Err(), want: codes.DeadlineExceeded},
+		{name: "cancelled context", err: cancelled.Err(), want: codes.Canceled},
+		// Reported by a repository call, the domain code is kept as the reason
+		{
+			name:       "query deadline exceeded",
+			err:        domain.NewAuthError(expired.Err(), "failed to find user: deadline exceeded", domain.CodeDeadlineExceeded),
+			want:       codes.DeadlineExceeded,
+			wantReason: domain.CodeDeadlineExceeded,
+		},
+		{
+			name:       "query cancelled",
+			err:        domain.NewAuthError(cancelled.Err(), "failed to find user: request cancelled", domain.CodeCanceled),
+			want:       codes.Canceled,
+			wantReason: domain.CodeCanceled,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := status.Convert(MapDomainErrorToGRPC(tt.err))
+			if st.Code() != tt.want {
+				t.Fatalf("MapDomainErrorToGRPC(%v) = %s, want %s", tt.err, st.Code(), tt.want)
+			}
+			reason := ""
+			for _, detail := range st.Details() {
+				if info, ok := detail.(*errdetails.ErrorInfo); ok {
+					reason = info.Reason
+				}
+			}
+			if reason != tt.wantReason {
+				t.Fatalf("reason = %q, want %q", reason, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestMapRoleToProto(t *testing.T) {
+	description := "Teaching staff"
+	role := MapRoleToProto(&ports.RoleDetails{
+		Role:        sqlc.Role{ID: uuid.New(), Code: "LECTURER", Name: "Lecturer", Description: &description},
+		Permissions: []domain.PermissionGrant{{ResourceCode: "users", ResourceName: "Users", Actions: []string{"READ", "UPDATE"}}},
+		IsDefault:   true,
+	})
+	if role.Code != "LECTURER" || role.Name != "Lecturer" || role.Description != description || !role.IsDefault {
+		t.Fatalf("role = %+v, want LECTURER flagged as the default", role)
+	}
+	if len(role.Permissions) != 1 || role.Permissions[0].ResourceCode != "users" ||
+		role.Permissions[0].ResourceName != "Users" || len(role.Permissions[0].Actions) != 2 {
+		t.Fatalf("permissions = %v, want users READ and UPDATE", role.Permissions)
+	}
+
+	// A role without a description or permissions maps to empty values
+	role = MapRoleToProto(&ports.RoleDetails{Role: sqlc.Role{ID: uuid.New(), Code: "STUDENT"}, Permissions: []domain.PermissionGrant{}})
+	if role.Description != "" || len(role.Permissions) != 0 || role.IsDefault {
+		t.Fatalf("role = %+v, want no description, permissions or default flag", role)
+	}
+}
+
+func TestMapCustomClaimsToProto(t *testing.T) {
+	encoded := mapCustomClaimsToProto(map[string]any{
+		"org_id": "org-1",
+		"flags":  []any{"beta"},
+		"seats":  float64(20),
+		// Not representable in JSON, so left out rather than failing the validation
+		"broken": math.Inf(1),
+	})
+	want := map[string]string{"org_id": `"org-1"`, "flags": `["beta"]`, "seats": "20"}
+	if !maps.Equal(encoded, want) {
+		t.Fatalf("custom claims = %v, want %v", encoded, want)
+	}
+
+	if encoded := mapCustomClaimsToProto(nil); encoded != nil {
+		t.Fatalf("no custom claims = %v, want nil", encoded)
+	}
+}
+
+func TestMapDecodedTokenToProto(t *testing.T) {
+	issuedAt := time.Unix(1700000000, 0)
+	resp := mapDecodedTokenToProto(&domain.DecodedToken{
+		Algorithm: "HS256",
+		Subject:   "user-1",
+		Roles:     []string{"ADMIN"},
+		Audience:  []string{"gateway"},
+		ID:        "token-1",
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(time.Hour),
+	})
+	claims := resp.UnverifiedClaims
+	if resp.Algorithm != "HS256" || claims.Sub != "user-1" || claims.Jti != "token-1" ||
+		len(claims.Roles) != 1 || len(claims.Aud) != 1 {
+		t.Fatalf("response = %+v, want the decoded claims", resp)
+	}
+	// Claims the token lacks are 0, not the Unix time of the zero time
+	if claims.Iat != issuedAt.Unix() || claims.Exp != issuedAt.Add(time.Hour).Unix() || claims.Nbf != 0 {
+		t.Fatalf("iat %d, exp %d, nbf %d; want %d, %d and 0", claims.Iat, claims.Exp, claims.Nbf, issuedAt.Unix(), issuedAt.Add(time.Hour).Unix())
+	}
+}
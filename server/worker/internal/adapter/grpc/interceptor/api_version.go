@@ -0,0 +1,53 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/adapter/grpc/grpcmd"
+	"worker/internal/adapter/logger"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	pb "worker/pb"
+)
+
+// APIVersion returns a unary interceptor that stores the x-api-version metadata value in the request context.
+// Versions outside GRPC_API_VERSIONS are rejected with codes.FailedPrecondition. A request without the header
+// gets GRPC_DEFAULT_API_VERSION and a deprecation warning, or is rejected when no default is configured.
+// Ping is exempt, so connectivity checks work for every client.
+func APIVersion(cfg *config.GRPCConfig, base *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		// Ping must answer whatever client version is asking, that is its whole point
+		if info.FullMethod == pb.AuthService_Ping_FullMethodName {
+			return handler(ctx, req)
+		}
+
+		value, ok, err := grpcmd.FromContext(ctx).Single(grpcmd.APIVersionMetadataKey)
+		if !ok {
+			if cfg.DefaultAPIVersion == "" {
+				return nil, grpcerr.New(codes.FailedPrecondition, "API_VERSION_REQUIRED",
+					fmt.Sprintf("%s is required (supported: %s)", grpcmd.APIVersionMetadataKey, strings.Join(cfg.APIVersions, ", ")),
+					grpcmd.APIVersionMetadataKey)
+			}
+			logger.FromContext(ctx, base).Warn("Request without API version is deprecated, assuming the default",
+				zap.String("method", info.FullMethod),
+				zap.String("api_version", cfg.DefaultAPIVersion))
+			return handler(domain.WithAPIVersion(ctx, cfg.DefaultAPIVersion), req)
+		}
+
+		version := strings.ToLower(strings.TrimSpace(value))
+		if err != nil || !slices.Contains(cfg.APIVersions, version) {
+			return nil, grpcerr.New(codes.FailedPrecondition, "UNSUPPORTED_API_VERSION",
+				fmt.Sprintf("%s must be one of: %s", grpcmd.APIVersionMetadataKey, strings.Join(cfg.APIVersions, ", ")),
+				grpcmd.APIVersionMetadataKey)
+		}
+		return handler(domain.WithAPIVersion(ctx, version), req)
+	}
+}
@@ -0,0 +1,89 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/adapter/grpc/grpcmd"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	pb "worker/pb"
+)
+
+// callAPIVersion runs method through the APIVersion interceptor with the given x-api-version values
+// and returns the version the handler saw, or "" when the handler did not run
+func callAPIVersion(cfg *config.GRPCConfig, method string, versions ...string) (string, error) {
+	ctx := context.Background()
+	if len(versions) > 0 {
+		md := metadata.MD{}
+		md.Append(grpcmd.APIVersionMetadataKey, versions...)
+		ctx = metadata.NewIncomingContext(ctx, md)
+	}
+	seen := ""
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		seen, _ = domain.APIVersionFromContext(ctx)
+		if seen == "" {
+			seen = "(none)"
+		}
+		return nil, nil
+	}
+	_, err := APIVersion(cfg, zap.NewNop())(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	return seen, err
+}
+
+func TestAPIVersion(t *testing.T) {
+	withDefault := &config.GRPCConfig{APIVersions: []string{"v1", "v2"}, DefaultAPIVersion: "v1"}
+	noDefault := &config.GRPCConfig{APIVersions: []string{"v1", "v2"}}
+
+	tests := []struct {
+		name       string
+		cfg        *config.GRPCConfig
+		method     string
+		versions   []string
+		want       string // Version the handler sees, "" when it must not run
+		wantReason string
+	}{
+		{name: "supported version", cfg: withDefault, versions: []string{"v2"}, want: "v2"},
+		{name: "version is normalized", cfg: withDefault, versions: []string{" V2 "}, want: "v2"},
+		{name: "missing version gets the default", cfg: withDefault, want: "v1"},
+		{name: "missing version without a default", cfg: noDefault, wantReason: "API_VERSION_REQUIRED"},
+		{name: "unsupported version", cfg: withDefault, versions: []string{"v3"}, wantReason: "UNSUPPORTED_API_VERSION"},
+		// The default only covers a missing header, not an empty or unknown one
+		{name: "empty version", cfg: withDefault, versions: []string{""}, wantReason: "UNSUPPORTED_API_VERSION"},
+		{name: "repeated version", cfg: withDefault, versions: []string{"v1", "v2"}, wantReason: "UNSUPPORTED_API_VERSION"},
+		{name: "ping is exempt", cfg: noDefault, method: pb.AuthService_Ping_FullMethodName, versions: []string{"v9"}, want: "(none)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			method := tt.method
+			if method == "" {
+				method = pb.AuthService_Login_FullMethodName
+			}
+			seen, err := callAPIVersion(tt.cfg, method, tt.versions...)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if seen != tt.want {
+					t.Fatalf("handler saw version %q, want %q", seen, tt.want)
+				}
+				return
+			}
+			if seen != "" {
+				t.Fatal("handler ran for a rejected version")
+			}
+			if status.Code(err) != codes.FailedPrecondition {
+				t.Fatalf("err = %v, want FailedPrecondition", err)
+			}
+			if got := errorReason(err); got != tt.wantReason {
+				t.Fatalf("reason = %q, want %q", got, tt.wantReason)
+			}
+		})
+	}
+}
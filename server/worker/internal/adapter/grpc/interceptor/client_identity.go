@@ -0,0 +1,132 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/x509"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/adapter/grpc/grpcmd"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	pb "worker/pb"
+)
+
+// internalMethods are service-to-service methods; with mTLS enabled only allowlisted client certificates may call them
+var internalMethods = map[string]bool{
+	pb.AuthService_ValidateToken_FullMethodName:       true,
+	pb.AuthService_ValidateTokensBatch_FullMethodName: true,
+	pb.AuthService_IntrospectToken_FullMethodName:     true,
+	pb.AuthService_CheckPermission_FullMethodName:     true,
+	pb.AuthService_WatchRevocations_FullMethodName:    true,
+}
+
+// ClientIdentity returns a unary interceptor that authorizes internal methods by client certificate.
+// The identity of a verified certificate found in GRPC_TLS_ALLOWED_CLIENTS is stored in the request context;
+// internal methods are refused without one. Without GRPC_TLS_CLIENT_CA_FILE every call passes through,
+// though IntrospectToken and WatchRevocations are then refused by their handlers, which always require a
+// service identity.
+func ClientIdentity(cfg *config.GRPCConfig) grpc.UnaryServerInterceptor {
+	allowed := allowedClients(cfg)
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if allowed == nil {
+			return handler(ctx, req)
+		}
+		ctx, err := authorizeClient(ctx, allowed, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// ClientIdentityStream is ClientIdentity for streaming methods
+func ClientIdentityStream(cfg *config.GRPCConfig) grpc.StreamServerInterceptor {
+	allowed := allowedClients(cfg)
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if allowed == nil {
+			return handler(srv, ss)
+		}
+		ctx, err := authorizeClient(ss.Context(), allowed, info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &identityStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// allowedClients returns the allowlist as a set, or nil when mTLS is disabled
+func allowedClients(cfg *config.GRPCConfig) map[string]bool {
+	if cfg.TLSClientCAFile == "" {
+		return nil
+	}
+	allowed := make(map[string]bool, len(cfg.TLSAllowedClients))
+	for _, identity := range cfg.TLSAllowedClients {
+		allowed[identity] = true
+	}
+	return allowed
+}
+
+// authorizeClient stores the caller's service identity in ctx and refuses internal methods without one
+func authorizeClient(ctx context.Context, allowed map[string]bool, method string) (context.Context, error) {
+	cert := peerCertificate(ctx)
+	if cert != nil {
+		if identity := certificateIdentity(cert, allowed); identity != "" {
+			return domain.WithServiceIdentity(ctx, identity), nil
+		}
+	}
+	if !internalMethods[method] {
+		return ctx, nil
+	}
+	if cert == nil {
+		return ctx, grpcerr.New(codes.Unauthenticated, domain.CodePermissionDenied,
+			"a client certificate is required for internal methods", "")
+	}
+	return ctx, grpcerr.New(codes.PermissionDenied, domain.CodePermissionDenied,
+		"client certificate is not allowed to call internal methods", "")
+}
+
+// peerCertificate returns the verified leaf certificate of the caller, or nil
+// Certificates the TLS handshake did not verify against the client CA are ignored
+func peerCertificate(ctx context.Context) *x509.Certificate {
+	p := grpcmd.FromContext(ctx).Peer()
+	if p == nil {
+		return nil
+	}
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil
+	}
+	return tlsInfo.State.VerifiedChains[0][0]
+}
+
+// certificateIdentity returns the first allowlisted name of cert: its common name, a DNS SAN or a URI SAN
+func certificateIdentity(cert *x509.Certificate, allowed map[string]bool) string {
+	if allowed[cert.Subject.CommonName] {
+		return cert.Subject.CommonName
+	}
+	for _, name := range cert.DNSNames {
+		if allowed[name] {
+			return name
+		}
+	}
+	for _, uri := range cert.URIs {
+		if allowed[uri.String()] {
+			return uri.String()
+		}
+	}
+	return ""
+}
+
+// identityStream overrides the context of a server stream
+type identityStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *identityStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,197 @@
+package interceptor
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	pb "worker/pb"
+)
+
+// testCA issues client certificates for the tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	t.Helper()
+	key := newTestKey(t)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	return &testCA{cert: createCertificate(t, template, template, key, key), key: key}
+}
+
+// issue returns a client certificate signed by the CA, with the names set by name
+func (ca *testCA) issue(t *testing.T, name func(*x509.Certificate)) *x509.Certificate {
+	t.Helper()
+	template := clientTemplate(name)
+	return createCertificate(t, template, ca.cert, newTestKey(t), ca.key)
+}
+
+// verifiedChains verifies cert against the CA the way the TLS handshake does for client certificates
+func (ca *testCA) verifiedChains(t *testing.T, cert *x509.Certificate) [][]*x509.Certificate {
+	t.Helper()
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	chains, err := cert.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	if err != nil {
+		t.Fatalf("verify client certificate: %v", err)
+	}
+	return chains
+}
+
+// selfSigned returns a client certificate that no CA vouches for
+func selfSigned(t *testing.T, name func(*x509.Certificate)) *x509.Certificate {
+	t.Helper()
+	key := newTestKey(t)
+	template := clientTemplate(name)
+	return createCertificate(t, template, template, key, key)
+}
+
+func clientTemplate(name func(*x509.Certificate)) *x509.Certificate {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	name(template)
+	return template
+}
+
+func newTestKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func createCertificate(t *testing.T, template, parent *x509.Certificate, key, parentKey *ecdsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return cert
+}
+
+// withPeer returns a context for a TLS caller that presented cert, whose verified chains are chains
+func withPeer(cert *x509.Certificate, chains [][]*x509.Certificate) context.Context {
+	state := tls.ConnectionState{VerifiedChains: chains}
+	if cert != nil {
+		state.PeerCertificates = []*x509.Certificate{cert}
+	}
+	return peer.NewContext(context.Background(), &peer.Peer{
+		Addr:     &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 443},
+		AuthInfo: credentials.TLSInfo{State: state},
+	})
+}
+
+// callClientIdentity runs an internal method through the ClientIdentity interceptor
+// and returns the service identity the handler saw
+func callClientIdentity(ctx context.Context) (string, bool, error) {
+	cfg := &config.GRPCConfig{
+		TLSClientCAFile:   "ca.pem",
+		TLSAllowedClients: []string{"gateway", "billing.internal", "spiffe://cluster/notifier"},
+	}
+	var identity string
+	var called bool
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		identity, called = domain.ServiceIdentityFromContext(ctx)
+		return nil, nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_IntrospectToken_FullMethodName}
+	_, err := ClientIdentity(cfg)(ctx, nil, info, handler)
+	return identity, called, err
+}
+
+func TestClientIdentityAllowsListedNames(t *testing.T) {
+	ca := newTestCA(t)
+	tests := []struct {
+		name string
+		set  func(*x509.Certificate)
+		want string
+	}{
+		{name: "common name", set: func(c *x509.Certificate) { c.Subject.CommonName = "gateway" }, want: "gateway"},
+		{name: "DNS SAN", set: func(c *x509.Certificate) {
+			c.Subject.CommonName = "billing"
+			c.DNSNames = []string{"billing.internal"}
+		}, want: "billing.internal"},
+		{name: "URI SAN", set: func(c *x509.Certificate) {
+			c.URIs = []*url.URL{{Scheme: "spiffe", Host: "cluster", Path: "/notifier"}}
+		}, want: "spiffe://cluster/notifier"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := ca.issue(t, tt.set)
+			identity, ok, err := callClientIdentity(withPeer(cert, ca.verifiedChains(t, cert)))
+			if err != nil {
+				t.Fatalf("call: %v", err)
+			}
+			if !ok || identity != tt.want {
+				t.Fatalf("service identity = %q (%t), want %q", identity, ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientIdentityRefuses(t *testing.T) {
+	ca := newTestCA(t)
+	unknown := ca.issue(t, func(c *x509.Certificate) { c.Subject.CommonName = "intruder" })
+	// A certificate naming an allowed client that the handshake did not verify carries no identity
+	forged := selfSigned(t, func(c *x509.Certificate) { c.Subject.CommonName = "gateway" })
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		want codes.Code
+	}{
+		{name: "unknown certificate", ctx: withPeer(unknown, ca.verifiedChains(t, unknown)), want: codes.PermissionDenied},
+		{name: "no certificate", ctx: withPeer(nil, nil), want: codes.Unauthenticated},
+		{name: "no peer", ctx: context.Background(), want: codes.Unauthenticated},
+		{name: "unverified chain", ctx: withPeer(forged, nil), want: codes.Unauthenticated},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, called, err := callClientIdentity(tt.ctx)
+			if called {
+				t.Fatal("handler ran for a caller without an allowed identity")
+			}
+			if status.Code(err) != tt.want {
+				t.Fatalf("err = %v, want %s", err, tt.want)
+			}
+		})
+	}
+}
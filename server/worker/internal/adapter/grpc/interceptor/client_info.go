@@ -0,0 +1,29 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"worker/internal/adapter/grpc/grpcmd"
+	"worker/internal/core/domain"
+)
+
+// ClientInfo returns a unary interceptor that stores the caller's IP address, user agent, country and
+// accept-language in the request context.
+// The forwarded values are preferred; without them the peer address and the user-agent and
+// accept-language metadata are used.
+// Missing or malformed values are left empty, they never fail the request.
+func ClientInfo() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		md := grpcmd.FromContext(ctx)
+		client := domain.ClientInfo{
+			IP:        md.ClientIP(),
+			UserAgent: md.UserAgent(),
+			Country:   md.Country(),
+
+			AcceptLanguage: md.AcceptLanguage(),
+		}
+		return handler(domain.WithClientInfo(ctx, client), req)
+	}
+}
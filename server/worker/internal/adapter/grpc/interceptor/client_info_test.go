@@ -0,0 +1,53 @@
+package interceptor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"worker/internal/adapter/grpc/grpcmd"
+	"worker/internal/core/domain"
+)
+
+// clientInfoOf runs the ClientInfo interceptor over md and returns the client the handler saw
+func clientInfoOf(t *testing.T, md metadata.MD) domain.ClientInfo {
+	t.Helper()
+	var client domain.ClientInfo
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		client = domain.ClientInfoFromContext(ctx)
+		return nil, nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), md)
+	if _, err := ClientInfo()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/auth.AuthService/Register"}, handler); err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+	return client
+}
+
+func TestClientInfoAcceptLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		md   metadata.MD
+		want string
+	}{
+		{name: "none", md: metadata.MD{}},
+		{name: "caller's own", md: metadata.Pairs("accept-language", "vi-VN,vi;q=0.9"), want: "vi-VN,vi;q=0.9"},
+		{
+			// The gateway forwards the end user's header, which wins over the gateway's own
+			name: "forwarded",
+			md:   metadata.Pairs("accept-language", "en", grpcmd.ForwardedLanguageMetadataKey, " vi "),
+			want: "vi",
+		},
+		{name: "overlong", md: metadata.Pairs("accept-language", strings.Repeat("en,", 100))},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clientInfoOf(t, tt.md).AcceptLanguage; got != tt.want {
+				t.Fatalf("accept-language = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package interceptor
+
+import (
+	"context"
+	"slices"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	_ "google.golang.org/grpc/encoding/gzip" // registers gzip so clients may compress requests
+	"google.golang.org/protobuf/proto"
+
+	"worker/internal/config"
+)
+
+// Compression returns a unary interceptor that picks the response compressor per call.
+// Responses smaller than CompressionMinSize are sent uncompressed; larger ones use
+// SendCompressor when the client advertises it, otherwise grpc-go's default applies
+// (the compressor the request was sent with).
+func Compression(cfg *config.GRPCConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err != nil {
+			return resp, err
+		}
+		msg, ok := resp.(proto.Message)
+		if !ok {
+			return resp, nil
+		}
+
+		// Failing to pick a compressor is not worth failing the call over
+		if proto.Size(msg) < cfg.CompressionMinSize {
+			_ = grpc.SetSendCompressor(ctx, encoding.Identity)
+		} else if cfg.SendCompressor != "" && clientAccepts(ctx, cfg.SendCompressor) {
+			_ = grpc.SetSendCompressor(ctx, cfg.SendCompressor)
+		}
+		return resp, nil
+	}
+}
+
+// clientAccepts reports whether the client listed name in grpc-accept-encoding
+func clientAccepts(ctx context.Context, name string) bool {
+	supported, err := grpc.ClientSupportedCompressors(ctx)
+	return err == nil && slices.Contains(supported, name)
+}
@@ -0,0 +1,119 @@
+package interceptor
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/stats"
+	"google.golang.org/grpc/test/bufconn"
+
+	"worker/internal/config"
+	pb "worker/pb"
+)
+
+// echoServer answers Ping with the request's message, so tests choose the response size
+type echoServer struct {
+	pb.UnimplementedAuthServiceServer
+}
+
+func (echoServer) Ping(ctx context.Context, req *pb.PingRequest) (*pb.PingResponse, error) {
+	return &pb.PingResponse{Message: req.Message}, nil
+}
+
+// responseEncoding records the grpc-encoding of the responses a client receives
+type responseEncoding struct {
+	mu       sync.Mutex
+	encoding string
+}
+
+func (r *responseEncoding) TagRPC(ctx context.Context, _ *stats.RPCTagInfo) context.Context {
+	return ctx
+}
+
+func (r *responseEncoding) TagConn(ctx context.Context, _ *stats.ConnTagInfo) context.Context {
+	return ctx
+}
+
+func (r *responseEncoding) HandleConn(context.Context, stats.ConnStats) {}
+
+func (r *responseEncoding) HandleRPC(ctx context.Context, s stats.RPCStats) {
+	if header, ok := s.(*stats.InHeader); ok && header.Client {
+		r.mu.Lock()
+		r.encoding = header.Compression
+		r.mu.Unlock()
+	}
+}
+
+func (r *responseEncoding) get() string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.encoding
+}
+
+// newCompressionClient serves echoServer through the Compression interceptor over an in-memory listener
+func newCompressionClient(t *testing.T, cfg *config.GRPCConfig) (pb.AuthServiceClient, *responseEncoding) {
+	t.Helper()
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(grpc.UnaryInterceptor(Compression(cfg)))
+	pb.RegisterAuthServiceServer(server, echoServer{})
+	go server.Serve(listener)
+	t.Cleanup(server.Stop)
+
+	encoding := &responseEncoding{}
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithStatsHandler(encoding),
+	)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return pb.NewAuthServiceClient(conn), encoding
+}
+
+func TestCompressionThreshold(t *testing.T) {
+	const minSize = 1024
+	tests := []struct {
+		name           string
+		sendCompressor string
+		size           int
+		callOptions    []grpc.CallOption
+		want           string
+	}{
+		{name: "small response", sendCompressor: gzip.Name, size: 64, want: ""},
+		{name: "just below the threshold", sendCompressor: gzip.Name, size: minSize - 8, want: ""},
+		{name: "large response", sendCompressor: gzip.Name, size: 4 * minSize, want: gzip.Name},
+		// A small response stays uncompressed even when the request itself was compressed
+		{name: "small response to a gzip request", sendCompressor: gzip.Name, size: 64, callOptions: []grpc.CallOption{grpc.UseCompressor(gzip.Name)}, want: ""},
+		{name: "no send compressor", size: 4 * minSize, want: ""},
+		// Without a send compressor grpc-go answers in the request's encoding
+		{name: "no send compressor, gzip request", size: 4 * minSize, callOptions: []grpc.CallOption{grpc.UseCompressor(gzip.Name)}, want: gzip.Name},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, encoding := newCompressionClient(t, &config.GRPCConfig{SendCompressor: tt.sendCompressor, CompressionMinSize: minSize})
+			message := strings.Repeat("a", tt.size)
+			resp, err := client.Ping(context.Background(), &pb.PingRequest{Message: message}, tt.callOptions...)
+			if err != nil {
+				t.Fatalf("ping: %v", err)
+			}
+			if resp.Message != message {
+				t.Fatal("response message was altered")
+			}
+			got := encoding.get()
+			if got == "identity" {
+				got = ""
+			}
+			if got != tt.want {
+				t.Fatalf("response encoding = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package interceptor
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"worker/internal/adapter/grpc/grpcmd"
+	"worker/internal/adapter/logger"
+	"worker/internal/common/correlation"
+)
+
+// Correlation returns a unary interceptor that propagates the x-correlation-id metadata.
+// A missing or malformed ID is replaced by a generated one. The ID is stored in the context,
+// echoed in the response header and attached to the request-scoped logger.
+func Correlation(base *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		id := grpcmd.FromContext(ctx).CorrelationID()
+		if id == "" {
+			id = correlation.NewID()
+		}
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs(correlation.MetadataKey, id))
+
+		ctx = correlation.WithID(ctx, id)
+		ctx = logger.WithContext(ctx, base.With(
+			zap.String("correlation_id", id),
+			zap.String("method", info.FullMethod),
+		))
+		return handler(ctx, req)
+	}
+}
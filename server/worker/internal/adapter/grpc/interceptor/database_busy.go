@@ -0,0 +1,33 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/common/acquire"
+)
+
+// DatabaseBusy returns a unary interceptor that reports a saturated connection pool as codes.ResourceExhausted.
+// A call failing with Internal, Unknown or DeadlineExceeded after one of its queries timed out waiting for
+// a connection is rejected with DATABASE_BUSY, so clients can back off and retry instead of seeing a server fault.
+// Domain errors such as NotFound pass through, the query that timed out did not decide them.
+func DatabaseBusy() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = acquire.Track(ctx)
+		resp, err := handler(ctx, req)
+		if err == nil || !acquire.TimedOut(ctx) {
+			return resp, err
+		}
+
+		switch status.Code(err) {
+		case codes.Internal, codes.Unknown, codes.DeadlineExceeded:
+			return nil, grpcerr.New(codes.ResourceExhausted, "DATABASE_BUSY",
+				"the database is busy, try again later", "")
+		}
+		return resp, err
+	}
+}
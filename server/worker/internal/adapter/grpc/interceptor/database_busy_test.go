@@ -0,0 +1,46 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/common/acquire"
+	pb "worker/pb"
+)
+
+func TestDatabaseBusy(t *testing.T) {
+	tests := []struct {
+		name       string
+		timedOut   bool
+		err        error
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{name: "acquire timed out", timedOut: true, err: status.Error(codes.Internal, "database error"), wantCode: codes.ResourceExhausted, wantReason: "DATABASE_BUSY"},
+		{name: "deadline after a timed out acquire", timedOut: true, err: status.Error(codes.DeadlineExceeded, "deadline"), wantCode: codes.ResourceExhausted, wantReason: "DATABASE_BUSY"},
+		{name: "unknown after a timed out acquire", timedOut: true, err: status.Error(codes.Unknown, "unknown"), wantCode: codes.ResourceExhausted, wantReason: "DATABASE_BUSY"},
+		// Domain errors were decided without the query that timed out
+		{name: "domain error", timedOut: true, err: status.Error(codes.NotFound, "user not found"), wantCode: codes.NotFound},
+		{name: "no acquire timeout", err: status.Error(codes.Internal, "database error"), wantCode: codes.Internal},
+		{name: "success", timedOut: true, wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+				if tt.timedOut {
+					acquire.MarkTimedOut(ctx)
+				}
+				return nil, tt.err
+			}
+			info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_Login_FullMethodName}
+			_, err := DatabaseBusy()(context.Background(), nil, info, handler)
+			if status.Code(err) != tt.wantCode || errorReason(err) != tt.wantReason {
+				t.Fatalf("error = %v (reason %q), want %s with reason %q", err, errorReason(err), tt.wantCode, tt.wantReason)
+			}
+		})
+	}
+}
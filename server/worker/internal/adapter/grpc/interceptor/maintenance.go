@@ -0,0 +1,58 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/config"
+	pb "worker/pb"
+)
+
+// readOnlyMethods stay available in maintenance mode, every other AuthService method is rejected,
+// so a new RPC is blocked until it is listed here
+// Login and RefreshToken only touch session bookkeeping and stay available so users remain signed in
+var readOnlyMethods = map[string]bool{
+	pb.AuthService_Login_FullMethodName:                  true,
+	pb.AuthService_RefreshToken_FullMethodName:           true,
+	pb.AuthService_ValidateToken_FullMethodName:          true,
+	pb.AuthService_ValidateTokensBatch_FullMethodName:    true,
+	pb.AuthService_IntrospectToken_FullMethodName:        true,
+	pb.AuthService_DecodeToken_FullMethodName:            true,
+	pb.AuthService_CheckPermission_FullMethodName:        true,
+	pb.AuthService_GetMyPermissions_FullMethodName:       true,
+	pb.AuthService_CheckAvailabilityBatch_FullMethodName: true,
+	pb.AuthService_ListRoles_FullMethodName:              true,
+	pb.AuthService_GetRole_FullMethodName:                true,
+	pb.AuthService_ListUsers_FullMethodName:              true,
+	pb.AuthService_ListPendingApprovals_FullMethodName:   true,
+	pb.AuthService_GetStats_FullMethodName:               true,
+	pb.AuthService_GetDBStats_FullMethodName:             true,
+	pb.AuthService_GetHealth_FullMethodName:              true,
+	pb.AuthService_Ping_FullMethodName:                   true,
+}
+
+// authServicePrefix starts the full method names of AuthService; other services, like the gRPC
+// health check, are not affected by maintenance mode
+var authServicePrefix = "/" + pb.AuthService_ServiceDesc.ServiceName + "/"
+
+// Maintenance returns a unary interceptor that rejects the AuthService methods not in readOnlyMethods
+// with codes.Unavailable while MAINTENANCE_MODE is on. The flag is read per call, so a reload toggles it immediately.
+func Maintenance(reloader *config.Reloader) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if reloader.Current().MaintenanceMode && blockedInMaintenance(info.FullMethod) {
+			return nil, grpcerr.New(codes.Unavailable, "MAINTENANCE_MODE",
+				fmt.Sprintf("%s is unavailable during maintenance, try again later", info.FullMethod), "")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// blockedInMaintenance reports whether maintenance mode rejects method
+func blockedInMaintenance(method string) bool {
+	return strings.HasPrefix(method, authServicePrefix) && !readOnlyMethods[method]
+}
@@ -0,0 +1,104 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/config"
+	pb "worker/pb"
+)
+
+// newTestReloader loads the configuration with MAINTENANCE_MODE set to maintenance
+func newTestReloader(t *testing.T, maintenance string) *config.Reloader {
+	t.Helper()
+	t.Setenv("STORAGE_BACKEND", config.StorageBackendMemory)
+	t.Setenv("JWT_ACCESS_SECRET", "test-access-secret")
+	t.Setenv("JWT_REFRESH_SECRET", "test-refresh-secret")
+	t.Setenv("MAINTENANCE_MODE", maintenance)
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	return config.NewReloader(fxtest.NewLifecycle(t), cfg, zap.NewNop())
+}
+
+// callMaintenance runs method through the Maintenance interceptor and reports whether the handler ran
+func callMaintenance(reloader *config.Reloader, method string) (bool, error) {
+	called := false
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		called = true
+		return nil, nil
+	}
+	_, err := Maintenance(reloader)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	return called, err
+}
+
+func TestMaintenance(t *testing.T) {
+	reloader := newTestReloader(t, "true")
+
+	tests := []struct {
+		method  string
+		blocked bool
+	}{
+		{pb.AuthService_Login_FullMethodName, false},
+		{pb.AuthService_RefreshToken_FullMethodName, false},
+		{pb.AuthService_ValidateToken_FullMethodName, false},
+		{pb.AuthService_ListUsers_FullMethodName, false},
+		{grpc_health_v1.Health_Check_FullMethodName, false},
+		{pb.AuthService_Register_FullMethodName, true},
+		{pb.AuthService_ChangePassword_FullMethodName, true},
+		{pb.AuthService_SetUserActive_FullMethodName, true},
+		{pb.AuthService_RevokeAllUserTokens_FullMethodName, true},
+		{pb.AuthService_ForcePasswordReset_FullMethodName, true},
+		// Methods added later are blocked until they are listed as read-only
+		{"/auth.AuthService/NewMethod", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method, func(t *testing.T) {
+			called, err := callMaintenance(reloader, tt.method)
+			if !tt.blocked {
+				if err != nil || !called {
+					t.Fatalf("called = %t, err = %v; want the handler to run", called, err)
+				}
+				return
+			}
+			if called {
+				t.Fatal("handler ran in maintenance mode")
+			}
+			if status.Code(err) != codes.Unavailable {
+				t.Fatalf("err = %v, want Unavailable", err)
+			}
+		})
+	}
+}
+
+func TestMaintenanceReload(t *testing.T) {
+	reloader := newTestReloader(t, "false")
+	if called, err := callMaintenance(reloader, pb.AuthService_Register_FullMethodName); err != nil || !called {
+		t.Fatalf("Register outside maintenance: called = %t, err = %v", called, err)
+	}
+
+	t.Setenv("MAINTENANCE_MODE", "true")
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, err := callMaintenance(reloader, pb.AuthService_Register_FullMethodName); status.Code(err) != codes.Unavailable {
+		t.Fatalf("Register after enabling maintenance: err = %v, want Unavailable", err)
+	}
+
+	t.Setenv("MAINTENANCE_MODE", "false")
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if called, err := callMaintenance(reloader, pb.AuthService_Register_FullMethodName); err != nil || !called {
+		t.Fatalf("Register after disabling maintenance: called = %t, err = %v", called, err)
+	}
+}
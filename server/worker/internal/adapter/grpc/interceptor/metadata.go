@@ -0,0 +1,17 @@
+package interceptor
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"worker/internal/adapter/grpc/grpcmd"
+)
+
+// Metadata returns a unary interceptor that reads the incoming metadata once and stores it in the request context.
+// Later interceptors and handlers read it with grpcmd.FromContext; without this interceptor they parse it themselves.
+func Metadata() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(grpcmd.WithRequest(ctx), req)
+	}
+}
@@ -0,0 +1,34 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"worker/internal/adapter/grpc/grpcmd"
+)
+
+func TestMetadataStoresRequest(t *testing.T) {
+	var stored, again *grpcmd.Request
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		stored, again = grpcmd.FromContext(ctx), grpcmd.FromContext(ctx)
+		return nil, nil
+	}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		grpcmd.AuthorizationMetadataKey, "Bearer abc",
+		"x-correlation-id", "req-42",
+	))
+	if _, err := Metadata()(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/auth.AuthService/Ping"}, handler); err != nil {
+		t.Fatalf("interceptor: %v", err)
+	}
+
+	// The handler reads the request parsed once by the interceptor
+	if stored != again {
+		t.Fatal("handler re-parsed the metadata")
+	}
+	if stored.BearerToken() != "abc" || stored.CorrelationID() != "req-42" {
+		t.Fatalf("request = bearer %q, correlation %q; want the incoming metadata", stored.BearerToken(), stored.CorrelationID())
+	}
+}
@@ -0,0 +1,49 @@
+package interceptor
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/adapter/logger"
+	"worker/internal/core/domain"
+)
+
+// Recovery returns a unary interceptor that turns a panic into codes.Internal instead of crashing the worker.
+// The panic is logged with its stack; the client only sees INTERNAL_ERROR. NewGRPCServer always installs it
+// outermost, whatever GRPC_INTERCEPTORS says, so panics raised in other interceptors are recovered too.
+func Recovery(base *zap.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				resp, err = nil, recovered(ctx, base, info.FullMethod, r)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// RecoveryStream is Recovery for streaming methods
+func RecoveryStream(base *zap.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = recovered(ss.Context(), base, info.FullMethod, r)
+			}
+		}()
+		return handler(srv, ss)
+	}
+}
+
+// recovered logs a recovered panic and returns the status error reported for it
+func recovered(ctx context.Context, base *zap.Logger, method string, r interface{}) error {
+	logger.FromContext(ctx, base).Error("gRPC handler panicked",
+		zap.String("method", method),
+		zap.Any("panic", r),
+		zap.Stack("stack"),
+	)
+	return grpcerr.New(codes.Internal, domain.CodeInternalError, "internal error", "")
+}
@@ -0,0 +1,54 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/adapter/logger"
+	"worker/internal/common/correlation"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// Sanitize returns a unary interceptor that keeps internal error details away from clients.
+// Errors built by grpcerr (domain errors and interceptor rejections) carry safe messages and pass through.
+// Any other Unknown or Internal error is logged in full and replaced by an INTERNAL_ERROR status;
+// in production its message is a generic one with the correlation ID, so support can find the log entry.
+func Sanitize(serverCfg *config.ServerConfig, base *zap.Logger) grpc.UnaryServerInterceptor {
+	production := serverCfg.Env == "production"
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+
+		st := status.Convert(err)
+		if hasErrorInfo(st) || (st.Code() != codes.Unknown && st.Code() != codes.Internal) {
+			return resp, err
+		}
+
+		logger.FromContext(ctx, base).Error("Request failed with an internal error", zap.Error(err))
+		message := st.Message()
+		if production {
+			message = fmt.Sprintf("internal server error (correlation ID: %s)", correlation.FromContext(ctx))
+		}
+		return nil, grpcerr.New(codes.Internal, domain.CodeInternalError, message, "")
+	}
+}
+
+// hasErrorInfo reports whether st was built by grpcerr
+func hasErrorInfo(st *status.Status) bool {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == grpcerr.Domain {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,109 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/common/correlation"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	pb "worker/pb"
+)
+
+// callSanitize runs a handler failing with err through the Sanitize interceptor in env
+func callSanitize(env string, err error) error {
+	ctx := correlation.WithID(context.Background(), "corr-123")
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return nil, err
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_Login_FullMethodName}
+	_, err = Sanitize(&config.ServerConfig{Env: env}, zap.NewNop())(ctx, nil, info, handler)
+	return err
+}
+
+// errorReason returns the grpcerr reason of err, or "" when it has none
+func errorReason(err error) string {
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == grpcerr.Domain {
+			return info.Reason
+		}
+	}
+	return ""
+}
+
+func TestSanitize(t *testing.T) {
+	leaky := fmt.Errorf("create user: %w", errors.New(`pq: relation "users" does not exist at 10.0.0.5:5432`))
+	tests := []struct {
+		name        string
+		env         string
+		err         error
+		wantCode    codes.Code
+		wantReason  string
+		wantMessage string // Substring of the message clients see
+		hidden      string // Must not appear in the message clients see
+	}{
+		{
+			name: "plain error in production", env: "production", err: leaky,
+			wantCode: codes.Internal, wantReason: domain.CodeInternalError,
+			wantMessage: "correlation ID: corr-123", hidden: "10.0.0.5",
+		},
+		{
+			name: "internal status in production", env: "production", err: status.Error(codes.Internal, "pgx: conn busy"),
+			wantCode: codes.Internal, wantReason: domain.CodeInternalError,
+			wantMessage: "internal server error", hidden: "pgx",
+		},
+		{
+			name: "plain error in development", env: "development", err: leaky,
+			wantCode: codes.Internal, wantReason: domain.CodeInternalError,
+			wantMessage: "10.0.0.5",
+		},
+		{
+			name: "grpcerr passes through", env: "production",
+			err:      grpcerr.New(codes.NotFound, domain.CodeUserNotFound, "user not found", ""),
+			wantCode: codes.NotFound, wantReason: domain.CodeUserNotFound, wantMessage: "user not found",
+		},
+		{
+			name: "internal grpcerr passes through", env: "production",
+			err:      grpcerr.New(codes.Internal, domain.CodeInternalError, "failed to hash password", ""),
+			wantCode: codes.Internal, wantReason: domain.CodeInternalError, wantMessage: "failed to hash password",
+		},
+		{
+			name: "non-internal status passes through", env: "production", err: status.Error(codes.Unavailable, "draining"),
+			wantCode: codes.Unavailable, wantMessage: "draining",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := callSanitize(tt.env, tt.err)
+			st := status.Convert(err)
+			if st.Code() != tt.wantCode {
+				t.Fatalf("code = %s, want %s", st.Code(), tt.wantCode)
+			}
+			if got := errorReason(err); got != tt.wantReason {
+				t.Fatalf("reason = %q, want %q", got, tt.wantReason)
+			}
+			if !strings.Contains(st.Message(), tt.wantMessage) {
+				t.Fatalf("message %q does not contain %q", st.Message(), tt.wantMessage)
+			}
+			if tt.hidden != "" && strings.Contains(st.Message(), tt.hidden) {
+				t.Fatalf("message %q leaks %q", st.Message(), tt.hidden)
+			}
+		})
+	}
+}
+
+func TestSanitizePassesSuccess(t *testing.T) {
+	if err := callSanitize("production", nil); err != nil {
+		t.Fatalf("successful call failed: %v", err)
+	}
+}
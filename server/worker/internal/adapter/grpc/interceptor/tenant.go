@@ -0,0 +1,29 @@
+package interceptor
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/adapter/grpc/grpcmd"
+	"worker/internal/core/domain"
+)
+
+// Tenant returns a unary interceptor that stores the x-tenant-id metadata value in the request context.
+// Malformed tenant IDs are rejected; whether a tenant is required is decided by the service.
+func Tenant() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		tenantID, ok, err := grpcmd.FromContext(ctx).Single(grpcmd.TenantMetadataKey)
+		if !ok {
+			return handler(ctx, req)
+		}
+		if err != nil || !domain.IsValidTenantID(tenantID) {
+			return nil, grpcerr.New(codes.InvalidArgument, domain.CodeInvalidTenant,
+				fmt.Sprintf("%s must be a single lowercase tenant ID", grpcmd.TenantMetadataKey), grpcmd.TenantMetadataKey)
+		}
+		return handler(domain.WithTenant(ctx, tenantID), req)
+	}
+}
@@ -0,0 +1,77 @@
+package interceptor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/adapter/grpc/grpcerr"
+	"worker/internal/config"
+)
+
+// Timeout returns a unary interceptor that enforces a server-side ceiling on handler execution.
+// The handler context gets the ceiling as deadline when the client deadline is absent or later,
+// and the call fails with codes.DeadlineExceeded once it passes, even if the handler ignores ctx.
+// Failing the call does not stop a handler that ignores ctx: its goroutine keeps running after the
+// deadline, holding any database connection it acquired, until it returns on its own.
+func Timeout(cfg *config.GRPCConfig) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		limit := methodTimeout(cfg, info.FullMethod)
+		if limit <= 0 {
+			return handler(ctx, req)
+		}
+
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) <= limit {
+			// Client deadline is already stricter, grpc-go enforces it
+			return handler(ctx, req)
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, limit)
+		defer cancel()
+
+		type result struct {
+			resp interface{}
+			err  error
+		}
+		done := make(chan result, 1)
+		go func() {
+			resp, err := handler(ctx, req)
+			done <- result{resp: resp, err: err}
+		}()
+
+		select {
+		case r := <-done:
+			if r.err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, deadlineExceeded(info.FullMethod, limit)
+			}
+			return r.resp, r.err
+		case <-ctx.Done():
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, deadlineExceeded(info.FullMethod, limit)
+			}
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+	}
+}
+
+// methodTimeout resolves the ceiling for a method: full name, then bare name, then global default
+func methodTimeout(cfg *config.GRPCConfig, fullMethod string) time.Duration {
+	if d, ok := cfg.MethodTimeouts[fullMethod]; ok {
+		return d
+	}
+	if d, ok := cfg.MethodTimeouts[path.Base(fullMethod)]; ok {
+		return d
+	}
+	return cfg.MaxHandlerDuration
+}
+
+func deadlineExceeded(fullMethod string, limit time.Duration) error {
+	return grpcerr.New(codes.DeadlineExceeded, "DEADLINE_EXCEEDED",
+		fmt.Sprintf("%s exceeded the server time limit of %s", fullMethod, limit), "")
+}
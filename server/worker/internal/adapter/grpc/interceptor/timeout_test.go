@@ -0,0 +1,112 @@
+package interceptor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"worker/internal/config"
+	pb "worker/pb"
+)
+
+// handlerBudget runs method through the Timeout interceptor and returns the time the handler had left,
+// or zero when its context carried no deadline
+func handlerBudget(t *testing.T, ctx context.Context, cfg *config.GRPCConfig, method string) time.Duration {
+	t.Helper()
+	var budget time.Duration
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		if deadline, ok := ctx.Deadline(); ok {
+			budget = time.Until(deadline)
+		}
+		return nil, nil
+	}
+	if _, err := Timeout(cfg)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, handler); err != nil {
+		t.Fatalf("call %s: %v", method, err)
+	}
+	return budget
+}
+
+func TestTimeoutLimits(t *testing.T) {
+	cfg := &config.GRPCConfig{
+		MaxHandlerDuration: time.Minute,
+		MethodTimeouts: map[string]time.Duration{
+			pb.AuthService_Login_FullMethodName:     2 * time.Minute,
+			"Register":                              3 * time.Minute,
+			"ListUsers":                             4 * time.Minute,
+			pb.AuthService_ListUsers_FullMethodName: 5 * time.Minute,
+			"Ping":                                  0,
+		},
+	}
+	tests := []struct {
+		name   string
+		method string
+		want   time.Duration
+	}{
+		{name: "global default", method: pb.AuthService_ValidateToken_FullMethodName, want: time.Minute},
+		{name: "full name", method: pb.AuthService_Login_FullMethodName, want: 2 * time.Minute},
+		{name: "bare name", method: pb.AuthService_Register_FullMethodName, want: 3 * time.Minute},
+		{name: "full name before bare name", method: pb.AuthService_ListUsers_FullMethodName, want: 5 * time.Minute},
+		{name: "disabled", method: pb.AuthService_Ping_FullMethodName, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			budget := handlerBudget(t, context.Background(), cfg, tt.method)
+			if tt.want == 0 {
+				if budget != 0 {
+					t.Fatalf("handler got a deadline %s away, want none", budget)
+				}
+				return
+			}
+			if budget > tt.want || budget < tt.want-time.Second {
+				t.Fatalf("handler got %s, want %s", budget, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeoutKeepsStricterClientDeadline(t *testing.T) {
+	cfg := &config.GRPCConfig{MaxHandlerDuration: time.Minute}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	budget := handlerBudget(t, ctx, cfg, pb.AuthService_Login_FullMethodName)
+	if budget > 10*time.Second || budget < 9*time.Second {
+		t.Fatalf("handler got %s, want the client's 10s", budget)
+	}
+
+	// A laxer client deadline is cut down to the ceiling
+	ctx, cancel = context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+	if budget := handlerBudget(t, ctx, cfg, pb.AuthService_Login_FullMethodName); budget > time.Minute {
+		t.Fatalf("handler got %s, want at most the 1m ceiling", budget)
+	}
+}
+
+func TestTimeoutFailsHandlerIgnoringContext(t *testing.T) {
+	cfg := &config.GRPCConfig{MaxHandlerDuration: 20 * time.Millisecond}
+	release := make(chan struct{})
+	defer close(release)
+
+	// The handler blocks without watching ctx, like a query that does not honour cancellation
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		<-release
+		return "late", nil
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: pb.AuthService_Login_FullMethodName}
+
+	start := time.Now()
+	resp, err := Timeout(cfg)(context.Background(), nil, info, handler)
+	if status.Code(err) != codes.DeadlineExceeded {
+		t.Fatalf("err = %v, want DeadlineExceeded", err)
+	}
+	if resp != nil {
+		t.Fatalf("resp = %v, want none", resp)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Fatalf("call returned after %s, want it cut off at the ceiling", elapsed)
+	}
+}
@@ -0,0 +1,48 @@
+package grpc
+
+import (
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+
+	"worker/internal/adapter/grpc/interceptor"
+	"worker/internal/config"
+)
+
+// unaryInterceptors returns the unary chain: recovery and metadata, then the GRPC_INTERCEPTORS in their configured order
+// Interceptors missing from the list are not constructed, so a disabled one never runs
+func unaryInterceptors(
+	cfg *config.GRPCConfig,
+	serverCfg *config.ServerConfig,
+	reloader *config.Reloader,
+	logger *zap.Logger,
+) []grpc.UnaryServerInterceptor {
+	available := map[string]func() grpc.UnaryServerInterceptor{
+		config.GRPCInterceptorCorrelation:    func() grpc.UnaryServerInterceptor { return interceptor.Correlation(logger) },
+		config.GRPCInterceptorClientIdentity: func() grpc.UnaryServerInterceptor { return interceptor.ClientIdentity(cfg) },
+		config.GRPCInterceptorAPIVersion:     func() grpc.UnaryServerInterceptor { return interceptor.APIVersion(cfg, logger) },
+		config.GRPCInterceptorCompression:    func() grpc.UnaryServerInterceptor { return interceptor.Compression(cfg) },
+		config.GRPCInterceptorSanitize:       func() grpc.UnaryServerInterceptor { return interceptor.Sanitize(serverCfg, logger) },
+		config.GRPCInterceptorTimeout:        func() grpc.UnaryServerInterceptor { return interceptor.Timeout(cfg) },
+		config.GRPCInterceptorDatabaseBusy:   interceptor.DatabaseBusy,
+		config.GRPCInterceptorMaintenance:    func() grpc.UnaryServerInterceptor { return interceptor.Maintenance(reloader) },
+		config.GRPCInterceptorTenant:         interceptor.Tenant,
+		config.GRPCInterceptorClientInfo:     interceptor.ClientInfo,
+	}
+
+	chain := []grpc.UnaryServerInterceptor{interceptor.Recovery(logger), interceptor.Metadata()}
+	for _, name := range cfg.Interceptors {
+		chain = append(chain, available[name]())
+	}
+	return chain
+}
+
+// streamInterceptors returns the stream chain: recovery, then the client identity check unless disabled
+func streamInterceptors(cfg *config.GRPCConfig, logger *zap.Logger) []grpc.StreamServerInterceptor {
+	chain := []grpc.StreamServerInterceptor{interceptor.RecoveryStream(logger)}
+	for _, name := range cfg.Interceptors {
+		if name == config.GRPCInterceptorClientIdentity {
+			chain = append(chain, interceptor.ClientIdentityStream(cfg))
+		}
+	}
+	return chain
+}
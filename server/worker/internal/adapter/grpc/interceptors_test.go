@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"testing"
+
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"worker/internal/adapter/grpc/grpcmd"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	pb "worker/pb"
+)
+
+// runChain calls handler through chain the way grpc.ChainUnaryInterceptor does
+func runChain(ctx context.Context, chain []grpc.UnaryServerInterceptor, method string, handler grpc.UnaryHandler) (interface{}, error) {
+	info := &grpc.UnaryServerInfo{FullMethod: method}
+	for i := len(chain) - 1; i >= 0; i-- {
+		next, current := handler, chain[i]
+		handler = func(ctx context.Context, req interface{}) (interface{}, error) {
+			return current(ctx, req, info, next)
+		}
+	}
+	return handler(ctx, nil)
+}
+
+func TestUnaryInterceptorsSkipDisabled(t *testing.T) {
+	// Maintenance mode is on, so an invoked maintenance interceptor rejects Register
+	cfg := &config.Config{Server: config.ServerConfig{MaintenanceMode: true}}
+	reloader := config.NewReloader(fxtest.NewLifecycle(t), cfg, zap.NewNop())
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(grpcmd.TenantMetadataKey, "acme"))
+
+	tests := []struct {
+		name         string
+		interceptors []string
+		wantTenant   bool
+		wantHandler  bool
+	}{
+		{"enabled", []string{config.GRPCInterceptorTenant, config.GRPCInterceptorMaintenance}, true, false},
+		{"tenant only", []string{config.GRPCInterceptorTenant}, true, true},
+		{"disabled", nil, false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			grpcCfg := &config.GRPCConfig{Interceptors: tt.interceptors}
+			chain := unaryInterceptors(grpcCfg, &cfg.Server, reloader, zap.NewNop())
+
+			called, gotTenant := false, false
+			_, err := runChain(ctx, chain, pb.AuthService_Register_FullMethodName, func(ctx context.Context, req interface{}) (interface{}, error) {
+				called = true
+				_, gotTenant = domain.TenantFromContext(ctx)
+				return nil, nil
+			})
+			if called != tt.wantHandler {
+				t.Fatalf("handler called = %t (err %v), want %t", called, err, tt.wantHandler)
+			}
+			if called && gotTenant != tt.wantTenant {
+				t.Fatalf("tenant in context = %t, want %t", gotTenant, tt.wantTenant)
+			}
+		})
+	}
+}
+
+func TestUnaryInterceptorsLetPingThrough(t *testing.T) {
+	// Every default interceptor runs, and clients must name their API version
+	cfg := &config.Config{GRPC: config.GRPCConfig{
+		Interceptors: config.DefaultGRPCInterceptors,
+		APIVersions:  []string{"v1"},
+	}}
+	reloader := config.NewReloader(fxtest.NewLifecycle(t), cfg, zap.NewNop())
+	chain := unaryInterceptors(&cfg.GRPC, &cfg.Server, reloader, zap.NewNop())
+
+	// A bare client with no token, API version or other metadata
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.MD{})
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return &pb.PingResponse{}, nil
+	}
+	if resp, err := runChain(ctx, chain, pb.AuthService_Ping_FullMethodName, handler); err != nil || resp == nil {
+		t.Fatalf("ping = %v, %v; want it answered", resp, err)
+	}
+	// The same bare call to another method is turned away
+	if _, err := runChain(ctx, chain, pb.AuthService_Login_FullMethodName, handler); err == nil {
+		t.Fatal("login without an API version got through")
+	}
+}
@@ -2,12 +2,16 @@ package grpc
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"net"
+	"os"
 
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/health"
 	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
@@ -23,18 +27,44 @@ var Module = fx.Module("grpc",
 		NewGRPCServer,
 		handler.NewAuthHandler,
 	),
-	fx.Invoke(registerServices),
+	// Nothing depends on the server, so force its construction
+	fx.Invoke(func(*GRPCServer) {}),
 )
 
 // GRPCServer wraps the gRPC server with its dependencies
+// Listener is set once the server starts
 type GRPCServer struct {
 	Server   *grpc.Server
 	Listener net.Listener
 }
 
-// NewGRPCServer creates a new gRPC server
-func NewGRPCServer(lc fx.Lifecycle, cfg *config.GRPCConfig, serverCfg *config.ServerConfig, logger *zap.Logger) (*GRPCServer, error) {
-	server := grpc.NewServer()
+// NewGRPCServer creates a new gRPC server with every service registered
+// The port is only bound on start, after registration, so no request can reach an unregistered service
+func NewGRPCServer(
+	lc fx.Lifecycle,
+	cfg *config.GRPCConfig,
+	serverCfg *config.ServerConfig,
+	reloader *config.Reloader,
+	authHandler *handler.AuthHandler,
+	logger *zap.Logger,
+) (*GRPCServer, error) {
+	opts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unaryInterceptors(cfg, serverCfg, reloader, logger)...),
+		grpc.ChainStreamInterceptor(streamInterceptors(cfg, logger)...),
+	}
+	logger.Info("✅ gRPC interceptors", zap.Strings("chain", cfg.Interceptors))
+	if cfg.TLSCertFile != "" {
+		tlsConfig, err := loadTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, grpc.Creds(credentials.NewTLS(tlsConfig)))
+		logger.Info("✅ gRPC TLS enabled", zap.Bool("mtls", cfg.TLSClientCAFile != ""))
+	}
+	server := grpc.NewServer(opts...)
+
+	pb.RegisterAuthServiceServer(server, authHandler)
+	logger.Info("✅ Registered AuthService gRPC handler")
 
 	// Enable reflection in development mode
 	if serverCfg.Env == "development" {
@@ -42,26 +72,28 @@ func NewGRPCServer(lc fx.Lifecycle, cfg *config.GRPCConfig, serverCfg *config.Se
 		logger.Info("✅ gRPC reflection enabled")
 	}
 
-	// Register health check service
+	// Register health check service; it reports SERVING once the listener is up
 	healthServer := health.NewServer()
 	grpc_health_v1.RegisterHealthServer(server, healthServer)
-	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
+	healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_NOT_SERVING)
 
-	// Create listener
+	grpcServer := &GRPCServer{Server: server}
 	addr := fmt.Sprintf(":%s", cfg.Port)
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		return nil, fmt.Errorf("failed to listen on %s: %w", addr, err)
-	}
-
-	grpcServer := &GRPCServer{
-		Server:   server,
-		Listener: listener,
-	}
 
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
+			if err := checkRegistered(server); err != nil {
+				return err
+			}
+
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", addr, err)
+			}
+			grpcServer.Listener = listener
+
 			logger.Info("🚀 Starting gRPC server", zap.String("addr", addr))
+			healthServer.SetServingStatus("", grpc_health_v1.HealthCheckResponse_SERVING)
 			go func() {
 				if err := server.Serve(listener); err != nil {
 					logger.Error("gRPC server error", zap.Error(err))
@@ -71,6 +103,7 @@ func NewGRPCServer(lc fx.Lifecycle, cfg *config.GRPCConfig, serverCfg *config.Se
 		},
 		OnStop: func(ctx context.Context) error {
 			logger.Info("Shutting down gRPC server...")
+			healthServer.Shutdown()
 			server.GracefulStop()
 			return nil
 		},
@@ -79,12 +112,47 @@ func NewGRPCServer(lc fx.Lifecycle, cfg *config.GRPCConfig, serverCfg *config.Se
 	return grpcServer, nil
 }
 
-// registerServices registers all gRPC service handlers
-func registerServices(
-	server *GRPCServer,
-	authHandler *handler.AuthHandler,
-	logger *zap.Logger,
-) {
-	pb.RegisterAuthServiceServer(server.Server, authHandler)
-	logger.Info("✅ Registered AuthService gRPC handler")
+// loadTLSConfig builds the server TLS configuration
+// With a client CA, presented client certificates must chain to it; calls without one
+// are still accepted so public methods keep working, interceptor.ClientIdentity guards the internal ones
+func loadTLSConfig(cfg *config.GRPCConfig) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(cfg.TLSCertFile, cfg.TLSKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load gRPC TLS certificate: %w", err)
+	}
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	if cfg.TLSClientCAFile != "" {
+		pem, err := os.ReadFile(cfg.TLSClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read GRPC_TLS_CLIENT_CA_FILE: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("GRPC_TLS_CLIENT_CA_FILE contains no PEM certificates")
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	}
+	return tlsConfig, nil
+}
+
+// requiredServices must be registered before the server accepts connections
+var requiredServices = []string{
+	pb.AuthService_ServiceDesc.ServiceName,
+	grpc_health_v1.Health_ServiceDesc.ServiceName,
+}
+
+// checkRegistered fails startup if a required service is missing from server
+func checkRegistered(server *grpc.Server) error {
+	registered := server.GetServiceInfo()
+	for _, name := range requiredServices {
+		if _, ok := registered[name]; !ok {
+			return fmt.Errorf("gRPC service %s is not registered", name)
+		}
+	}
+	return nil
 }
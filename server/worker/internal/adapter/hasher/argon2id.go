@@ -0,0 +1,99 @@
+package hasher
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+
+	"worker/internal/core/domain"
+)
+
+// argon2idPrefix starts every argon2id hash in PHC string format:
+// $argon2id$v=19$m=<KiB>,t=<iterations>,p=<lanes>$<salt>$<key>, base64 without padding
+const argon2idPrefix = "$argon2id$"
+
+const (
+	argon2idSaltLength = 16
+	argon2idKeyLength  = 32
+)
+
+// argon2idHasher produces argon2id hashes with the configured cost parameters
+type argon2idHasher struct {
+	params argon2idParams
+}
+
+type argon2idParams struct {
+	memory      uint32
+	iterations  uint32
+	parallelism uint8
+}
+
+func newArgon2id(memory, iterations uint32, parallelism uint8) *argon2idHasher {
+	return &argon2idHasher{params: argon2idParams{memory: memory, iterations: iterations, parallelism: parallelism}}
+}
+
+func (a *argon2idHasher) hash(password string) (string, error) {
+	salt := make([]byte, argon2idSaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	p := a.params
+	key := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, argon2idKeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version, p.memory, p.iterations, p.parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key)), nil
+}
+
+// compare recomputes the key with the parameters stored in hash, not the configured ones
+func (a *argon2idHasher) compare(hash, password string) error {
+	p, salt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		return err
+	}
+	computed := argon2.IDKey([]byte(password), salt, p.iterations, p.memory, p.parallelism, uint32(len(key)))
+	if subtle.ConstantTimeCompare(computed, key) != 1 {
+		return domain.ErrIncorrectPassword
+	}
+	return nil
+}
+
+func (a *argon2idHasher) matches(hash string) bool {
+	return strings.HasPrefix(hash, argon2idPrefix)
+}
+
+func (a *argon2idHasher) outdated(hash, password string) bool {
+	p, _, _, err := decodeArgon2id(hash)
+	return err != nil || p != a.params
+}
+
+// decodeArgon2id parses a PHC argon2id string
+func decodeArgon2id(hash string) (argon2idParams, []byte, []byte, error) {
+	var p argon2idParams
+	parts := strings.Split(strings.TrimPrefix(hash, argon2idPrefix), "$")
+	if len(parts) != 4 {
+		return p, nil, nil, fmt.Errorf("malformed argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[0], "v=%d", &version); err != nil || version != argon2.Version {
+		return p, nil, nil, fmt.Errorf("unsupported argon2id version %q", parts[0])
+	}
+	if _, err := fmt.Sscanf(parts[1], "m=%d,t=%d,p=%d", &p.memory, &p.iterations, &p.parallelism); err != nil {
+		return p, nil, nil, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+	if p.iterations < 1 || p.parallelism < 1 {
+		return p, nil, nil, fmt.Errorf("malformed argon2id parameters")
+	}
+	salt, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return p, nil, nil, fmt.Errorf("malformed argon2id salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(parts[3])
+	if err != nil || len(key) == 0 {
+		return p, nil, nil, fmt.Errorf("malformed argon2id key")
+	}
+	return p, salt, key, nil
+}
@@ -0,0 +1,126 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// newArgon2idTestHasher hashes with argon2id at a small cost; iterations set the parameters under test
+func newArgon2idTestHasher(iterations uint32) *MultiHasher {
+	return NewMultiHasher(&config.AuthConfig{
+		PasswordHasher:    config.PasswordHasherArgon2id,
+		BcryptCost:        bcrypt.MinCost,
+		Argon2Memory:      1024,
+		Argon2Iterations:  iterations,
+		Argon2Parallelism: 1,
+	})
+}
+
+func TestArgon2idRoundTrip(t *testing.T) {
+	h := newArgon2idTestHasher(1)
+
+	hash, err := h.Hash("Correct-Horse-9-Battery")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=1024,t=1,p=1$") {
+		t.Fatalf("hash = %q, want a PHC string carrying its parameters", hash)
+	}
+	if err := h.Compare(hash, "Correct-Horse-9-Battery"); err != nil {
+		t.Fatalf("compare with the password: %v", err)
+	}
+	if err := h.Compare(hash, "Correct-Horse-9-Batterz"); !errors.Is(err, domain.ErrIncorrectPassword) {
+		t.Fatalf("compare with another password = %v, want ErrIncorrectPassword", err)
+	}
+	if h.NeedsRehash(hash, "Correct-Horse-9-Battery") {
+		t.Fatal("fresh argon2id hash needs a rehash")
+	}
+
+	// Each hash gets its own salt
+	again, err := h.Hash("Correct-Horse-9-Battery")
+	if err != nil {
+		t.Fatalf("hash again: %v", err)
+	}
+	if again == hash {
+		t.Fatal("two hashes of the same password are equal")
+	}
+}
+
+func TestCrossAlgorithmVerification(t *testing.T) {
+	bcryptHasher, argon2idHasher := newTestHasher(false), newArgon2idTestHasher(1)
+	const password = "Correct-Horse-9-Battery"
+
+	bcryptHash, err := bcryptHasher.Hash(password)
+	if err != nil {
+		t.Fatalf("bcrypt hash: %v", err)
+	}
+	argon2idHash, err := argon2idHasher.Hash(password)
+	if err != nil {
+		t.Fatalf("argon2id hash: %v", err)
+	}
+
+	// Either hasher verifies both formats, so mixed hashes coexist while users migrate
+	for name, h := range map[string]*MultiHasher{"bcrypt": bcryptHasher, "argon2id": argon2idHasher} {
+		for format, hash := range map[string]string{"bcrypt": bcryptHash, "argon2id": argon2idHash} {
+			if err := h.Compare(hash, password); err != nil {
+				t.Fatalf("%s hasher comparing a %s hash: %v", name, format, err)
+			}
+			if err := h.Compare(hash, "wrong-password"); !errors.Is(err, domain.ErrIncorrectPassword) {
+				t.Fatalf("%s hasher comparing a %s hash with the wrong password = %v, want ErrIncorrectPassword", name, format, err)
+			}
+			// Only hashes of the configured algorithm are kept
+			if rehash := h.NeedsRehash(hash, password); rehash != (name != format) {
+				t.Fatalf("%s hasher: %s hash needs rehash = %v, want %v", name, format, rehash, name != format)
+			}
+		}
+	}
+}
+
+func TestArgon2idParametersChange(t *testing.T) {
+	hash, err := newArgon2idTestHasher(1).Hash("Correct-Horse-9-Battery")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+
+	// Hashes verify with the parameters they store, and are rehashed with the configured ones
+	h := newArgon2idTestHasher(2)
+	if err := h.Compare(hash, "Correct-Horse-9-Battery"); err != nil {
+		t.Fatalf("compare a hash made with other parameters: %v", err)
+	}
+	if !h.NeedsRehash(hash, "Correct-Horse-9-Battery") {
+		t.Fatal("hash made with other parameters does not need a rehash")
+	}
+}
+
+func TestCompareMalformedHashes(t *testing.T) {
+	h := newArgon2idTestHasher(1)
+	tests := []struct {
+		name string
+		hash string
+	}{
+		{name: "unknown algorithm", hash: "$scrypt$ln=15,r=8,p=1$c2FsdA$a2V5"},
+		{name: "empty", hash: ""},
+		{name: "missing key", hash: "$argon2id$v=19$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA"},
+		{name: "other version", hash: "$argon2id$v=16$m=1024,t=1,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5"},
+		{name: "no iterations", hash: "$argon2id$v=19$m=1024,t=0,p=1$c2FsdHNhbHRzYWx0c2FsdA$a2V5a2V5a2V5a2V5"},
+		{name: "bad salt", hash: "$argon2id$v=19$m=1024,t=1,p=1$!!!$a2V5a2V5a2V5a2V5"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A hash that cannot be read is an error, not a wrong password
+			err := h.Compare(tt.hash, "Correct-Horse-9-Battery")
+			if err == nil || errors.Is(err, domain.ErrIncorrectPassword) {
+				t.Fatalf("compare = %v, want a malformed hash error", err)
+			}
+			if !h.NeedsRehash(tt.hash, "Correct-Horse-9-Battery") {
+				t.Fatal("malformed hash does not need a rehash")
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package hasher
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"worker/internal/core/domain"
+)
+
+// maxBcryptPasswordBytes is the longest input bcrypt uses; GenerateFromPassword refuses longer ones and
+// CompareHashAndPassword only looks at their first 72 bytes
+const maxBcryptPasswordBytes = 72
+
+// bcryptSHA256Prefix marks a bcrypt hash of the pre-hashed password, stored as the prefix followed by the
+// bcrypt hash. Such hashes always verify by pre-hashing, whether or not AUTH_BCRYPT_PREHASH is still set
+const bcryptSHA256Prefix = "$bcrypt-sha256$"
+
+// bcryptHasher produces the bcrypt hashes the worker has always stored ($2a$, $2b$, $2y$)
+// With prehash, passwords longer than bcrypt accepts are pre-hashed instead (bcryptSHA256Prefix),
+// so every byte of a long passphrase counts; shorter passwords keep plain bcrypt hashes
+type bcryptHasher struct {
+	cost    int
+	prehash bool
+}
+
+func newBcrypt(cost int, prehash bool) *bcryptHasher {
+	return &bcryptHasher{cost: cost, prehash: prehash}
+}
+
+func (b *bcryptHasher) hash(password string) (string, error) {
+	if b.prehash && len(password) > maxBcryptPasswordBytes {
+		hashed, err := bcrypt.GenerateFromPassword(prehashPassword(password), b.cost)
+		return bcryptSHA256Prefix + string(hashed), err
+	}
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), b.cost)
+	return string(hashed), err
+}
+
+// compare verifies pre-hashed hashes by pre-hashing; plain hashes of a long password made before
+// AUTH_BCRYPT_PREHASH match on its first 72 bytes, as they always did
+func (b *bcryptHasher) compare(hash, password string) error {
+	input := []byte(password)
+	if inner, ok := strings.CutPrefix(hash, bcryptSHA256Prefix); ok {
+		hash, input = inner, prehashPassword(password)
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(hash), input)
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return domain.ErrIncorrectPassword
+	}
+	return err
+}
+
+func (b *bcryptHasher) matches(hash string) bool {
+	return strings.HasPrefix(hash, "$2") || strings.HasPrefix(hash, bcryptSHA256Prefix)
+}
+
+// outdated also reports a plain hash of a long password once prehash is on, so it is
+// rehashed into the pre-hashed form when the user next logs in
+func (b *bcryptHasher) outdated(hash, password string) bool {
+	inner, prehashed := strings.CutPrefix(hash, bcryptSHA256Prefix)
+	if b.prehash && !prehashed && len(password) > maxBcryptPasswordBytes {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(inner))
+	return err != nil || cost < b.cost
+}
+
+// prehashPassword returns the base64 SHA-256 of password: 44 bytes, within bcrypt's limit and free of NUL bytes
+func prehashPassword(password string) []byte {
+	sum := sha256.Sum256([]byte(password))
+	return []byte(base64.StdEncoding.EncodeToString(sum[:]))
+}
@@ -0,0 +1,98 @@
+package hasher
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// longPassword is over bcrypt's 72-byte limit; longPasswordTwin shares its first 72 bytes
+var (
+	longPassword     = strings.Repeat("correct horse battery staple ", 4) + "one"
+	longPasswordTwin = strings.Repeat("correct horse battery staple ", 4) + "two"
+)
+
+func newTestHasher(prehash bool) *MultiHasher {
+	return NewMultiHasher(&config.AuthConfig{
+		PasswordHasher: config.PasswordHasherBcrypt,
+		BcryptCost:     bcrypt.MinCost,
+		BcryptPrehash:  prehash,
+	})
+}
+
+func TestBcryptPrehashRoundTrip(t *testing.T) {
+	h := newTestHasher(true)
+
+	hash, err := h.Hash(longPassword)
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, bcryptSHA256Prefix) {
+		t.Fatalf("hash of a %d-byte password = %q, want the %s form", len(longPassword), hash, bcryptSHA256Prefix)
+	}
+	if err := h.Compare(hash, longPassword); err != nil {
+		t.Fatalf("compare with the password: %v", err)
+	}
+	// Every byte counts, not only the first 72
+	if err := h.Compare(hash, longPasswordTwin); !errors.Is(err, domain.ErrIncorrectPassword) {
+		t.Fatalf("compare with a password differing after byte 72 = %v, want ErrIncorrectPassword", err)
+	}
+	if h.NeedsRehash(hash, longPassword) {
+		t.Fatal("fresh pre-hashed hash needs a rehash")
+	}
+
+	// Pre-hashed hashes still verify once AUTH_BCRYPT_PREHASH is turned off again
+	if err := newTestHasher(false).Compare(hash, longPassword); err != nil {
+		t.Fatalf("compare without prehash: %v", err)
+	}
+}
+
+func TestBcryptPrehashKeepsShortPasswordsPlain(t *testing.T) {
+	h := newTestHasher(true)
+
+	hash, err := h.Hash("short-password")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if !strings.HasPrefix(hash, "$2") {
+		t.Fatalf("hash of a short password = %q, want a plain bcrypt hash", hash)
+	}
+	if err := h.Compare(hash, "short-password"); err != nil {
+		t.Fatalf("compare: %v", err)
+	}
+	if h.NeedsRehash(hash, "short-password") {
+		t.Fatal("plain hash of a short password needs a rehash")
+	}
+}
+
+func TestBcryptPrehashRehashesLegacyLongPasswords(t *testing.T) {
+	// A hash of the first 72 bytes, as stored for long passwords before AUTH_BCRYPT_PREHASH
+	legacy, err := bcrypt.GenerateFromPassword([]byte(longPassword[:maxBcryptPasswordBytes]), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("legacy hash: %v", err)
+	}
+
+	h := newTestHasher(true)
+	if err := h.Compare(string(legacy), longPassword); err != nil {
+		t.Fatalf("compare legacy hash: %v", err)
+	}
+	if !h.NeedsRehash(string(legacy), longPassword) {
+		t.Fatal("legacy hash of a long password does not need a rehash")
+	}
+	if newTestHasher(false).NeedsRehash(string(legacy), longPassword) {
+		t.Fatal("legacy hash needs a rehash without prehash")
+	}
+
+	rehashed, err := h.Hash(longPassword)
+	if err != nil {
+		t.Fatalf("rehash: %v", err)
+	}
+	if err := h.Compare(rehashed, longPasswordTwin); !errors.Is(err, domain.ErrIncorrectPassword) {
+		t.Fatalf("rehashed hash accepts a password differing after byte 72: %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package hasher
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+
+	"worker/internal/config"
+)
+
+// defaultArgon2idIterations is used when AUTH_ARGON2_ITERATIONS is 0 and calibration is off
+const defaultArgon2idIterations = 2
+
+// Bounds of calibrated costs: calibration never goes below the static defaults, and stops
+// where one hash takes seconds on any hardware
+const (
+	minCalibratedBcryptCost   = bcrypt.DefaultCost
+	maxCalibratedBcryptCost   = 16
+	minCalibratedArgon2idTime = defaultArgon2idIterations
+	maxCalibratedArgon2idTime = 64
+)
+
+// calibrationRuns is how many hashes a measurement takes the fastest of, to discount a slow first run
+const calibrationRuns = 2
+
+// calibrationPassword is hashed by the benchmarks; its value does not affect the duration
+const calibrationPassword = "calibration-password"
+
+// costs are the cost parameters new hashes are made with
+type costs struct {
+	bcryptCost       int
+	argon2Iterations uint32
+	calibrated       bool // The cost of the configured algorithm was picked by calibration
+}
+
+// calibrationKey identifies a calibration result; the same inputs on the same machine give the same costs
+type calibrationKey struct {
+	algorithm   string
+	target      time.Duration
+	memory      uint32
+	parallelism uint8
+}
+
+// calibrated caches calibration results for the life of the process, so a hasher built again
+// (e.g. by another fx graph in the same process) does not benchmark again
+var calibrated sync.Map // calibrationKey -> int
+
+// resolveCosts returns the configured costs, with a cost left at 0 replaced by its default or,
+// for the configured algorithm with AUTH_HASH_CALIBRATION_TARGET set, by the calibrated cost
+// An explicitly configured cost is always used as it is
+func resolveCosts(cfg *config.AuthConfig) costs {
+	c := costs{bcryptCost: cfg.BcryptCost, argon2Iterations: cfg.Argon2Iterations}
+	calibrate := cfg.HashCalibrationTarget > 0
+
+	if c.bcryptCost == 0 {
+		c.bcryptCost = bcrypt.DefaultCost
+		if calibrate && cfg.PasswordHasher == config.PasswordHasherBcrypt {
+			c.bcryptCost = cachedCalibration(calibrationKey{algorithm: config.PasswordHasherBcrypt, target: cfg.HashCalibrationTarget},
+				func() int { return calibrateBcrypt(cfg.HashCalibrationTarget) })
+			c.calibrated = true
+		}
+	}
+	if c.argon2Iterations == 0 {
+		c.argon2Iterations = defaultArgon2idIterations
+		if calibrate && cfg.PasswordHasher == config.PasswordHasherArgon2id {
+			key := calibrationKey{
+				algorithm:   config.PasswordHasherArgon2id,
+				target:      cfg.HashCalibrationTarget,
+				memory:      cfg.Argon2Memory,
+				parallelism: cfg.Argon2Parallelism,
+			}
+			c.argon2Iterations = uint32(cachedCalibration(key, func() int {
+				return calibrateArgon2id(cfg.HashCalibrationTarget, cfg.Argon2Memory, cfg.Argon2Parallelism)
+			}))
+			c.calibrated = true
+		}
+	}
+	return c
+}
+
+func cachedCalibration(key calibrationKey, calibrate func() int) int {
+	if cost, ok := calibrated.Load(key); ok {
+		return cost.(int)
+	}
+	cost := calibrate()
+	calibrated.Store(key, cost)
+	return cost
+}
+
+// calibrateBcrypt returns the highest bcrypt cost whose hashes take at most target, within bounds
+// Only the lowest cost is measured: every further cost doubles the work
+func calibrateBcrypt(target time.Duration) int {
+	cost := minCalibratedBcryptCost
+	elapsed := measure(func() { _, _ = bcrypt.GenerateFromPassword([]byte(calibrationPassword), cost) })
+	for cost < maxCalibratedBcryptCost && 2*elapsed <= target {
+		cost++
+		elapsed *= 2
+	}
+	return cost
+}
+
+// calibrateArgon2id returns the highest number of argon2id iterations whose hashes take at most target,
+// within bounds; memory and parallelism stay as configured, and the work grows linearly with iterations
+func calibrateArgon2id(target time.Duration, memory uint32, parallelism uint8) int {
+	salt := make([]byte, argon2idSaltLength)
+	perIteration := measure(func() {
+		argon2.IDKey([]byte(calibrationPassword), salt, 1, memory, parallelism, argon2idKeyLength)
+	})
+	iterations := minCalibratedArgon2idTime
+	if perIteration > 0 {
+		iterations = max(iterations, int(target/perIteration))
+	}
+	return min(iterations, maxCalibratedArgon2idTime)
+}
+
+// measure returns the fastest of calibrationRuns runs of fn
+func measure(fn func()) time.Duration {
+	fastest := time.Duration(0)
+	for i := 0; i < calibrationRuns; i++ {
+		start := time.Now()
+		fn()
+		if elapsed := time.Since(start); i == 0 || elapsed < fastest {
+			fastest = elapsed
+		}
+	}
+	return fastest
+}
@@ -0,0 +1,134 @@
+package hasher
+
+import (
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"worker/internal/config"
+)
+
+func TestCalibrateBcryptWithinBounds(t *testing.T) {
+	tests := []struct {
+		target time.Duration
+		want   int // 0 when any cost within bounds will do
+	}{
+		// Faster than any cost: never below the static default
+		{target: time.Nanosecond, want: minCalibratedBcryptCost},
+		// Slower than any cost: capped
+		{target: time.Hour, want: maxCalibratedBcryptCost},
+		{target: 250 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		cost := calibrateBcrypt(tt.target)
+		if cost < minCalibratedBcryptCost || cost > maxCalibratedBcryptCost {
+			t.Fatalf("calibrateBcrypt(%s) = %d, want between %d and %d", tt.target, cost, minCalibratedBcryptCost, maxCalibratedBcryptCost)
+		}
+		if tt.want != 0 && cost != tt.want {
+			t.Fatalf("calibrateBcrypt(%s) = %d, want %d", tt.target, cost, tt.want)
+		}
+	}
+}
+
+func TestCalibrateArgon2idWithinBounds(t *testing.T) {
+	tests := []struct {
+		target time.Duration
+		want   int
+	}{
+		{target: time.Nanosecond, want: minCalibratedArgon2idTime},
+		{target: time.Hour, want: maxCalibratedArgon2idTime},
+		{target: 10 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		iterations := calibrateArgon2id(tt.target, 1024, 1)
+		if iterations < minCalibratedArgon2idTime || iterations > maxCalibratedArgon2idTime {
+			t.Fatalf("calibrateArgon2id(%s) = %d, want between %d and %d",
+				tt.target, iterations, minCalibratedArgon2idTime, maxCalibratedArgon2idTime)
+		}
+		if tt.want != 0 && iterations != tt.want {
+			t.Fatalf("calibrateArgon2id(%s) = %d, want %d", tt.target, iterations, tt.want)
+		}
+	}
+}
+
+func TestResolveCosts(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.AuthConfig
+		want costs
+	}{
+		{
+			name: "defaults",
+			cfg:  config.AuthConfig{PasswordHasher: config.PasswordHasherBcrypt},
+			want: costs{bcryptCost: bcrypt.DefaultCost, argon2Iterations: defaultArgon2idIterations},
+		},
+		{
+			name: "explicit costs without calibration",
+			cfg:  config.AuthConfig{PasswordHasher: config.PasswordHasherBcrypt, BcryptCost: 5, Argon2Iterations: 3},
+			want: costs{bcryptCost: 5, argon2Iterations: 3},
+		},
+		// An explicit cost wins over calibration, which then does not run
+		{
+			name: "explicit bcrypt cost with calibration",
+			cfg:  config.AuthConfig{PasswordHasher: config.PasswordHasherBcrypt, BcryptCost: 5, HashCalibrationTarget: time.Hour},
+			want: costs{bcryptCost: 5, argon2Iterations: defaultArgon2idIterations},
+		},
+		{
+			name: "explicit argon2id iterations with calibration",
+			cfg: config.AuthConfig{PasswordHasher: config.PasswordHasherArgon2id, Argon2Iterations: 3,
+				Argon2Memory: 1024, Argon2Parallelism: 1, HashCalibrationTarget: time.Hour},
+			want: costs{bcryptCost: bcrypt.DefaultCost, argon2Iterations: 3},
+		},
+		// Only the configured algorithm is calibrated
+		{
+			name: "argon2id calibrated",
+			cfg: config.AuthConfig{PasswordHasher: config.PasswordHasherArgon2id,
+				Argon2Memory: 1024, Argon2Parallelism: 1, HashCalibrationTarget: time.Hour},
+			want: costs{bcryptCost: bcrypt.DefaultCost, argon2Iterations: maxCalibratedArgon2idTime, calibrated: true},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := resolveCosts(&tt.cfg); got != tt.want {
+				t.Fatalf("resolveCosts = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalibrationCached(t *testing.T) {
+	key := calibrationKey{algorithm: config.PasswordHasherBcrypt, target: 123 * time.Millisecond}
+	t.Cleanup(func() { calibrated.Delete(key) })
+
+	runs := 0
+	calibrate := func() int {
+		runs++
+		return 12
+	}
+	for i := 0; i < 3; i++ {
+		if cost := cachedCalibration(key, calibrate); cost != 12 {
+			t.Fatalf("cost = %d, want 12", cost)
+		}
+	}
+	if runs != 1 {
+		t.Fatalf("calibrated %d times, want once", runs)
+	}
+
+	// A hasher built with the same settings uses the cached cost instead of benchmarking
+	h := NewMultiHasher(&config.AuthConfig{PasswordHasher: config.PasswordHasherBcrypt, HashCalibrationTarget: key.target})
+	if h.costs.bcryptCost != 12 || !h.costs.calibrated {
+		t.Fatalf("costs = %+v, want the cached cost 12", h.costs)
+	}
+}
+
+func TestHasherUsesResolvedCost(t *testing.T) {
+	h := NewMultiHasher(&config.AuthConfig{PasswordHasher: config.PasswordHasherBcrypt, BcryptCost: bcrypt.MinCost})
+	hash, err := h.Hash("Correct-Horse-9-Battery")
+	if err != nil {
+		t.Fatalf("hash: %v", err)
+	}
+	if cost, err := bcrypt.Cost([]byte(hash)); err != nil || cost != bcrypt.MinCost {
+		t.Fatalf("hash cost = %d, %v; want %d", cost, err, bcrypt.MinCost)
+	}
+}
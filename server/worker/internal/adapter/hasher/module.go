@@ -0,0 +1,30 @@
+package hasher
+
+import (
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/config"
+	"worker/internal/core/ports"
+)
+
+// Module provides password hashing dependencies
+var Module = fx.Module("hasher",
+	fx.Provide(NewHasher),
+)
+
+// NewHasher returns a hasher producing AUTH_PASSWORD_HASHER hashes and verifying hashes of every supported algorithm
+// With AUTH_HASH_CALIBRATION_TARGET the cost is benchmarked here, delaying startup by a few hashes
+func NewHasher(cfg *config.AuthConfig, logger *zap.Logger) ports.PasswordHasher {
+	h := NewMultiHasher(cfg)
+	cost := zap.Int("cost", h.costs.bcryptCost)
+	if cfg.PasswordHasher == config.PasswordHasherArgon2id {
+		cost = zap.Uint32("iterations", h.costs.argon2Iterations)
+	}
+	logger.Info("✅ Password hashing configured",
+		zap.String("algorithm", cfg.PasswordHasher),
+		cost,
+		zap.Bool("calibrated", h.costs.calibrated),
+	)
+	return h
+}
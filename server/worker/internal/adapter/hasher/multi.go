@@ -0,0 +1,75 @@
+package hasher
+
+import (
+	"errors"
+
+	"worker/internal/config"
+	"worker/internal/core/ports"
+)
+
+// Ensure MultiHasher implements ports.PasswordHasher
+var _ ports.PasswordHasher = (*MultiHasher)(nil)
+
+// errUnknownAlgorithm is returned for stored hashes no supported algorithm recognizes
+var errUnknownAlgorithm = errors.New("unknown password hash algorithm")
+
+// algorithm is one supported hash format, recognized by the prefix of its hashes
+type algorithm interface {
+	hash(password string) (string, error)
+	compare(hash, password string) error
+	// matches reports whether hash is of this algorithm's format
+	matches(hash string) bool
+	// outdated reports whether hash, of this algorithm's format and matching password, was made with other parameters
+	outdated(hash, password string) bool
+}
+
+// MultiHasher implements ports.PasswordHasher over bcrypt and argon2id
+// Both formats are self-describing: bcrypt hashes start with "$2", argon2id hashes are
+// PHC strings starting with "$argon2id$", so existing bcrypt hashes need no migration
+type MultiHasher struct {
+	current    algorithm
+	algorithms []algorithm
+	costs      costs
+}
+
+// NewMultiHasher creates a hasher hashing with cfg.PasswordHasher
+// Costs left at 0 are defaulted or calibrated, see resolveCosts
+func NewMultiHasher(cfg *config.AuthConfig) *MultiHasher {
+	c := resolveCosts(cfg)
+	bcrypt := newBcrypt(c.bcryptCost, cfg.BcryptPrehash)
+	argon2id := newArgon2id(cfg.Argon2Memory, c.argon2Iterations, cfg.Argon2Parallelism)
+
+	h := &MultiHasher{current: bcrypt, algorithms: []algorithm{bcrypt, argon2id}, costs: c}
+	if cfg.PasswordHasher == config.PasswordHasherArgon2id {
+		h.current = argon2id
+	}
+	return h
+}
+
+// Hash hashes password with the configured algorithm
+func (h *MultiHasher) Hash(password string) (string, error) {
+	return h.current.hash(password)
+}
+
+// Compare verifies password with the algorithm hash was made with
+func (h *MultiHasher) Compare(hash, password string) error {
+	alg := h.algorithmOf(hash)
+	if alg == nil {
+		return errUnknownAlgorithm
+	}
+	return alg.compare(hash, password)
+}
+
+// NeedsRehash reports whether hash is of another algorithm than the configured one, or uses other parameters
+func (h *MultiHasher) NeedsRehash(hash, password string) bool {
+	return !h.current.matches(hash) || h.current.outdated(hash, password)
+}
+
+func (h *MultiHasher) algorithmOf(hash string) algorithm {
+	for _, alg := range h.algorithms {
+		if alg.matches(hash) {
+			return alg
+		}
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"worker/internal/config"
+)
+
+// CORS is a compiled CORS policy
+// Origins are matched exactly ("https://app.example.com"), by subdomain wildcard
+// ("https://*.example.com") or with "*" for any origin. Requests from other origins
+// are passed through without CORS headers, so browsers block the response.
+type CORS struct {
+	anyOrigin        bool
+	origins          map[string]bool
+	wildcardOrigins  []wildcardOrigin
+	allowedMethods   string
+	allowedHeaders   string
+	exposedHeaders   string
+	allowCredentials bool
+	maxAge           string
+}
+
+// wildcardOrigin matches any subdomain of suffix with the given scheme
+type wildcardOrigin struct {
+	scheme string
+	suffix string // ".example.com", optionally with ":port"
+}
+
+// NewCORS validates the configured origin patterns and compiles the policy
+func NewCORS(cfg *config.CORSConfig) (*CORS, error) {
+	c := &CORS{
+		origins:          make(map[string]bool),
+		allowedMethods:   strings.Join(cfg.AllowedMethods, ", "),
+		allowedHeaders:   strings.Join(cfg.AllowedHeaders, ", "),
+		exposedHeaders:   strings.Join(cfg.ExposedHeaders, ", "),
+		allowCredentials: cfg.AllowCredentials,
+		maxAge:           strconv.Itoa(int(cfg.MaxAge.Seconds())),
+	}
+
+	for _, pattern := range cfg.AllowedOrigins {
+		if pattern == "*" {
+			if cfg.AllowCredentials {
+				return nil, fmt.Errorf("CORS origin \"*\" cannot be combined with CORS_ALLOW_CREDENTIALS")
+			}
+			c.anyOrigin = true
+			continue
+		}
+
+		u, err := url.Parse(pattern)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			(u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.User != nil {
+			return nil, fmt.Errorf("invalid CORS origin %q: expected scheme://host[:port]", pattern)
+		}
+
+		host := strings.ToLower(u.Host)
+		if strings.HasPrefix(host, "*.") {
+			if strings.Contains(host[2:], "*") {
+				return nil, fmt.Errorf("invalid CORS origin %q: only a leading \"*.\" wildcard is supported", pattern)
+			}
+			c.wildcardOrigins = append(c.wildcardOrigins, wildcardOrigin{scheme: u.Scheme, suffix: host[1:]})
+			continue
+		}
+		if strings.Contains(host, "*") {
+			return nil, fmt.Errorf("invalid CORS origin %q: only a leading \"*.\" wildcard is supported", pattern)
+		}
+		c.origins[u.Scheme+"://"+host] = true
+	}
+
+	return c, nil
+}
+
+// Handler wraps next with CORS handling, answering preflight requests directly
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+
+		if !c.allowsOrigin(origin) {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if c.anyOrigin && !c.allowCredentials {
+			w.Header().Set("Access-Control-Allow-Origin", "*")
+		} else {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+		}
+		if c.allowCredentials {
+			w.Header().Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if c.exposedHeaders != "" {
+				w.Header().Set("Access-Control-Expose-Headers", c.exposedHeaders)
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		w.Header().Add("Vary", "Access-Control-Request-Method")
+		w.Header().Add("Vary", "Access-Control-Request-Headers")
+		if !c.allowsMethod(r.Header.Get("Access-Control-Request-Method")) {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Access-Control-Allow-Methods", c.allowedMethods)
+		if c.allowedHeaders != "" {
+			w.Header().Set("Access-Control-Allow-Headers", c.allowedHeaders)
+		}
+		w.Header().Set("Access-Control-Max-Age", c.maxAge)
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (c *CORS) allowsOrigin(origin string) bool {
+	if c.anyOrigin {
+		return true
+	}
+
+	u, err := url.Parse(origin)
+	if err != nil || u.Host == "" {
+		return false
+	}
+	host := strings.ToLower(u.Host)
+	if c.origins[u.Scheme+"://"+host] {
+		return true
+	}
+	for _, w := range c.wildcardOrigins {
+		if u.Scheme == w.scheme && strings.HasSuffix(host, w.suffix) && len(host) > len(w.suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *CORS) allowsMethod(method string) bool {
+	return slices.Contains(strings.Split(c.allowedMethods, ", "), strings.ToUpper(method))
+}
@@ -0,0 +1,157 @@
+package httpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"worker/internal/config"
+)
+
+func newTestCORS(t *testing.T, origins ...string) http.Handler {
+	t.Helper()
+	cors, err := NewCORS(&config.CORSConfig{
+		AllowedOrigins: origins,
+		AllowedMethods: []string{"GET", "POST"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		ExposedHeaders: []string{"X-Correlation-Id"},
+		MaxAge:         10 * time.Minute,
+	})
+	if err != nil {
+		t.Fatalf("new CORS: %v", err)
+	}
+	return cors.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+}
+
+// serve sends a request with the given origin, as a preflight for requestMethod unless it is empty
+func serve(handler http.Handler, origin, requestMethod string) *httptest.ResponseRecorder {
+	method := http.MethodGet
+	if requestMethod != "" {
+		method = http.MethodOptions
+	}
+	r := httptest.NewRequest(method, "/v1/auth/profile", nil)
+	if origin != "" {
+		r.Header.Set("Origin", origin)
+	}
+	if requestMethod != "" {
+		r.Header.Set("Access-Control-Request-Method", requestMethod)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, r)
+	return w
+}
+
+func TestCORSPreflight(t *testing.T) {
+	handler := newTestCORS(t, "https://app.example.com", "https://*.example.org")
+
+	tests := []struct {
+		name          string
+		origin        string
+		requestMethod string
+		want          int
+		allowed       bool // Whether the origin is allowed, and so echoed in Access-Control-Allow-Origin
+	}{
+		{name: "exact origin", origin: "https://app.example.com", requestMethod: "POST", want: http.StatusNoContent, allowed: true},
+		{name: "origin case", origin: "https://APP.example.com", requestMethod: "POST", want: http.StatusNoContent, allowed: true},
+		{name: "wildcard subdomain", origin: "https://a.b.example.org", requestMethod: "GET", want: http.StatusNoContent, allowed: true},
+		{name: "method not allowed", origin: "https://app.example.com", requestMethod: "DELETE", want: http.StatusForbidden, allowed: true},
+		{name: "unknown origin", origin: "https://evil.example.net", requestMethod: "POST", want: http.StatusForbidden},
+		{name: "scheme mismatch", origin: "http://app.example.com", requestMethod: "POST", want: http.StatusForbidden},
+		{name: "wildcard apex", origin: "https://example.org", requestMethod: "GET", want: http.StatusForbidden},
+		{name: "suffix lookalike", origin: "https://evilexample.org", requestMethod: "GET", want: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(handler, tt.origin, tt.requestMethod)
+			if w.Code != tt.want {
+				t.Fatalf("preflight status = %d, want %d", w.Code, tt.want)
+			}
+			wantOrigin := ""
+			if tt.allowed {
+				wantOrigin = tt.origin
+			}
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != wantOrigin {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, wantOrigin)
+			}
+			if tt.want != http.StatusNoContent {
+				return
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods"); got != "GET, POST" {
+				t.Fatalf("Access-Control-Allow-Methods = %q, want GET, POST", got)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Headers"); got != "Authorization, Content-Type" {
+				t.Fatalf("Access-Control-Allow-Headers = %q", got)
+			}
+			if got := w.Header().Get("Access-Control-Max-Age"); got != "600" {
+				t.Fatalf("Access-Control-Max-Age = %q, want 600", got)
+			}
+		})
+	}
+}
+
+func TestCORSSimpleRequests(t *testing.T) {
+	handler := newTestCORS(t, "https://app.example.com")
+
+	// An allowed origin reaches the handler with CORS headers
+	w := serve(handler, "https://app.example.com", "")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" {
+		t.Fatalf("allowed origin: status %d, headers %v", w.Code, w.Header())
+	}
+	if got := w.Header().Get("Access-Control-Expose-Headers"); got != "X-Correlation-Id" {
+		t.Fatalf("Access-Control-Expose-Headers = %q, want X-Correlation-Id", got)
+	}
+
+	// Other origins reach it too, but without CORS headers the browser hides the response
+	w = serve(handler, "https://evil.example.net", "")
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Fatalf("unknown origin: status %d, headers %v", w.Code, w.Header())
+	}
+	if got := w.Header().Get("Vary"); got != "Origin" {
+		t.Fatalf("Vary = %q, want Origin", got)
+	}
+
+	// Same-origin and non-browser requests are left alone
+	w = serve(handler, "", "")
+	if w.Code != http.StatusOK || len(w.Header()) != 0 {
+		t.Fatalf("request without Origin: status %d, headers %v", w.Code, w.Header())
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	w := serve(newTestCORS(t, "*"), "https://anywhere.example.com", "POST")
+	if w.Code != http.StatusNoContent || w.Header().Get("Access-Control-Allow-Origin") != "*" {
+		t.Fatalf("any origin: status %d, headers %v", w.Code, w.Header())
+	}
+}
+
+func TestCORSDefaultAllowsNoOrigin(t *testing.T) {
+	w := serve(newTestCORS(t), "https://app.example.com", "GET")
+	if w.Code != http.StatusForbidden {
+		t.Fatalf("preflight with no configured origins: status %d, want %d", w.Code, http.StatusForbidden)
+	}
+}
+
+func TestNewCORSRejectsInvalidOrigins(t *testing.T) {
+	for _, origin := range []string{
+		"app.example.com",
+		"ftp://app.example.com",
+		"https://app.example.com/path",
+		"https://app.example.com?x=1",
+		"https://user@app.example.com",
+		"https://app.*.example.com",
+		"https://*.*.example.com",
+		"https://",
+	} {
+		if _, err := NewCORS(&config.CORSConfig{AllowedOrigins: []string{origin}}); err == nil {
+			t.Fatalf("NewCORS accepted origin %q", origin)
+		}
+	}
+
+	_, err := NewCORS(&config.CORSConfig{AllowedOrigins: []string{"*"}, AllowCredentials: true})
+	if err == nil {
+		t.Fatal("NewCORS accepted \"*\" with credentials")
+	}
+}
@@ -0,0 +1,68 @@
+package httpserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/config"
+)
+
+// Module provides the HTTP server used for browser-facing endpoints
+var Module = fx.Module("http",
+	fx.Provide(
+		NewCORS,
+		NewHTTPServer,
+	),
+	// Nothing depends on the server yet, so force its construction
+	fx.Invoke(func(*HTTPServer) {}),
+)
+
+// HTTPServer wraps the HTTP server and the mux handlers are registered on
+type HTTPServer struct {
+	Mux    *http.ServeMux
+	Server *http.Server
+}
+
+// NewHTTPServer creates the HTTP server on SERVER_PORT with the CORS policy applied to every route
+func NewHTTPServer(lc fx.Lifecycle, cfg *config.ServerConfig, cors *CORS, logger *zap.Logger) *HTTPServer {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	addr := fmt.Sprintf(":%s", cfg.Port)
+	server := &http.Server{
+		Addr:              addr,
+		Handler:           cors.Handler(mux),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			listener, err := net.Listen("tcp", addr)
+			if err != nil {
+				return fmt.Errorf("failed to listen on %s: %w", addr, err)
+			}
+			logger.Info("🚀 Starting HTTP server", zap.String("addr", addr))
+			go func() {
+				if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					logger.Error("HTTP server error", zap.Error(err))
+				}
+			}()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.Info("Shutting down HTTP server...")
+			return server.Shutdown(ctx)
+		},
+	})
+
+	return &HTTPServer{Mux: mux, Server: server}
+}
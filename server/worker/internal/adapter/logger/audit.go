@@ -0,0 +1,48 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"worker/internal/core/domain"
+)
+
+// AuditLog implements ports.AuditLog by writing events to the "audit" logger
+// Entries carry the request's correlation ID when the context has a request-scoped logger
+type AuditLog struct {
+	logger *zap.Logger
+}
+
+// NewAuditLog creates a new AuditLog
+func NewAuditLog(logger *zap.Logger) *AuditLog {
+	return &AuditLog{logger: logger}
+}
+
+// Record writes the event at info level
+func (a *AuditLog) Record(ctx context.Context, event domain.AuditEvent) {
+	fields := []zap.Field{
+		zap.String("event", event.Type),
+		zap.String("user_id", event.UserID),
+		zap.String("tenant_id", event.TenantID),
+		zap.String("ip", event.Client.IP),
+		zap.String("user_agent", event.Client.UserAgent),
+		zap.Time("at", event.At),
+	}
+	if event.Client.Country != "" {
+		fields = append(fields, zap.String("country", event.Client.Country))
+	}
+	if event.Reason != "" {
+		fields = append(fields, zap.String("reason", event.Reason))
+	}
+	if event.ActorID != "" {
+		fields = append(fields, zap.String("actor_id", event.ActorID))
+	}
+	if event.PreviousIP != "" || event.PreviousUA != "" {
+		fields = append(fields,
+			zap.String("previous_ip", event.PreviousIP),
+			zap.String("previous_user_agent", event.PreviousUA),
+		)
+	}
+	FromContext(ctx, a.logger).Named("audit").Info("Audit event", fields...)
+}
@@ -0,0 +1,23 @@
+package logger
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying a request-scoped logger
+func WithContext(ctx context.Context, l *zap.Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the request-scoped logger, which carries the request's correlation ID,
+// or fallback when the context has none
+func FromContext(ctx context.Context, fallback *zap.Logger) *zap.Logger {
+	if l, ok := ctx.Value(contextKey{}).(*zap.Logger); ok {
+		return l
+	}
+	return fallback
+}
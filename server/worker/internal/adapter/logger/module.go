@@ -3,29 +3,64 @@ package logger
 import (
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 
 	"worker/internal/config"
+	"worker/internal/core/ports"
 )
 
 // Module provides logger dependencies
 var Module = fx.Module("logger",
-	fx.Provide(NewLogger),
+	fx.Provide(
+		NewLogger,
+		fx.Annotate(
+			NewAuditLog,
+			fx.As(new(ports.AuditLog)),
+		),
+	),
+	fx.Invoke(watchLogLevel),
 )
 
 // NewLogger creates a new zap logger based on environment
-func NewLogger(cfg *config.ServerConfig) (*zap.Logger, error) {
-	var logger *zap.Logger
-	var err error
-
+// The returned level is shared with the logger so it can be changed at runtime
+func NewLogger(cfg *config.ServerConfig) (*zap.Logger, zap.AtomicLevel, error) {
+	var zapConfig zap.Config
 	if cfg.Env == "production" {
-		logger, err = zap.NewProduction()
+		zapConfig = zap.NewProductionConfig()
 	} else {
-		logger, err = zap.NewDevelopment()
+		zapConfig = zap.NewDevelopmentConfig()
+	}
+
+	if cfg.LogLevel != "" {
+		level, err := zapcore.ParseLevel(cfg.LogLevel)
+		if err != nil {
+			return nil, zap.AtomicLevel{}, err
+		}
+		zapConfig.Level.SetLevel(level)
 	}
 
+	logger, err := zapConfig.Build()
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, err
 	}
 
-	return logger, nil
+	return logger, zapConfig.Level, nil
+}
+
+// watchLogLevel applies LOG_LEVEL changes made while running
+func watchLogLevel(reloader *config.Reloader, level zap.AtomicLevel, logger *zap.Logger) {
+	reloader.Subscribe(func(dynamic config.DynamicConfig) {
+		if dynamic.LogLevel == "" {
+			return
+		}
+		next, err := zapcore.ParseLevel(dynamic.LogLevel)
+		if err != nil {
+			logger.Warn("Ignoring invalid LOG_LEVEL", zap.String("level", dynamic.LogLevel))
+			return
+		}
+		if next != level.Level() {
+			level.SetLevel(next)
+			logger.Info("Log level changed", zap.String("level", next.String()))
+		}
+	})
 }
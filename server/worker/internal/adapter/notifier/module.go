@@ -0,0 +1,23 @@
+package notifier
+
+import (
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/config"
+	"worker/internal/core/ports"
+)
+
+// Module provides account event notification dependencies
+var Module = fx.Module("notifier",
+	fx.Provide(NewNotifier),
+)
+
+// NewNotifier returns a webhook notifier, or a no-op notifier when no webhook is configured
+func NewNotifier(cfg *config.NotifierConfig, logger *zap.Logger) ports.Notifier {
+	if !cfg.Enabled() {
+		return NoopNotifier{}
+	}
+	logger.Info("✅ Account event webhook enabled", zap.Bool("signed", cfg.WebhookSecret != ""))
+	return NewWebhookNotifier(cfg)
+}
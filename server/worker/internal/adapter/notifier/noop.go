@@ -0,0 +1,30 @@
+package notifier
+
+import (
+	"context"
+	"time"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/ports"
+)
+
+// Ensure NoopNotifier implements ports.Notifier
+var _ ports.Notifier = NoopNotifier{}
+
+// NoopNotifier drops every event, used when no webhook is configured
+type NoopNotifier struct{}
+
+// UserRegistered always succeeds
+func (NoopNotifier) UserRegistered(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow) error {
+	return nil
+}
+
+// UserApproved always succeeds
+func (NoopNotifier) UserApproved(ctx context.Context, user *sqlc.GetUserByIDRow, at time.Time) error {
+	return nil
+}
+
+// UserRejected always succeeds
+func (NoopNotifier) UserRejected(ctx context.Context, user *sqlc.GetUserByIDRow, reason string, at time.Time) error {
+	return nil
+}
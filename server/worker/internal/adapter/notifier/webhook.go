@@ -0,0 +1,144 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/common/utils"
+	"worker/internal/config"
+	"worker/internal/core/ports"
+)
+
+// Ensure WebhookNotifier implements ports.Notifier
+var _ ports.Notifier = (*WebhookNotifier)(nil)
+
+// Event types sent in the "type" field of the webhook body
+const (
+	eventUserRegistered = "user.registered"
+	eventUserApproved   = "user.approved"
+	eventUserRejected   = "user.rejected"
+)
+
+// signatureHeader carries the hex HMAC-SHA256 of the body when NOTIFY_WEBHOOK_SECRET is set
+const signatureHeader = "X-Signature"
+
+// WebhookNotifier implements ports.Notifier by POSTing each event as JSON to NOTIFY_WEBHOOK_URL
+// Any 2xx answer counts as delivered; events are not retried
+type WebhookNotifier struct {
+	url        string
+	secret     []byte
+	httpClient *http.Client
+}
+
+// NewWebhookNotifier creates a notifier for the configured webhook
+func NewWebhookNotifier(cfg *config.NotifierConfig) *WebhookNotifier {
+	return &WebhookNotifier{
+		url:        cfg.WebhookURL,
+		secret:     []byte(cfg.WebhookSecret),
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// event is the webhook body
+type event struct {
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	User       eventUser `json:"user"`
+	Reason     string    `json:"reason,omitempty"` // Only set for user.rejected
+}
+
+// eventUser is the part of the user a receiver needs to greet or verify them; never the password hash
+type eventUser struct {
+	ID       string `json:"id"`
+	TenantID string `json:"tenant_id"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Locale   string `json:"locale,omitempty"`
+}
+
+// UserRegistered posts a user.registered event
+func (n *WebhookNotifier) UserRegistered(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow) error {
+	return n.send(ctx, event{
+		Type:       eventUserRegistered,
+		OccurredAt: user.CreatedAt.Time.UTC(),
+		User: eventUser{
+			ID:       user.ID.String(),
+			TenantID: user.TenantID,
+			Email:    user.Email,
+			Username: user.Username,
+			FullName: user.FullName,
+			Locale:   utils.PtrStringValue(user.Locale),
+		},
+	})
+}
+
+// UserApproved posts a user.approved event
+func (n *WebhookNotifier) UserApproved(ctx context.Context, user *sqlc.GetUserByIDRow, at time.Time) error {
+	return n.send(ctx, event{
+		Type:       eventUserApproved,
+		OccurredAt: at.UTC(),
+		User:       eventUserOf(user),
+	})
+}
+
+// UserRejected posts a user.rejected event with the admin's reason
+func (n *WebhookNotifier) UserRejected(ctx context.Context, user *sqlc.GetUserByIDRow, reason string, at time.Time) error {
+	return n.send(ctx, event{
+		Type:       eventUserRejected,
+		OccurredAt: at.UTC(),
+		User:       eventUserOf(user),
+		Reason:     reason,
+	})
+}
+
+func eventUserOf(user *sqlc.GetUserByIDRow) eventUser {
+	return eventUser{
+		ID:       user.ID.String(),
+		TenantID: user.TenantID,
+		Email:    user.Email,
+		Username: user.Username,
+		FullName: user.FullName,
+		Locale:   utils.PtrStringValue(user.Locale),
+	}
+}
+
+func (n *WebhookNotifier) send(ctx context.Context, e event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.secret) > 0 {
+		mac := hmac.New(sha256.New, n.secret)
+		mac.Write(body)
+		req.Header.Set(signatureHeader, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+	}
+
+	resp, err := n.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("notify webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	// Drain a little so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("notify webhook: unexpected status %d for %s", resp.StatusCode, e.Type)
+	}
+	return nil
+}
@@ -0,0 +1,135 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+)
+
+// delivery is a request received by a test webhook
+type delivery struct {
+	header http.Header
+	body   []byte
+}
+
+// newTestWebhook returns a notifier posting to a server answering with status, and the deliveries it receives
+func newTestWebhook(t *testing.T, secret string, status int) (*WebhookNotifier, *[]delivery) {
+	t.Helper()
+	var received []delivery
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Method != http.MethodPost {
+			t.Errorf("webhook called with %s, want POST", r.Method)
+		}
+		received = append(received, delivery{header: r.Header.Clone(), body: body})
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return NewWebhookNotifier(&config.NotifierConfig{WebhookURL: server.URL, WebhookSecret: secret, Timeout: 5 * time.Second}), &received
+}
+
+func registeredAlice() *sqlc.GetUserByEmailOrUsernameRow {
+	locale := "vi"
+	return &sqlc.GetUserByEmailOrUsernameRow{
+		ID:        uuid.New(),
+		TenantID:  "default",
+		Email:     "alice@example.com",
+		Username:  "alice",
+		Password:  "$2a$12$hash",
+		FullName:  "Alice",
+		Locale:    &locale,
+		CreatedAt: pgtype.Timestamp{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true},
+	}
+}
+
+func TestWebhookUserRegistered(t *testing.T) {
+	n, received := newTestWebhook(t, "", http.StatusNoContent)
+	alice := registeredAlice()
+
+	if err := n.UserRegistered(context.Background(), alice); err != nil {
+		t.Fatalf("user registered: %v", err)
+	}
+	if len(*received) != 1 {
+		t.Fatalf("webhook received %d deliveries, want 1", len(*received))
+	}
+	got := (*received)[0]
+	if got.header.Get("Content-Type") != "application/json" || got.header.Get(signatureHeader) != "" {
+		t.Fatalf("headers = %v, want an unsigned JSON body", got.header)
+	}
+	if strings.Contains(string(got.body), alice.Password) {
+		t.Fatalf("body carries the password hash: %s", got.body)
+	}
+
+	var e event
+	if err := json.Unmarshal(got.body, &e); err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	want := event{
+		Type:       eventUserRegistered,
+		OccurredAt: alice.CreatedAt.Time.UTC(),
+		User: eventUser{
+			ID: alice.ID.String(), TenantID: "default", Email: "alice@example.com",
+			Username: "alice", FullName: "Alice", Locale: "vi",
+		},
+	}
+	if !e.OccurredAt.Equal(want.OccurredAt) || e.Type != want.Type || e.User != want.User || e.Reason != "" {
+		t.Fatalf("event = %+v, want %+v", e, want)
+	}
+}
+
+func TestWebhookSignature(t *testing.T) {
+	n, received := newTestWebhook(t, "webhook-secret", http.StatusOK)
+	if err := n.UserRegistered(context.Background(), registeredAlice()); err != nil {
+		t.Fatalf("user registered: %v", err)
+	}
+
+	got := (*received)[0]
+	mac := hmac.New(sha256.New, []byte("webhook-secret"))
+	mac.Write(got.body)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); got.header.Get(signatureHeader) != want {
+		t.Fatalf("signature = %q, want %q", got.header.Get(signatureHeader), want)
+	}
+}
+
+func TestWebhookFailures(t *testing.T) {
+	n, _ := newTestWebhook(t, "", http.StatusBadGateway)
+	if err := n.UserRegistered(context.Background(), registeredAlice()); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("user registered = %v, want an error naming the status", err)
+	}
+
+	// An unreachable webhook fails the delivery
+	unreachable := httptest.NewServer(http.NotFoundHandler())
+	unreachable.Close()
+	n = NewWebhookNotifier(&config.NotifierConfig{WebhookURL: unreachable.URL, Timeout: time.Second})
+	if err := n.UserRegistered(context.Background(), registeredAlice()); err == nil {
+		t.Fatal("delivery to an unreachable webhook succeeded")
+	}
+}
+
+func TestNewNotifier(t *testing.T) {
+	if _, ok := NewNotifier(&config.NotifierConfig{}, zap.NewNop()).(NoopNotifier); !ok {
+		t.Fatal("notifier without a webhook is not the no-op notifier")
+	}
+	if err := (NoopNotifier{}).UserRegistered(context.Background(), registeredAlice()); err != nil {
+		t.Fatalf("no-op user registered: %v", err)
+	}
+	cfg := &config.NotifierConfig{WebhookURL: "https://hooks.example.com/accounts", Timeout: time.Second}
+	if _, ok := NewNotifier(cfg, zap.NewNop()).(*WebhookNotifier); !ok {
+		t.Fatal("notifier with a webhook is not the webhook notifier")
+	}
+}
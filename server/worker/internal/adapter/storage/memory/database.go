@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure the in-memory database adapters implement their ports
+var (
+	_ ports.ReadOnlyQuerier   = (*StatsQuerier)(nil)
+	_ ports.DatabaseHealth    = Health{}
+	_ ports.DatabaseStats     = PoolStats{}
+	_ ports.LastLoginRecorder = (*LastLoginRecorder)(nil)
+)
+
+// StatsQuerier is the in-memory ports.ReadOnlyQuerier
+type StatsQuerier struct {
+	db handle
+}
+
+// NewStatsQuerier creates a StatsQuerier on store
+func NewStatsQuerier(store *Store) *StatsQuerier {
+	return &StatsQuerier{db: handle{store: store}}
+}
+
+// CountUsersByRole counts the tenant's users per primary role, including roles nobody holds, ordered by role code
+func (q *StatsQuerier) CountUsersByRole(ctx context.Context, tenantID string) ([]sqlc.CountUsersByRoleRow, error) {
+	rows := []sqlc.CountUsersByRoleRow{}
+	err := q.db.do(func(t *tables) error {
+		for _, role := range t.roles {
+			row := sqlc.CountUsersByRoleRow{RoleCode: role.Code, RoleName: role.Name}
+			for _, user := range t.users {
+				if user.RoleID == role.ID && user.TenantID == tenantID {
+					row.UserCount++
+				}
+			}
+			rows = append(rows, row)
+		}
+		return nil
+	})
+	slices.SortFunc(rows, func(a, b sqlc.CountUsersByRoleRow) int {
+		return cmp.Compare(a.RoleCode, b.RoleCode)
+	})
+	return rows, err
+}
+
+// CountRegistrationsPerDay counts the tenant's registrations per day since the given time
+// Days without registrations are omitted
+func (q *StatsQuerier) CountRegistrationsPerDay(ctx context.Context, tenantID string, since time.Time) ([]sqlc.CountRegistrationsPerDayRow, error) {
+	perDay := make(map[time.Time]int64)
+	err := q.db.do(func(t *tables) error {
+		for _, user := range t.users {
+			if user.TenantID != tenantID || user.CreatedAt.Time.Before(since) {
+				continue
+			}
+			created := user.CreatedAt.Time
+			perDay[time.Date(created.Year(), created.Month(), created.Day(), 0, 0, 0, 0, time.UTC)]++
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows := make([]sqlc.CountRegistrationsPerDayRow, 0, len(perDay))
+	for day, registrations := range perDay {
+		rows = append(rows, sqlc.CountRegistrationsPerDayRow{
+			Day:           pgtype.Date{Time: day, Valid: true},
+			Registrations: registrations,
+		})
+	}
+	slices.SortFunc(rows, func(a, b sqlc.CountRegistrationsPerDayRow) int {
+		return a.Day.Time.Compare(b.Day.Time)
+	})
+	return rows, nil
+}
+
+// Health is the in-memory ports.DatabaseHealth; the store is always up and has no schema to check
+type Health struct{}
+
+// NewHealth creates a new Health
+func NewHealth() Health {
+	return Health{}
+}
+
+// Ping always succeeds
+func (Health) Ping(ctx context.Context) error {
+	return nil
+}
+
+// CheckSchema always succeeds
+func (Health) CheckSchema(ctx context.Context) error {
+	return nil
+}
+
+// PoolStats is the in-memory ports.DatabaseStats; there is no connection pool, so every count is zero
+type PoolStats struct{}
+
+// NewPoolStats creates a new PoolStats
+func NewPoolStats() PoolStats {
+	return PoolStats{}
+}
+
+// Stats returns empty pool statistics
+func (PoolStats) Stats() *domain.DBStats {
+	return &domain.DBStats{}
+}
+
+// LastLoginRecorder is the in-memory ports.LastLoginRecorder
+// Writing to the store is cheap, so logins are stored right away instead of queued
+type LastLoginRecorder struct {
+	users *UserRepository
+}
+
+// NewLastLoginRecorder creates a LastLoginRecorder storing logins on the users of store
+func NewLastLoginRecorder(store *Store) *LastLoginRecorder {
+	return &LastLoginRecorder{users: NewUserRepository(store)}
+}
+
+// Record stores a login, unless a more recent one is stored already
+func (r *LastLoginRecorder) Record(login domain.LastLogin) {
+	_ = r.users.UpdateLastLogins(context.Background(), []domain.LastLogin{login}) // Never fails
+}
@@ -0,0 +1,98 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"worker/internal/core/ports"
+)
+
+// Ensure LoginThrottle implements ports.LoginThrottle
+var _ ports.LoginThrottle = (*LoginThrottle)(nil)
+
+// LoginThrottle is the default in-process ports.LoginThrottle
+// The first failure of a key is answered at once, the next ones after base, 2*base, 4*base...
+// up to max. Like the RiskEvaluator, counts are kept per replica and lost on restart
+type LoginThrottle struct {
+	mu        sync.Mutex
+	failures  map[string]throttleEntry
+	nextPrune time.Time
+
+	base   time.Duration
+	max    time.Duration
+	window time.Duration
+	clock  ports.Clock
+}
+
+type throttleEntry struct {
+	count int
+	last  time.Time
+}
+
+// NewLoginThrottle creates a LoginThrottle forgetting a key window after its last failure
+// A non-positive base disables the delays
+func NewLoginThrottle(base, max, window time.Duration, clock ports.Clock) *LoginThrottle {
+	return &LoginThrottle{
+		failures: make(map[string]throttleEntry),
+		base:     base,
+		max:      max,
+		window:   window,
+		clock:    clock,
+	}
+}
+
+// RecordFailure counts a failed login of key and returns its delay
+// A key idle for longer than the window starts over; the other idle keys are pruned at most once per
+// window, so a failure costs O(1) amortized instead of a scan of every key
+func (t *LoginThrottle) RecordFailure(ctx context.Context, key string) time.Duration {
+	if t.base <= 0 {
+		return 0
+	}
+
+	now := t.clock.Now()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !now.Before(t.nextPrune) {
+		t.prune(now)
+	}
+	entry := t.failures[key]
+	if now.Sub(entry.last) > t.window {
+		entry.count = 0
+	}
+	entry.count++
+	entry.last = now
+	t.failures[key] = entry
+	return t.delay(entry.count)
+}
+
+// Reset forgets the failures of key
+func (t *LoginThrottle) Reset(ctx context.Context, key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.failures, key)
+}
+
+// prune drops the keys idle for longer than the window and schedules the next pass
+func (t *LoginThrottle) prune(now time.Time) {
+	for k, entry := range t.failures {
+		if now.Sub(entry.last) > t.window {
+			delete(t.failures, k)
+		}
+	}
+	t.nextPrune = now.Add(t.window)
+}
+
+// delay returns the delay of the failures-th consecutive failure
+func (t *LoginThrottle) delay(failures int) time.Duration {
+	if failures < 2 {
+		return 0
+	}
+	delay := t.base
+	for i := 2; i < failures && delay < t.max; i++ {
+		delay *= 2
+	}
+	return min(delay, t.max)
+}
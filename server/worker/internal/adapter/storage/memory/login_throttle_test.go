@@ -0,0 +1,94 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"worker/internal/common/clock"
+)
+
+func newTestThrottle(base time.Duration) (*LoginThrottle, *clock.Fake) {
+	fake := clock.NewFake(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC))
+	return NewLoginThrottle(base, 8*time.Second, 15*time.Minute, fake), fake
+}
+
+// recordFailures records n failures of key, advancing the clock by each delay like a client would wait
+func recordFailures(throttle *LoginThrottle, fake *clock.Fake, key string, n int) []time.Duration {
+	delays := make([]time.Duration, n)
+	for i := range delays {
+		delays[i] = throttle.RecordFailure(context.Background(), key)
+		fake.Advance(delays[i])
+	}
+	return delays
+}
+
+func TestLoginThrottleDelays(t *testing.T) {
+	throttle, fake := newTestThrottle(time.Second)
+
+	got := recordFailures(throttle, fake, "alice|10.0.0.1", 7)
+	want := []time.Duration{0, time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second, 8 * time.Second}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Fatalf("delays = %v, want %v", got, want)
+	}
+
+	// Keys are throttled independently
+	if delay := throttle.RecordFailure(context.Background(), "alice|10.0.0.2"); delay != 0 {
+		t.Fatalf("first failure of another key delayed by %v", delay)
+	}
+}
+
+func TestLoginThrottleReset(t *testing.T) {
+	throttle, fake := newTestThrottle(time.Second)
+	recordFailures(throttle, fake, "alice", 4)
+
+	throttle.Reset(context.Background(), "alice")
+	if got := recordFailures(throttle, fake, "alice", 2); got[0] != 0 || got[1] != time.Second {
+		t.Fatalf("delays after a reset = %v, want [0s 1s]", got)
+	}
+}
+
+func TestLoginThrottleWindow(t *testing.T) {
+	throttle, fake := newTestThrottle(time.Second)
+	recordFailures(throttle, fake, "alice", 4)
+
+	// Failures within the window keep counting
+	fake.Advance(14 * time.Minute)
+	if delay := throttle.RecordFailure(context.Background(), "alice"); delay != 8*time.Second {
+		t.Fatalf("delay within the window = %v, want 8s", delay)
+	}
+
+	// A key idle for longer than the window starts over
+	fake.Advance(15*time.Minute + time.Second)
+	if delay := throttle.RecordFailure(context.Background(), "alice"); delay != 0 {
+		t.Fatalf("delay after the window = %v, want 0", delay)
+	}
+}
+
+func TestLoginThrottlePrunesIdleKeys(t *testing.T) {
+	throttle, fake := newTestThrottle(time.Second)
+	for i := range 100 {
+		throttle.RecordFailure(context.Background(), fmt.Sprintf("user%d", i))
+	}
+
+	// Idle keys stay until the next pass, at most one window later
+	fake.Advance(15*time.Minute + time.Second)
+	throttle.RecordFailure(context.Background(), "alice")
+	if n := len(throttle.failures); n != 1 {
+		t.Fatalf("%d keys after the prune pass, want only alice", n)
+	}
+	throttle.RecordFailure(context.Background(), "bob")
+	if n := len(throttle.failures); n != 2 {
+		t.Fatalf("%d keys, want alice and bob", n)
+	}
+}
+
+func TestLoginThrottleDisabled(t *testing.T) {
+	throttle, fake := newTestThrottle(0)
+	for _, delay := range recordFailures(throttle, fake, "alice", 5) {
+		if delay != 0 {
+			t.Fatalf("disabled throttle delayed a failure by %v", delay)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package memory
+
+import (
+	"go.uber.org/fx"
+
+	"worker/internal/config"
+	"worker/internal/core/ports"
+)
+
+// Module provides in-process cache, pub/sub, login risk and rate limit dependencies
+var Module = fx.Module("memory",
+	fx.Provide(
+		fx.Annotate(
+			newPermissionCache,
+			fx.As(new(ports.PermissionCache)),
+		),
+		fx.Annotate(
+			newTokenVersionCache,
+			fx.As(new(ports.TokenVersionCache)),
+		),
+		fx.Annotate(
+			newRiskEvaluator,
+			fx.As(new(ports.RiskEvaluator)),
+		),
+		fx.Annotate(
+			newLoginThrottle,
+			fx.As(new(ports.LoginThrottle)),
+		),
+		fx.Annotate(
+			newAvailabilityRateLimiter,
+			fx.As(new(ports.RateLimiter)),
+		),
+		fx.Annotate(
+			newRevocationBroker,
+			fx.As(new(ports.RevocationBroker)),
+		),
+	),
+)
+
+// revocationBufferSize is how many revocation events a stream subscriber may lag behind
+const revocationBufferSize = 256
+
+func newPermissionCache(cfg *config.AuthConfig) *PermissionCache {
+	return NewPermissionCache(cfg.PermissionCacheTTL)
+}
+
+func newTokenVersionCache(cfg *config.AuthConfig) *TokenVersionCache {
+	return NewTokenVersionCache(cfg.TokenVersionCacheTTL)
+}
+
+func newRiskEvaluator(cfg *config.AuthConfig, clock ports.Clock) *RiskEvaluator {
+	return NewRiskEvaluator(cfg.RiskFailureWindow, cfg.RiskChallengeFailures, cfg.RiskDenyFailures, clock)
+}
+
+func newLoginThrottle(cfg *config.AuthConfig, clock ports.Clock) *LoginThrottle {
+	return NewLoginThrottle(cfg.LoginDelayBase, cfg.LoginDelayMax, cfg.RiskFailureWindow, clock)
+}
+
+func newAvailabilityRateLimiter(cfg *config.AuthConfig, clock ports.Clock) *RateLimiter {
+	return NewRateLimiter(cfg.AvailabilityCheckLimit, cfg.AvailabilityCheckWindow, clock)
+}
+
+func newRevocationBroker() *RevocationBroker {
+	return NewRevocationBroker(revocationBufferSize)
+}
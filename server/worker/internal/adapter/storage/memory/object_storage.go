@@ -0,0 +1,86 @@
+package memory
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure ObjectStorage implements ports.ObjectStorage
+var _ ports.ObjectStorage = (*ObjectStorage)(nil)
+
+// ObjectStorage is an in-memory ports.ObjectStorage for tests and local development
+// Presigned URLs are not reachable over HTTP; use Put to simulate an upload
+type ObjectStorage struct {
+	mu        sync.RWMutex
+	objects   map[string]*domain.ObjectInfo
+	publicURL string
+}
+
+// NewObjectStorage creates an empty in-memory object storage
+func NewObjectStorage(publicURL string) *ObjectStorage {
+	if publicURL == "" {
+		publicURL = "memory://objects"
+	}
+	return &ObjectStorage{
+		objects:   make(map[string]*domain.ObjectInfo),
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+	}
+}
+
+// Put stores object metadata as if it had been uploaded through a presigned URL
+func (s *ObjectStorage) Put(key, contentType string, size int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.objects[key] = &domain.ObjectInfo{Key: key, Size: size, ContentType: contentType}
+}
+
+// PresignPut returns a fake upload URL for the object
+// Nothing enforces the content type or length; Put stores whatever a test uploads
+func (s *ObjectStorage) PresignPut(ctx context.Context, key, contentType string, contentLength int64, expires time.Duration) (string, error) {
+	return s.publicURL + "/" + key + "?upload=true", nil
+}
+
+// Stat retrieves object metadata
+func (s *ObjectStorage) Stat(ctx context.Context, key string) (*domain.ObjectInfo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	info, ok := s.objects[key]
+	if !ok {
+		return nil, domain.ErrObjectNotFound
+	}
+	copied := *info
+	return &copied, nil
+}
+
+// Delete removes an object
+func (s *ObjectStorage) Delete(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.objects, key)
+	return nil
+}
+
+// List returns the keys of the objects whose key starts with prefix, in no particular order
+func (s *ObjectStorage) List(ctx context.Context, prefix string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var keys []string
+	for key := range s.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	return keys, nil
+}
+
+// PublicURL returns the URL an object is publicly served from
+func (s *ObjectStorage) PublicURL(key string) string {
+	return s.publicURL + "/" + key
+}
@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure PasswordResetCodeRepository implements ports.PasswordResetCodeRepository
+var _ ports.PasswordResetCodeRepository = (*PasswordResetCodeRepository)(nil)
+
+// PasswordResetCodeRepository is the in-memory ports.PasswordResetCodeRepository
+type PasswordResetCodeRepository struct {
+	db handle
+}
+
+// NewPasswordResetCodeRepository creates a PasswordResetCodeRepository on store
+func NewPasswordResetCodeRepository(store *Store) *PasswordResetCodeRepository {
+	return &PasswordResetCodeRepository{db: handle{store: store}}
+}
+
+// Save stores a user's new reset code, replacing the pending one and resetting its attempts
+func (r *PasswordResetCodeRepository) Save(ctx context.Context, params sqlc.UpsertPasswordResetCodeParams) error {
+	now := r.db.store.now()
+	return r.db.do(func(t *tables) error {
+		if _, ok := t.users[params.UserID]; !ok {
+			return fmt.Errorf("save password reset code: user %s does not exist", params.UserID)
+		}
+		t.resetCodes[params.UserID] = sqlc.PasswordResetCode{
+			UserID:    params.UserID,
+			CodeHash:  params.CodeHash,
+			ExpiresAt: params.ExpiresAt,
+			CreatedAt: now,
+		}
+		return nil
+	})
+}
+
+// RecordAttempt counts a verification attempt on a user's pending code and returns the code
+func (r *PasswordResetCodeRepository) RecordAttempt(ctx context.Context, userID uuid.UUID) (*sqlc.PasswordResetCode, error) {
+	var code sqlc.PasswordResetCode
+	err := r.db.do(func(t *tables) error {
+		var ok bool
+		if code, ok = t.resetCodes[userID]; !ok {
+			return domain.ErrTokenNotFound
+		}
+		code.Attempts++
+		t.resetCodes[userID] = code
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &code, nil
+}
+
+// Delete deletes a user's pending code
+func (r *PasswordResetCodeRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return r.db.do(func(t *tables) error {
+		delete(t.resetCodes, userID)
+		return nil
+	})
+}
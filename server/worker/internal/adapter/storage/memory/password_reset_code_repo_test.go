@@ -0,0 +1,63 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+)
+
+func TestPasswordResetCodeRepository(t *testing.T) {
+	store, fake := newTestStore(t)
+	codes := NewPasswordResetCodeRepository(store)
+	ctx := context.Background()
+	alice := mustCreateUser(t, store, "alice")
+	save := func(hash string) {
+		t.Helper()
+		err := codes.Save(ctx, sqlc.UpsertPasswordResetCodeParams{UserID: alice, CodeHash: hash, ExpiresAt: timestamp(fake.Now().Add(time.Hour))})
+		if err != nil {
+			t.Fatalf("save code: %v", err)
+		}
+	}
+
+	if _, err := codes.RecordAttempt(ctx, alice); !errors.Is(err, domain.ErrTokenNotFound) {
+		t.Fatalf("attempt without a code = %v, want ErrTokenNotFound", err)
+	}
+
+	// Each attempt is counted before the code is handed out for comparison
+	save("first")
+	for want := int32(1); want <= 2; want++ {
+		code, err := codes.RecordAttempt(ctx, alice)
+		if err != nil {
+			t.Fatalf("record attempt: %v", err)
+		}
+		if code.CodeHash != "first" || code.Attempts != want {
+			t.Fatalf("code = %s after %d attempts, want first", code.CodeHash, code.Attempts)
+		}
+	}
+
+	// A new code replaces the pending one and starts over
+	save("second")
+	code, err := codes.RecordAttempt(ctx, alice)
+	if err != nil {
+		t.Fatalf("record attempt: %v", err)
+	}
+	if code.CodeHash != "second" || code.Attempts != 1 {
+		t.Fatalf("code = %s with %d attempts, want second with 1", code.CodeHash, code.Attempts)
+	}
+
+	if err := codes.Delete(ctx, alice); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := codes.RecordAttempt(ctx, alice); !errors.Is(err, domain.ErrTokenNotFound) {
+		t.Fatalf("attempt after delete = %v, want ErrTokenNotFound", err)
+	}
+	if err := codes.Save(ctx, sqlc.UpsertPasswordResetCodeParams{UserID: uuid.New(), CodeHash: "orphan"}); err == nil {
+		t.Fatal("saved a code for a user that does not exist")
+	}
+}
@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/ports"
+)
+
+// Ensure PermissionCache implements ports.PermissionCache
+var _ ports.PermissionCache = (*PermissionCache)(nil)
+
+type permissionEntry struct {
+	permissions []string
+	expiresAt   time.Time
+}
+
+// PermissionCache is an in-process ports.PermissionCache with per-entry TTL
+// A non-positive TTL disables caching
+type PermissionCache struct {
+	mu      sync.RWMutex
+	entries map[uuid.UUID]permissionEntry
+	ttl     time.Duration
+}
+
+// NewPermissionCache creates an empty permission cache
+func NewPermissionCache(ttl time.Duration) *PermissionCache {
+	return &PermissionCache{
+		entries: make(map[uuid.UUID]permissionEntry),
+		ttl:     ttl,
+	}
+}
+
+// Get returns the cached permissions of a user if present and not expired
+func (c *PermissionCache) Get(ctx context.Context, userID uuid.UUID) ([]string, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[userID]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return nil, false
+	}
+	return slices.Clone(entry.permissions), true
+}
+
+// Set caches the permissions of a user
+func (c *PermissionCache) Set(ctx context.Context, userID uuid.UUID, permissions []string) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[userID] = permissionEntry{
+		permissions: slices.Clone(permissions),
+		expiresAt:   time.Now().Add(c.ttl),
+	}
+}
+
+// Invalidate drops the cached permissions of a user
+func (c *PermissionCache) Invalidate(ctx context.Context, userID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, userID)
+}
+
+// InvalidateAll drops every cached entry
+func (c *PermissionCache) InvalidateAll(ctx context.Context) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	clear(c.entries)
+}
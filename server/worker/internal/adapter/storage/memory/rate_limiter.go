@@ -0,0 +1,77 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"worker/internal/core/ports"
+)
+
+// Ensure RateLimiter implements ports.RateLimiter
+var _ ports.RateLimiter = (*RateLimiter)(nil)
+
+// RateLimiter is the default in-process ports.RateLimiter, counting fixed windows per key
+// Like the LoginThrottle, counts are kept per replica and lost on restart
+type RateLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]rateWindow
+	nextPrune time.Time
+
+	limit  int
+	window time.Duration
+	clock  ports.Clock
+}
+
+type rateWindow struct {
+	used  int
+	start time.Time
+}
+
+// NewRateLimiter creates a RateLimiter allowing limit units per key and window
+// A non-positive limit disables the limiter
+func NewRateLimiter(limit int, window time.Duration, clock ports.Clock) *RateLimiter {
+	return &RateLimiter{
+		windows: make(map[string]rateWindow),
+		limit:   limit,
+		window:  window,
+		clock:   clock,
+	}
+}
+
+// Allow spends n units of key if they fit in its current window
+// A key whose window has ended starts a new one; the other ended windows are pruned at most once per
+// window, so a call costs O(1) amortized instead of a scan of every key
+func (l *RateLimiter) Allow(ctx context.Context, key string, n int) bool {
+	if l.limit <= 0 {
+		return true
+	}
+
+	now := l.clock.Now()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !now.Before(l.nextPrune) {
+		l.prune(now)
+	}
+	w, ok := l.windows[key]
+	if !ok || now.Sub(w.start) >= l.window {
+		w = rateWindow{start: now}
+	}
+	if w.used+n > l.limit {
+		return false
+	}
+	w.used += n
+	l.windows[key] = w
+	return true
+}
+
+// prune drops the keys whose window has ended and schedules the next pass
+func (l *RateLimiter) prune(now time.Time) {
+	for k, w := range l.windows {
+		if now.Sub(w.start) >= l.window {
+			delete(l.windows, k)
+		}
+	}
+	l.nextPrune = now.Add(l.window)
+}
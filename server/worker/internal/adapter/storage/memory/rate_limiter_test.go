@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"worker/internal/common/clock"
+)
+
+func newTestRateLimiter(limit int) (*RateLimiter, *clock.Fake) {
+	fake := clock.NewFake(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC))
+	return NewRateLimiter(limit, time.Minute, fake), fake
+}
+
+func TestRateLimiterWindow(t *testing.T) {
+	limiter, fake := newTestRateLimiter(5)
+	ctx := context.Background()
+
+	if !limiter.Allow(ctx, "alice", 3) || !limiter.Allow(ctx, "alice", 2) {
+		t.Fatal("units within the limit were denied")
+	}
+	if limiter.Allow(ctx, "alice", 1) {
+		t.Fatal("a unit over the limit was allowed")
+	}
+	// Keys are limited independently
+	if !limiter.Allow(ctx, "bob", 5) {
+		t.Fatal("another key was denied")
+	}
+
+	// A key starts a new window once its window has ended, whether or not a prune pass ran
+	fake.Advance(time.Minute)
+	if !limiter.Allow(ctx, "alice", 5) {
+		t.Fatal("units of a new window were denied")
+	}
+}
+
+func TestRateLimiterDeniedCallSpendsNothing(t *testing.T) {
+	limiter, _ := newTestRateLimiter(5)
+	ctx := context.Background()
+
+	limiter.Allow(ctx, "alice", 3)
+	if limiter.Allow(ctx, "alice", 3) {
+		t.Fatal("a batch over the limit was allowed")
+	}
+	if !limiter.Allow(ctx, "alice", 2) {
+		t.Fatal("the denied batch spent units")
+	}
+}
+
+// A batch of n units is allowed exactly when n single units in a row would all be
+func TestRateLimiterBatchMatchesSingleUnits(t *testing.T) {
+	ctx := context.Background()
+	for spent := range 6 {
+		for n := 1; n <= 6; n++ {
+			batched, _ := newTestRateLimiter(5)
+			single, _ := newTestRateLimiter(5)
+			batched.Allow(ctx, "alice", spent)
+			for range spent {
+				single.Allow(ctx, "alice", 1)
+			}
+
+			allSingle := true
+			for range n {
+				allSingle = single.Allow(ctx, "alice", 1) && allSingle
+			}
+			if got := batched.Allow(ctx, "alice", n); got != allSingle {
+				t.Fatalf("after %d spent, a batch of %d allowed = %t, single units all allowed = %t", spent, n, got, allSingle)
+			}
+		}
+	}
+}
+
+func TestRateLimiterPrunesOncePerWindow(t *testing.T) {
+	limiter, fake := newTestRateLimiter(5)
+	ctx := context.Background()
+	for i := range 100 {
+		limiter.Allow(ctx, fmt.Sprintf("user%d", i), 1)
+	}
+
+	// Ended windows stay until the next pass, at most one window after the last
+	fake.Advance(30 * time.Second)
+	limiter.Allow(ctx, "alice", 1)
+	if n := len(limiter.windows); n != 101 {
+		t.Fatalf("%d keys before the window ended, want 101", n)
+	}
+
+	fake.Advance(30 * time.Second)
+	limiter.Allow(ctx, "alice", 1)
+	if n := len(limiter.windows); n != 1 {
+		t.Fatalf("%d keys after the prune pass, want only alice", n)
+	}
+
+	// The next pass is a window away, so later calls do not scan again
+	fake.Advance(30 * time.Second)
+	limiter.Allow(ctx, "bob", 1)
+	fake.Advance(29 * time.Second)
+	limiter.Allow(ctx, "carol", 1)
+	if n := len(limiter.windows); n != 3 {
+		t.Fatalf("%d keys, want alice, bob and carol until the next pass", n)
+	}
+}
+
+func TestRateLimiterDisabled(t *testing.T) {
+	limiter, _ := newTestRateLimiter(0)
+	for range 10 {
+		if !limiter.Allow(context.Background(), "alice", 100) {
+			t.Fatal("a disabled limiter denied a call")
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure RevocationBroker implements ports.RevocationBroker
+var _ ports.RevocationBroker = (*RevocationBroker)(nil)
+
+// RevocationBroker is an in-process ports.RevocationBroker
+// Each subscriber gets a buffered channel; a subscriber whose buffer is full is
+// disconnected instead of blocking publishers or silently missing events
+type RevocationBroker struct {
+	mu          sync.Mutex
+	subscribers map[chan domain.RevocationEvent]struct{}
+	buffer      int
+}
+
+// NewRevocationBroker creates a broker buffering up to buffer events per subscriber
+func NewRevocationBroker(buffer int) *RevocationBroker {
+	return &RevocationBroker{
+		subscribers: make(map[chan domain.RevocationEvent]struct{}),
+		buffer:      buffer,
+	}
+}
+
+// Publish delivers event to every subscriber, disconnecting those that are full
+func (b *RevocationBroker) Publish(event domain.RevocationEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Subscribe registers a subscriber until ctx is done
+func (b *RevocationBroker) Subscribe(ctx context.Context) <-chan domain.RevocationEvent {
+	ch := make(chan domain.RevocationEvent, b.buffer)
+
+	b.mu.Lock()
+	b.subscribers[ch] = struct{}{}
+	b.mu.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		b.unsubscribe(ch)
+	}()
+	return ch
+}
+
+func (b *RevocationBroker) unsubscribe(ch chan domain.RevocationEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Already removed if Publish disconnected the subscriber
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
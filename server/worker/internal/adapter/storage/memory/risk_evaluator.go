@@ -0,0 +1,105 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure RiskEvaluator implements ports.RiskEvaluator
+var _ ports.RiskEvaluator = (*RiskEvaluator)(nil)
+
+// maxKnownCountries bounds how many login countries are remembered per user
+const maxKnownCountries = 16
+
+// RiskEvaluator is the default in-process ports.RiskEvaluator
+// It challenges logins from a country the user has not logged in from before and challenges,
+// then denies, logins after many recent failed attempts. State is kept per replica and lost on
+// restart: the first country seen afterwards is trusted, and failures on other replicas do not count
+type RiskEvaluator struct {
+	mu        sync.Mutex
+	failures  map[string][]time.Time // Recent failed logins per user, oldest first
+	countries map[string][]string    // Countries of allowed logins per user
+
+	window         time.Duration
+	challengeAfter int
+	denyAfter      int
+	clock          ports.Clock
+}
+
+// NewRiskEvaluator creates a RiskEvaluator counting failures over window
+// A non-positive challengeAfter or denyAfter disables that limit
+func NewRiskEvaluator(window time.Duration, challengeAfter, denyAfter int, clock ports.Clock) *RiskEvaluator {
+	return &RiskEvaluator{
+		failures:       make(map[string][]time.Time),
+		countries:      make(map[string][]string),
+		window:         window,
+		challengeAfter: challengeAfter,
+		denyAfter:      denyAfter,
+		clock:          clock,
+	}
+}
+
+// Evaluate decides on a login with correct credentials
+func (e *RiskEvaluator) Evaluate(ctx context.Context, attempt domain.LoginAttempt) (domain.RiskAssessment, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	failures := len(e.recentFailures(attempt.UserID, e.clock.Now()))
+	switch {
+	case e.denyAfter > 0 && failures >= e.denyAfter:
+		return domain.RiskAssessment{Decision: domain.RiskDeny, Reason: "too many recent failed logins"}, nil
+	case e.challengeAfter > 0 && failures >= e.challengeAfter:
+		return domain.RiskAssessment{Decision: domain.RiskChallenge, Reason: "recent failed logins"}, nil
+	}
+
+	known := e.countries[attempt.UserID]
+	if country := attempt.Client.Country; country != "" && len(known) > 0 && !slices.Contains(known, country) {
+		return domain.RiskAssessment{Decision: domain.RiskChallenge, Reason: "login from a new country"}, nil
+	}
+	return domain.RiskAssessment{Decision: domain.RiskAllow}, nil
+}
+
+// RecordFailure counts a failed login towards the user's limits
+func (e *RiskEvaluator) RecordFailure(ctx context.Context, attempt domain.LoginAttempt) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := e.clock.Now()
+	e.failures[attempt.UserID] = append(e.recentFailures(attempt.UserID, now), now)
+}
+
+// RecordSuccess clears the user's failures and remembers the login country
+func (e *RiskEvaluator) RecordSuccess(ctx context.Context, attempt domain.LoginAttempt) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.failures, attempt.UserID)
+
+	known := e.countries[attempt.UserID]
+	if country := attempt.Client.Country; country != "" && !slices.Contains(known, country) && len(known) < maxKnownCountries {
+		e.countries[attempt.UserID] = append(known, country)
+	}
+}
+
+// recentFailures drops the user's failures older than the window and returns the rest
+// Callers must hold e.mu
+func (e *RiskEvaluator) recentFailures(userID string, now time.Time) []time.Time {
+	failures := e.failures[userID]
+	cutoff := now.Add(-e.window)
+	i := 0
+	for i < len(failures) && !failures[i].After(cutoff) {
+		i++
+	}
+	if i == len(failures) {
+		delete(e.failures, userID)
+		return nil
+	}
+	failures = failures[i:]
+	e.failures[userID] = failures
+	return failures
+}
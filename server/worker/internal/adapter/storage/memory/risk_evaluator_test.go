@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"worker/internal/common/clock"
+	"worker/internal/core/domain"
+)
+
+func newTestRiskEvaluator() (*RiskEvaluator, *clock.Fake) {
+	fake := clock.NewFake(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC))
+	return NewRiskEvaluator(15*time.Minute, 3, 5, fake), fake
+}
+
+// attemptFrom is a login attempt of userID from country
+func attemptFrom(userID, country string) domain.LoginAttempt {
+	return domain.LoginAttempt{UserID: userID, Client: domain.ClientInfo{Country: country}}
+}
+
+// assertDecision evaluates attempt and fails unless the evaluator decides want
+func assertDecision(t *testing.T, e *RiskEvaluator, attempt domain.LoginAttempt, want domain.RiskDecision) {
+	t.Helper()
+	got, err := e.Evaluate(context.Background(), attempt)
+	if err != nil {
+		t.Fatalf("evaluate: %v", err)
+	}
+	if got.Decision != want {
+		t.Fatalf("decision = %s (%q), want %s", got.Decision, got.Reason, want)
+	}
+	if want != domain.RiskAllow && got.Reason == "" {
+		t.Fatalf("%s without a reason", got.Decision)
+	}
+}
+
+// failLogins records n failed logins of attempt
+func failLogins(e *RiskEvaluator, attempt domain.LoginAttempt, n int) {
+	for range n {
+		e.RecordFailure(context.Background(), attempt)
+	}
+}
+
+func TestRiskEvaluatorFailures(t *testing.T) {
+	e, _ := newTestRiskEvaluator()
+	alice := attemptFrom("alice", "")
+
+	assertDecision(t, e, alice, domain.RiskAllow)
+	failLogins(e, alice, 2)
+	assertDecision(t, e, alice, domain.RiskAllow)
+	failLogins(e, alice, 1)
+	assertDecision(t, e, alice, domain.RiskChallenge)
+	failLogins(e, alice, 2)
+	assertDecision(t, e, alice, domain.RiskDeny)
+
+	// Failures are counted per user
+	assertDecision(t, e, attemptFrom("bob", ""), domain.RiskAllow)
+
+	// A successful login clears them
+	e.RecordSuccess(context.Background(), alice)
+	assertDecision(t, e, alice, domain.RiskAllow)
+}
+
+func TestRiskEvaluatorFailureWindow(t *testing.T) {
+	e, fake := newTestRiskEvaluator()
+	alice := attemptFrom("alice", "")
+
+	failLogins(e, alice, 2)
+	fake.Advance(10 * time.Minute)
+	failLogins(e, alice, 3)
+	assertDecision(t, e, alice, domain.RiskDeny)
+
+	// The first two failures leave the window, the last three still count
+	fake.Advance(5*time.Minute + time.Second)
+	assertDecision(t, e, alice, domain.RiskChallenge)
+
+	fake.Advance(10 * time.Minute)
+	assertDecision(t, e, alice, domain.RiskAllow)
+	if _, ok := e.failures["alice"]; ok {
+		t.Fatal("failures outside the window are still kept")
+	}
+}
+
+func TestRiskEvaluatorLimitsDisabled(t *testing.T) {
+	e := NewRiskEvaluator(15*time.Minute, 0, 0, clock.NewFake(time.Now()))
+	alice := attemptFrom("alice", "")
+
+	failLogins(e, alice, 50)
+	assertDecision(t, e, alice, domain.RiskAllow)
+}
+
+func TestRiskEvaluatorNewCountry(t *testing.T) {
+	e, _ := newTestRiskEvaluator()
+	ctx := context.Background()
+
+	// The first country is trusted, nothing is known to compare it with
+	assertDecision(t, e, attemptFrom("alice", "VN"), domain.RiskAllow)
+	e.RecordSuccess(ctx, attemptFrom("alice", "VN"))
+	assertDecision(t, e, attemptFrom("alice", "VN"), domain.RiskAllow)
+	assertDecision(t, e, attemptFrom("alice", "DE"), domain.RiskChallenge)
+
+	// Logins without a forwarded country are not compared
+	assertDecision(t, e, attemptFrom("alice", ""), domain.RiskAllow)
+
+	// Countries of allowed logins become known
+	e.RecordSuccess(ctx, attemptFrom("alice", "DE"))
+	assertDecision(t, e, attemptFrom("alice", "DE"), domain.RiskAllow)
+	assertDecision(t, e, attemptFrom("bob", "DE"), domain.RiskAllow)
+}
+
+func TestRiskEvaluatorKnownCountriesBounded(t *testing.T) {
+	e, _ := newTestRiskEvaluator()
+	for i := range maxKnownCountries + 4 {
+		e.RecordSuccess(context.Background(), attemptFrom("alice", string(rune('A'+i))+"X"))
+	}
+	if n := len(e.countries["alice"]); n != maxKnownCountries {
+		t.Fatalf("%d known countries, want at most %d", n, maxKnownCountries)
+	}
+}
@@ -0,0 +1,256 @@
+package memory
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure RoleRepository implements ports.RoleRepository
+var _ ports.RoleRepository = (*RoleRepository)(nil)
+
+// RoleRepository is the in-memory ports.RoleRepository
+// Permission strings come back sorted and without duplicates, like the DISTINCT queries
+type RoleRepository struct {
+	db handle
+}
+
+// NewRoleRepository creates a RoleRepository on store
+func NewRoleRepository(store *Store) *RoleRepository {
+	return &RoleRepository{db: handle{store: store}}
+}
+
+// FindByID retrieves a role by its UUID
+func (r *RoleRepository) FindByID(ctx context.Context, id uuid.UUID) (*sqlc.Role, error) {
+	return r.findRole(domain.ErrRoleNotFound, func(role sqlc.Role) bool { return role.ID == id })
+}
+
+// FindByCode retrieves a role by its code (e.g., "STUDENT", "ADMIN")
+func (r *RoleRepository) FindByCode(ctx context.Context, code string) (*sqlc.Role, error) {
+	return r.findRole(domain.ErrRoleNotFound, func(role sqlc.Role) bool { return role.Code == code })
+}
+
+// GetDefaultRole retrieves the default role for new users ("STUDENT")
+func (r *RoleRepository) GetDefaultRole(ctx context.Context) (*sqlc.Role, error) {
+	return r.findRole(domain.ErrDefaultRoleNotFound, func(role sqlc.Role) bool { return role.Code == defaultRoleCode })
+}
+
+// findRole returns the role matching match, or notFound
+func (r *RoleRepository) findRole(notFound error, match func(sqlc.Role) bool) (*sqlc.Role, error) {
+	var found sqlc.Role
+	err := r.db.do(func(t *tables) error {
+		for _, role := range t.roles {
+			if match(role) {
+				found = role
+				return nil
+			}
+		}
+		return notFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+// rolePermissions returns the flattened "resource:action" permissions of the given roles, sorted and deduplicated
+func rolePermissions(t *tables, roleIDs ...uuid.UUID) []string {
+	permissions := []string{}
+	for key, actions := range t.permissions {
+		if !slices.Contains(roleIDs, key.roleID) {
+			continue
+		}
+		resource := t.resources[key.resourceID]
+		for _, action := range actions {
+			permissions = append(permissions, resource.Code+":"+action)
+		}
+	}
+	slices.Sort(permissions)
+	return slices.Compact(permissions)
+}
+
+// GetPermissionsByRoleID retrieves all permission strings for a given role
+func (r *RoleRepository) GetPermissionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	var permissions []string
+	err := r.db.do(func(t *tables) error {
+		permissions = rolePermissions(t, roleID)
+		return nil
+	})
+	return permissions, err
+}
+
+// GetPermissionsByRoleIDs retrieves the permissions of several roles, keyed by role ID
+// Every requested role is present in the result; roles without permissions map to an empty slice
+func (r *RoleRepository) GetPermissionsByRoleIDs(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	result := make(map[uuid.UUID][]string, len(roleIDs))
+	err := r.db.do(func(t *tables) error {
+		for _, id := range roleIDs {
+			result[id] = rolePermissions(t, id)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// FindByUserID retrieves all roles assigned to a user (primary + additional), ordered by code
+func (r *RoleRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]sqlc.Role, error) {
+	roles := []sqlc.Role{}
+	err := r.db.do(func(t *tables) error {
+		for _, id := range t.roleIDsOfUser(userID) {
+			if role, ok := t.roles[id]; ok {
+				roles = append(roles, role)
+			}
+		}
+		return nil
+	})
+	slices.SortFunc(roles, compareRoles)
+	return roles, err
+}
+
+// GetPermissionsByUserID retrieves the union of permissions across all roles of a user
+func (r *RoleRepository) GetPermissionsByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	var permissions []string
+	err := r.db.do(func(t *tables) error {
+		permissions = rolePermissions(t, t.roleIDsOfUser(userID)...)
+		return nil
+	})
+	return permissions, err
+}
+
+// GetPermissionsByUserIDs retrieves the permissions of several users, keyed by user ID
+// Every requested user is present in the result; users without permissions map to an empty slice
+func (r *RoleRepository) GetPermissionsByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	result := make(map[uuid.UUID][]string, len(userIDs))
+	err := r.db.do(func(t *tables) error {
+		for _, id := range userIDs {
+			result[id] = rolePermissions(t, t.roleIDsOfUser(id)...)
+		}
+		return nil
+	})
+	return result, err
+}
+
+// List retrieves every role, ordered by code
+func (r *RoleRepository) List(ctx context.Context) ([]sqlc.Role, error) {
+	roles := []sqlc.Role{}
+	err := r.db.do(func(t *tables) error {
+		for _, role := range t.roles {
+			roles = append(roles, role)
+		}
+		return nil
+	})
+	slices.SortFunc(roles, compareRoles)
+	return roles, err
+}
+
+func compareRoles(a, b sqlc.Role) int {
+	return cmp.Compare(a.Code, b.Code)
+}
+
+// roleGrants returns the permissions of a role per resource, ordered by resource code
+func roleGrants(t *tables, roleID uuid.UUID) []domain.PermissionGrant {
+	grants := []domain.PermissionGrant{}
+	for key, actions := range t.permissions {
+		if key.roleID != roleID {
+			continue
+		}
+		resource := t.resources[key.resourceID]
+		grants = append(grants, domain.PermissionGrant{
+			ResourceCode: resource.Code,
+			ResourceName: resource.Name,
+			Actions:      slices.Clone(actions),
+		})
+	}
+	slices.SortFunc(grants, func(a, b domain.PermissionGrant) int {
+		return cmp.Compare(a.ResourceCode, b.ResourceCode)
+	})
+	return grants
+}
+
+// GetGrantsByRoleID retrieves the permissions of a role per resource
+func (r *RoleRepository) GetGrantsByRoleID(ctx context.Context, roleID uuid.UUID) ([]domain.PermissionGrant, error) {
+	var grants []domain.PermissionGrant
+	err := r.db.do(func(t *tables) error {
+		grants = roleGrants(t, roleID)
+		return nil
+	})
+	return grants, err
+}
+
+// ListGrants retrieves the permissions of every role per resource, keyed by role ID
+func (r *RoleRepository) ListGrants(ctx context.Context) (map[uuid.UUID][]domain.PermissionGrant, error) {
+	result := make(map[uuid.UUID][]domain.PermissionGrant)
+	err := r.db.do(func(t *tables) error {
+		for key := range t.permissions {
+			if _, done := result[key.roleID]; !done {
+				result[key.roleID] = roleGrants(t, key.roleID)
+			}
+		}
+		return nil
+	})
+	return result, err
+}
+
+// FindResourceByCode retrieves a resource by its code (e.g., "users")
+func (r *RoleRepository) FindResourceByCode(ctx context.Context, code string) (*sqlc.Resource, error) {
+	var found sqlc.Resource
+	err := r.db.do(func(t *tables) error {
+		for _, resource := range t.resources {
+			if resource.Code == code {
+				found = resource
+				return nil
+			}
+		}
+		return domain.ErrResourceNotFound
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+// GrantAction grants an action on a resource to a role; granting a held action changes nothing
+func (r *RoleRepository) GrantAction(ctx context.Context, roleID, resourceID uuid.UUID, action string) error {
+	return r.db.do(func(t *tables) error {
+		if _, ok := t.roles[roleID]; !ok {
+			return fmt.Errorf("grant action: role %s does not exist", roleID)
+		}
+		if _, ok := t.resources[resourceID]; !ok {
+			return fmt.Errorf("grant action: resource %s does not exist", resourceID)
+		}
+		key := permissionKey{roleID: roleID, resourceID: resourceID}
+		if actions := t.permissions[key]; !slices.Contains(actions, action) {
+			t.permissions[key] = append(slices.Clip(actions), action)
+		}
+		return nil
+	})
+}
+
+// RevokeAction revokes an action on a resource from a role
+// A role left without actions on the resource loses its permissions row, so it does not show up as an empty grant
+func (r *RoleRepository) RevokeAction(ctx context.Context, roleID, resourceID uuid.UUID, action string) (bool, error) {
+	var revoked bool
+	err := r.db.do(func(t *tables) error {
+		key := permissionKey{roleID: roleID, resourceID: resourceID}
+		actions := t.permissions[key]
+		if !slices.Contains(actions, action) {
+			return nil
+		}
+		revoked = true
+		remaining := slices.DeleteFunc(slices.Clone(actions), func(held string) bool { return held == action })
+		if len(remaining) == 0 {
+			delete(t.permissions, key)
+			return nil
+		}
+		t.permissions[key] = remaining
+		return nil
+	})
+	return revoked, err
+}
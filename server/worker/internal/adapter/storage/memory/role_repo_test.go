@@ -0,0 +1,79 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// roleID returns the ID of the seeded role with code
+func roleID(t *testing.T, roles *RoleRepository, code string) uuid.UUID {
+	t.Helper()
+	role, err := roles.FindByCode(context.Background(), code)
+	if err != nil {
+		t.Fatalf("find role %s: %v", code, err)
+	}
+	return role.ID
+}
+
+func TestGetPermissionsByRoleIDs(t *testing.T) {
+	store, _ := newTestStore(t)
+	roles := NewRoleRepository(store)
+	ctx := context.Background()
+
+	admin := roleID(t, roles, "ADMIN")
+	lecturer := roleID(t, roles, "LECTURER")
+	student := roleID(t, roles, defaultRoleCode)
+	users, err := roles.FindResourceByCode(ctx, "users")
+	if err != nil {
+		t.Fatalf("find resource: %v", err)
+	}
+	for _, action := range []string{"READ", "READ", "UPDATE"} {
+		if err := roles.GrantAction(ctx, lecturer, users.ID, action); err != nil {
+			t.Fatalf("grant %s: %v", action, err)
+		}
+	}
+	unknown := uuid.New()
+
+	got, err := roles.GetPermissionsByRoleIDs(ctx, []uuid.UUID{admin, lecturer, student, unknown})
+	if err != nil {
+		t.Fatalf("get permissions: %v", err)
+	}
+	want := map[uuid.UUID][]string{
+		admin:    {"roles:READ", "roles:UPDATE", "system:READ", "users:DELETE", "users:READ", "users:UPDATE"},
+		lecturer: {"users:READ", "users:UPDATE"},
+		// Roles without permissions, and unknown roles, map to an empty slice rather than being absent
+		student: {},
+		unknown: {},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d roles, want %d: %v", len(got), len(want), got)
+	}
+	for id, permissions := range want {
+		granted, ok := got[id]
+		if !ok || granted == nil {
+			t.Fatalf("role %s is missing from %v", id, got)
+		}
+		if !slices.Equal(granted, permissions) {
+			t.Fatalf("permissions of %s = %v, want %v", id, granted, permissions)
+		}
+	}
+
+	// Each role's permissions match the single-role lookup
+	for _, id := range []uuid.UUID{admin, lecturer, student} {
+		single, err := roles.GetPermissionsByRoleID(ctx, id)
+		if err != nil {
+			t.Fatalf("get permissions of %s: %v", id, err)
+		}
+		if !slices.Equal(single, got[id]) {
+			t.Fatalf("single lookup of %s = %v, batch = %v", id, single, got[id])
+		}
+	}
+
+	none, err := roles.GetPermissionsByRoleIDs(ctx, nil)
+	if err != nil || len(none) != 0 {
+		t.Fatalf("no roles = %v, %v; want an empty map", none, err)
+	}
+}
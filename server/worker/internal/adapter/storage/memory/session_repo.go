@@ -0,0 +1,286 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure SessionRepository implements ports.SessionRepository
+var _ ports.SessionRepository = (*SessionRepository)(nil)
+
+// SessionRepository is the in-memory ports.SessionRepository
+type SessionRepository struct {
+	db handle
+}
+
+// NewSessionRepository creates a SessionRepository on store
+func NewSessionRepository(store *Store) *SessionRepository {
+	return &SessionRepository{db: handle{store: store}}
+}
+
+// Create creates a new session
+func (r *SessionRepository) Create(ctx context.Context, params sqlc.CreateSessionParams) (*sqlc.Session, error) {
+	now := r.db.store.now()
+	session := sqlc.Session{
+		ID:           params.ID,
+		UserID:       params.UserID,
+		RefreshNonce: params.RefreshNonce,
+		ExpiresAt:    params.ExpiresAt,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		LastSeenAt:   now,
+	}
+	err := r.db.do(func(t *tables) error {
+		if _, ok := t.sessions[session.ID]; ok {
+			return fmt.Errorf("create session: session %s already exists", session.ID)
+		}
+		if _, ok := t.users[session.UserID]; !ok {
+			return fmt.Errorf("create session: user %s does not exist", session.UserID)
+		}
+		t.sessions[session.ID] = session
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindByID retrieves a session by its UUID
+func (r *SessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*sqlc.Session, error) {
+	var session sqlc.Session
+	err := r.db.do(func(t *tables) error {
+		var ok bool
+		if session, ok = t.sessions[id]; !ok {
+			return domain.ErrSessionNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RotateNonce swaps the refresh nonce if currentNonce is still the active one and marks the session as seen at seenAt
+// Returns false if the nonce was already rotated or the session was revoked
+func (r *SessionRepository) RotateNonce(ctx context.Context, id uuid.UUID, currentNonce, newNonce string, expiresAt, seenAt time.Time) (bool, error) {
+	now := r.db.store.now()
+	var rotated bool
+	err := r.db.do(func(t *tables) error {
+		session, ok := t.sessions[id]
+		if !ok || session.RefreshNonce != currentNonce || session.RevokedAt.Valid {
+			return nil
+		}
+		session.RefreshNonce = newNonce
+		session.ExpiresAt = timestamp(expiresAt)
+		session.UpdatedAt = now
+		session.LastSeenAt = timestamp(seenAt)
+		t.sessions[id] = session
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+// MarkSeen marks an active session as seen at seenAt, unless it was last seen after staleBefore
+// Sessions last seen before idleBefore stay idle unless it is zero
+func (r *SessionRepository) MarkSeen(ctx context.Context, id uuid.UUID, seenAt, staleBefore, idleBefore time.Time) error {
+	return r.db.do(func(t *tables) error {
+		session, ok := t.sessions[id]
+		if !ok || session.RevokedAt.Valid {
+			return nil
+		}
+		if session.LastSeenAt.Valid {
+			idle := !idleBefore.IsZero() && !session.LastSeenAt.Time.After(idleBefore)
+			if session.LastSeenAt.Time.After(staleBefore) || idle {
+				return nil
+			}
+		}
+		session.LastSeenAt = timestamp(seenAt)
+		t.sessions[id] = session
+		return nil
+	})
+}
+
+// Revoke revokes a session
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.revoke(func(session sqlc.Session) bool { return session.ID == id })
+}
+
+// RevokeAllForUser revokes every active session of a user
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.revoke(func(session sqlc.Session) bool { return session.UserID == userID })
+}
+
+// revoke revokes the active sessions matching match
+func (r *SessionRepository) revoke(match func(sqlc.Session) bool) error {
+	now := r.db.store.now()
+	return r.db.do(func(t *tables) error {
+		for id, session := range t.sessions {
+			if session.RevokedAt.Valid || !match(session) {
+				continue
+			}
+			session.RevokedAt = now
+			session.UpdatedAt = now
+			t.sessions[id] = session
+		}
+		return nil
+	})
+}
+
+// ListActiveIDsForUser returns the IDs of a user's sessions that can still refresh at now, oldest first
+func (r *SessionRepository) ListActiveIDsForUser(ctx context.Context, userID uuid.UUID, now, idleBefore time.Time) ([]uuid.UUID, error) {
+	var active []sqlc.Session
+	err := r.db.do(func(t *tables) error {
+		for _, session := range t.sessions {
+			if session.UserID != userID || session.RevokedAt.Valid || !session.ExpiresAt.Time.After(now) {
+				continue
+			}
+			if !idleBefore.IsZero() && session.LastSeenAt.Valid && !session.LastSeenAt.Time.After(idleBefore) {
+				continue
+			}
+			active = append(active, session)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(active, func(a, b sqlc.Session) int {
+		return cmp.Or(a.CreatedAt.Time.Compare(b.CreatedAt.Time), bytes.Compare(a.ID[:], b.ID[:]))
+	})
+	ids := make([]uuid.UUID, len(active))
+	for i, session := range active {
+		ids[i] = session.ID
+	}
+	return ids, nil
+}
+
+// DeleteAllForUser deletes every session of a user, revoked or not
+func (r *SessionRepository) DeleteAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.do(func(t *tables) error {
+		for id, session := range t.sessions {
+			if session.UserID == userID {
+				delete(t.sessions, id)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteExpired deletes up to limit sessions that expired before the given time, or were last seen before
+// idleBefore unless it is zero, and returns how many
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before, idleBefore time.Time, limit int32) (int64, error) {
+	var deleted int64
+	err := r.db.do(func(t *tables) error {
+		for id, session := range t.sessions {
+			if deleted >= int64(limit) {
+				break
+			}
+			idle := !idleBefore.IsZero() && session.LastSeenAt.Valid && !session.LastSeenAt.Time.After(idleBefore)
+			if !session.ExpiresAt.Time.After(before) || idle {
+				delete(t.sessions, id)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
+
+// Ensure AccessTokenRepository implements ports.AccessTokenRepository
+var _ ports.AccessTokenRepository = (*AccessTokenRepository)(nil)
+
+// AccessTokenRepository is the in-memory ports.AccessTokenRepository
+type AccessTokenRepository struct {
+	db handle
+}
+
+// NewAccessTokenRepository creates an AccessTokenRepository on store
+func NewAccessTokenRepository(store *Store) *AccessTokenRepository {
+	return &AccessTokenRepository{db: handle{store: store}}
+}
+
+// Create stores an opaque access token and drops the user's expired ones
+func (r *AccessTokenRepository) Create(ctx context.Context, params sqlc.CreateAccessTokenParams) error {
+	now := r.db.store.now()
+	return r.db.do(func(t *tables) error {
+		if _, ok := t.accessTokens[params.TokenHash]; ok {
+			return fmt.Errorf("create access token: token hash already exists")
+		}
+		if _, ok := t.users[params.UserID]; !ok {
+			return fmt.Errorf("create access token: user %s does not exist", params.UserID)
+		}
+		for hash, token := range t.accessTokens {
+			if token.UserID == params.UserID && !token.ExpiresAt.Time.After(now.Time) {
+				delete(t.accessTokens, hash)
+			}
+		}
+		t.accessTokens[params.TokenHash] = sqlc.AccessToken{
+			TokenHash: params.TokenHash,
+			UserID:    params.UserID,
+			Claims:    params.Claims,
+			ExpiresAt: params.ExpiresAt,
+			CreatedAt: now,
+		}
+		return nil
+	})
+}
+
+// FindByHash retrieves an opaque access token by the hash of the token
+func (r *AccessTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*sqlc.AccessToken, error) {
+	var token sqlc.AccessToken
+	err := r.db.do(func(t *tables) error {
+		var ok bool
+		if token, ok = t.accessTokens[tokenHash]; !ok {
+			return domain.ErrTokenNotFound
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteAllForUser deletes every opaque access token of a user
+func (r *AccessTokenRepository) DeleteAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.db.do(func(t *tables) error {
+		for hash, token := range t.accessTokens {
+			if token.UserID == userID {
+				delete(t.accessTokens, hash)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteExpired deletes up to limit tokens that expired before the given time and returns how many
+func (r *AccessTokenRepository) DeleteExpired(ctx context.Context, before time.Time, limit int32) (int64, error) {
+	var deleted int64
+	err := r.db.do(func(t *tables) error {
+		for hash, token := range t.accessTokens {
+			if deleted >= int64(limit) {
+				break
+			}
+			if !token.ExpiresAt.Time.After(before) {
+				delete(t.accessTokens, hash)
+				deleted++
+			}
+		}
+		return nil
+	})
+	return deleted, err
+}
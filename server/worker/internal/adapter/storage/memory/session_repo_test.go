@@ -0,0 +1,169 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+)
+
+func TestSessionRepositoryRotateNonceSeenAt(t *testing.T) {
+	store, fake := newTestStore(t)
+	sessions := NewSessionRepository(store)
+	ctx := context.Background()
+	sessionID := createSession(t, store, mustCreateUser(t, store, "alice"), "nonce-0", fake.Now().Add(time.Hour))
+
+	// The caller's clock decides last_seen_at, not the store's
+	seenAt := fake.Now().Add(time.Minute)
+	if ok, err := sessions.RotateNonce(ctx, sessionID, "nonce-0", "nonce-1", fake.Now().Add(time.Hour), seenAt); err != nil || !ok {
+		t.Fatalf("rotate = %t, %v; want rotated", ok, err)
+	}
+	session, err := sessions.FindByID(ctx, sessionID)
+	if err != nil {
+		t.Fatalf("find session: %v", err)
+	}
+	if !session.LastSeenAt.Time.Equal(seenAt) {
+		t.Fatalf("last seen at %s, want %s", session.LastSeenAt.Time, seenAt)
+	}
+}
+
+func TestSessionRepositoryMarkSeen(t *testing.T) {
+	store, fake := newTestStore(t)
+	sessions := NewSessionRepository(store)
+	ctx := context.Background()
+	userID := mustCreateUser(t, store, "alice")
+	created := fake.Now()
+
+	lastSeen := func(sessionID uuid.UUID) time.Time {
+		t.Helper()
+		session, err := sessions.FindByID(ctx, sessionID)
+		if err != nil {
+			t.Fatalf("find session: %v", err)
+		}
+		return session.LastSeenAt.Time
+	}
+
+	tests := []struct {
+		name        string
+		staleBefore time.Time
+		idleBefore  time.Time
+		revoked     bool
+		want        time.Time
+	}{
+		{name: "stale session", staleBefore: created, want: created.Add(time.Hour)},
+		{name: "recently seen session", staleBefore: created.Add(-time.Second), want: created},
+		{name: "idle session stays idle", staleBefore: created, idleBefore: created, want: created},
+		{name: "session within the idle timeout", staleBefore: created, idleBefore: created.Add(-time.Second), want: created.Add(time.Hour)},
+		{name: "revoked session", staleBefore: created, revoked: true, want: created},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sessionID := createSession(t, store, userID, "nonce", created.Add(2*time.Hour))
+			if tt.revoked {
+				if err := sessions.Revoke(ctx, sessionID); err != nil {
+					t.Fatalf("revoke: %v", err)
+				}
+			}
+			if err := sessions.MarkSeen(ctx, sessionID, created.Add(time.Hour), tt.staleBefore, tt.idleBefore); err != nil {
+				t.Fatalf("mark seen: %v", err)
+			}
+			if got := lastSeen(sessionID); !got.Equal(tt.want) {
+				t.Fatalf("last seen at %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSessionRepositoryDeleteExpired(t *testing.T) {
+	store, fake := newTestStore(t)
+	sessions := NewSessionRepository(store)
+	ctx := context.Background()
+	userID := mustCreateUser(t, store, "alice")
+	start := fake.Now()
+	now := start
+
+	expired := createSession(t, store, userID, "expired", now.Add(time.Minute))
+	idle := createSession(t, store, userID, "idle", now.Add(time.Hour))
+	fake.Advance(30 * time.Minute)
+	live := createSession(t, store, userID, "live", now.Add(time.Hour))
+	fake.Advance(30 * time.Minute)
+	now = fake.Now().Add(-time.Second)
+
+	// Without an idle timeout only the expired session goes
+	if n, err := sessions.DeleteExpired(ctx, now, time.Time{}, 100); err != nil || n != 1 {
+		t.Fatalf("delete expired = %d, %v; want 1", n, err)
+	}
+	if _, err := sessions.FindByID(ctx, expired); err == nil {
+		t.Fatal("expired session was kept")
+	}
+
+	// Sessions last seen before idleBefore go too
+	if n, err := sessions.DeleteExpired(ctx, now, start.Add(15*time.Minute), 100); err != nil || n != 1 {
+		t.Fatalf("delete idle = %d, %v; want 1", n, err)
+	}
+	if _, err := sessions.FindByID(ctx, idle); err == nil {
+		t.Fatal("idle session was kept")
+	}
+	if _, err := sessions.FindByID(ctx, live); err != nil {
+		t.Fatalf("live session was deleted: %v", err)
+	}
+}
+
+func TestSessionRepositoryDeleteExpiredLimit(t *testing.T) {
+	store, fake := newTestStore(t)
+	sessions := NewSessionRepository(store)
+	userID := mustCreateUser(t, store, "alice")
+	for range 5 {
+		createSession(t, store, userID, uuid.NewString(), fake.Now())
+	}
+
+	var batches []int64
+	for {
+		n, err := sessions.DeleteExpired(context.Background(), fake.Now(), time.Time{}, 2)
+		if err != nil {
+			t.Fatalf("delete expired: %v", err)
+		}
+		batches = append(batches, n)
+		if n < 2 {
+			break
+		}
+	}
+	if len(batches) != 3 || batches[0] != 2 || batches[1] != 2 || batches[2] != 1 {
+		t.Fatalf("batches = %v, want [2 2 1]", batches)
+	}
+}
+
+func TestAccessTokenRepositoryDeleteExpired(t *testing.T) {
+	store, fake := newTestStore(t)
+	tokens := NewAccessTokenRepository(store)
+	ctx := context.Background()
+	userID := mustCreateUser(t, store, "alice")
+
+	for i, expiresAt := range []time.Time{fake.Now().Add(time.Minute), fake.Now().Add(2 * time.Minute), fake.Now().Add(time.Hour)} {
+		err := tokens.Create(ctx, sqlc.CreateAccessTokenParams{
+			TokenHash: fmt.Sprintf("hash-%d", i),
+			UserID:    userID,
+			Claims:    []byte("{}"),
+			ExpiresAt: pgtype.Timestamp{Time: expiresAt, Valid: true},
+		})
+		if err != nil {
+			t.Fatalf("create token: %v", err)
+		}
+	}
+
+	cutoff := fake.Now().Add(5 * time.Minute)
+	if n, err := tokens.DeleteExpired(ctx, cutoff, 1); err != nil || n != 1 {
+		t.Fatalf("delete a batch of 1 = %d, %v; want 1", n, err)
+	}
+	if n, err := tokens.DeleteExpired(ctx, cutoff, 100); err != nil || n != 1 {
+		t.Fatalf("delete the rest = %d, %v; want 1", n, err)
+	}
+	if _, err := tokens.FindByHash(ctx, "hash-2"); err != nil {
+		t.Fatalf("live token was deleted: %v", err)
+	}
+}
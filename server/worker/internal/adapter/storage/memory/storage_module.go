@@ -0,0 +1,128 @@
+package memory
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/config"
+	"worker/internal/core/ports"
+)
+
+// StorageModule provides in-memory repositories in place of postgres.Module (STORAGE_BACKEND=memory)
+// It lets the worker run without PostgreSQL for tests and demos; everything is lost on restart
+var StorageModule = fx.Module("memory_storage",
+	fx.Provide(
+		newStore,
+		fx.Annotate(
+			NewUserRepository,
+			fx.As(new(ports.UserRepository)),
+		),
+		fx.Annotate(
+			NewRoleRepository,
+			fx.As(new(ports.RoleRepository)),
+		),
+		fx.Annotate(
+			NewSessionRepository,
+			fx.As(new(ports.SessionRepository)),
+		),
+		fx.Annotate(
+			NewAccessTokenRepository,
+			fx.As(new(ports.AccessTokenRepository)),
+		),
+		fx.Annotate(
+			NewPasswordResetCodeRepository,
+			fx.As(new(ports.PasswordResetCodeRepository)),
+		),
+		fx.Annotate(
+			NewUnitOfWork,
+			fx.As(new(ports.UnitOfWork)),
+		),
+		fx.Annotate(
+			NewStatsQuerier,
+			fx.As(new(ports.ReadOnlyQuerier)),
+		),
+		fx.Annotate(
+			NewPoolStats,
+			fx.As(new(ports.DatabaseStats)),
+		),
+		fx.Annotate(
+			NewHealth,
+			fx.As(new(ports.DatabaseHealth)),
+		),
+		fx.Annotate(
+			NewLastLoginRecorder,
+			fx.As(new(ports.LastLoginRecorder)),
+		),
+	),
+	fx.Invoke(startExpiredSweeper),
+)
+
+// newStore creates the seeded store of StorageModule
+func newStore(cfg *config.AuthConfig, clock ports.Clock, logger *zap.Logger) *Store {
+	logger.Warn("⚠️ STORAGE_BACKEND=memory, using in-memory storage (tests and demos only, data is lost on restart)")
+	store := NewStore(clock)
+	store.Seed(cfg.DefaultRoleCode)
+	return store
+}
+
+// startExpiredSweeper deletes expired and idle sessions and opaque access tokens every DB_SWEEP_INTERVAL,
+// like the PostgreSQL sweeper but in a single pass, since the store has no locks worth bounding
+func startExpiredSweeper(
+	lc fx.Lifecycle,
+	cfg *config.DatabaseConfig,
+	authCfg *config.AuthConfig,
+	sessions ports.SessionRepository,
+	accessTokens ports.AccessTokenRepository,
+	clock ports.Clock,
+	logger *zap.Logger,
+) {
+	if cfg.SweepInterval <= 0 {
+		return
+	}
+
+	logger = logger.Named("expired_sweeper")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	sweep := func() {
+		now := clock.Now()
+		// The in-memory repositories never fail
+		removedSessions, _ := sessions.DeleteExpired(ctx, now, authCfg.SessionIdleBefore(now), math.MaxInt32)
+		removedTokens, _ := accessTokens.DeleteExpired(ctx, now, math.MaxInt32)
+		if removedSessions > 0 || removedTokens > 0 {
+			logger.Info("Deleted expired records",
+				zap.Int64("sessions", removedSessions),
+				zap.Int64("access_tokens", removedTokens),
+			)
+		}
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(cfg.SweepInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						sweep()
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/ports"
+)
+
+// GetDefaultRole returns this role, like the GetDefaultRole query
+const defaultRoleCode = "STUDENT"
+
+// permissionKey identifies the permissions row of a role on a resource
+type permissionKey struct {
+	roleID     uuid.UUID
+	resourceID uuid.UUID
+}
+
+// userRoleKey identifies an additional role of a user
+type userRoleKey struct {
+	userID uuid.UUID
+	roleID uuid.UUID
+}
+
+// tables are the rows of the in-memory database
+// Rows are stored by value and slices are replaced rather than modified, so a shallow copy of the maps is a snapshot
+type tables struct {
+	users        map[uuid.UUID]sqlc.User
+	roles        map[uuid.UUID]sqlc.Role
+	resources    map[uuid.UUID]sqlc.Resource
+	permissions  map[permissionKey][]string // Actions of a role on a resource, in grant order
+	userRoles    map[userRoleKey]struct{}
+	sessions     map[uuid.UUID]sqlc.Session
+	accessTokens map[string]sqlc.AccessToken
+	resetCodes   map[uuid.UUID]sqlc.PasswordResetCode // Pending password reset code, keyed by user ID
+}
+
+func newTables() *tables {
+	return &tables{
+		users:        make(map[uuid.UUID]sqlc.User),
+		roles:        make(map[uuid.UUID]sqlc.Role),
+		resources:    make(map[uuid.UUID]sqlc.Resource),
+		permissions:  make(map[permissionKey][]string),
+		userRoles:    make(map[userRoleKey]struct{}),
+		sessions:     make(map[uuid.UUID]sqlc.Session),
+		accessTokens: make(map[string]sqlc.AccessToken),
+		resetCodes:   make(map[uuid.UUID]sqlc.PasswordResetCode),
+	}
+}
+
+// clone returns a snapshot of the tables
+func (t *tables) clone() *tables {
+	return &tables{
+		users:        maps.Clone(t.users),
+		roles:        maps.Clone(t.roles),
+		resources:    maps.Clone(t.resources),
+		permissions:  maps.Clone(t.permissions),
+		userRoles:    maps.Clone(t.userRoles),
+		sessions:     maps.Clone(t.sessions),
+		accessTokens: maps.Clone(t.accessTokens),
+		resetCodes:   maps.Clone(t.resetCodes),
+	}
+}
+
+// roleIDsOfUser returns the primary and additional role IDs of a user
+func (t *tables) roleIDsOfUser(userID uuid.UUID) []uuid.UUID {
+	var ids []uuid.UUID
+	if user, ok := t.users[userID]; ok {
+		ids = append(ids, user.RoleID)
+	}
+	for key := range t.userRoles {
+		if key.userID == userID && (len(ids) == 0 || key.roleID != ids[0]) {
+			ids = append(ids, key.roleID)
+		}
+	}
+	return ids
+}
+
+// Store is the in-memory database behind the repositories of STORAGE_BACKEND=memory
+// Every access holds one mutex; a unit of work holds it for its whole run, so units are serialized
+// and never observe each other's partial changes. Data is lost on restart
+type Store struct {
+	mu    sync.Mutex
+	data  *tables
+	clock ports.Clock
+}
+
+// NewStore creates an empty store; use Seed to add the roles a fresh database starts with
+func NewStore(clock ports.Clock) *Store {
+	return &Store{
+		data:  newTables(),
+		clock: clock,
+	}
+}
+
+// seedRoles are the roles a fresh store starts with; ADMIN is granted the permissions the worker checks
+var seedRoles = []string{"ADMIN", "LECTURER", defaultRoleCode}
+
+// seedResources are the resources a fresh store starts with, keyed by code, with the actions ADMIN holds on them
+var seedResources = map[string][]string{
+	"users":  {"READ", "UPDATE", "DELETE"},
+	"roles":  {"READ", "UPDATE"},
+	"system": {"READ"},
+}
+
+// Seed adds the ADMIN, LECTURER and STUDENT roles, the users, roles and system resources and ADMIN's grants,
+// plus the configured default role when it is none of these, so registration and login work on an empty store
+func (s *Store) Seed(defaultRole string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	codes := seedRoles
+	if defaultRole != "" && !slices.Contains(codes, defaultRole) {
+		codes = append(slices.Clip(codes), defaultRole)
+	}
+	var adminID uuid.UUID
+	for _, code := range codes {
+		role := sqlc.Role{ID: uuid.New(), Name: code, Code: code, CreatedAt: now}
+		s.data.roles[role.ID] = role
+		if code == "ADMIN" {
+			adminID = role.ID
+		}
+	}
+	for code, actions := range seedResources {
+		resource := sqlc.Resource{ID: uuid.New(), Name: code, Code: code}
+		s.data.resources[resource.ID] = resource
+		s.data.permissions[permissionKey{roleID: adminID, resourceID: resource.ID}] = actions
+	}
+}
+
+// now returns the current time as a database timestamp
+func (s *Store) now() pgtype.Timestamp {
+	return timestamp(s.clock.Now())
+}
+
+// handle gives a repository access to the store's tables
+// Repositories of a unit of work run while the unit holds the lock, so their handle does not lock again
+type handle struct {
+	store *Store
+	inTx  bool
+}
+
+// do runs fn on the tables, holding the store lock unless a unit of work already does
+func (h handle) do(fn func(t *tables) error) error {
+	if !h.inTx {
+		h.store.mu.Lock()
+		defer h.store.mu.Unlock()
+	}
+	return fn(h.store.data)
+}
+
+// timestamp returns t as a valid database timestamp
+func timestamp(t time.Time) pgtype.Timestamp {
+	return pgtype.Timestamp{Time: t, Valid: true}
+}
@@ -0,0 +1,241 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/common/clock"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// workers is how many goroutines each concurrency test runs; run the tests with -race
+const workers = 32
+
+func newTestStore(t *testing.T) (*Store, *clock.Fake) {
+	t.Helper()
+	fake := clock.NewFake(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC))
+	store := NewStore(fake)
+	store.Seed(defaultRoleCode)
+	return store, fake
+}
+
+// createUser creates a user named username with the default role
+func createUser(store *Store, username string) (*sqlc.User, error) {
+	ctx := context.Background()
+	role, err := NewRoleRepository(store).FindByCode(ctx, defaultRoleCode)
+	if err != nil {
+		return nil, err
+	}
+	now := store.now()
+	return NewUserRepository(store).CreateUser(ctx, sqlc.CreateUserParams{
+		ID:        uuid.New(),
+		RoleID:    role.ID,
+		Email:     username + "@example.com",
+		Username:  username,
+		Password:  "hash",
+		FullName:  "Test " + username,
+		CreatedAt: now,
+		UpdatedAt: now,
+	})
+}
+
+func mustCreateUser(t *testing.T, store *Store, username string) uuid.UUID {
+	t.Helper()
+	user, err := createUser(store, username)
+	if err != nil {
+		t.Fatalf("create user %s: %v", username, err)
+	}
+	return user.ID
+}
+
+// createSession creates a session of userID with nonce, expiring at expiresAt
+func createSession(t *testing.T, store *Store, userID uuid.UUID, nonce string, expiresAt time.Time) uuid.UUID {
+	t.Helper()
+	session, err := NewSessionRepository(store).Create(context.Background(), sqlc.CreateSessionParams{
+		ID:           uuid.New(),
+		UserID:       userID,
+		RefreshNonce: nonce,
+		ExpiresAt:    timestamp(expiresAt),
+	})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	return session.ID
+}
+
+// parallel runs fn on workers goroutines at once and waits for them
+func parallel(fn func(i int)) {
+	var start, done sync.WaitGroup
+	start.Add(1)
+	for i := range workers {
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			start.Wait()
+			fn(i)
+		}()
+	}
+	start.Done()
+	done.Wait()
+}
+
+func TestUserRepositoryConcurrentCreate(t *testing.T) {
+	store, _ := newTestStore(t)
+	users := NewUserRepository(store)
+	ctx := context.Background()
+
+	// Every name is registered by several workers at once, while others read
+	const names = 4
+	var created atomic.Int32
+	parallel(func(i int) {
+		username := fmt.Sprintf("user%d", i%names)
+		if _, err := createUser(store, username); err == nil {
+			created.Add(1)
+		} else if !errors.Is(err, domain.ErrEmailAlreadyExists) && !errors.Is(err, domain.ErrUsernameAlreadyExists) {
+			t.Errorf("create %s: %v", username, err)
+		}
+		if _, err := users.ExistsByUsername(ctx, "", username); err != nil {
+			t.Errorf("exists %s: %v", username, err)
+		}
+	})
+
+	if got := created.Load(); got != names {
+		t.Fatalf("created %d users, want %d", got, names)
+	}
+	for i := range names {
+		if _, err := users.FindByUsername(ctx, "", fmt.Sprintf("user%d", i)); err != nil {
+			t.Fatalf("find user%d: %v", i, err)
+		}
+	}
+}
+
+func TestSessionRepositoryConcurrentRotate(t *testing.T) {
+	store, fake := newTestStore(t)
+	sessions := NewSessionRepository(store)
+	ctx := context.Background()
+	userID := mustCreateUser(t, store, "alice")
+	sessionID := createSession(t, store, userID, "nonce-0", fake.Now().Add(time.Hour))
+
+	// Refreshes racing on the same nonce: exactly one may redeem it
+	var rotated atomic.Int32
+	parallel(func(i int) {
+		ok, err := sessions.RotateNonce(ctx, sessionID, "nonce-0", fmt.Sprintf("nonce-%d", i+1), fake.Now().Add(time.Hour), fake.Now())
+		if err != nil {
+			t.Errorf("rotate: %v", err)
+		}
+		if ok {
+			rotated.Add(1)
+		}
+	})
+	if got := rotated.Load(); got != 1 {
+		t.Fatalf("%d rotations redeemed the same nonce, want 1", got)
+	}
+
+	// Rotations of distinct sessions do not interfere
+	ids := make([]uuid.UUID, workers)
+	for i := range ids {
+		ids[i] = createSession(t, store, userID, "nonce", fake.Now().Add(time.Hour))
+	}
+	parallel(func(i int) {
+		if ok, err := sessions.RotateNonce(ctx, ids[i], "nonce", "next", fake.Now().Add(time.Hour), fake.Now()); err != nil || !ok {
+			t.Errorf("rotate session %d = %t, %v; want rotated", i, ok, err)
+		}
+	})
+}
+
+func TestUnitOfWorkConcurrentDo(t *testing.T) {
+	store, _ := newTestStore(t)
+	unitOfWork := NewUnitOfWork(store)
+	ctx := context.Background()
+	userID := mustCreateUser(t, store, "alice")
+	errFailed := errors.New("failed")
+
+	// Each unit bumps the token version twice; odd workers fail after both bumps and must leave no trace
+	parallel(func(i int) {
+		err := unitOfWork.Do(ctx, func(repos ports.Repositories) error {
+			for range 2 {
+				if _, err := repos.Users.IncrementTokenVersion(ctx, userID); err != nil {
+					return err
+				}
+			}
+			if i%2 == 1 {
+				return errFailed
+			}
+			return nil
+		})
+		if err != nil && !errors.Is(err, errFailed) {
+			t.Errorf("unit of work: %v", err)
+		}
+	})
+
+	version, err := NewUserRepository(store).GetTokenVersion(ctx, userID)
+	if err != nil {
+		t.Fatalf("get token version: %v", err)
+	}
+	if want := int32(workers / 2 * 2); version != want {
+		t.Fatalf("token version = %d, want %d from the committed units only", version, want)
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	store, fake := newTestStore(t)
+	sessions := NewSessionRepository(store)
+	ctx := context.Background()
+	userID := mustCreateUser(t, store, "alice")
+
+	short := createSession(t, store, userID, "short", fake.Now().Add(time.Hour))
+	long := createSession(t, store, userID, "long", fake.Now().Add(2*time.Hour))
+
+	active, err := sessions.ListActiveIDsForUser(ctx, userID, fake.Now(), time.Time{})
+	if err != nil || len(active) != 2 {
+		t.Fatalf("active sessions = %v, %v; want both", active, err)
+	}
+
+	// A session is expired from its expiry on
+	fake.Advance(time.Hour)
+	active, err = sessions.ListActiveIDsForUser(ctx, userID, fake.Now(), time.Time{})
+	if err != nil || len(active) != 1 || active[0] != long {
+		t.Fatalf("active sessions = %v, %v; want only %s", active, err, long)
+	}
+
+	deleted, err := sessions.DeleteExpired(ctx, fake.Now(), time.Time{}, 100)
+	if err != nil || deleted != 1 {
+		t.Fatalf("delete expired = %d, %v; want 1", deleted, err)
+	}
+	if _, err := sessions.FindByID(ctx, short); !errors.Is(err, domain.ErrSessionNotFound) {
+		t.Fatalf("find expired session: %v, want %v", err, domain.ErrSessionNotFound)
+	}
+	if _, err := sessions.FindByID(ctx, long); err != nil {
+		t.Fatalf("find live session: %v", err)
+	}
+}
+
+func TestPermissionCacheExpiry(t *testing.T) {
+	ctx := context.Background()
+	userID := uuid.New()
+
+	cache := NewPermissionCache(10 * time.Millisecond)
+	cache.Set(ctx, userID, []string{"users:READ"})
+	if permissions, ok := cache.Get(ctx, userID); !ok || len(permissions) != 1 {
+		t.Fatalf("get = %v, %t; want the cached permissions", permissions, ok)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if permissions, ok := cache.Get(ctx, userID); ok {
+		t.Fatalf("get after the TTL = %v, want a miss", permissions)
+	}
+
+	disabled := NewPermissionCache(0)
+	disabled.Set(ctx, userID, []string{"users:READ"})
+	if _, ok := disabled.Get(ctx, userID); ok {
+		t.Fatal("a cache with a zero TTL stored an entry")
+	}
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/ports"
+)
+
+// Ensure TokenVersionCache implements ports.TokenVersionCache
+var _ ports.TokenVersionCache = (*TokenVersionCache)(nil)
+
+type tokenVersionEntry struct {
+	version   int32
+	expiresAt time.Time
+}
+
+// TokenVersionCache is an in-process ports.TokenVersionCache with per-entry TTL
+// Bumps made by another replica are seen once the entry expires, so the TTL bounds
+// how long a revoked access token keeps validating there. A non-positive TTL disables caching
+type TokenVersionCache struct {
+	mu      sync.RWMutex
+	entries map[uuid.UUID]tokenVersionEntry
+	ttl     time.Duration
+}
+
+// NewTokenVersionCache creates an empty token version cache
+func NewTokenVersionCache(ttl time.Duration) *TokenVersionCache {
+	return &TokenVersionCache{
+		entries: make(map[uuid.UUID]tokenVersionEntry),
+		ttl:     ttl,
+	}
+}
+
+// Get returns the cached token version of a user if present and not expired
+func (c *TokenVersionCache) Get(ctx context.Context, userID uuid.UUID) (int32, bool) {
+	c.mu.RLock()
+	entry, ok := c.entries[userID]
+	c.mu.RUnlock()
+
+	if !ok || time.Now().After(entry.expiresAt) {
+		return 0, false
+	}
+	return entry.version, true
+}
+
+// Set caches the token version of a user
+func (c *TokenVersionCache) Set(ctx context.Context, userID uuid.UUID, version int32) {
+	if c.ttl <= 0 {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[userID] = tokenVersionEntry{
+		version:   version,
+		expiresAt: time.Now().Add(c.ttl),
+	}
+}
+
+// Invalidate drops the cached token version of a user
+func (c *TokenVersionCache) Invalidate(ctx context.Context, userID uuid.UUID) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, userID)
+}
@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+
+	"worker/internal/core/ports"
+)
+
+// Ensure UnitOfWork implements ports.UnitOfWork
+var _ ports.UnitOfWork = (*UnitOfWork)(nil)
+
+// UnitOfWork is the in-memory ports.UnitOfWork
+// A unit holds the store lock while it runs and restores a snapshot of the tables when it fails
+type UnitOfWork struct {
+	store *Store
+}
+
+// NewUnitOfWork creates a UnitOfWork on store
+func NewUnitOfWork(store *Store) *UnitOfWork {
+	return &UnitOfWork{store: store}
+}
+
+// Do runs fn with repositories bound to the locked store, rolling its changes back when fn returns an error
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos ports.Repositories) error) error {
+	u.store.mu.Lock()
+	defer u.store.mu.Unlock()
+
+	snapshot := u.store.data.clone()
+	db := handle{store: u.store, inTx: true}
+	err := fn(ports.Repositories{
+		Users:    &UserRepository{db: db},
+		Roles:    &RoleRepository{db: db},
+		Sessions: &SessionRepository{db: db},
+	})
+	if err != nil {
+		u.store.data = snapshot
+	}
+	return err
+}
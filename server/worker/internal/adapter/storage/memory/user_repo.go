@@ -0,0 +1,811 @@
+package memory
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure UserRepository implements ports.UserRepository
+var _ ports.UserRepository = (*UserRepository)(nil)
+
+// UserRepository is the in-memory ports.UserRepository
+// It mirrors the sqlc queries, including the per-tenant unique email and username
+type UserRepository struct {
+	db handle
+}
+
+// NewUserRepository creates a UserRepository on store
+func NewUserRepository(store *Store) *UserRepository {
+	return &UserRepository{db: handle{store: store}}
+}
+
+// withRole returns a user joined with its primary role, like the GetUserBy* queries
+func withRole(t *tables, user sqlc.User) sqlc.GetUserByIDRow {
+	row := sqlc.GetUserByIDRow{
+		ID:                  user.ID,
+		RoleID:              user.RoleID,
+		Email:               user.Email,
+		Username:            user.Username,
+		Password:            user.Password,
+		FullName:            user.FullName,
+		Phone:               user.Phone,
+		Avatar:              user.Avatar,
+		IsActive:            user.IsActive,
+		LastLogin:           user.LastLogin,
+		CreatedAt:           user.CreatedAt,
+		UpdatedAt:           user.UpdatedAt,
+		PasswordChangedAt:   user.PasswordChangedAt,
+		TenantID:            user.TenantID,
+		UsernameChangedAt:   user.UsernameChangedAt,
+		TokenVersion:        user.TokenVersion,
+		LastLoginIp:         user.LastLoginIp,
+		LastLoginUserAgent:  user.LastLoginUserAgent,
+		MustResetPassword:   user.MustResetPassword,
+		Locale:              user.Locale,
+		ScheduledDeletionAt: user.ScheduledDeletionAt,
+	}
+	if role, ok := t.roles[user.RoleID]; ok {
+		row.RoleName = &role.Name
+		row.RoleCode = &role.Code
+	}
+	return row
+}
+
+// findUser returns the first user of a tenant matching match
+func findUser(t *tables, tenantID string, match func(sqlc.User) bool) (sqlc.User, bool) {
+	for _, user := range t.users {
+		if user.TenantID == tenantID && match(user) {
+			return user, true
+		}
+	}
+	return sqlc.User{}, false
+}
+
+// uniqueConflict returns the error of the users unique constraint user violates, ignoring user's own row
+func uniqueConflict(t *tables, user sqlc.User) error {
+	for _, other := range t.users {
+		if other.ID == user.ID || other.TenantID != user.TenantID {
+			continue
+		}
+		if other.Email == user.Email {
+			return domain.ErrEmailAlreadyExists
+		}
+		if other.Username == user.Username {
+			return domain.ErrUsernameAlreadyExists
+		}
+	}
+	return nil
+}
+
+// update applies fn to a stored user, reporting false when the user does not exist
+func update(t *tables, userID uuid.UUID, fn func(user *sqlc.User)) bool {
+	user, ok := t.users[userID]
+	if !ok {
+		return false
+	}
+	fn(&user)
+	t.users[userID] = user
+	return true
+}
+
+// FindByID retrieves a user by their UUID (includes role info)
+func (r *UserRepository) FindByID(ctx context.Context, id uuid.UUID) (*sqlc.GetUserByIDRow, error) {
+	var row sqlc.GetUserByIDRow
+	err := r.db.do(func(t *tables) error {
+		user, ok := t.users[id]
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		row = withRole(t, user)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &row, nil
+}
+
+// FindByEmail retrieves a user by their email address within a tenant (includes role info)
+func (r *UserRepository) FindByEmail(ctx context.Context, tenantID, email string) (*sqlc.GetUserByEmailRow, error) {
+	row, err := r.findOne(tenantID, func(user sqlc.User) bool { return user.Email == email })
+	if err != nil {
+		return nil, err
+	}
+	found := sqlc.GetUserByEmailRow(row)
+	return &found, nil
+}
+
+// FindByUsername retrieves a user by their username within a tenant (includes role info)
+func (r *UserRepository) FindByUsername(ctx context.Context, tenantID, username string) (*sqlc.GetUserByUsernameRow, error) {
+	row, err := r.findOne(tenantID, func(user sqlc.User) bool { return user.Username == username })
+	if err != nil {
+		return nil, err
+	}
+	found := sqlc.GetUserByUsernameRow(row)
+	return &found, nil
+}
+
+// FindByEmailOrUsername retrieves a user by email or username within a tenant (includes role info)
+func (r *UserRepository) FindByEmailOrUsername(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error) {
+	row, err := r.findOne(tenantID, func(user sqlc.User) bool {
+		return user.Email == identifier || user.Username == identifier
+	})
+	if err != nil {
+		return nil, err
+	}
+	found := sqlc.GetUserByEmailOrUsernameRow(row)
+	return &found, nil
+}
+
+// FindByUsernameIgnoringCase retrieves a user by their username, in any letter case, within a tenant (includes role info)
+func (r *UserRepository) FindByUsernameIgnoringCase(ctx context.Context, tenantID, username string) (*sqlc.GetUserByUsernameRow, error) {
+	row, err := r.findOne(tenantID, func(user sqlc.User) bool { return strings.EqualFold(user.Username, username) })
+	if err != nil {
+		return nil, err
+	}
+	found := sqlc.GetUserByUsernameRow(row)
+	return &found, nil
+}
+
+// FindByEmailOrUsernameIgnoringCase retrieves a user by email, or by username in any letter case,
+// within a tenant (includes role info)
+func (r *UserRepository) FindByEmailOrUsernameIgnoringCase(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error) {
+	row, err := r.findOne(tenantID, func(user sqlc.User) bool {
+		return user.Email == identifier || strings.EqualFold(user.Username, identifier)
+	})
+	if err != nil {
+		return nil, err
+	}
+	found := sqlc.GetUserByEmailOrUsernameRow(row)
+	return &found, nil
+}
+
+// findOne returns the user of a tenant matching match, joined with its primary role
+func (r *UserRepository) findOne(tenantID string, match func(sqlc.User) bool) (sqlc.GetUserByIDRow, error) {
+	var row sqlc.GetUserByIDRow
+	err := r.db.do(func(t *tables) error {
+		user, ok := findUser(t, tenantID, match)
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		row = withRole(t, user)
+		return nil
+	})
+	return row, err
+}
+
+// ListUsers returns one page of a tenant's users in (created_at, id) order, after params' keyset position
+func (r *UserRepository) ListUsers(ctx context.Context, params sqlc.ListUsersParams) ([]sqlc.ListUsersRow, error) {
+	return r.listUsers(userListing{
+		tenantID:        params.TenantID,
+		isActive:        params.IsActive,
+		pendingApproval: params.PendingApproval,
+		pageSize:        params.PageSize,
+		sortField:       "created_at",
+		descending:      false,
+		after:           sqlc.ListUsersRow{CreatedAt: pgtype.Timestamp{Time: params.AfterTime, Valid: true}, ID: params.AfterID},
+	})
+}
+
+// ListUsersDesc returns one page of a tenant's users in descending (created_at, id) order, before params' keyset position
+func (r *UserRepository) ListUsersDesc(ctx context.Context, params sqlc.ListUsersDescParams) ([]sqlc.ListUsersRow, error) {
+	return r.listUsers(userListing{
+		tenantID:        params.TenantID,
+		isActive:        params.IsActive,
+		pendingApproval: params.PendingApproval,
+		pageSize:        params.PageSize,
+		sortField:       "created_at",
+		descending:      true,
+		after:           sqlc.ListUsersRow{CreatedAt: pgtype.Timestamp{Time: params.AfterTime, Valid: true}, ID: params.AfterID},
+	})
+}
+
+// ListUsersByLastLogin returns one page of a tenant's users in (last_login, id) order, after params' keyset position
+func (r *UserRepository) ListUsersByLastLogin(ctx context.Context, params sqlc.ListUsersByLastLoginParams) ([]sqlc.ListUsersRow, error) {
+	return r.listUsers(userListing{
+		tenantID:        params.TenantID,
+		isActive:        params.IsActive,
+		pendingApproval: params.PendingApproval,
+		pageSize:        params.PageSize,
+		sortField:       "last_login",
+		descending:      false,
+		after:           sqlc.ListUsersRow{LastLogin: pgtype.Timestamp{Time: params.AfterTime, Valid: true}, ID: params.AfterID},
+	})
+}
+
+// ListUsersByLastLoginDesc returns one page of a tenant's users in descending (last_login, id) order, before params' keyset position
+func (r *UserRepository) ListUsersByLastLoginDesc(ctx context.Context, params sqlc.ListUsersByLastLoginDescParams) ([]sqlc.ListUsersRow, error) {
+	return r.listUsers(userListing{
+		tenantID:        params.TenantID,
+		isActive:        params.IsActive,
+		pendingApproval: params.PendingApproval,
+		pageSize:        params.PageSize,
+		sortField:       "last_login",
+		descending:      true,
+		after:           sqlc.ListUsersRow{LastLogin: pgtype.Timestamp{Time: params.AfterTime, Valid: true}, ID: params.AfterID},
+	})
+}
+
+// ListUsersByUsername returns one page of a tenant's users in bytewise (username, id) order, after params' keyset position
+func (r *UserRepository) ListUsersByUsername(ctx context.Context, params sqlc.ListUsersByUsernameParams) ([]sqlc.ListUsersRow, error) {
+	return r.listUsers(userListing{
+		tenantID:        params.TenantID,
+		isActive:        params.IsActive,
+		pendingApproval: params.PendingApproval,
+		pageSize:        params.PageSize,
+		sortField:       "username",
+		descending:      false,
+		after:           sqlc.ListUsersRow{Username: params.AfterText, ID: params.AfterID},
+	})
+}
+
+// ListUsersByUsernameDesc returns one page of a tenant's users in descending bytewise (username, id) order, before params' keyset position
+func (r *UserRepository) ListUsersByUsernameDesc(ctx context.Context, params sqlc.ListUsersByUsernameDescParams) ([]sqlc.ListUsersRow, error) {
+	return r.listUsers(userListing{
+		tenantID:        params.TenantID,
+		isActive:        params.IsActive,
+		pendingApproval: params.PendingApproval,
+		pageSize:        params.PageSize,
+		sortField:       "username",
+		descending:      true,
+		after:           sqlc.ListUsersRow{Username: params.AfterText, ID: params.AfterID},
+	})
+}
+
+// userListing is one ListUsers query: its filters, its order and the keyset position to start from
+type userListing struct {
+	tenantID        string
+	isActive        *bool
+	pendingApproval bool
+	pageSize        int32
+	sortField       string
+	descending      bool
+	after           sqlc.ListUsersRow
+}
+
+// listUsers returns the users of listing's tenant that pass its filters and sort after its keyset position
+func (r *UserRepository) listUsers(listing userListing) ([]sqlc.ListUsersRow, error) {
+	var rows []sqlc.ListUsersRow
+	err := r.db.do(func(t *tables) error {
+		for _, user := range t.users {
+			if user.TenantID != listing.tenantID {
+				continue
+			}
+			if listing.isActive != nil && (user.IsActive == nil || *user.IsActive) != *listing.isActive {
+				continue
+			}
+			if listing.pendingApproval && (user.IsActive == nil || *user.IsActive || user.LastLogin.Valid || user.ScheduledDeletionAt.Valid) {
+				continue
+			}
+			row := sqlc.ListUsersRow(withRole(t, user))
+			after := compareUsers(listing.sortField, row, listing.after)
+			if listing.descending {
+				after = -after
+			}
+			if after > 0 {
+				rows = append(rows, row)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(rows, func(a, b sqlc.ListUsersRow) int {
+		if listing.descending {
+			return compareUsers(listing.sortField, b, a)
+		}
+		return compareUsers(listing.sortField, a, b)
+	})
+	if len(rows) > int(listing.pageSize) {
+		rows = rows[:max(listing.pageSize, 0)]
+	}
+	return rows, nil
+}
+
+// compareUsers orders users by sort field then ID; users who never logged in sort as logging in at the epoch
+func compareUsers(sortField string, a, b sqlc.ListUsersRow) int {
+	var byField int
+	switch sortField {
+	case "username":
+		byField = cmp.Compare(a.Username, b.Username)
+	case "last_login":
+		byField = lastLoginOrEpoch(a).Compare(lastLoginOrEpoch(b))
+	case "created_at":
+		byField = a.CreatedAt.Time.Compare(b.CreatedAt.Time)
+	}
+	if byField != 0 {
+		return byField
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
+
+func lastLoginOrEpoch(row sqlc.ListUsersRow) time.Time {
+	if !row.LastLogin.Valid {
+		return time.Unix(0, 0).UTC()
+	}
+	return row.LastLogin.Time
+}
+
+// ExistsByEmail checks if a user with the given email exists within a tenant
+func (r *UserRepository) ExistsByEmail(ctx context.Context, tenantID, email string) (bool, error) {
+	var exists bool
+	err := r.db.do(func(t *tables) error {
+		_, exists = findUser(t, tenantID, func(user sqlc.User) bool { return user.Email == email })
+		return nil
+	})
+	return exists, err
+}
+
+// ExistsByUsername checks if a user with the given username exists within a tenant
+func (r *UserRepository) ExistsByUsername(ctx context.Context, tenantID, username string) (bool, error) {
+	var exists bool
+	err := r.db.do(func(t *tables) error {
+		_, exists = findUser(t, tenantID, func(user sqlc.User) bool { return user.Username == username })
+		return nil
+	})
+	return exists, err
+}
+
+// ExistsByUsernameIgnoringCase checks if a user with the given username, in any letter case, exists within a tenant
+func (r *UserRepository) ExistsByUsernameIgnoringCase(ctx context.Context, tenantID, username string) (bool, error) {
+	var exists bool
+	err := r.db.do(func(t *tables) error {
+		_, exists = findUser(t, tenantID, func(user sqlc.User) bool { return strings.EqualFold(user.Username, username) })
+		return nil
+	})
+	return exists, err
+}
+
+// ExistingEmails returns which of the given emails belong to a user of the tenant
+func (r *UserRepository) ExistingEmails(ctx context.Context, tenantID string, emails []string) ([]string, error) {
+	return r.existing(tenantID, emails, func(user sqlc.User) string { return user.Email })
+}
+
+// ExistingUsernames returns which of the given usernames belong to a user of the tenant
+func (r *UserRepository) ExistingUsernames(ctx context.Context, tenantID string, usernames []string) ([]string, error) {
+	return r.existing(tenantID, usernames, func(user sqlc.User) string { return user.Username })
+}
+
+// ExistingUsernamesIgnoringCase returns which of the given lowercase usernames belong, in any letter case,
+// to a user of the tenant
+func (r *UserRepository) ExistingUsernamesIgnoringCase(ctx context.Context, tenantID string, usernames []string) ([]string, error) {
+	return r.existing(tenantID, usernames, func(user sqlc.User) string { return strings.ToLower(user.Username) })
+}
+
+// existing returns the values among wanted that field takes for a user of the tenant
+func (r *UserRepository) existing(tenantID string, wanted []string, field func(sqlc.User) string) ([]string, error) {
+	set := make(map[string]bool, len(wanted))
+	for _, value := range wanted {
+		set[value] = true
+	}
+	found := []string{}
+	err := r.db.do(func(t *tables) error {
+		for _, user := range t.users {
+			if user.TenantID == tenantID && set[field(user)] {
+				found = append(found, field(user))
+			}
+		}
+		return nil
+	})
+	return found, err
+}
+
+// CreateUser creates a new user
+// Returns domain.ErrEmailAlreadyExists / ErrUsernameAlreadyExists when the tenant already has the email or username
+func (r *UserRepository) CreateUser(ctx context.Context, params sqlc.CreateUserParams) (*sqlc.User, error) {
+	user := sqlc.User{
+		ID:                params.ID,
+		RoleID:            params.RoleID,
+		Email:             params.Email,
+		Username:          params.Username,
+		Password:          params.Password,
+		FullName:          params.FullName,
+		Phone:             params.Phone,
+		Avatar:            params.Avatar,
+		IsActive:          params.IsActive,
+		CreatedAt:         params.CreatedAt,
+		UpdatedAt:         params.UpdatedAt,
+		PasswordChangedAt: r.db.store.now(),
+		TenantID:          params.TenantID,
+		Locale:            params.Locale,
+	}
+	err := r.db.do(func(t *tables) error {
+		if _, ok := t.users[user.ID]; ok {
+			return domain.ErrUserAlreadyExists
+		}
+		if _, ok := t.roles[user.RoleID]; !ok {
+			return fmt.Errorf("create user: role %s does not exist", user.RoleID)
+		}
+		if err := uniqueConflict(t, user); err != nil {
+			return err
+		}
+		t.users[user.ID] = user
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUser updates an existing user; nil phone, avatar and is_active keep the stored values
+func (r *UserRepository) UpdateUser(ctx context.Context, params sqlc.UpdateUserParams) (*sqlc.User, error) {
+	var updated sqlc.User
+	err := r.db.do(func(t *tables) error {
+		user, ok := t.users[params.ID]
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		user.Email = params.Email
+		user.Username = params.Username
+		user.Password = params.Password
+		user.FullName = params.FullName
+		user.Phone = cmp.Or(params.Phone, user.Phone)
+		user.Avatar = cmp.Or(params.Avatar, user.Avatar)
+		user.IsActive = cmp.Or(params.IsActive, user.IsActive)
+		user.UpdatedAt = r.db.store.now()
+		if err := uniqueConflict(t, user); err != nil {
+			return err
+		}
+		t.users[user.ID] = user
+		updated = user
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// UpdateLastLogins stores a batch of last logins, skipping any older than the stored one
+func (r *UserRepository) UpdateLastLogins(ctx context.Context, logins []domain.LastLogin) error {
+	return r.db.do(func(t *tables) error {
+		for _, login := range logins {
+			update(t, login.UserID, func(user *sqlc.User) {
+				if user.LastLogin.Valid && !user.LastLogin.Time.Before(login.At) {
+					return
+				}
+				user.LastLogin = timestamp(login.At)
+				user.LastLoginIp = nonEmpty(login.Client.IP)
+				user.LastLoginUserAgent = nonEmpty(login.Client.UserAgent)
+			})
+		}
+		return nil
+	})
+}
+
+// nonEmpty returns nil for an empty string, like NULLIF(value, ”)
+func nonEmpty(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
+// UpdateAvatar updates the avatar URL of a user
+func (r *UserRepository) UpdateAvatar(ctx context.Context, userID uuid.UUID, avatarURL string) error {
+	now := r.db.store.now()
+	return r.db.do(func(t *tables) error {
+		update(t, userID, func(user *sqlc.User) {
+			user.Avatar = &avatarURL
+			user.UpdatedAt = now
+		})
+		return nil
+	})
+}
+
+// UpdateEmail changes the email of a user
+// Returns domain.ErrEmailAlreadyExists when the email is taken, domain.ErrUserNotFound for an unknown user
+func (r *UserRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	now := r.db.store.now()
+	return r.db.do(func(t *tables) error {
+		user, ok := t.users[userID]
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		user.Email = email
+		user.UpdatedAt = now
+		if err := uniqueConflict(t, user); err != nil {
+			return err
+		}
+		t.users[userID] = user
+		return nil
+	})
+}
+
+// SetActive activates or deactivates a user
+func (r *UserRepository) SetActive(ctx context.Context, userID uuid.UUID, active bool) error {
+	now := r.db.store.now()
+	return r.db.do(func(t *tables) error {
+		ok := update(t, userID, func(user *sqlc.User) {
+			user.IsActive = &active
+			user.UpdatedAt = now
+		})
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		return nil
+	})
+}
+
+// UpdatePassword replaces the password hash of a user, sets password_changed_at and lifts a forced reset
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	now := r.db.store.now()
+	return r.db.do(func(t *tables) error {
+		update(t, userID, func(user *sqlc.User) {
+			user.Password = passwordHash
+			user.PasswordChangedAt = now
+			user.MustResetPassword = false
+			user.UpdatedAt = now
+		})
+		return nil
+	})
+}
+
+// RehashPassword replaces a password hash with newHash unless the password changed since oldHash was read
+func (r *UserRepository) RehashPassword(ctx context.Context, userID uuid.UUID, oldHash, newHash string) (bool, error) {
+	var rehashed bool
+	err := r.db.do(func(t *tables) error {
+		user, ok := t.users[userID]
+		if !ok || user.Password != oldHash {
+			return nil
+		}
+		user.Password = newHash
+		t.users[userID] = user
+		rehashed = true
+		return nil
+	})
+	return rehashed, err
+}
+
+// UpdateUsername renames a user and sets username_changed_at, unless the previous rename is within cooldown
+func (r *UserRepository) UpdateUsername(ctx context.Context, userID uuid.UUID, username string, cooldown time.Duration) (bool, error) {
+	now := r.db.store.now()
+	var renamed bool
+	err := r.db.do(func(t *tables) error {
+		user, ok := t.users[userID]
+		if !ok || (user.UsernameChangedAt.Valid && user.UsernameChangedAt.Time.After(now.Time.Add(-cooldown))) {
+			return nil
+		}
+		user.Username = username
+		user.UsernameChangedAt = now
+		user.UpdatedAt = now
+		if err := uniqueConflict(t, user); err != nil {
+			return err
+		}
+		t.users[userID] = user
+		renamed = true
+		return nil
+	})
+	return renamed, err
+}
+
+// GetTokenVersion returns the user's current token version
+func (r *UserRepository) GetTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error) {
+	var version int32
+	err := r.db.do(func(t *tables) error {
+		user, ok := t.users[userID]
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		version = user.TokenVersion
+		return nil
+	})
+	return version, err
+}
+
+// GetTokenSubjects returns the email and token version of several users; missing users are skipped
+func (r *UserRepository) GetTokenSubjects(ctx context.Context, userIDs []uuid.UUID) ([]sqlc.GetTokenSubjectsByIDsRow, error) {
+	var rows []sqlc.GetTokenSubjectsByIDsRow
+	err := r.db.do(func(t *tables) error {
+		seen := make(map[uuid.UUID]bool, len(userIDs))
+		for _, id := range userIDs {
+			user, ok := t.users[id]
+			if !ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			rows = append(rows, sqlc.GetTokenSubjectsByIDsRow{
+				ID:           user.ID,
+				Email:        user.Email,
+				TokenVersion: user.TokenVersion,
+			})
+		}
+		return nil
+	})
+	return rows, err
+}
+
+// IncrementTokenVersion bumps the user's token version and returns the new one
+func (r *UserRepository) IncrementTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error) {
+	return r.bumpTokenVersion(userID, false)
+}
+
+// RequirePasswordReset refuses logins until the user resets their password
+// and bumps the token version, returning the new one
+func (r *UserRepository) RequirePasswordReset(ctx context.Context, userID uuid.UUID) (int32, error) {
+	return r.bumpTokenVersion(userID, true)
+}
+
+func (r *UserRepository) bumpTokenVersion(userID uuid.UUID, requireReset bool) (int32, error) {
+	now := r.db.store.now()
+	var version int32
+	err := r.db.do(func(t *tables) error {
+		ok := update(t, userID, func(user *sqlc.User) {
+			user.TokenVersion++
+			user.MustResetPassword = user.MustResetPassword || requireReset
+			user.UpdatedAt = now
+			version = user.TokenVersion
+		})
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		return nil
+	})
+	return version, err
+}
+
+// Delete permanently removes a user, their additional roles and opaque access tokens
+// Like the users foreign keys, it fails while sessions still reference the user
+func (r *UserRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return r.db.do(func(t *tables) error {
+		if _, ok := t.users[userID]; !ok {
+			return domain.ErrUserNotFound
+		}
+		return deleteUser(t, userID)
+	})
+}
+
+// ScheduleDeletion schedules the erasure of a user unless one is already scheduled,
+// returning the schedule in effect
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) (time.Time, error) {
+	now := r.db.store.now()
+	var scheduled time.Time
+	err := r.db.do(func(t *tables) error {
+		ok := update(t, userID, func(user *sqlc.User) {
+			if !user.ScheduledDeletionAt.Valid {
+				user.ScheduledDeletionAt = timestamp(at)
+			}
+			user.UpdatedAt = now
+			scheduled = user.ScheduledDeletionAt.Time
+		})
+		if !ok {
+			return domain.ErrUserNotFound
+		}
+		return nil
+	})
+	return scheduled, err
+}
+
+// CancelDeletion cancels the scheduled erasure of a user
+func (r *UserRepository) CancelDeletion(ctx context.Context, userID uuid.UUID) (bool, error) {
+	now := r.db.store.now()
+	var cancelled bool
+	err := r.db.do(func(t *tables) error {
+		user, ok := t.users[userID]
+		if !ok || !user.ScheduledDeletionAt.Valid {
+			return nil
+		}
+		user.ScheduledDeletionAt.Valid = false
+		user.ScheduledDeletionAt.Time = time.Time{}
+		user.UpdatedAt = now
+		t.users[userID] = user
+		cancelled = true
+		return nil
+	})
+	return cancelled, err
+}
+
+// ListDueForDeletion returns up to limit users whose scheduled erasure is due, oldest schedule first
+func (r *UserRepository) ListDueForDeletion(ctx context.Context, dueBy time.Time, limit int32) ([]sqlc.ListUsersDueForDeletionRow, error) {
+	var due []sqlc.User
+	err := r.db.do(func(t *tables) error {
+		for _, user := range t.users {
+			if user.ScheduledDeletionAt.Valid && !user.ScheduledDeletionAt.Time.After(dueBy) {
+				due = append(due, user)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(due, func(a, b sqlc.User) int {
+		return a.ScheduledDeletionAt.Time.Compare(b.ScheduledDeletionAt.Time)
+	})
+	due = due[:min(len(due), int(max(limit, 0)))]
+	rows := make([]sqlc.ListUsersDueForDeletionRow, 0, len(due))
+	for _, user := range due {
+		rows = append(rows, sqlc.ListUsersDueForDeletionRow{ID: user.ID, TenantID: user.TenantID})
+	}
+	return rows, nil
+}
+
+// DeleteIfDue is Delete guarded by the user's deletion schedule
+func (r *UserRepository) DeleteIfDue(ctx context.Context, userID uuid.UUID, dueBy time.Time) error {
+	return r.db.do(func(t *tables) error {
+		user, ok := t.users[userID]
+		if !ok || !user.ScheduledDeletionAt.Valid || user.ScheduledDeletionAt.Time.After(dueBy) {
+			return domain.ErrUserNotFound
+		}
+		return deleteUser(t, userID)
+	})
+}
+
+// deleteUser removes a user with their additional roles and opaque access tokens
+func deleteUser(t *tables, userID uuid.UUID) error {
+	for _, session := range t.sessions {
+		if session.UserID == userID {
+			return fmt.Errorf("delete user %s: sessions still reference the user", userID)
+		}
+	}
+	for key := range t.userRoles {
+		if key.userID == userID {
+			delete(t.userRoles, key)
+		}
+	}
+	for hash, token := range t.accessTokens {
+		if token.UserID == userID {
+			delete(t.accessTokens, hash)
+		}
+	}
+	delete(t.resetCodes, userID)
+	delete(t.users, userID)
+	return nil
+}
+
+// AddRole assigns an additional role to a user (idempotent)
+func (r *UserRepository) AddRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	return r.db.do(func(t *tables) error {
+		if _, ok := t.users[userID]; !ok {
+			return fmt.Errorf("add role: user %s does not exist", userID)
+		}
+		if _, ok := t.roles[roleID]; !ok {
+			return fmt.Errorf("add role: role %s does not exist", roleID)
+		}
+		t.userRoles[userRoleKey{userID: userID, roleID: roleID}] = struct{}{}
+		return nil
+	})
+}
+
+// RemoveRole removes an additional role from a user
+func (r *UserRepository) RemoveRole(ctx context.Context, userID, roleID uuid.UUID) (bool, error) {
+	var removed bool
+	err := r.db.do(func(t *tables) error {
+		key := userRoleKey{userID: userID, roleID: roleID}
+		_, removed = t.userRoles[key]
+		delete(t.userRoles, key)
+		return nil
+	})
+	return removed, err
+}
+
+// SetPrimaryRole changes the primary role stored on the user row
+func (r *UserRepository) SetPrimaryRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	now := r.db.store.now()
+	return r.db.do(func(t *tables) error {
+		if _, ok := t.roles[roleID]; !ok {
+			return fmt.Errorf("set primary role: role %s does not exist", roleID)
+		}
+		update(t, userID, func(user *sqlc.User) {
+			user.RoleID = roleID
+			user.UpdatedAt = now
+		})
+		return nil
+	})
+}
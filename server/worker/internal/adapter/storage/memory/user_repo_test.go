@@ -0,0 +1,173 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/domain"
+)
+
+func TestUserRepositoryDeleteIfDue(t *testing.T) {
+	store, fake := newTestStore(t)
+	users := NewUserRepository(store)
+	ctx := context.Background()
+
+	due := mustCreateUser(t, store, "due")
+	later := mustCreateUser(t, store, "later")
+	unscheduled := mustCreateUser(t, store, "unscheduled")
+	now := fake.Now()
+	if _, err := users.ScheduleDeletion(ctx, due, now.Add(-time.Minute)); err != nil {
+		t.Fatalf("schedule deletion: %v", err)
+	}
+	if _, err := users.ScheduleDeletion(ctx, later, now.Add(time.Hour)); err != nil {
+		t.Fatalf("schedule deletion: %v", err)
+	}
+
+	if err := users.DeleteIfDue(ctx, due, now); err != nil {
+		t.Fatalf("delete the due user: %v", err)
+	}
+	if _, err := users.FindByID(ctx, due); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("find the deleted user: got %v, want ErrUserNotFound", err)
+	}
+
+	// Users not yet due, or never scheduled, are reported missing and kept
+	for name, id := range map[string]uuid.UUID{"later": later, "unscheduled": unscheduled} {
+		if err := users.DeleteIfDue(ctx, id, now); !errors.Is(err, domain.ErrUserNotFound) {
+			t.Fatalf("delete the %s user: got %v, want ErrUserNotFound", name, err)
+		}
+		if _, err := users.FindByID(ctx, id); err != nil {
+			t.Fatalf("find the %s user: %v", name, err)
+		}
+	}
+}
+
+func TestUserRepositoryUpdateLastLogins(t *testing.T) {
+	store, fake := newTestStore(t)
+	users := NewUserRepository(store)
+	ctx := context.Background()
+	alice := mustCreateUser(t, store, "alice")
+
+	first := fake.Now()
+	second := first.Add(time.Minute)
+	login := func(at time.Time, ip string) domain.LastLogin {
+		return domain.LastLogin{UserID: alice, Client: domain.ClientInfo{IP: ip}, At: at}
+	}
+	if err := users.UpdateLastLogins(ctx, []domain.LastLogin{login(second, "10.0.0.2")}); err != nil {
+		t.Fatalf("update last logins: %v", err)
+	}
+
+	// Replayed or out-of-order batches leave the newer login in place
+	for _, batch := range [][]domain.LastLogin{{login(first, "10.0.0.1")}, {login(second, "10.0.0.3")}} {
+		if err := users.UpdateLastLogins(ctx, batch); err != nil {
+			t.Fatalf("update last logins: %v", err)
+		}
+	}
+	user, err := users.FindByID(ctx, alice)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if !user.LastLogin.Time.Equal(second) || user.LastLoginIp == nil || *user.LastLoginIp != "10.0.0.2" {
+		t.Fatalf("last login = %v from %v, want %v from 10.0.0.2", user.LastLogin.Time, user.LastLoginIp, second)
+	}
+
+	// Unknown users in a batch do not fail the others
+	if err := users.UpdateLastLogins(ctx, []domain.LastLogin{{UserID: uuid.New(), At: second}}); err != nil {
+		t.Fatalf("update the last login of an unknown user: %v", err)
+	}
+}
+
+func TestUserRepositoryRehashPassword(t *testing.T) {
+	store, fake := newTestStore(t)
+	users := NewUserRepository(store)
+	ctx := context.Background()
+	alice := mustCreateUser(t, store, "alice")
+	before, err := users.FindByID(ctx, alice)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	fake.Advance(time.Hour)
+
+	rehashed, err := users.RehashPassword(ctx, alice, before.Password, "$argon2id$new")
+	if err != nil || !rehashed {
+		t.Fatalf("rehash = %v, %v; want rehashed", rehashed, err)
+	}
+	after, err := users.FindByID(ctx, alice)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	// The password itself did not change, so its age is kept
+	if after.Password != "$argon2id$new" || after.PasswordChangedAt != before.PasswordChangedAt {
+		t.Fatalf("after the rehash: hash %q, changed at %v; want the new hash changed at %v",
+			after.Password, after.PasswordChangedAt, before.PasswordChangedAt)
+	}
+
+	// A hash replaced since it was read is left alone
+	rehashed, err = users.RehashPassword(ctx, alice, before.Password, "$argon2id$stale")
+	if err != nil || rehashed {
+		t.Fatalf("rehash from a stale hash = %v, %v; want nothing rehashed", rehashed, err)
+	}
+	if after, _ := users.FindByID(ctx, alice); after.Password != "$argon2id$new" {
+		t.Fatalf("hash = %q after a stale rehash, want it kept", after.Password)
+	}
+}
+
+func TestUserRepositoryUpdateEmail(t *testing.T) {
+	store, fake := newTestStore(t)
+	users := NewUserRepository(store)
+	ctx := context.Background()
+	alice := mustCreateUser(t, store, "alice")
+	mustCreateUser(t, store, "bob")
+	fake.Advance(time.Hour)
+
+	if err := users.UpdateEmail(ctx, alice, "bob@example.com"); !errors.Is(err, domain.ErrEmailAlreadyExists) {
+		t.Fatalf("update to a taken email = %v, want ErrEmailAlreadyExists", err)
+	}
+	if user, _ := users.FindByID(ctx, alice); user.Email != "alice@example.com" {
+		t.Fatalf("email = %q after a collision, want it unchanged", user.Email)
+	}
+	if err := users.UpdateEmail(ctx, uuid.New(), "carol@example.com"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("update an unknown user = %v, want ErrUserNotFound", err)
+	}
+
+	if err := users.UpdateEmail(ctx, alice, "alice@university.edu"); err != nil {
+		t.Fatalf("update email: %v", err)
+	}
+	user, err := users.FindByID(ctx, alice)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if user.Email != "alice@university.edu" || !user.UpdatedAt.Time.Equal(fake.Now()) {
+		t.Fatalf("user = %s updated at %v, want the new email updated now", user.Email, user.UpdatedAt.Time)
+	}
+}
+
+func TestUserRepositoryConcurrentUpdateEmail(t *testing.T) {
+	store, _ := newTestStore(t)
+	users := NewUserRepository(store)
+	ctx := context.Background()
+	ids := make([]uuid.UUID, workers)
+	for i := range ids {
+		ids[i] = mustCreateUser(t, store, fmt.Sprintf("user%d", i))
+	}
+
+	// Every user tries to take the same email at once; exactly one gets it
+	var updated atomic.Int32
+	parallel(func(i int) {
+		err := users.UpdateEmail(ctx, ids[i], "shared@example.com")
+		switch {
+		case err == nil:
+			updated.Add(1)
+		case !errors.Is(err, domain.ErrEmailAlreadyExists):
+			t.Errorf("update email of user%d: %v", i, err)
+		}
+	})
+	if updated.Load() != 1 {
+		t.Fatalf("%d users took the email, want exactly one", updated.Load())
+	}
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/logger"
+	"worker/internal/common/acquire"
+)
+
+// acquireTracer bounds connection acquires by DB_ACQUIRE_TIMEOUT and reports the ones that time out
+// Timed out acquires are logged, marked on the request context (see package acquire)
+// and counted by pgxpool as canceled acquires, which GetDBStats exposes
+type acquireTracer struct {
+	timeout time.Duration
+	pool    string // "primary" or "replica", for the log
+	base    *zap.Logger
+}
+
+type acquireCancelKey struct{}
+
+// TraceAcquireStart gives the acquire its own deadline; queries keep running under the request context
+func (t *acquireTracer) TraceAcquireStart(ctx context.Context, _ *pgxpool.Pool, _ pgxpool.TraceAcquireStartData) context.Context {
+	if t.timeout <= 0 {
+		return ctx
+	}
+	ctx, cancel := context.WithTimeout(ctx, t.timeout)
+	return context.WithValue(ctx, acquireCancelKey{}, cancel)
+}
+
+// TraceAcquireEnd releases the acquire deadline and reports a timeout
+// Client cancellations are not timeouts and pass unreported
+func (t *acquireTracer) TraceAcquireEnd(ctx context.Context, pool *pgxpool.Pool, data pgxpool.TraceAcquireEndData) {
+	if cancel, ok := ctx.Value(acquireCancelKey{}).(context.CancelFunc); ok {
+		defer cancel()
+	}
+	if data.Err == nil || !errors.Is(data.Err, context.DeadlineExceeded) {
+		return
+	}
+
+	acquire.MarkTimedOut(ctx)
+	stat := pool.Stat()
+	logger.FromContext(ctx, t.base).Warn("Timed out acquiring a PostgreSQL connection",
+		zap.String("pool", t.pool),
+		zap.Duration("acquire_timeout", t.timeout),
+		zap.Int32("acquired", stat.AcquiredConns()),
+		zap.Int32("max", stat.MaxConns()),
+		zap.Int64("canceled_acquires", stat.CanceledAcquireCount()),
+	)
+}
+
+// TraceQueryStart is a no-op; pgx only accepts the acquire tracer in the slot of a pgx.QueryTracer
+func (t *acquireTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	return ctx
+}
+
+// TraceQueryEnd is a no-op
+func (t *acquireTracer) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
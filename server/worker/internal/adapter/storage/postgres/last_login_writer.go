@@ -0,0 +1,135 @@
+package postgres
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// lastLoginWriteTimeout bounds a single batch write
+const lastLoginWriteTimeout = 5 * time.Second
+
+// LastLoginWriter implements ports.LastLoginRecorder with a bounded queue drained by one goroutine
+// Queued logins are deduplicated per user and written in batches; the queue is flushed on shutdown
+type LastLoginWriter struct {
+	users         ports.UserRepository
+	batchSize     int
+	flushInterval time.Duration
+	logger        *zap.Logger
+
+	mu     sync.RWMutex // guards closed against Record sending on a closed queue
+	closed bool
+	queue  chan domain.LastLogin
+	done   chan struct{}
+}
+
+// NewLastLoginWriter creates a LastLoginWriter and ties its goroutine to the fx lifecycle
+func NewLastLoginWriter(lc fx.Lifecycle, cfg *config.DatabaseConfig, users ports.UserRepository, logger *zap.Logger) *LastLoginWriter {
+	w := &LastLoginWriter{
+		users:         users,
+		batchSize:     cfg.LastLoginBatchSize,
+		flushInterval: cfg.LastLoginFlushInterval,
+		logger:        logger.Named("last_login"),
+		queue:         make(chan domain.LastLogin, cfg.LastLoginQueueSize),
+		done:          make(chan struct{}),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go w.run()
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			w.close()
+			select {
+			case <-w.done:
+				return nil
+			case <-ctx.Done():
+				w.logger.Warn("Shutdown interrupted the last login flush", zap.Int("pending", len(w.queue)))
+				return ctx.Err()
+			}
+		},
+	})
+	return w
+}
+
+// Record queues a login without blocking
+// Logins are dropped when the queue is full or the writer is shutting down
+func (w *LastLoginWriter) Record(login domain.LastLogin) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.closed {
+		w.logger.Warn("Last login dropped: writer is stopped", zap.String("user_id", login.UserID.String()))
+		return
+	}
+
+	select {
+	case w.queue <- login:
+	default:
+		w.logger.Warn("Last login dropped: queue is full", zap.String("user_id", login.UserID.String()))
+	}
+}
+
+// close stops accepting logins; run flushes what is queued and exits
+func (w *LastLoginWriter) close() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.closed {
+		w.closed = true
+		close(w.queue)
+	}
+}
+
+func (w *LastLoginWriter) run() {
+	defer close(w.done)
+	ticker := time.NewTicker(w.flushInterval)
+	defer ticker.Stop()
+
+	// Only the latest login of a user matters
+	pending := make(map[uuid.UUID]domain.LastLogin)
+	for {
+		select {
+		case login, ok := <-w.queue:
+			if !ok {
+				w.flush(pending)
+				return
+			}
+			if prev, seen := pending[login.UserID]; !seen || login.At.After(prev.At) {
+				pending[login.UserID] = login
+			}
+			if len(pending) >= w.batchSize {
+				w.flush(pending)
+			}
+		case <-ticker.C:
+			w.flush(pending)
+		}
+	}
+}
+
+// flush writes and clears pending; a failed batch is logged and discarded
+func (w *LastLoginWriter) flush(pending map[uuid.UUID]domain.LastLogin) {
+	if len(pending) == 0 {
+		return
+	}
+	logins := make([]domain.LastLogin, 0, len(pending))
+	for _, login := range pending {
+		logins = append(logins, login)
+	}
+	clear(pending)
+
+	ctx, cancel := context.WithTimeout(context.Background(), lastLoginWriteTimeout)
+	defer cancel()
+	if err := w.users.UpdateLastLogins(ctx, logins); err != nil {
+		w.logger.Error("Failed to store last logins", zap.Int("count", len(logins)), zap.Error(err))
+		return
+	}
+	w.logger.Debug("Stored last logins", zap.Int("count", len(logins)))
+}
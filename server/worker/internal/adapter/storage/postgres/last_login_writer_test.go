@@ -0,0 +1,208 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// batchRecorder is a user repository keeping the batches of last logins written to it
+type batchRecorder struct {
+	ports.UserRepository
+	mu      sync.Mutex
+	batches [][]domain.LastLogin
+	err     error
+	written chan struct{} // Receives after every batch
+}
+
+func newBatchRecorder() *batchRecorder {
+	return &batchRecorder{written: make(chan struct{}, 64)}
+}
+
+func (r *batchRecorder) UpdateLastLogins(ctx context.Context, logins []domain.LastLogin) error {
+	r.mu.Lock()
+	r.batches = append(r.batches, logins)
+	r.mu.Unlock()
+	r.written <- struct{}{}
+	return r.err
+}
+
+// logins returns every written login by user
+func (r *batchRecorder) logins() map[uuid.UUID]domain.LastLogin {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	logins := map[uuid.UUID]domain.LastLogin{}
+	for _, batch := range r.batches {
+		for _, login := range batch {
+			logins[login.UserID] = login
+		}
+	}
+	return logins
+}
+
+// newTestWriter returns a LastLoginWriter over users on a test lifecycle, logging warnings and errors to logs
+func newTestWriter(t *testing.T, users ports.UserRepository, cfg config.DatabaseConfig) (*LastLoginWriter, *fxtest.Lifecycle, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	lc := fxtest.NewLifecycle(t)
+	return NewLastLoginWriter(lc, &cfg, users, zap.New(core)), lc, logs
+}
+
+var testLastLoginConfig = config.DatabaseConfig{
+	LastLoginQueueSize:     16,
+	LastLoginBatchSize:     100,
+	LastLoginFlushInterval: time.Hour, // Only shutdown and full batches flush
+}
+
+func TestLastLoginWriterDrainsOnStop(t *testing.T) {
+	users := newBatchRecorder()
+	w, lc, _ := newTestWriter(t, users, testLastLoginConfig)
+	lc.RequireStart()
+
+	alice, bob := uuid.New(), uuid.New()
+	at := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+	w.Record(domain.LastLogin{UserID: alice, At: at.Add(time.Minute), Client: domain.ClientInfo{IP: "10.0.0.2"}})
+	w.Record(domain.LastLogin{UserID: alice, At: at, Client: domain.ClientInfo{IP: "10.0.0.1"}})
+	w.Record(domain.LastLogin{UserID: bob, At: at})
+	lc.RequireStop()
+
+	// Everything queued is written before Stop returns, the latest login of each user in one batch
+	if len(users.batches) != 1 || len(users.batches[0]) != 2 {
+		t.Fatalf("batches = %v, want one batch of 2 logins", users.batches)
+	}
+	if got := users.logins()[alice]; !got.At.Equal(at.Add(time.Minute)) || got.Client.IP != "10.0.0.2" {
+		t.Fatalf("alice's login = %+v, want the latest", got)
+	}
+}
+
+func TestLastLoginWriterBatchSize(t *testing.T) {
+	users := newBatchRecorder()
+	cfg := testLastLoginConfig
+	cfg.LastLoginBatchSize = 2
+	w, lc, _ := newTestWriter(t, users, cfg)
+	lc.RequireStart()
+
+	for range 5 {
+		w.Record(domain.LastLogin{UserID: uuid.New(), At: time.Now()})
+	}
+	lc.RequireStop()
+
+	var sizes []int
+	for _, batch := range users.batches {
+		sizes = append(sizes, len(batch))
+	}
+	if len(sizes) != 3 || sizes[0] != 2 || sizes[1] != 2 || sizes[2] != 1 {
+		t.Fatalf("batch sizes = %v, want [2 2 1]", sizes)
+	}
+}
+
+func TestLastLoginWriterFlushInterval(t *testing.T) {
+	users := newBatchRecorder()
+	cfg := testLastLoginConfig
+	cfg.LastLoginFlushInterval = 10 * time.Millisecond
+	w, lc, _ := newTestWriter(t, users, cfg)
+	lc.RequireStart()
+	defer lc.RequireStop()
+
+	alice := uuid.New()
+	w.Record(domain.LastLogin{UserID: alice, At: time.Now()})
+	select {
+	case <-users.written:
+	case <-time.After(5 * time.Second):
+		t.Fatal("a partial batch was not written after the flush interval")
+	}
+	if _, ok := users.logins()[alice]; !ok {
+		t.Fatal("alice's login was not written")
+	}
+}
+
+func TestLastLoginWriterDropsWhenFull(t *testing.T) {
+	users := newBatchRecorder()
+	cfg := testLastLoginConfig
+	cfg.LastLoginQueueSize = 2
+	w, lc, logs := newTestWriter(t, users, cfg)
+
+	// Not started yet, so nothing drains the queue and Record must not block
+	for range 3 {
+		w.Record(domain.LastLogin{UserID: uuid.New(), At: time.Now()})
+	}
+	if n := logs.FilterMessage("Last login dropped: queue is full").Len(); n != 1 {
+		t.Fatalf("logged %d dropped logins, want 1", n)
+	}
+
+	lc.RequireStart()
+	lc.RequireStop()
+	if n := len(users.logins()); n != 2 {
+		t.Fatalf("wrote %d logins, want the 2 queued", n)
+	}
+
+	// After shutdown logins are dropped, not sent on the closed queue
+	w.Record(domain.LastLogin{UserID: uuid.New(), At: time.Now()})
+	if n := logs.FilterMessage("Last login dropped: writer is stopped").Len(); n != 1 {
+		t.Fatalf("logged %d logins dropped after stop, want 1", n)
+	}
+}
+
+func TestLastLoginWriterLogsFailures(t *testing.T) {
+	users := newBatchRecorder()
+	users.err = errors.New("connection reset by peer")
+	cfg := testLastLoginConfig
+	cfg.LastLoginBatchSize = 1
+	w, lc, logs := newTestWriter(t, users, cfg)
+	lc.RequireStart()
+
+	// A failed batch is logged and the writer carries on with the next one
+	w.Record(domain.LastLogin{UserID: uuid.New(), At: time.Now()})
+	w.Record(domain.LastLogin{UserID: uuid.New(), At: time.Now()})
+	lc.RequireStop()
+
+	if len(users.batches) != 2 {
+		t.Fatalf("wrote %d batches, want 2", len(users.batches))
+	}
+	if n := logs.FilterMessage("Failed to store last logins").Len(); n != 2 {
+		t.Fatalf("logged %d failed batches, want 2", n)
+	}
+}
+
+// blockedUsers is a user repository whose last login writes wait for release
+type blockedUsers struct {
+	ports.UserRepository
+	release chan struct{}
+}
+
+func (r blockedUsers) UpdateLastLogins(ctx context.Context, logins []domain.LastLogin) error {
+	<-r.release
+	return nil
+}
+
+func TestLastLoginWriterStopDeadline(t *testing.T) {
+	users := blockedUsers{release: make(chan struct{})}
+	cfg := testLastLoginConfig
+	cfg.LastLoginBatchSize = 1
+	w, lc, logs := newTestWriter(t, users, cfg)
+	lc.RequireStart()
+	w.Record(domain.LastLogin{UserID: uuid.New(), At: time.Now()})
+
+	// Shutdown does not wait past its deadline for a stuck write
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := lc.Stop(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("stop = %v, want the deadline error", err)
+	}
+	if logs.FilterMessage("Shutdown interrupted the last login flush").Len() != 1 {
+		t.Fatal("interrupted flush was not logged")
+	}
+	close(users.release)
+	<-w.done
+}
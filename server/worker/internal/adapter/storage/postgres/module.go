@@ -3,6 +3,7 @@ package postgres
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/fx"
@@ -17,6 +18,8 @@ import (
 var Module = fx.Module("postgres",
 	fx.Provide(
 		NewPostgresPool,
+		NewReplicaPool,
+		newReadRouter,
 		// Repositories - implement ports interfaces
 		fx.Annotate(
 			repository.NewUserRepository,
@@ -26,13 +29,54 @@ var Module = fx.Module("postgres",
 			repository.NewRoleRepository,
 			fx.As(new(ports.RoleRepository)),
 		),
+		fx.Annotate(
+			repository.NewSessionRepository,
+			fx.As(new(ports.SessionRepository)),
+		),
+		fx.Annotate(
+			repository.NewAccessTokenRepository,
+			fx.As(new(ports.AccessTokenRepository)),
+		),
+		fx.Annotate(
+			repository.NewPasswordResetCodeRepository,
+			fx.As(new(ports.PasswordResetCodeRepository)),
+		),
+		fx.Annotate(
+			repository.NewUnitOfWork,
+			fx.As(new(ports.UnitOfWork)),
+		),
+		fx.Annotate(
+			repository.NewStatsRepository,
+			fx.As(new(ports.ReadOnlyQuerier)),
+		),
+		fx.Annotate(
+			NewPoolStats,
+			fx.As(new(ports.DatabaseStats)),
+		),
+		fx.Annotate(
+			NewHealth,
+			fx.As(new(ports.DatabaseHealth)),
+		),
+		fx.Annotate(
+			NewLastLoginWriter,
+			fx.As(new(ports.LastLoginRecorder)),
+		),
 	),
-	fx.Invoke(verifyConnection),
+	fx.Invoke(verifyConnection, startStatsSampler, startExpiredSweeper),
 )
 
 // NewPostgresPool creates a new PostgreSQL connection pool
 func NewPostgresPool(lc fx.Lifecycle, cfg *config.DatabaseConfig, logger *zap.Logger) (*pgxpool.Pool, error) {
-	pool, err := pgxpool.New(context.Background(), cfg.GetDSN())
+	poolConfig, err := pgxpool.ParseConfig(cfg.GetDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse postgres config: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	poolConfig.ConnConfig.Tracer = &acquireTracer{timeout: cfg.AcquireTimeout, pool: "primary", base: logger}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create postgres pool: %w", err)
 	}
@@ -48,11 +92,88 @@ func NewPostgresPool(lc fx.Lifecycle, cfg *config.DatabaseConfig, logger *zap.Lo
 	return pool, nil
 }
 
+// ReplicaPool is the connection pool of the read replica
+// It is the primary pool itself when DB_REPLICA_HOST is not set
+type ReplicaPool struct {
+	*pgxpool.Pool
+}
+
+// NewReplicaPool creates the read replica connection pool, or reuses primary when no replica is configured
+// The replica is not pinged at startup; pgx connects on the first lookup routed to it
+func NewReplicaPool(lc fx.Lifecycle, cfg *config.DatabaseConfig, primary *pgxpool.Pool, logger *zap.Logger) (*ReplicaPool, error) {
+	if !cfg.HasReplica() {
+		return &ReplicaPool{Pool: primary}, nil
+	}
+
+	poolConfig, err := pgxpool.ParseConfig(cfg.GetReplicaDSN())
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse postgres replica config: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolConfig.MaxConns = cfg.MaxConns
+	}
+	poolConfig.ConnConfig.Tracer = &acquireTracer{timeout: cfg.AcquireTimeout, pool: "replica", base: logger}
+
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create postgres replica pool: %w", err)
+	}
+	logger.Info("Routing user lookups to the PostgreSQL read replica",
+		zap.String("host", cfg.ReplicaHost),
+		zap.Duration("read_after_write_window", cfg.ReplicaReadAfterWriteWindow),
+	)
+
+	lc.Append(fx.Hook{
+		OnStop: func(ctx context.Context) error {
+			logger.Info("Closing PostgreSQL replica connection pool...")
+			pool.Close()
+			return nil
+		},
+	})
+
+	return &ReplicaPool{Pool: pool}, nil
+}
+
+// newReadRouter routes user lookups between the primary and the replica pool
+func newReadRouter(primary *pgxpool.Pool, replica *ReplicaPool, cfg *config.DatabaseConfig, clock ports.Clock) *repository.ReadRouter {
+	return repository.NewReadRouter(primary, replica.Pool, cfg.ReplicaReadAfterWriteWindow, clock)
+}
+
+// Backoff between startup pings: doubles after each failed attempt, up to connectBackoffMax
+const (
+	connectBackoffInitial = 500 * time.Millisecond
+	connectBackoffMax     = 5 * time.Second
+)
+
 // verifyConnection verifies the database connection on startup
-func verifyConnection(pool *pgxpool.Pool, logger *zap.Logger) error {
-	if err := pool.Ping(context.Background()); err != nil {
-		return fmt.Errorf("failed to ping database: %w", err)
+// Each ping is bounded by DB_CONNECT_TIMEOUT and retried with backoff up to DB_CONNECT_ATTEMPTS times,
+// so a database that is still starting does not fail startup and a hung one does not block it forever
+func verifyConnection(pool *pgxpool.Pool, cfg *config.DatabaseConfig, logger *zap.Logger) error {
+	backoff := connectBackoffInitial
+	var err error
+	for attempt := 1; attempt <= cfg.ConnectAttempts; attempt++ {
+		if err = ping(pool, cfg.ConnectTimeout); err == nil {
+			logger.Info("✅ Connected to PostgreSQL", zap.Int("attempt", attempt))
+			return nil
+		}
+		if attempt == cfg.ConnectAttempts {
+			break
+		}
+
+		logger.Warn("PostgreSQL ping failed, retrying",
+			zap.Int("attempt", attempt),
+			zap.Int("max_attempts", cfg.ConnectAttempts),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		time.Sleep(backoff)
+		backoff = min(backoff*2, connectBackoffMax)
 	}
-	logger.Info("✅ Connected to PostgreSQL")
-	return nil
+	return fmt.Errorf("failed to ping database after %d attempts: %w", cfg.ConnectAttempts, err)
+}
+
+func ping(pool *pgxpool.Pool, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return pool.Ping(ctx)
 }
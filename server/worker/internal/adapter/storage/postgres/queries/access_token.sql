@@ -0,0 +1,31 @@
+-- =============================================
+-- Access Token Queries
+-- Claims of opaque access tokens (AUTH_TOKEN_TYPE=opaque),
+-- keyed by the SHA-256 of the token
+-- =============================================
+
+-- name: CreateAccessToken :exec
+-- Stores an opaque access token and drops the user's expired ones
+WITH expired AS (
+    DELETE FROM access_tokens WHERE user_id = $2 AND expires_at <= NOW()
+)
+INSERT INTO access_tokens (token_hash, user_id, claims, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetAccessToken :one
+-- Retrieves an opaque access token by the hash of the token
+SELECT * FROM access_tokens WHERE token_hash = $1 LIMIT 1;
+
+-- name: DeleteUserAccessTokens :exec
+-- Deletes every opaque access token of a user, revoking them immediately
+DELETE FROM access_tokens WHERE user_id = $1;
+
+-- name: DeleteExpiredAccessTokens :execrows
+-- Deletes up to max_tokens opaque access tokens that expired before expired_before
+-- Bounded so each statement holds its locks briefly
+DELETE FROM access_tokens WHERE token_hash IN (
+    SELECT token_hash FROM access_tokens
+    WHERE expires_at <= sqlc.arg(expired_before)
+    LIMIT sqlc.arg(max_tokens)
+    FOR UPDATE SKIP LOCKED
+);
@@ -0,0 +1,23 @@
+-- =============================================
+-- Password Reset Code Queries
+-- One-time reset codes (AUTH_RESET_TOKEN_TYPE=otp),
+-- at most one pending code per user
+-- =============================================
+
+-- name: UpsertPasswordResetCode :exec
+-- Stores a user's new reset code, replacing the pending one and its attempts
+INSERT INTO password_reset_codes (user_id, code_hash, expires_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id) DO UPDATE
+SET code_hash = EXCLUDED.code_hash, attempts = 0, expires_at = EXCLUDED.expires_at, created_at = NOW();
+
+-- name: RecordPasswordResetAttempt :one
+-- Counts a verification attempt on a user's pending reset code and returns the code
+-- The count is raised before the code is compared, so concurrent guesses cannot exceed the limit
+UPDATE password_reset_codes SET attempts = attempts + 1
+WHERE user_id = $1
+RETURNING *;
+
+-- name: DeletePasswordResetCode :exec
+-- Deletes a user's pending reset code once it has been used
+DELETE FROM password_reset_codes WHERE user_id = $1;
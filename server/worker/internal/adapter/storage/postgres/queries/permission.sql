@@ -3,7 +3,7 @@
 -- =============================================
 
 -- name: GetPermissionsByRoleID :many
--- Retrieves all permissions for a given role
+-- Retrieves all permissions for a given role, ordered by resource code
 SELECT 
     p.id,
     p.role_id,
@@ -13,13 +13,99 @@ SELECT
     r.name AS resource_name
 FROM permissions p
 JOIN resources r ON p.resource_id = r.id
-WHERE p.role_id = $1;
+WHERE p.role_id = $1
+ORDER BY r.code;
 
 -- name: GetPermissionActionsByRoleID :many
--- Retrieves flattened permission actions for a role (e.g., "users:read", "users:write")
+-- Retrieves flattened permission actions for a role (e.g., "users:read", "users:write"), deduplicated and sorted
 SELECT DISTINCT
-    r.code || ':' || action AS permission
+    (r.code || ':' || action)::text AS permission
 FROM permissions p
 JOIN resources r ON p.resource_id = r.id,
 LATERAL jsonb_array_elements_text(p.actions) AS action
-WHERE p.role_id = $1;
+WHERE p.role_id = $1
+ORDER BY permission;
+
+-- name: GetPermissionActionsByRoleIDs :many
+-- Retrieves flattened permission actions for several roles at once, one row per role and permission
+SELECT DISTINCT
+    p.role_id,
+    (r.code || ':' || action)::text AS permission
+FROM permissions p
+JOIN resources r ON p.resource_id = r.id,
+LATERAL jsonb_array_elements_text(p.actions) AS action
+WHERE p.role_id = ANY(sqlc.arg(role_ids)::uuid[])
+ORDER BY p.role_id, permission;
+
+-- name: GetPermissionActionsByUserID :many
+-- Retrieves flattened permission actions across all roles of a user, deduplicated and sorted
+SELECT DISTINCT
+    (r.code || ':' || action)::text AS permission
+FROM permissions p
+JOIN resources r ON p.resource_id = r.id,
+LATERAL jsonb_array_elements_text(p.actions) AS action
+WHERE p.role_id IN (
+    SELECT ur.role_id FROM user_roles ur WHERE ur.user_id = $1
+    UNION
+    SELECT u.role_id FROM users u WHERE u.id = $1
+)
+ORDER BY permission;
+
+-- name: GetPermissionActionsByUserIDs :many
+-- Retrieves flattened permission actions across all roles of several users at once, one row per user and permission
+SELECT DISTINCT
+    ur.user_id,
+    (r.code || ':' || action)::text AS permission
+FROM (
+    SELECT user_roles.user_id, user_roles.role_id FROM user_roles WHERE user_roles.user_id = ANY(sqlc.arg(user_ids)::uuid[])
+    UNION
+    SELECT users.id, users.role_id FROM users WHERE users.id = ANY(sqlc.arg(user_ids)::uuid[])
+) ur
+JOIN permissions p ON p.role_id = ur.role_id
+JOIN resources r ON p.resource_id = r.id,
+LATERAL jsonb_array_elements_text(p.actions) AS action
+ORDER BY ur.user_id, permission;
+
+-- name: ListPermissions :many
+-- Retrieves the permissions of every role with their resource, ordered by role and resource code
+SELECT
+    p.role_id,
+    p.actions,
+    r.code AS resource_code,
+    r.name AS resource_name
+FROM permissions p
+JOIN resources r ON p.resource_id = r.id
+ORDER BY p.role_id, r.code;
+
+-- name: GetResourceByCode :one
+-- Retrieves a resource by its code (e.g., "users")
+SELECT * FROM resources WHERE code = $1;
+
+-- name: AddPermissionAction :exec
+-- Grants an action on a resource to a role, creating the role's permissions row for the resource if needed
+-- Granting an action the role already holds changes nothing
+WITH updated AS (
+    UPDATE permissions
+    SET actions = CASE
+        WHEN COALESCE(actions, '[]') ? sqlc.arg(action)::text THEN actions
+        ELSE COALESCE(actions, '[]') || jsonb_build_array(sqlc.arg(action)::text)
+    END
+    WHERE role_id = sqlc.arg(role_id) AND resource_id = sqlc.arg(resource_id)
+    RETURNING id
+)
+INSERT INTO permissions (role_id, resource_id, actions)
+SELECT sqlc.arg(role_id), sqlc.arg(resource_id), jsonb_build_array(sqlc.arg(action)::text)
+WHERE NOT EXISTS (SELECT 1 FROM updated);
+
+-- name: RemovePermissionAction :execrows
+-- Revokes an action on a resource from a role; no rows are affected when the role does not hold it
+UPDATE permissions
+SET actions = actions - sqlc.arg(action)::text
+WHERE role_id = sqlc.arg(role_id)
+  AND resource_id = sqlc.arg(resource_id)
+  AND actions ? sqlc.arg(action)::text;
+
+-- name: DeleteEmptyPermissions :exec
+-- Deletes the role's permissions rows for a resource that no longer grant any action
+DELETE FROM permissions
+WHERE role_id = $1 AND resource_id = $2 AND (actions IS NULL OR actions = '[]'::jsonb);
@@ -19,3 +19,7 @@ SELECT * FROM roles WHERE code = 'STUDENT' LIMIT 1;
 INSERT INTO roles (name, code, description)
 VALUES ($1, $2, $3)
 RETURNING *;
+
+-- name: ListRoles :many
+-- Retrieves every role, ordered by code
+SELECT * FROM roles ORDER BY code;
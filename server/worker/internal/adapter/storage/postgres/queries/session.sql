@@ -0,0 +1,74 @@
+-- =============================================
+-- Session Queries
+-- A session tracks one refresh token family; the refresh
+-- nonce is rotated on every refresh to detect replay
+-- =============================================
+
+-- name: CreateSession :one
+-- Creates a new session for a user
+INSERT INTO sessions (id, user_id, refresh_nonce, expires_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetSessionByID :one
+-- Retrieves a session by ID
+SELECT * FROM sessions WHERE id = $1 LIMIT 1;
+
+-- name: ListActiveUserSessionIDs :many
+-- Lists the sessions of a user that can still refresh, oldest first
+-- idle_before is NULL when there is no idle timeout
+SELECT id FROM sessions
+WHERE user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL
+  AND expires_at > sqlc.arg(now)
+  AND (sqlc.narg(idle_before)::timestamp IS NULL OR last_seen_at IS NULL OR last_seen_at > sqlc.narg(idle_before)::timestamp)
+ORDER BY created_at, id;
+
+-- name: RotateSessionNonce :execrows
+-- Replaces the refresh nonce only if the presented nonce is still current
+-- (compare-and-swap, so a nonce can be redeemed at most once)
+UPDATE sessions
+SET refresh_nonce = sqlc.arg(new_nonce),
+    expires_at = sqlc.arg(expires_at),
+    updated_at = NOW(),
+    last_seen_at = sqlc.arg(seen_at)
+WHERE id = sqlc.arg(id)
+  AND refresh_nonce = sqlc.arg(current_nonce)
+  AND revoked_at IS NULL;
+
+-- name: MarkSessionSeen :execrows
+-- Moves last_seen_at of an active session to seen_at when it is older than stale_before,
+-- so a session in use is written at most once per interval
+-- Sessions last seen before idle_before (NULL when there is no idle timeout) stay idle
+UPDATE sessions
+SET last_seen_at = sqlc.arg(seen_at)
+WHERE id = sqlc.arg(id)
+  AND revoked_at IS NULL
+  AND (last_seen_at IS NULL OR last_seen_at <= sqlc.arg(stale_before)::timestamp)
+  AND (sqlc.narg(idle_before)::timestamp IS NULL OR last_seen_at IS NULL OR last_seen_at > sqlc.narg(idle_before)::timestamp);
+
+-- name: RevokeSession :exec
+-- Revokes a session, invalidating its refresh token
+UPDATE sessions SET revoked_at = NOW(), updated_at = NOW()
+WHERE id = $1 AND revoked_at IS NULL;
+
+-- name: RevokeUserSessions :exec
+-- Revokes all active sessions of a user
+UPDATE sessions SET revoked_at = NOW(), updated_at = NOW()
+WHERE user_id = $1 AND revoked_at IS NULL;
+
+-- name: DeleteUserSessions :exec
+-- Deletes every session of a user, revoked or not, before the user is purged
+DELETE FROM sessions WHERE user_id = $1;
+
+-- name: DeleteExpiredSessions :execrows
+-- Deletes up to max_sessions sessions that expired before expired_before, or were last seen before
+-- idle_before (NULL when there is no idle timeout), revoked or not
+-- Bounded so each statement holds its locks briefly; rows locked by a refresh are left for the next batch
+DELETE FROM sessions WHERE id IN (
+    SELECT id FROM sessions
+    WHERE expires_at <= sqlc.arg(expired_before)
+       OR last_seen_at <= sqlc.narg(idle_before)
+    LIMIT sqlc.arg(max_sessions)
+    FOR UPDATE SKIP LOCKED
+);
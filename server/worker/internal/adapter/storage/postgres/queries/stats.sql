@@ -0,0 +1,25 @@
+-- =============================================
+-- Statistics Queries (read-only aggregates for reporting)
+-- =============================================
+
+-- name: CountUsersByRole :many
+-- Counts the users of a tenant per primary role (users.role_id), including roles nobody holds
+SELECT
+    r.code AS role_code,
+    r.name AS role_name,
+    COUNT(u.id) AS user_count
+FROM roles r
+LEFT JOIN users u ON u.role_id = r.id AND u.tenant_id = $1
+GROUP BY r.id, r.code, r.name
+ORDER BY r.code;
+
+-- name: CountRegistrationsPerDay :many
+-- Counts the users of a tenant registered per day since the given time; days without registrations are omitted
+SELECT
+    u.created_at::date AS day,
+    COUNT(*) AS registrations
+FROM users u
+WHERE u.tenant_id = sqlc.arg(tenant_id)
+  AND u.created_at >= sqlc.arg(since)::timestamp
+GROUP BY day
+ORDER BY day;
@@ -15,9 +15,11 @@ INSERT INTO users (
     avatar,
     is_active,
     created_at,
-    updated_at
+    updated_at,
+    tenant_id,
+    locale
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
 ) RETURNING *;
 
 -- name: GetUserByID :one
@@ -31,42 +33,175 @@ LEFT JOIN roles r ON u.role_id = r.id
 WHERE u.id = $1;
 
 -- name: GetUserByEmail :one
--- Retrieves a user by their email address with role info
+-- Retrieves a user by their email address within a tenant with role info
 SELECT 
     u.*,
     r.name AS role_name,
     r.code AS role_code
 FROM users u
 LEFT JOIN roles r ON u.role_id = r.id
-WHERE u.email = $1;
+WHERE u.email = $1 AND u.tenant_id = $2;
 
 -- name: GetUserByUsername :one
--- Retrieves a user by their username with role info
+-- Retrieves a user by their username within a tenant with role info
 SELECT 
     u.*,
     r.name AS role_name,
     r.code AS role_code
 FROM users u
 LEFT JOIN roles r ON u.role_id = r.id
-WHERE u.username = $1;
+WHERE u.username = $1 AND u.tenant_id = $2;
+
+-- name: GetUserByUsernameIgnoringCase :one
+-- Retrieves a user by their username in any letter case within a tenant with role info
+SELECT 
+    u.*,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE LOWER(u.username) = LOWER($1) AND u.tenant_id = $2;
 
 -- name: GetUserByEmailOrUsername :one
--- Retrieves a user by email OR username (for login) with role info
+-- Retrieves a user by email OR username within a tenant (for login) with role info
+SELECT 
+    u.*,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE (u.email = $1 OR u.username = $1) AND u.tenant_id = $2;
+
+-- name: GetUserByEmailOrUsernameIgnoringCase :one
+-- Retrieves a user by email OR username in any letter case within a tenant (for login) with role info
+SELECT 
+    u.*,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE (u.email = $1 OR LOWER(u.username) = LOWER($1)) AND u.tenant_id = $2;
+
+-- name: ListUsers :many
+-- Lists users of a tenant in (created_at, id) order after the keyset position, using idx_users_tenant_created_id
+-- pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+SELECT 
+    u.*,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::bool IS NULL OR COALESCE(u.is_active, TRUE) = sqlc.narg(is_active)::bool)
+  AND (u.created_at, u.id) > (sqlc.arg(after_time)::timestamp, sqlc.arg(after_id)::uuid)
+  AND (NOT sqlc.arg(pending_approval)::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY u.created_at ASC, u.id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListUsersDesc :many
+-- Lists users of a tenant in descending (created_at, id) order before the keyset position
+-- pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+SELECT 
+    u.*,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::bool IS NULL OR COALESCE(u.is_active, TRUE) = sqlc.narg(is_active)::bool)
+  AND (u.created_at, u.id) < (sqlc.arg(after_time)::timestamp, sqlc.arg(after_id)::uuid)
+  AND (NOT sqlc.arg(pending_approval)::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY u.created_at DESC, u.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListUsersByLastLogin :many
+-- Lists users of a tenant in (last_login, id) order after the keyset position, using idx_users_tenant_last_login_id
+-- Users who never logged in sort as logging in at the epoch
+-- pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+SELECT 
+    u.*,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::bool IS NULL OR COALESCE(u.is_active, TRUE) = sqlc.narg(is_active)::bool)
+  AND (COALESCE(u.last_login, 'epoch'::timestamp), u.id) > (sqlc.arg(after_time)::timestamp, sqlc.arg(after_id)::uuid)
+  AND (NOT sqlc.arg(pending_approval)::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY COALESCE(u.last_login, 'epoch'::timestamp) ASC, u.id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListUsersByLastLoginDesc :many
+-- Lists users of a tenant in descending (last_login, id) order before the keyset position
+-- pending_approval keeps inactive users who never logged in and are not scheduled for deletion
 SELECT 
     u.*,
     r.name AS role_name,
     r.code AS role_code
 FROM users u
 LEFT JOIN roles r ON u.role_id = r.id
-WHERE u.email = $1 OR u.username = $1;
+WHERE u.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::bool IS NULL OR COALESCE(u.is_active, TRUE) = sqlc.narg(is_active)::bool)
+  AND (COALESCE(u.last_login, 'epoch'::timestamp), u.id) < (sqlc.arg(after_time)::timestamp, sqlc.arg(after_id)::uuid)
+  AND (NOT sqlc.arg(pending_approval)::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY COALESCE(u.last_login, 'epoch'::timestamp) DESC, u.id DESC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListUsersByUsername :many
+-- Lists users of a tenant in bytewise (username, id) order after the keyset position, using idx_users_tenant_username_id
+-- pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+SELECT 
+    u.*,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::bool IS NULL OR COALESCE(u.is_active, TRUE) = sqlc.narg(is_active)::bool)
+  AND (u.username COLLATE "C", u.id) > (sqlc.arg(after_text)::text, sqlc.arg(after_id)::uuid)
+  AND (NOT sqlc.arg(pending_approval)::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY u.username COLLATE "C" ASC, u.id ASC
+LIMIT sqlc.arg(page_size);
+
+-- name: ListUsersByUsernameDesc :many
+-- Lists users of a tenant in descending bytewise (username, id) order before the keyset position
+-- pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+SELECT 
+    u.*,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.narg(is_active)::bool IS NULL OR COALESCE(u.is_active, TRUE) = sqlc.narg(is_active)::bool)
+  AND (u.username COLLATE "C", u.id) < (sqlc.arg(after_text)::text, sqlc.arg(after_id)::uuid)
+  AND (NOT sqlc.arg(pending_approval)::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY u.username COLLATE "C" DESC, u.id DESC
+LIMIT sqlc.arg(page_size);
 
 -- name: ExistsByEmail :one
--- Checks if a user with the given email exists
-SELECT EXISTS(SELECT 1 FROM users WHERE email = $1) AS exists;
+-- Checks if a user with the given email exists within a tenant
+SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND tenant_id = $2) AS exists;
 
 -- name: ExistsByUsername :one
--- Checks if a user with the given username exists
-SELECT EXISTS(SELECT 1 FROM users WHERE username = $1) AS exists;
+-- Checks if a user with the given username exists within a tenant
+SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 AND tenant_id = $2) AS exists;
+
+-- name: ExistsByUsernameIgnoringCase :one
+-- Checks if a user with the given username, in any letter case, exists within a tenant
+SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(username) = LOWER(sqlc.arg(username)) AND tenant_id = sqlc.arg(tenant_id)) AS exists;
+
+-- name: ExistingEmails :many
+-- Returns which of several emails are taken within a tenant, for batch availability checks
+SELECT email FROM users WHERE tenant_id = sqlc.arg(tenant_id) AND email = ANY(sqlc.arg(emails)::text[]);
+
+-- name: ExistingUsernames :many
+-- Returns which of several usernames are taken within a tenant, for batch availability checks
+SELECT username FROM users WHERE tenant_id = sqlc.arg(tenant_id) AND username = ANY(sqlc.arg(usernames)::text[]);
+
+-- name: ExistingUsernamesIgnoringCase :many
+-- Returns which of several lowercase usernames are taken in any letter case within a tenant, lowercased
+SELECT LOWER(username)::text AS username FROM users WHERE tenant_id = sqlc.arg(tenant_id) AND LOWER(username) = ANY(sqlc.arg(usernames)::text[]);
 
 -- name: UpdateUser :one
 -- Updates an existing user
@@ -82,10 +217,93 @@ UPDATE users SET
 WHERE id = $1
 RETURNING *;
 
--- name: UpdateLastLogin :exec
--- Updates the last login timestamp for a user
-UPDATE users SET last_login = NOW() WHERE id = $1;
+-- name: UpdateLastLogins :exec
+-- Applies a batch of last logins (one per user); empty IPs and user agents are stored as NULL
+-- Logins older than the stored one are skipped, so replaying a batch is a no-op
+UPDATE users AS u SET
+    last_login = v.logged_in_at,
+    last_login_ip = NULLIF(v.ip, ''),
+    last_login_user_agent = NULLIF(v.user_agent, '')
+FROM unnest(
+    sqlc.arg(user_ids)::uuid[],
+    sqlc.arg(logged_in_at)::timestamp[],
+    sqlc.arg(ips)::text[],
+    sqlc.arg(user_agents)::text[]
+) AS v(id, logged_in_at, ip, user_agent)
+WHERE u.id = v.id
+  AND (u.last_login IS NULL OR u.last_login < v.logged_in_at);
+
+-- name: UpdateUserActive :execrows
+-- Activates or deactivates a user
+UPDATE users SET is_active = sqlc.arg(is_active), updated_at = NOW() WHERE id = sqlc.arg(id);
+
+-- name: UpdateUserAvatar :exec
+-- Updates the avatar URL of a user
+UPDATE users SET avatar = $2, updated_at = NOW() WHERE id = $1;
 
--- name: DeleteUser :exec
+-- name: UpdateUserEmail :execrows
+-- Changes the email of a user; the tenant's unique constraint rejects an email already in use
+UPDATE users SET email = sqlc.arg(email), updated_at = NOW() WHERE id = sqlc.arg(id);
+
+-- name: UpdateUserPassword :exec
+-- Replaces the password hash and records when it was changed
+-- and lifts a forced reset
+UPDATE users SET password = $2, password_changed_at = NOW(), must_reset_password = FALSE, updated_at = NOW() WHERE id = $1;
+
+-- name: RehashUserPassword :execrows
+-- Replaces the password hash with a new hash of the same password, leaving password_changed_at alone
+-- Nothing is updated when the password changed since old_hash was read
+UPDATE users SET password = sqlc.arg(new_hash) WHERE id = sqlc.arg(id) AND password = sqlc.arg(old_hash);
+
+-- name: UpdateUsername :execrows
+-- Renames a user unless the previous rename is more recent than the cooldown
+UPDATE users SET
+    username = sqlc.arg(username),
+    username_changed_at = NOW(),
+    updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND (username_changed_at IS NULL OR username_changed_at <= NOW() - sqlc.arg(cooldown)::interval);
+
+-- name: GetTokenVersion :one
+-- Retrieves the current token version of a user
+SELECT token_version FROM users WHERE id = $1;
+
+-- name: GetTokenSubjectsByIDs :many
+-- Retrieves the email and token version of several users at once, for batch token validation
+SELECT id, email, token_version FROM users WHERE id = ANY(sqlc.arg(ids)::uuid[]);
+
+-- name: IncrementTokenVersion :one
+-- Bumps the token version, invalidating every access token issued before
+UPDATE users SET token_version = token_version + 1, updated_at = NOW() WHERE id = $1
+RETURNING token_version;
+
+-- name: DeleteUser :execrows
 -- Soft delete is not implemented, this is hard delete
 DELETE FROM users WHERE id = $1;
+
+-- name: RequirePasswordReset :one
+-- Refuses logins until the password is reset and bumps the token version, invalidating every access token issued before
+UPDATE users SET must_reset_password = TRUE, token_version = token_version + 1, updated_at = NOW() WHERE id = $1
+RETURNING token_version;
+
+-- name: ScheduleUserDeletion :one
+-- Schedules the erasure of a user; an existing schedule is kept, so repeating the request does not postpone it
+UPDATE users SET scheduled_deletion_at = COALESCE(scheduled_deletion_at, sqlc.arg(deletion_at)::timestamp), updated_at = NOW()
+WHERE id = sqlc.arg(id)
+RETURNING scheduled_deletion_at;
+
+-- name: CancelUserDeletion :execrows
+-- Cancels the scheduled erasure of a user
+UPDATE users SET scheduled_deletion_at = NULL, updated_at = NOW()
+WHERE id = $1 AND scheduled_deletion_at IS NOT NULL;
+
+-- name: ListUsersDueForDeletion :many
+-- Lists users whose scheduled erasure is due, oldest schedule first
+SELECT id, tenant_id FROM users
+WHERE scheduled_deletion_at <= sqlc.arg(due_by)
+ORDER BY scheduled_deletion_at
+LIMIT sqlc.arg(max_users);
+
+-- name: DeleteUserIfDue :execrows
+-- Erases a user whose scheduled erasure is due; a cancelled schedule deletes nothing
+DELETE FROM users WHERE id = sqlc.arg(id) AND scheduled_deletion_at <= sqlc.arg(due_by);
@@ -0,0 +1,33 @@
+-- =============================================
+-- User Role Queries (multi-role support)
+-- A user's roles are the primary role on users.role_id
+-- plus any additional roles in user_roles
+-- =============================================
+
+-- name: GetRolesByUserID :many
+-- Retrieves all roles assigned to a user (primary + additional)
+SELECT * FROM roles
+WHERE id IN (
+    SELECT ur.role_id FROM user_roles ur WHERE ur.user_id = $1
+    UNION
+    SELECT u.role_id FROM users u WHERE u.id = $1
+)
+ORDER BY code;
+
+-- name: AddUserRole :exec
+-- Assigns an additional role to a user (no-op if already assigned)
+INSERT INTO user_roles (user_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveUserRole :execrows
+-- Removes an additional role from a user
+DELETE FROM user_roles WHERE user_id = $1 AND role_id = $2;
+
+-- name: DeleteUserRoles :exec
+-- Removes every additional role of a user, before the user is purged
+DELETE FROM user_roles WHERE user_id = $1;
+
+-- name: UpdateUserPrimaryRole :exec
+-- Changes the primary role stored on the users row
+UPDATE users SET role_id = $2, updated_at = NOW() WHERE id = $1;
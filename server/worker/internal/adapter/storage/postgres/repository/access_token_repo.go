@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+)
+
+// AccessTokenRepository implements ports.AccessTokenRepository using sqlc generated queries
+type AccessTokenRepository struct {
+	queries *sqlc.Queries
+}
+
+// NewAccessTokenRepository creates a new AccessTokenRepository instance
+func NewAccessTokenRepository(pool *pgxpool.Pool) *AccessTokenRepository {
+	return &AccessTokenRepository{
+		queries: newQueries(pool),
+	}
+}
+
+// Create stores an opaque access token and drops the user's expired ones
+func (r *AccessTokenRepository) Create(ctx context.Context, params sqlc.CreateAccessTokenParams) error {
+	return r.queries.CreateAccessToken(ctx, params)
+}
+
+// FindByHash retrieves an opaque access token by the hash of the token
+func (r *AccessTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*sqlc.AccessToken, error) {
+	token, err := r.queries.GetAccessToken(ctx, tokenHash)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return &token, nil
+}
+
+// DeleteAllForUser deletes every opaque access token of a user
+func (r *AccessTokenRepository) DeleteAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.queries.DeleteUserAccessTokens(ctx, userID)
+}
+
+// DeleteExpired deletes up to limit tokens that expired before the given time and returns how many
+func (r *AccessTokenRepository) DeleteExpired(ctx context.Context, before time.Time, limit int32) (int64, error) {
+	return r.queries.DeleteExpiredAccessTokens(ctx, sqlc.DeleteExpiredAccessTokensParams{
+		ExpiredBefore: pgtype.Timestamp{Time: before, Valid: true},
+		MaxTokens:     limit,
+	})
+}
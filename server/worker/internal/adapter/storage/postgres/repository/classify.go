@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+)
+
+// unavailableSQLStates are server errors meaning the database cannot serve the query right now
+// Class 08 (connection exception) is matched by prefix
+var unavailableSQLStates = map[string]bool{
+	"25006": true, // read_only_sql_transaction: writing to a standby during a failover
+	"53300": true, // too_many_connections
+	"57P01": true, // admin_shutdown
+	"57P02": true, // crash_shutdown
+	"57P03": true, // cannot_connect_now: starting up or in recovery
+}
+
+// classifyError marks failures of reaching the database with domain.ErrDatabaseUnavailable, keeping err wrapped
+// Query errors (constraint violations, pgx.ErrNoRows...) and context errors are returned unchanged
+func classifyError(err error) error {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	if databaseUnavailable(err) {
+		return fmt.Errorf("%w: %w", domain.ErrDatabaseUnavailable, err)
+	}
+	return err
+}
+
+func databaseUnavailable(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return strings.HasPrefix(pgErr.Code, "08") || unavailableSQLStates[pgErr.Code]
+	}
+
+	var connectErr *pgconn.ConnectError
+	var netErr net.Error
+	return errors.As(err, &connectErr) ||
+		errors.As(err, &netErr) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		pgconn.SafeToRetry(err)
+}
+
+// newQueries returns sqlc queries on db whose errors go through classifyError
+func newQueries(db sqlc.DBTX) *sqlc.Queries {
+	return sqlc.New(classifiedDB{db: db})
+}
+
+// classifiedDB is a sqlc.DBTX classifying the errors of a pool or transaction
+type classifiedDB struct {
+	db sqlc.DBTX
+}
+
+func (c classifiedDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	tag, err := c.db.Exec(ctx, sql, args...)
+	return tag, classifyError(err)
+}
+
+func (c classifiedDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	rows, err := c.db.Query(ctx, sql, args...)
+	if err != nil {
+		return rows, classifyError(err)
+	}
+	return classifiedRows{Rows: rows}, nil
+}
+
+func (c classifiedDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	return classifiedRow{row: c.db.QueryRow(ctx, sql, args...)}
+}
+
+// classifiedRows classifies the errors reported while reading rows
+type classifiedRows struct {
+	pgx.Rows
+}
+
+func (r classifiedRows) Err() error {
+	return classifyError(r.Rows.Err())
+}
+
+func (r classifiedRows) Scan(dest ...any) error {
+	return classifyError(r.Rows.Scan(dest...))
+}
+
+// classifiedRow classifies the error of a single-row query, reported by Scan
+type classifiedRow struct {
+	row pgx.Row
+}
+
+func (r classifiedRow) Scan(dest ...any) error {
+	return classifyError(r.row.Scan(dest...))
+}
@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"worker/internal/core/domain"
+)
+
+// connectError dials a port nothing listens on and returns the *pgconn.ConnectError pgconn reports
+func connectError(t *testing.T) error {
+	t.Helper()
+	_, err := pgconn.Connect(context.Background(), "postgres://worker@127.0.0.1:1/worker?connect_timeout=1")
+	var connectErr *pgconn.ConnectError
+	if !errors.As(err, &connectErr) {
+		t.Fatalf("dial error %v is not a *pgconn.ConnectError", err)
+	}
+	return err
+}
+
+func TestDatabaseUnavailable(t *testing.T) {
+	dialErr := connectError(t)
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "connection failure", err: &pgconn.PgError{Code: "08006"}, want: true},
+		{name: "connection does not exist", err: &pgconn.PgError{Code: "08003"}, want: true},
+		{name: "too many connections", err: &pgconn.PgError{Code: "53300"}, want: true},
+		{name: "cannot connect now", err: &pgconn.PgError{Code: "57P03"}, want: true},
+		{name: "admin shutdown", err: &pgconn.PgError{Code: "57P01"}, want: true},
+		{name: "read-only transaction", err: &pgconn.PgError{Code: "25006"}, want: true},
+		{name: "wrapped server error", err: fmt.Errorf("create user: %w", &pgconn.PgError{Code: "53300"}), want: true},
+		{name: "connect error", err: dialErr, want: true},
+		{name: "EOF", err: io.EOF, want: true},
+		{name: "unexpected EOF", err: io.ErrUnexpectedEOF, want: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: pgUniqueViolation}, want: false},
+		{name: "foreign key violation", err: &pgconn.PgError{Code: pgForeignKeyViolation}, want: false},
+		{name: "no rows", err: pgx.ErrNoRows, want: false},
+		{name: "other error", err: errors.New("boom"), want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := databaseUnavailable(tt.err); got != tt.want {
+				t.Fatalf("databaseUnavailable(%v) = %t, want %t", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifyError(t *testing.T) {
+	dialErr := connectError(t)
+
+	tests := []struct {
+		name        string
+		err         error
+		unavailable bool
+	}{
+		{name: "server unavailable", err: &pgconn.PgError{Code: "57P03"}, unavailable: true},
+		{name: "connect error", err: dialErr, unavailable: true},
+		{name: "EOF", err: io.EOF, unavailable: true},
+		{name: "unique violation", err: &pgconn.PgError{Code: pgUniqueViolation}},
+		{name: "no rows", err: pgx.ErrNoRows},
+		// A query cut short by the request is the caller's doing, even if the connection broke as a result
+		{name: "deadline exceeded", err: fmt.Errorf("%w: %w", context.DeadlineExceeded, io.EOF)},
+		{name: "canceled", err: fmt.Errorf("%w: %w", context.Canceled, io.EOF)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := classifyError(tt.err)
+			if errors.Is(got, domain.ErrDatabaseUnavailable) != tt.unavailable {
+				t.Fatalf("classifyError(%v) = %v, want unavailable %t", tt.err, got, tt.unavailable)
+			}
+			// The original error stays reachable for errors.Is and errors.As
+			if !errors.Is(got, tt.err) {
+				t.Fatalf("classifyError(%v) = %v, which no longer wraps the original error", tt.err, got)
+			}
+		})
+	}
+
+	if classifyError(nil) != nil {
+		t.Fatal("classifyError(nil) is not nil")
+	}
+}
@@ -0,0 +1,51 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"worker/internal/core/domain"
+)
+
+// pgUniqueViolation is the SQLSTATE of unique constraint violations
+const pgUniqueViolation = "23505"
+
+// pgForeignKeyViolation is the SQLSTATE of foreign key constraint violations
+const pgForeignKeyViolation = "23503"
+
+// uniqueViolation returns the violated constraint name if err is a unique constraint violation
+func uniqueViolation(err error) (string, bool) {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == pgUniqueViolation {
+		return pgErr.ConstraintName, true
+	}
+	return "", false
+}
+
+// isForeignKeyViolation reports whether err is a foreign key constraint violation
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == pgForeignKeyViolation
+}
+
+// constraintOnColumn reports whether a constraint name refers to the given column
+// Matches Postgres default names (users_email_key), Drizzle names (users_email_unique)
+// and per-tenant composite names (users_tenant_email_unique)
+func constraintOnColumn(constraint, table, column string) bool {
+	return strings.HasPrefix(constraint, table+"_"+column+"_") ||
+		strings.HasPrefix(constraint, table+"_tenant_"+column+"_")
+}
+
+// userConflict returns the domain error for a violated unique constraint of the users table
+func userConflict(constraint string) error {
+	switch {
+	case constraintOnColumn(constraint, "users", "email"):
+		return domain.ErrEmailAlreadyExists
+	case constraintOnColumn(constraint, "users", "username"):
+		return domain.ErrUsernameAlreadyExists
+	default:
+		return domain.ErrUserAlreadyExists
+	}
+}
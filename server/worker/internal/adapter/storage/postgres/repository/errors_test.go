@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"worker/internal/core/domain"
+)
+
+func TestUserConflict(t *testing.T) {
+	tests := []struct {
+		constraint string
+		want       error
+	}{
+		{constraint: "users_tenant_email_unique", want: domain.ErrEmailAlreadyExists},
+		{constraint: "users_tenant_username_unique", want: domain.ErrUsernameAlreadyExists},
+		{constraint: "users_email_key", want: domain.ErrEmailAlreadyExists},
+		{constraint: "users_email_unique", want: domain.ErrEmailAlreadyExists},
+		{constraint: "users_username_key", want: domain.ErrUsernameAlreadyExists},
+		{constraint: "users_username_unique", want: domain.ErrUsernameAlreadyExists},
+		// Names that merely start with a column name belong to another column
+		{constraint: "users_emailer_key", want: domain.ErrUserAlreadyExists},
+		{constraint: "users_pkey", want: domain.ErrUserAlreadyExists},
+		{constraint: "", want: domain.ErrUserAlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.constraint, func(t *testing.T) {
+			if got := userConflict(tt.constraint); !errors.Is(got, tt.want) {
+				t.Fatalf("userConflict(%q) = %v, want %v", tt.constraint, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUniqueViolation(t *testing.T) {
+	constraint, ok := uniqueViolation(fmt.Errorf("create user: %w", &pgconn.PgError{
+		Code:           pgUniqueViolation,
+		ConstraintName: "users_tenant_email_unique",
+	}))
+	if !ok || constraint != "users_tenant_email_unique" {
+		t.Fatalf("uniqueViolation = %q, %t; want users_tenant_email_unique", constraint, ok)
+	}
+
+	for _, err := range []error{
+		&pgconn.PgError{Code: pgForeignKeyViolation, ConstraintName: "users_role_id_fkey"},
+		errors.New("boom"),
+		nil,
+	} {
+		if constraint, ok := uniqueViolation(err); ok {
+			t.Fatalf("uniqueViolation(%v) = %q, want no violation", err, constraint)
+		}
+	}
+}
+
+func TestDeleteUserError(t *testing.T) {
+	fkErr := fmt.Errorf("delete user: %w", &pgconn.PgError{
+		Code:           pgForeignKeyViolation,
+		ConstraintName: "submissions_user_id_users_id_fk",
+	})
+	err := deleteUserError(fkErr)
+	if !errors.Is(err, domain.ErrUserReferenced) {
+		t.Fatalf("deleteUserError(%v) = %v, want ErrUserReferenced", fkErr, err)
+	}
+	// The driver error stays wrapped for the logs
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.ConstraintName != "submissions_user_id_users_id_fk" {
+		t.Fatalf("deleteUserError(%v) = %v, want the driver error wrapped", fkErr, err)
+	}
+
+	other := errors.New("boom")
+	if err := deleteUserError(other); err != other {
+		t.Fatalf("deleteUserError(%v) = %v, want it unchanged", other, err)
+	}
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+)
+
+// PasswordResetCodeRepository implements ports.PasswordResetCodeRepository using sqlc generated queries
+type PasswordResetCodeRepository struct {
+	queries *sqlc.Queries
+}
+
+// NewPasswordResetCodeRepository creates a new PasswordResetCodeRepository instance
+func NewPasswordResetCodeRepository(pool *pgxpool.Pool) *PasswordResetCodeRepository {
+	return &PasswordResetCodeRepository{
+		queries: sqlc.New(pool),
+	}
+}
+
+// Save stores a user's new reset code, replacing the pending one and resetting its attempts
+func (r *PasswordResetCodeRepository) Save(ctx context.Context, params sqlc.UpsertPasswordResetCodeParams) error {
+	return r.queries.UpsertPasswordResetCode(ctx, params)
+}
+
+// RecordAttempt counts a verification attempt on a user's pending code and returns the code
+func (r *PasswordResetCodeRepository) RecordAttempt(ctx context.Context, userID uuid.UUID) (*sqlc.PasswordResetCode, error) {
+	code, err := r.queries.RecordPasswordResetAttempt(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrTokenNotFound
+		}
+		return nil, err
+	}
+	return &code, nil
+}
+
+// Delete deletes a user's pending code
+func (r *PasswordResetCodeRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	return r.queries.DeletePasswordResetCode(ctx, userID)
+}
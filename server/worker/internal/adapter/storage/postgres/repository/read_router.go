@@ -0,0 +1,102 @@
+package repository
+
+import (
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/ports"
+)
+
+// ReadRouter picks the pool a lookup reads from: the replica, or the primary for recently written rows
+// Writes are remembered per key for the read-after-write window, so a user who just registered
+// or renamed is read back from the primary while the replica may still lag behind
+// Writes are only known to this process; another replica of the worker may still read stale rows
+type ReadRouter struct {
+	primary *sqlc.Queries
+	replica *sqlc.Queries
+	window  time.Duration
+	clock   ports.Clock
+
+	mu     sync.Mutex
+	writes map[string]time.Time // Key -> end of its read-after-write window
+}
+
+// NewReadRouter creates a ReadRouter; pass the primary pool as replica when no replica is configured
+func NewReadRouter(primary, replica *pgxpool.Pool, window time.Duration, clock ports.Clock) *ReadRouter {
+	router := &ReadRouter{
+		primary: newQueries(primary),
+		window:  window,
+		clock:   clock,
+		writes:  make(map[string]time.Time),
+	}
+	router.replica = router.primary
+	if replica != primary {
+		router.replica = newQueries(replica)
+	}
+	return router
+}
+
+// hasReplica reports whether lookups can go anywhere but the primary
+func (r *ReadRouter) hasReplica() bool {
+	return r.replica != r.primary
+}
+
+// Queries returns the queries to read keys with: the primary if any key was written within the window
+func (r *ReadRouter) Queries(keys ...string) *sqlc.Queries {
+	if r.Recent(keys...) {
+		return r.primary
+	}
+	return r.replica
+}
+
+// Primary returns the queries of the primary pool
+func (r *ReadRouter) Primary() *sqlc.Queries {
+	return r.primary
+}
+
+// Recent reports whether any of keys was written within the read-after-write window
+func (r *ReadRouter) Recent(keys ...string) bool {
+	if !r.hasReplica() {
+		return false
+	}
+
+	now := r.clock.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		if until, ok := r.writes[key]; ok && now.Before(until) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkWritten starts the read-after-write window of keys
+// Expired keys are pruned on the way, keeping the map as small as the recent writes
+func (r *ReadRouter) MarkWritten(keys ...string) {
+	if !r.hasReplica() || r.window <= 0 {
+		return
+	}
+
+	now := r.clock.Now()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for key, until := range r.writes {
+		if !now.Before(until) {
+			delete(r.writes, key)
+		}
+	}
+	for _, key := range keys {
+		r.writes[key] = now.Add(r.window)
+	}
+}
+
+// Read-after-write keys of a user; email and username are not scoped by tenant,
+// a write in one tenant merely sends the same lookup in another tenant to the primary
+func userIDKey(id uuid.UUID) string      { return "id:" + id.String() }
+func userEmailKey(email string) string   { return "email:" + email }
+func usernameKey(username string) string { return "username:" + username }
@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/common/clock"
+	"worker/internal/core/domain"
+)
+
+// fakePool is a sqlc.DBTX standing in for one pool, answering every single-row query with the same user
+type fakePool struct {
+	user    uuid.UUID // uuid.Nil finds no user
+	queries int
+}
+
+func (p *fakePool) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	p.queries++
+	return pgconn.CommandTag{}, nil
+}
+
+func (p *fakePool) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	p.queries++
+	return nil, errors.New("fakePool answers single-row queries only")
+}
+
+func (p *fakePool) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	p.queries++
+	return fakeRow{user: p.user}
+}
+
+// fakeRow scans the user's ID, or true for an existence check, and leaves the other columns zero
+type fakeRow struct {
+	user uuid.UUID
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	if r.user == uuid.Nil {
+		return pgx.ErrNoRows
+	}
+	switch d := dest[0].(type) {
+	case *uuid.UUID:
+		*d = r.user
+	case *bool:
+		*d = true
+	}
+	return nil
+}
+
+// routedRepository is a UserRepository reading through a ReadRouter over two fake pools
+type routedRepository struct {
+	*UserRepository
+	primary, replica *fakePool
+	clock            *clock.Fake
+}
+
+func newRoutedRepository(window time.Duration) *routedRepository {
+	primary, replica := &fakePool{}, &fakePool{}
+	fake := clock.NewFake(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	router := &ReadRouter{
+		primary: newQueries(primary),
+		replica: newQueries(replica),
+		window:  window,
+		clock:   fake,
+		writes:  make(map[string]time.Time),
+	}
+	return &routedRepository{
+		UserRepository: &UserRepository{queries: router.primary, reads: router},
+		primary:        primary,
+		replica:        replica,
+		clock:          fake,
+	}
+}
+
+// read runs lookup and returns the pool it read from
+func (r *routedRepository) read(t *testing.T, lookup func() error) string {
+	t.Helper()
+	primary, replica := r.primary.queries, r.replica.queries
+	if err := lookup(); err != nil {
+		t.Fatalf("lookup: %v", err)
+	}
+	switch {
+	case r.primary.queries > primary && r.replica.queries > replica:
+		return "replica, then primary"
+	case r.primary.queries > primary:
+		return "primary"
+	case r.replica.queries > replica:
+		return "replica"
+	}
+	t.Fatal("lookup queried neither pool")
+	return ""
+}
+
+func TestReadRouterWithoutReplica(t *testing.T) {
+	router := NewReadRouter(nil, nil, 5*time.Second, clock.NewFake(time.Now()))
+	router.MarkWritten("username:alice")
+
+	if router.Queries("username:alice") != router.Primary() || router.Queries("username:bob") != router.Primary() {
+		t.Fatal("lookups without a replica do not read the primary")
+	}
+	if router.Recent("username:alice") {
+		t.Fatal("writes are tracked without a replica to lag behind")
+	}
+}
+
+func TestReadRouterWindow(t *testing.T) {
+	r := newRoutedRepository(5 * time.Second)
+	router := r.reads
+	router.MarkWritten("username:alice")
+
+	if router.Queries("username:alice") != router.Primary() {
+		t.Fatal("recently written key does not read the primary")
+	}
+	if router.Queries("username:bob", "username:alice") != router.Primary() {
+		t.Fatal("lookup by several keys ignores the recently written one")
+	}
+	if router.Queries("username:bob") == router.Primary() {
+		t.Fatal("untouched key does not read the replica")
+	}
+
+	r.clock.Advance(5 * time.Second)
+	if router.Queries("username:alice") == router.Primary() {
+		t.Fatal("key still reads the primary once its window is over")
+	}
+	// Later writes prune the expired keys
+	router.MarkWritten("username:bob")
+	if _, ok := router.writes["username:alice"]; ok || len(router.writes) != 1 {
+		t.Fatalf("tracked writes = %v, want only username:bob", router.writes)
+	}
+
+	// A zero window reads every lookup from the replica
+	r = newRoutedRepository(0)
+	r.reads.MarkWritten("username:alice")
+	if r.reads.Queries("username:alice") == r.reads.Primary() {
+		t.Fatal("write is tracked with a zero window")
+	}
+}
+
+func TestUserRepositoryReadsOwnWrites(t *testing.T) {
+	r := newRoutedRepository(5 * time.Second)
+	ctx := context.Background()
+	alice := uuid.New()
+	r.primary.user, r.replica.user = alice, alice
+
+	findByID := func() error { _, err := r.FindByID(ctx, alice); return err }
+	findByEmail := func() error { _, err := r.FindByEmail(ctx, "", "alice@example.com"); return err }
+	findByUsername := func() error { _, err := r.FindByUsername(ctx, "", "alice"); return err }
+	existsByEmail := func() error { _, err := r.ExistsByEmail(ctx, "", "alice@example.com"); return err }
+
+	for _, lookup := range []func() error{findByID, findByEmail, findByUsername, existsByEmail} {
+		if got := r.read(t, lookup); got != "replica" {
+			t.Fatalf("lookup before any write read the %s", got)
+		}
+	}
+
+	// Registering alice sends lookups by any of her keys to the primary
+	if _, err := r.CreateUser(ctx, sqlc.CreateUserParams{ID: alice, Email: "alice@example.com", Username: "alice"}); err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	for _, lookup := range []func() error{findByID, findByEmail, findByUsername, existsByEmail} {
+		if got := r.read(t, lookup); got != "primary" {
+			t.Fatalf("lookup right after registering read the %s", got)
+		}
+	}
+
+	r.clock.Advance(5 * time.Second)
+	for _, lookup := range []func() error{findByID, findByEmail, findByUsername, existsByEmail} {
+		if got := r.read(t, lookup); got != "replica" {
+			t.Fatalf("lookup after the window read the %s", got)
+		}
+	}
+}
+
+func TestUserRepositoryRereadsStaleRows(t *testing.T) {
+	r := newRoutedRepository(5 * time.Second)
+	ctx := context.Background()
+	alice := uuid.New()
+	r.primary.user, r.replica.user = alice, alice
+	if _, err := r.UpdateUser(ctx, sqlc.UpdateUserParams{ID: alice, Email: "alice@example.com", Username: "alice2"}); err != nil {
+		t.Fatalf("update user: %v", err)
+	}
+
+	// The replica still finds alice by her old username; the primary knows she renamed
+	r.primary.user = uuid.Nil
+	if got := r.read(t, func() error {
+		_, err := r.FindByUsername(ctx, "", "alice")
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			t.Fatalf("find by the replaced username = %v, want %v", err, domain.ErrUserNotFound)
+		}
+		return nil
+	}); got != "replica, then primary" {
+		t.Fatalf("stale replica row was not re-read, lookup read the %s", got)
+	}
+
+	// Rows of users not written lately are taken from the replica as they are
+	r.replica.user = uuid.New()
+	if got := r.read(t, func() error { _, err := r.FindByUsername(ctx, "", "bob"); return err }); got != "replica" {
+		t.Fatalf("lookup of bob read the %s", got)
+	}
+}
+
+func TestUserRepositoryReadsInTransaction(t *testing.T) {
+	r := newRoutedRepository(5 * time.Second)
+	tx := &fakePool{user: uuid.New()}
+	r.replica.user = tx.user
+	inTx := &UserRepository{queries: newQueries(tx), reads: r.reads, inTx: true}
+
+	if _, err := inTx.FindByUsername(context.Background(), "", "alice"); err != nil {
+		t.Fatalf("find in the transaction: %v", err)
+	}
+	if tx.queries != 1 || r.replica.queries != 0 || r.primary.queries != 0 {
+		t.Fatalf("queries = %d in the transaction, %d replica, %d primary; want the transaction only",
+			tx.queries, r.replica.queries, r.primary.queries)
+	}
+}
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"slices"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
@@ -23,7 +26,15 @@ type RoleRepository struct {
 func NewRoleRepository(pool *pgxpool.Pool) *RoleRepository {
 	return &RoleRepository{
 		pool:    pool,
-		queries: sqlc.New(pool),
+		queries: newQueries(pool),
+	}
+}
+
+// withTx returns a copy of the repository running its queries in tx
+func (r *RoleRepository) withTx(tx pgx.Tx) *RoleRepository {
+	return &RoleRepository{
+		pool:    r.pool,
+		queries: newQueries(tx),
 	}
 }
 
@@ -64,20 +75,194 @@ func (r *RoleRepository) GetDefaultRole(ctx context.Context) (*sqlc.Role, error)
 }
 
 // GetPermissionsByRoleID retrieves all permissions for a given role
-// Returns a flattened list of permission strings (e.g., "users:read", "users:write")
+// Returns a flattened, deduplicated and sorted list of permission strings (e.g., "users:read", "users:write")
+// The query returns a text column, so an unexpected type fails the scan instead of being dropped
 func (r *RoleRepository) GetPermissionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]string, error) {
-	permissions, err := r.queries.GetPermissionActionsByRoleID(ctx, roleID)
+	return sortedPermissions(r.queries.GetPermissionActionsByRoleID(ctx, roleID))
+}
+
+// GetPermissionsByRoleIDs retrieves the permissions of several roles in a single query
+// Every requested role is present in the result; roles without permissions map to an empty slice
+func (r *RoleRepository) GetPermissionsByRoleIDs(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	result := make(map[uuid.UUID][]string, len(roleIDs))
+	if len(roleIDs) == 0 {
+		return result, nil
+	}
+	for _, id := range roleIDs {
+		result[id] = []string{}
+	}
+
+	rows, err := r.queries.GetPermissionActionsByRoleIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.RoleID] = append(result[row.RoleID], row.Permission)
+	}
+
+	return result, nil
+}
+
+// FindByUserID retrieves all roles assigned to a user (primary + additional)
+func (r *RoleRepository) FindByUserID(ctx context.Context, userID uuid.UUID) ([]sqlc.Role, error) {
+	return r.queries.GetRolesByUserID(ctx, userID)
+}
+
+// GetPermissionsByUserID retrieves the union of permissions across all roles of a user
+func (r *RoleRepository) GetPermissionsByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return sortedPermissions(r.queries.GetPermissionActionsByUserID(ctx, userID))
+}
+
+// sortedPermissions sorts permissions and drops repeats, in case the query let duplicate rows through
+func sortedPermissions(permissions []string, err error) ([]string, error) {
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(permissions)
+	return slices.Compact(permissions), nil
+}
+
+// GetPermissionsByUserIDs retrieves the permissions of several users in a single query
+// Every requested user is present in the result; users without permissions map to an empty slice
+func (r *RoleRepository) GetPermissionsByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	result := make(map[uuid.UUID][]string, len(userIDs))
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+	for _, id := range userIDs {
+		result[id] = []string{}
+	}
+
+	rows, err := r.queries.GetPermissionActionsByUserIDs(ctx, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	for _, row := range rows {
+		result[row.UserID] = append(result[row.UserID], row.Permission)
+	}
+	return result, nil
+}
+
+// List retrieves every role, ordered by code
+func (r *RoleRepository) List(ctx context.Context) ([]sqlc.Role, error) {
+	return r.queries.ListRoles(ctx)
+}
+
+// GetGrantsByRoleID retrieves the permissions of a role per resource, ordered by resource code
+// Duplicate permissions rows of a resource are merged into one grant
+func (r *RoleRepository) GetGrantsByRoleID(ctx context.Context, roleID uuid.UUID) ([]domain.PermissionGrant, error) {
+	rows, err := r.queries.GetPermissionsByRoleID(ctx, roleID)
 	if err != nil {
 		return nil, err
 	}
 
-	// Convert interface{} slice to string slice
-	result := make([]string, 0, len(permissions))
-	for _, p := range permissions {
-		if str, ok := p.(string); ok {
-			result = append(result, str)
+	grants := make([]domain.PermissionGrant, 0, len(rows))
+	for _, row := range rows {
+		grant, err := toPermissionGrant(row.ResourceCode, row.ResourceName, row.Actions)
+		if err != nil {
+			return nil, err
 		}
+		grants = appendGrant(grants, grant)
 	}
+	return grants, nil
+}
 
+// ListGrants retrieves the permissions of every role per resource in a single query, keyed by role ID
+// Duplicate permissions rows of a resource are merged into one grant
+func (r *RoleRepository) ListGrants(ctx context.Context) (map[uuid.UUID][]domain.PermissionGrant, error) {
+	rows, err := r.queries.ListPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uuid.UUID][]domain.PermissionGrant)
+	for _, row := range rows {
+		grant, err := toPermissionGrant(row.ResourceCode, row.ResourceName, row.Actions)
+		if err != nil {
+			return nil, err
+		}
+		result[row.RoleID] = appendGrant(result[row.RoleID], grant)
+	}
 	return result, nil
 }
+
+// FindResourceByCode retrieves a resource by its code (e.g., "users")
+func (r *RoleRepository) FindResourceByCode(ctx context.Context, code string) (*sqlc.Resource, error) {
+	resource, err := r.queries.GetResourceByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrResourceNotFound
+		}
+		return nil, err
+	}
+	return &resource, nil
+}
+
+// GrantAction grants an action on a resource to a role in a single statement
+func (r *RoleRepository) GrantAction(ctx context.Context, roleID, resourceID uuid.UUID, action string) error {
+	return r.queries.AddPermissionAction(ctx, sqlc.AddPermissionActionParams{
+		Action:     action,
+		RoleID:     roleID,
+		ResourceID: resourceID,
+	})
+}
+
+// RevokeAction revokes an action on a resource from a role
+// A permissions row left without actions is deleted, so it does not show up as an empty grant
+func (r *RoleRepository) RevokeAction(ctx context.Context, roleID, resourceID uuid.UUID, action string) (bool, error) {
+	rows, err := r.queries.RemovePermissionAction(ctx, sqlc.RemovePermissionActionParams{
+		Action:     action,
+		RoleID:     roleID,
+		ResourceID: resourceID,
+	})
+	if err != nil || rows == 0 {
+		return false, err
+	}
+
+	err = r.queries.DeleteEmptyPermissions(ctx, sqlc.DeleteEmptyPermissionsParams{
+		RoleID:     roleID,
+		ResourceID: resourceID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// toPermissionGrant decodes the JSONB actions array of a permissions row
+func toPermissionGrant(resourceCode, resourceName string, actions []byte) (domain.PermissionGrant, error) {
+	grant := domain.PermissionGrant{
+		ResourceCode: resourceCode,
+		ResourceName: resourceName,
+		Actions:      []string{},
+	}
+	if len(actions) == 0 {
+		return grant, nil
+	}
+	var decoded []string
+	if err := json.Unmarshal(actions, &decoded); err != nil {
+		return domain.PermissionGrant{}, fmt.Errorf("decode actions of resource %s: %w", resourceCode, err)
+	}
+	grant.Actions = appendActions(grant.Actions, decoded)
+	return grant, nil
+}
+
+// appendGrant appends a grant to grants ordered by resource code, merging it into the last grant
+// when both are of the same resource, as happens when the permissions table holds duplicate rows
+func appendGrant(grants []domain.PermissionGrant, grant domain.PermissionGrant) []domain.PermissionGrant {
+	if n := len(grants); n > 0 && grants[n-1].ResourceCode == grant.ResourceCode {
+		grants[n-1].Actions = appendActions(grants[n-1].Actions, grant.Actions)
+		return grants
+	}
+	return append(grants, grant)
+}
+
+// appendActions appends the actions missing from dst, keeping their order
+func appendActions(dst, actions []string) []string {
+	for _, action := range actions {
+		if !slices.Contains(dst, action) {
+			dst = append(dst, action)
+		}
+	}
+	return dst
+}
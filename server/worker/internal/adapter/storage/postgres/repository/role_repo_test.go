@@ -0,0 +1,256 @@
+package repository
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// resultDB is a sqlc.DBTX answering every query with one result set, decoded by pgx's type map
+// as it would decode the wire values of a real connection
+type resultDB struct {
+	fields []pgconn.FieldDescription
+	rows   [][][]byte
+}
+
+func (db resultDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, nil
+}
+
+func (db resultDB) Query(ctx context.Context, sql string, args ...interface{}) (pgx.Rows, error) {
+	return &resultRows{db: db, typeMap: pgtype.NewMap(), next: -1}, nil
+}
+
+func (db resultDB) QueryRow(ctx context.Context, sql string, args ...interface{}) pgx.Row {
+	rows, _ := db.Query(ctx, sql, args...)
+	return rows.(pgx.Row)
+}
+
+// resultRows are the rows of a resultDB
+type resultRows struct {
+	db      resultDB
+	typeMap *pgtype.Map
+	next    int
+	err     error
+}
+
+func (r *resultRows) Close()                                       {}
+func (r *resultRows) Err() error                                   { return r.err }
+func (r *resultRows) CommandTag() pgconn.CommandTag                { return pgconn.CommandTag{} }
+func (r *resultRows) FieldDescriptions() []pgconn.FieldDescription { return r.db.fields }
+func (r *resultRows) RawValues() [][]byte                          { return r.db.rows[r.next] }
+func (r *resultRows) Conn() *pgx.Conn                              { return nil }
+
+func (r *resultRows) Next() bool {
+	if r.err != nil || r.next+1 >= len(r.db.rows) {
+		return false
+	}
+	r.next++
+	return true
+}
+
+func (r *resultRows) Scan(dest ...any) error {
+	for i, field := range r.db.fields {
+		if err := r.typeMap.Scan(field.DataTypeOID, field.Format, r.db.rows[r.next][i], dest[i]); err != nil {
+			r.err = err
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *resultRows) Values() ([]any, error) {
+	return nil, nil
+}
+
+// textColumn, uuidColumn and byteaColumn describe result columns in the format Postgres sends them
+func textColumn(name string) pgconn.FieldDescription {
+	return pgconn.FieldDescription{Name: name, DataTypeOID: pgtype.TextOID, Format: pgtype.TextFormatCode}
+}
+
+func uuidColumn(name string) pgconn.FieldDescription {
+	return pgconn.FieldDescription{Name: name, DataTypeOID: pgtype.UUIDOID, Format: pgtype.BinaryFormatCode}
+}
+
+func byteaColumn(name string) pgconn.FieldDescription {
+	return pgconn.FieldDescription{Name: name, DataTypeOID: pgtype.ByteaOID, Format: pgtype.BinaryFormatCode}
+}
+
+func TestGetPermissionsByRoleIDDecodesText(t *testing.T) {
+	roles := &RoleRepository{queries: newQueries(resultDB{
+		fields: []pgconn.FieldDescription{textColumn("permission")},
+		rows:   [][][]byte{{[]byte("roles:READ")}, {[]byte("users:UPDATE")}},
+	})}
+
+	got, err := roles.GetPermissionsByRoleID(context.Background(), uuid.New())
+	if err != nil {
+		t.Fatalf("get permissions: %v", err)
+	}
+	if want := []string{"roles:READ", "users:UPDATE"}; !slices.Equal(got, want) {
+		t.Fatalf("permissions = %v, want %v", got, want)
+	}
+}
+
+func TestGetPermissionsByRoleIDRejectsNonText(t *testing.T) {
+	// A permission that does not decode as text must fail the query, not come back as no permissions
+	tests := []struct {
+		name string
+		db   resultDB
+	}{
+		{name: "bytea column", db: resultDB{
+			fields: []pgconn.FieldDescription{byteaColumn("permission")},
+			rows:   [][][]byte{{[]byte("users:READ")}},
+		}},
+		{name: "null permission", db: resultDB{
+			fields: []pgconn.FieldDescription{textColumn("permission")},
+			rows:   [][][]byte{{[]byte("roles:READ")}, {nil}},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			roles := &RoleRepository{queries: newQueries(tt.db)}
+			if got, err := roles.GetPermissionsByRoleID(context.Background(), uuid.New()); err == nil {
+				t.Fatalf("permissions = %#v, want a scan error", got)
+			}
+		})
+	}
+}
+
+func TestGetPermissionsByRoleIDsDecodesText(t *testing.T) {
+	admin, student := uuid.New(), uuid.New()
+	roles := &RoleRepository{queries: newQueries(resultDB{
+		fields: []pgconn.FieldDescription{uuidColumn("role_id"), textColumn("permission")},
+		rows: [][][]byte{
+			{admin[:], []byte("roles:READ")},
+			{admin[:], []byte("users:READ")},
+		},
+	})}
+
+	got, err := roles.GetPermissionsByRoleIDs(context.Background(), []uuid.UUID{admin, student})
+	if err != nil {
+		t.Fatalf("get permissions: %v", err)
+	}
+	if want := []string{"roles:READ", "users:READ"}; !slices.Equal(got[admin], want) {
+		t.Fatalf("admin permissions = %v, want %v", got[admin], want)
+	}
+	if permissions, ok := got[student]; !ok || permissions == nil || len(permissions) != 0 {
+		t.Fatalf("student permissions = %#v, want an empty list", permissions)
+	}
+
+	// A mixed result with one undecodable permission fails as a whole
+	roles.queries = newQueries(resultDB{
+		fields: []pgconn.FieldDescription{uuidColumn("role_id"), textColumn("permission")},
+		rows:   [][][]byte{{admin[:], []byte("roles:READ")}, {student[:], nil}},
+	})
+	if got, err := roles.GetPermissionsByRoleIDs(context.Background(), []uuid.UUID{admin}); err == nil {
+		t.Fatalf("permissions = %v, want a scan error", got)
+	}
+}
+
+// jsonbColumn describes a jsonb result column in text format
+func jsonbColumn(name string) pgconn.FieldDescription {
+	return pgconn.FieldDescription{Name: name, DataTypeOID: pgtype.JSONBOID, Format: pgtype.TextFormatCode}
+}
+
+// permissionRows returns a result of one text permission per row
+func permissionRows(permissions ...string) resultDB {
+	db := resultDB{fields: []pgconn.FieldDescription{textColumn("permission")}}
+	for _, permission := range permissions {
+		db.rows = append(db.rows, [][]byte{[]byte(permission)})
+	}
+	return db
+}
+
+func TestGetPermissionsDeduplicatesRows(t *testing.T) {
+	db := permissionRows("users:READ", "roles:READ", "users:READ", "roles:UPDATE", "roles:READ")
+	want := []string{"roles:READ", "roles:UPDATE", "users:READ"}
+	roles := &RoleRepository{queries: newQueries(db)}
+	ctx := context.Background()
+
+	byRole, err := roles.GetPermissionsByRoleID(ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("get permissions by role: %v", err)
+	}
+	if !slices.Equal(byRole, want) {
+		t.Fatalf("permissions by role = %v, want %v", byRole, want)
+	}
+
+	byUser, err := roles.GetPermissionsByUserID(ctx, uuid.New())
+	if err != nil {
+		t.Fatalf("get permissions by user: %v", err)
+	}
+	if !slices.Equal(byUser, want) {
+		t.Fatalf("permissions by user = %v, want %v", byUser, want)
+	}
+}
+
+func TestGetGrantsByRoleIDMergesDuplicateRows(t *testing.T) {
+	roleID := uuid.New()
+	row := func(code, actions string) [][]byte {
+		id, resourceID := uuid.New(), uuid.New()
+		return [][]byte{id[:], roleID[:], resourceID[:], []byte(actions), []byte(code), []byte(code)}
+	}
+	roles := &RoleRepository{queries: newQueries(resultDB{
+		fields: []pgconn.FieldDescription{
+			uuidColumn("id"), uuidColumn("role_id"), uuidColumn("resource_id"),
+			jsonbColumn("actions"), textColumn("resource_code"), textColumn("resource_name"),
+		},
+		// Ordered by resource code, with one resource repeated and an action repeated within a row
+		rows: [][][]byte{
+			row("roles", `["READ"]`),
+			row("users", `["READ", "UPDATE"]`),
+			row("users", `["UPDATE", "DELETE", "DELETE"]`),
+		},
+	})}
+
+	grants, err := roles.GetGrantsByRoleID(context.Background(), roleID)
+	if err != nil {
+		t.Fatalf("get grants: %v", err)
+	}
+	want := []struct {
+		code    string
+		actions []string
+	}{
+		{"roles", []string{"READ"}},
+		{"users", []string{"READ", "UPDATE", "DELETE"}},
+	}
+	if len(grants) != len(want) {
+		t.Fatalf("grants = %+v, want one per resource", grants)
+	}
+	for i, grant := range grants {
+		if grant.ResourceCode != want[i].code || !slices.Equal(grant.Actions, want[i].actions) {
+			t.Fatalf("grant %d = %+v, want %s %v", i, grant, want[i].code, want[i].actions)
+		}
+	}
+}
+
+func TestListGrantsMergesDuplicateRows(t *testing.T) {
+	admin, student := uuid.New(), uuid.New()
+	roles := &RoleRepository{queries: newQueries(resultDB{
+		fields: []pgconn.FieldDescription{
+			uuidColumn("role_id"), jsonbColumn("actions"), textColumn("resource_code"), textColumn("resource_name"),
+		},
+		rows: [][][]byte{
+			{admin[:], []byte(`["READ"]`), []byte("users"), []byte("Users")},
+			{admin[:], []byte(`["READ", "UPDATE"]`), []byte("users"), []byte("Users")},
+			{student[:], []byte(`["READ"]`), []byte("users"), []byte("Users")},
+		},
+	})}
+
+	grants, err := roles.ListGrants(context.Background())
+	if err != nil {
+		t.Fatalf("list grants: %v", err)
+	}
+	if got := grants[admin]; len(got) != 1 || !slices.Equal(got[0].Actions, []string{"READ", "UPDATE"}) {
+		t.Fatalf("admin grants = %+v, want users READ and UPDATE once", got)
+	}
+	// The same resource under another role is a grant of its own
+	if got := grants[student]; len(got) != 1 || !slices.Equal(got[0].Actions, []string{"READ"}) {
+		t.Fatalf("student grants = %+v, want users READ", got)
+	}
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"os"
+	"regexp"
+	"testing"
+)
+
+// foreignKeyAction matches the foreign keys drizzle adds, capturing the constraint name and its ON DELETE action
+var foreignKeyAction = regexp.MustCompile(`ADD CONSTRAINT "(\w+)" FOREIGN KEY .* ON DELETE (set null|cascade|no action|restrict)`)
+
+// TestUserForeignKeyActions checks the ON DELETE action each table referencing users ends up with once every
+// migration has run: erasing a user must not be blocked by rows without a cascading foreign key
+func TestUserForeignKeyActions(t *testing.T) {
+	schema, err := os.ReadFile("../schema/schema.sql")
+	if err != nil {
+		t.Fatalf("read schema: %v", err)
+	}
+	// Migrations recreate constraints to change them, so the last definition wins
+	actions := map[string]string{}
+	for _, match := range foreignKeyAction.FindAllSubmatch(schema, -1) {
+		actions[string(match[1])] = string(match[2])
+	}
+
+	want := map[string]string{
+		// Coursework of the user goes with them
+		"enrollments_student_id_users_id_fk": "cascade",
+		"submissions_user_id_users_id_fk":    "cascade",
+		// Classes and problems they created are kept without them
+		"classes_lecturer_id_users_id_fk": "set null",
+		"problems_created_by_users_id_fk": "set null",
+		// Derived from the user
+		"access_tokens_user_id_users_id_fk":        "cascade",
+		"password_reset_codes_user_id_users_id_fk": "cascade",
+		// Deleted by the repository before the user
+		"sessions_user_id_users_id_fk":   "no action",
+		"user_roles_user_id_users_id_fk": "no action",
+	}
+	for constraint, action := range want {
+		if got, ok := actions[constraint]; !ok || got != action {
+			t.Errorf("%s ON DELETE = %q, want %q", constraint, got, action)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+)
+
+// SessionRepository implements ports.SessionRepository using sqlc generated queries
+type SessionRepository struct {
+	pool    *pgxpool.Pool
+	queries *sqlc.Queries
+}
+
+// NewSessionRepository creates a new SessionRepository instance
+func NewSessionRepository(pool *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{
+		pool:    pool,
+		queries: newQueries(pool),
+	}
+}
+
+// withTx returns a copy of the repository running its queries in tx
+func (r *SessionRepository) withTx(tx pgx.Tx) *SessionRepository {
+	return &SessionRepository{
+		pool:    r.pool,
+		queries: newQueries(tx),
+	}
+}
+
+// Create creates a new session
+func (r *SessionRepository) Create(ctx context.Context, params sqlc.CreateSessionParams) (*sqlc.Session, error) {
+	session, err := r.queries.CreateSession(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+// FindByID retrieves a session by its UUID
+func (r *SessionRepository) FindByID(ctx context.Context, id uuid.UUID) (*sqlc.Session, error) {
+	session, err := r.queries.GetSessionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrSessionNotFound
+		}
+		return nil, err
+	}
+	return &session, nil
+}
+
+// RotateNonce swaps the refresh nonce if currentNonce is still the active one and marks the session as seen at seenAt
+// Returns false if the nonce was already rotated or the session was revoked
+func (r *SessionRepository) RotateNonce(ctx context.Context, id uuid.UUID, currentNonce, newNonce string, expiresAt, seenAt time.Time) (bool, error) {
+	rows, err := r.queries.RotateSessionNonce(ctx, sqlc.RotateSessionNonceParams{
+		ID:           id,
+		CurrentNonce: currentNonce,
+		NewNonce:     newNonce,
+		ExpiresAt:    pgtype.Timestamp{Time: expiresAt, Valid: true},
+		SeenAt:       pgtype.Timestamp{Time: seenAt, Valid: true},
+	})
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+// MarkSeen marks an active session as seen at seenAt, unless it was last seen after staleBefore
+// Sessions last seen before idleBefore stay idle unless it is zero
+func (r *SessionRepository) MarkSeen(ctx context.Context, id uuid.UUID, seenAt, staleBefore, idleBefore time.Time) error {
+	_, err := r.queries.MarkSessionSeen(ctx, sqlc.MarkSessionSeenParams{
+		ID:          id,
+		SeenAt:      pgtype.Timestamp{Time: seenAt, Valid: true},
+		StaleBefore: staleBefore,
+		IdleBefore:  pgtype.Timestamp{Time: idleBefore, Valid: !idleBefore.IsZero()},
+	})
+	return err
+}
+
+// Revoke revokes a session
+func (r *SessionRepository) Revoke(ctx context.Context, id uuid.UUID) error {
+	return r.queries.RevokeSession(ctx, id)
+}
+
+// RevokeAllForUser revokes every active session of a user
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.queries.RevokeUserSessions(ctx, userID)
+}
+
+// ListActiveIDsForUser returns the IDs of a user's sessions that can still refresh at now, oldest first
+func (r *SessionRepository) ListActiveIDsForUser(ctx context.Context, userID uuid.UUID, now, idleBefore time.Time) ([]uuid.UUID, error) {
+	return r.queries.ListActiveUserSessionIDs(ctx, sqlc.ListActiveUserSessionIDsParams{
+		UserID:     userID,
+		Now:        pgtype.Timestamp{Time: now, Valid: true},
+		IdleBefore: pgtype.Timestamp{Time: idleBefore, Valid: !idleBefore.IsZero()},
+	})
+}
+
+// DeleteAllForUser deletes every session of a user, revoked or not
+func (r *SessionRepository) DeleteAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.queries.DeleteUserSessions(ctx, userID)
+}
+
+// DeleteExpired deletes up to limit sessions that expired before the given time, or were last seen before
+// idleBefore unless it is zero, and returns how many
+func (r *SessionRepository) DeleteExpired(ctx context.Context, before, idleBefore time.Time, limit int32) (int64, error) {
+	return r.queries.DeleteExpiredSessions(ctx, sqlc.DeleteExpiredSessionsParams{
+		ExpiredBefore: pgtype.Timestamp{Time: before, Valid: true},
+		IdleBefore:    pgtype.Timestamp{Time: idleBefore, Valid: !idleBefore.IsZero()},
+		MaxSessions:   limit,
+	})
+}
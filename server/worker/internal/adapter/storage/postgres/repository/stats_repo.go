@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+)
+
+// StatsRepository implements ports.ReadOnlyQuerier using sqlc generated queries
+// Every query runs in a READ ONLY transaction, so PostgreSQL rejects writes even if a query were changed to make one
+type StatsRepository struct {
+	pool *pgxpool.Pool
+}
+
+// NewStatsRepository creates a new StatsRepository instance
+func NewStatsRepository(pool *pgxpool.Pool) *StatsRepository {
+	return &StatsRepository{pool: pool}
+}
+
+// CountUsersByRole counts the tenant's users per primary role
+func (r *StatsRepository) CountUsersByRole(ctx context.Context, tenantID string) ([]sqlc.CountUsersByRoleRow, error) {
+	var rows []sqlc.CountUsersByRoleRow
+	err := r.readOnly(ctx, func(q *sqlc.Queries) error {
+		var err error
+		rows, err = q.CountUsersByRole(ctx, tenantID)
+		return err
+	})
+	return rows, err
+}
+
+// CountRegistrationsPerDay counts the tenant's registrations per day since the given time
+func (r *StatsRepository) CountRegistrationsPerDay(ctx context.Context, tenantID string, since time.Time) ([]sqlc.CountRegistrationsPerDayRow, error) {
+	var rows []sqlc.CountRegistrationsPerDayRow
+	err := r.readOnly(ctx, func(q *sqlc.Queries) error {
+		var err error
+		rows, err = q.CountRegistrationsPerDay(ctx, sqlc.CountRegistrationsPerDayParams{
+			TenantID: tenantID,
+			Since:    since,
+		})
+		return err
+	})
+	return rows, err
+}
+
+// readOnly runs fn in a READ ONLY transaction that is always rolled back
+func (r *StatsRepository) readOnly(ctx context.Context, fn func(*sqlc.Queries) error) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return classifyError(err)
+	}
+	defer tx.Rollback(ctx) // Nothing to commit
+
+	return fn(newQueries(tx))
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"worker/internal/core/ports"
+)
+
+// Ensure UnitOfWork implements ports.UnitOfWork
+var _ ports.UnitOfWork = (*UnitOfWork)(nil)
+
+// UnitOfWork implements ports.UnitOfWork with one pgx transaction per call
+type UnitOfWork struct {
+	pool     *pgxpool.Pool
+	users    *UserRepository
+	roles    *RoleRepository
+	sessions *SessionRepository
+}
+
+// NewUnitOfWork creates a new UnitOfWork instance
+func NewUnitOfWork(pool *pgxpool.Pool, reads *ReadRouter) *UnitOfWork {
+	return &UnitOfWork{
+		pool:     pool,
+		users:    NewUserRepository(pool, reads),
+		roles:    NewRoleRepository(pool),
+		sessions: NewSessionRepository(pool),
+	}
+}
+
+// Do runs fn in a transaction, committing when fn returns nil and rolling back otherwise
+func (u *UnitOfWork) Do(ctx context.Context, fn func(repos ports.Repositories) error) error {
+	tx, err := u.pool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", classifyError(err))
+	}
+	defer tx.Rollback(ctx) // No-op once committed
+
+	if err := fn(ports.Repositories{
+		Users:    u.users.withTx(tx),
+		Roles:    u.roles.withTx(tx),
+		Sessions: u.sessions.withTx(tx),
+	}); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("commit transaction: %w", classifyError(err))
+	}
+	return nil
+}
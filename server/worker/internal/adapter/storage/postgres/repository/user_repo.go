@@ -3,9 +3,13 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/jackc/pgx/v5/pgxpool"
 
 	"worker/internal/adapter/storage/postgres/sqlc"
@@ -14,22 +18,52 @@ import (
 
 // UserRepository implements ports.UserRepository using sqlc generated queries
 // Returns sqlc types directly - no mapping needed
+// Lookups (FindBy*, Exists*) go through the ReadRouter, everything else to the primary
 type UserRepository struct {
 	pool    *pgxpool.Pool
 	queries *sqlc.Queries
+	reads   *ReadRouter
+	inTx    bool // Lookups read in the transaction too, the router only records writes
 }
 
 // NewUserRepository creates a new UserRepository instance
-func NewUserRepository(pool *pgxpool.Pool) *UserRepository {
+func NewUserRepository(pool *pgxpool.Pool, reads *ReadRouter) *UserRepository {
 	return &UserRepository{
 		pool:    pool,
-		queries: sqlc.New(pool),
+		queries: newQueries(pool),
+		reads:   reads,
 	}
 }
 
+// withTx returns a copy of the repository running its queries in tx
+func (r *UserRepository) withTx(tx pgx.Tx) *UserRepository {
+	return &UserRepository{
+		pool:    r.pool,
+		queries: newQueries(tx),
+		reads:   r.reads,
+		inTx:    true,
+	}
+}
+
+// lookupQueries returns the queries a lookup of keys reads with, and whether they read the replica
+func (r *UserRepository) lookupQueries(keys ...string) (*sqlc.Queries, bool) {
+	if r.inTx {
+		return r.queries, false
+	}
+	q := r.reads.Queries(keys...)
+	return q, q != r.reads.Primary()
+}
+
+// staleRead reports whether a row read from the replica belongs to a recently written user
+// A lookup by a just-replaced email or username misses the key check and is caught here by ID
+func (r *UserRepository) staleRead(fromReplica bool, id uuid.UUID) bool {
+	return fromReplica && r.reads.Recent(userIDKey(id))
+}
+
 // FindByID retrieves a user by their UUID (includes role info)
 func (r *UserRepository) FindByID(ctx context.Context, id uuid.UUID) (*sqlc.GetUserByIDRow, error) {
-	row, err := r.queries.GetUserByID(ctx, id)
+	q, _ := r.lookupQueries(userIDKey(id))
+	row, err := q.GetUserByID(ctx, id)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -39,9 +73,17 @@ func (r *UserRepository) FindByID(ctx context.Context, id uuid.UUID) (*sqlc.GetU
 	return &row, nil
 }
 
-// FindByEmail retrieves a user by their email address (includes role info)
-func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*sqlc.GetUserByEmailRow, error) {
-	row, err := r.queries.GetUserByEmail(ctx, email)
+// FindByEmail retrieves a user by their email address within a tenant (includes role info)
+func (r *UserRepository) FindByEmail(ctx context.Context, tenantID, email string) (*sqlc.GetUserByEmailRow, error) {
+	params := sqlc.GetUserByEmailParams{
+		Email:    email,
+		TenantID: tenantID,
+	}
+	q, fromReplica := r.lookupQueries(userEmailKey(email))
+	row, err := q.GetUserByEmail(ctx, params)
+	if err == nil && r.staleRead(fromReplica, row.ID) {
+		row, err = r.queries.GetUserByEmail(ctx, params)
+	}
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -51,9 +93,17 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*sqlc.G
 	return &row, nil
 }
 
-// FindByUsername retrieves a user by their username (includes role info)
-func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*sqlc.GetUserByUsernameRow, error) {
-	row, err := r.queries.GetUserByUsername(ctx, username)
+// FindByUsername retrieves a user by their username within a tenant (includes role info)
+func (r *UserRepository) FindByUsername(ctx context.Context, tenantID, username string) (*sqlc.GetUserByUsernameRow, error) {
+	params := sqlc.GetUserByUsernameParams{
+		Username: username,
+		TenantID: tenantID,
+	}
+	q, fromReplica := r.lookupQueries(usernameKey(username))
+	row, err := q.GetUserByUsername(ctx, params)
+	if err == nil && r.staleRead(fromReplica, row.ID) {
+		row, err = r.queries.GetUserByUsername(ctx, params)
+	}
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -63,9 +113,17 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 	return &row, nil
 }
 
-// FindByEmailOrUsername retrieves a user by email or username (includes role info)
-func (r *UserRepository) FindByEmailOrUsername(ctx context.Context, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error) {
-	row, err := r.queries.GetUserByEmailOrUsername(ctx, identifier)
+// FindByEmailOrUsername retrieves a user by email or username within a tenant (includes role info)
+func (r *UserRepository) FindByEmailOrUsername(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error) {
+	params := sqlc.GetUserByEmailOrUsernameParams{
+		Email:    identifier,
+		TenantID: tenantID,
+	}
+	q, fromReplica := r.lookupQueries(userEmailKey(identifier), usernameKey(identifier))
+	row, err := q.GetUserByEmailOrUsername(ctx, params)
+	if err == nil && r.staleRead(fromReplica, row.ID) {
+		row, err = r.queries.GetUserByEmailOrUsername(ctx, params)
+	}
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, domain.ErrUserNotFound
@@ -75,20 +133,181 @@ func (r *UserRepository) FindByEmailOrUsername(ctx context.Context, identifier s
 	return &row, nil
 }
 
-// ExistsByEmail checks if a user with the given email exists
-func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	return r.queries.ExistsByEmail(ctx, email)
+// FindByUsernameIgnoringCase retrieves a user by their username, in any letter case, within a tenant (includes role info)
+func (r *UserRepository) FindByUsernameIgnoringCase(ctx context.Context, tenantID, username string) (*sqlc.GetUserByUsernameRow, error) {
+	params := sqlc.GetUserByUsernameIgnoringCaseParams{
+		Username: username,
+		TenantID: tenantID,
+	}
+	q, fromReplica := r.lookupQueries(usernameKey(strings.ToLower(username)))
+	row, err := q.GetUserByUsernameIgnoringCase(ctx, params)
+	if err == nil && r.staleRead(fromReplica, row.ID) {
+		row, err = r.queries.GetUserByUsernameIgnoringCase(ctx, params)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	found := sqlc.GetUserByUsernameRow(row)
+	return &found, nil
+}
+
+// FindByEmailOrUsernameIgnoringCase retrieves a user by email, or by username in any letter case,
+// within a tenant (includes role info)
+func (r *UserRepository) FindByEmailOrUsernameIgnoringCase(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error) {
+	params := sqlc.GetUserByEmailOrUsernameIgnoringCaseParams{
+		Email:    identifier,
+		TenantID: tenantID,
+	}
+	q, fromReplica := r.lookupQueries(userEmailKey(identifier), usernameKey(strings.ToLower(identifier)))
+	row, err := q.GetUserByEmailOrUsernameIgnoringCase(ctx, params)
+	if err == nil && r.staleRead(fromReplica, row.ID) {
+		row, err = r.queries.GetUserByEmailOrUsernameIgnoringCase(ctx, params)
+	}
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, domain.ErrUserNotFound
+		}
+		return nil, err
+	}
+	found := sqlc.GetUserByEmailOrUsernameRow(row)
+	return &found, nil
+}
+
+// ExistsByEmail checks if a user with the given email exists within a tenant
+func (r *UserRepository) ExistsByEmail(ctx context.Context, tenantID, email string) (bool, error) {
+	q, _ := r.lookupQueries(userEmailKey(email))
+	return q.ExistsByEmail(ctx, sqlc.ExistsByEmailParams{
+		Email:    email,
+		TenantID: tenantID,
+	})
+}
+
+// ExistsByUsername checks if a user with the given username exists within a tenant
+func (r *UserRepository) ExistsByUsername(ctx context.Context, tenantID, username string) (bool, error) {
+	q, _ := r.lookupQueries(usernameKey(username))
+	return q.ExistsByUsername(ctx, sqlc.ExistsByUsernameParams{
+		Username: username,
+		TenantID: tenantID,
+	})
 }
 
-// ExistsByUsername checks if a user with the given username exists
-func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
-	return r.queries.ExistsByUsername(ctx, username)
+// ExistsByUsernameIgnoringCase checks if a user with the given username, in any letter case, exists within a tenant
+func (r *UserRepository) ExistsByUsernameIgnoringCase(ctx context.Context, tenantID, username string) (bool, error) {
+	q, _ := r.lookupQueries(usernameKey(strings.ToLower(username)))
+	return q.ExistsByUsernameIgnoringCase(ctx, sqlc.ExistsByUsernameIgnoringCaseParams{
+		Username: username,
+		TenantID: tenantID,
+	})
+}
+
+// ExistingEmails returns which of the given emails belong to a user of the tenant
+func (r *UserRepository) ExistingEmails(ctx context.Context, tenantID string, emails []string) ([]string, error) {
+	keys := make([]string, len(emails))
+	for i, email := range emails {
+		keys[i] = userEmailKey(email)
+	}
+	q, _ := r.lookupQueries(keys...)
+	return q.ExistingEmails(ctx, sqlc.ExistingEmailsParams{
+		TenantID: tenantID,
+		Emails:   emails,
+	})
+}
+
+// ExistingUsernames returns which of the given usernames belong to a user of the tenant
+func (r *UserRepository) ExistingUsernames(ctx context.Context, tenantID string, usernames []string) ([]string, error) {
+	keys := make([]string, len(usernames))
+	for i, username := range usernames {
+		keys[i] = usernameKey(username)
+	}
+	q, _ := r.lookupQueries(keys...)
+	return q.ExistingUsernames(ctx, sqlc.ExistingUsernamesParams{
+		TenantID:  tenantID,
+		Usernames: usernames,
+	})
+}
+
+// ExistingUsernamesIgnoringCase returns which of the given lowercase usernames belong, in any letter case,
+// to a user of the tenant
+func (r *UserRepository) ExistingUsernamesIgnoringCase(ctx context.Context, tenantID string, usernames []string) ([]string, error) {
+	keys := make([]string, len(usernames))
+	for i, username := range usernames {
+		keys[i] = usernameKey(username)
+	}
+	q, _ := r.lookupQueries(keys...)
+	return q.ExistingUsernamesIgnoringCase(ctx, sqlc.ExistingUsernamesIgnoringCaseParams{
+		TenantID:  tenantID,
+		Usernames: usernames,
+	})
+}
+
+// ListUsers returns one page of a tenant's users in (created_at, id) order, after params' keyset position
+func (r *UserRepository) ListUsers(ctx context.Context, params sqlc.ListUsersParams) ([]sqlc.ListUsersRow, error) {
+	return r.queries.ListUsers(ctx, params)
+}
+
+// ListUsersDesc returns one page of a tenant's users in descending (created_at, id) order, before params' keyset position
+func (r *UserRepository) ListUsersDesc(ctx context.Context, params sqlc.ListUsersDescParams) ([]sqlc.ListUsersRow, error) {
+	rows, err := r.queries.ListUsersDesc(ctx, params)
+	page := make([]sqlc.ListUsersRow, len(rows))
+	for i, row := range rows {
+		page[i] = sqlc.ListUsersRow(row)
+	}
+	return page, err
+}
+
+// ListUsersByLastLogin returns one page of a tenant's users in (last_login, id) order, after params' keyset position
+func (r *UserRepository) ListUsersByLastLogin(ctx context.Context, params sqlc.ListUsersByLastLoginParams) ([]sqlc.ListUsersRow, error) {
+	rows, err := r.queries.ListUsersByLastLogin(ctx, params)
+	page := make([]sqlc.ListUsersRow, len(rows))
+	for i, row := range rows {
+		page[i] = sqlc.ListUsersRow(row)
+	}
+	return page, err
+}
+
+// ListUsersByLastLoginDesc returns one page of a tenant's users in descending (last_login, id) order, before params' keyset position
+func (r *UserRepository) ListUsersByLastLoginDesc(ctx context.Context, params sqlc.ListUsersByLastLoginDescParams) ([]sqlc.ListUsersRow, error) {
+	rows, err := r.queries.ListUsersByLastLoginDesc(ctx, params)
+	page := make([]sqlc.ListUsersRow, len(rows))
+	for i, row := range rows {
+		page[i] = sqlc.ListUsersRow(row)
+	}
+	return page, err
+}
+
+// ListUsersByUsername returns one page of a tenant's users in bytewise (username, id) order, after params' keyset position
+func (r *UserRepository) ListUsersByUsername(ctx context.Context, params sqlc.ListUsersByUsernameParams) ([]sqlc.ListUsersRow, error) {
+	rows, err := r.queries.ListUsersByUsername(ctx, params)
+	page := make([]sqlc.ListUsersRow, len(rows))
+	for i, row := range rows {
+		page[i] = sqlc.ListUsersRow(row)
+	}
+	return page, err
+}
+
+// ListUsersByUsernameDesc returns one page of a tenant's users in descending bytewise (username, id) order, before params' keyset position
+func (r *UserRepository) ListUsersByUsernameDesc(ctx context.Context, params sqlc.ListUsersByUsernameDescParams) ([]sqlc.ListUsersRow, error) {
+	rows, err := r.queries.ListUsersByUsernameDesc(ctx, params)
+	page := make([]sqlc.ListUsersRow, len(rows))
+	for i, row := range rows {
+		page[i] = sqlc.ListUsersRow(row)
+	}
+	return page, err
 }
 
 // CreateUser creates a new user in the database
+// Unique violations are mapped to domain.ErrEmailAlreadyExists / ErrUsernameAlreadyExists
 func (r *UserRepository) CreateUser(ctx context.Context, params sqlc.CreateUserParams) (*sqlc.User, error) {
+	r.reads.MarkWritten(userIDKey(params.ID), userEmailKey(params.Email), usernameKey(params.Username))
 	created, err := r.queries.CreateUser(ctx, params)
 	if err != nil {
+		// Concurrent registrations can both pass the existence checks
+		if constraint, ok := uniqueViolation(err); ok {
+			return nil, userConflict(constraint)
+		}
 		return nil, err
 	}
 	return &created, nil
@@ -96,6 +315,7 @@ func (r *UserRepository) CreateUser(ctx context.Context, params sqlc.CreateUserP
 
 // UpdateUser updates an existing user
 func (r *UserRepository) UpdateUser(ctx context.Context, params sqlc.UpdateUserParams) (*sqlc.User, error) {
+	r.reads.MarkWritten(userIDKey(params.ID), userEmailKey(params.Email), usernameKey(params.Username))
 	updated, err := r.queries.UpdateUser(ctx, params)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
@@ -106,7 +326,268 @@ func (r *UserRepository) UpdateUser(ctx context.Context, params sqlc.UpdateUserP
 	return &updated, nil
 }
 
-// UpdateLastLogin updates the last login timestamp for a user
-func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID uuid.UUID) error {
-	return r.queries.UpdateLastLogin(ctx, userID)
+// UpdateLastLogins stores a batch of last logins in a single statement
+// Logins older than the stored one are skipped, so retrying a batch is safe
+func (r *UserRepository) UpdateLastLogins(ctx context.Context, logins []domain.LastLogin) error {
+	params := sqlc.UpdateLastLoginsParams{
+		UserIds:    make([]uuid.UUID, len(logins)),
+		LoggedInAt: make([]time.Time, len(logins)),
+		Ips:        make([]string, len(logins)),
+		UserAgents: make([]string, len(logins)),
+	}
+	for i, login := range logins {
+		params.UserIds[i] = login.UserID
+		params.LoggedInAt[i] = login.At
+		params.Ips[i] = login.Client.IP
+		params.UserAgents[i] = login.Client.UserAgent
+	}
+	return r.queries.UpdateLastLogins(ctx, params)
+}
+
+// UpdateAvatar updates the avatar URL of a user
+func (r *UserRepository) UpdateAvatar(ctx context.Context, userID uuid.UUID, avatarURL string) error {
+	r.reads.MarkWritten(userIDKey(userID))
+	return r.queries.UpdateUserAvatar(ctx, sqlc.UpdateUserAvatarParams{
+		ID:     userID,
+		Avatar: &avatarURL,
+	})
+}
+
+// UpdateEmail changes the email of a user
+// Returns domain.ErrEmailAlreadyExists when the email is taken, domain.ErrUserNotFound for an unknown user
+func (r *UserRepository) UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error {
+	r.reads.MarkWritten(userIDKey(userID), userEmailKey(email))
+	affected, err := r.queries.UpdateUserEmail(ctx, sqlc.UpdateUserEmailParams{
+		Email: email,
+		ID:    userID,
+	})
+	if err != nil {
+		// A concurrent change or registration can take the email after any availability check
+		if constraint, ok := uniqueViolation(err); ok && constraintOnColumn(constraint, "users", "email") {
+			return domain.ErrEmailAlreadyExists
+		}
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// UpdatePassword replaces the password hash of a user, sets password_changed_at and lifts a forced reset
+func (r *UserRepository) UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error {
+	r.reads.MarkWritten(userIDKey(userID))
+	return r.queries.UpdateUserPassword(ctx, sqlc.UpdateUserPasswordParams{
+		ID:       userID,
+		Password: passwordHash,
+	})
+}
+
+// RehashPassword replaces a password hash with newHash unless the password changed since oldHash was read
+func (r *UserRepository) RehashPassword(ctx context.Context, userID uuid.UUID, oldHash, newHash string) (bool, error) {
+	r.reads.MarkWritten(userIDKey(userID))
+	affected, err := r.queries.RehashUserPassword(ctx, sqlc.RehashUserPasswordParams{
+		NewHash: newHash,
+		ID:      userID,
+		OldHash: oldHash,
+	})
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// UpdateUsername renames a user and sets username_changed_at, unless the previous rename is within cooldown
+// Returns false when the cooldown blocked the rename, domain.ErrUsernameAlreadyExists when the name is taken
+func (r *UserRepository) UpdateUsername(ctx context.Context, userID uuid.UUID, username string, cooldown time.Duration) (bool, error) {
+	r.reads.MarkWritten(userIDKey(userID), usernameKey(username))
+	affected, err := r.queries.UpdateUsername(ctx, sqlc.UpdateUsernameParams{
+		Username: username,
+		ID:       userID,
+		Cooldown: pgtype.Interval{Microseconds: cooldown.Microseconds(), Valid: true},
+	})
+	if err != nil {
+		// A concurrent rename or registration can take the name after the availability check
+		if constraint, ok := uniqueViolation(err); ok && constraintOnColumn(constraint, "users", "username") {
+			return false, domain.ErrUsernameAlreadyExists
+		}
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// GetTokenVersion returns the user's current token version
+func (r *UserRepository) GetTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error) {
+	version, err := r.queries.GetTokenVersion(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrUserNotFound
+		}
+		return 0, err
+	}
+	return version, nil
+}
+
+// GetTokenSubjects returns the email and token version of several users
+// Like GetTokenVersion it reads the primary, so a revocation is never missed on a lagging replica
+func (r *UserRepository) GetTokenSubjects(ctx context.Context, userIDs []uuid.UUID) ([]sqlc.GetTokenSubjectsByIDsRow, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+	return r.queries.GetTokenSubjectsByIDs(ctx, userIDs)
+}
+
+// IncrementTokenVersion bumps the user's token version and returns the new one
+func (r *UserRepository) IncrementTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error) {
+	r.reads.MarkWritten(userIDKey(userID))
+	version, err := r.queries.IncrementTokenVersion(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrUserNotFound
+		}
+		return 0, err
+	}
+	return version, nil
+}
+
+// SetActive activates or deactivates a user
+// Returns domain.ErrUserNotFound if the user does not exist
+func (r *UserRepository) SetActive(ctx context.Context, userID uuid.UUID, active bool) error {
+	r.reads.MarkWritten(userIDKey(userID))
+	affected, err := r.queries.UpdateUserActive(ctx, sqlc.UpdateUserActiveParams{
+		IsActive: &active,
+		ID:       userID,
+	})
+	if err != nil {
+		return err
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// RequirePasswordReset refuses logins until the user resets their password
+// and bumps the token version, returning the new one
+func (r *UserRepository) RequirePasswordReset(ctx context.Context, userID uuid.UUID) (int32, error) {
+	r.reads.MarkWritten(userIDKey(userID))
+	version, err := r.queries.RequirePasswordReset(ctx, userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, domain.ErrUserNotFound
+		}
+		return 0, err
+	}
+	return version, nil
+}
+
+// Delete permanently removes a user and their additional roles
+// Run it in a unit of work so both deletes commit together; sessions reference the user and must go first
+func (r *UserRepository) Delete(ctx context.Context, userID uuid.UUID) error {
+	r.reads.MarkWritten(userIDKey(userID))
+	if err := r.queries.DeleteUserRoles(ctx, userID); err != nil {
+		return err
+	}
+	affected, err := r.queries.DeleteUser(ctx, userID)
+	if err != nil {
+		return deleteUserError(err)
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// ScheduleDeletion schedules the erasure of a user unless one is already scheduled,
+// returning the schedule in effect
+func (r *UserRepository) ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) (time.Time, error) {
+	r.reads.MarkWritten(userIDKey(userID))
+	scheduled, err := r.queries.ScheduleUserDeletion(ctx, sqlc.ScheduleUserDeletionParams{
+		ID:         userID,
+		DeletionAt: pgtype.Timestamp{Time: at, Valid: true},
+	})
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return time.Time{}, domain.ErrUserNotFound
+		}
+		return time.Time{}, err
+	}
+	return scheduled.Time, nil
+}
+
+// CancelDeletion cancels the scheduled erasure of a user
+func (r *UserRepository) CancelDeletion(ctx context.Context, userID uuid.UUID) (bool, error) {
+	r.reads.MarkWritten(userIDKey(userID))
+	affected, err := r.queries.CancelUserDeletion(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// ListDueForDeletion returns up to limit users whose scheduled erasure is due, oldest schedule first
+func (r *UserRepository) ListDueForDeletion(ctx context.Context, dueBy time.Time, limit int32) ([]sqlc.ListUsersDueForDeletionRow, error) {
+	return r.queries.ListUsersDueForDeletion(ctx, sqlc.ListUsersDueForDeletionParams{
+		DueBy:    pgtype.Timestamp{Time: dueBy, Valid: true},
+		MaxUsers: limit,
+	})
+}
+
+// DeleteIfDue is Delete guarded by the user's deletion schedule
+// The roles deleted first come back on rollback, so run it in a unit of work
+func (r *UserRepository) DeleteIfDue(ctx context.Context, userID uuid.UUID, dueBy time.Time) error {
+	r.reads.MarkWritten(userIDKey(userID))
+	if err := r.queries.DeleteUserRoles(ctx, userID); err != nil {
+		return err
+	}
+	affected, err := r.queries.DeleteUserIfDue(ctx, sqlc.DeleteUserIfDueParams{
+		ID:    userID,
+		DueBy: pgtype.Timestamp{Time: dueBy, Valid: true},
+	})
+	if err != nil {
+		return deleteUserError(err)
+	}
+	if affected == 0 {
+		return domain.ErrUserNotFound
+	}
+	return nil
+}
+
+// deleteUserError reports rows still referencing the user, such as coursework, as domain.ErrUserReferenced
+func deleteUserError(err error) error {
+	if isForeignKeyViolation(err) {
+		return fmt.Errorf("%w: %w", domain.ErrUserReferenced, err)
+	}
+	return err
+}
+
+// AddRole assigns an additional role to a user (idempotent)
+func (r *UserRepository) AddRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	r.reads.MarkWritten(userIDKey(userID))
+	return r.queries.AddUserRole(ctx, sqlc.AddUserRoleParams{
+		UserID: userID,
+		RoleID: roleID,
+	})
+}
+
+// RemoveRole removes an additional role from a user
+func (r *UserRepository) RemoveRole(ctx context.Context, userID, roleID uuid.UUID) (bool, error) {
+	r.reads.MarkWritten(userIDKey(userID))
+	affected, err := r.queries.RemoveUserRole(ctx, sqlc.RemoveUserRoleParams{
+		UserID: userID,
+		RoleID: roleID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return affected > 0, nil
+}
+
+// SetPrimaryRole changes the primary role stored on the user row
+func (r *UserRepository) SetPrimaryRole(ctx context.Context, userID, roleID uuid.UUID) error {
+	r.reads.MarkWritten(userIDKey(userID))
+	return r.queries.UpdateUserPrimaryRole(ctx, sqlc.UpdateUserPrimaryRoleParams{
+		ID:     userID,
+		RoleID: roleID,
+	})
 }
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"worker/internal/common/clock"
+	"worker/internal/core/domain"
+)
+
+// execDB is a sqlc.DBTX answering every statement with tag, or failing it with err
+type execDB struct {
+	resultDB
+	tag pgconn.CommandTag
+	err error
+}
+
+func (db execDB) Exec(ctx context.Context, sql string, args ...interface{}) (pgconn.CommandTag, error) {
+	return db.tag, db.err
+}
+
+func TestUserRepositoryUpdateEmail(t *testing.T) {
+	tests := []struct {
+		name string
+		db   execDB
+		want error
+	}{
+		{name: "updated", db: execDB{tag: pgconn.NewCommandTag("UPDATE 1")}},
+		{name: "unknown user", db: execDB{tag: pgconn.NewCommandTag("UPDATE 0")}, want: domain.ErrUserNotFound},
+		// The unique constraint settles a collision, even one with a change committed concurrently
+		{name: "email taken", db: execDB{err: &pgconn.PgError{
+			Code: pgUniqueViolation, ConstraintName: "users_tenant_email_unique",
+		}}, want: domain.ErrEmailAlreadyExists},
+		{name: "email taken, default constraint name", db: execDB{err: &pgconn.PgError{
+			Code: pgUniqueViolation, ConstraintName: "users_email_key",
+		}}, want: domain.ErrEmailAlreadyExists},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users := &UserRepository{
+				queries: newQueries(tt.db),
+				reads:   NewReadRouter(nil, nil, 0, clock.NewFake(time.Now())),
+			}
+			if err := users.UpdateEmail(context.Background(), uuid.New(), "alice@example.com"); !errors.Is(err, tt.want) {
+				t.Fatalf("update email = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	// Other failures, including other unique violations, are passed on as they are
+	violation := &pgconn.PgError{Code: pgUniqueViolation, ConstraintName: "users_tenant_username_unique"}
+	users := &UserRepository{
+		queries: newQueries(execDB{err: violation}),
+		reads:   NewReadRouter(nil, nil, 0, clock.NewFake(time.Now())),
+	}
+	if err := users.UpdateEmail(context.Background(), uuid.New(), "alice@example.com"); !errors.Is(err, violation) ||
+		errors.Is(err, domain.ErrEmailAlreadyExists) {
+		t.Fatalf("update email = %v, want the violation passed on", err)
+	}
+}
@@ -1,54 +1,232 @@
 -- =============================================
--- AUTO-SYNCED FROM GATEWAY (Drizzle Migrations)
+-- AUTO-GENERATED FROM GATEWAY MIGRATIONS
 -- DO NOT EDIT THIS FILE DIRECTLY!
--- Run: make sync-schema to update
+-- Source: gateway/drizzle/*.sql
 -- =============================================
 
--- Roles table
-CREATE TABLE IF NOT EXISTS roles (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    name VARCHAR(50) NOT NULL UNIQUE,
-    code VARCHAR(20) NOT NULL UNIQUE,
-    description TEXT,
-    created_at TIMESTAMP DEFAULT NOW()
+-- From: 0000_crazy_spencer_smythe.sql
+CREATE TABLE "users" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"email" varchar(255) NOT NULL,
+	"username" varchar(50) NOT NULL,
+	"password" text NOT NULL,
+	"full_name" text,
+	"role" varchar(20) DEFAULT 'USER',
+	"created_at" timestamp DEFAULT now(),
+	"updated_at" timestamp DEFAULT now(),
+	CONSTRAINT "users_email_unique" UNIQUE("email"),
+	CONSTRAINT "users_username_unique" UNIQUE("username")
 );
 
--- Users table
-CREATE TABLE IF NOT EXISTS users (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    role_id UUID NOT NULL REFERENCES roles(id),
-    email VARCHAR(255) NOT NULL UNIQUE,
-    username VARCHAR(50) NOT NULL UNIQUE,
-    password TEXT NOT NULL,
-    full_name TEXT NOT NULL,
-    phone VARCHAR(20),
-    avatar TEXT,
-    is_active BOOLEAN DEFAULT TRUE,
-    last_login TIMESTAMP,
-    created_at TIMESTAMP DEFAULT NOW(),
-    updated_at TIMESTAMP DEFAULT NOW()
+-- From: 0001_lazy_human_torch.sql
+CREATE TABLE "assignment_problems" (
+	"assignment_id" uuid NOT NULL,
+	"problem_id" uuid NOT NULL,
+	"points" integer DEFAULT 10,
+	CONSTRAINT "assignment_problems_assignment_id_problem_id_pk" PRIMARY KEY("assignment_id","problem_id")
 );
-
--- Resources table
-CREATE TABLE IF NOT EXISTS resources (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    name VARCHAR(100) NOT NULL,
-    code VARCHAR(50) NOT NULL UNIQUE,
-    api_uri VARCHAR(200),
-    description TEXT
+--> statement-breakpoint
+CREATE TABLE "assignments" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"class_id" uuid NOT NULL,
+	"name" varchar(200) NOT NULL,
+	"start_time" timestamp,
+	"end_time" timestamp,
+	"is_open" boolean DEFAULT false,
+	"created_at" timestamp DEFAULT now()
 );
-
--- Permissions table
-CREATE TABLE IF NOT EXISTS permissions (
-    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
-    role_id UUID NOT NULL REFERENCES roles(id),
-    resource_id UUID NOT NULL REFERENCES resources(id),
-    actions JSONB DEFAULT '[]',
-    created_at TIMESTAMP DEFAULT NOW()
+--> statement-breakpoint
+CREATE TABLE "classes" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"code" varchar(50) NOT NULL,
+	"name" varchar(200) NOT NULL,
+	"semester" varchar(20),
+	"lecturer_id" uuid,
+	"is_active" boolean DEFAULT true,
+	"created_at" timestamp DEFAULT now(),
+	CONSTRAINT "classes_code_unique" UNIQUE("code")
 );
-
--- Indexes
-CREATE INDEX IF NOT EXISTS idx_users_email ON users(email);
-CREATE INDEX IF NOT EXISTS idx_users_username ON users(username);
-CREATE INDEX IF NOT EXISTS idx_users_role_id ON users(role_id);
-CREATE INDEX IF NOT EXISTS idx_permissions_role_id ON permissions(role_id);
+--> statement-breakpoint
+CREATE TABLE "enrollments" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"class_id" uuid NOT NULL,
+	"student_id" uuid NOT NULL,
+	"joined_at" timestamp DEFAULT now()
+);
+--> statement-breakpoint
+CREATE TABLE "permissions" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"role_id" uuid NOT NULL,
+	"resource_id" uuid NOT NULL,
+	"actions" jsonb DEFAULT '[]'::jsonb,
+	"created_at" timestamp DEFAULT now()
+);
+--> statement-breakpoint
+CREATE TABLE "problems" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"topic_id" uuid,
+	"title" text NOT NULL,
+	"description" text NOT NULL,
+	"difficulty" varchar(20) DEFAULT 'EASY',
+	"init_schema_sql" text NOT NULL,
+	"correct_query" text NOT NULL,
+	"created_by" uuid,
+	"created_at" timestamp DEFAULT now()
+);
+--> statement-breakpoint
+CREATE TABLE "resources" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" varchar(100) NOT NULL,
+	"code" varchar(50) NOT NULL,
+	"api_uri" varchar(200),
+	"description" text,
+	CONSTRAINT "resources_code_unique" UNIQUE("code")
+);
+--> statement-breakpoint
+CREATE TABLE "roles" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" varchar(50) NOT NULL,
+	"code" varchar(20) NOT NULL,
+	"description" text,
+	"created_at" timestamp DEFAULT now(),
+	CONSTRAINT "roles_name_unique" UNIQUE("name"),
+	CONSTRAINT "roles_code_unique" UNIQUE("code")
+);
+--> statement-breakpoint
+CREATE TABLE "submissions" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"user_id" uuid NOT NULL,
+	"problem_id" uuid NOT NULL,
+	"assignment_id" uuid,
+	"code" text NOT NULL,
+	"status" varchar(20) DEFAULT 'PENDING',
+	"score" integer DEFAULT 0,
+	"execution_time" integer,
+	"error_log" text,
+	"submitted_at" timestamp DEFAULT now()
+);
+--> statement-breakpoint
+CREATE TABLE "topics" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"name" varchar(100) NOT NULL,
+	"slug" varchar(100),
+	"description" text,
+	"icon_url" text,
+	"created_at" timestamp DEFAULT now(),
+	CONSTRAINT "topics_slug_unique" UNIQUE("slug")
+);
+--> statement-breakpoint
+ALTER TABLE "users" ALTER COLUMN "full_name" SET NOT NULL;--> statement-breakpoint
+ALTER TABLE "users" ADD COLUMN "role_id" uuid NOT NULL;--> statement-breakpoint
+ALTER TABLE "users" ADD COLUMN "phone" varchar(20);--> statement-breakpoint
+ALTER TABLE "users" ADD COLUMN "avatar" text;--> statement-breakpoint
+ALTER TABLE "users" ADD COLUMN "is_active" boolean DEFAULT true;--> statement-breakpoint
+ALTER TABLE "users" ADD COLUMN "last_login" timestamp;--> statement-breakpoint
+ALTER TABLE "assignment_problems" ADD CONSTRAINT "assignment_problems_assignment_id_assignments_id_fk" FOREIGN KEY ("assignment_id") REFERENCES "public"."assignments"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "assignment_problems" ADD CONSTRAINT "assignment_problems_problem_id_problems_id_fk" FOREIGN KEY ("problem_id") REFERENCES "public"."problems"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "assignments" ADD CONSTRAINT "assignments_class_id_classes_id_fk" FOREIGN KEY ("class_id") REFERENCES "public"."classes"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "classes" ADD CONSTRAINT "classes_lecturer_id_users_id_fk" FOREIGN KEY ("lecturer_id") REFERENCES "public"."users"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "enrollments" ADD CONSTRAINT "enrollments_class_id_classes_id_fk" FOREIGN KEY ("class_id") REFERENCES "public"."classes"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "enrollments" ADD CONSTRAINT "enrollments_student_id_users_id_fk" FOREIGN KEY ("student_id") REFERENCES "public"."users"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "permissions" ADD CONSTRAINT "permissions_role_id_roles_id_fk" FOREIGN KEY ("role_id") REFERENCES "public"."roles"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "permissions" ADD CONSTRAINT "permissions_resource_id_resources_id_fk" FOREIGN KEY ("resource_id") REFERENCES "public"."resources"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "problems" ADD CONSTRAINT "problems_topic_id_topics_id_fk" FOREIGN KEY ("topic_id") REFERENCES "public"."topics"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "problems" ADD CONSTRAINT "problems_created_by_users_id_fk" FOREIGN KEY ("created_by") REFERENCES "public"."users"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "submissions" ADD CONSTRAINT "submissions_user_id_users_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."users"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "submissions" ADD CONSTRAINT "submissions_problem_id_problems_id_fk" FOREIGN KEY ("problem_id") REFERENCES "public"."problems"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "submissions" ADD CONSTRAINT "submissions_assignment_id_assignments_id_fk" FOREIGN KEY ("assignment_id") REFERENCES "public"."assignments"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "users" ADD CONSTRAINT "users_role_id_roles_id_fk" FOREIGN KEY ("role_id") REFERENCES "public"."roles"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "users" DROP COLUMN "role";
+-- From: 0002_brainy_wolverine.sql
+CREATE TABLE "user_roles" (
+	"user_id" uuid NOT NULL,
+	"role_id" uuid NOT NULL,
+	"created_at" timestamp DEFAULT now(),
+	CONSTRAINT "user_roles_user_id_role_id_pk" PRIMARY KEY("user_id","role_id")
+);
+--> statement-breakpoint
+ALTER TABLE "user_roles" ADD CONSTRAINT "user_roles_user_id_users_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."users"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "user_roles" ADD CONSTRAINT "user_roles_role_id_roles_id_fk" FOREIGN KEY ("role_id") REFERENCES "public"."roles"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "idx_user_roles_role_id" ON "user_roles" USING btree ("role_id");
+-- From: 0003_quiet_sentinel.sql
+CREATE TABLE "sessions" (
+	"id" uuid PRIMARY KEY DEFAULT gen_random_uuid() NOT NULL,
+	"user_id" uuid NOT NULL,
+	"refresh_nonce" varchar(64) NOT NULL,
+	"expires_at" timestamp NOT NULL,
+	"revoked_at" timestamp,
+	"created_at" timestamp DEFAULT now(),
+	"updated_at" timestamp DEFAULT now()
+);
+--> statement-breakpoint
+ALTER TABLE "sessions" ADD CONSTRAINT "sessions_user_id_users_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."users"("id") ON DELETE no action ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "idx_sessions_user_id" ON "sessions" USING btree ("user_id");
+-- From: 0004_swift_beast.sql
+ALTER TABLE "users" ADD COLUMN "password_changed_at" timestamp DEFAULT now();
+-- From: 0005_fancy_nightcrawler.sql
+ALTER TABLE "users" DROP CONSTRAINT "users_email_unique";--> statement-breakpoint
+ALTER TABLE "users" DROP CONSTRAINT "users_username_unique";--> statement-breakpoint
+ALTER TABLE "users" ADD COLUMN "tenant_id" varchar(64) DEFAULT 'default' NOT NULL;--> statement-breakpoint
+ALTER TABLE "users" ADD CONSTRAINT "users_tenant_email_unique" UNIQUE("tenant_id","email");--> statement-breakpoint
+ALTER TABLE "users" ADD CONSTRAINT "users_tenant_username_unique" UNIQUE("tenant_id","username");
+-- From: 0006_gentle_karma.sql
+ALTER TABLE "users" ADD COLUMN "username_changed_at" timestamp;
+-- From: 0007_rapid_havok.sql
+ALTER TABLE "users" ADD COLUMN "token_version" integer DEFAULT 0 NOT NULL;
+-- From: 0008_calm_forge.sql
+CREATE INDEX "idx_users_tenant_created_id" ON "users" USING btree ("tenant_id","created_at","id");
+-- From: 0009_wise_banshee.sql
+ALTER TABLE "users" ADD COLUMN "last_login_ip" varchar(45);--> statement-breakpoint
+ALTER TABLE "users" ADD COLUMN "last_login_user_agent" text;
+-- From: 0010_bold_cyclops.sql
+ALTER TABLE "users" ADD COLUMN "must_reset_password" boolean DEFAULT false NOT NULL;
+-- From: 0011_sharp_polaris.sql
+ALTER TABLE "users" ADD COLUMN "locale" varchar(35);
+-- From: 0012_smooth_mimic.sql
+ALTER TABLE "classes" DROP CONSTRAINT "classes_lecturer_id_users_id_fk";
+--> statement-breakpoint
+ALTER TABLE "classes" ADD CONSTRAINT "classes_lecturer_id_users_id_fk" FOREIGN KEY ("lecturer_id") REFERENCES "public"."users"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "enrollments" DROP CONSTRAINT "enrollments_student_id_users_id_fk";
+--> statement-breakpoint
+ALTER TABLE "enrollments" ADD CONSTRAINT "enrollments_student_id_users_id_fk" FOREIGN KEY ("student_id") REFERENCES "public"."users"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "problems" DROP CONSTRAINT "problems_created_by_users_id_fk";
+--> statement-breakpoint
+ALTER TABLE "problems" ADD CONSTRAINT "problems_created_by_users_id_fk" FOREIGN KEY ("created_by") REFERENCES "public"."users"("id") ON DELETE set null ON UPDATE no action;--> statement-breakpoint
+ALTER TABLE "submissions" DROP CONSTRAINT "submissions_user_id_users_id_fk";
+--> statement-breakpoint
+ALTER TABLE "submissions" ADD CONSTRAINT "submissions_user_id_users_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."users"("id") ON DELETE cascade ON UPDATE no action;
+-- From: 0013_lucky_gambit.sql
+CREATE TABLE "access_tokens" (
+	"token_hash" varchar(64) PRIMARY KEY NOT NULL,
+	"user_id" uuid NOT NULL,
+	"claims" jsonb NOT NULL,
+	"expires_at" timestamp NOT NULL,
+	"created_at" timestamp DEFAULT now()
+);
+--> statement-breakpoint
+ALTER TABLE "access_tokens" ADD CONSTRAINT "access_tokens_user_id_users_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."users"("id") ON DELETE cascade ON UPDATE no action;--> statement-breakpoint
+CREATE INDEX "idx_access_tokens_user_id" ON "access_tokens" USING btree ("user_id");
+-- From: 0014_steady_warpath.sql
+ALTER TABLE "users" ADD COLUMN "scheduled_deletion_at" timestamp;--> statement-breakpoint
+CREATE INDEX "idx_users_scheduled_deletion_at" ON "users" USING btree ("scheduled_deletion_at") WHERE "users"."scheduled_deletion_at" IS NOT NULL;
+-- From: 0015_tidy_sunfire.sql
+CREATE INDEX "idx_sessions_expires_at" ON "sessions" USING btree ("expires_at");--> statement-breakpoint
+CREATE INDEX "idx_access_tokens_expires_at" ON "access_tokens" USING btree ("expires_at");
+-- From: 0016_clever_longshot.sql
+CREATE TABLE "password_reset_codes" (
+	"user_id" uuid PRIMARY KEY NOT NULL,
+	"code_hash" varchar(64) NOT NULL,
+	"attempts" integer DEFAULT 0 NOT NULL,
+	"expires_at" timestamp NOT NULL,
+	"created_at" timestamp DEFAULT now()
+);
+--> statement-breakpoint
+ALTER TABLE "password_reset_codes" ADD CONSTRAINT "password_reset_codes_user_id_users_id_fk" FOREIGN KEY ("user_id") REFERENCES "public"."users"("id") ON DELETE cascade ON UPDATE no action;
+-- From: 0017_nimble_dazzler.sql
+CREATE INDEX "idx_users_tenant_lower_username" ON "users" USING btree ("tenant_id",lower("username"));
+-- From: 0018_sleepy_hellcat.sql
+ALTER TABLE "sessions" ADD COLUMN "last_seen_at" timestamp DEFAULT now();--> statement-breakpoint
+CREATE INDEX "idx_sessions_last_seen_at" ON "sessions" USING btree ("last_seen_at");
+-- From: 0019_brave_quasar.sql
+CREATE INDEX "idx_users_tenant_last_login_id" ON "users" USING btree ("tenant_id",COALESCE("last_login", 'epoch'::timestamp),"id");--> statement-breakpoint
+CREATE INDEX "idx_users_tenant_username_id" ON "users" USING btree ("tenant_id","username" COLLATE "C","id");
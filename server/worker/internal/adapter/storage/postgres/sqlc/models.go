@@ -9,6 +9,55 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type AccessToken struct {
+	TokenHash string           `db:"token_hash" json:"token_hash"`
+	UserID    uuid.UUID        `db:"user_id" json:"user_id"`
+	Claims    []byte           `db:"claims" json:"claims"`
+	ExpiresAt pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
+type Assignment struct {
+	ID        uuid.UUID        `db:"id" json:"id"`
+	ClassID   uuid.UUID        `db:"class_id" json:"class_id"`
+	Name      string           `db:"name" json:"name"`
+	StartTime pgtype.Timestamp `db:"start_time" json:"start_time"`
+	EndTime   pgtype.Timestamp `db:"end_time" json:"end_time"`
+	IsOpen    *bool            `db:"is_open" json:"is_open"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
+type AssignmentProblem struct {
+	AssignmentID uuid.UUID `db:"assignment_id" json:"assignment_id"`
+	ProblemID    uuid.UUID `db:"problem_id" json:"problem_id"`
+	Points       *int32    `db:"points" json:"points"`
+}
+
+type Class struct {
+	ID         uuid.UUID        `db:"id" json:"id"`
+	Code       string           `db:"code" json:"code"`
+	Name       string           `db:"name" json:"name"`
+	Semester   *string          `db:"semester" json:"semester"`
+	LecturerID pgtype.UUID      `db:"lecturer_id" json:"lecturer_id"`
+	IsActive   *bool            `db:"is_active" json:"is_active"`
+	CreatedAt  pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
+type Enrollment struct {
+	ID        uuid.UUID        `db:"id" json:"id"`
+	ClassID   uuid.UUID        `db:"class_id" json:"class_id"`
+	StudentID uuid.UUID        `db:"student_id" json:"student_id"`
+	JoinedAt  pgtype.Timestamp `db:"joined_at" json:"joined_at"`
+}
+
+type PasswordResetCode struct {
+	UserID    uuid.UUID        `db:"user_id" json:"user_id"`
+	CodeHash  string           `db:"code_hash" json:"code_hash"`
+	Attempts  int32            `db:"attempts" json:"attempts"`
+	ExpiresAt pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
 type Permission struct {
 	ID         uuid.UUID        `db:"id" json:"id"`
 	RoleID     uuid.UUID        `db:"role_id" json:"role_id"`
@@ -17,6 +66,18 @@ type Permission struct {
 	CreatedAt  pgtype.Timestamp `db:"created_at" json:"created_at"`
 }
 
+type Problem struct {
+	ID            uuid.UUID        `db:"id" json:"id"`
+	TopicID       pgtype.UUID      `db:"topic_id" json:"topic_id"`
+	Title         string           `db:"title" json:"title"`
+	Description   string           `db:"description" json:"description"`
+	Difficulty    *string          `db:"difficulty" json:"difficulty"`
+	InitSchemaSql string           `db:"init_schema_sql" json:"init_schema_sql"`
+	CorrectQuery  string           `db:"correct_query" json:"correct_query"`
+	CreatedBy     pgtype.UUID      `db:"created_by" json:"created_by"`
+	CreatedAt     pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
 type Resource struct {
 	ID          uuid.UUID `db:"id" json:"id"`
 	Name        string    `db:"name" json:"name"`
@@ -33,17 +94,65 @@ type Role struct {
 	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
 }
 
+type Session struct {
+	ID           uuid.UUID        `db:"id" json:"id"`
+	UserID       uuid.UUID        `db:"user_id" json:"user_id"`
+	RefreshNonce string           `db:"refresh_nonce" json:"refresh_nonce"`
+	ExpiresAt    pgtype.Timestamp `db:"expires_at" json:"expires_at"`
+	RevokedAt    pgtype.Timestamp `db:"revoked_at" json:"revoked_at"`
+	CreatedAt    pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	LastSeenAt   pgtype.Timestamp `db:"last_seen_at" json:"last_seen_at"`
+}
+
+type Submission struct {
+	ID            uuid.UUID        `db:"id" json:"id"`
+	UserID        uuid.UUID        `db:"user_id" json:"user_id"`
+	ProblemID     uuid.UUID        `db:"problem_id" json:"problem_id"`
+	AssignmentID  pgtype.UUID      `db:"assignment_id" json:"assignment_id"`
+	Code          string           `db:"code" json:"code"`
+	Status        *string          `db:"status" json:"status"`
+	Score         *int32           `db:"score" json:"score"`
+	ExecutionTime *int32           `db:"execution_time" json:"execution_time"`
+	ErrorLog      *string          `db:"error_log" json:"error_log"`
+	SubmittedAt   pgtype.Timestamp `db:"submitted_at" json:"submitted_at"`
+}
+
+type Topic struct {
+	ID          uuid.UUID        `db:"id" json:"id"`
+	Name        string           `db:"name" json:"name"`
+	Slug        *string          `db:"slug" json:"slug"`
+	Description *string          `db:"description" json:"description"`
+	IconUrl     *string          `db:"icon_url" json:"icon_url"`
+	CreatedAt   pgtype.Timestamp `db:"created_at" json:"created_at"`
+}
+
 type User struct {
-	ID        uuid.UUID        `db:"id" json:"id"`
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+}
+
+type UserRole struct {
+	UserID    uuid.UUID        `db:"user_id" json:"user_id"`
 	RoleID    uuid.UUID        `db:"role_id" json:"role_id"`
-	Email     string           `db:"email" json:"email"`
-	Username  string           `db:"username" json:"username"`
-	Password  string           `db:"password" json:"password"`
-	FullName  string           `db:"full_name" json:"full_name"`
-	Phone     *string          `db:"phone" json:"phone"`
-	Avatar    *string          `db:"avatar" json:"avatar"`
-	IsActive  *bool            `db:"is_active" json:"is_active"`
-	LastLogin pgtype.Timestamp `db:"last_login" json:"last_login"`
 	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
 }
@@ -11,25 +11,70 @@ import (
 	"github.com/google/uuid"
 )
 
+const addPermissionAction = `-- name: AddPermissionAction :exec
+WITH updated AS (
+    UPDATE permissions
+    SET actions = CASE
+        WHEN COALESCE(actions, '[]') ? $1::text THEN actions
+        ELSE COALESCE(actions, '[]') || jsonb_build_array($1::text)
+    END
+    WHERE role_id = $2 AND resource_id = $3
+    RETURNING id
+)
+INSERT INTO permissions (role_id, resource_id, actions)
+SELECT $2, $3, jsonb_build_array($1::text)
+WHERE NOT EXISTS (SELECT 1 FROM updated)
+`
+
+type AddPermissionActionParams struct {
+	Action     string    `db:"action" json:"action"`
+	RoleID     uuid.UUID `db:"role_id" json:"role_id"`
+	ResourceID uuid.UUID `db:"resource_id" json:"resource_id"`
+}
+
+// Grants an action on a resource to a role, creating the role's permissions row for the resource if needed
+// Granting an action the role already holds changes nothing
+func (q *Queries) AddPermissionAction(ctx context.Context, arg AddPermissionActionParams) error {
+	_, err := q.db.Exec(ctx, addPermissionAction, arg.Action, arg.RoleID, arg.ResourceID)
+	return err
+}
+
+const deleteEmptyPermissions = `-- name: DeleteEmptyPermissions :exec
+DELETE FROM permissions
+WHERE role_id = $1 AND resource_id = $2 AND (actions IS NULL OR actions = '[]'::jsonb)
+`
+
+type DeleteEmptyPermissionsParams struct {
+	RoleID     uuid.UUID `db:"role_id" json:"role_id"`
+	ResourceID uuid.UUID `db:"resource_id" json:"resource_id"`
+}
+
+// Deletes the role's permissions rows for a resource that no longer grant any action
+func (q *Queries) DeleteEmptyPermissions(ctx context.Context, arg DeleteEmptyPermissionsParams) error {
+	_, err := q.db.Exec(ctx, deleteEmptyPermissions, arg.RoleID, arg.ResourceID)
+	return err
+}
+
 const getPermissionActionsByRoleID = `-- name: GetPermissionActionsByRoleID :many
 SELECT DISTINCT
-    r.code || ':' || action AS permission
+    (r.code || ':' || action)::text AS permission
 FROM permissions p
 JOIN resources r ON p.resource_id = r.id,
 LATERAL jsonb_array_elements_text(p.actions) AS action
 WHERE p.role_id = $1
+ORDER BY permission
 `
 
-// Retrieves flattened permission actions for a role (e.g., "users:read", "users:write")
-func (q *Queries) GetPermissionActionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]interface{}, error) {
+// Retrieves flattened permission actions for a role (e.g., "users:read", "users:write"), deduplicated and sorted
+func (q *Queries) GetPermissionActionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]string, error) {
 	rows, err := q.db.Query(ctx, getPermissionActionsByRoleID, roleID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	items := []interface{}{}
+	items := []string{}
 	for rows.Next() {
-		var permission interface{}
+		var permission string
 		if err := rows.Scan(&permission); err != nil {
 			return nil, err
 		}
@@ -41,6 +86,125 @@ func (q *Queries) GetPermissionActionsByRoleID(ctx context.Context, roleID uuid.
 	return items, nil
 }
 
+const getPermissionActionsByRoleIDs = `-- name: GetPermissionActionsByRoleIDs :many
+SELECT DISTINCT
+    p.role_id,
+    (r.code || ':' || action)::text AS permission
+FROM permissions p
+JOIN resources r ON p.resource_id = r.id,
+LATERAL jsonb_array_elements_text(p.actions) AS action
+WHERE p.role_id = ANY($1::uuid[])
+ORDER BY p.role_id, permission
+`
+
+type GetPermissionActionsByRoleIDsRow struct {
+	RoleID     uuid.UUID `db:"role_id" json:"role_id"`
+	Permission string    `db:"permission" json:"permission"`
+}
+
+// Retrieves flattened permission actions for several roles at once, one row per role and permission
+func (q *Queries) GetPermissionActionsByRoleIDs(ctx context.Context, roleIds []uuid.UUID) ([]GetPermissionActionsByRoleIDsRow, error) {
+	rows, err := q.db.Query(ctx, getPermissionActionsByRoleIDs, roleIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetPermissionActionsByRoleIDsRow{}
+	for rows.Next() {
+		var i GetPermissionActionsByRoleIDsRow
+		if err := rows.Scan(
+			&i.RoleID,
+			&i.Permission,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPermissionActionsByUserID = `-- name: GetPermissionActionsByUserID :many
+SELECT DISTINCT
+    (r.code || ':' || action)::text AS permission
+FROM permissions p
+JOIN resources r ON p.resource_id = r.id,
+LATERAL jsonb_array_elements_text(p.actions) AS action
+WHERE p.role_id IN (
+    SELECT ur.role_id FROM user_roles ur WHERE ur.user_id = $1
+    UNION
+    SELECT u.role_id FROM users u WHERE u.id = $1
+)
+ORDER BY permission
+`
+
+// Retrieves flattened permission actions across all roles of a user, deduplicated and sorted
+func (q *Queries) GetPermissionActionsByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, getPermissionActionsByUserID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var permission string
+		if err := rows.Scan(&permission); err != nil {
+			return nil, err
+		}
+		items = append(items, permission)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPermissionActionsByUserIDs = `-- name: GetPermissionActionsByUserIDs :many
+SELECT DISTINCT
+    ur.user_id,
+    (r.code || ':' || action)::text AS permission
+FROM (
+    SELECT user_roles.user_id, user_roles.role_id FROM user_roles WHERE user_roles.user_id = ANY($1::uuid[])
+    UNION
+    SELECT users.id, users.role_id FROM users WHERE users.id = ANY($1::uuid[])
+) ur
+JOIN permissions p ON p.role_id = ur.role_id
+JOIN resources r ON p.resource_id = r.id,
+LATERAL jsonb_array_elements_text(p.actions) AS action
+ORDER BY ur.user_id, permission
+`
+
+type GetPermissionActionsByUserIDsRow struct {
+	UserID     uuid.UUID `db:"user_id" json:"user_id"`
+	Permission string    `db:"permission" json:"permission"`
+}
+
+// Retrieves flattened permission actions across all roles of several users at once, one row per user and permission
+func (q *Queries) GetPermissionActionsByUserIDs(ctx context.Context, userIds []uuid.UUID) ([]GetPermissionActionsByUserIDsRow, error) {
+	rows, err := q.db.Query(ctx, getPermissionActionsByUserIDs, userIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetPermissionActionsByUserIDsRow{}
+	for rows.Next() {
+		var i GetPermissionActionsByUserIDsRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.Permission,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getPermissionsByRoleID = `-- name: GetPermissionsByRoleID :many
 
 SELECT 
@@ -53,6 +217,7 @@ SELECT
 FROM permissions p
 JOIN resources r ON p.resource_id = r.id
 WHERE p.role_id = $1
+ORDER BY r.code
 `
 
 type GetPermissionsByRoleIDRow struct {
@@ -67,7 +232,7 @@ type GetPermissionsByRoleIDRow struct {
 // =============================================
 // Permission Queries
 // =============================================
-// Retrieves all permissions for a given role
+// Retrieves all permissions for a given role, ordered by resource code
 func (q *Queries) GetPermissionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]GetPermissionsByRoleIDRow, error) {
 	rows, err := q.db.Query(ctx, getPermissionsByRoleID, roleID)
 	if err != nil {
@@ -94,3 +259,88 @@ func (q *Queries) GetPermissionsByRoleID(ctx context.Context, roleID uuid.UUID)
 	}
 	return items, nil
 }
+
+const getResourceByCode = `-- name: GetResourceByCode :one
+SELECT id, name, code, api_uri, description FROM resources WHERE code = $1
+`
+
+// Retrieves a resource by its code (e.g., "users")
+func (q *Queries) GetResourceByCode(ctx context.Context, code string) (Resource, error) {
+	row := q.db.QueryRow(ctx, getResourceByCode, code)
+	var i Resource
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Code,
+		&i.ApiUri,
+		&i.Description,
+	)
+	return i, err
+}
+
+const listPermissions = `-- name: ListPermissions :many
+SELECT
+    p.role_id,
+    p.actions,
+    r.code AS resource_code,
+    r.name AS resource_name
+FROM permissions p
+JOIN resources r ON p.resource_id = r.id
+ORDER BY p.role_id, r.code
+`
+
+type ListPermissionsRow struct {
+	RoleID       uuid.UUID `db:"role_id" json:"role_id"`
+	Actions      []byte    `db:"actions" json:"actions"`
+	ResourceCode string    `db:"resource_code" json:"resource_code"`
+	ResourceName string    `db:"resource_name" json:"resource_name"`
+}
+
+// Retrieves the permissions of every role with their resource, ordered by role and resource code
+func (q *Queries) ListPermissions(ctx context.Context) ([]ListPermissionsRow, error) {
+	rows, err := q.db.Query(ctx, listPermissions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListPermissionsRow{}
+	for rows.Next() {
+		var i ListPermissionsRow
+		if err := rows.Scan(
+			&i.RoleID,
+			&i.Actions,
+			&i.ResourceCode,
+			&i.ResourceName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const removePermissionAction = `-- name: RemovePermissionAction :execrows
+UPDATE permissions
+SET actions = actions - $1::text
+WHERE role_id = $2
+  AND resource_id = $3
+  AND actions ? $1::text
+`
+
+type RemovePermissionActionParams struct {
+	Action     string    `db:"action" json:"action"`
+	RoleID     uuid.UUID `db:"role_id" json:"role_id"`
+	ResourceID uuid.UUID `db:"resource_id" json:"resource_id"`
+}
+
+// Revokes an action on a resource from a role; no rows are affected when the role does not hold it
+func (q *Queries) RemovePermissionAction(ctx context.Context, arg RemovePermissionActionParams) (int64, error) {
+	result, err := q.db.Exec(ctx, removePermissionAction, arg.Action, arg.RoleID, arg.ResourceID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
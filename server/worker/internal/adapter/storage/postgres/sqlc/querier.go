@@ -8,31 +8,94 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type Querier interface {
+	// Grants an action on a resource to a role, creating the role's permissions row for the resource if needed
+	// Granting an action the role already holds changes nothing
+	AddPermissionAction(ctx context.Context, arg AddPermissionActionParams) error
+	// Assigns an additional role to a user (no-op if already assigned)
+	AddUserRole(ctx context.Context, arg AddUserRoleParams) error
+	// Cancels the scheduled erasure of a user
+	CancelUserDeletion(ctx context.Context, id uuid.UUID) (int64, error)
+	// Counts the users of a tenant registered per day since the given time; days without registrations are omitted
+	CountRegistrationsPerDay(ctx context.Context, arg CountRegistrationsPerDayParams) ([]CountRegistrationsPerDayRow, error)
+	// Counts the users of a tenant per primary role (users.role_id), including roles nobody holds
+	CountUsersByRole(ctx context.Context, tenantID string) ([]CountUsersByRoleRow, error)
+	// =============================================
+	// Access Token Queries
+	// Claims of opaque access tokens (AUTH_TOKEN_TYPE=opaque),
+	// keyed by the SHA-256 of the token
+	// =============================================
+	// Stores an opaque access token and drops the user's expired ones
+	CreateAccessToken(ctx context.Context, arg CreateAccessTokenParams) error
 	// Creates a new role
 	CreateRole(ctx context.Context, arg CreateRoleParams) (Role, error)
 	// =============================================
+	// Session Queries
+	// A session tracks one refresh token family; the refresh
+	// nonce is rotated on every refresh to detect replay
+	// =============================================
+	// Creates a new session for a user
+	CreateSession(ctx context.Context, arg CreateSessionParams) (Session, error)
+	// =============================================
 	// User Queries
 	// =============================================
 	// Creates a new user and returns the created record
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	// Deletes the role's permissions rows for a resource that no longer grant any action
+	DeleteEmptyPermissions(ctx context.Context, arg DeleteEmptyPermissionsParams) error
+	// Deletes up to max_tokens opaque access tokens that expired before expired_before
+	// Bounded so each statement holds its locks briefly
+	DeleteExpiredAccessTokens(ctx context.Context, arg DeleteExpiredAccessTokensParams) (int64, error)
+	// Deletes up to max_sessions sessions that expired before expired_before, or were last seen before
+	// idle_before (NULL when there is no idle timeout), revoked or not
+	// Bounded so each statement holds its locks briefly; rows locked by a refresh are left for the next batch
+	DeleteExpiredSessions(ctx context.Context, arg DeleteExpiredSessionsParams) (int64, error)
+	// Deletes a user's pending reset code once it has been used
+	DeletePasswordResetCode(ctx context.Context, userID uuid.UUID) error
 	// Soft delete is not implemented, this is hard delete
-	DeleteUser(ctx context.Context, id uuid.UUID) error
-	// Checks if a user with the given email exists
-	ExistsByEmail(ctx context.Context, email string) (bool, error)
-	// Checks if a user with the given username exists
-	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	DeleteUser(ctx context.Context, id uuid.UUID) (int64, error)
+	// Deletes every opaque access token of a user, revoking them immediately
+	DeleteUserAccessTokens(ctx context.Context, userID uuid.UUID) error
+	// Erases a user whose scheduled erasure is due; a cancelled schedule deletes nothing
+	DeleteUserIfDue(ctx context.Context, arg DeleteUserIfDueParams) (int64, error)
+	// Removes every additional role of a user, before the user is purged
+	DeleteUserRoles(ctx context.Context, userID uuid.UUID) error
+	// Deletes every session of a user, revoked or not, before the user is purged
+	DeleteUserSessions(ctx context.Context, userID uuid.UUID) error
+	// Returns which of several emails are taken within a tenant, for batch availability checks
+	ExistingEmails(ctx context.Context, arg ExistingEmailsParams) ([]string, error)
+	// Returns which of several usernames are taken within a tenant, for batch availability checks
+	ExistingUsernames(ctx context.Context, arg ExistingUsernamesParams) ([]string, error)
+	// Returns which of several lowercase usernames are taken in any letter case within a tenant, lowercased
+	ExistingUsernamesIgnoringCase(ctx context.Context, arg ExistingUsernamesIgnoringCaseParams) ([]string, error)
+	// Checks if a user with the given email exists within a tenant
+	ExistsByEmail(ctx context.Context, arg ExistsByEmailParams) (bool, error)
+	// Checks if a user with the given username exists within a tenant
+	ExistsByUsername(ctx context.Context, arg ExistsByUsernameParams) (bool, error)
+	// Checks if a user with the given username, in any letter case, exists within a tenant
+	ExistsByUsernameIgnoringCase(ctx context.Context, arg ExistsByUsernameIgnoringCaseParams) (bool, error)
+	// Retrieves an opaque access token by the hash of the token
+	GetAccessToken(ctx context.Context, tokenHash string) (AccessToken, error)
 	// Retrieves the default role for new users (STUDENT)
 	GetDefaultRole(ctx context.Context) (Role, error)
-	// Retrieves flattened permission actions for a role (e.g., "users:read", "users:write")
-	GetPermissionActionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]interface{}, error)
+	// Retrieves flattened permission actions for a role (e.g., "users:read", "users:write"), deduplicated and sorted
+	GetPermissionActionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]string, error)
+	// Retrieves flattened permission actions for several roles at once, one row per role and permission
+	GetPermissionActionsByRoleIDs(ctx context.Context, roleIds []uuid.UUID) ([]GetPermissionActionsByRoleIDsRow, error)
+	// Retrieves flattened permission actions across all roles of a user, deduplicated and sorted
+	GetPermissionActionsByUserID(ctx context.Context, userID uuid.UUID) ([]string, error)
+	// Retrieves flattened permission actions across all roles of several users at once, one row per user and permission
+	GetPermissionActionsByUserIDs(ctx context.Context, userIds []uuid.UUID) ([]GetPermissionActionsByUserIDsRow, error)
 	// =============================================
 	// Permission Queries
 	// =============================================
-	// Retrieves all permissions for a given role
+	// Retrieves all permissions for a given role, ordered by resource code
 	GetPermissionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]GetPermissionsByRoleIDRow, error)
+	// Retrieves a resource by its code (e.g., "users")
+	GetResourceByCode(ctx context.Context, code string) (Resource, error)
 	// Retrieves a role by its code (e.g., "STUDENT", "ADMIN")
 	GetRoleByCode(ctx context.Context, code string) (Role, error)
 	// =============================================
@@ -40,18 +103,111 @@ type Querier interface {
 	// =============================================
 	// Retrieves a role by its UUID
 	GetRoleByID(ctx context.Context, id uuid.UUID) (Role, error)
-	// Retrieves a user by their email address with role info
-	GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error)
-	// Retrieves a user by email OR username (for login) with role info
-	GetUserByEmailOrUsername(ctx context.Context, email string) (GetUserByEmailOrUsernameRow, error)
+	// =============================================
+	// User Role Queries (multi-role support)
+	// A user's roles are the primary role on users.role_id
+	// plus any additional roles in user_roles
+	// =============================================
+	// Retrieves all roles assigned to a user (primary + additional)
+	GetRolesByUserID(ctx context.Context, userID uuid.UUID) ([]Role, error)
+	// Retrieves a session by ID
+	GetSessionByID(ctx context.Context, id uuid.UUID) (Session, error)
+	// Retrieves the email and token version of several users at once, for batch token validation
+	GetTokenSubjectsByIDs(ctx context.Context, ids []uuid.UUID) ([]GetTokenSubjectsByIDsRow, error)
+	// Retrieves the current token version of a user
+	GetTokenVersion(ctx context.Context, id uuid.UUID) (int32, error)
+	// Retrieves a user by their email address within a tenant with role info
+	GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (GetUserByEmailRow, error)
+	// Retrieves a user by email OR username within a tenant (for login) with role info
+	GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (GetUserByEmailOrUsernameRow, error)
+	// Retrieves a user by email OR username in any letter case within a tenant (for login) with role info
+	GetUserByEmailOrUsernameIgnoringCase(ctx context.Context, arg GetUserByEmailOrUsernameIgnoringCaseParams) (GetUserByEmailOrUsernameIgnoringCaseRow, error)
 	// Retrieves a user by their UUID with role info
 	GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow, error)
-	// Retrieves a user by their username with role info
-	GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error)
-	// Updates the last login timestamp for a user
-	UpdateLastLogin(ctx context.Context, id uuid.UUID) error
+	// Retrieves a user by their username within a tenant with role info
+	GetUserByUsername(ctx context.Context, arg GetUserByUsernameParams) (GetUserByUsernameRow, error)
+	// Retrieves a user by their username in any letter case within a tenant with role info
+	GetUserByUsernameIgnoringCase(ctx context.Context, arg GetUserByUsernameIgnoringCaseParams) (GetUserByUsernameIgnoringCaseRow, error)
+	// Bumps the token version, invalidating every access token issued before
+	IncrementTokenVersion(ctx context.Context, id uuid.UUID) (int32, error)
+	// Lists the sessions of a user that can still refresh, oldest first
+	// idle_before is NULL when there is no idle timeout
+	ListActiveUserSessionIDs(ctx context.Context, arg ListActiveUserSessionIDsParams) ([]uuid.UUID, error)
+	// Retrieves the permissions of every role with their resource, ordered by role and resource code
+	ListPermissions(ctx context.Context) ([]ListPermissionsRow, error)
+	// Retrieves every role, ordered by code
+	ListRoles(ctx context.Context) ([]Role, error)
+	// Lists users of a tenant in (created_at, id) order after the keyset position, using idx_users_tenant_created_id
+	// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
+	// Lists users of a tenant in (last_login, id) order after the keyset position, using idx_users_tenant_last_login_id
+	// Users who never logged in sort as logging in at the epoch
+	// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+	ListUsersByLastLogin(ctx context.Context, arg ListUsersByLastLoginParams) ([]ListUsersByLastLoginRow, error)
+	// Lists users of a tenant in descending (last_login, id) order before the keyset position
+	// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+	ListUsersByLastLoginDesc(ctx context.Context, arg ListUsersByLastLoginDescParams) ([]ListUsersByLastLoginDescRow, error)
+	// Lists users of a tenant in bytewise (username, id) order after the keyset position, using idx_users_tenant_username_id
+	// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+	ListUsersByUsername(ctx context.Context, arg ListUsersByUsernameParams) ([]ListUsersByUsernameRow, error)
+	// Lists users of a tenant in descending bytewise (username, id) order before the keyset position
+	// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+	ListUsersByUsernameDesc(ctx context.Context, arg ListUsersByUsernameDescParams) ([]ListUsersByUsernameDescRow, error)
+	// Lists users of a tenant in descending (created_at, id) order before the keyset position
+	// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+	ListUsersDesc(ctx context.Context, arg ListUsersDescParams) ([]ListUsersDescRow, error)
+	// Lists users whose scheduled erasure is due, oldest schedule first
+	ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]ListUsersDueForDeletionRow, error)
+	// Moves last_seen_at of an active session to seen_at when it is older than stale_before,
+	// so a session in use is written at most once per interval
+	// Sessions last seen before idle_before (NULL when there is no idle timeout) stay idle
+	MarkSessionSeen(ctx context.Context, arg MarkSessionSeenParams) (int64, error)
+	// Counts a verification attempt on a user's pending reset code and returns the code
+	// The count is raised before the code is compared, so concurrent guesses cannot exceed the limit
+	RecordPasswordResetAttempt(ctx context.Context, userID uuid.UUID) (PasswordResetCode, error)
+	// Replaces the password hash with a new hash of the same password, leaving password_changed_at alone
+	// Nothing is updated when the password changed since old_hash was read
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error)
+	// Revokes an action on a resource from a role; no rows are affected when the role does not hold it
+	RemovePermissionAction(ctx context.Context, arg RemovePermissionActionParams) (int64, error)
+	// Removes an additional role from a user
+	RemoveUserRole(ctx context.Context, arg RemoveUserRoleParams) (int64, error)
+	// Refuses logins until the password is reset and bumps the token version, invalidating every access token issued before
+	RequirePasswordReset(ctx context.Context, id uuid.UUID) (int32, error)
+	// Revokes a session, invalidating its refresh token
+	RevokeSession(ctx context.Context, id uuid.UUID) error
+	// Revokes all active sessions of a user
+	RevokeUserSessions(ctx context.Context, userID uuid.UUID) error
+	// Replaces the refresh nonce only if the presented nonce is still current
+	// (compare-and-swap, so a nonce can be redeemed at most once)
+	RotateSessionNonce(ctx context.Context, arg RotateSessionNonceParams) (int64, error)
+	// Schedules the erasure of a user; an existing schedule is kept, so repeating the request does not postpone it
+	ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (pgtype.Timestamp, error)
+	// Applies a batch of last logins (one per user); empty IPs and user agents are stored as NULL
+	// Logins older than the stored one are skipped, so replaying a batch is a no-op
+	UpdateLastLogins(ctx context.Context, arg UpdateLastLoginsParams) error
 	// Updates an existing user
 	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	// Activates or deactivates a user
+	UpdateUserActive(ctx context.Context, arg UpdateUserActiveParams) (int64, error)
+	// Updates the avatar URL of a user
+	UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) error
+	// Changes the email of a user; the tenant's unique constraint rejects an email already in use
+	UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (int64, error)
+	// Replaces the password hash and records when it was changed
+	// and lifts a forced reset
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	// Changes the primary role stored on the users row
+	UpdateUserPrimaryRole(ctx context.Context, arg UpdateUserPrimaryRoleParams) error
+	// Renames a user unless the previous rename is more recent than the cooldown
+	UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (int64, error)
+	// =============================================
+	// Password Reset Code Queries
+	// One-time reset codes (AUTH_RESET_TOKEN_TYPE=otp),
+	// at most one pending code per user
+	// =============================================
+	// Stores a user's new reset code, replacing the pending one and its attempts
+	UpsertPasswordResetCode(ctx context.Context, arg UpsertPasswordResetCodeParams) error
 }
 
 var _ Querier = (*Queries)(nil)
@@ -7,11 +7,26 @@ package sqlc
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const cancelUserDeletion = `-- name: CancelUserDeletion :execrows
+UPDATE users SET scheduled_deletion_at = NULL, updated_at = NOW()
+WHERE id = $1 AND scheduled_deletion_at IS NOT NULL
+`
+
+// Cancels the scheduled erasure of a user
+func (q *Queries) CancelUserDeletion(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, cancelUserDeletion, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const createUser = `-- name: CreateUser :one
 
 INSERT INTO users (
@@ -25,10 +40,12 @@ INSERT INTO users (
     avatar,
     is_active,
     created_at,
-    updated_at
+    updated_at,
+    tenant_id,
+    locale
 ) VALUES (
-    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
-) RETURNING id, role_id, email, username, password, full_name, phone, avatar, is_active, last_login, created_at, updated_at
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING id, email, username, password, full_name, created_at, updated_at, role_id, phone, avatar, is_active, last_login, password_changed_at, tenant_id, username_changed_at, token_version, last_login_ip, last_login_user_agent, must_reset_password, locale, scheduled_deletion_at
 `
 
 type CreateUserParams struct {
@@ -43,6 +60,8 @@ type CreateUserParams struct {
 	IsActive  *bool            `db:"is_active" json:"is_active"`
 	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
 	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	TenantID  string           `db:"tenant_id" json:"tenant_id"`
+	Locale    *string          `db:"locale" json:"locale"`
 }
 
 // =============================================
@@ -62,103 +81,322 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.IsActive,
 		arg.CreatedAt,
 		arg.UpdatedAt,
+		arg.TenantID,
+		arg.Locale,
 	)
 	var i User
 	err := row.Scan(
 		&i.ID,
-		&i.RoleID,
 		&i.Email,
 		&i.Username,
 		&i.Password,
 		&i.FullName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RoleID,
 		&i.Phone,
 		&i.Avatar,
 		&i.IsActive,
 		&i.LastLogin,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.PasswordChangedAt,
+		&i.TenantID,
+		&i.UsernameChangedAt,
+		&i.TokenVersion,
+		&i.LastLoginIp,
+		&i.LastLoginUserAgent,
+		&i.MustResetPassword,
+		&i.Locale,
+		&i.ScheduledDeletionAt,
 	)
 	return i, err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
+const deleteUser = `-- name: DeleteUser :execrows
 DELETE FROM users WHERE id = $1
 `
 
 // Soft delete is not implemented, this is hard delete
-func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteUser, id)
-	return err
+func (q *Queries) DeleteUser(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUser, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteUserIfDue = `-- name: DeleteUserIfDue :execrows
+DELETE FROM users WHERE id = $1 AND scheduled_deletion_at <= $2
+`
+
+type DeleteUserIfDueParams struct {
+	ID    uuid.UUID        `db:"id" json:"id"`
+	DueBy pgtype.Timestamp `db:"due_by" json:"due_by"`
+}
+
+// Erases a user whose scheduled erasure is due; a cancelled schedule deletes nothing
+func (q *Queries) DeleteUserIfDue(ctx context.Context, arg DeleteUserIfDueParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteUserIfDue, arg.ID, arg.DueBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const existingEmails = `-- name: ExistingEmails :many
+SELECT email FROM users WHERE tenant_id = $1 AND email = ANY($2::text[])
+`
+
+type ExistingEmailsParams struct {
+	TenantID string   `db:"tenant_id" json:"tenant_id"`
+	Emails   []string `db:"emails" json:"emails"`
+}
+
+// Returns which of several emails are taken within a tenant, for batch availability checks
+func (q *Queries) ExistingEmails(ctx context.Context, arg ExistingEmailsParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, existingEmails, arg.TenantID, arg.Emails)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var email string
+		if err := rows.Scan(&email); err != nil {
+			return nil, err
+		}
+		items = append(items, email)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const existingUsernames = `-- name: ExistingUsernames :many
+SELECT username FROM users WHERE tenant_id = $1 AND username = ANY($2::text[])
+`
+
+type ExistingUsernamesParams struct {
+	TenantID  string   `db:"tenant_id" json:"tenant_id"`
+	Usernames []string `db:"usernames" json:"usernames"`
+}
+
+// Returns which of several usernames are taken within a tenant, for batch availability checks
+func (q *Queries) ExistingUsernames(ctx context.Context, arg ExistingUsernamesParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, existingUsernames, arg.TenantID, arg.Usernames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		items = append(items, username)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const existingUsernamesIgnoringCase = `-- name: ExistingUsernamesIgnoringCase :many
+SELECT LOWER(username)::text AS username FROM users WHERE tenant_id = $1 AND LOWER(username) = ANY($2::text[])
+`
+
+type ExistingUsernamesIgnoringCaseParams struct {
+	TenantID  string   `db:"tenant_id" json:"tenant_id"`
+	Usernames []string `db:"usernames" json:"usernames"`
+}
+
+// Returns which of several lowercase usernames are taken in any letter case within a tenant, lowercased
+func (q *Queries) ExistingUsernamesIgnoringCase(ctx context.Context, arg ExistingUsernamesIgnoringCaseParams) ([]string, error) {
+	rows, err := q.db.Query(ctx, existingUsernamesIgnoringCase, arg.TenantID, arg.Usernames)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []string{}
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			return nil, err
+		}
+		items = append(items, username)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const existsByEmail = `-- name: ExistsByEmail :one
-SELECT EXISTS(SELECT 1 FROM users WHERE email = $1) AS exists
+SELECT EXISTS(SELECT 1 FROM users WHERE email = $1 AND tenant_id = $2) AS exists
 `
 
-// Checks if a user with the given email exists
-func (q *Queries) ExistsByEmail(ctx context.Context, email string) (bool, error) {
-	row := q.db.QueryRow(ctx, existsByEmail, email)
+type ExistsByEmailParams struct {
+	Email    string `db:"email" json:"email"`
+	TenantID string `db:"tenant_id" json:"tenant_id"`
+}
+
+// Checks if a user with the given email exists within a tenant
+func (q *Queries) ExistsByEmail(ctx context.Context, arg ExistsByEmailParams) (bool, error) {
+	row := q.db.QueryRow(ctx, existsByEmail, arg.Email, arg.TenantID)
 	var exists bool
 	err := row.Scan(&exists)
 	return exists, err
 }
 
 const existsByUsername = `-- name: ExistsByUsername :one
-SELECT EXISTS(SELECT 1 FROM users WHERE username = $1) AS exists
+SELECT EXISTS(SELECT 1 FROM users WHERE username = $1 AND tenant_id = $2) AS exists
+`
+
+type ExistsByUsernameParams struct {
+	Username string `db:"username" json:"username"`
+	TenantID string `db:"tenant_id" json:"tenant_id"`
+}
+
+// Checks if a user with the given username exists within a tenant
+func (q *Queries) ExistsByUsername(ctx context.Context, arg ExistsByUsernameParams) (bool, error) {
+	row := q.db.QueryRow(ctx, existsByUsername, arg.Username, arg.TenantID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
+const existsByUsernameIgnoringCase = `-- name: ExistsByUsernameIgnoringCase :one
+SELECT EXISTS(SELECT 1 FROM users WHERE LOWER(username) = LOWER($1) AND tenant_id = $2) AS exists
 `
 
-// Checks if a user with the given username exists
-func (q *Queries) ExistsByUsername(ctx context.Context, username string) (bool, error) {
-	row := q.db.QueryRow(ctx, existsByUsername, username)
+type ExistsByUsernameIgnoringCaseParams struct {
+	Username string `db:"username" json:"username"`
+	TenantID string `db:"tenant_id" json:"tenant_id"`
+}
+
+// Checks if a user with the given username, in any letter case, exists within a tenant
+func (q *Queries) ExistsByUsernameIgnoringCase(ctx context.Context, arg ExistsByUsernameIgnoringCaseParams) (bool, error) {
+	row := q.db.QueryRow(ctx, existsByUsernameIgnoringCase, arg.Username, arg.TenantID)
 	var exists bool
 	err := row.Scan(&exists)
 	return exists, err
 }
 
+const getTokenVersion = `-- name: GetTokenVersion :one
+SELECT token_version FROM users WHERE id = $1
+`
+
+// Retrieves the current token version of a user
+func (q *Queries) GetTokenVersion(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, getTokenVersion, id)
+	var token_version int32
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
+const getTokenSubjectsByIDs = `-- name: GetTokenSubjectsByIDs :many
+SELECT id, email, token_version FROM users WHERE id = ANY($1::uuid[])
+`
+
+type GetTokenSubjectsByIDsRow struct {
+	ID           uuid.UUID `db:"id" json:"id"`
+	Email        string    `db:"email" json:"email"`
+	TokenVersion int32     `db:"token_version" json:"token_version"`
+}
+
+// Retrieves the email and token version of several users at once, for batch token validation
+func (q *Queries) GetTokenSubjectsByIDs(ctx context.Context, ids []uuid.UUID) ([]GetTokenSubjectsByIDsRow, error) {
+	rows, err := q.db.Query(ctx, getTokenSubjectsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []GetTokenSubjectsByIDsRow{}
+	for rows.Next() {
+		var i GetTokenSubjectsByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.TokenVersion,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserByEmail = `-- name: GetUserByEmail :one
 SELECT 
-    u.id, u.role_id, u.email, u.username, u.password, u.full_name, u.phone, u.avatar, u.is_active, u.last_login, u.created_at, u.updated_at,
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
     r.name AS role_name,
     r.code AS role_code
 FROM users u
 LEFT JOIN roles r ON u.role_id = r.id
-WHERE u.email = $1
+WHERE u.email = $1 AND u.tenant_id = $2
 `
 
+type GetUserByEmailParams struct {
+	Email    string `db:"email" json:"email"`
+	TenantID string `db:"tenant_id" json:"tenant_id"`
+}
+
 type GetUserByEmailRow struct {
-	ID        uuid.UUID        `db:"id" json:"id"`
-	RoleID    uuid.UUID        `db:"role_id" json:"role_id"`
-	Email     string           `db:"email" json:"email"`
-	Username  string           `db:"username" json:"username"`
-	Password  string           `db:"password" json:"password"`
-	FullName  string           `db:"full_name" json:"full_name"`
-	Phone     *string          `db:"phone" json:"phone"`
-	Avatar    *string          `db:"avatar" json:"avatar"`
-	IsActive  *bool            `db:"is_active" json:"is_active"`
-	LastLogin pgtype.Timestamp `db:"last_login" json:"last_login"`
-	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	RoleName  *string          `db:"role_name" json:"role_name"`
-	RoleCode  *string          `db:"role_code" json:"role_code"`
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
 }
 
-// Retrieves a user by their email address with role info
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEmailRow, error) {
-	row := q.db.QueryRow(ctx, getUserByEmail, email)
+// Retrieves a user by their email address within a tenant with role info
+func (q *Queries) GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (GetUserByEmailRow, error) {
+	row := q.db.QueryRow(ctx, getUserByEmail, arg.Email, arg.TenantID)
 	var i GetUserByEmailRow
 	err := row.Scan(
 		&i.ID,
-		&i.RoleID,
 		&i.Email,
 		&i.Username,
 		&i.Password,
 		&i.FullName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RoleID,
 		&i.Phone,
 		&i.Avatar,
 		&i.IsActive,
 		&i.LastLogin,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.PasswordChangedAt,
+		&i.TenantID,
+		&i.UsernameChangedAt,
+		&i.TokenVersion,
+		&i.LastLoginIp,
+		&i.LastLoginUserAgent,
+		&i.MustResetPassword,
+		&i.Locale,
+		&i.ScheduledDeletionAt,
 		&i.RoleName,
 		&i.RoleCode,
 	)
@@ -167,48 +405,144 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (GetUserByEm
 
 const getUserByEmailOrUsername = `-- name: GetUserByEmailOrUsername :one
 SELECT 
-    u.id, u.role_id, u.email, u.username, u.password, u.full_name, u.phone, u.avatar, u.is_active, u.last_login, u.created_at, u.updated_at,
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
     r.name AS role_name,
     r.code AS role_code
 FROM users u
 LEFT JOIN roles r ON u.role_id = r.id
-WHERE u.email = $1 OR u.username = $1
+WHERE (u.email = $1 OR u.username = $1) AND u.tenant_id = $2
 `
 
+type GetUserByEmailOrUsernameParams struct {
+	Email    string `db:"email" json:"email"`
+	TenantID string `db:"tenant_id" json:"tenant_id"`
+}
+
 type GetUserByEmailOrUsernameRow struct {
-	ID        uuid.UUID        `db:"id" json:"id"`
-	RoleID    uuid.UUID        `db:"role_id" json:"role_id"`
-	Email     string           `db:"email" json:"email"`
-	Username  string           `db:"username" json:"username"`
-	Password  string           `db:"password" json:"password"`
-	FullName  string           `db:"full_name" json:"full_name"`
-	Phone     *string          `db:"phone" json:"phone"`
-	Avatar    *string          `db:"avatar" json:"avatar"`
-	IsActive  *bool            `db:"is_active" json:"is_active"`
-	LastLogin pgtype.Timestamp `db:"last_login" json:"last_login"`
-	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	RoleName  *string          `db:"role_name" json:"role_name"`
-	RoleCode  *string          `db:"role_code" json:"role_code"`
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
 }
 
-// Retrieves a user by email OR username (for login) with role info
-func (q *Queries) GetUserByEmailOrUsername(ctx context.Context, email string) (GetUserByEmailOrUsernameRow, error) {
-	row := q.db.QueryRow(ctx, getUserByEmailOrUsername, email)
+// Retrieves a user by email OR username within a tenant (for login) with role info
+func (q *Queries) GetUserByEmailOrUsername(ctx context.Context, arg GetUserByEmailOrUsernameParams) (GetUserByEmailOrUsernameRow, error) {
+	row := q.db.QueryRow(ctx, getUserByEmailOrUsername, arg.Email, arg.TenantID)
 	var i GetUserByEmailOrUsernameRow
 	err := row.Scan(
 		&i.ID,
-		&i.RoleID,
 		&i.Email,
 		&i.Username,
 		&i.Password,
 		&i.FullName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RoleID,
 		&i.Phone,
 		&i.Avatar,
 		&i.IsActive,
 		&i.LastLogin,
+		&i.PasswordChangedAt,
+		&i.TenantID,
+		&i.UsernameChangedAt,
+		&i.TokenVersion,
+		&i.LastLoginIp,
+		&i.LastLoginUserAgent,
+		&i.MustResetPassword,
+		&i.Locale,
+		&i.ScheduledDeletionAt,
+		&i.RoleName,
+		&i.RoleCode,
+	)
+	return i, err
+}
+
+const getUserByEmailOrUsernameIgnoringCase = `-- name: GetUserByEmailOrUsernameIgnoringCase :one
+SELECT 
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE (u.email = $1 OR LOWER(u.username) = LOWER($1)) AND u.tenant_id = $2
+`
+
+type GetUserByEmailOrUsernameIgnoringCaseParams struct {
+	Email    string `db:"email" json:"email"`
+	TenantID string `db:"tenant_id" json:"tenant_id"`
+}
+
+type GetUserByEmailOrUsernameIgnoringCaseRow struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
+}
+
+// Retrieves a user by email OR username in any letter case within a tenant (for login) with role info
+func (q *Queries) GetUserByEmailOrUsernameIgnoringCase(ctx context.Context, arg GetUserByEmailOrUsernameIgnoringCaseParams) (GetUserByEmailOrUsernameIgnoringCaseRow, error) {
+	row := q.db.QueryRow(ctx, getUserByEmailOrUsernameIgnoringCase, arg.Email, arg.TenantID)
+	var i GetUserByEmailOrUsernameIgnoringCaseRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.Password,
+		&i.FullName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RoleID,
+		&i.Phone,
+		&i.Avatar,
+		&i.IsActive,
+		&i.LastLogin,
+		&i.PasswordChangedAt,
+		&i.TenantID,
+		&i.UsernameChangedAt,
+		&i.TokenVersion,
+		&i.LastLoginIp,
+		&i.LastLoginUserAgent,
+		&i.MustResetPassword,
+		&i.Locale,
+		&i.ScheduledDeletionAt,
 		&i.RoleName,
 		&i.RoleCode,
 	)
@@ -217,7 +551,7 @@ func (q *Queries) GetUserByEmailOrUsername(ctx context.Context, email string) (G
 
 const getUserByID = `-- name: GetUserByID :one
 SELECT 
-    u.id, u.role_id, u.email, u.username, u.password, u.full_name, u.phone, u.avatar, u.is_active, u.last_login, u.created_at, u.updated_at,
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
     r.name AS role_name,
     r.code AS role_code
 FROM users u
@@ -226,20 +560,29 @@ WHERE u.id = $1
 `
 
 type GetUserByIDRow struct {
-	ID        uuid.UUID        `db:"id" json:"id"`
-	RoleID    uuid.UUID        `db:"role_id" json:"role_id"`
-	Email     string           `db:"email" json:"email"`
-	Username  string           `db:"username" json:"username"`
-	Password  string           `db:"password" json:"password"`
-	FullName  string           `db:"full_name" json:"full_name"`
-	Phone     *string          `db:"phone" json:"phone"`
-	Avatar    *string          `db:"avatar" json:"avatar"`
-	IsActive  *bool            `db:"is_active" json:"is_active"`
-	LastLogin pgtype.Timestamp `db:"last_login" json:"last_login"`
-	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	RoleName  *string          `db:"role_name" json:"role_name"`
-	RoleCode  *string          `db:"role_code" json:"role_code"`
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
 }
 
 // Retrieves a user by their UUID with role info
@@ -248,17 +591,26 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow
 	var i GetUserByIDRow
 	err := row.Scan(
 		&i.ID,
-		&i.RoleID,
 		&i.Email,
 		&i.Username,
 		&i.Password,
 		&i.FullName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RoleID,
 		&i.Phone,
 		&i.Avatar,
 		&i.IsActive,
 		&i.LastLogin,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.PasswordChangedAt,
+		&i.TenantID,
+		&i.UsernameChangedAt,
+		&i.TokenVersion,
+		&i.LastLoginIp,
+		&i.LastLoginUserAgent,
+		&i.MustResetPassword,
+		&i.Locale,
+		&i.ScheduledDeletionAt,
 		&i.RoleName,
 		&i.RoleCode,
 	)
@@ -267,61 +619,903 @@ func (q *Queries) GetUserByID(ctx context.Context, id uuid.UUID) (GetUserByIDRow
 
 const getUserByUsername = `-- name: GetUserByUsername :one
 SELECT 
-    u.id, u.role_id, u.email, u.username, u.password, u.full_name, u.phone, u.avatar, u.is_active, u.last_login, u.created_at, u.updated_at,
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
     r.name AS role_name,
     r.code AS role_code
 FROM users u
 LEFT JOIN roles r ON u.role_id = r.id
-WHERE u.username = $1
+WHERE u.username = $1 AND u.tenant_id = $2
 `
 
+type GetUserByUsernameParams struct {
+	Username string `db:"username" json:"username"`
+	TenantID string `db:"tenant_id" json:"tenant_id"`
+}
+
 type GetUserByUsernameRow struct {
-	ID        uuid.UUID        `db:"id" json:"id"`
-	RoleID    uuid.UUID        `db:"role_id" json:"role_id"`
-	Email     string           `db:"email" json:"email"`
-	Username  string           `db:"username" json:"username"`
-	Password  string           `db:"password" json:"password"`
-	FullName  string           `db:"full_name" json:"full_name"`
-	Phone     *string          `db:"phone" json:"phone"`
-	Avatar    *string          `db:"avatar" json:"avatar"`
-	IsActive  *bool            `db:"is_active" json:"is_active"`
-	LastLogin pgtype.Timestamp `db:"last_login" json:"last_login"`
-	CreatedAt pgtype.Timestamp `db:"created_at" json:"created_at"`
-	UpdatedAt pgtype.Timestamp `db:"updated_at" json:"updated_at"`
-	RoleName  *string          `db:"role_name" json:"role_name"`
-	RoleCode  *string          `db:"role_code" json:"role_code"`
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
 }
 
-// Retrieves a user by their username with role info
-func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error) {
-	row := q.db.QueryRow(ctx, getUserByUsername, username)
+// Retrieves a user by their username within a tenant with role info
+func (q *Queries) GetUserByUsername(ctx context.Context, arg GetUserByUsernameParams) (GetUserByUsernameRow, error) {
+	row := q.db.QueryRow(ctx, getUserByUsername, arg.Username, arg.TenantID)
 	var i GetUserByUsernameRow
 	err := row.Scan(
 		&i.ID,
-		&i.RoleID,
 		&i.Email,
 		&i.Username,
 		&i.Password,
 		&i.FullName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RoleID,
 		&i.Phone,
 		&i.Avatar,
 		&i.IsActive,
 		&i.LastLogin,
+		&i.PasswordChangedAt,
+		&i.TenantID,
+		&i.UsernameChangedAt,
+		&i.TokenVersion,
+		&i.LastLoginIp,
+		&i.LastLoginUserAgent,
+		&i.MustResetPassword,
+		&i.Locale,
+		&i.ScheduledDeletionAt,
+		&i.RoleName,
+		&i.RoleCode,
+	)
+	return i, err
+}
+
+const getUserByUsernameIgnoringCase = `-- name: GetUserByUsernameIgnoringCase :one
+SELECT 
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE LOWER(u.username) = LOWER($1) AND u.tenant_id = $2
+`
+
+type GetUserByUsernameIgnoringCaseParams struct {
+	Username string `db:"username" json:"username"`
+	TenantID string `db:"tenant_id" json:"tenant_id"`
+}
+
+type GetUserByUsernameIgnoringCaseRow struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
+}
+
+// Retrieves a user by their username in any letter case within a tenant with role info
+func (q *Queries) GetUserByUsernameIgnoringCase(ctx context.Context, arg GetUserByUsernameIgnoringCaseParams) (GetUserByUsernameIgnoringCaseRow, error) {
+	row := q.db.QueryRow(ctx, getUserByUsernameIgnoringCase, arg.Username, arg.TenantID)
+	var i GetUserByUsernameIgnoringCaseRow
+	err := row.Scan(
+		&i.ID,
+		&i.Email,
+		&i.Username,
+		&i.Password,
+		&i.FullName,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.RoleID,
+		&i.Phone,
+		&i.Avatar,
+		&i.IsActive,
+		&i.LastLogin,
+		&i.PasswordChangedAt,
+		&i.TenantID,
+		&i.UsernameChangedAt,
+		&i.TokenVersion,
+		&i.LastLoginIp,
+		&i.LastLoginUserAgent,
+		&i.MustResetPassword,
+		&i.Locale,
+		&i.ScheduledDeletionAt,
 		&i.RoleName,
 		&i.RoleCode,
 	)
 	return i, err
 }
 
-const updateLastLogin = `-- name: UpdateLastLogin :exec
-UPDATE users SET last_login = NOW() WHERE id = $1
+const incrementTokenVersion = `-- name: IncrementTokenVersion :one
+UPDATE users SET token_version = token_version + 1, updated_at = NOW() WHERE id = $1
+RETURNING token_version
+`
+
+// Bumps the token version, invalidating every access token issued before
+func (q *Queries) IncrementTokenVersion(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, incrementTokenVersion, id)
+	var token_version int32
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
+const listUsers = `-- name: ListUsers :many
+SELECT 
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = $1
+  AND ($2::bool IS NULL OR COALESCE(u.is_active, TRUE) = $2::bool)
+  AND (u.created_at, u.id) > ($3::timestamp, $4::uuid)
+  AND (NOT $5::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY u.created_at ASC, u.id ASC
+LIMIT $6
+`
+
+type ListUsersParams struct {
+	TenantID        string    `db:"tenant_id" json:"tenant_id"`
+	IsActive        *bool     `db:"is_active" json:"is_active"`
+	AfterTime       time.Time `db:"after_time" json:"after_time"`
+	AfterID         uuid.UUID `db:"after_id" json:"after_id"`
+	PendingApproval bool      `db:"pending_approval" json:"pending_approval"`
+	PageSize        int32     `db:"page_size" json:"page_size"`
+}
+
+type ListUsersRow struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
+}
+
+// Lists users of a tenant in (created_at, id) order after the keyset position, using idx_users_tenant_created_id
+// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
+	rows, err := q.db.Query(ctx, listUsers,
+		arg.TenantID,
+		arg.IsActive,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.PendingApproval,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersRow{}
+	for rows.Next() {
+		var i ListUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.Password,
+			&i.FullName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RoleID,
+			&i.Phone,
+			&i.Avatar,
+			&i.IsActive,
+			&i.LastLogin,
+			&i.PasswordChangedAt,
+			&i.TenantID,
+			&i.UsernameChangedAt,
+			&i.TokenVersion,
+			&i.LastLoginIp,
+			&i.LastLoginUserAgent,
+			&i.MustResetPassword,
+			&i.Locale,
+			&i.ScheduledDeletionAt,
+			&i.RoleName,
+			&i.RoleCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByLastLogin = `-- name: ListUsersByLastLogin :many
+SELECT 
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = $1
+  AND ($2::bool IS NULL OR COALESCE(u.is_active, TRUE) = $2::bool)
+  AND (COALESCE(u.last_login, 'epoch'::timestamp), u.id) > ($3::timestamp, $4::uuid)
+  AND (NOT $5::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY COALESCE(u.last_login, 'epoch'::timestamp) ASC, u.id ASC
+LIMIT $6
+`
+
+type ListUsersByLastLoginParams struct {
+	TenantID        string    `db:"tenant_id" json:"tenant_id"`
+	IsActive        *bool     `db:"is_active" json:"is_active"`
+	AfterTime       time.Time `db:"after_time" json:"after_time"`
+	AfterID         uuid.UUID `db:"after_id" json:"after_id"`
+	PendingApproval bool      `db:"pending_approval" json:"pending_approval"`
+	PageSize        int32     `db:"page_size" json:"page_size"`
+}
+
+type ListUsersByLastLoginRow struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
+}
+
+// Lists users of a tenant in (last_login, id) order after the keyset position, using idx_users_tenant_last_login_id
+// Users who never logged in sort as logging in at the epoch
+// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+func (q *Queries) ListUsersByLastLogin(ctx context.Context, arg ListUsersByLastLoginParams) ([]ListUsersByLastLoginRow, error) {
+	rows, err := q.db.Query(ctx, listUsersByLastLogin,
+		arg.TenantID,
+		arg.IsActive,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.PendingApproval,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersByLastLoginRow{}
+	for rows.Next() {
+		var i ListUsersByLastLoginRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.Password,
+			&i.FullName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RoleID,
+			&i.Phone,
+			&i.Avatar,
+			&i.IsActive,
+			&i.LastLogin,
+			&i.PasswordChangedAt,
+			&i.TenantID,
+			&i.UsernameChangedAt,
+			&i.TokenVersion,
+			&i.LastLoginIp,
+			&i.LastLoginUserAgent,
+			&i.MustResetPassword,
+			&i.Locale,
+			&i.ScheduledDeletionAt,
+			&i.RoleName,
+			&i.RoleCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByLastLoginDesc = `-- name: ListUsersByLastLoginDesc :many
+SELECT 
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = $1
+  AND ($2::bool IS NULL OR COALESCE(u.is_active, TRUE) = $2::bool)
+  AND (COALESCE(u.last_login, 'epoch'::timestamp), u.id) < ($3::timestamp, $4::uuid)
+  AND (NOT $5::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY COALESCE(u.last_login, 'epoch'::timestamp) DESC, u.id DESC
+LIMIT $6
+`
+
+type ListUsersByLastLoginDescParams struct {
+	TenantID        string    `db:"tenant_id" json:"tenant_id"`
+	IsActive        *bool     `db:"is_active" json:"is_active"`
+	AfterTime       time.Time `db:"after_time" json:"after_time"`
+	AfterID         uuid.UUID `db:"after_id" json:"after_id"`
+	PendingApproval bool      `db:"pending_approval" json:"pending_approval"`
+	PageSize        int32     `db:"page_size" json:"page_size"`
+}
+
+type ListUsersByLastLoginDescRow struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
+}
+
+// Lists users of a tenant in descending (last_login, id) order before the keyset position
+// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+func (q *Queries) ListUsersByLastLoginDesc(ctx context.Context, arg ListUsersByLastLoginDescParams) ([]ListUsersByLastLoginDescRow, error) {
+	rows, err := q.db.Query(ctx, listUsersByLastLoginDesc,
+		arg.TenantID,
+		arg.IsActive,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.PendingApproval,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersByLastLoginDescRow{}
+	for rows.Next() {
+		var i ListUsersByLastLoginDescRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.Password,
+			&i.FullName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RoleID,
+			&i.Phone,
+			&i.Avatar,
+			&i.IsActive,
+			&i.LastLogin,
+			&i.PasswordChangedAt,
+			&i.TenantID,
+			&i.UsernameChangedAt,
+			&i.TokenVersion,
+			&i.LastLoginIp,
+			&i.LastLoginUserAgent,
+			&i.MustResetPassword,
+			&i.Locale,
+			&i.ScheduledDeletionAt,
+			&i.RoleName,
+			&i.RoleCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByUsername = `-- name: ListUsersByUsername :many
+SELECT 
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = $1
+  AND ($2::bool IS NULL OR COALESCE(u.is_active, TRUE) = $2::bool)
+  AND (u.username COLLATE "C", u.id) > ($3::text, $4::uuid)
+  AND (NOT $5::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY u.username COLLATE "C" ASC, u.id ASC
+LIMIT $6
+`
+
+type ListUsersByUsernameParams struct {
+	TenantID        string    `db:"tenant_id" json:"tenant_id"`
+	IsActive        *bool     `db:"is_active" json:"is_active"`
+	AfterText       string    `db:"after_text" json:"after_text"`
+	AfterID         uuid.UUID `db:"after_id" json:"after_id"`
+	PendingApproval bool      `db:"pending_approval" json:"pending_approval"`
+	PageSize        int32     `db:"page_size" json:"page_size"`
+}
+
+type ListUsersByUsernameRow struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
+}
+
+// Lists users of a tenant in bytewise (username, id) order after the keyset position, using idx_users_tenant_username_id
+// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+func (q *Queries) ListUsersByUsername(ctx context.Context, arg ListUsersByUsernameParams) ([]ListUsersByUsernameRow, error) {
+	rows, err := q.db.Query(ctx, listUsersByUsername,
+		arg.TenantID,
+		arg.IsActive,
+		arg.AfterText,
+		arg.AfterID,
+		arg.PendingApproval,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersByUsernameRow{}
+	for rows.Next() {
+		var i ListUsersByUsernameRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.Password,
+			&i.FullName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RoleID,
+			&i.Phone,
+			&i.Avatar,
+			&i.IsActive,
+			&i.LastLogin,
+			&i.PasswordChangedAt,
+			&i.TenantID,
+			&i.UsernameChangedAt,
+			&i.TokenVersion,
+			&i.LastLoginIp,
+			&i.LastLoginUserAgent,
+			&i.MustResetPassword,
+			&i.Locale,
+			&i.ScheduledDeletionAt,
+			&i.RoleName,
+			&i.RoleCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersByUsernameDesc = `-- name: ListUsersByUsernameDesc :many
+SELECT 
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = $1
+  AND ($2::bool IS NULL OR COALESCE(u.is_active, TRUE) = $2::bool)
+  AND (u.username COLLATE "C", u.id) < ($3::text, $4::uuid)
+  AND (NOT $5::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY u.username COLLATE "C" DESC, u.id DESC
+LIMIT $6
 `
 
-// Updates the last login timestamp for a user
-func (q *Queries) UpdateLastLogin(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.Exec(ctx, updateLastLogin, id)
+type ListUsersByUsernameDescParams struct {
+	TenantID        string    `db:"tenant_id" json:"tenant_id"`
+	IsActive        *bool     `db:"is_active" json:"is_active"`
+	AfterText       string    `db:"after_text" json:"after_text"`
+	AfterID         uuid.UUID `db:"after_id" json:"after_id"`
+	PendingApproval bool      `db:"pending_approval" json:"pending_approval"`
+	PageSize        int32     `db:"page_size" json:"page_size"`
+}
+
+type ListUsersByUsernameDescRow struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
+}
+
+// Lists users of a tenant in descending bytewise (username, id) order before the keyset position
+// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+func (q *Queries) ListUsersByUsernameDesc(ctx context.Context, arg ListUsersByUsernameDescParams) ([]ListUsersByUsernameDescRow, error) {
+	rows, err := q.db.Query(ctx, listUsersByUsernameDesc,
+		arg.TenantID,
+		arg.IsActive,
+		arg.AfterText,
+		arg.AfterID,
+		arg.PendingApproval,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersByUsernameDescRow{}
+	for rows.Next() {
+		var i ListUsersByUsernameDescRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.Password,
+			&i.FullName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RoleID,
+			&i.Phone,
+			&i.Avatar,
+			&i.IsActive,
+			&i.LastLogin,
+			&i.PasswordChangedAt,
+			&i.TenantID,
+			&i.UsernameChangedAt,
+			&i.TokenVersion,
+			&i.LastLoginIp,
+			&i.LastLoginUserAgent,
+			&i.MustResetPassword,
+			&i.Locale,
+			&i.ScheduledDeletionAt,
+			&i.RoleName,
+			&i.RoleCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersDesc = `-- name: ListUsersDesc :many
+SELECT 
+    u.id, u.email, u.username, u.password, u.full_name, u.created_at, u.updated_at, u.role_id, u.phone, u.avatar, u.is_active, u.last_login, u.password_changed_at, u.tenant_id, u.username_changed_at, u.token_version, u.last_login_ip, u.last_login_user_agent, u.must_reset_password, u.locale, u.scheduled_deletion_at,
+    r.name AS role_name,
+    r.code AS role_code
+FROM users u
+LEFT JOIN roles r ON u.role_id = r.id
+WHERE u.tenant_id = $1
+  AND ($2::bool IS NULL OR COALESCE(u.is_active, TRUE) = $2::bool)
+  AND (u.created_at, u.id) < ($3::timestamp, $4::uuid)
+  AND (NOT $5::bool OR (COALESCE(u.is_active, TRUE) = FALSE AND u.last_login IS NULL AND u.scheduled_deletion_at IS NULL))
+ORDER BY u.created_at DESC, u.id DESC
+LIMIT $6
+`
+
+type ListUsersDescParams struct {
+	TenantID        string    `db:"tenant_id" json:"tenant_id"`
+	IsActive        *bool     `db:"is_active" json:"is_active"`
+	AfterTime       time.Time `db:"after_time" json:"after_time"`
+	AfterID         uuid.UUID `db:"after_id" json:"after_id"`
+	PendingApproval bool      `db:"pending_approval" json:"pending_approval"`
+	PageSize        int32     `db:"page_size" json:"page_size"`
+}
+
+type ListUsersDescRow struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	Email               string           `db:"email" json:"email"`
+	Username            string           `db:"username" json:"username"`
+	Password            string           `db:"password" json:"password"`
+	FullName            string           `db:"full_name" json:"full_name"`
+	CreatedAt           pgtype.Timestamp `db:"created_at" json:"created_at"`
+	UpdatedAt           pgtype.Timestamp `db:"updated_at" json:"updated_at"`
+	RoleID              uuid.UUID        `db:"role_id" json:"role_id"`
+	Phone               *string          `db:"phone" json:"phone"`
+	Avatar              *string          `db:"avatar" json:"avatar"`
+	IsActive            *bool            `db:"is_active" json:"is_active"`
+	LastLogin           pgtype.Timestamp `db:"last_login" json:"last_login"`
+	PasswordChangedAt   pgtype.Timestamp `db:"password_changed_at" json:"password_changed_at"`
+	TenantID            string           `db:"tenant_id" json:"tenant_id"`
+	UsernameChangedAt   pgtype.Timestamp `db:"username_changed_at" json:"username_changed_at"`
+	TokenVersion        int32            `db:"token_version" json:"token_version"`
+	LastLoginIp         *string          `db:"last_login_ip" json:"last_login_ip"`
+	LastLoginUserAgent  *string          `db:"last_login_user_agent" json:"last_login_user_agent"`
+	MustResetPassword   bool             `db:"must_reset_password" json:"must_reset_password"`
+	Locale              *string          `db:"locale" json:"locale"`
+	ScheduledDeletionAt pgtype.Timestamp `db:"scheduled_deletion_at" json:"scheduled_deletion_at"`
+	RoleName            *string          `db:"role_name" json:"role_name"`
+	RoleCode            *string          `db:"role_code" json:"role_code"`
+}
+
+// Lists users of a tenant in descending (created_at, id) order before the keyset position
+// pending_approval keeps inactive users who never logged in and are not scheduled for deletion
+func (q *Queries) ListUsersDesc(ctx context.Context, arg ListUsersDescParams) ([]ListUsersDescRow, error) {
+	rows, err := q.db.Query(ctx, listUsersDesc,
+		arg.TenantID,
+		arg.IsActive,
+		arg.AfterTime,
+		arg.AfterID,
+		arg.PendingApproval,
+		arg.PageSize,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersDescRow{}
+	for rows.Next() {
+		var i ListUsersDescRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Email,
+			&i.Username,
+			&i.Password,
+			&i.FullName,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.RoleID,
+			&i.Phone,
+			&i.Avatar,
+			&i.IsActive,
+			&i.LastLogin,
+			&i.PasswordChangedAt,
+			&i.TenantID,
+			&i.UsernameChangedAt,
+			&i.TokenVersion,
+			&i.LastLoginIp,
+			&i.LastLoginUserAgent,
+			&i.MustResetPassword,
+			&i.Locale,
+			&i.ScheduledDeletionAt,
+			&i.RoleName,
+			&i.RoleCode,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUsersDueForDeletion = `-- name: ListUsersDueForDeletion :many
+SELECT id, tenant_id FROM users
+WHERE scheduled_deletion_at <= $1
+ORDER BY scheduled_deletion_at
+LIMIT $2
+`
+
+type ListUsersDueForDeletionParams struct {
+	DueBy    pgtype.Timestamp `db:"due_by" json:"due_by"`
+	MaxUsers int32            `db:"max_users" json:"max_users"`
+}
+
+type ListUsersDueForDeletionRow struct {
+	ID       uuid.UUID `db:"id" json:"id"`
+	TenantID string    `db:"tenant_id" json:"tenant_id"`
+}
+
+// Lists users whose scheduled erasure is due, oldest schedule first
+func (q *Queries) ListUsersDueForDeletion(ctx context.Context, arg ListUsersDueForDeletionParams) ([]ListUsersDueForDeletionRow, error) {
+	rows, err := q.db.Query(ctx, listUsersDueForDeletion, arg.DueBy, arg.MaxUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUsersDueForDeletionRow{}
+	for rows.Next() {
+		var i ListUsersDueForDeletionRow
+		if err := rows.Scan(&i.ID, &i.TenantID); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE users SET password = $1 WHERE id = $2 AND password = $3
+`
+
+type RehashUserPasswordParams struct {
+	NewHash string    `db:"new_hash" json:"new_hash"`
+	ID      uuid.UUID `db:"id" json:"id"`
+	OldHash string    `db:"old_hash" json:"old_hash"`
+}
+
+// Replaces the password hash with a new hash of the same password, leaving password_changed_at alone
+// Nothing is updated when the password changed since old_hash was read
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.Exec(ctx, rehashUserPassword, arg.NewHash, arg.ID, arg.OldHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const requirePasswordReset = `-- name: RequirePasswordReset :one
+UPDATE users SET must_reset_password = TRUE, token_version = token_version + 1, updated_at = NOW() WHERE id = $1
+RETURNING token_version
+`
+
+// Refuses logins until the password is reset and bumps the token version, invalidating every access token issued before
+func (q *Queries) RequirePasswordReset(ctx context.Context, id uuid.UUID) (int32, error) {
+	row := q.db.QueryRow(ctx, requirePasswordReset, id)
+	var token_version int32
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
+const scheduleUserDeletion = `-- name: ScheduleUserDeletion :one
+UPDATE users SET scheduled_deletion_at = COALESCE(scheduled_deletion_at, $1::timestamp), updated_at = NOW()
+WHERE id = $2
+RETURNING scheduled_deletion_at
+`
+
+type ScheduleUserDeletionParams struct {
+	DeletionAt pgtype.Timestamp `db:"deletion_at" json:"deletion_at"`
+	ID         uuid.UUID        `db:"id" json:"id"`
+}
+
+// Schedules the erasure of a user; an existing schedule is kept, so repeating the request does not postpone it
+func (q *Queries) ScheduleUserDeletion(ctx context.Context, arg ScheduleUserDeletionParams) (pgtype.Timestamp, error) {
+	row := q.db.QueryRow(ctx, scheduleUserDeletion, arg.DeletionAt, arg.ID)
+	var scheduled_deletion_at pgtype.Timestamp
+	err := row.Scan(&scheduled_deletion_at)
+	return scheduled_deletion_at, err
+}
+
+const updateLastLogins = `-- name: UpdateLastLogins :exec
+UPDATE users AS u SET
+    last_login = v.logged_in_at,
+    last_login_ip = NULLIF(v.ip, ''),
+    last_login_user_agent = NULLIF(v.user_agent, '')
+FROM unnest(
+    $1::uuid[],
+    $2::timestamp[],
+    $3::text[],
+    $4::text[]
+) AS v(id, logged_in_at, ip, user_agent)
+WHERE u.id = v.id
+  AND (u.last_login IS NULL OR u.last_login < v.logged_in_at)
+`
+
+type UpdateLastLoginsParams struct {
+	UserIds    []uuid.UUID `db:"user_ids" json:"user_ids"`
+	LoggedInAt []time.Time `db:"logged_in_at" json:"logged_in_at"`
+	Ips        []string    `db:"ips" json:"ips"`
+	UserAgents []string    `db:"user_agents" json:"user_agents"`
+}
+
+// Applies a batch of last logins (one per user); empty IPs and user agents are stored as NULL
+// Logins older than the stored one are skipped, so replaying a batch is a no-op
+func (q *Queries) UpdateLastLogins(ctx context.Context, arg UpdateLastLoginsParams) error {
+	_, err := q.db.Exec(ctx, updateLastLogins,
+		arg.UserIds,
+		arg.LoggedInAt,
+		arg.Ips,
+		arg.UserAgents,
+	)
 	return err
 }
 
@@ -336,7 +1530,7 @@ UPDATE users SET
     is_active = COALESCE($8, is_active),
     updated_at = NOW()
 WHERE id = $1
-RETURNING id, role_id, email, username, password, full_name, phone, avatar, is_active, last_login, created_at, updated_at
+RETURNING id, email, username, password, full_name, created_at, updated_at, role_id, phone, avatar, is_active, last_login, password_changed_at, tenant_id, username_changed_at, token_version, last_login_ip, last_login_user_agent, must_reset_password, locale, scheduled_deletion_at
 `
 
 type UpdateUserParams struct {
@@ -365,17 +1559,117 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (User, e
 	var i User
 	err := row.Scan(
 		&i.ID,
-		&i.RoleID,
 		&i.Email,
 		&i.Username,
 		&i.Password,
 		&i.FullName,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.RoleID,
 		&i.Phone,
 		&i.Avatar,
 		&i.IsActive,
 		&i.LastLogin,
-		&i.CreatedAt,
-		&i.UpdatedAt,
+		&i.PasswordChangedAt,
+		&i.TenantID,
+		&i.UsernameChangedAt,
+		&i.TokenVersion,
+		&i.LastLoginIp,
+		&i.LastLoginUserAgent,
+		&i.MustResetPassword,
+		&i.Locale,
+		&i.ScheduledDeletionAt,
 	)
 	return i, err
 }
+
+const updateUserActive = `-- name: UpdateUserActive :execrows
+UPDATE users SET is_active = $1, updated_at = NOW() WHERE id = $2
+`
+
+type UpdateUserActiveParams struct {
+	IsActive *bool     `db:"is_active" json:"is_active"`
+	ID       uuid.UUID `db:"id" json:"id"`
+}
+
+// Activates or deactivates a user
+func (q *Queries) UpdateUserActive(ctx context.Context, arg UpdateUserActiveParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserActive, arg.IsActive, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUserAvatar = `-- name: UpdateUserAvatar :exec
+UPDATE users SET avatar = $2, updated_at = NOW() WHERE id = $1
+`
+
+type UpdateUserAvatarParams struct {
+	ID     uuid.UUID `db:"id" json:"id"`
+	Avatar *string   `db:"avatar" json:"avatar"`
+}
+
+// Updates the avatar URL of a user
+func (q *Queries) UpdateUserAvatar(ctx context.Context, arg UpdateUserAvatarParams) error {
+	_, err := q.db.Exec(ctx, updateUserAvatar, arg.ID, arg.Avatar)
+	return err
+}
+
+const updateUserEmail = `-- name: UpdateUserEmail :execrows
+UPDATE users SET email = $1, updated_at = NOW() WHERE id = $2
+`
+
+type UpdateUserEmailParams struct {
+	Email string    `db:"email" json:"email"`
+	ID    uuid.UUID `db:"id" json:"id"`
+}
+
+// Changes the email of a user; the tenant's unique constraint rejects an email already in use
+func (q *Queries) UpdateUserEmail(ctx context.Context, arg UpdateUserEmailParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUserEmail, arg.Email, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updateUserPassword = `-- name: UpdateUserPassword :exec
+UPDATE users SET password = $2, password_changed_at = NOW(), must_reset_password = FALSE, updated_at = NOW() WHERE id = $1
+`
+
+type UpdateUserPasswordParams struct {
+	ID       uuid.UUID `db:"id" json:"id"`
+	Password string    `db:"password" json:"password"`
+}
+
+// Replaces the password hash and records when it was changed
+// and lifts a forced reset
+func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
+	_, err := q.db.Exec(ctx, updateUserPassword, arg.ID, arg.Password)
+	return err
+}
+
+const updateUsername = `-- name: UpdateUsername :execrows
+UPDATE users SET
+    username = $1,
+    username_changed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $2
+  AND (username_changed_at IS NULL OR username_changed_at <= NOW() - $3::interval)
+`
+
+type UpdateUsernameParams struct {
+	Username string          `db:"username" json:"username"`
+	ID       uuid.UUID       `db:"id" json:"id"`
+	Cooldown pgtype.Interval `db:"cooldown" json:"cooldown"`
+}
+
+// Renames a user unless the previous rename is more recent than the cooldown
+func (q *Queries) UpdateUsername(ctx context.Context, arg UpdateUsernameParams) (int64, error) {
+	result, err := q.db.Exec(ctx, updateUsername, arg.Username, arg.ID, arg.Cooldown)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// PoolStats implements ports.DatabaseStats on top of pgxpool.Stat
+type PoolStats struct {
+	pool *pgxpool.Pool
+}
+
+// NewPoolStats creates a new PoolStats
+func NewPoolStats(pool *pgxpool.Pool) *PoolStats {
+	return &PoolStats{pool: pool}
+}
+
+// Stats returns a snapshot of the pool statistics
+func (p *PoolStats) Stats() *domain.DBStats {
+	stat := p.pool.Stat()
+	return &domain.DBStats{
+		AcquiredConns:     stat.AcquiredConns(),
+		IdleConns:         stat.IdleConns(),
+		TotalConns:        stat.TotalConns(),
+		MaxConns:          stat.MaxConns(),
+		AcquireCount:      stat.AcquireCount(),
+		EmptyAcquireCount: stat.EmptyAcquireCount(),
+		EmptyAcquireWait:  stat.EmptyAcquireWaitTime(),
+		AcquireDuration:   stat.AcquireDuration(),
+		CanceledAcquires:  stat.CanceledAcquireCount(),
+	}
+}
+
+// startStatsSampler logs pool statistics every DB_STATS_INTERVAL
+// Samples are logged at debug level, or as a warning when acquires had to wait since the last sample
+func startStatsSampler(lc fx.Lifecycle, cfg *config.DatabaseConfig, stats ports.DatabaseStats, logger *zap.Logger) {
+	if cfg.StatsInterval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(cfg.StatsInterval)
+				defer ticker.Stop()
+
+				var lastWaits int64
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						s := stats.Stats()
+						fields := []zap.Field{
+							zap.Int32("acquired", s.AcquiredConns),
+							zap.Int32("idle", s.IdleConns),
+							zap.Int32("total", s.TotalConns),
+							zap.Int32("max", s.MaxConns),
+							zap.Int64("wait_count", s.EmptyAcquireCount),
+							zap.Duration("wait_duration", s.EmptyAcquireWait),
+						}
+						if s.EmptyAcquireCount > lastWaits {
+							logger.Warn("PostgreSQL pool acquires waited for a connection", fields...)
+						} else {
+							logger.Debug("PostgreSQL pool stats", fields...)
+						}
+						lastWaits = s.EmptyAcquireCount
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
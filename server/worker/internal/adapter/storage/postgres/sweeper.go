@@ -0,0 +1,94 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/config"
+	"worker/internal/core/ports"
+)
+
+// sweepTimeout bounds a single sweep; whatever is left is deleted on the next tick
+const sweepTimeout = time.Minute
+
+// startExpiredSweeper deletes expired and idle (AUTH_SESSION_IDLE_TIMEOUT) sessions and opaque access tokens every DB_SWEEP_INTERVAL
+// Rows are deleted in batches of DB_SWEEP_BATCH_SIZE, each its own statement, so no sweep holds locks for long
+func startExpiredSweeper(
+	lc fx.Lifecycle,
+	cfg *config.DatabaseConfig,
+	authCfg *config.AuthConfig,
+	sessions ports.SessionRepository,
+	accessTokens ports.AccessTokenRepository,
+	clock ports.Clock,
+	logger *zap.Logger,
+) {
+	if cfg.SweepInterval <= 0 {
+		return
+	}
+
+	logger = logger.Named("expired_sweeper")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	sweep := func() {
+		sweepCtx, cancelSweep := context.WithTimeout(ctx, sweepTimeout)
+		defer cancelSweep()
+
+		now := clock.Now()
+		sweepTable(sweepCtx, logger, "sessions", cfg.SweepBatchSize, func(ctx context.Context) (int64, error) {
+			return sessions.DeleteExpired(ctx, now, authCfg.SessionIdleBefore(now), cfg.SweepBatchSize)
+		})
+		sweepTable(sweepCtx, logger, "access_tokens", cfg.SweepBatchSize, func(ctx context.Context) (int64, error) {
+			return accessTokens.DeleteExpired(ctx, now, cfg.SweepBatchSize)
+		})
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(cfg.SweepInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						sweep()
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
+
+// sweepTable runs deleteBatch until a batch comes back short, then logs how many rows were removed
+// A failed batch ends the sweep of the table; the rows are retried on the next tick
+func sweepTable(ctx context.Context, logger *zap.Logger, table string, batchSize int32, deleteBatch func(context.Context) (int64, error)) {
+	var removed int64
+	for ctx.Err() == nil {
+		n, err := deleteBatch(ctx)
+		removed += n
+		if err != nil {
+			logger.Error("Failed to delete expired records", zap.String("table", table), zap.Int64("removed", removed), zap.Error(err))
+			return
+		}
+		if n < int64(batchSize) {
+			break
+		}
+	}
+
+	if removed > 0 {
+		logger.Info("Deleted expired records", zap.String("table", table), zap.Int64("removed", removed))
+	}
+}
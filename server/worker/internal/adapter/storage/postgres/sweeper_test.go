@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"worker/internal/adapter/storage/memory"
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/common/clock"
+	"worker/internal/config"
+)
+
+func TestExpiredSweeperAgesOutRecords(t *testing.T) {
+	fake := clock.NewFake(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC))
+	store := memory.NewStore(fake)
+	store.Seed("STUDENT")
+	users := memory.NewUserRepository(store)
+	sessions := memory.NewSessionRepository(store)
+	tokens := memory.NewAccessTokenRepository(store)
+	ctx := context.Background()
+
+	role, err := memory.NewRoleRepository(store).FindByCode(ctx, "STUDENT")
+	if err != nil {
+		t.Fatalf("find role: %v", err)
+	}
+	user, err := users.CreateUser(ctx, sqlc.CreateUserParams{ID: uuid.New(), RoleID: role.ID, Username: "alice", Email: "alice@example.com", Password: "hash"})
+	if err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	expiring := func(d time.Duration) pgtype.Timestamp {
+		return pgtype.Timestamp{Time: fake.Now().Add(d), Valid: true}
+	}
+	session, err := sessions.Create(ctx, sqlc.CreateSessionParams{ID: uuid.New(), UserID: user.ID, RefreshNonce: "nonce", ExpiresAt: expiring(time.Hour)})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if err := tokens.Create(ctx, sqlc.CreateAccessTokenParams{TokenHash: "hash", UserID: user.ID, Claims: []byte("{}"), ExpiresAt: expiring(time.Minute)}); err != nil {
+		t.Fatalf("create token: %v", err)
+	}
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	lc := fxtest.NewLifecycle(t)
+	cfg := &config.DatabaseConfig{SweepInterval: 5 * time.Millisecond, SweepBatchSize: 10}
+	startExpiredSweeper(lc, cfg, &config.AuthConfig{}, sessions, tokens, fake, zap.New(core))
+	lc.RequireStart()
+
+	// Ticks before the records expire leave them alone
+	time.Sleep(50 * time.Millisecond)
+	if _, err := sessions.FindByID(ctx, session.ID); err != nil {
+		t.Fatalf("live session was swept: %v", err)
+	}
+
+	// Past both expiries, the next tick deletes them and logs how many went
+	fake.Advance(2 * time.Hour)
+	deadline := time.Now().Add(5 * time.Second)
+	for logs.FilterMessage("Deleted expired records").Len() < 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("expired records were not swept; logged %v", logs.All())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	lc.RequireStop()
+
+	if _, err := sessions.FindByID(ctx, session.ID); err == nil {
+		t.Fatal("expired session was kept")
+	}
+	if _, err := tokens.FindByHash(ctx, "hash"); err == nil {
+		t.Fatal("expired token was kept")
+	}
+	for _, entry := range logs.FilterMessage("Deleted expired records").All() {
+		if entry.ContextMap()["removed"] != int64(1) {
+			t.Fatalf("logged %v, want one record removed per table", entry.ContextMap())
+		}
+	}
+}
+
+func TestExpiredSweeperDisabled(t *testing.T) {
+	lc := fxtest.NewLifecycle(t)
+	// A zero interval registers nothing, so the nil repositories are never called
+	startExpiredSweeper(lc, &config.DatabaseConfig{}, &config.AuthConfig{}, nil, nil, clock.NewReal(), zap.NewNop())
+	lc.RequireStart()
+	lc.RequireStop()
+}
+
+func TestSweepTableBatches(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	remaining := int64(5)
+	var calls int
+	sweepTable(context.Background(), zap.New(core), "sessions", 2, func(ctx context.Context) (int64, error) {
+		calls++
+		n := min(remaining, 2)
+		remaining -= n
+		return n, nil
+	})
+
+	// Batches run until one comes back short
+	if calls != 3 || remaining != 0 {
+		t.Fatalf("%d batches leaving %d rows, want 3 leaving none", calls, remaining)
+	}
+	entries := logs.FilterMessage("Deleted expired records").All()
+	if len(entries) != 1 || entries[0].ContextMap()["removed"] != int64(5) {
+		t.Fatalf("logged %v, want 5 removed", logs.All())
+	}
+}
+
+func TestSweepTableStopsOnError(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	var calls int
+	sweepTable(context.Background(), zap.New(core), "sessions", 2, func(ctx context.Context) (int64, error) {
+		calls++
+		if calls == 2 {
+			return 0, errors.New("lock timeout")
+		}
+		return 2, nil
+	})
+
+	if calls != 2 {
+		t.Fatalf("%d batches, want the sweep to end at the failed one", calls)
+	}
+	entries := logs.FilterMessage("Failed to delete expired records").All()
+	if len(entries) != 1 || entries[0].ContextMap()["removed"] != int64(2) {
+		t.Fatalf("logged %v, want the failure after 2 removed", logs.All())
+	}
+}
+
+func TestSweepTableStopsWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+	sweepTable(ctx, zap.NewNop(), "sessions", 2, func(ctx context.Context) (int64, error) {
+		calls++
+		cancel() // Shutdown arrives during the first batch
+		return 2, nil
+	})
+	if calls != 1 {
+		t.Fatalf("%d batches, want the sweep to end on shutdown", calls)
+	}
+}
@@ -0,0 +1,218 @@
+package s3
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// Ensure Client implements ports.ObjectStorage
+var _ ports.ObjectStorage = (*Client)(nil)
+
+// Client implements ports.ObjectStorage for S3-compatible services (AWS S3, MinIO)
+// Requests are signed with AWS Signature Version 4, no SDK required
+type Client struct {
+	endpoint     *url.URL
+	bucket       string
+	publicURL    string
+	usePathStyle bool
+	signer       *signer
+	httpClient   *http.Client
+	clock        ports.Clock // Dates the signatures, so presigned URLs expire when the service says they do
+}
+
+// NewClient creates a new S3 client from storage configuration
+func NewClient(cfg *config.StorageConfig, clock ports.Clock) (*Client, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid S3_ENDPOINT %q", cfg.Endpoint)
+	}
+
+	client := &Client{
+		endpoint:     endpoint,
+		bucket:       cfg.Bucket,
+		publicURL:    strings.TrimSuffix(cfg.PublicURL, "/"),
+		usePathStyle: cfg.UsePathStyle,
+		signer: &signer{
+			accessKey: cfg.AccessKey,
+			secretKey: cfg.SecretKey,
+			region:    cfg.Region,
+		},
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		clock:      clock,
+	}
+
+	// Default public URL: the bucket URL itself
+	if client.publicURL == "" {
+		client.publicURL = strings.TrimSuffix(client.objectURL("").String(), "/")
+	}
+
+	return client, nil
+}
+
+// PresignPut returns a presigned PUT URL bound to the given content type and length
+// Content-Length is a signed header, so S3 refuses an upload of any other size before storing it
+func (c *Client) PresignPut(ctx context.Context, key, contentType string, contentLength int64, expires time.Duration) (string, error) {
+	u := c.objectURL(key)
+	headers := http.Header{}
+	headers.Set("Content-Type", contentType)
+	headers.Set("Content-Length", strconv.FormatInt(contentLength, 10))
+
+	c.signer.presign(http.MethodPut, u, headers, expires, c.clock.Now())
+	return u.String(), nil
+}
+
+// Stat retrieves object metadata with a HEAD request
+func (c *Client) Stat(ctx context.Context, key string) (*domain.ObjectInfo, error) {
+	resp, err := c.do(ctx, http.MethodHead, key)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return nil, domain.ErrObjectNotFound
+	case resp.StatusCode >= 300:
+		return nil, fmt.Errorf("s3 HEAD %s: unexpected status %d", key, resp.StatusCode)
+	}
+
+	return &domain.ObjectInfo{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+// Delete removes an object
+func (c *Client) Delete(ctx context.Context, key string) error {
+	resp, err := c.do(ctx, http.MethodDelete, key)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("s3 DELETE %s: unexpected status %d", key, resp.StatusCode)
+	}
+	return nil
+}
+
+// List returns the keys of the objects whose key starts with prefix, with ListObjectsV2
+func (c *Client) List(ctx context.Context, prefix string) ([]string, error) {
+	var keys []string
+	continuation := ""
+	for {
+		query := url.Values{}
+		query.Set("list-type", "2")
+		query.Set("prefix", prefix)
+		if continuation != "" {
+			query.Set("continuation-token", continuation)
+		}
+		u := c.bucketURL()
+		u.RawQuery = canonicalQuery(query)
+
+		page, err := c.listPage(ctx, u, prefix)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			keys = append(keys, object.Key)
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return keys, nil
+		}
+		continuation = page.NextContinuationToken
+	}
+}
+
+// listBucketResult is the part of a ListObjectsV2 response List reads
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// listPage fetches and decodes one page of a ListObjectsV2 request
+func (c *Client) listPage(ctx context.Context, u *url.URL, prefix string) (*listBucketResult, error) {
+	resp, err := c.send(ctx, http.MethodGet, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("s3 LIST %s: unexpected status %d", prefix, resp.StatusCode)
+	}
+	page := &listBucketResult{}
+	if err := xml.NewDecoder(resp.Body).Decode(page); err != nil {
+		return nil, fmt.Errorf("s3 LIST %s: %w", prefix, err)
+	}
+	return page, nil
+}
+
+// PublicURL returns the URL an object is publicly served from
+func (c *Client) PublicURL(key string) string {
+	return c.publicURL + "/" + escapePath(key)
+}
+
+// do sends a signed request without body for the given object
+func (c *Client) do(ctx context.Context, method, key string) (*http.Response, error) {
+	return c.send(ctx, method, c.objectURL(key))
+}
+
+// send sends a signed request without body to u, whose query must already be canonical
+func (c *Client) send(ctx context.Context, method string, u *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	c.signer.sign(req, c.clock.Now())
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("s3 %s %s: %w", method, u.Path, err)
+	}
+	return resp, nil
+}
+
+// bucketURL builds the URL of the bucket itself, for bucket-level requests such as listing
+func (c *Client) bucketURL() *url.URL {
+	u := *c.endpoint
+	basePath := strings.TrimSuffix(u.Path, "/")
+
+	if c.usePathStyle {
+		u.Path = basePath + "/" + c.bucket
+	} else {
+		u.Host = c.bucket + "." + u.Host
+		u.Path = basePath + "/"
+	}
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
+
+// objectURL builds the object URL using path-style or virtual-hosted-style addressing
+func (c *Client) objectURL(key string) *url.URL {
+	u := *c.endpoint
+	basePath := strings.TrimSuffix(u.Path, "/")
+
+	if c.usePathStyle {
+		u.Path = basePath + "/" + c.bucket + "/" + key
+	} else {
+		u.Host = c.bucket + "." + u.Host
+		u.Path = basePath + "/" + key
+	}
+	u.RawPath = escapePath(u.Path)
+	return &u
+}
@@ -0,0 +1,96 @@
+package s3
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestPresignPutSignsContentLength(t *testing.T) {
+	client := newExampleClient(t, "http://localhost:9000", true)
+	ctx := context.Background()
+
+	presign := func(contentLength int64) url.Values {
+		t.Helper()
+		raw, err := client.PresignPut(ctx, "avatars/a.png", "image/png", contentLength, 15*time.Minute)
+		if err != nil {
+			t.Fatalf("presign: %v", err)
+		}
+		u, err := url.Parse(raw)
+		if err != nil {
+			t.Fatalf("parse presigned URL: %v", err)
+		}
+		return u.Query()
+	}
+
+	small := presign(1024)
+	if got := small.Get("X-Amz-SignedHeaders"); got != "content-length;content-type;host" {
+		t.Fatalf("X-Amz-SignedHeaders = %q, want content-length;content-type;host", got)
+	}
+	// The signature is dated by the injected clock, not the system clock
+	if got := small.Get("X-Amz-Date"); got != "20130524T000000Z" {
+		t.Fatalf("X-Amz-Date = %q, want 20130524T000000Z", got)
+	}
+	if small.Get("X-Amz-Signature") == presign(2048).Get("X-Amz-Signature") {
+		t.Fatal("URLs presigned for different lengths share a signature")
+	}
+}
+
+func TestList(t *testing.T) {
+	pages := map[string]string{
+		"": `<ListBucketResult><IsTruncated>true</IsTruncated><NextContinuationToken>next</NextContinuationToken>` +
+			`<Contents><Key>avatars/u/1.png</Key></Contents><Contents><Key>avatars/u/2.png</Key></Contents></ListBucketResult>`,
+		"next": `<ListBucketResult><IsTruncated>false</IsTruncated>` +
+			`<Contents><Key>avatars/u/3.png</Key></Contents></ListBucketResult>`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Method != http.MethodGet || r.URL.Path != "/examplebucket" || query.Get("list-type") != "2" || query.Get("prefix") != "avatars/u/" {
+			http.Error(w, "unexpected request "+r.Method+" "+r.URL.String(), http.StatusBadRequest)
+			return
+		}
+		if r.Header.Get("Authorization") == "" {
+			http.Error(w, "unsigned request", http.StatusForbidden)
+			return
+		}
+		page, ok := pages[query.Get("continuation-token")]
+		if !ok {
+			http.Error(w, "unknown continuation token", http.StatusBadRequest)
+			return
+		}
+		fmt.Fprint(w, page)
+	}))
+	defer server.Close()
+
+	client := newExampleClient(t, server.URL, true)
+	keys, err := client.List(context.Background(), "avatars/u/")
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	want := []string{"avatars/u/1.png", "avatars/u/2.png", "avatars/u/3.png"}
+	if !slices.Equal(keys, want) {
+		t.Fatalf("list = %v, want %v", keys, want)
+	}
+}
+
+func TestBucketURL(t *testing.T) {
+	tests := []struct {
+		endpoint  string
+		pathStyle bool
+		want      string
+	}{
+		{endpoint: "http://localhost:9000", pathStyle: true, want: "http://localhost:9000/examplebucket"},
+		{endpoint: "https://s3.amazonaws.com", pathStyle: false, want: "https://examplebucket.s3.amazonaws.com/"},
+	}
+	for _, tt := range tests {
+		client := newExampleClient(t, tt.endpoint, tt.pathStyle)
+		if got := client.bucketURL().String(); got != tt.want {
+			t.Fatalf("bucketURL(%s, path style %t) = %s, want %s", tt.endpoint, tt.pathStyle, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package s3
+
+import (
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/storage/memory"
+	"worker/internal/config"
+	"worker/internal/core/ports"
+)
+
+// Module provides object storage dependencies
+var Module = fx.Module("s3",
+	fx.Provide(NewObjectStorage),
+)
+
+// NewObjectStorage returns an S3 client, or an in-memory store when S3_ENDPOINT is not set
+func NewObjectStorage(cfg *config.StorageConfig, clock ports.Clock, logger *zap.Logger) (ports.ObjectStorage, error) {
+	if cfg.Endpoint == "" {
+		logger.Warn("⚠️ S3_ENDPOINT not set, using in-memory object storage (development only)")
+		return memory.NewObjectStorage(cfg.PublicURL), nil
+	}
+
+	client, err := NewClient(cfg, clock)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("✅ Object storage configured", zap.String("bucket", cfg.Bucket))
+	return client, nil
+}
@@ -0,0 +1,178 @@
+package s3
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// =============================================================================
+// AWS Signature Version 4 helpers (subset needed for S3 presign/HEAD/DELETE)
+// =============================================================================
+
+const (
+	sigAlgorithm    = "AWS4-HMAC-SHA256"
+	sigService      = "s3"
+	unsignedPayload = "UNSIGNED-PAYLOAD"
+	amzDateFormat   = "20060102T150405Z"
+	shortDateFormat = "20060102"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body
+var emptyPayloadHash = hashHex("")
+
+type signer struct {
+	accessKey string
+	secretKey string
+	region    string
+}
+
+// presign adds SigV4 query parameters to u, signing the given headers
+func (s *signer) presign(method string, u *url.URL, headers http.Header, expires time.Duration, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	scope := s.scope(now)
+	signedHeaders := s.signedHeaderNames(u, headers)
+
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", sigAlgorithm)
+	query.Set("X-Amz-Credential", s.accessKey+"/"+scope)
+	query.Set("X-Amz-Date", amzDate)
+	query.Set("X-Amz-Expires", fmt.Sprintf("%d", int64(expires.Seconds())))
+	query.Set("X-Amz-SignedHeaders", strings.Join(signedHeaders, ";"))
+	u.RawQuery = canonicalQuery(query)
+
+	canonical := s.canonicalRequest(method, u, headers, signedHeaders, unsignedPayload)
+	query.Set("X-Amz-Signature", s.signature(canonical, amzDate, scope, now))
+	u.RawQuery = canonicalQuery(query)
+}
+
+// sign adds SigV4 Authorization headers to req for an empty payload
+func (s *signer) sign(req *http.Request, now time.Time) {
+	amzDate := now.UTC().Format(amzDateFormat)
+	scope := s.scope(now)
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", emptyPayloadHash)
+	signedHeaders := s.signedHeaderNames(req.URL, req.Header)
+
+	canonical := s.canonicalRequest(req.Method, req.URL, req.Header, signedHeaders, emptyPayloadHash)
+	signature := s.signature(canonical, amzDate, scope, now)
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		sigAlgorithm, s.accessKey, scope, strings.Join(signedHeaders, ";"), signature,
+	))
+}
+
+func (s *signer) scope(now time.Time) string {
+	return strings.Join([]string{now.UTC().Format(shortDateFormat), s.region, sigService, "aws4_request"}, "/")
+}
+
+// signedHeaderNames returns the sorted lowercase header names to sign (host is always included)
+// Content-Length is only signed when set in headers, as PresignPut does; requests pass it outside the header map
+func (s *signer) signedHeaderNames(u *url.URL, headers http.Header) []string {
+	names := []string{"host"}
+	for name := range headers {
+		lower := strings.ToLower(name)
+		if lower == "content-type" || lower == "content-length" || strings.HasPrefix(lower, "x-amz-") {
+			names = append(names, lower)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (s *signer) canonicalRequest(method string, u *url.URL, headers http.Header, signedHeaders []string, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := u.Host
+		if name != "host" {
+			value = strings.TrimSpace(headers.Get(name))
+		}
+		canonicalHeaders.WriteString(name + ":" + value + "\n")
+	}
+
+	return strings.Join([]string{
+		method,
+		escapePath(u.Path),
+		u.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+}
+
+func (s *signer) signature(canonicalRequest, amzDate, scope string, now time.Time) string {
+	stringToSign := strings.Join([]string{
+		sigAlgorithm,
+		amzDate,
+		scope,
+		hashHex(canonicalRequest),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.secretKey), now.UTC().Format(shortDateFormat))
+	key = hmacSHA256(key, s.region)
+	key = hmacSHA256(key, sigService)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+func hashHex(data string) string {
+	sum := sha256.Sum256([]byte(data))
+	return hex.EncodeToString(sum[:])
+}
+
+// canonicalQuery encodes query parameters sorted by key using SigV4 escaping
+func canonicalQuery(values url.Values) string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	parts := make([]string, 0, len(keys))
+	for _, key := range keys {
+		for _, value := range values[key] {
+			parts = append(parts, escape(key, true)+"="+escape(value, true))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// escapePath URI-encodes each path segment, keeping the slashes
+func escapePath(path string) string {
+	if path == "" {
+		return "/"
+	}
+	return escape(path, false)
+}
+
+// escape implements the SigV4 URI encoding (only unreserved characters are kept)
+func escape(value string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		c := value[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
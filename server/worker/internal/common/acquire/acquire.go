@@ -0,0 +1,35 @@
+// Package acquire reports database connection acquire timeouts back to the request that hit them.
+//
+// Services turn repository errors into generic database errors, so the cause is lost on the way up;
+// the pool marks the request context instead and the transport reads the mark when the call fails.
+package acquire
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type contextKey struct{}
+
+type tracker struct {
+	timedOut atomic.Bool
+}
+
+// Track returns a copy of ctx that records acquire timeouts of the queries run with it
+func Track(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextKey{}, &tracker{})
+}
+
+// MarkTimedOut records that acquiring a connection for ctx timed out
+// It is a no-op for contexts not derived from Track
+func MarkTimedOut(ctx context.Context) {
+	if t, ok := ctx.Value(contextKey{}).(*tracker); ok {
+		t.timedOut.Store(true)
+	}
+}
+
+// TimedOut reports whether any query run with ctx failed to acquire a connection in time
+func TimedOut(ctx context.Context) bool {
+	t, ok := ctx.Value(contextKey{}).(*tracker)
+	return ok && t.timedOut.Load()
+}
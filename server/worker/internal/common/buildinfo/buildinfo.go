@@ -0,0 +1,26 @@
+// Package buildinfo reports which build of the worker is running.
+//
+// Release builds set the version at link time:
+//
+//	go build -ldflags "-X worker/internal/common/buildinfo.version=v1.2.3" ./cmd
+package buildinfo
+
+import "runtime/debug"
+
+// version is set at link time; empty for plain go build / go run
+var version string
+
+// Version returns the link-time version, else the VCS revision recorded by the Go toolchain, else "dev"
+func Version() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, setting := range info.Settings {
+			if setting.Key == "vcs.revision" {
+				return setting.Value
+			}
+		}
+	}
+	return "dev"
+}
@@ -0,0 +1,52 @@
+package clock
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	if !fake.Now().Equal(start) || !fake.Now().Equal(fake.Now()) {
+		t.Fatalf("Now() = %v, want it stopped at %v", fake.Now(), start)
+	}
+	fake.Advance(90 * time.Second)
+	if want := start.Add(90 * time.Second); !fake.Now().Equal(want) {
+		t.Fatalf("Now() after Advance = %v, want %v", fake.Now(), want)
+	}
+	// Set may also move the clock back, e.g. to test skewed timestamps
+	fake.Set(start.Add(-time.Hour))
+	if want := start.Add(-time.Hour); !fake.Now().Equal(want) {
+		t.Fatalf("Now() after Set = %v, want %v", fake.Now(), want)
+	}
+}
+
+func TestFakeConcurrentAdvance(t *testing.T) {
+	start := time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC)
+	fake := NewFake(start)
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fake.Advance(time.Second)
+			fake.Now()
+		}()
+	}
+	wg.Wait()
+	if want := start.Add(50 * time.Second); !fake.Now().Equal(want) {
+		t.Fatalf("Now() = %v, want %v", fake.Now(), want)
+	}
+}
+
+func TestReal(t *testing.T) {
+	before := time.Now()
+	now := NewReal().Now()
+	if now.Before(before) || now.After(time.Now()) {
+		t.Fatalf("Real.Now() = %v, want the system time", now)
+	}
+}
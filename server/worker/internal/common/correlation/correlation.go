@@ -0,0 +1,52 @@
+// Package correlation carries the correlation ID used to tie together logs of one request across services.
+//
+// The ID travels in the x-correlation-id metadata; it is unrelated to OpenTelemetry trace context.
+package correlation
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// MetadataKey is the gRPC metadata key (and HTTP header) carrying the correlation ID
+const MetadataKey = "x-correlation-id"
+
+// maxIDLength bounds IDs accepted from callers so they cannot bloat every log line
+const maxIDLength = 128
+
+type contextKey struct{}
+
+// NewID generates a new correlation ID
+func NewID() string {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.NewString()
+	}
+	return id.String()
+}
+
+// IsValid reports whether an ID received from a caller can be propagated as is
+// Only printable ASCII without spaces is accepted
+func IsValid(id string) bool {
+	if id == "" || len(id) > maxIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// WithID returns a copy of ctx carrying the correlation ID
+func WithID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the correlation ID of the request, or "" when none was set
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
@@ -0,0 +1,96 @@
+// Package emaildomain decides which email domains may register, from an allowlist and a blocklist
+// of domain patterns such as "university.edu" or "*.university.edu".
+package emaildomain
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Policy is a compiled pair of domain allowlist and blocklist
+// An empty allowlist allows every domain; the blocklist wins over the allowlist
+// A Policy is immutable and safe for concurrent use
+type Policy struct {
+	allowed []pattern
+	blocked []pattern
+}
+
+// pattern matches one domain exactly, or with a leading "*." any of its subdomains (but not the domain itself)
+type pattern struct {
+	domain   string
+	wildcard bool
+}
+
+// NewPolicy validates and compiles domain patterns
+func NewPolicy(allowed, blocked []string) (*Policy, error) {
+	p := &Policy{}
+	var err error
+	if p.allowed, err = compile(allowed); err != nil {
+		return nil, err
+	}
+	if p.blocked, err = compile(blocked); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// MustNewPolicy is NewPolicy for patterns already checked by config.Validate; it panics on an invalid pattern
+func MustNewPolicy(allowed, blocked []string) *Policy {
+	p, err := NewPolicy(allowed, blocked)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func compile(values []string) ([]pattern, error) {
+	patterns := make([]pattern, 0, len(values))
+	for _, value := range values {
+		domain := Normalize(value)
+		wildcard := strings.HasPrefix(domain, "*.")
+		if wildcard {
+			domain = domain[2:]
+		}
+		if domain == "" || strings.ContainsAny(domain, "*@ ") || !strings.Contains(domain, ".") {
+			return nil, fmt.Errorf("email domain %q is not valid: expected domain.tld or *.domain.tld", value)
+		}
+		patterns = append(patterns, pattern{domain: domain, wildcard: wildcard})
+	}
+	return patterns, nil
+}
+
+// Normalize lowercases a domain and drops surrounding spaces and a trailing dot
+func Normalize(domain string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(domain)), ".")
+}
+
+// Of returns the normalized domain of an email address, or "" when it has none
+func Of(email string) string {
+	at := strings.LastIndexByte(email, '@')
+	if at < 0 {
+		return ""
+	}
+	return Normalize(email[at+1:])
+}
+
+// Allows reports whether domain may register: it is not blocked, and allowed when an allowlist is set
+func (p *Policy) Allows(domain string) bool {
+	domain = Normalize(domain)
+	if matchesAny(p.blocked, domain) {
+		return false
+	}
+	return len(p.allowed) == 0 || matchesAny(p.allowed, domain)
+}
+
+func matchesAny(patterns []pattern, domain string) bool {
+	for _, pt := range patterns {
+		if pt.wildcard {
+			if strings.HasSuffix(domain, "."+pt.domain) {
+				return true
+			}
+		} else if domain == pt.domain {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package emaildomain
+
+import "testing"
+
+func TestNewPolicy(t *testing.T) {
+	if _, err := NewPolicy([]string{"university.edu", "*.University.EDU."}, []string{"mail.university.edu"}); err != nil {
+		t.Fatalf("NewPolicy: %v", err)
+	}
+	for _, value := range []string{"", "*.", "localhost", "*.*.edu", "uni*.edu", "user@university.edu", "uni versity.edu"} {
+		if _, err := NewPolicy([]string{value}, nil); err == nil {
+			t.Fatalf("NewPolicy(%q) succeeded, want an error", value)
+		}
+		if _, err := NewPolicy(nil, []string{value}); err == nil {
+			t.Fatalf("NewPolicy(nil, %q) succeeded, want an error", value)
+		}
+	}
+}
+
+func TestOf(t *testing.T) {
+	tests := []struct {
+		email string
+		want  string
+	}{
+		{email: "alice@university.edu", want: "university.edu"},
+		{email: "Alice@CS.University.EDU.", want: "cs.university.edu"},
+		// The domain follows the last @, as in a quoted local part
+		{email: `"a@b"@university.edu`, want: "university.edu"},
+		{email: "alice"},
+	}
+	for _, tt := range tests {
+		if got := Of(tt.email); got != tt.want {
+			t.Fatalf("Of(%q) = %q, want %q", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestAllows(t *testing.T) {
+	tests := []struct {
+		name             string
+		allowed, blocked []string
+		domain           string
+		want             bool
+	}{
+		{name: "no lists", domain: "anything.com", want: true},
+		{name: "allowed", allowed: []string{"university.edu"}, domain: "university.edu", want: true},
+		{name: "allowed, normalized", allowed: []string{"University.EDU"}, domain: " UNIVERSITY.edu. ", want: true},
+		{name: "not allowed", allowed: []string{"university.edu"}, domain: "gmail.com"},
+		{name: "allowed, not a subdomain", allowed: []string{"university.edu"}, domain: "cs.university.edu"},
+		{name: "allowed, lookalike", allowed: []string{"university.edu"}, domain: "notuniversity.edu"},
+		{name: "wildcard subdomain", allowed: []string{"*.university.edu"}, domain: "cs.university.edu", want: true},
+		{name: "wildcard nested subdomain", allowed: []string{"*.university.edu"}, domain: "a.cs.university.edu", want: true},
+		{name: "wildcard, not the domain itself", allowed: []string{"*.university.edu"}, domain: "university.edu"},
+		{name: "wildcard, lookalike", allowed: []string{"*.university.edu"}, domain: "evil-university.edu"},
+		{name: "wildcard and domain", allowed: []string{"university.edu", "*.university.edu"}, domain: "university.edu", want: true},
+		{name: "blocked", blocked: []string{"mailinator.com"}, domain: "mailinator.com"},
+		{name: "blocked, other domain", blocked: []string{"mailinator.com"}, domain: "gmail.com", want: true},
+		{name: "blocked wildcard", blocked: []string{"*.mailinator.com"}, domain: "x.mailinator.com"},
+		// The blocklist wins over the allowlist
+		{name: "blocked and allowed", allowed: []string{"*.university.edu"}, blocked: []string{"guest.university.edu"}, domain: "guest.university.edu"},
+		{name: "allowed next to a blocked one", allowed: []string{"*.university.edu"}, blocked: []string{"guest.university.edu"}, domain: "cs.university.edu", want: true},
+		{name: "no domain", allowed: []string{"university.edu"}, domain: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := MustNewPolicy(tt.allowed, tt.blocked)
+			if got := p.Allows(tt.domain); got != tt.want {
+				t.Fatalf("Allows(%q) = %v, want %v", tt.domain, got, tt.want)
+			}
+		})
+	}
+}
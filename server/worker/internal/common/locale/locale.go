@@ -0,0 +1,89 @@
+// Package locale validates user locales against an allowlist of BCP 47 language tags
+// and resolves a locale from an Accept-Language style header.
+package locale
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// Set is an allowlist of BCP 47 language tags; the first tag is the default
+// A Set is immutable and safe for concurrent use
+type Set struct {
+	tags    []language.Tag
+	matcher language.Matcher
+}
+
+// NewSet parses an allowlist of BCP 47 tags such as "en" or "pt-BR"
+func NewSet(codes []string) (*Set, error) {
+	if len(codes) == 0 {
+		return nil, fmt.Errorf("at least one locale is required")
+	}
+	tags := make([]language.Tag, len(codes))
+	for i, code := range codes {
+		tag, err := language.Parse(strings.TrimSpace(code))
+		if err != nil {
+			return nil, fmt.Errorf("locale %q is not a valid BCP 47 tag", code)
+		}
+		tags[i] = tag
+	}
+	return &Set{tags: tags, matcher: language.NewMatcher(tags)}, nil
+}
+
+// MustNewSet is NewSet for allowlists already checked by config.Validate; it panics on an invalid list
+func MustNewSet(codes []string) *Set {
+	s, err := NewSet(codes)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// Default returns the first tag of the allowlist
+func (s *Set) Default() string {
+	return s.tags[0].String()
+}
+
+// Supported returns the allowlisted tags in canonical form
+func (s *Set) Supported() []string {
+	codes := make([]string, len(s.tags))
+	for i, tag := range s.tags {
+		codes[i] = tag.String()
+	}
+	return codes
+}
+
+// Normalize returns the canonical form of value ("EN-us" becomes "en-US")
+// ok is false when value is not a valid tag or not in the allowlist
+func (s *Set) Normalize(value string) (string, bool) {
+	tag, err := language.Parse(strings.TrimSpace(value))
+	if err != nil {
+		return "", false
+	}
+	for _, supported := range s.tags {
+		if supported == tag {
+			return supported.String(), true
+		}
+	}
+	return "", false
+}
+
+// FromAcceptLanguage returns the allowlisted tag that best matches an Accept-Language header
+// ("vi-VN,vi;q=0.9,en;q=0.8"); regional variants fall back to their base language.
+// It returns "" when the header is empty, malformed or matches nothing in the allowlist.
+func (s *Set) FromAcceptLanguage(header string) string {
+	if header == "" {
+		return ""
+	}
+	preferred, _, err := language.ParseAcceptLanguage(header)
+	if err != nil || len(preferred) == 0 {
+		return ""
+	}
+	_, index, confidence := s.matcher.Match(preferred...)
+	if confidence == language.No {
+		return ""
+	}
+	return s.tags[index].String()
+}
@@ -0,0 +1,84 @@
+package locale
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewSet(t *testing.T) {
+	s, err := NewSet([]string{"vi", " en-us ", "pt-BR"})
+	if err != nil {
+		t.Fatalf("NewSet: %v", err)
+	}
+	if s.Default() != "vi" {
+		t.Fatalf("Default() = %s, want vi", s.Default())
+	}
+	if got, want := s.Supported(), []string{"vi", "en-US", "pt-BR"}; !slices.Equal(got, want) {
+		t.Fatalf("Supported() = %v, want %v", got, want)
+	}
+
+	for _, codes := range [][]string{nil, {"en", "not a tag"}, {"en", ""}} {
+		if _, err := NewSet(codes); err == nil {
+			t.Fatalf("NewSet(%q) succeeded, want an error", codes)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	s := MustNewSet([]string{"en", "vi", "en-US"})
+	tests := []struct {
+		value string
+		want  string // Empty when the value is rejected
+	}{
+		{value: "en", want: "en"},
+		{value: "VI", want: "vi"},
+		{value: " en-us ", want: "en-US"},
+		{value: "en_US", want: "en-US"},
+		// Only allowlisted tags are accepted, not their relatives
+		{value: "en-GB"},
+		{value: "vi-VN"},
+		{value: "fr"},
+		{value: "english"},
+		{value: ""},
+	}
+	for _, tt := range tests {
+		got, ok := s.Normalize(tt.value)
+		if ok != (tt.want != "") || got != tt.want {
+			t.Fatalf("Normalize(%q) = %q, %v; want %q", tt.value, got, ok, tt.want)
+		}
+	}
+}
+
+func TestFromAcceptLanguage(t *testing.T) {
+	s := MustNewSet([]string{"en", "vi"})
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{header: "vi", want: "vi"},
+		{header: "vi-VN,vi;q=0.9,en;q=0.8", want: "vi"},
+		{header: "en-GB,en;q=0.9", want: "en"},
+		{header: "fr-FR,fr;q=0.9,vi;q=0.5", want: "vi"},
+		// Quality values decide, not the order
+		{header: "en;q=0.2,vi;q=0.8", want: "vi"},
+		// Nothing to match, or nothing readable
+		{header: "fr-FR,de;q=0.9"},
+		{header: ""},
+		{header: strings.Repeat(";", 10)},
+	}
+	for _, tt := range tests {
+		if got := s.FromAcceptLanguage(tt.header); got != tt.want {
+			t.Fatalf("FromAcceptLanguage(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestMustNewSetPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("MustNewSet accepted an invalid allowlist")
+		}
+	}()
+	MustNewSet([]string{"not a tag"})
+}
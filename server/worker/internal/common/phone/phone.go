@@ -0,0 +1,178 @@
+// Package phone normalizes phone numbers to E.164 (+<country code><national number>).
+//
+// Numbers in international format ("+84 912 345 678", "0084912345678") are accepted for
+// any country code; national-format numbers ("0912 345 678") are interpreted using a
+// default region. Length rules are enforced for the regions listed below and fall back to
+// the generic E.164 limits for other country codes.
+package phone
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrEmpty             = errors.New("phone number is empty")
+	ErrInvalidCharacters = errors.New("phone number contains invalid characters")
+	ErrUnknownRegion     = errors.New("unknown phone region")
+	ErrInvalidLength     = errors.New("phone number has an invalid length")
+	ErrInvalidNumber     = errors.New("phone number is invalid")
+)
+
+const (
+	// E.164 limits the full number (country code + national number) to 15 digits
+	maxE164Digits = 15
+	// Shortest national significant number accepted for regions without specific rules
+	minNationalDigits = 4
+)
+
+// region describes the dialing rules of a country or territory
+type region struct {
+	callingCode string
+	trunkPrefix string // Dialed before national numbers, stripped when normalizing
+	minLength   int    // National significant number length bounds
+	maxLength   int
+}
+
+// regions holds the dialing rules of supported default regions (ISO 3166-1 alpha-2)
+var regions = map[string]region{
+	"AU": {callingCode: "61", trunkPrefix: "0", minLength: 9, maxLength: 9},
+	"CA": {callingCode: "1", trunkPrefix: "1", minLength: 10, maxLength: 10},
+	"CN": {callingCode: "86", trunkPrefix: "0", minLength: 9, maxLength: 11},
+	"DE": {callingCode: "49", trunkPrefix: "0", minLength: 6, maxLength: 13},
+	"FR": {callingCode: "33", trunkPrefix: "0", minLength: 9, maxLength: 9},
+	"GB": {callingCode: "44", trunkPrefix: "0", minLength: 9, maxLength: 10},
+	"ID": {callingCode: "62", trunkPrefix: "0", minLength: 8, maxLength: 12},
+	"IN": {callingCode: "91", trunkPrefix: "0", minLength: 10, maxLength: 10},
+	"JP": {callingCode: "81", trunkPrefix: "0", minLength: 9, maxLength: 10},
+	"KH": {callingCode: "855", trunkPrefix: "0", minLength: 8, maxLength: 9},
+	"KR": {callingCode: "82", trunkPrefix: "0", minLength: 8, maxLength: 10},
+	"LA": {callingCode: "856", trunkPrefix: "0", minLength: 8, maxLength: 10},
+	"MY": {callingCode: "60", trunkPrefix: "0", minLength: 8, maxLength: 10},
+	"PH": {callingCode: "63", trunkPrefix: "0", minLength: 8, maxLength: 10},
+	"SG": {callingCode: "65", minLength: 8, maxLength: 8},
+	"TH": {callingCode: "66", trunkPrefix: "0", minLength: 8, maxLength: 9},
+	"TW": {callingCode: "886", trunkPrefix: "0", minLength: 8, maxLength: 9},
+	"US": {callingCode: "1", trunkPrefix: "1", minLength: 10, maxLength: 10},
+	"VN": {callingCode: "84", trunkPrefix: "0", minLength: 9, maxLength: 10},
+}
+
+// callingCodes maps calling codes to the length rules used when validating international numbers
+// Regions sharing a calling code (NANP) share the same rules
+var callingCodes = func() map[string]region {
+	codes := make(map[string]region, len(regions))
+	for _, r := range regions {
+		codes[r.callingCode] = r
+	}
+	return codes
+}()
+
+// IsSupportedRegion reports whether the region can be used as a default region
+func IsSupportedRegion(code string) bool {
+	_, ok := regions[strings.ToUpper(code)]
+	return ok
+}
+
+// Normalize converts a phone number to E.164, interpreting national-format numbers in defaultRegion
+// Spaces, dashes, dots and parentheses are ignored
+func Normalize(raw, defaultRegion string) (string, error) {
+	digits, international, err := clean(raw)
+	if err != nil {
+		return "", err
+	}
+
+	if international {
+		return normalizeInternational(digits)
+	}
+
+	r, ok := regions[strings.ToUpper(defaultRegion)]
+	if !ok {
+		return "", ErrUnknownRegion
+	}
+
+	national := strings.TrimPrefix(digits, r.trunkPrefix)
+	if err := r.validate(national); err != nil {
+		return "", err
+	}
+	return "+" + r.callingCode + national, nil
+}
+
+// clean strips formatting characters and reports whether the number is in international format
+func clean(raw string) (digits string, international bool, err error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return "", false, ErrEmpty
+	}
+
+	if strings.HasPrefix(raw, "+") {
+		international = true
+		raw = raw[1:]
+	}
+
+	var b strings.Builder
+	for _, c := range raw {
+		switch {
+		case c >= '0' && c <= '9':
+			b.WriteRune(c)
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+		default:
+			return "", false, ErrInvalidCharacters
+		}
+	}
+
+	digits = b.String()
+	if digits == "" {
+		return "", false, ErrEmpty
+	}
+
+	// "00" is the international call prefix used by most countries
+	if !international && strings.HasPrefix(digits, "00") {
+		international = true
+		digits = digits[2:]
+	}
+	return digits, international, nil
+}
+
+func normalizeInternational(digits string) (string, error) {
+	if len(digits) > maxE164Digits {
+		return "", ErrInvalidLength
+	}
+	if digits == "" || digits[0] == '0' {
+		return "", ErrInvalidNumber
+	}
+
+	// Calling codes are prefix-free, so at most one of the 1-3 digit prefixes matches
+	for n := 1; n <= 3 && n < len(digits); n++ {
+		if r, ok := callingCodes[digits[:n]]; ok {
+			if err := r.validate(digits[n:]); err != nil {
+				return "", err
+			}
+			return "+" + digits, nil
+		}
+	}
+
+	// Unlisted calling code: only the generic E.164 bounds apply
+	if len(digits) < minNationalDigits+1 {
+		return "", ErrInvalidLength
+	}
+	return "+" + digits, nil
+}
+
+// validate checks a national significant number against the region's rules
+func (r region) validate(national string) error {
+	if len(national) < r.minLength || len(national) > r.maxLength {
+		return ErrInvalidLength
+	}
+	if len(r.callingCode)+len(national) > maxE164Digits {
+		return ErrInvalidLength
+	}
+	// A leading trunk digit means the number was written as "+<code> 0..."
+	if r.trunkPrefix != "" && strings.HasPrefix(national, r.trunkPrefix) {
+		return ErrInvalidNumber
+	}
+	if r.callingCode == "1" && national[0] < '2' {
+		// NANP area codes start with 2-9
+		return ErrInvalidNumber
+	}
+	return nil
+}
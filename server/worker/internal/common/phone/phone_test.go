@@ -0,0 +1,79 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		raw    string
+		region string
+		want   string
+	}{
+		// National format, read in the default region
+		{raw: "0912 345 678", region: "VN", want: "+84912345678"},
+		{raw: "0912.345.678", region: "vn", want: "+84912345678"},
+		{raw: "(415) 555-2671", region: "US", want: "+14155552671"},
+		{raw: "1 415 555 2671", region: "US", want: "+14155552671"},
+		{raw: "020 7946 0958", region: "GB", want: "+442079460958"},
+		{raw: "6123 4567", region: "SG", want: "+6561234567"},
+		{raw: "  0912345678  ", region: "VN", want: "+84912345678"},
+		// International format, whatever the default region
+		{raw: "+84 912 345 678", region: "US", want: "+84912345678"},
+		{raw: "0084912345678", region: "US", want: "+84912345678"},
+		{raw: "+1 (415) 555-2671", region: "VN", want: "+14155552671"},
+		{raw: "+855 12 345 678", region: "VN", want: "+85512345678"},
+		// Unlisted calling codes only get the generic E.164 bounds
+		{raw: "+358 40 1234567", region: "VN", want: "+358401234567"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := Normalize(tt.raw, tt.region)
+			if err != nil {
+				t.Fatalf("Normalize(%q, %s): %v", tt.raw, tt.region, err)
+			}
+			if got != tt.want {
+				t.Fatalf("Normalize(%q, %s) = %s, want %s", tt.raw, tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		region string
+		want   error
+	}{
+		{name: "empty", raw: "  ", region: "VN", want: ErrEmpty},
+		{name: "formatting only", raw: "( - )", region: "VN", want: ErrEmpty},
+		{name: "letters", raw: "0912-CALL-ME", region: "VN", want: ErrInvalidCharacters},
+		{name: "plus inside", raw: "09+12345678", region: "VN", want: ErrInvalidCharacters},
+		{name: "unknown region", raw: "0912345678", region: "XX", want: ErrUnknownRegion},
+		{name: "too short", raw: "091234", region: "VN", want: ErrInvalidLength},
+		{name: "too long", raw: "091234567890", region: "VN", want: ErrInvalidLength},
+		{name: "over 15 digits", raw: "+3581234567890123", region: "VN", want: ErrInvalidLength},
+		{name: "unlisted code too short", raw: "+3581", region: "VN", want: ErrInvalidLength},
+		{name: "trunk prefix after country code", raw: "+84 0912 345 678", region: "VN", want: ErrInvalidNumber},
+		{name: "leading zero country code", raw: "+0912345678", region: "VN", want: ErrInvalidNumber},
+		{name: "NANP area code starting with 1", raw: "+1 115 555 2671", region: "US", want: ErrInvalidNumber},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Normalize(tt.raw, tt.region)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Normalize(%q, %s) = %q, %v; want %v", tt.raw, tt.region, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSupportedRegion(t *testing.T) {
+	for code, want := range map[string]bool{"VN": true, "us": true, "XX": false, "": false} {
+		if got := IsSupportedRegion(code); got != want {
+			t.Fatalf("IsSupportedRegion(%q) = %t, want %t", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+// Package textnorm normalizes user-entered text so that strings which look the same compare equal.
+//
+// Text is converted to Unicode NFC, so a precomposed "é" (U+00E9) and "e" followed by a
+// combining acute accent (U+0065 U+0301) become the same string.
+package textnorm
+
+import (
+	"strings"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Identifier normalizes a username, email or login identifier: surrounding whitespace is trimmed
+// and the result is NFC-normalized. Inner characters are left alone, an identifier with inner
+// whitespace is for the validation to reject
+func Identifier(s string) string {
+	return norm.NFC.String(strings.TrimSpace(s))
+}
+
+// Name normalizes a display name: runs of whitespace (including tabs and newlines) become a single
+// space, surrounding whitespace is trimmed and the result is NFC-normalized
+func Name(s string) string {
+	return norm.NFC.String(strings.Join(strings.Fields(s), " "))
+}
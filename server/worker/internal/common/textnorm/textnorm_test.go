@@ -0,0 +1,57 @@
+package textnorm
+
+import "testing"
+
+// Decomposed spellings: a base letter followed by combining marks
+const (
+	decomposedJose   = "Jose\u0301"         // "José" with U+0301 COMBINING ACUTE ACCENT
+	decomposedNguyen = "Nguye\u0302\u0303n" // "Nguyễn" with U+0302 and U+0303
+)
+
+func TestIdentifier(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "alice", want: "alice"},
+		{in: "  alice\t\n", want: "alice"},
+		{in: decomposedJose, want: "José"},
+		{in: " " + decomposedJose + "@example.com ", want: "José@example.com"},
+		// Inner whitespace and case are kept for validation and the case policy to handle
+		{in: "al ice", want: "al ice"},
+		{in: "Alice", want: "Alice"},
+		{in: "   ", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Identifier(tt.in); got != tt.want {
+			t.Fatalf("Identifier(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestName(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{in: "Alice Smith", want: "Alice Smith"},
+		{in: "  Alice \t  Smith\n", want: "Alice Smith"},
+		{in: "Alice\u00a0Smith", want: "Alice Smith"}, // No-break space counts as whitespace
+		{in: decomposedNguyen + "  Van A", want: "Nguyễn Van A"},
+		{in: "\t\n", want: ""},
+	}
+	for _, tt := range tests {
+		if got := Name(tt.in); got != tt.want {
+			t.Fatalf("Name(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestNormalizedFormsCompareEqual(t *testing.T) {
+	if Identifier(decomposedJose) != Identifier("José") {
+		t.Fatal("decomposed and precomposed identifiers differ after normalization")
+	}
+	if Name(decomposedNguyen) != Name("Nguyễn") {
+		t.Fatal("decomposed and precomposed names differ after normalization")
+	}
+}
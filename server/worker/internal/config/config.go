@@ -1,10 +1,21 @@
 package config
 
 import (
+	"cmp"
 	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/spf13/viper"
+	"go.uber.org/zap/zapcore"
+
+	"worker/internal/common/emaildomain"
+	"worker/internal/common/locale"
+	"worker/internal/common/phone"
+	"worker/internal/core/domain"
 )
 
 // Config holds all configuration for the worker service
@@ -13,22 +24,69 @@ type Config struct {
 	Database DatabaseConfig
 	JWT      JWTConfig
 	GRPC     GRPCConfig
+	Auth     AuthConfig
+	Storage  StorageConfig
+	Avatar   AvatarConfig
+	CORS     CORSConfig
+	Captcha  CaptchaConfig
+	Notifier NotifierConfig
+	Remote   RemoteConfig
 }
 
 // ServerConfig holds server-related configuration
 type ServerConfig struct {
 	Port string
 	Env  string
+	// LogLevel overrides the environment's default log level (debug, info, warn, error)
+	// It is reloaded at runtime from the remote config source
+	LogLevel string
+	// MaintenanceMode rejects every AuthService RPC but the read-only ones, reloadable at runtime
+	MaintenanceMode bool
 }
 
 // DatabaseConfig holds database connection configuration
 type DatabaseConfig struct {
+	// Backend is STORAGE_BACKEND: postgres, or memory to run without a database (tests and demos only)
+	// The connection settings below are ignored by the memory backend
+	Backend  string
 	Host     string
 	Port     string
 	User     string
 	Password string
 	Name     string
 	SSLMode  string
+	// MaxConns caps the pool size (0 keeps the pgx default of max(4, NumCPU))
+	MaxConns int32
+	// StatsInterval is how often pool statistics are sampled and logged (0 disables sampling)
+	StatsInterval time.Duration
+	// ConnectTimeout bounds each startup ping of the database
+	ConnectTimeout time.Duration
+	// AcquireTimeout bounds waiting for a pooled connection, below the request deadline, so a saturated
+	// pool fails fast with ResourceExhausted; 0 waits until the request deadline
+	AcquireTimeout time.Duration
+	// ConnectAttempts is how many startup pings are tried, with backoff, before startup fails
+	ConnectAttempts int
+	// LastLoginQueueSize is how many last-login updates may wait for the background writer
+	LastLoginQueueSize int
+	// LastLoginBatchSize caps the updates written in one statement
+	LastLoginBatchSize int
+	// LastLoginFlushInterval is how long queued updates may wait before a partial batch is written
+	LastLoginFlushInterval time.Duration
+	// SweepInterval is how often expired sessions and opaque access tokens are deleted (0 disables sweeping)
+	SweepInterval time.Duration
+	// SweepBatchSize caps the rows deleted in one statement, keeping each sweep's locks short
+	SweepBatchSize int32
+	// ReplicaHost enables a read replica for user lookups (FindBy*, Exists*); empty reads everything from the primary
+	// The other replica settings fall back to their primary counterparts when empty
+	ReplicaHost     string
+	ReplicaPort     string
+	ReplicaUser     string
+	ReplicaPassword string
+	ReplicaName     string
+	ReplicaSSLMode  string
+	// ReplicaReadAfterWriteWindow is how long lookups of a user this worker just wrote keep going to the primary,
+	// covering the replica's lag so a user can log in right after registering
+	ReplicaReadAfterWriteWindow time.Duration
 }
 
 // JWTConfig holds JWT-related configuration
@@ -37,11 +95,391 @@ type JWTConfig struct {
 	RefreshSecret     string
 	AccessExpiration  time.Duration
 	RefreshExpiration time.Duration
+
+	// PreviousAccessSecret is still accepted when verifying access tokens, so rotating JWT_ACCESS_SECRET does not
+	// sign everyone out at once; new tokens use AccessSecret. Tokens signed with it are gone after AccessExpiration,
+	// so it can be removed by then
+	PreviousAccessSecret string
+
+	// AccessExpirationByRole overrides AccessExpiration per role code,
+	// set with JWT_ACCESS_EXPIRATION_<ROLE_CODE>, e.g. JWT_ACCESS_EXPIRATION_ADMIN=5m
+	AccessExpirationByRole map[string]time.Duration
 }
 
 // GRPCConfig holds gRPC server configuration
 type GRPCConfig struct {
 	Port string
+	// MaxHandlerDuration is the server-side ceiling on handler execution,
+	// applied when the client deadline is absent or later (0 disables it). A handler that ignores its
+	// context keeps running, and holding its database connection, after the call failed
+	MaxHandlerDuration time.Duration
+	// MethodTimeouts overrides MaxHandlerDuration per method, keyed by full
+	// method name (/auth.AuthService/Login) or bare method name (Login)
+	MethodTimeouts map[string]time.Duration
+	// SendCompressor compresses responses for clients that accept it ("" or gzip);
+	// requests are decompressed whenever the client compresses them
+	SendCompressor string
+	// CompressionMinSize is the response size in bytes below which responses are sent uncompressed
+	CompressionMinSize int
+	// TLSCertFile and TLSKeyFile enable TLS on the gRPC port (both empty serves plaintext)
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSClientCAFile enables mTLS: client certificates are verified against this CA bundle,
+	// and internal methods (ValidateToken, IntrospectToken, ...) require one
+	TLSClientCAFile string
+	// TLSAllowedClients lists the service identities allowed to call internal methods,
+	// matched against the certificate's common name, DNS or URI SANs
+	TLSAllowedClients []string
+	// Interceptors is the unary interceptor chain by name, outermost first; an interceptor left out
+	// is disabled (client_identity also guards streams). Panic recovery is not listed, it always
+	// runs outermost so panics raised by any interceptor are recovered too
+	Interceptors []string
+	// APIVersions lists the x-api-version values the api_version interceptor accepts
+	APIVersions []string
+	// DefaultAPIVersion is assumed for requests without x-api-version, which are logged as deprecated;
+	// empty rejects them instead
+	DefaultAPIVersion string
+}
+
+// Values of GRPC_INTERCEPTORS
+const (
+	GRPCInterceptorCorrelation    = "correlation"
+	GRPCInterceptorClientIdentity = "client_identity"
+	GRPCInterceptorAPIVersion     = "api_version"
+	GRPCInterceptorCompression    = "compression"
+	GRPCInterceptorSanitize       = "sanitize"
+	GRPCInterceptorTimeout        = "timeout"
+	GRPCInterceptorDatabaseBusy   = "database_busy"
+	GRPCInterceptorMaintenance    = "maintenance"
+	GRPCInterceptorTenant         = "tenant"
+	GRPCInterceptorClientInfo     = "client_info"
+)
+
+// DefaultGRPCInterceptors is the default GRPC_INTERCEPTORS chain
+// Correlation comes first so every later interceptor logs with the correlation ID; client identity
+// is checked before any work is done for a caller; the timeout wraps database_busy so a pool wait
+// cut short by the deadline is still reported as DATABASE_BUSY
+var DefaultGRPCInterceptors = []string{
+	GRPCInterceptorCorrelation,
+	GRPCInterceptorClientIdentity,
+	GRPCInterceptorAPIVersion,
+	GRPCInterceptorCompression,
+	GRPCInterceptorSanitize,
+	GRPCInterceptorTimeout,
+	GRPCInterceptorDatabaseBusy,
+	GRPCInterceptorMaintenance,
+	GRPCInterceptorTenant,
+	GRPCInterceptorClientInfo,
+}
+
+// GRPCCompressorGzip is the only response compressor the gRPC server registers
+const GRPCCompressorGzip = "gzip"
+
+// AuthConfig holds authentication business rules configuration
+type AuthConfig struct {
+	// DefaultRoleCode is the role code assigned to newly registered users.
+	// When empty, the role returned by the GetDefaultRole query is used.
+	DefaultRoleCode string
+	// PhoneDefaultRegion is the ISO 3166-1 alpha-2 region used to interpret
+	// phone numbers entered without a country code (e.g. "VN")
+	PhoneDefaultRegion string
+	// PermissionCacheTTL is how long resolved user permissions are cached (0 disables caching)
+	PermissionCacheTTL time.Duration
+	// TokenVersionCacheTTL is how long a user's token version is cached (0 disables caching);
+	// it bounds how long another replica accepts access tokens after they were revoked
+	TokenVersionCacheTTL time.Duration
+	// PasswordMaxAge forces a password change once a password is older than this (0 disables expiry)
+	PasswordMaxAge time.Duration
+	// PasswordChangeTokenExpiration is the lifetime of the token issued for an expired password
+	PasswordChangeTokenExpiration time.Duration
+	// PasswordResetTokenExpiration is the lifetime of the token issued by ForcePasswordReset
+	PasswordResetTokenExpiration time.Duration
+	// ResetTokenType selects what ForcePasswordReset issues: "jwt" issues a stateless reset token,
+	// "otp" issues a short numeric code to send by email or SMS, stored hashed until it is used
+	ResetTokenType string
+	// ResetOTPLength is the number of digits of a reset code, ResetOTPExpiration its lifetime,
+	// and ResetOTPMaxAttempts how many times it may be entered before it is locked
+	ResetOTPLength      int
+	ResetOTPExpiration  time.Duration
+	ResetOTPMaxAttempts int
+	// MultiTenant requires every Login/Register call to name its tenant in the x-tenant-id
+	// metadata and issues access tokens with a per-tenant issuer
+	MultiTenant bool
+	// DefaultTenant is the tenant used for all users when MultiTenant is disabled
+	DefaultTenant string
+	// UsernameChangeCooldown is the minimum time between two username changes of a user (0 disables it)
+	UsernameChangeCooldown time.Duration
+	// RiskFailureWindow is how long failed logins count towards the default risk evaluator's limits
+	RiskFailureWindow time.Duration
+	// RiskChallengeFailures is how many recent failed logins make the next correct login a challenge (0 disables)
+	RiskChallengeFailures int
+	// RiskDenyFailures is how many recent failed logins deny the next correct login (0 disables)
+	RiskDenyFailures int
+	// LoginDelayBase is the delay after the second consecutive failed login of an identifier from an IP,
+	// doubling with every further failure up to LoginDelayMax (0 disables the delays)
+	// Failures are forgotten after RiskFailureWindow without one, or on a successful login
+	LoginDelayBase time.Duration
+	LoginDelayMax  time.Duration
+	// HideInactive makes Login answer an unknown account, a wrong password and a deactivated or unapproved
+	// account alike, with the generic invalid-credentials error after a password comparison, so neither the
+	// response nor its timing reveals that the account exists; the real reason is still logged
+	HideInactive bool
+	// NewUserActive is whether Register activates accounts right away; when false new accounts wait,
+	// without tokens, until an admin activates them with SetUserActive
+	NewUserActive bool
+	// AvailabilityCheckLimit is how many identifiers one caller may check with CheckAvailabilityBatch
+	// per AvailabilityCheckWindow (0 disables the limit); like the login delays it is counted per replica
+	AvailabilityCheckLimit  int
+	AvailabilityCheckWindow time.Duration
+	// PermissionsFailMode decides what ValidateAccessToken returns when the user's permissions cannot be
+	// resolved: "open" reports the token valid with no permissions, "closed" fails the validation
+	PermissionsFailMode string
+	// SessionIdleTimeout expires a session that has not been used for this long, even before its refresh
+	// token expires; the user has to log in again (0 disables it)
+	// Activity is seen on login, refresh and validation of the session's access tokens, the latter at most
+	// once a minute. The timeout may not be shorter than JWT_ACCESS_EXPIRATION, so an access token never
+	// outlives its session's idle timeout
+	SessionIdleTimeout time.Duration
+	// MaxSessions caps how many sessions a user may hold at once, to limit credential sharing (0 disables it)
+	// MaxSessionsPolicy decides what a login past the cap does: "evict_oldest" revokes the oldest sessions,
+	// "reject" refuses the login. Concurrent logins may briefly exceed the cap
+	MaxSessions       int
+	MaxSessionsPolicy string
+	// SuperadminPermissions and SuperadminRoles mark superadmins: a user holding one of these granted
+	// permissions, or one of these role codes, passes every permission check without further matching
+	SuperadminPermissions []string
+	SuperadminRoles       []string
+	// MissingRole decides what happens when a user's primary role cannot be resolved while issuing a token:
+	// "default" logs a warning and puts the default role's code in the token, "reject" refuses the login
+	MissingRole string
+	// SupportedLocales is the allowlist of BCP 47 tags users may pick; the first one is the default
+	// for users whose request names none and whose accept-language matches none
+	SupportedLocales []string
+	// AllowedEmailDomains limits registration to these email domains (empty allows any), and
+	// BlockedEmailDomains refuses these even when allowed; "*.example.com" matches subdomains only
+	AllowedEmailDomains []string
+	BlockedEmailDomains []string
+	// TokenType selects the access token format: "jwt" issues self-contained JWTs, "opaque" issues
+	// random reference tokens whose claims are stored server-side and can be revoked instantly
+	TokenType string
+	// EmbedPermissions puts the user's permissions in a perms claim of access tokens, so gateways can
+	// authorize without calling back; tokens then carry the permissions of their issue time until they expire
+	// Users with more than EmbedPermissionsMax permissions get no claim and are resolved from the database
+	EmbedPermissions    bool
+	EmbedPermissionsMax int
+	// PermissionsPageMax caps how many permissions one GetMyPermissions response lists; a user holding
+	// more gets them page by page, with a next cursor and the total telling the list is not complete
+	PermissionsPageMax int
+	// SubjectFormat is the template of the sub claim of issued tokens, e.g. "urn:worker:user:{user_id}"
+	// It must contain SubjectUserIDPlaceholder exactly once; the default is the bare user ID
+	SubjectFormat string
+	// LoginIdentifier decides what Login accepts: "email", "username" or "both"
+	// An identifier containing "@" is taken for an email address, anything else for a username
+	LoginIdentifier string
+	// UsernameCase decides how usernames are stored and compared: "preserve" keeps them as typed,
+	// "lower" lowercases them on registration, rename and lookup and checks uniqueness ignoring case.
+	// Usernames stored with capitals before switching to "lower" cannot sign in by username until they are
+	// lowercased in the database (UPDATE users SET username = LOWER(username))
+	UsernameCase string
+	// DeletionGracePeriod is how long an account scheduled for deletion can still be restored
+	DeletionGracePeriod time.Duration
+	// DeletionSweepInterval is how often accounts past their grace period are erased
+	DeletionSweepInterval time.Duration
+	// ScheduledDeletionLogin decides what Login does for an account scheduled for deletion:
+	// "warn" signs the user in and reports the schedule, "block" refuses until the deletion is cancelled
+	ScheduledDeletionLogin string
+	// MaxEmailLength, MaxUsernameLength and MaxFullNameLength cap user-entered fields in characters,
+	// so overlong input is rejected as invalid instead of failing in the database
+	MaxEmailLength    int
+	MaxUsernameLength int
+	MaxFullNameLength int
+	// PasswordHasher selects the algorithm of new password hashes: "bcrypt" or "argon2id"
+	// Hashes of the other algorithm still verify and are rehashed on the user's next login
+	PasswordHasher string
+	// BcryptCost is the cost of new bcrypt hashes (0 uses bcrypt's default, or the calibrated cost)
+	BcryptCost int
+	// BcryptPrehash lets passwords exceed bcrypt's 72-byte limit: longer ones are hashed as bcrypt of their
+	// base64 SHA-256, marked with a "$bcrypt-sha256$" prefix. Those hashes keep verifying after the flag is
+	// turned off, but new passwords are then limited to 72 bytes again. Plain hashes of long passwords stored
+	// before passwords were limited only match on the first 72 bytes; with the flag on they are rehashed on
+	// the user's next login. Passwords up to 72 bytes keep plain bcrypt hashes either way
+	BcryptPrehash bool
+	// Argon2Memory (KiB), Argon2Iterations and Argon2Parallelism tune argon2id hashes
+	// Argon2Iterations 0 uses 2 iterations, or the calibrated number
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	// HashCalibrationTarget enables a startup benchmark picking the bcrypt cost or argon2id iterations
+	// (whichever AUTH_PASSWORD_HASHER uses and is left at 0) so one hash takes about this long (0 disables)
+	HashCalibrationTarget time.Duration
+}
+
+// StorageConfig holds S3-compatible object storage (S3/MinIO) configuration
+// When Endpoint is empty an in-memory store is used (local development only)
+type StorageConfig struct {
+	Endpoint     string // e.g. https://s3.amazonaws.com or http://localhost:9000
+	Region       string
+	Bucket       string
+	AccessKey    string
+	SecretKey    string
+	PublicURL    string // Base URL objects are publicly served from
+	UsePathStyle bool   // Required by MinIO
+}
+
+// AvatarConfig holds avatar upload constraints
+type AvatarConfig struct {
+	MaxSize             int64 // Bytes
+	AllowedContentTypes []string
+	UploadExpiration    time.Duration
+}
+
+// CORSConfig holds the CORS policy of the HTTP server
+// No origins are allowed by default; origin patterns are validated at startup
+type CORSConfig struct {
+	AllowedOrigins   []string // "https://app.example.com", "https://*.example.com" or "*"
+	AllowedMethods   []string
+	AllowedHeaders   []string
+	ExposedHeaders   []string
+	AllowCredentials bool
+	MaxAge           time.Duration // Preflight cache duration
+}
+
+// Widths of the users columns, the upper bounds of the AUTH_MAX_*_LENGTH settings
+const (
+	emailColumnLength    = 255
+	usernameColumnLength = 50
+)
+
+// Values of STORAGE_BACKEND
+const (
+	StorageBackendPostgres = "postgres"
+	StorageBackendMemory   = "memory"
+)
+
+// Values of AUTH_PERMISSIONS_FAIL_MODE
+const (
+	PermissionsFailOpen   = "open"
+	PermissionsFailClosed = "closed"
+)
+
+// Values of AUTH_MISSING_ROLE
+const (
+	MissingRoleDefault = "default"
+	MissingRoleReject  = "reject"
+)
+
+// Values of AUTH_TOKEN_TYPE
+const (
+	TokenTypeJWT    = "jwt"
+	TokenTypeOpaque = "opaque"
+)
+
+// Values of AUTH_RESET_TOKEN_TYPE
+const (
+	ResetTokenTypeJWT = "jwt"
+	ResetTokenTypeOTP = "otp"
+)
+
+// Bounds of AUTH_RESET_OTP_LENGTH
+const (
+	minResetOTPLength = 4
+	maxResetOTPLength = 10
+)
+
+// SubjectUserIDPlaceholder is replaced by the user ID in AUTH_SUBJECT_FORMAT
+const SubjectUserIDPlaceholder = "{user_id}"
+
+// Values of AUTH_LOGIN_IDENTIFIER
+const (
+	LoginIdentifierEmail    = "email"
+	LoginIdentifierUsername = "username"
+	LoginIdentifierBoth     = "both"
+)
+
+// Values of AUTH_USERNAME_CASE
+const (
+	UsernameCasePreserve = "preserve"
+	UsernameCaseLower    = "lower"
+)
+
+// Values of AUTH_PASSWORD_HASHER
+const (
+	PasswordHasherBcrypt   = "bcrypt"
+	PasswordHasherArgon2id = "argon2id"
+)
+
+// Bounds of AUTH_BCRYPT_COST, those of golang.org/x/crypto/bcrypt
+const (
+	minBcryptCost = 4
+	maxBcryptCost = 31
+)
+
+// Values of AUTH_SCHEDULED_DELETION_LOGIN
+const (
+	ScheduledDeletionLoginWarn  = "warn"
+	ScheduledDeletionLoginBlock = "block"
+)
+
+// Values of AUTH_MAX_SESSIONS_POLICY
+const (
+	MaxSessionsPolicyEvictOldest = "evict_oldest"
+	MaxSessionsPolicyReject      = "reject"
+)
+
+// CAPTCHA providers supported by the captcha adapter
+const (
+	CaptchaProviderRecaptcha = "recaptcha"
+	CaptchaProviderHcaptcha  = "hcaptcha"
+)
+
+// CaptchaConfig enables CAPTCHA verification of Register and Login
+// When Provider is empty no CAPTCHA token is required
+type CaptchaConfig struct {
+	Provider string // "recaptcha" or "hcaptcha", empty disables CAPTCHA
+	Secret   string // Server-side secret key issued by the provider
+	Timeout  time.Duration
+}
+
+// Enabled reports whether Register and Login require a CAPTCHA token
+func (c *CaptchaConfig) Enabled() bool {
+	return c.Provider != ""
+}
+
+// NotifierConfig enables the webhook notified of account events such as registrations
+// When WebhookURL is empty events are dropped
+type NotifierConfig struct {
+	WebhookURL    string // Receives one JSON POST per event, e.g. the mailer sending welcome emails
+	WebhookSecret string // Optional, signs each body with HMAC-SHA256 in the X-Signature header
+	Timeout       time.Duration
+}
+
+// Enabled reports whether account events are delivered
+func (c *NotifierConfig) Enabled() bool {
+	return c.WebhookURL != ""
+}
+
+// RemoteConfig locates an optional remote KV source (Consul or etcd v3) holding configuration
+// Values from it are read at startup; only DynamicConfig settings are reloaded while running
+type RemoteConfig struct {
+	Provider      string // "consul" or "etcd3", empty disables the remote source
+	Endpoint      string // e.g. "http://consul:8500"
+	Path          string // Key holding a YAML or JSON document
+	WatchInterval time.Duration
+}
+
+// Validate checks that the remote source is fully specified
+func (r *RemoteConfig) Validate() error {
+	if r.Provider != RemoteProviderConsul && r.Provider != RemoteProviderEtcd3 {
+		return fmt.Errorf("CONFIG_REMOTE_PROVIDER %q is not supported (use %s or %s)",
+			r.Provider, RemoteProviderConsul, RemoteProviderEtcd3)
+	}
+	if r.Endpoint == "" || r.Path == "" {
+		return fmt.Errorf("CONFIG_REMOTE_ENDPOINT and CONFIG_REMOTE_PATH are required when CONFIG_REMOTE_PROVIDER is set")
+	}
+	if r.WatchInterval <= 0 {
+		return fmt.Errorf("CONFIG_REMOTE_WATCH_INTERVAL must be positive")
+	}
+	return nil
 }
 
 // LoadConfig loads configuration from environment variables and config files
@@ -69,28 +507,180 @@ func LoadConfig() (*Config, error) {
 		// Config file not found is okay, we use env vars and defaults
 	}
 
+	// Read the remote KV source (optional); env vars and the config file take precedence over it
+	remote := RemoteConfig{
+		Provider:      viper.GetString("CONFIG_REMOTE_PROVIDER"),
+		Endpoint:      viper.GetString("CONFIG_REMOTE_ENDPOINT"),
+		Path:          viper.GetString("CONFIG_REMOTE_PATH"),
+		WatchInterval: viper.GetDuration("CONFIG_REMOTE_WATCH_INTERVAL"),
+	}
+	if remote.Provider != "" {
+		if err := remote.Validate(); err != nil {
+			return nil, err
+		}
+		viper.RemoteConfig = newKVClient(remote.WatchInterval)
+		if err := viper.AddRemoteProvider(remote.Provider, remote.Endpoint, remote.Path); err != nil {
+			return nil, fmt.Errorf("invalid remote config provider: %w", err)
+		}
+		if err := viper.ReadRemoteConfig(); err != nil {
+			return nil, fmt.Errorf("error reading remote config: %w", err)
+		}
+	}
+
+	methodTimeouts, err := parseDurationMap(viper.GetString("GRPC_METHOD_TIMEOUTS"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid GRPC_METHOD_TIMEOUTS: %w", err)
+	}
+
+	accessExpirationByRole, err := parseRoleExpirations()
+	if err != nil {
+		return nil, err
+	}
+
 	config := &Config{
 		Server: ServerConfig{
-			Port: viper.GetString("SERVER_PORT"),
-			Env:  viper.GetString("SERVER_ENV"),
+			Port:            viper.GetString("SERVER_PORT"),
+			Env:             viper.GetString("SERVER_ENV"),
+			LogLevel:        viper.GetString("LOG_LEVEL"),
+			MaintenanceMode: viper.GetBool("MAINTENANCE_MODE"),
 		},
 		Database: DatabaseConfig{
-			Host:     viper.GetString("DB_HOST"),
-			Port:     viper.GetString("DB_PORT"),
-			User:     viper.GetString("DB_USER"),
-			Password: viper.GetString("DB_PASSWORD"),
-			Name:     viper.GetString("DB_NAME"),
-			SSLMode:  viper.GetString("DB_SSL_MODE"),
+			Backend:                     StorageBackend(),
+			Host:                        viper.GetString("DB_HOST"),
+			Port:                        viper.GetString("DB_PORT"),
+			User:                        viper.GetString("DB_USER"),
+			Password:                    viper.GetString("DB_PASSWORD"),
+			Name:                        viper.GetString("DB_NAME"),
+			SSLMode:                     viper.GetString("DB_SSL_MODE"),
+			MaxConns:                    viper.GetInt32("DB_MAX_CONNS"),
+			StatsInterval:               viper.GetDuration("DB_STATS_INTERVAL"),
+			ConnectTimeout:              viper.GetDuration("DB_CONNECT_TIMEOUT"),
+			AcquireTimeout:              viper.GetDuration("DB_ACQUIRE_TIMEOUT"),
+			ConnectAttempts:             viper.GetInt("DB_CONNECT_ATTEMPTS"),
+			LastLoginQueueSize:          viper.GetInt("DB_LAST_LOGIN_QUEUE_SIZE"),
+			LastLoginBatchSize:          viper.GetInt("DB_LAST_LOGIN_BATCH_SIZE"),
+			LastLoginFlushInterval:      viper.GetDuration("DB_LAST_LOGIN_FLUSH_INTERVAL"),
+			SweepInterval:               viper.GetDuration("DB_SWEEP_INTERVAL"),
+			SweepBatchSize:              viper.GetInt32("DB_SWEEP_BATCH_SIZE"),
+			ReplicaHost:                 viper.GetString("DB_REPLICA_HOST"),
+			ReplicaPort:                 viper.GetString("DB_REPLICA_PORT"),
+			ReplicaUser:                 viper.GetString("DB_REPLICA_USER"),
+			ReplicaPassword:             viper.GetString("DB_REPLICA_PASSWORD"),
+			ReplicaName:                 viper.GetString("DB_REPLICA_NAME"),
+			ReplicaSSLMode:              viper.GetString("DB_REPLICA_SSL_MODE"),
+			ReplicaReadAfterWriteWindow: viper.GetDuration("DB_REPLICA_READ_AFTER_WRITE_WINDOW"),
 		},
 		JWT: JWTConfig{
-			AccessSecret:      viper.GetString("JWT_ACCESS_SECRET"),
-			RefreshSecret:     viper.GetString("JWT_REFRESH_SECRET"),
-			AccessExpiration:  viper.GetDuration("JWT_ACCESS_EXPIRATION"),
-			RefreshExpiration: viper.GetDuration("JWT_REFRESH_EXPIRATION"),
+			AccessSecret:           viper.GetString("JWT_ACCESS_SECRET"),
+			RefreshSecret:          viper.GetString("JWT_REFRESH_SECRET"),
+			PreviousAccessSecret:   viper.GetString("JWT_PREVIOUS_ACCESS_SECRET"),
+			AccessExpiration:       viper.GetDuration("JWT_ACCESS_EXPIRATION"),
+			RefreshExpiration:      viper.GetDuration("JWT_REFRESH_EXPIRATION"),
+			AccessExpirationByRole: accessExpirationByRole,
 		},
 		GRPC: GRPCConfig{
-			Port: viper.GetString("GRPC_PORT"),
+			Port:               viper.GetString("GRPC_PORT"),
+			MaxHandlerDuration: viper.GetDuration("GRPC_MAX_HANDLER_DURATION"),
+			MethodTimeouts:     methodTimeouts,
+			SendCompressor:     viper.GetString("GRPC_SEND_COMPRESSOR"),
+			CompressionMinSize: viper.GetInt("GRPC_COMPRESSION_MIN_SIZE"),
+			TLSCertFile:        viper.GetString("GRPC_TLS_CERT_FILE"),
+			TLSKeyFile:         viper.GetString("GRPC_TLS_KEY_FILE"),
+			TLSClientCAFile:    viper.GetString("GRPC_TLS_CLIENT_CA_FILE"),
+			TLSAllowedClients:  splitList(viper.GetString("GRPC_TLS_ALLOWED_CLIENTS")),
+			Interceptors:       splitList(strings.ToLower(viper.GetString("GRPC_INTERCEPTORS"))),
+			APIVersions:        splitList(strings.ToLower(viper.GetString("GRPC_API_VERSIONS"))),
+			DefaultAPIVersion:  strings.ToLower(strings.TrimSpace(viper.GetString("GRPC_DEFAULT_API_VERSION"))),
 		},
+		Auth: AuthConfig{
+			DefaultRoleCode:      viper.GetString("AUTH_DEFAULT_ROLE_CODE"),
+			PhoneDefaultRegion:   viper.GetString("PHONE_DEFAULT_REGION"),
+			PermissionCacheTTL:   viper.GetDuration("PERMISSION_CACHE_TTL"),
+			TokenVersionCacheTTL: viper.GetDuration("TOKEN_VERSION_CACHE_TTL"),
+			PasswordMaxAge:       viper.GetDuration("AUTH_PASSWORD_MAX_AGE"),
+
+			PasswordChangeTokenExpiration: viper.GetDuration("AUTH_PASSWORD_CHANGE_TOKEN_EXPIRATION"),
+			PasswordResetTokenExpiration:  viper.GetDuration("AUTH_PASSWORD_RESET_TOKEN_EXPIRATION"),
+			ResetTokenType:                strings.ToLower(viper.GetString("AUTH_RESET_TOKEN_TYPE")),
+			ResetOTPLength:                viper.GetInt("AUTH_RESET_OTP_LENGTH"),
+			ResetOTPExpiration:            viper.GetDuration("AUTH_RESET_OTP_EXPIRATION"),
+			ResetOTPMaxAttempts:           viper.GetInt("AUTH_RESET_OTP_MAX_ATTEMPTS"),
+			MultiTenant:                   viper.GetBool("AUTH_MULTI_TENANT"),
+			DefaultTenant:                 viper.GetString("AUTH_DEFAULT_TENANT"),
+			UsernameChangeCooldown:        viper.GetDuration("AUTH_USERNAME_CHANGE_COOLDOWN"),
+			RiskFailureWindow:             viper.GetDuration("AUTH_RISK_FAILURE_WINDOW"),
+			RiskChallengeFailures:         viper.GetInt("AUTH_RISK_CHALLENGE_FAILURES"),
+			RiskDenyFailures:              viper.GetInt("AUTH_RISK_DENY_FAILURES"),
+			LoginDelayBase:                viper.GetDuration("AUTH_LOGIN_DELAY_BASE"),
+			LoginDelayMax:                 viper.GetDuration("AUTH_LOGIN_DELAY_MAX"),
+			HideInactive:                  viper.GetBool("AUTH_HIDE_INACTIVE"),
+			NewUserActive:                 viper.GetBool("AUTH_NEW_USER_ACTIVE"),
+			AvailabilityCheckLimit:        viper.GetInt("AUTH_AVAILABILITY_CHECK_LIMIT"),
+			AvailabilityCheckWindow:       viper.GetDuration("AUTH_AVAILABILITY_CHECK_WINDOW"),
+			PermissionsFailMode:           strings.ToLower(viper.GetString("AUTH_PERMISSIONS_FAIL_MODE")),
+			SessionIdleTimeout:            viper.GetDuration("AUTH_SESSION_IDLE_TIMEOUT"),
+			MaxSessions:                   viper.GetInt("AUTH_MAX_SESSIONS"),
+			MaxSessionsPolicy:             strings.ToLower(viper.GetString("AUTH_MAX_SESSIONS_POLICY")),
+			SuperadminPermissions:         splitList(viper.GetString("AUTH_SUPERADMIN_PERMISSIONS")),
+			SuperadminRoles:               splitList(strings.ToUpper(viper.GetString("AUTH_SUPERADMIN_ROLES"))),
+			MissingRole:                   strings.ToLower(viper.GetString("AUTH_MISSING_ROLE")),
+			SupportedLocales:              splitList(viper.GetString("AUTH_SUPPORTED_LOCALES")),
+			AllowedEmailDomains:           splitList(viper.GetString("AUTH_ALLOWED_EMAIL_DOMAINS")),
+			BlockedEmailDomains:           splitList(viper.GetString("AUTH_BLOCKED_EMAIL_DOMAINS")),
+			TokenType:                     strings.ToLower(viper.GetString("AUTH_TOKEN_TYPE")),
+			EmbedPermissions:              viper.GetBool("AUTH_EMBED_PERMISSIONS"),
+			EmbedPermissionsMax:           viper.GetInt("AUTH_EMBED_PERMISSIONS_MAX"),
+			PermissionsPageMax:            viper.GetInt("AUTH_PERMISSIONS_PAGE_MAX"),
+			SubjectFormat:                 viper.GetString("AUTH_SUBJECT_FORMAT"),
+			LoginIdentifier:               strings.ToLower(viper.GetString("AUTH_LOGIN_IDENTIFIER")),
+			UsernameCase:                  strings.ToLower(viper.GetString("AUTH_USERNAME_CASE")),
+			DeletionGracePeriod:           viper.GetDuration("AUTH_DELETION_GRACE_PERIOD"),
+			DeletionSweepInterval:         viper.GetDuration("AUTH_DELETION_SWEEP_INTERVAL"),
+			ScheduledDeletionLogin:        strings.ToLower(viper.GetString("AUTH_SCHEDULED_DELETION_LOGIN")),
+			MaxEmailLength:                viper.GetInt("AUTH_MAX_EMAIL_LENGTH"),
+			MaxUsernameLength:             viper.GetInt("AUTH_MAX_USERNAME_LENGTH"),
+			MaxFullNameLength:             viper.GetInt("AUTH_MAX_FULL_NAME_LENGTH"),
+			PasswordHasher:                strings.ToLower(viper.GetString("AUTH_PASSWORD_HASHER")),
+			Argon2Memory:                  viper.GetUint32("AUTH_ARGON2_MEMORY"),
+			Argon2Iterations:              viper.GetUint32("AUTH_ARGON2_ITERATIONS"),
+			Argon2Parallelism:             uint8(min(viper.GetUint32("AUTH_ARGON2_PARALLELISM"), 255)),
+			BcryptCost:                    viper.GetInt("AUTH_BCRYPT_COST"),
+			BcryptPrehash:                 viper.GetBool("AUTH_BCRYPT_PREHASH"),
+			HashCalibrationTarget:         viper.GetDuration("AUTH_HASH_CALIBRATION_TARGET"),
+		},
+		Storage: StorageConfig{
+			Endpoint:     viper.GetString("S3_ENDPOINT"),
+			Region:       viper.GetString("S3_REGION"),
+			Bucket:       viper.GetString("S3_BUCKET"),
+			AccessKey:    viper.GetString("S3_ACCESS_KEY"),
+			SecretKey:    viper.GetString("S3_SECRET_KEY"),
+			PublicURL:    viper.GetString("S3_PUBLIC_URL"),
+			UsePathStyle: viper.GetBool("S3_USE_PATH_STYLE"),
+		},
+		Avatar: AvatarConfig{
+			MaxSize:             viper.GetInt64("AVATAR_MAX_SIZE"),
+			AllowedContentTypes: splitList(viper.GetString("AVATAR_ALLOWED_CONTENT_TYPES")),
+			UploadExpiration:    viper.GetDuration("AVATAR_UPLOAD_EXPIRATION"),
+		},
+		CORS: CORSConfig{
+			AllowedOrigins:   splitList(viper.GetString("CORS_ALLOWED_ORIGINS")),
+			AllowedMethods:   splitList(strings.ToUpper(viper.GetString("CORS_ALLOWED_METHODS"))),
+			AllowedHeaders:   splitList(viper.GetString("CORS_ALLOWED_HEADERS")),
+			ExposedHeaders:   splitList(viper.GetString("CORS_EXPOSED_HEADERS")),
+			AllowCredentials: viper.GetBool("CORS_ALLOW_CREDENTIALS"),
+			MaxAge:           viper.GetDuration("CORS_MAX_AGE"),
+		},
+		Captcha: CaptchaConfig{
+			Provider: strings.ToLower(viper.GetString("CAPTCHA_PROVIDER")),
+			Secret:   viper.GetString("CAPTCHA_SECRET"),
+			Timeout:  viper.GetDuration("CAPTCHA_TIMEOUT"),
+		},
+		Notifier: NotifierConfig{
+			WebhookURL:    viper.GetString("NOTIFY_WEBHOOK_URL"),
+			WebhookSecret: viper.GetString("NOTIFY_WEBHOOK_SECRET"),
+			Timeout:       viper.GetDuration("NOTIFY_TIMEOUT"),
+		},
+		Remote: remote,
 	}
 
 	// Validate required configuration
@@ -105,22 +695,129 @@ func LoadConfig() (*Config, error) {
 func setDefaults() {
 	viper.SetDefault("SERVER_PORT", "8080")
 	viper.SetDefault("SERVER_ENV", "development")
+	viper.SetDefault("LOG_LEVEL", "")
+	viper.SetDefault("MAINTENANCE_MODE", false)
 
 	viper.SetDefault("DB_HOST", "localhost")
 	viper.SetDefault("DB_PORT", "5432")
 	viper.SetDefault("DB_SSL_MODE", "disable")
+	viper.SetDefault("DB_MAX_CONNS", 0)
+	viper.SetDefault("DB_STATS_INTERVAL", time.Minute)
+	viper.SetDefault("DB_CONNECT_TIMEOUT", 10*time.Second)
+	viper.SetDefault("DB_CONNECT_ATTEMPTS", 5)
+	viper.SetDefault("DB_ACQUIRE_TIMEOUT", 2*time.Second)
+	viper.SetDefault("DB_LAST_LOGIN_QUEUE_SIZE", 1024)
+	viper.SetDefault("DB_LAST_LOGIN_BATCH_SIZE", 100)
+	viper.SetDefault("DB_LAST_LOGIN_FLUSH_INTERVAL", time.Second)
+	viper.SetDefault("DB_SWEEP_INTERVAL", time.Hour)
+	viper.SetDefault("DB_SWEEP_BATCH_SIZE", 1000)
+	viper.SetDefault("DB_REPLICA_READ_AFTER_WRITE_WINDOW", 5*time.Second)
 
 	// JWT defaults: 15 minutes for access, 7 days for refresh
 	viper.SetDefault("JWT_ACCESS_EXPIRATION", 15*time.Minute)
 	viper.SetDefault("JWT_REFRESH_EXPIRATION", 7*24*time.Hour)
 
 	viper.SetDefault("GRPC_PORT", "50051")
+	viper.SetDefault("GRPC_MAX_HANDLER_DURATION", 30*time.Second)
+	viper.SetDefault("GRPC_SEND_COMPRESSOR", "")
+	viper.SetDefault("GRPC_COMPRESSION_MIN_SIZE", 1024)
+	viper.SetDefault("GRPC_TLS_CERT_FILE", "")
+	viper.SetDefault("GRPC_TLS_KEY_FILE", "")
+	viper.SetDefault("GRPC_TLS_CLIENT_CA_FILE", "")
+	viper.SetDefault("GRPC_TLS_ALLOWED_CLIENTS", "")
+	viper.SetDefault("GRPC_INTERCEPTORS", strings.Join(DefaultGRPCInterceptors, ","))
+	viper.SetDefault("GRPC_API_VERSIONS", "v1")
+	viper.SetDefault("GRPC_DEFAULT_API_VERSION", "v1")
+
+	viper.SetDefault("AUTH_DEFAULT_ROLE_CODE", "STUDENT")
+	viper.SetDefault("PHONE_DEFAULT_REGION", "VN")
+	viper.SetDefault("PERMISSION_CACHE_TTL", time.Minute)
+	viper.SetDefault("TOKEN_VERSION_CACHE_TTL", 30*time.Second)
+	viper.SetDefault("AUTH_PASSWORD_MAX_AGE", 0)
+	viper.SetDefault("AUTH_PASSWORD_CHANGE_TOKEN_EXPIRATION", 10*time.Minute)
+	viper.SetDefault("AUTH_PASSWORD_RESET_TOKEN_EXPIRATION", 24*time.Hour)
+	viper.SetDefault("AUTH_RESET_TOKEN_TYPE", ResetTokenTypeJWT)
+	viper.SetDefault("AUTH_RESET_OTP_LENGTH", 6)
+	viper.SetDefault("AUTH_RESET_OTP_EXPIRATION", 15*time.Minute)
+	viper.SetDefault("AUTH_RESET_OTP_MAX_ATTEMPTS", 5)
+	viper.SetDefault("AUTH_MULTI_TENANT", false)
+	viper.SetDefault("AUTH_USERNAME_CHANGE_COOLDOWN", 30*24*time.Hour)
+	viper.SetDefault("AUTH_DEFAULT_TENANT", domain.DefaultTenantID)
+	viper.SetDefault("AUTH_RISK_FAILURE_WINDOW", 15*time.Minute)
+	viper.SetDefault("AUTH_RISK_CHALLENGE_FAILURES", 5)
+	viper.SetDefault("AUTH_RISK_DENY_FAILURES", 20)
+	viper.SetDefault("AUTH_LOGIN_DELAY_BASE", time.Second)
+	viper.SetDefault("AUTH_LOGIN_DELAY_MAX", 30*time.Second)
+	viper.SetDefault("AUTH_HIDE_INACTIVE", false)
+	viper.SetDefault("AUTH_NEW_USER_ACTIVE", true)
+	viper.SetDefault("AUTH_AVAILABILITY_CHECK_LIMIT", 1000)
+	viper.SetDefault("AUTH_AVAILABILITY_CHECK_WINDOW", time.Minute)
+	viper.SetDefault("AUTH_PERMISSIONS_FAIL_MODE", PermissionsFailOpen)
+	viper.SetDefault("AUTH_SESSION_IDLE_TIMEOUT", 0)
+	viper.SetDefault("AUTH_MAX_SESSIONS", 0)
+	viper.SetDefault("AUTH_MAX_SESSIONS_POLICY", MaxSessionsPolicyEvictOldest)
+	viper.SetDefault("AUTH_SUPERADMIN_PERMISSIONS", "*:*")
+	viper.SetDefault("AUTH_SUPERADMIN_ROLES", "")
+	viper.SetDefault("AUTH_MISSING_ROLE", MissingRoleDefault)
+	viper.SetDefault("AUTH_TOKEN_TYPE", TokenTypeJWT)
+	viper.SetDefault("AUTH_EMBED_PERMISSIONS", false)
+	viper.SetDefault("AUTH_EMBED_PERMISSIONS_MAX", 50)
+	viper.SetDefault("AUTH_PERMISSIONS_PAGE_MAX", 500)
+	viper.SetDefault("AUTH_SUBJECT_FORMAT", SubjectUserIDPlaceholder)
+	viper.SetDefault("AUTH_LOGIN_IDENTIFIER", LoginIdentifierBoth)
+	viper.SetDefault("AUTH_USERNAME_CASE", UsernameCasePreserve)
+	viper.SetDefault("AUTH_DELETION_GRACE_PERIOD", 30*24*time.Hour)
+	viper.SetDefault("AUTH_DELETION_SWEEP_INTERVAL", time.Hour)
+	viper.SetDefault("AUTH_SCHEDULED_DELETION_LOGIN", ScheduledDeletionLoginWarn)
+	viper.SetDefault("AUTH_MAX_EMAIL_LENGTH", emailColumnLength)
+	viper.SetDefault("AUTH_MAX_USERNAME_LENGTH", usernameColumnLength)
+	viper.SetDefault("AUTH_MAX_FULL_NAME_LENGTH", 200)
+	// Argon2id defaults follow the OWASP minimum: 19 MiB, 2 iterations (AUTH_ARGON2_ITERATIONS=0), 1 lane
+	viper.SetDefault("AUTH_PASSWORD_HASHER", PasswordHasherBcrypt)
+	viper.SetDefault("AUTH_ARGON2_MEMORY", 19*1024)
+	viper.SetDefault("AUTH_ARGON2_ITERATIONS", 0)
+	viper.SetDefault("AUTH_ARGON2_PARALLELISM", 1)
+	viper.SetDefault("AUTH_BCRYPT_COST", 0)
+	viper.SetDefault("AUTH_BCRYPT_PREHASH", false)
+	viper.SetDefault("AUTH_HASH_CALIBRATION_TARGET", 0)
+	viper.SetDefault("AUTH_SUPPORTED_LOCALES", "en,vi")
+	viper.SetDefault("AUTH_ALLOWED_EMAIL_DOMAINS", "")
+	viper.SetDefault("AUTH_BLOCKED_EMAIL_DOMAINS", "")
+
+	viper.SetDefault("S3_REGION", "us-east-1")
+	viper.SetDefault("S3_BUCKET", "avatars")
+	viper.SetDefault("S3_USE_PATH_STYLE", true)
+
+	// Avatar defaults: 5 MB, common image types, 15 minutes to upload
+	viper.SetDefault("AVATAR_MAX_SIZE", 5*1024*1024)
+	viper.SetDefault("AVATAR_ALLOWED_CONTENT_TYPES", "image/jpeg,image/png,image/webp")
+	viper.SetDefault("AVATAR_UPLOAD_EXPIRATION", 15*time.Minute)
+
+	// CORS defaults: no cross-origin access until origins are configured
+	viper.SetDefault("CORS_ALLOWED_ORIGINS", "")
+	viper.SetDefault("CORS_ALLOWED_METHODS", "GET,POST,PUT,PATCH,DELETE")
+	viper.SetDefault("CORS_ALLOWED_HEADERS", "Authorization,Content-Type")
+	viper.SetDefault("CORS_EXPOSED_HEADERS", "")
+	viper.SetDefault("CORS_ALLOW_CREDENTIALS", false)
+	viper.SetDefault("CORS_MAX_AGE", 10*time.Minute)
+
+	// CAPTCHA is disabled until a provider is configured
+	viper.SetDefault("CAPTCHA_PROVIDER", "")
+	viper.SetDefault("CAPTCHA_TIMEOUT", 5*time.Second)
+
+	// Account events are dropped until a webhook is configured
+	viper.SetDefault("NOTIFY_WEBHOOK_URL", "")
+	viper.SetDefault("NOTIFY_TIMEOUT", 5*time.Second)
+
+	viper.SetDefault("CONFIG_REMOTE_WATCH_INTERVAL", 30*time.Second)
 }
 
 // bindEnvVariables binds environment variables to config keys
 func bindEnvVariables() {
 	viper.BindEnv("SERVER_PORT")
 	viper.BindEnv("SERVER_ENV")
+	viper.BindEnv("LOG_LEVEL")
+	viper.BindEnv("MAINTENANCE_MODE")
 
 	viper.BindEnv("DB_HOST")
 	viper.BindEnv("DB_PORT")
@@ -128,13 +825,127 @@ func bindEnvVariables() {
 	viper.BindEnv("DB_PASSWORD")
 	viper.BindEnv("DB_NAME")
 	viper.BindEnv("DB_SSL_MODE")
+	viper.BindEnv("DB_MAX_CONNS")
+	viper.BindEnv("DB_STATS_INTERVAL")
+	viper.BindEnv("DB_CONNECT_TIMEOUT")
+	viper.BindEnv("DB_ACQUIRE_TIMEOUT")
+	viper.BindEnv("DB_CONNECT_ATTEMPTS")
+	viper.BindEnv("DB_LAST_LOGIN_QUEUE_SIZE")
+	viper.BindEnv("DB_LAST_LOGIN_BATCH_SIZE")
+	viper.BindEnv("DB_LAST_LOGIN_FLUSH_INTERVAL")
+	viper.BindEnv("DB_SWEEP_INTERVAL")
+	viper.BindEnv("DB_SWEEP_BATCH_SIZE")
+	viper.BindEnv("DB_REPLICA_HOST")
+	viper.BindEnv("DB_REPLICA_PORT")
+	viper.BindEnv("DB_REPLICA_USER")
+	viper.BindEnv("DB_REPLICA_PASSWORD")
+	viper.BindEnv("DB_REPLICA_NAME")
+	viper.BindEnv("DB_REPLICA_SSL_MODE")
+	viper.BindEnv("DB_REPLICA_READ_AFTER_WRITE_WINDOW")
 
 	viper.BindEnv("JWT_ACCESS_SECRET")
 	viper.BindEnv("JWT_REFRESH_SECRET")
+	viper.BindEnv("JWT_PREVIOUS_ACCESS_SECRET")
 	viper.BindEnv("JWT_ACCESS_EXPIRATION")
 	viper.BindEnv("JWT_REFRESH_EXPIRATION")
 
 	viper.BindEnv("GRPC_PORT")
+	viper.BindEnv("GRPC_MAX_HANDLER_DURATION")
+	viper.BindEnv("GRPC_METHOD_TIMEOUTS")
+	viper.BindEnv("GRPC_SEND_COMPRESSOR")
+	viper.BindEnv("GRPC_COMPRESSION_MIN_SIZE")
+	viper.BindEnv("GRPC_TLS_CERT_FILE")
+	viper.BindEnv("GRPC_TLS_KEY_FILE")
+	viper.BindEnv("GRPC_TLS_CLIENT_CA_FILE")
+	viper.BindEnv("GRPC_TLS_ALLOWED_CLIENTS")
+	viper.BindEnv("GRPC_INTERCEPTORS")
+	viper.BindEnv("GRPC_API_VERSIONS")
+	viper.BindEnv("GRPC_DEFAULT_API_VERSION")
+
+	viper.BindEnv("AUTH_DEFAULT_ROLE_CODE")
+	viper.BindEnv("PHONE_DEFAULT_REGION")
+	viper.BindEnv("PERMISSION_CACHE_TTL")
+	viper.BindEnv("TOKEN_VERSION_CACHE_TTL")
+	viper.BindEnv("AUTH_PASSWORD_MAX_AGE")
+	viper.BindEnv("AUTH_PASSWORD_CHANGE_TOKEN_EXPIRATION")
+	viper.BindEnv("AUTH_PASSWORD_RESET_TOKEN_EXPIRATION")
+	viper.BindEnv("AUTH_RESET_TOKEN_TYPE")
+	viper.BindEnv("AUTH_RESET_OTP_LENGTH")
+	viper.BindEnv("AUTH_RESET_OTP_EXPIRATION")
+	viper.BindEnv("AUTH_RESET_OTP_MAX_ATTEMPTS")
+	viper.BindEnv("AUTH_MULTI_TENANT")
+	viper.BindEnv("AUTH_USERNAME_CHANGE_COOLDOWN")
+	viper.BindEnv("AUTH_DEFAULT_TENANT")
+	viper.BindEnv("AUTH_RISK_FAILURE_WINDOW")
+	viper.BindEnv("AUTH_RISK_CHALLENGE_FAILURES")
+	viper.BindEnv("AUTH_RISK_DENY_FAILURES")
+	viper.BindEnv("AUTH_LOGIN_DELAY_BASE")
+	viper.BindEnv("AUTH_LOGIN_DELAY_MAX")
+	viper.BindEnv("AUTH_HIDE_INACTIVE")
+	viper.BindEnv("AUTH_NEW_USER_ACTIVE")
+	viper.BindEnv("AUTH_AVAILABILITY_CHECK_LIMIT")
+	viper.BindEnv("AUTH_AVAILABILITY_CHECK_WINDOW")
+	viper.BindEnv("AUTH_PERMISSIONS_FAIL_MODE")
+	viper.BindEnv("AUTH_SESSION_IDLE_TIMEOUT")
+	viper.BindEnv("AUTH_MAX_SESSIONS")
+	viper.BindEnv("AUTH_MAX_SESSIONS_POLICY")
+	viper.BindEnv("AUTH_SUPERADMIN_PERMISSIONS")
+	viper.BindEnv("AUTH_SUPERADMIN_ROLES")
+	viper.BindEnv("AUTH_MISSING_ROLE")
+	viper.BindEnv("AUTH_TOKEN_TYPE")
+	viper.BindEnv("AUTH_EMBED_PERMISSIONS")
+	viper.BindEnv("AUTH_EMBED_PERMISSIONS_MAX")
+	viper.BindEnv("AUTH_PERMISSIONS_PAGE_MAX")
+	viper.BindEnv("AUTH_SUBJECT_FORMAT")
+	viper.BindEnv("AUTH_LOGIN_IDENTIFIER")
+	viper.BindEnv("AUTH_USERNAME_CASE")
+	viper.BindEnv("AUTH_DELETION_GRACE_PERIOD")
+	viper.BindEnv("AUTH_DELETION_SWEEP_INTERVAL")
+	viper.BindEnv("AUTH_SCHEDULED_DELETION_LOGIN")
+	viper.BindEnv("AUTH_MAX_EMAIL_LENGTH")
+	viper.BindEnv("AUTH_MAX_USERNAME_LENGTH")
+	viper.BindEnv("AUTH_MAX_FULL_NAME_LENGTH")
+	viper.BindEnv("AUTH_PASSWORD_HASHER")
+	viper.BindEnv("AUTH_ARGON2_MEMORY")
+	viper.BindEnv("AUTH_ARGON2_ITERATIONS")
+	viper.BindEnv("AUTH_ARGON2_PARALLELISM")
+	viper.BindEnv("AUTH_BCRYPT_COST")
+	viper.BindEnv("AUTH_BCRYPT_PREHASH")
+	viper.BindEnv("AUTH_HASH_CALIBRATION_TARGET")
+	viper.BindEnv("AUTH_SUPPORTED_LOCALES")
+	viper.BindEnv("AUTH_ALLOWED_EMAIL_DOMAINS")
+	viper.BindEnv("AUTH_BLOCKED_EMAIL_DOMAINS")
+
+	viper.BindEnv("S3_ENDPOINT")
+	viper.BindEnv("S3_REGION")
+	viper.BindEnv("S3_BUCKET")
+	viper.BindEnv("S3_ACCESS_KEY")
+	viper.BindEnv("S3_SECRET_KEY")
+	viper.BindEnv("S3_PUBLIC_URL")
+	viper.BindEnv("S3_USE_PATH_STYLE")
+
+	viper.BindEnv("AVATAR_MAX_SIZE")
+	viper.BindEnv("AVATAR_ALLOWED_CONTENT_TYPES")
+	viper.BindEnv("AVATAR_UPLOAD_EXPIRATION")
+
+	viper.BindEnv("CORS_ALLOWED_ORIGINS")
+	viper.BindEnv("CORS_ALLOWED_METHODS")
+	viper.BindEnv("CORS_ALLOWED_HEADERS")
+	viper.BindEnv("CORS_EXPOSED_HEADERS")
+	viper.BindEnv("CORS_ALLOW_CREDENTIALS")
+	viper.BindEnv("CORS_MAX_AGE")
+
+	viper.BindEnv("CAPTCHA_PROVIDER")
+	viper.BindEnv("CAPTCHA_SECRET")
+	viper.BindEnv("CAPTCHA_TIMEOUT")
+	viper.BindEnv("NOTIFY_WEBHOOK_URL")
+	viper.BindEnv("NOTIFY_WEBHOOK_SECRET")
+	viper.BindEnv("NOTIFY_TIMEOUT")
+
+	viper.BindEnv("CONFIG_REMOTE_PROVIDER")
+	viper.BindEnv("CONFIG_REMOTE_ENDPOINT")
+	viper.BindEnv("CONFIG_REMOTE_PATH")
+	viper.BindEnv("CONFIG_REMOTE_WATCH_INTERVAL")
 }
 
 // Validate validates the configuration
@@ -145,15 +956,223 @@ func (c *Config) Validate() error {
 	if c.JWT.RefreshSecret == "" {
 		return fmt.Errorf("JWT_REFRESH_SECRET is required")
 	}
-	if c.Database.User == "" {
+	if c.JWT.PreviousAccessSecret == c.JWT.AccessSecret {
+		return fmt.Errorf("JWT_PREVIOUS_ACCESS_SECRET must differ from JWT_ACCESS_SECRET")
+	}
+	if c.Database.Backend != StorageBackendPostgres && c.Database.Backend != StorageBackendMemory {
+		return fmt.Errorf("STORAGE_BACKEND %q is not supported (use %s or %s)",
+			c.Database.Backend, StorageBackendPostgres, StorageBackendMemory)
+	}
+	if c.Database.Backend == StorageBackendPostgres && c.Database.User == "" {
 		return fmt.Errorf("DB_USER is required")
 	}
-	if c.Database.Name == "" {
+	if c.Database.Backend == StorageBackendPostgres && c.Database.Name == "" {
 		return fmt.Errorf("DB_NAME is required")
 	}
+	if c.Database.MaxConns < 0 {
+		return fmt.Errorf("DB_MAX_CONNS must not be negative")
+	}
+	if c.Database.ConnectTimeout <= 0 {
+		return fmt.Errorf("DB_CONNECT_TIMEOUT must be positive")
+	}
+	if c.Database.AcquireTimeout < 0 {
+		return fmt.Errorf("DB_ACQUIRE_TIMEOUT must not be negative")
+	}
+	if c.Database.ConnectAttempts < 1 {
+		return fmt.Errorf("DB_CONNECT_ATTEMPTS must be at least 1")
+	}
+	if c.Database.LastLoginQueueSize < 1 || c.Database.LastLoginBatchSize < 1 {
+		return fmt.Errorf("DB_LAST_LOGIN_QUEUE_SIZE and DB_LAST_LOGIN_BATCH_SIZE must be at least 1")
+	}
+	if c.Database.LastLoginFlushInterval <= 0 {
+		return fmt.Errorf("DB_LAST_LOGIN_FLUSH_INTERVAL must be positive")
+	}
+	if c.Database.SweepInterval < 0 {
+		return fmt.Errorf("DB_SWEEP_INTERVAL must not be negative")
+	}
+	if c.Database.SweepBatchSize < 1 {
+		return fmt.Errorf("DB_SWEEP_BATCH_SIZE must be at least 1")
+	}
+	if c.Database.ReplicaReadAfterWriteWindow < 0 {
+		return fmt.Errorf("DB_REPLICA_READ_AFTER_WRITE_WINDOW must not be negative")
+	}
+	if !phone.IsSupportedRegion(c.Auth.PhoneDefaultRegion) {
+		return fmt.Errorf("PHONE_DEFAULT_REGION %q is not supported", c.Auth.PhoneDefaultRegion)
+	}
+	if !domain.IsValidTenantID(c.Auth.DefaultTenant) {
+		return fmt.Errorf("AUTH_DEFAULT_TENANT %q is not a valid tenant ID", c.Auth.DefaultTenant)
+	}
+	if c.Auth.PasswordResetTokenExpiration <= 0 {
+		return fmt.Errorf("AUTH_PASSWORD_RESET_TOKEN_EXPIRATION must be positive")
+	}
+	if c.Auth.ResetTokenType != ResetTokenTypeJWT && c.Auth.ResetTokenType != ResetTokenTypeOTP {
+		return fmt.Errorf("AUTH_RESET_TOKEN_TYPE %q is not supported (use %s or %s)",
+			c.Auth.ResetTokenType, ResetTokenTypeJWT, ResetTokenTypeOTP)
+	}
+	if c.Auth.ResetOTPLength < minResetOTPLength || c.Auth.ResetOTPLength > maxResetOTPLength {
+		return fmt.Errorf("AUTH_RESET_OTP_LENGTH must be between %d and %d", minResetOTPLength, maxResetOTPLength)
+	}
+	if c.Auth.ResetOTPExpiration <= 0 || c.Auth.ResetOTPMaxAttempts < 1 {
+		return fmt.Errorf("AUTH_RESET_OTP_EXPIRATION must be positive and AUTH_RESET_OTP_MAX_ATTEMPTS at least 1")
+	}
+	if c.Auth.PermissionsFailMode != PermissionsFailOpen && c.Auth.PermissionsFailMode != PermissionsFailClosed {
+		return fmt.Errorf("AUTH_PERMISSIONS_FAIL_MODE %q is not supported (use %s or %s)",
+			c.Auth.PermissionsFailMode, PermissionsFailOpen, PermissionsFailClosed)
+	}
+	if c.Auth.SessionIdleTimeout < 0 {
+		return fmt.Errorf("AUTH_SESSION_IDLE_TIMEOUT must not be negative")
+	}
+	// Clients refresh once their access token expires, so a shorter timeout would end active sessions
+	if c.Auth.SessionIdleTimeout > 0 && c.Auth.SessionIdleTimeout < c.JWT.AccessExpiration {
+		return fmt.Errorf("AUTH_SESSION_IDLE_TIMEOUT must be at least JWT_ACCESS_EXPIRATION (%s)", c.JWT.AccessExpiration)
+	}
+	if c.Auth.MaxSessions < 0 {
+		return fmt.Errorf("AUTH_MAX_SESSIONS must not be negative")
+	}
+	if c.Auth.MaxSessionsPolicy != MaxSessionsPolicyEvictOldest && c.Auth.MaxSessionsPolicy != MaxSessionsPolicyReject {
+		return fmt.Errorf("AUTH_MAX_SESSIONS_POLICY %q is not supported (use %s or %s)",
+			c.Auth.MaxSessionsPolicy, MaxSessionsPolicyEvictOldest, MaxSessionsPolicyReject)
+	}
+	for _, permission := range c.Auth.SuperadminPermissions {
+		if resource, action, ok := strings.Cut(permission, ":"); !ok || resource == "" || action == "" {
+			return fmt.Errorf("AUTH_SUPERADMIN_PERMISSIONS: %q must have the form resource:ACTION", permission)
+		}
+	}
+	if c.Auth.MissingRole != MissingRoleDefault && c.Auth.MissingRole != MissingRoleReject {
+		return fmt.Errorf("AUTH_MISSING_ROLE %q is not supported (use %s or %s)",
+			c.Auth.MissingRole, MissingRoleDefault, MissingRoleReject)
+	}
+	if c.Auth.TokenType != TokenTypeJWT && c.Auth.TokenType != TokenTypeOpaque {
+		return fmt.Errorf("AUTH_TOKEN_TYPE %q is not supported (use %s or %s)",
+			c.Auth.TokenType, TokenTypeJWT, TokenTypeOpaque)
+	}
+	if c.Auth.EmbedPermissions && c.Auth.EmbedPermissionsMax < 1 {
+		return fmt.Errorf("AUTH_EMBED_PERMISSIONS_MAX must be at least 1 when AUTH_EMBED_PERMISSIONS is enabled")
+	}
+	if c.Auth.PermissionsPageMax < 1 {
+		return fmt.Errorf("AUTH_PERMISSIONS_PAGE_MAX must be at least 1")
+	}
+	if strings.Count(c.Auth.SubjectFormat, SubjectUserIDPlaceholder) != 1 {
+		return fmt.Errorf("AUTH_SUBJECT_FORMAT %q must contain %s exactly once", c.Auth.SubjectFormat, SubjectUserIDPlaceholder)
+	}
+	switch c.Auth.LoginIdentifier {
+	case LoginIdentifierEmail, LoginIdentifierUsername, LoginIdentifierBoth:
+	default:
+		return fmt.Errorf("AUTH_LOGIN_IDENTIFIER %q is not supported (use %s, %s or %s)",
+			c.Auth.LoginIdentifier, LoginIdentifierEmail, LoginIdentifierUsername, LoginIdentifierBoth)
+	}
+	switch c.Auth.UsernameCase {
+	case UsernameCasePreserve, UsernameCaseLower:
+	default:
+		return fmt.Errorf("AUTH_USERNAME_CASE %q is not supported (use %s or %s)",
+			c.Auth.UsernameCase, UsernameCasePreserve, UsernameCaseLower)
+	}
+	if c.Auth.DeletionGracePeriod <= 0 || c.Auth.DeletionSweepInterval <= 0 {
+		return fmt.Errorf("AUTH_DELETION_GRACE_PERIOD and AUTH_DELETION_SWEEP_INTERVAL must be positive")
+	}
+	if c.Auth.ScheduledDeletionLogin != ScheduledDeletionLoginWarn && c.Auth.ScheduledDeletionLogin != ScheduledDeletionLoginBlock {
+		return fmt.Errorf("AUTH_SCHEDULED_DELETION_LOGIN %q is not supported (use %s or %s)",
+			c.Auth.ScheduledDeletionLogin, ScheduledDeletionLoginWarn, ScheduledDeletionLoginBlock)
+	}
+	if c.Auth.MaxEmailLength < 1 || c.Auth.MaxEmailLength > emailColumnLength {
+		return fmt.Errorf("AUTH_MAX_EMAIL_LENGTH must be between 1 and %d", emailColumnLength)
+	}
+	if c.Auth.MaxUsernameLength < 3 || c.Auth.MaxUsernameLength > usernameColumnLength {
+		return fmt.Errorf("AUTH_MAX_USERNAME_LENGTH must be between 3 and %d", usernameColumnLength)
+	}
+	if c.Auth.MaxFullNameLength < 1 {
+		return fmt.Errorf("AUTH_MAX_FULL_NAME_LENGTH must be at least 1")
+	}
+	if c.Auth.LoginDelayBase < 0 || c.Auth.LoginDelayMax < c.Auth.LoginDelayBase {
+		return fmt.Errorf("AUTH_LOGIN_DELAY_BASE must not be negative and AUTH_LOGIN_DELAY_MAX not below it")
+	}
+	if c.Auth.AvailabilityCheckLimit < 0 || c.Auth.AvailabilityCheckWindow <= 0 {
+		return fmt.Errorf("AUTH_AVAILABILITY_CHECK_LIMIT must not be negative and AUTH_AVAILABILITY_CHECK_WINDOW must be positive")
+	}
+	if c.Auth.PasswordHasher != PasswordHasherBcrypt && c.Auth.PasswordHasher != PasswordHasherArgon2id {
+		return fmt.Errorf("AUTH_PASSWORD_HASHER %q is not supported (use %s or %s)",
+			c.Auth.PasswordHasher, PasswordHasherBcrypt, PasswordHasherArgon2id)
+	}
+	if c.Auth.Argon2Memory < 8*uint32(c.Auth.Argon2Parallelism) || c.Auth.Argon2Parallelism < 1 {
+		return fmt.Errorf("AUTH_ARGON2_PARALLELISM must be at least 1 and AUTH_ARGON2_MEMORY at least 8 KiB per lane")
+	}
+	if c.Auth.BcryptCost != 0 && (c.Auth.BcryptCost < minBcryptCost || c.Auth.BcryptCost > maxBcryptCost) {
+		return fmt.Errorf("AUTH_BCRYPT_COST must be 0 or between %d and %d", minBcryptCost, maxBcryptCost)
+	}
+	if c.Auth.HashCalibrationTarget < 0 {
+		return fmt.Errorf("AUTH_HASH_CALIBRATION_TARGET must not be negative")
+	}
+	if c.GRPC.SendCompressor != "" && c.GRPC.SendCompressor != GRPCCompressorGzip {
+		return fmt.Errorf("GRPC_SEND_COMPRESSOR %q is not supported (use %s)", c.GRPC.SendCompressor, GRPCCompressorGzip)
+	}
+	if c.GRPC.CompressionMinSize < 0 {
+		return fmt.Errorf("GRPC_COMPRESSION_MIN_SIZE must not be negative")
+	}
+	if (c.GRPC.TLSCertFile == "") != (c.GRPC.TLSKeyFile == "") {
+		return fmt.Errorf("GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE must be set together")
+	}
+	if c.GRPC.TLSClientCAFile != "" && c.GRPC.TLSCertFile == "" {
+		return fmt.Errorf("GRPC_TLS_CLIENT_CA_FILE requires GRPC_TLS_CERT_FILE and GRPC_TLS_KEY_FILE")
+	}
+	if c.GRPC.TLSClientCAFile != "" && len(c.GRPC.TLSAllowedClients) == 0 {
+		return fmt.Errorf("GRPC_TLS_ALLOWED_CLIENTS is required when GRPC_TLS_CLIENT_CA_FILE is set")
+	}
+	if err := c.GRPC.validateInterceptors(c.Auth.MultiTenant, c.Server.MaintenanceMode); err != nil {
+		return err
+	}
+	if len(c.GRPC.APIVersions) == 0 {
+		return fmt.Errorf("GRPC_API_VERSIONS must list at least one version")
+	}
+	if c.GRPC.DefaultAPIVersion != "" && !slices.Contains(c.GRPC.APIVersions, c.GRPC.DefaultAPIVersion) {
+		return fmt.Errorf("GRPC_DEFAULT_API_VERSION %q must be one of GRPC_API_VERSIONS (%s)",
+			c.GRPC.DefaultAPIVersion, strings.Join(c.GRPC.APIVersions, ", "))
+	}
+	if _, err := locale.NewSet(c.Auth.SupportedLocales); err != nil {
+		return fmt.Errorf("AUTH_SUPPORTED_LOCALES: %w", err)
+	}
+	if _, err := emaildomain.NewPolicy(c.Auth.AllowedEmailDomains, c.Auth.BlockedEmailDomains); err != nil {
+		return fmt.Errorf("AUTH_ALLOWED_EMAIL_DOMAINS / AUTH_BLOCKED_EMAIL_DOMAINS: %w", err)
+	}
+	if c.Storage.Endpoint != "" && (c.Storage.AccessKey == "" || c.Storage.SecretKey == "") {
+		return fmt.Errorf("S3_ACCESS_KEY and S3_SECRET_KEY are required when S3_ENDPOINT is set")
+	}
+	if c.Avatar.MaxSize <= 0 {
+		return fmt.Errorf("AVATAR_MAX_SIZE must be positive")
+	}
+	if c.Captcha.Enabled() {
+		if c.Captcha.Provider != CaptchaProviderRecaptcha && c.Captcha.Provider != CaptchaProviderHcaptcha {
+			return fmt.Errorf("CAPTCHA_PROVIDER %q is not supported (use %s or %s)",
+				c.Captcha.Provider, CaptchaProviderRecaptcha, CaptchaProviderHcaptcha)
+		}
+		if c.Captcha.Secret == "" {
+			return fmt.Errorf("CAPTCHA_SECRET is required when CAPTCHA_PROVIDER is set")
+		}
+		if c.Captcha.Timeout <= 0 {
+			return fmt.Errorf("CAPTCHA_TIMEOUT must be positive")
+		}
+	}
+	if c.Notifier.Enabled() {
+		if u, err := url.Parse(c.Notifier.WebhookURL); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("NOTIFY_WEBHOOK_URL must be an absolute http or https URL")
+		}
+		if c.Notifier.Timeout <= 0 {
+			return fmt.Errorf("NOTIFY_TIMEOUT must be positive")
+		}
+	}
+	if c.Server.LogLevel != "" {
+		if _, err := zapcore.ParseLevel(c.Server.LogLevel); err != nil {
+			return fmt.Errorf("LOG_LEVEL %q is not a valid log level", c.Server.LogLevel)
+		}
+	}
 	return nil
 }
 
+// StorageBackend returns the STORAGE_BACKEND environment variable, postgres when unset
+// It is read from the environment only, before the config is loaded, because it decides which storage modules are wired
+func StorageBackend() string {
+	return cmp.Or(strings.ToLower(strings.TrimSpace(os.Getenv("STORAGE_BACKEND"))), StorageBackendPostgres)
+}
+
 // GetDSN returns the PostgreSQL connection string
 func (c *DatabaseConfig) GetDSN() string {
 	return fmt.Sprintf(
@@ -166,3 +1185,120 @@ func (c *DatabaseConfig) GetDSN() string {
 		c.SSLMode,
 	)
 }
+
+// HasReplica reports whether a read replica is configured
+func (c *DatabaseConfig) HasReplica() bool {
+	return c.ReplicaHost != ""
+}
+
+// GetReplicaDSN returns the read replica DSN, taking unset replica settings from the primary
+func (c *DatabaseConfig) GetReplicaDSN() string {
+	replica := *c
+	replica.Host = c.ReplicaHost
+	replica.Port = cmp.Or(c.ReplicaPort, c.Port)
+	replica.User = cmp.Or(c.ReplicaUser, c.User)
+	replica.Password = cmp.Or(c.ReplicaPassword, c.Password)
+	replica.Name = cmp.Or(c.ReplicaName, c.Name)
+	replica.SSLMode = cmp.Or(c.ReplicaSSLMode, c.SSLMode)
+	return replica.GetDSN()
+}
+
+// SessionIdleBefore returns the last-seen time before which a session has been idle for longer than
+// AUTH_SESSION_IDLE_TIMEOUT at now, or the zero time when the timeout is disabled
+func (c *AuthConfig) SessionIdleBefore(now time.Time) time.Time {
+	if c.SessionIdleTimeout <= 0 {
+		return time.Time{}
+	}
+	return now.Add(-c.SessionIdleTimeout)
+}
+
+// validateInterceptors checks that GRPC_INTERCEPTORS only names known interceptors, each at most once
+// An interceptor cannot be left out while a setting depends on it: the client identity check while mTLS
+// guards internal methods, the tenant while Login and Register require one, and maintenance while it is on
+func (c *GRPCConfig) validateInterceptors(multiTenant, maintenanceMode bool) error {
+	seen := make(map[string]bool, len(c.Interceptors))
+	for _, name := range c.Interceptors {
+		if !slices.Contains(DefaultGRPCInterceptors, name) {
+			return fmt.Errorf("GRPC_INTERCEPTORS: interceptor %q is not supported (use %s)",
+				name, strings.Join(DefaultGRPCInterceptors, ", "))
+		}
+		if seen[name] {
+			return fmt.Errorf("GRPC_INTERCEPTORS: interceptor %q is listed twice", name)
+		}
+		seen[name] = true
+	}
+	if c.TLSClientCAFile != "" && !seen[GRPCInterceptorClientIdentity] {
+		return fmt.Errorf("GRPC_INTERCEPTORS must include %s when GRPC_TLS_CLIENT_CA_FILE is set", GRPCInterceptorClientIdentity)
+	}
+	if multiTenant && !seen[GRPCInterceptorTenant] {
+		return fmt.Errorf("GRPC_INTERCEPTORS must include %s when AUTH_MULTI_TENANT is enabled", GRPCInterceptorTenant)
+	}
+	if maintenanceMode && !seen[GRPCInterceptorMaintenance] {
+		return fmt.Errorf("GRPC_INTERCEPTORS must include %s when MAINTENANCE_MODE is enabled", GRPCInterceptorMaintenance)
+	}
+	return nil
+}
+
+// splitList splits a comma-separated value into trimmed, non-empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// roleExpirationPrefix prefixes the per-role access token expiration keys
+const roleExpirationPrefix = "JWT_ACCESS_EXPIRATION_"
+
+// parseRoleExpirations collects the JWT_ACCESS_EXPIRATION_<ROLE_CODE> keys set in the environment,
+// the config file or the remote source, keyed by upper-case role code
+func parseRoleExpirations() (map[string]time.Duration, error) {
+	keys := viper.AllKeys()
+	for _, env := range os.Environ() {
+		if name, _, _ := strings.Cut(env, "="); strings.HasPrefix(name, roleExpirationPrefix) {
+			// Bound by its exact name, as viper would look a lower-case role code up upper-cased
+			viper.BindEnv(name, name)
+			keys = append(keys, name)
+		}
+	}
+
+	result := make(map[string]time.Duration)
+	for _, key := range keys {
+		role, ok := strings.CutPrefix(strings.ToUpper(key), roleExpirationPrefix)
+		if !ok || role == "" {
+			continue
+		}
+		// Keys bound by an earlier load stay known to viper after their variable is unset
+		value := viper.GetString(key)
+		if value == "" {
+			continue
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid %s%s: must be a positive duration", roleExpirationPrefix, role)
+		}
+		result[role] = d
+	}
+	return result, nil
+}
+
+// parseDurationMap parses "key=duration" pairs separated by commas,
+// e.g. "Login=5s,/auth.AuthService/Register=10s"
+func parseDurationMap(value string) (map[string]time.Duration, error) {
+	result := make(map[string]time.Duration)
+	for _, item := range splitList(value) {
+		key, raw, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected key=duration, got %q", item)
+		}
+		d, err := time.ParseDuration(strings.TrimSpace(raw))
+		if err != nil {
+			return nil, fmt.Errorf("invalid duration for %q: %w", key, err)
+		}
+		result[strings.TrimSpace(key)] = d
+	}
+	return result, nil
+}
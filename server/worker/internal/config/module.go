@@ -6,11 +6,18 @@ import "go.uber.org/fx"
 var Module = fx.Module("config",
 	fx.Provide(
 		LoadConfig,
+		NewReloader,
 		// Extract individual configs for easier injection
 		provideJWTConfig,
 		provideDatabaseConfig,
 		provideGRPCConfig,
 		provideServerConfig,
+		provideAuthConfig,
+		provideStorageConfig,
+		provideAvatarConfig,
+		provideCORSConfig,
+		provideCaptchaConfig,
+		provideNotifierConfig,
 	),
 )
 
@@ -29,3 +36,27 @@ func provideGRPCConfig(cfg *Config) *GRPCConfig {
 func provideServerConfig(cfg *Config) *ServerConfig {
 	return &cfg.Server
 }
+
+func provideAuthConfig(cfg *Config) *AuthConfig {
+	return &cfg.Auth
+}
+
+func provideStorageConfig(cfg *Config) *StorageConfig {
+	return &cfg.Storage
+}
+
+func provideAvatarConfig(cfg *Config) *AvatarConfig {
+	return &cfg.Avatar
+}
+
+func provideCORSConfig(cfg *Config) *CORSConfig {
+	return &cfg.CORS
+}
+
+func provideCaptchaConfig(cfg *Config) *CaptchaConfig {
+	return &cfg.Captcha
+}
+
+func provideNotifierConfig(cfg *Config) *NotifierConfig {
+	return &cfg.Notifier
+}
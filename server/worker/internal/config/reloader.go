@@ -0,0 +1,236 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// DynamicConfig holds the non-secret settings that change at runtime, without a restart
+// Token expirations apply to tokens issued after the change
+type DynamicConfig struct {
+	LogLevel                      string
+	MaintenanceMode               bool
+	AccessExpiration              time.Duration
+	RefreshExpiration             time.Duration
+	PasswordChangeTokenExpiration time.Duration
+	AccessExpirationByRole        map[string]time.Duration
+}
+
+// Reloader holds the current DynamicConfig and reloads it from the remote config source
+// while running, and from the whole configuration (LoadConfig) on SIGHUP
+// Every other setting, secrets included, only changes on restart
+type Reloader struct {
+	logger  *zap.Logger
+	remote  RemoteConfig
+	started *Config // Configuration the process started with, its other settings stay in effect until restart
+	loaded  *Config // Configuration as of the last reload, compared on SIGHUP
+
+	current atomic.Pointer[DynamicConfig]
+
+	// viperMu serializes access to the global viper instance between the remote watch and SIGHUP;
+	// a SIGHUP reload waits for an in-flight remote watch to return
+	viperMu     sync.Mutex
+	mu          sync.Mutex
+	subscribers []func(DynamicConfig)
+}
+
+// NewReloader creates a Reloader that watches the remote source and SIGHUP for the application lifetime
+func NewReloader(lc fx.Lifecycle, cfg *Config, logger *zap.Logger) *Reloader {
+	r := &Reloader{
+		logger:  logger,
+		remote:  cfg.Remote,
+		started: cfg,
+		loaded:  cfg,
+	}
+	r.current.Store(dynamicConfigOf(cfg))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			signal.Notify(signals, syscall.SIGHUP)
+			go r.handleSignals(ctx, signals)
+			if cfg.Remote.Provider != "" {
+				go r.watchRemote(ctx)
+			}
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			signal.Stop(signals)
+			cancel()
+			return nil
+		},
+	})
+	return r
+}
+
+// Current returns the latest dynamic settings
+func (r *Reloader) Current() *DynamicConfig {
+	return r.current.Load()
+}
+
+// Subscribe registers fn to be called with the new settings after every change
+func (r *Reloader) Subscribe(fn func(DynamicConfig)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.subscribers = append(r.subscribers, fn)
+}
+
+// Reload re-runs LoadConfig and applies the dynamic settings
+// Changes to other settings are logged and ignored until restart
+func (r *Reloader) Reload() error {
+	r.viperMu.Lock()
+	cfg, err := LoadConfig()
+	r.viperMu.Unlock()
+	if err != nil {
+		return err
+	}
+	next := dynamicConfigOf(cfg)
+	if err := r.validate(next); err != nil {
+		return err
+	}
+
+	if changed := immutableChanges(r.loaded, cfg); len(changed) > 0 {
+		r.logger.Warn("Ignoring config changes that require a restart", zap.Strings("sections", changed))
+	}
+	r.loaded = cfg
+	r.update(next)
+	return nil
+}
+
+// validate runs the startup validation on the settings next would put in effect: the dynamic ones
+// from next, every other one as the process started, since a reload does not apply them
+// e.g. JWT_ACCESS_EXPIRATION is checked against the AUTH_SESSION_IDLE_TIMEOUT in use, not a reloaded one
+func (r *Reloader) validate(next *DynamicConfig) error {
+	cfg := *r.started
+	cfg.Server.LogLevel = next.LogLevel
+	cfg.Server.MaintenanceMode = next.MaintenanceMode
+	cfg.JWT.AccessExpiration = next.AccessExpiration
+	cfg.JWT.RefreshExpiration = next.RefreshExpiration
+	cfg.JWT.AccessExpirationByRole = next.AccessExpirationByRole
+	cfg.Auth.PasswordChangeTokenExpiration = next.PasswordChangeTokenExpiration
+	return cfg.Validate()
+}
+
+func (r *Reloader) handleSignals(ctx context.Context, signals <-chan os.Signal) {
+	for {
+		select {
+		case <-signals:
+			r.logger.Info("SIGHUP received, reloading config")
+			if err := r.Reload(); err != nil {
+				r.logger.Error("Failed to reload config, keeping the current settings", zap.Error(err))
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// watchRemote blocks on the remote source and applies dynamic settings as they change
+func (r *Reloader) watchRemote(ctx context.Context) {
+	for ctx.Err() == nil {
+		r.viperMu.Lock()
+		err := viper.WatchRemoteConfig()
+		var next *DynamicConfig
+		if err == nil {
+			next = r.loadDynamicConfig()
+		}
+		r.viperMu.Unlock()
+
+		if err != nil {
+			r.logger.Warn("Failed to watch remote config", zap.Error(err))
+			select {
+			case <-time.After(r.remote.WatchInterval):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+		if err := r.validate(next); err != nil {
+			r.logger.Error("Ignoring invalid remote config, keeping the current settings", zap.Error(err))
+			continue
+		}
+		r.update(next)
+	}
+}
+
+func (r *Reloader) update(next *DynamicConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if reflect.DeepEqual(next, r.current.Load()) {
+		return
+	}
+	r.current.Store(next)
+	for _, fn := range r.subscribers {
+		fn(*next)
+	}
+}
+
+// loadDynamicConfig reads the dynamic settings from viper, keeping the current value of invalid ones
+func (r *Reloader) loadDynamicConfig() *DynamicConfig {
+	next := *r.current.Load()
+	next.LogLevel = viper.GetString("LOG_LEVEL")
+	next.MaintenanceMode = viper.GetBool("MAINTENANCE_MODE")
+	for key, field := range map[string]*time.Duration{
+		"JWT_ACCESS_EXPIRATION":                 &next.AccessExpiration,
+		"JWT_REFRESH_EXPIRATION":                &next.RefreshExpiration,
+		"AUTH_PASSWORD_CHANGE_TOKEN_EXPIRATION": &next.PasswordChangeTokenExpiration,
+	} {
+		if d := viper.GetDuration(key); d > 0 {
+			*field = d
+		} else {
+			r.logger.Warn("Ignoring invalid duration from remote config", zap.String("key", key))
+		}
+	}
+	if byRole, err := parseRoleExpirations(); err == nil {
+		next.AccessExpirationByRole = byRole
+	} else {
+		r.logger.Warn("Ignoring invalid per-role access expiration from remote config", zap.Error(err))
+	}
+	return &next
+}
+
+func dynamicConfigOf(cfg *Config) *DynamicConfig {
+	return &DynamicConfig{
+		LogLevel:                      cfg.Server.LogLevel,
+		MaintenanceMode:               cfg.Server.MaintenanceMode,
+		AccessExpiration:              cfg.JWT.AccessExpiration,
+		RefreshExpiration:             cfg.JWT.RefreshExpiration,
+		PasswordChangeTokenExpiration: cfg.Auth.PasswordChangeTokenExpiration,
+		AccessExpirationByRole:        cfg.JWT.AccessExpirationByRole,
+	}
+}
+
+// immutableChanges returns the config sections that differ between old and new,
+// ignoring the dynamic settings
+func immutableChanges(old, new *Config) []string {
+	a, b := *old, *new
+	for _, c := range []*Config{&a, &b} {
+		c.Server.LogLevel = ""
+		c.Server.MaintenanceMode = false
+		c.JWT.AccessExpiration = 0
+		c.JWT.RefreshExpiration = 0
+		c.JWT.AccessExpirationByRole = nil
+		c.Auth.PasswordChangeTokenExpiration = 0
+	}
+
+	var changed []string
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			changed = append(changed, va.Type().Field(i).Name)
+		}
+	}
+	return changed
+}
@@ -0,0 +1,231 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+)
+
+const testRemotePath = "worker/config"
+
+// fakeConsul serves one key of the Consul KV API, answering blocking queries once the value changes
+type fakeConsul struct {
+	mu      sync.Mutex
+	value   string
+	index   int
+	served  int           // Index of the last value served
+	changed chan struct{} // Closed and replaced on every change
+}
+
+// newFakeConsul starts a fakeConsul holding value and returns it with its endpoint
+func newFakeConsul(t *testing.T, value string) (*fakeConsul, string) {
+	c := &fakeConsul{value: value, index: 1, changed: make(chan struct{})}
+	server := httptest.NewServer(c)
+	t.Cleanup(server.Close)
+	t.Cleanup(c.stop)
+	return c, server.URL
+}
+
+func (c *fakeConsul) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/v1/kv/"+testRemotePath {
+		http.NotFound(w, r)
+		return
+	}
+	c.mu.Lock()
+	index, changed := c.index, c.changed
+	c.mu.Unlock()
+
+	if r.URL.Query().Get("index") == fmt.Sprint(index) {
+		wait, _ := time.ParseDuration(r.URL.Query().Get("wait"))
+		select {
+		case <-changed:
+		case <-time.After(wait):
+		case <-r.Context().Done():
+			return
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	w.Header().Set("X-Consul-Index", fmt.Sprint(c.index))
+	fmt.Fprint(w, c.value)
+	c.served = c.index
+}
+
+func (c *fakeConsul) set(value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.value = value
+	c.index++
+	close(c.changed)
+	c.changed = make(chan struct{})
+}
+
+// waitServed waits until the current value was served to the watch
+func (c *fakeConsul) waitServed(t *testing.T) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		c.mu.Lock()
+		served := c.served == c.index
+		c.mu.Unlock()
+		if served {
+			return
+		}
+	}
+	t.Fatal("the watch did not read the remote value")
+}
+
+// stop releases the blocking queries in flight so the server can close
+func (c *fakeConsul) stop() {
+	c.set(c.value)
+}
+
+// startRemoteReloader loads the configuration from a fake Consul holding remote and starts a
+// Reloader watching it; the returned channel receives every change
+func startRemoteReloader(t *testing.T, env map[string]string, remote string) (*Reloader, *fakeConsul, <-chan DynamicConfig) {
+	t.Helper()
+	consul, endpoint := newFakeConsul(t, remote)
+	setTestEnv(t, env)
+	setTestEnv(t, map[string]string{
+		"CONFIG_REMOTE_PROVIDER":       RemoteProviderConsul,
+		"CONFIG_REMOTE_ENDPOINT":       endpoint,
+		"CONFIG_REMOTE_PATH":           testRemotePath,
+		"CONFIG_REMOTE_WATCH_INTERVAL": "1s",
+	})
+
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	lc := fxtest.NewLifecycle(t)
+	r := NewReloader(lc, cfg, zap.NewNop())
+	changes := make(chan DynamicConfig, 10)
+	r.Subscribe(func(next DynamicConfig) { changes <- next })
+
+	lc.RequireStart()
+	t.Cleanup(func() {
+		lc.RequireStop()
+		// The watch may still be in viper; LoadConfig registered the provider globally
+		r.viperMu.Lock()
+		defer r.viperMu.Unlock()
+		viper.Reset()
+	})
+	return r, consul, changes
+}
+
+func waitForChange(t *testing.T, changes <-chan DynamicConfig) DynamicConfig {
+	t.Helper()
+	select {
+	case next := <-changes:
+		return next
+	case <-time.After(5 * time.Second):
+		t.Fatal("no config change applied")
+		return DynamicConfig{}
+	}
+}
+
+func TestRemoteReload(t *testing.T) {
+	r, consul, changes := startRemoteReloader(t, nil, strings.Join([]string{
+		"LOG_LEVEL: info",
+		"JWT_ACCESS_EXPIRATION: 15m",
+	}, "\n"))
+	if got := r.Current(); got.LogLevel != "info" || got.AccessExpiration != 15*time.Minute {
+		t.Fatalf("settings at startup = %+v, want the remote ones", got)
+	}
+
+	consul.set(strings.Join([]string{
+		"LOG_LEVEL: debug",
+		"JWT_ACCESS_EXPIRATION: 30m",
+		"MAINTENANCE_MODE: true",
+	}, "\n"))
+	next := waitForChange(t, changes)
+	if next.LogLevel != "debug" || next.AccessExpiration != 30*time.Minute || !next.MaintenanceMode {
+		t.Fatalf("reloaded settings = %+v, want debug, 30m and maintenance", next)
+	}
+}
+
+func TestRemoteReloadRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name    string
+		env     map[string]string
+		initial string
+		invalid string
+	}{
+		{
+			name:    "access expiration over the idle timeout",
+			env:     map[string]string{"AUTH_SESSION_IDLE_TIMEOUT": "1h"},
+			initial: "JWT_ACCESS_EXPIRATION: 15m",
+			// The idle timeout in use stays the environment's
+			invalid: "JWT_ACCESS_EXPIRATION: 2h\nAUTH_SESSION_IDLE_TIMEOUT: 3h",
+		},
+		{
+			name:    "maintenance without its interceptor",
+			env:     map[string]string{"GRPC_INTERCEPTORS": interceptorsWithout(GRPCInterceptorMaintenance)},
+			initial: "MAINTENANCE_MODE: false",
+			invalid: "MAINTENANCE_MODE: true",
+		},
+		{
+			name:    "unknown log level",
+			initial: "LOG_LEVEL: info",
+			invalid: "LOG_LEVEL: verbose",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, consul, changes := startRemoteReloader(t, tt.env, tt.initial)
+			before := *r.Current()
+
+			consul.set(tt.invalid)
+			consul.waitServed(t)
+			// A valid change after the invalid one is applied on top of the current settings
+			// (viper merges remote values, so it restates the keys the invalid one set)
+			consul.set(tt.initial + "\nJWT_REFRESH_EXPIRATION: 48h")
+			next := waitForChange(t, changes)
+			before.RefreshExpiration = 48 * time.Hour
+			if fmt.Sprint(next) != fmt.Sprint(before) {
+				t.Fatalf("settings = %+v, want %+v", next, before)
+			}
+			if len(changes) > 0 {
+				t.Fatalf("unexpected change %+v", <-changes)
+			}
+		})
+	}
+}
+
+func TestReloadValidatesAgainstRunningConfig(t *testing.T) {
+	setTestEnv(t, map[string]string{
+		"AUTH_SESSION_IDLE_TIMEOUT": "20m",
+		"JWT_ACCESS_EXPIRATION":     "15m",
+	})
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("load config: %v", err)
+	}
+	r := NewReloader(fxtest.NewLifecycle(t), cfg, zap.NewNop())
+
+	// Valid on its own, but the idle timeout only changes on restart
+	t.Setenv("AUTH_SESSION_IDLE_TIMEOUT", "1h")
+	t.Setenv("JWT_ACCESS_EXPIRATION", "30m")
+	if err := r.Reload(); err == nil || !strings.Contains(err.Error(), "AUTH_SESSION_IDLE_TIMEOUT") {
+		t.Fatalf("reload = %v, want the idle timeout error", err)
+	}
+	if got := r.Current().AccessExpiration; got != 15*time.Minute {
+		t.Fatalf("access expiration after a rejected reload = %v, want 15m", got)
+	}
+
+	t.Setenv("JWT_ACCESS_EXPIRATION", "20m")
+	if err := r.Reload(); err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := r.Current().AccessExpiration; got != 20*time.Minute {
+		t.Fatalf("access expiration after reload = %v, want 20m", got)
+	}
+}
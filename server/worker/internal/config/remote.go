@@ -0,0 +1,180 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// Remote providers supported by kvClient
+const (
+	RemoteProviderConsul = "consul"
+	RemoteProviderEtcd3  = "etcd3"
+)
+
+// kvClient implements viper's remote config factory for Consul and etcd v3 over their HTTP APIs,
+// so remote config works without the viper/remote package and its crypt dependency.
+// The value stored at the path is a YAML (or JSON) document with the same keys as the environment.
+type kvClient struct {
+	client *http.Client
+	// watchInterval bounds a Consul blocking query and is the polling interval for etcd
+	watchInterval time.Duration
+
+	mu          sync.Mutex
+	consulIndex string // X-Consul-Index of the last read, used for blocking queries
+}
+
+func newKVClient(watchInterval time.Duration) *kvClient {
+	return &kvClient{
+		// Leaves room for the Consul server to answer a blocking query
+		client:        &http.Client{Timeout: watchInterval + 10*time.Second},
+		watchInterval: watchInterval,
+	}
+}
+
+// Get reads the current value at the provider's path
+func (c *kvClient) Get(rp viper.RemoteProvider) (io.Reader, error) {
+	value, err := c.read(rp, false)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(value), nil
+}
+
+// Watch blocks until the value may have changed (or the watch interval passes) and returns it
+func (c *kvClient) Watch(rp viper.RemoteProvider) (io.Reader, error) {
+	if rp.Provider() != RemoteProviderConsul {
+		time.Sleep(c.watchInterval)
+	}
+	value, err := c.read(rp, true)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.NewReader(value), nil
+}
+
+// WatchChannel streams values until the returned quit channel is closed
+func (c *kvClient) WatchChannel(rp viper.RemoteProvider) (<-chan *viper.RemoteResponse, chan bool) {
+	responses := make(chan *viper.RemoteResponse)
+	quit := make(chan bool)
+	go func() {
+		for {
+			value, err := c.read(rp, true)
+			select {
+			case responses <- &viper.RemoteResponse{Value: value, Error: err}:
+			case <-quit:
+				return
+			}
+			if rp.Provider() != RemoteProviderConsul || err != nil {
+				select {
+				case <-time.After(c.watchInterval):
+				case <-quit:
+					return
+				}
+			}
+		}
+	}()
+	return responses, quit
+}
+
+func (c *kvClient) read(rp viper.RemoteProvider, blocking bool) ([]byte, error) {
+	switch rp.Provider() {
+	case RemoteProviderConsul:
+		return c.readConsul(rp, blocking)
+	case RemoteProviderEtcd3:
+		return c.readEtcd3(rp)
+	default:
+		return nil, viper.UnsupportedRemoteProviderError(rp.Provider())
+	}
+}
+
+// readConsul reads a key through the Consul KV API, as a blocking query when blocking is set
+func (c *kvClient) readConsul(rp viper.RemoteProvider, blocking bool) ([]byte, error) {
+	query := url.Values{"raw": {""}}
+	c.mu.Lock()
+	if blocking && c.consulIndex != "" {
+		query.Set("index", c.consulIndex)
+		query.Set("wait", c.watchInterval.String())
+	}
+	c.mu.Unlock()
+
+	endpoint := remoteBaseURL(rp.Endpoint()) + "/v1/kv/" + strings.TrimPrefix(rp.Path(), "/") + "?" + query.Encode()
+	resp, err := c.client.Get(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("consul: reading %q returned %s", rp.Path(), resp.Status)
+	}
+	value, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("consul: %w", err)
+	}
+
+	c.mu.Lock()
+	c.consulIndex = resp.Header.Get("X-Consul-Index")
+	c.mu.Unlock()
+	return value, nil
+}
+
+// readEtcd3 reads a key through the etcd v3 JSON gateway
+func (c *kvClient) readEtcd3(rp viper.RemoteProvider) ([]byte, error) {
+	body, err := json.Marshal(map[string]string{
+		"key": base64.StdEncoding.EncodeToString([]byte(rp.Path())),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), c.client.Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, remoteBaseURL(rp.Endpoint())+"/v3/kv/range", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("etcd: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("etcd: reading %q returned %s", rp.Path(), resp.Status)
+	}
+
+	var result struct {
+		KVs []struct {
+			Value string `json:"value"`
+		} `json:"kvs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("etcd: %w", err)
+	}
+	if len(result.KVs) == 0 {
+		return nil, fmt.Errorf("etcd: key %q not found", rp.Path())
+	}
+	return base64.StdEncoding.DecodeString(result.KVs[0].Value)
+}
+
+// remoteBaseURL accepts endpoints with or without a scheme ("localhost:8500")
+func remoteBaseURL(endpoint string) string {
+	endpoint = strings.TrimSuffix(endpoint, "/")
+	if !strings.Contains(endpoint, "://") {
+		return "http://" + endpoint
+	}
+	return endpoint
+}
@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+type apiVersionContextKey struct{}
+
+// WithAPIVersion returns a copy of ctx carrying the API version the caller asked for
+func WithAPIVersion(ctx context.Context, version string) context.Context {
+	return context.WithValue(ctx, apiVersionContextKey{}, version)
+}
+
+// APIVersionFromContext returns the API version of the request, if the api_version interceptor resolved one
+func APIVersionFromContext(ctx context.Context) (string, bool) {
+	version, ok := ctx.Value(apiVersionContextKey{}).(string)
+	return version, ok && version != ""
+}
@@ -0,0 +1,38 @@
+package domain
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientInfo describes the client a request originates from
+// Fields are empty when the caller did not provide them
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+	Country   string // ISO 3166-1 alpha-2 code, only known when the gateway forwards it
+	// AcceptLanguage is the client's raw Accept-Language header, used to default the user's locale
+	AcceptLanguage string
+}
+
+// LastLogin is a successful login waiting to be stored on the user
+type LastLogin struct {
+	UserID uuid.UUID
+	Client ClientInfo
+	At     time.Time
+}
+
+type clientInfoContextKey struct{}
+
+// WithClientInfo returns a copy of ctx carrying the client of the request
+func WithClientInfo(ctx context.Context, info ClientInfo) context.Context {
+	return context.WithValue(ctx, clientInfoContextKey{}, info)
+}
+
+// ClientInfoFromContext returns the client of the request, or an empty ClientInfo when unknown
+func ClientInfoFromContext(ctx context.Context) ClientInfo {
+	info, _ := ctx.Value(clientInfoContextKey{}).(ClientInfo)
+	return info
+}
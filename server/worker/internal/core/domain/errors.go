@@ -5,28 +5,95 @@ import "errors"
 // Domain-specific errors for authentication
 var (
 	// User errors
-	ErrUserNotFound       = errors.New("user not found")
-	ErrUserAlreadyExists  = errors.New("user already exists")
-	ErrEmailAlreadyExists = errors.New("email already exists")
+	ErrUserNotFound          = errors.New("user not found")
+	ErrUserAlreadyExists     = errors.New("user already exists")
+	ErrEmailAlreadyExists    = errors.New("email already exists")
 	ErrUsernameAlreadyExists = errors.New("username already exists")
-	ErrUserInactive       = errors.New("user account is inactive")
+	ErrUserInactive          = errors.New("user account is inactive")
+	ErrUserPendingApproval   = errors.New("user account is pending approval")
+	ErrUserNotPending        = errors.New("user account is not pending approval")
+	ErrUserReferenced        = errors.New("user is still referenced by other records")
+	ErrInvalidPhone          = errors.New("invalid phone number")
+	ErrEmailDomainNotAllowed = errors.New("email domain is not allowed")
+	ErrInvalidLocale         = errors.New("unsupported locale")
+	ErrInvalidUsername       = errors.New("invalid username")
+	ErrUsernameChangeTooSoon = errors.New("username was changed too recently")
+	ErrFieldTooLong          = errors.New("field is too long")
+	ErrFieldRequired         = errors.New("field is required")
+
+	// Tenant errors
+	ErrTenantRequired = errors.New("tenant is required")
+	ErrInvalidTenant  = errors.New("invalid tenant")
+	ErrTenantMismatch = errors.New("token belongs to a different tenant")
 
 	// Authentication errors
 	ErrInvalidCredentials = errors.New("invalid credentials")
 	ErrIncorrectPassword  = errors.New("incorrect password")
 	ErrInvalidToken       = errors.New("invalid token")
+	ErrLoginChallenged    = errors.New("login requires additional verification")
+	ErrLoginDenied        = errors.New("login denied")
+	ErrLoginIdentifier    = errors.New("login identifier is not accepted")
+	ErrCaptchaInvalid     = errors.New("captcha verification failed")
 	ErrTokenExpired       = errors.New("token has expired")
+	ErrTooManySessions    = errors.New("too many active sessions")
 	ErrTokenMalformed     = errors.New("token is malformed")
+	ErrTokenNotFound      = errors.New("token not found")
+	ErrWrongTokenType     = errors.New("wrong token type")
+
+	// Password errors
+	ErrPasswordExpired       = errors.New("password has expired")
+	ErrPasswordResetRequired = errors.New("password must be reset")
+	ErrWeakPassword          = errors.New("password does not meet requirements")
+	ErrPasswordReused        = errors.New("new password must differ from the current one")
+	ErrResetCodeLocked       = errors.New("password reset code is locked after too many attempts")
+
+	// Session errors
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionRevoked     = errors.New("session has been revoked")
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
 
 	// Role errors
-	ErrRoleNotFound       = errors.New("role not found")
+	ErrRoleNotFound        = errors.New("role not found")
 	ErrDefaultRoleNotFound = errors.New("default role not found")
+	ErrRoleNotAssigned     = errors.New("role is not assigned to user")
+	ErrLastRole            = errors.New("cannot remove the last role of a user")
+	ErrRoleMissing         = errors.New("user role cannot be resolved")
+
+	// Pagination errors
+	ErrInvalidCursor    = errors.New("invalid pagination cursor")
+	ErrInvalidSortField = errors.New("invalid sort field")
+	ErrBatchTooLarge    = errors.New("batch is too large")
+
+	// Rate limit errors
+	ErrRateLimited = errors.New("rate limit exceeded")
+
+	// Permission errors
+	ErrInvalidPermission    = errors.New("invalid permission format")
+	ErrPermissionDenied     = errors.New("permission denied")
+	ErrResourceNotFound     = errors.New("resource not found")
+	ErrPermissionNotGranted = errors.New("permission is not granted to role")
+
+	// Confirmation errors
+	ErrConfirmationMismatch = errors.New("confirmation does not match")
+
+	// Account deletion errors
+	ErrDeletionScheduled    = errors.New("account is scheduled for deletion")
+	ErrDeletionNotScheduled = errors.New("account is not scheduled for deletion")
+
+	// Object storage errors
+	ErrObjectNotFound     = errors.New("object not found")
+	ErrInvalidObjectKey   = errors.New("invalid object key")
+	ErrInvalidContentType = errors.New("content type is not allowed")
+	ErrFileTooLarge       = errors.New("file is too large")
+	ErrInvalidFileSize    = errors.New("file size is invalid")
 
 	// Internal errors
-	ErrHashingPassword    = errors.New("failed to hash password")
-	ErrGeneratingToken    = errors.New("failed to generate token")
-	ErrGeneratingUUID     = errors.New("failed to generate UUID")
-	ErrDatabaseOperation  = errors.New("database operation failed")
+	ErrHashingPassword     = errors.New("failed to hash password")
+	ErrGeneratingToken     = errors.New("failed to generate token")
+	ErrGeneratingUUID      = errors.New("failed to generate UUID")
+	ErrDatabaseOperation   = errors.New("database operation failed")
+	ErrDatabaseUnavailable = errors.New("database unavailable")
+	ErrStorageOperation    = errors.New("object storage operation failed")
 )
 
 // AuthError wraps domain errors with additional context
@@ -34,6 +101,7 @@ type AuthError struct {
 	Err     error
 	Message string
 	Code    string
+	Field   string // Offending request field, set for validation errors
 }
 
 func (e *AuthError) Error() string {
@@ -56,13 +124,67 @@ func NewAuthError(err error, message string, code string) *AuthError {
 	}
 }
 
+// NewFieldError creates a validation AuthError for a single request field
+func NewFieldError(err error, field string, message string) *AuthError {
+	return &AuthError{
+		Err:     err,
+		Message: message,
+		Code:    CodeInvalidArgument,
+		Field:   field,
+	}
+}
+
+// WithField marks the error as caused by a single request field
+func (e *AuthError) WithField(field string) *AuthError {
+	e.Field = field
+	return e
+}
+
 // Error codes for gRPC status mapping
+// Codes are sent to clients as the ErrorInfo reason and must stay stable
 const (
-	CodeUserNotFound       = "USER_NOT_FOUND"
-	CodeUserAlreadyExists  = "USER_ALREADY_EXISTS"
-	CodeInvalidCredentials = "INVALID_CREDENTIALS"
-	CodeIncorrectPassword  = "INCORRECT_PASSWORD"
-	CodeInvalidToken       = "INVALID_TOKEN"
-	CodeTokenExpired       = "TOKEN_EXPIRED"
-	CodeInternalError      = "INTERNAL_ERROR"
+	CodeUserNotFound          = "USER_NOT_FOUND"
+	CodeUserAlreadyExists     = "USER_ALREADY_EXISTS"
+	CodeUserInactive          = "USER_INACTIVE"
+	CodeUserPendingApproval   = "USER_PENDING_APPROVAL"
+	CodeUserNotPending        = "USER_NOT_PENDING"
+	CodeUserReferenced        = "USER_REFERENCED"
+	CodeUsernameChangeTooSoon = "USERNAME_CHANGE_TOO_SOON"
+	CodeInvalidCredentials    = "INVALID_CREDENTIALS"
+	CodeLoginChallenged       = "LOGIN_CHALLENGED"
+	CodeLoginDenied           = "LOGIN_DENIED"
+	CodeTooManySessions       = "TOO_MANY_SESSIONS"
+	CodeLoginIdentifier       = "LOGIN_IDENTIFIER_NOT_ACCEPTED"
+	CodeCaptchaInvalid        = "CAPTCHA_INVALID"
+	CodeIncorrectPassword     = "INCORRECT_PASSWORD"
+	CodeInvalidToken          = "INVALID_TOKEN"
+	CodeTokenExpired          = "TOKEN_EXPIRED"
+	CodeWrongTokenType        = "WRONG_TOKEN_TYPE"
+	CodePasswordExpired       = "PASSWORD_EXPIRED"
+	CodePasswordResetRequired = "PASSWORD_RESET_REQUIRED"
+	CodeWeakPassword          = "WEAK_PASSWORD"
+	CodePasswordReused        = "PASSWORD_REUSED"
+	CodeResetCodeLocked       = "RESET_CODE_LOCKED"
+	CodeSessionRevoked        = "SESSION_REVOKED"
+	CodeTokenReused           = "REFRESH_TOKEN_REUSED"
+	CodeRoleNotFound          = "ROLE_NOT_FOUND"
+	CodeRoleNotAssigned       = "ROLE_NOT_ASSIGNED"
+	CodeDefaultRoleNotFound   = "DEFAULT_ROLE_NOT_FOUND"
+	CodeLastRole              = "LAST_ROLE"
+	CodeRoleMissing           = "ROLE_MISSING"
+	CodePermissionDenied      = "PERMISSION_DENIED"
+	CodeResourceNotFound      = "RESOURCE_NOT_FOUND"
+	CodePermissionNotGranted  = "PERMISSION_NOT_GRANTED"
+	CodeConfirmationMismatch  = "CONFIRMATION_MISMATCH"
+	CodeRateLimited           = "RATE_LIMITED"
+	CodeDeletionScheduled     = "DELETION_SCHEDULED"
+	CodeDeletionNotScheduled  = "DELETION_NOT_SCHEDULED"
+	CodeInvalidArgument       = "INVALID_ARGUMENT"
+	CodeTenantRequired        = "TENANT_REQUIRED"
+	CodeInvalidTenant         = "INVALID_TENANT"
+	CodeObjectNotFound        = "OBJECT_NOT_FOUND"
+	CodeDatabaseUnavailable   = "DATABASE_UNAVAILABLE"
+	CodeDeadlineExceeded      = "DEADLINE_EXCEEDED"
+	CodeCanceled              = "CANCELED"
+	CodeInternalError         = "INTERNAL_ERROR"
 )
@@ -0,0 +1,23 @@
+package domain
+
+// Permissions checked by the worker itself, in the gateway's "resource:ACTION" format
+// Roles holding a wildcard grant ("*:*", "system:*") satisfy them as well
+const (
+	// PermissionSystemRead allows reading operational data such as database pool statistics and user aggregates
+	PermissionSystemRead = "system:READ"
+
+	// PermissionRolesRead allows listing roles with their permissions
+	PermissionRolesRead = "roles:READ"
+
+	// PermissionRolesUpdate allows granting permissions to roles and revoking them
+	PermissionRolesUpdate = "roles:UPDATE"
+
+	// PermissionUsersRead allows listing the users of the caller's tenant
+	PermissionUsersRead = "users:READ"
+
+	// PermissionUsersUpdate allows managing other users' accounts, such as signing them out everywhere
+	PermissionUsersUpdate = "users:UPDATE"
+
+	// PermissionUsersDelete allows permanently erasing other users' accounts
+	PermissionUsersDelete = "users:DELETE"
+)
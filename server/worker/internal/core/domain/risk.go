@@ -0,0 +1,27 @@
+package domain
+
+// RiskDecision is the outcome of a login risk evaluation
+type RiskDecision string
+
+const (
+	// RiskAllow lets the login proceed
+	RiskAllow RiskDecision = "allow"
+	// RiskChallenge requires the user to complete an additional verification step (2FA)
+	RiskChallenge RiskDecision = "challenge"
+	// RiskDeny refuses the login even though the credentials are correct
+	RiskDeny RiskDecision = "deny"
+)
+
+// LoginAttempt describes a login evaluated by a risk evaluator
+type LoginAttempt struct {
+	UserID   string
+	TenantID string
+	Client   ClientInfo
+}
+
+// RiskAssessment is a risk evaluator's decision and the reason for it
+// Reason is recorded in audit events, it is not shown to the user
+type RiskAssessment struct {
+	Decision RiskDecision
+	Reason   string
+}
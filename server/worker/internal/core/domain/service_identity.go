@@ -0,0 +1,16 @@
+package domain
+
+import "context"
+
+type serviceIdentityContextKey struct{}
+
+// WithServiceIdentity returns a copy of ctx carrying the service identity of an mTLS caller
+func WithServiceIdentity(ctx context.Context, identity string) context.Context {
+	return context.WithValue(ctx, serviceIdentityContextKey{}, identity)
+}
+
+// ServiceIdentityFromContext returns the allowlisted service identity of the caller's client certificate, if any
+func ServiceIdentityFromContext(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(serviceIdentityContextKey{}).(string)
+	return identity, ok && identity != ""
+}
@@ -0,0 +1,30 @@
+package domain
+
+import (
+	"context"
+	"regexp"
+)
+
+// DefaultTenantID is the tenant of every user when multi-tenant mode is disabled
+const DefaultTenantID = "default"
+
+// tenantIDPattern restricts tenant IDs to a URL- and issuer-safe form
+var tenantIDPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,63}$`)
+
+// IsValidTenantID reports whether id is a well-formed tenant ID
+func IsValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+type tenantContextKey struct{}
+
+// WithTenant returns a copy of ctx carrying the tenant resolved for the request
+func WithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant resolved for the request, if any
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenantID, ok := ctx.Value(tenantContextKey{}).(string)
+	return tenantID, ok && tenantID != ""
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 // =============================================================================
 // Authentication Types (NOT duplicating sqlc models)
 // =============================================================================
@@ -17,16 +19,38 @@ type TokenPair struct {
 
 // RegisterRequest represents input for user registration
 type RegisterRequest struct {
-	Username string
-	Email    string
-	Password string // Raw password (will be hashed)
-	FullName string
+	Username     string
+	Email        string
+	Password     string // Raw password (will be hashed)
+	FullName     string
+	Phone        string // Optional, normalized to E.164
+	Locale       string // Optional BCP 47 tag, resolved from the client's accept-language when empty
+	CaptchaToken string // Required when CAPTCHA is enabled
 }
 
 // LoginRequest represents input for user login
 type LoginRequest struct {
-	Identifier string // email or username
-	Password   string
+	Identifier   string // email or username
+	Password     string
+	CaptchaToken string // Required when CAPTCHA is enabled
+}
+
+// Sort fields accepted by ListUsers
+const (
+	UserSortCreatedAt = "created_at"
+	UserSortLastLogin = "last_login"
+	UserSortUsername  = "username"
+)
+
+// UserListQuery represents input for listing users
+type UserListQuery struct {
+	Cursor     string // Empty for the first page
+	PageSize   int32  // 0 for the default page size
+	SortField  string // One of the UserSort* constants, empty for UserSortCreatedAt
+	Descending bool
+	IsActive   *bool // nil lists active and inactive users
+	// PendingApproval only lists accounts waiting for an admin: inactive, never logged in and not rejected
+	PendingApproval bool
 }
 
 // ValidateTokenResult represents the result of token validation
@@ -35,4 +59,210 @@ type ValidateTokenResult struct {
 	UserID      string
 	Email       string
 	Permissions []string
+	Claims      map[string]any // Custom claims surfaced by the ports.ClaimsEnricher, nil without any
+	Role        string         // Primary role code the token was issued with
+	ExpiresAt   time.Time      // Zero for tokens without an expiry
+}
+
+// TokenValidation is the outcome of one token of a batch validation
+// Err is why the token is invalid; Result is only set for valid tokens
+type TokenValidation struct {
+	Result *ValidateTokenResult
+	Err    error
+}
+
+// Availability tells whether one email or username of a batch availability check is still free
+// Value is the identifier as normalized for the check
+type Availability struct {
+	Value     string
+	Available bool
+}
+
+// AvailabilityBatch holds the results of a batch availability check, in request order
+type AvailabilityBatch struct {
+	Emails    []Availability
+	Usernames []Availability
+}
+
+// Token types used in introspection results (RFC 7662 token_type_hint values)
+const (
+	TokenTypeAccess  = "access_token"
+	TokenTypeRefresh = "refresh_token"
+)
+
+// IntrospectionResult represents the full claim set of a token (RFC 7662 style)
+// Active is false for expired, invalid or revoked tokens; other fields are then empty
+type IntrospectionResult struct {
+	Active      bool
+	TokenType   string
+	Subject     string
+	Username    string
+	Role        string
+	Roles       []string
+	Permissions []string
+	Issuer      string
+	TenantID    string // Only set in multi-tenant mode
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+// DecodedToken is what DecodeToken reads from a JWT without verifying it
+// None of it can be trusted; zero values stand for claims the token does not carry
+type DecodedToken struct {
+	Algorithm string
+	Subject   string
+	Username  string
+	Role      string
+	Roles     []string
+	Issuer    string
+	Audience  []string
+	ID        string // jti
+	TenantID  string
+	SessionID string // Only in refresh tokens
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	NotBefore time.Time
+}
+
+// PermissionPage is one page of a user's permissions, in sorted order
+// NextCursor is empty on the last page; Total counts the permissions across all pages
+type PermissionPage struct {
+	Permissions []string
+	NextCursor  string
+	Total       int
+}
+
+// PermissionDecision is the outcome of an authorization check
+type PermissionDecision struct {
+	Allowed           bool
+	Reason            string
+	MatchedPermission string // Granted permission that allowed the action, empty when denied
+}
+
+// PermissionGrant is what a role may do on one resource
+type PermissionGrant struct {
+	ResourceCode string
+	ResourceName string
+	Actions      []string // e.g. READ, CREATE; "*" grants every action
+}
+
+// DBStats is a snapshot of database connection pool statistics
+type DBStats struct {
+	AcquiredConns     int32 // Connections currently checked out
+	IdleConns         int32
+	TotalConns        int32
+	MaxConns          int32
+	AcquireCount      int64         // Successful acquires since startup
+	EmptyAcquireCount int64         // Acquires that had to wait for a connection
+	EmptyAcquireWait  time.Duration // Total time spent waiting in those acquires
+	AcquireDuration   time.Duration // Total time spent in successful acquires
+	CanceledAcquires  int64
+}
+
+// Health statuses of HealthReport and DependencyHealth
+const (
+	HealthUp   = "up"
+	HealthDown = "down"
+)
+
+// DependencyHealth is the outcome of probing one dependency
+type DependencyHealth struct {
+	Name    string // database, schema, cache
+	Status  string // HealthUp or HealthDown
+	Detail  string // unavailable or timeout when the dependency is down, what kind it is when up
+	Latency time.Duration
+}
+
+// HealthReport is the readiness of the worker and its dependencies
+// Status is HealthDown as soon as one dependency is down
+type HealthReport struct {
+	Status       string
+	Version      string
+	Dependencies []DependencyHealth
+	CheckedAt    time.Time
+}
+
+// Pong is the answer to a Ping
+type Pong struct {
+	Version    string
+	ServerTime time.Time
+}
+
+// ObjectInfo describes an object stored in object storage
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+}
+
+// AvatarUpload describes a presigned avatar upload slot
+type AvatarUpload struct {
+	UploadURL string // Presigned PUT URL, the upload must send the same Content-Type and Content-Length
+	ObjectKey string // Key to pass to ConfirmAvatar once uploaded
+	PublicURL string // URL the avatar will be served from
+	ExpiresAt time.Time
+	MaxSize   int64
+}
+
+// PasswordResetToken is issued by ForcePasswordReset and handed to the user out-of-band
+type PasswordResetToken struct {
+	Token     string // Accepted once by ChangePassword, without the current password; a numeric code with AUTH_RESET_TOKEN_TYPE=otp
+	ExpiresAt time.Time
+}
+
+// Audit event types
+const (
+	// AuditLoginNewClient is recorded when a user logs in from an IP address or user agent
+	// different from their previous login
+	AuditLoginNewClient = "login_new_client"
+	// AuditLoginChallenged is recorded when the risk evaluator requires additional verification
+	AuditLoginChallenged = "login_challenged"
+	// AuditLoginDenied is recorded when the risk evaluator refuses a login
+	AuditLoginDenied = "login_denied"
+	// AuditPasswordResetForced is recorded when an admin forces a user to reset their password
+	AuditPasswordResetForced = "password_reset_forced"
+	// AuditUserPurged is recorded just before a user is permanently erased, by an admin or once the
+	// deletion grace period is over
+	AuditUserPurged = "user_purged"
+	// AuditUserActivated and AuditUserDeactivated are recorded when an admin changes whether a user may log in
+	AuditUserActivated   = "user_activated"
+	AuditUserDeactivated = "user_deactivated"
+	// AuditUserApproved and AuditUserRejected are recorded when an admin acts on a pending account
+	AuditUserApproved = "user_approved"
+	AuditUserRejected = "user_rejected"
+)
+
+// AuditEvent is a security-relevant event kept for later review
+type AuditEvent struct {
+	Type       string
+	UserID     string
+	TenantID   string
+	Client     ClientInfo
+	PreviousIP string // Only set for AuditLoginNewClient
+	PreviousUA string // Only set for AuditLoginNewClient
+	Reason     string // Risk evaluator's reason for AuditLoginChallenged and AuditLoginDenied, the admin's for AuditUserRejected
+	ActorID    string // Admin acting on UserID, set for the admin events and AuditUserPurged by an admin
+	At         time.Time
+}
+
+// Reasons reported in RevocationEvent
+const (
+	RevocationReasonTokenReuse      = "refresh_token_reuse"
+	RevocationReasonPasswordChanged = "password_changed"
+	RevocationReasonAdminRevoked    = "admin_revoked"
+	RevocationReasonPasswordReset   = "password_reset_forced"
+	RevocationReasonUserPurged      = "user_purged"
+	RevocationReasonDeletion        = "deletion_scheduled"
+	RevocationReasonUserDeactivated = "user_deactivated"
+	RevocationReasonSessionLimit    = "session_limit"
+)
+
+// RevocationEvent announces that refresh sessions were revoked
+// Tokens carry no jti, so events identify the session (sid claim) and the user (sub claim);
+// SessionID is empty when every session of the user was revoked
+type RevocationEvent struct {
+	SessionID string
+	UserID    string
+	Reason    string
+	RevokedAt time.Time
 }
@@ -0,0 +1,12 @@
+package domain
+
+import "regexp"
+
+// usernamePattern allows 3-50 letters, digits, dots, underscores and hyphens, starting with a letter or digit
+// The upper bound matches the users.username column
+var usernamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{2,49}$`)
+
+// IsValidUsername reports whether username is well-formed
+func IsValidUsername(username string) bool {
+	return usernamePattern.MatchString(username)
+}
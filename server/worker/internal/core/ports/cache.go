@@ -0,0 +1,36 @@
+package ports
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+// PermissionCache caches the effective permissions resolved for a user
+type PermissionCache interface {
+	// Get returns the cached permissions of a user, reporting false on a miss
+	Get(ctx context.Context, userID uuid.UUID) ([]string, bool)
+
+	// Set caches the permissions of a user
+	Set(ctx context.Context, userID uuid.UUID, permissions []string)
+
+	// Invalidate drops the cached permissions of a user
+	Invalidate(ctx context.Context, userID uuid.UUID)
+
+	// InvalidateAll drops every cached entry (e.g. after a role's permissions change)
+	InvalidateAll(ctx context.Context)
+}
+
+// TokenVersionCache caches the token version of a user, checked on every access token validation
+// It only ever holds versions already stored in the database: revocations bump the stored version
+// first and cache the result, so a restart loses no revocation and there is nothing to flush on stop
+type TokenVersionCache interface {
+	// Get returns the cached token version of a user, reporting false on a miss
+	Get(ctx context.Context, userID uuid.UUID) (int32, bool)
+
+	// Set caches the token version of a user
+	Set(ctx context.Context, userID uuid.UUID, version int32)
+
+	// Invalidate drops the cached token version of a user
+	Invalidate(ctx context.Context, userID uuid.UUID)
+}
@@ -0,0 +1,10 @@
+package ports
+
+import "context"
+
+// CaptchaVerifier checks the CAPTCHA token a client solved before Register or Login
+type CaptchaVerifier interface {
+	// Verify returns domain.ErrCaptchaInvalid when the token is missing or rejected,
+	// and another error when the provider could not be asked
+	Verify(ctx context.Context, token, remoteIP string) error
+}
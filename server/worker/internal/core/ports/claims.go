@@ -0,0 +1,18 @@
+package ports
+
+import (
+	"context"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+)
+
+// ClaimsEnricher lets integrators put their own claims (e.g. org_id, feature flags) in access tokens
+// The default adds none; replace claims.Module to provide another one
+type ClaimsEnricher interface {
+	// Enrich returns the custom claims of an access token issued to user holding roles
+	// Claims named like a standard claim (sub, exp, roles, perms...) are dropped; an error fails the token issue
+	Enrich(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow, roles []string) (map[string]any, error)
+
+	// Surface picks which custom claims of a valid token are reported in domain.ValidateTokenResult
+	Surface(ctx context.Context, claims map[string]any) map[string]any
+}
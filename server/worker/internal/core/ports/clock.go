@@ -0,0 +1,9 @@
+package ports
+
+import "time"
+
+// Clock is the time source of the core services
+// Injected so tests can control token issue and expiry times
+type Clock interface {
+	Now() time.Time
+}
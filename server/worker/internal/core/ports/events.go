@@ -0,0 +1,47 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+)
+
+// AuditLog records security-relevant events
+type AuditLog interface {
+	// Record stores an event without blocking the request for long; failures are not reported
+	Record(ctx context.Context, event domain.AuditEvent)
+}
+
+// LastLoginRecorder stores successful logins on the user in the background
+type LastLoginRecorder interface {
+	// Record queues a login without blocking; it is dropped and logged when the queue is full
+	Record(login domain.LastLogin)
+}
+
+// RevocationBroker fans revocation events out to in-process subscribers
+// Events are published after the revocation is stored, so they are notifications only and
+// subscribers missing them (e.g. across a restart) can always fall back on the database
+type RevocationBroker interface {
+	// Publish delivers an event to every subscriber without blocking
+	Publish(event domain.RevocationEvent)
+
+	// Subscribe returns a channel of events published from now on
+	// The channel is closed when ctx is done, or early when the subscriber falls
+	// too far behind; in that case the subscriber must assume it missed events
+	Subscribe(ctx context.Context) <-chan domain.RevocationEvent
+}
+
+// Notifier tells systems outside the worker about account events, e.g. to send a welcome email
+type Notifier interface {
+	// UserRegistered announces a new account once it is committed
+	// An error means the event was not delivered; the registration stands regardless
+	UserRegistered(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow) error
+
+	// UserApproved announces that an admin activated a pending account at the given time
+	UserApproved(ctx context.Context, user *sqlc.GetUserByIDRow, at time.Time) error
+
+	// UserRejected announces that an admin rejected a pending account, which is erased after the deletion grace period
+	UserRejected(ctx context.Context, user *sqlc.GetUserByIDRow, reason string, at time.Time) error
+}
@@ -0,0 +1,23 @@
+package ports
+
+import (
+	"context"
+
+	"worker/internal/core/domain"
+)
+
+// DatabaseStats exposes connection pool statistics for monitoring
+type DatabaseStats interface {
+	// Stats returns a snapshot of the current pool statistics
+	Stats() *domain.DBStats
+}
+
+// DatabaseHealth probes the database for the readiness report
+type DatabaseHealth interface {
+	// Ping checks that the database accepts queries
+	Ping(ctx context.Context) error
+
+	// CheckSchema checks that every table and column the worker queries exists,
+	// failing when the schema has not been migrated for this build
+	CheckSchema(ctx context.Context) error
+}
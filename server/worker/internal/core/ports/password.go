@@ -0,0 +1,16 @@
+package ports
+
+// PasswordHasher hashes passwords and verifies them against stored hashes
+// Stored hashes carry their algorithm, so hashes of different algorithms coexist during a migration
+type PasswordHasher interface {
+	// Hash returns the stored form of password, made with the algorithm AUTH_PASSWORD_HASHER selects
+	Hash(password string) (string, error)
+
+	// Compare returns domain.ErrIncorrectPassword when password does not match hash,
+	// and another error when hash is malformed; hashes of every supported algorithm are accepted
+	Compare(hash, password string) error
+
+	// NeedsRehash reports whether hash, which password was just verified against, was made with another
+	// algorithm or other parameters than Hash uses
+	NeedsRehash(hash, password string) bool
+}
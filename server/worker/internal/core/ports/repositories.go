@@ -2,10 +2,12 @@ package ports
 
 import (
 	"context"
+	"time"
 
 	"github.com/google/uuid"
 
 	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
 )
 
 // UserRepository defines the interface for user data operations
@@ -14,31 +16,141 @@ type UserRepository interface {
 	// FindByID retrieves a user by their UUID (includes role info)
 	FindByID(ctx context.Context, id uuid.UUID) (*sqlc.GetUserByIDRow, error)
 
-	// FindByEmail retrieves a user by their email address (includes role info)
-	FindByEmail(ctx context.Context, email string) (*sqlc.GetUserByEmailRow, error)
+	// FindByEmail retrieves a user by their email address within a tenant (includes role info)
+	FindByEmail(ctx context.Context, tenantID, email string) (*sqlc.GetUserByEmailRow, error)
 
-	// FindByUsername retrieves a user by their username (includes role info)
-	FindByUsername(ctx context.Context, username string) (*sqlc.GetUserByUsernameRow, error)
+	// FindByUsername retrieves a user by their username within a tenant (includes role info)
+	FindByUsername(ctx context.Context, tenantID, username string) (*sqlc.GetUserByUsernameRow, error)
 
-	// FindByEmailOrUsername retrieves a user by email or username (includes role info)
-	// This is useful for login where user can use either
-	FindByEmailOrUsername(ctx context.Context, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error)
+	// FindByEmailOrUsername retrieves a user by email or username within a tenant (includes role info)
+	// Used by login when AUTH_LOGIN_IDENTIFIER allows both
+	FindByEmailOrUsername(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error)
 
-	// ExistsByEmail checks if a user with the given email exists
-	ExistsByEmail(ctx context.Context, email string) (bool, error)
+	// FindByUsernameIgnoringCase retrieves a user by their username, in any letter case, within a tenant
+	// Used instead of FindByUsername when AUTH_USERNAME_CASE=lower
+	FindByUsernameIgnoringCase(ctx context.Context, tenantID, username string) (*sqlc.GetUserByUsernameRow, error)
 
-	// ExistsByUsername checks if a user with the given username exists
-	ExistsByUsername(ctx context.Context, username string) (bool, error)
+	// FindByEmailOrUsernameIgnoringCase retrieves a user by email, or by username in any letter case, within a tenant
+	// Used instead of FindByEmailOrUsername when AUTH_USERNAME_CASE=lower
+	FindByEmailOrUsernameIgnoringCase(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error)
+
+	// ListUsers returns one page of a tenant's users in (created_at, id) order, after params' keyset position
+	ListUsers(ctx context.Context, params sqlc.ListUsersParams) ([]sqlc.ListUsersRow, error)
+
+	// ListUsersDesc returns one page of a tenant's users in descending (created_at, id) order, before params' keyset position
+	ListUsersDesc(ctx context.Context, params sqlc.ListUsersDescParams) ([]sqlc.ListUsersRow, error)
+
+	// ListUsersByLastLogin returns one page of a tenant's users in (last_login, id) order, after params' keyset position
+	// Users who never logged in sort as logging in at the epoch
+	ListUsersByLastLogin(ctx context.Context, params sqlc.ListUsersByLastLoginParams) ([]sqlc.ListUsersRow, error)
+
+	// ListUsersByLastLoginDesc returns one page of a tenant's users in descending (last_login, id) order, before params' keyset position
+	ListUsersByLastLoginDesc(ctx context.Context, params sqlc.ListUsersByLastLoginDescParams) ([]sqlc.ListUsersRow, error)
+
+	// ListUsersByUsername returns one page of a tenant's users in bytewise (username, id) order, after params' keyset position
+	ListUsersByUsername(ctx context.Context, params sqlc.ListUsersByUsernameParams) ([]sqlc.ListUsersRow, error)
+
+	// ListUsersByUsernameDesc returns one page of a tenant's users in descending bytewise (username, id) order, before params' keyset position
+	ListUsersByUsernameDesc(ctx context.Context, params sqlc.ListUsersByUsernameDescParams) ([]sqlc.ListUsersRow, error)
+
+	// ExistsByEmail checks if a user with the given email exists within a tenant
+	ExistsByEmail(ctx context.Context, tenantID, email string) (bool, error)
+
+	// ExistsByUsername checks if a user with the given username exists within a tenant
+	ExistsByUsername(ctx context.Context, tenantID, username string) (bool, error)
+
+	// ExistsByUsernameIgnoringCase checks if a user with the given username, in any letter case, exists within a tenant
+	ExistsByUsernameIgnoringCase(ctx context.Context, tenantID, username string) (bool, error)
+
+	// ExistingEmails returns which of the given emails belong to a user of the tenant, in one query
+	ExistingEmails(ctx context.Context, tenantID string, emails []string) ([]string, error)
+
+	// ExistingUsernames returns which of the given usernames belong to a user of the tenant, in one query
+	ExistingUsernames(ctx context.Context, tenantID string, usernames []string) ([]string, error)
+
+	// ExistingUsernamesIgnoringCase returns which of the given lowercase usernames belong, in any letter case,
+	// to a user of the tenant, lowercased
+	ExistingUsernamesIgnoringCase(ctx context.Context, tenantID string, usernames []string) ([]string, error)
 
 	// CreateUser creates a new user in the database
 	// Returns the created user (without role info, just base user)
+	// Returns domain.ErrEmailAlreadyExists / ErrUsernameAlreadyExists on unique violations
 	CreateUser(ctx context.Context, params sqlc.CreateUserParams) (*sqlc.User, error)
 
 	// UpdateUser updates an existing user
 	UpdateUser(ctx context.Context, params sqlc.UpdateUserParams) (*sqlc.User, error)
 
-	// UpdateLastLogin updates the last login timestamp for a user
-	UpdateLastLogin(ctx context.Context, userID uuid.UUID) error
+	// UpdateLastLogins stores a batch of last logins, skipping any older than the stored one
+	// Unknown client fields are stored as NULL
+	UpdateLastLogins(ctx context.Context, logins []domain.LastLogin) error
+
+	// UpdateAvatar updates the avatar URL of a user
+	UpdateAvatar(ctx context.Context, userID uuid.UUID, avatarURL string) error
+
+	// UpdateEmail changes the email of a user in one statement, relying on the unique constraint under concurrency
+	// Returns domain.ErrEmailAlreadyExists when the tenant already has the email, domain.ErrUserNotFound for an unknown user
+	UpdateEmail(ctx context.Context, userID uuid.UUID, email string) error
+
+	// SetActive activates or deactivates a user; inactive users cannot log in or refresh
+	// Returns domain.ErrUserNotFound if the user does not exist
+	SetActive(ctx context.Context, userID uuid.UUID, active bool) error
+
+	// UpdatePassword replaces the password hash of a user, sets password_changed_at and lifts a forced reset
+	UpdatePassword(ctx context.Context, userID uuid.UUID, passwordHash string) error
+
+	// RehashPassword replaces the password hash with a new hash of the same password, keeping password_changed_at
+	// Returns false when the stored hash is no longer oldHash, i.e. the password changed meanwhile
+	RehashPassword(ctx context.Context, userID uuid.UUID, oldHash, newHash string) (bool, error)
+
+	// UpdateUsername renames a user and sets username_changed_at, unless the previous rename is within cooldown
+	// Returns false when the cooldown blocked the rename, domain.ErrUsernameAlreadyExists when the name is taken
+	UpdateUsername(ctx context.Context, userID uuid.UUID, username string, cooldown time.Duration) (bool, error)
+
+	// GetTokenVersion returns the user's current token version
+	// Returns domain.ErrUserNotFound if the user does not exist
+	GetTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error)
+
+	// GetTokenSubjects returns the email and token version of several users in one query
+	// Users that do not exist are missing from the result
+	GetTokenSubjects(ctx context.Context, userIDs []uuid.UUID) ([]sqlc.GetTokenSubjectsByIDsRow, error)
+
+	// IncrementTokenVersion bumps the user's token version, invalidating access tokens issued before
+	// Returns domain.ErrUserNotFound if the user does not exist
+	IncrementTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error)
+
+	// RequirePasswordReset refuses logins until the password is reset and bumps the token version
+	// Returns the new token version, or domain.ErrUserNotFound if the user does not exist
+	RequirePasswordReset(ctx context.Context, userID uuid.UUID) (int32, error)
+
+	// Delete permanently removes a user and their additional roles; sessions must be deleted first
+	// Returns domain.ErrUserNotFound if the user does not exist, and domain.ErrUserReferenced if rows
+	// without a cascading foreign key still reference them
+	Delete(ctx context.Context, userID uuid.UUID) error
+
+	// ScheduleDeletion schedules the erasure of a user at the given time and returns the schedule in effect
+	// An existing schedule is kept; returns domain.ErrUserNotFound if the user does not exist
+	ScheduleDeletion(ctx context.Context, userID uuid.UUID, at time.Time) (time.Time, error)
+
+	// CancelDeletion cancels the scheduled erasure of a user
+	// Returns false if no erasure was scheduled
+	CancelDeletion(ctx context.Context, userID uuid.UUID) (bool, error)
+
+	// ListDueForDeletion returns up to limit users whose scheduled erasure is at or before dueBy
+	ListDueForDeletion(ctx context.Context, dueBy time.Time, limit int32) ([]sqlc.ListUsersDueForDeletionRow, error)
+
+	// DeleteIfDue is Delete for a user whose scheduled erasure is at or before dueBy
+	// Returns domain.ErrUserNotFound if the user is gone or the erasure was cancelled meanwhile
+	DeleteIfDue(ctx context.Context, userID uuid.UUID, dueBy time.Time) error
+
+	// AddRole assigns an additional role to a user (idempotent)
+	AddRole(ctx context.Context, userID, roleID uuid.UUID) error
+
+	// RemoveRole removes an additional role from a user
+	// Returns false if the role was not assigned through user_roles
+	RemoveRole(ctx context.Context, userID, roleID uuid.UUID) (bool, error)
+
+	// SetPrimaryRole changes the primary role stored on the user row
+	SetPrimaryRole(ctx context.Context, userID, roleID uuid.UUID) error
 }
 
 // RoleRepository defines the interface for role data operations
@@ -52,6 +164,134 @@ type RoleRepository interface {
 	// GetDefaultRole retrieves the default role for new users (usually "STUDENT")
 	GetDefaultRole(ctx context.Context) (*sqlc.Role, error)
 
-	// GetPermissionsByRoleID retrieves all permission strings for a given role
+	// GetPermissionsByRoleID retrieves all permission strings for a given role, deduplicated and sorted
 	GetPermissionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]string, error)
+
+	// GetPermissionsByRoleIDs retrieves the permissions of several roles in one query, keyed by role ID
+	// Roles without permissions map to an empty slice
+	GetPermissionsByRoleIDs(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+
+	// FindByUserID retrieves all roles assigned to a user (primary + additional)
+	FindByUserID(ctx context.Context, userID uuid.UUID) ([]sqlc.Role, error)
+
+	// GetPermissionsByUserID retrieves the union of permissions across all roles of a user, deduplicated and sorted
+	GetPermissionsByUserID(ctx context.Context, userID uuid.UUID) ([]string, error)
+
+	// GetPermissionsByUserIDs retrieves the permissions of several users in one query, keyed by user ID
+	// Users without permissions map to an empty slice
+	GetPermissionsByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, error)
+
+	// List retrieves every role, ordered by code
+	List(ctx context.Context) ([]sqlc.Role, error)
+
+	// GetGrantsByRoleID retrieves the permissions of a role per resource, one grant per resource
+	GetGrantsByRoleID(ctx context.Context, roleID uuid.UUID) ([]domain.PermissionGrant, error)
+
+	// ListGrants retrieves the permissions of every role per resource, keyed by role ID
+	// Roles without permissions are absent
+	ListGrants(ctx context.Context) (map[uuid.UUID][]domain.PermissionGrant, error)
+
+	// FindResourceByCode retrieves a resource by its code (e.g., "users")
+	// Returns domain.ErrResourceNotFound if no resource has this code
+	FindResourceByCode(ctx context.Context, code string) (*sqlc.Resource, error)
+
+	// GrantAction grants an action on a resource to a role; granting a held action changes nothing
+	GrantAction(ctx context.Context, roleID, resourceID uuid.UUID, action string) error
+
+	// RevokeAction revokes an action on a resource from a role
+	// Returns false if the role did not hold the action
+	RevokeAction(ctx context.Context, roleID, resourceID uuid.UUID, action string) (bool, error)
+}
+
+// SessionRepository defines the interface for refresh token session operations
+type SessionRepository interface {
+	// Create creates a new session
+	Create(ctx context.Context, params sqlc.CreateSessionParams) (*sqlc.Session, error)
+
+	// FindByID retrieves a session by its UUID
+	// Returns domain.ErrSessionNotFound if the session does not exist
+	FindByID(ctx context.Context, id uuid.UUID) (*sqlc.Session, error)
+
+	// RotateNonce swaps the refresh nonce if currentNonce is still the active one and marks the session as seen at seenAt
+	// Returns false if the nonce was already rotated or the session was revoked
+	RotateNonce(ctx context.Context, id uuid.UUID, currentNonce, newNonce string, expiresAt, seenAt time.Time) (bool, error)
+
+	// MarkSeen marks an active session as seen at seenAt, unless it was last seen after staleBefore
+	// Sessions last seen before idleBefore stay idle unless it is zero
+	MarkSeen(ctx context.Context, id uuid.UUID, seenAt, staleBefore, idleBefore time.Time) error
+
+	// Revoke revokes a session
+	Revoke(ctx context.Context, id uuid.UUID) error
+
+	// RevokeAllForUser revokes every active session of a user
+	RevokeAllForUser(ctx context.Context, userID uuid.UUID) error
+
+	// ListActiveIDsForUser returns the IDs of a user's sessions that can still refresh at now, oldest first
+	// Sessions last seen before idleBefore are left out unless it is zero
+	ListActiveIDsForUser(ctx context.Context, userID uuid.UUID, now, idleBefore time.Time) ([]uuid.UUID, error)
+
+	// DeleteAllForUser deletes every session of a user, revoked or not
+	DeleteAllForUser(ctx context.Context, userID uuid.UUID) error
+
+	// DeleteExpired deletes up to limit sessions that expired before the given time, or were last seen before
+	// idleBefore (zero when sessions never expire from inactivity), revoked or not
+	// Returns how many were deleted; fewer than limit means none are left
+	DeleteExpired(ctx context.Context, before, idleBefore time.Time, limit int32) (int64, error)
+}
+
+// AccessTokenRepository stores the claims of opaque access tokens (AUTH_TOKEN_TYPE=opaque)
+// Tokens are keyed by their hash, the token itself is never stored
+type AccessTokenRepository interface {
+	// Create stores an opaque access token and drops the user's expired ones
+	Create(ctx context.Context, params sqlc.CreateAccessTokenParams) error
+
+	// FindByHash retrieves an opaque access token by the hash of the token
+	// Returns domain.ErrTokenNotFound if no token has this hash
+	FindByHash(ctx context.Context, tokenHash string) (*sqlc.AccessToken, error)
+
+	// DeleteAllForUser deletes every opaque access token of a user
+	DeleteAllForUser(ctx context.Context, userID uuid.UUID) error
+
+	// DeleteExpired deletes up to limit tokens that expired before the given time
+	// Returns how many were deleted; fewer than limit means none are left
+	DeleteExpired(ctx context.Context, before time.Time, limit int32) (int64, error)
+}
+
+// PasswordResetCodeRepository stores the pending one-time reset code of each user (AUTH_RESET_TOKEN_TYPE=otp)
+// Codes are stored as a keyed hash, the code itself is never stored
+type PasswordResetCodeRepository interface {
+	// Save stores a user's new reset code, replacing the pending one and resetting its attempts
+	Save(ctx context.Context, params sqlc.UpsertPasswordResetCodeParams) error
+
+	// RecordAttempt counts a verification attempt on a user's pending code and returns the code
+	// Returns domain.ErrTokenNotFound if the user has no pending code
+	RecordAttempt(ctx context.Context, userID uuid.UUID) (*sqlc.PasswordResetCode, error)
+
+	// Delete deletes a user's pending code
+	Delete(ctx context.Context, userID uuid.UUID) error
+}
+
+// ReadOnlyQuerier runs a fixed set of aggregate queries for reporting
+// Callers cannot pass SQL; every query is parameterized and runs in a read-only transaction
+type ReadOnlyQuerier interface {
+	// CountUsersByRole counts the tenant's users per primary role, including roles nobody holds
+	CountUsersByRole(ctx context.Context, tenantID string) ([]sqlc.CountUsersByRoleRow, error)
+
+	// CountRegistrationsPerDay counts the tenant's registrations per day since the given time
+	// Days without registrations are omitted
+	CountRegistrationsPerDay(ctx context.Context, tenantID string, since time.Time) ([]sqlc.CountRegistrationsPerDayRow, error)
+}
+
+// Repositories are the repositories of one unit of work, bound to its transaction
+type Repositories struct {
+	Users    UserRepository
+	Roles    RoleRepository
+	Sessions SessionRepository
+}
+
+// UnitOfWork runs changes spanning several repositories atomically
+type UnitOfWork interface {
+	// Do runs fn in one transaction, committing when fn returns nil and rolling back otherwise
+	// fn must only use the repositories it is given; fn's error is returned unchanged
+	Do(ctx context.Context, fn func(repos Repositories) error) error
 }
@@ -0,0 +1,39 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"worker/internal/core/domain"
+)
+
+// RiskEvaluator decides whether a login with correct credentials may proceed
+// The default implementation can be replaced by providing another ports.RiskEvaluator to the fx graph
+type RiskEvaluator interface {
+	// Evaluate is consulted by Login once the password has been verified
+	Evaluate(ctx context.Context, attempt domain.LoginAttempt) (domain.RiskAssessment, error)
+
+	// RecordFailure is called when a login of an existing user failed with an incorrect password
+	RecordFailure(ctx context.Context, attempt domain.LoginAttempt)
+
+	// RecordSuccess is called when a login was allowed and tokens were issued
+	RecordSuccess(ctx context.Context, attempt domain.LoginAttempt)
+}
+
+// LoginThrottle slows down repeated failed logins instead of locking the account
+// Keys combine the login identifier and the client IP, see services.loginThrottleKey
+type LoginThrottle interface {
+	// RecordFailure counts a failed login and returns how long Login waits before answering it
+	RecordFailure(ctx context.Context, key string) time.Duration
+
+	// Reset forgets the failures of key once a login succeeded
+	Reset(ctx context.Context, key string)
+}
+
+// RateLimiter caps how many units a key may spend per window
+// Keys name the limited operation and the caller, see services.CheckAvailabilityBatch
+type RateLimiter interface {
+	// Allow spends n units of key and reports whether they fit in its current window
+	// A denied call spends nothing
+	Allow(ctx context.Context, key string, n int) bool
+}
@@ -2,6 +2,9 @@ package ports
 
 import (
 	"context"
+	"time"
+
+	"github.com/google/uuid"
 
 	"worker/internal/adapter/storage/postgres/sqlc"
 	"worker/internal/core/domain"
@@ -13,6 +16,7 @@ type AuthService interface {
 	Register(ctx context.Context, req *domain.RegisterRequest) (*AuthResponse, error)
 
 	// Login authenticates a user and returns tokens
+	// An expired password returns domain.ErrPasswordExpired together with a response carrying only PasswordChangeToken
 	Login(ctx context.Context, req *domain.LoginRequest) (*AuthResponse, error)
 
 	// RefreshAccessToken generates a new access token using refresh token
@@ -20,17 +24,176 @@ type AuthService interface {
 
 	// ValidateAccessToken validates an access token and returns user info
 	ValidateAccessToken(ctx context.Context, accessToken string) (*domain.ValidateTokenResult, error)
+
+	// ValidateAccessTokenClaims validates an access token from its claims alone, without looking up the user
+	// It does not see revocations or role changes made after the token was issued, and reports no email
+	ValidateAccessTokenClaims(ctx context.Context, accessToken string) (*domain.ValidateTokenResult, error)
+
+	// ValidateAccessTokens validates several access tokens at once, returning one result per token in order
+	ValidateAccessTokens(ctx context.Context, accessTokens []string) ([]domain.TokenValidation, error)
+
+	// IntrospectToken returns the claim set of an access or refresh token
+	// Invalid or expired tokens yield Active=false rather than an error
+	IntrospectToken(ctx context.Context, token, tokenTypeHint string) (*domain.IntrospectionResult, error)
+
+	// ChangePassword replaces the user's password and revokes all sessions
+	// token is an access token, the password change token returned for an expired password,
+	// or a password reset token from ForcePasswordReset (currentPassword is then ignored)
+	// When identifier (email or username) is set, token is the reset code ForcePasswordReset issues with
+	// AUTH_RESET_TOKEN_TYPE=otp; a wrong code counts as an attempt and returns domain.ErrResetCodeLocked at the limit
+	ChangePassword(ctx context.Context, identifier, token, currentPassword, newPassword string) error
+
+	// ChangeUsername renames the user, at most once per AUTH_USERNAME_CHANGE_COOLDOWN
+	// Returns domain.ErrUsernameChangeTooSoon while the cooldown is running
+	// Returns the username as stored, after normalization
+	ChangeUsername(ctx context.Context, userID uuid.UUID, newUsername string) (string, error)
+
+	// ListPendingApprovals returns one page of accounts waiting for approval, oldest first (requires users:READ)
+	ListPendingApprovals(ctx context.Context, accessToken, cursor string, pageSize int32) (*UserPage, error)
+
+	// ApproveUser activates a pending account, optionally replacing its role (requires users:UPDATE,
+	// and roles:UPDATE with a role)
+	ApproveUser(ctx context.Context, callerID, targetID uuid.UUID, roleCode string) error
+
+	// RejectUser schedules a pending account for deletion with the admin's reason (requires users:DELETE)
+	// The account is erased once the grace period is over; the reason only goes to the audit log and notifier
+	RejectUser(ctx context.Context, callerID, targetID uuid.UUID, reason string) error
+
+	// SetUserActive activates or deactivates the target user (requires users:UPDATE)
+	// Deactivating also signs them out everywhere
+	SetUserActive(ctx context.Context, callerID, targetID uuid.UUID, active bool) error
+
+	// RevokeAllUserTokens signs the target user out everywhere (requires users:UPDATE)
+	// Sessions are revoked and access tokens already issued stop validating
+	RevokeAllUserTokens(ctx context.Context, callerID, targetID uuid.UUID) error
+
+	// ForcePasswordReset blocks the target's login until the password is reset (requires users:UPDATE)
+	// Signs the target out everywhere and returns the reset token to hand over out-of-band
+	ForcePasswordReset(ctx context.Context, callerID, targetID uuid.UUID) (*domain.PasswordResetToken, error)
+
+	// PurgeUser permanently erases the target with their sessions and roles (requires users:DELETE)
+	// confirmation must be the target's email, otherwise domain.ErrConfirmationMismatch is returned
+	PurgeUser(ctx context.Context, callerID, targetID uuid.UUID, confirmation string) error
+
+	// ScheduleDeletion schedules the erasure of the user's account after AUTH_DELETION_GRACE_PERIOD
+	// Returns when the account will be erased; repeating the request keeps the first schedule
+	ScheduleDeletion(ctx context.Context, userID uuid.UUID) (time.Time, error)
+
+	// CancelDeletion restores an account scheduled for deletion, authenticating with the user's credentials
+	// Returns domain.ErrDeletionNotScheduled when no deletion is scheduled
+	CancelDeletion(ctx context.Context, req *domain.LoginRequest) error
+
+	// SweepScheduledDeletions erases a batch of accounts whose grace period is over, returning how many
+	SweepScheduledDeletions(ctx context.Context) (int, error)
+
+	// ListUsers returns one page of the caller's tenant users (requires users:READ)
+	// query.Cursor is empty for the first page or the NextCursor of the previous page
+	ListUsers(ctx context.Context, accessToken string, query *domain.UserListQuery) (*UserPage, error)
+
+	// CheckAvailabilityBatch reports which emails and usernames are still free in the caller's tenant (requires users:READ)
+	// Results follow the order of the request; the call is rate-limited per caller
+	CheckAvailabilityBatch(ctx context.Context, accessToken string, emails, usernames []string) (*domain.AvailabilityBatch, error)
+
+	// DecodeToken reads the claims of a JWT without verifying it, for debugging (requires system:READ)
+	DecodeToken(ctx context.Context, accessToken, token string) (*domain.DecodedToken, error)
+
+	// WatchRevocations streams session revocations until ctx is done
+	// The channel closes early when the subscriber falls behind and missed events
+	WatchRevocations(ctx context.Context) <-chan domain.RevocationEvent
+
+	// ListRoles returns every role with its permissions (requires roles:READ)
+	ListRoles(ctx context.Context, accessToken string) ([]RoleDetails, error)
+
+	// GetRole returns the role with the given code and its permissions (requires roles:READ)
+	// Returns domain.ErrRoleNotFound when no role has this code
+	GetRole(ctx context.Context, accessToken, code string) (*RoleDetails, error)
+
+	// GrantPermission grants a permission (resource:ACTION) to the role with the given code (requires roles:UPDATE)
+	// Granting a permission the role already holds succeeds without changes
+	GrantPermission(ctx context.Context, accessToken, roleCode, permission string) error
+
+	// RevokePermission revokes a permission (resource:ACTION) from the role with the given code (requires roles:UPDATE)
+	// Returns domain.ErrPermissionNotGranted when the role does not hold it
+	RevokePermission(ctx context.Context, accessToken, roleCode, permission string) error
+
+	// GetMyPermissions returns the effective permissions of the access token's user
+	GetMyPermissions(ctx context.Context, accessToken string) ([]string, error)
+
+	// ListMyPermissions returns one page of the effective permissions of the access token's user,
+	// at most AUTH_PERMISSIONS_PAGE_MAX; cursor is empty for the first page
+	ListMyPermissions(ctx context.Context, accessToken, cursor string, pageSize int32) (*domain.PermissionPage, error)
+
+	// CheckPermission decides whether the access token's user holds a permission (resource:ACTION)
+	CheckPermission(ctx context.Context, accessToken, permission string) (*domain.PermissionDecision, error)
+
+	// GetDBStats returns database pool statistics (requires system:READ)
+	GetDBStats(ctx context.Context, accessToken string) (*domain.DBStats, error)
+
+	// GetStats returns user aggregates of the caller's tenant over the last days (requires system:READ)
+	GetStats(ctx context.Context, accessToken string, days int32) (*UserStats, error)
+
+	// GetAvatarUploadURL returns a presigned URL for uploading a new avatar
+	GetAvatarUploadURL(ctx context.Context, userID uuid.UUID, contentType string, contentLength int64) (*domain.AvatarUpload, error)
+
+	// ConfirmAvatar validates an uploaded avatar and stores its public URL on the user
+	ConfirmAvatar(ctx context.Context, userID uuid.UUID, objectKey string) (string, error)
+
+	// AddRole assigns an additional role (by code) to a user
+	AddRole(ctx context.Context, userID uuid.UUID, roleCode string) error
+
+	// RemoveRole removes a role (by code) from a user
+	RemoveRole(ctx context.Context, userID uuid.UUID, roleCode string) error
+}
+
+// HealthService reports the readiness of the worker's dependencies
+type HealthService interface {
+	// GetHealth probes every dependency, each within a short timeout, and never fails
+	GetHealth(ctx context.Context) *domain.HealthReport
+
+	// Ping answers with the server time and build version without probing any dependency
+	Ping(ctx context.Context) *domain.Pong
 }
 
 // AuthResponse represents the authentication response with user and tokens
 // Uses sqlc.GetUserByEmailOrUsernameRow which includes role info
 type AuthResponse struct {
-	User         *sqlc.GetUserByEmailOrUsernameRow
-	AccessToken  string
-	RefreshToken string
+	User                 *sqlc.GetUserByEmailOrUsernameRow
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	RefreshToken         string
+	// PasswordChangeToken is set instead of the tokens above when Login fails with domain.ErrPasswordExpired
+	PasswordChangeToken string
+	// PendingApproval is set instead of the tokens when Register created an inactive account (AUTH_NEW_USER_ACTIVE=false)
+	PendingApproval bool
+}
+
+// UserPage is one page of ListUsers
+// NextCursor is empty on the last page
+type UserPage struct {
+	Users      []sqlc.ListUsersRow
+	NextCursor string
+	// RolePermissions holds the permissions of every primary role on the page, keyed by role ID
+	RolePermissions map[uuid.UUID][]string
+}
+
+// UserStats are the reporting aggregates returned by GetStats
+type UserStats struct {
+	TotalUsers          int64
+	UsersByRole         []sqlc.CountUsersByRoleRow
+	RegistrationsPerDay []sqlc.CountRegistrationsPerDayRow
+	Since               time.Time // Start of the first day counted in RegistrationsPerDay
+}
+
+// RoleDetails is a role with its permissions, as returned by ListRoles and GetRole
+type RoleDetails struct {
+	Role        sqlc.Role
+	Permissions []domain.PermissionGrant
+	IsDefault   bool // Assigned to newly registered users
 }
 
 // TokenResponse represents token refresh response
 type TokenResponse struct {
-	AccessToken string
+	AccessToken          string
+	AccessTokenExpiresAt time.Time
+	RefreshToken         string // Rotated refresh token, the presented one is no longer valid
 }
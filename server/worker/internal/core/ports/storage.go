@@ -0,0 +1,27 @@
+package ports
+
+import (
+	"context"
+	"time"
+
+	"worker/internal/core/domain"
+)
+
+// ObjectStorage defines the interface for S3-compatible object storage
+type ObjectStorage interface {
+	// PresignPut returns a URL that allows uploading an object with the given content type and exact length
+	PresignPut(ctx context.Context, key, contentType string, contentLength int64, expires time.Duration) (string, error)
+
+	// Stat retrieves metadata of a stored object
+	// Returns domain.ErrObjectNotFound if the object does not exist
+	Stat(ctx context.Context, key string) (*domain.ObjectInfo, error)
+
+	// Delete removes an object
+	Delete(ctx context.Context, key string) error
+
+	// List returns the keys of the objects whose key starts with prefix
+	List(ctx context.Context, prefix string) ([]string, error)
+
+	// PublicURL returns the URL an object is publicly served from
+	PublicURL(key string) string
+}
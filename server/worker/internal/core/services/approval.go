@@ -0,0 +1,151 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/common/utils"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// maxRejectionReasonLength bounds the reason RejectUser keeps in the audit log and sends to the notifier
+const maxRejectionReasonLength = 500
+
+// awaitingApproval reports whether an account still waits for an admin: Register created it inactive
+// (AUTH_NEW_USER_ACTIVE=false), it never logged in and it was not rejected
+// An account deactivated before its first login cannot be told apart and counts as pending
+func awaitingApproval(isActive *bool, lastLogin, scheduledDeletionAt pgtype.Timestamp) bool {
+	return !utils.PtrBoolValue(isActive) && !lastLogin.Valid && !scheduledDeletionAt.Valid
+}
+
+// ListPendingApprovals returns one page of the caller's tenant accounts waiting for approval, oldest first
+// It is ListUsers restricted to pending accounts and requires domain.PermissionUsersRead
+func (s *AuthService) ListPendingApprovals(ctx context.Context, accessToken, cursor string, pageSize int32) (*ports.UserPage, error) {
+	return s.ListUsers(ctx, accessToken, &domain.UserListQuery{
+		Cursor:          cursor,
+		PageSize:        pageSize,
+		SortField:       domain.UserSortCreatedAt,
+		PendingApproval: true,
+	})
+}
+
+// ApproveUser activates a pending account, requires domain.PermissionUsersUpdate
+// A non-empty roleCode replaces the default role Register assigned, which also requires domain.PermissionRolesUpdate
+func (s *AuthService) ApproveUser(ctx context.Context, callerID, targetID uuid.UUID, roleCode string) error {
+	if err := s.requirePermission(ctx, callerID, domain.PermissionUsersUpdate); err != nil {
+		return err
+	}
+	var role *sqlc.Role
+	if roleCode = strings.TrimSpace(roleCode); roleCode != "" {
+		if err := s.requirePermission(ctx, callerID, domain.PermissionRolesUpdate); err != nil {
+			return err
+		}
+		var err error
+		if role, err = s.findRoleByCode(ctx, roleCode); err != nil {
+			return err
+		}
+	}
+
+	target, err := s.pendingTarget(ctx, callerID, targetID)
+	if err != nil {
+		return err
+	}
+
+	err = s.unitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		if role != nil {
+			if err := repos.Users.SetPrimaryRole(ctx, targetID, role.ID); err != nil {
+				return err
+			}
+		}
+		return repos.Users.SetActive(ctx, targetID, true)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return mapUserLookupError(err)
+		}
+		return databaseError(err, "failed to approve user")
+	}
+
+	now := s.clock.Now()
+	s.auditLog.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditUserApproved,
+		UserID:   targetID.String(),
+		TenantID: target.TenantID,
+		Client:   domain.ClientInfoFromContext(ctx),
+		ActorID:  callerID.String(),
+		At:       now,
+	})
+	s.notifyApproved(ctx, target, now)
+	return nil
+}
+
+// RejectUser refuses a pending account, requires domain.PermissionUsersDelete
+// The account stays inactive and is scheduled for deletion, so the deletion sweeper erases it once
+// AUTH_DELETION_GRACE_PERIOD is over; the reason is not stored with the account, it only goes to the
+// audit log and the notifier
+func (s *AuthService) RejectUser(ctx context.Context, callerID, targetID uuid.UUID, reason string) error {
+	if err := s.requirePermission(ctx, callerID, domain.PermissionUsersDelete); err != nil {
+		return err
+	}
+	if reason = strings.TrimSpace(reason); reason == "" {
+		return domain.NewFieldError(domain.ErrFieldRequired, "reason", "reason is required to reject a user")
+	}
+	if err := checkFieldLength("reason", reason, maxRejectionReasonLength); err != nil {
+		return err
+	}
+
+	target, err := s.pendingTarget(ctx, callerID, targetID)
+	if err != nil {
+		return err
+	}
+
+	now := s.clock.Now()
+	if _, err := s.userRepo.ScheduleDeletion(ctx, targetID, now.Add(s.authConfig.DeletionGracePeriod)); err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return mapUserLookupError(err)
+		}
+		return databaseError(err, "failed to reject user")
+	}
+
+	s.auditLog.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditUserRejected,
+		UserID:   targetID.String(),
+		TenantID: target.TenantID,
+		Client:   domain.ClientInfoFromContext(ctx),
+		Reason:   reason,
+		ActorID:  callerID.String(),
+		At:       now,
+	})
+	s.notifyRejected(ctx, target, reason, now)
+	return nil
+}
+
+// pendingTarget returns the target of an approval decision once it is a pending account of the caller's tenant
+// Users of other tenants are reported as missing
+func (s *AuthService) pendingTarget(ctx context.Context, callerID, targetID uuid.UUID) (*sqlc.GetUserByIDRow, error) {
+	caller, err := s.userRepo.FindByID(ctx, callerID)
+	if err != nil {
+		return nil, mapUserLookupError(err)
+	}
+	target, err := s.userRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, mapUserLookupError(err)
+	}
+	if target.TenantID != caller.TenantID {
+		return nil, mapUserLookupError(domain.ErrUserNotFound)
+	}
+	if !awaitingApproval(target.IsActive, target.LastLogin, target.ScheduledDeletionAt) {
+		return nil, domain.NewAuthError(
+			domain.ErrUserNotPending,
+			"user account is not waiting for approval",
+			domain.CodeUserNotPending,
+		)
+	}
+	return target, nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// newApprovalTestService returns a service registering inactive accounts, with an active ADMIN account
+// named admin, and the admin's ID and access token
+func newApprovalTestService(t *testing.T) (*testService, uuid.UUID, string) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.NewUserActive = false
+	})
+	adminID := s.registerAdmin(t)
+	return s, adminID, s.mustLogin(t, "admin").AccessToken
+}
+
+// pendingUsernames lists the usernames ListPendingApprovals returns
+func pendingUsernames(t *testing.T, s *testService, accessToken string) []string {
+	page, err := s.ListPendingApprovals(context.Background(), accessToken, "", 0)
+	if err != nil {
+		t.Fatalf("list pending approvals: %v", err)
+	}
+	var names []string
+	for _, user := range page.Users {
+		names = append(names, user.Username)
+	}
+	return names
+}
+
+func TestApproveUser(t *testing.T) {
+	s, adminID, adminToken := newApprovalTestService(t)
+	ctx := context.Background()
+
+	resp := s.register(t, "bob")
+	if !resp.PendingApproval || resp.AccessToken != "" {
+		t.Fatalf("register = %+v, want a pending account without tokens", resp)
+	}
+	_, err := s.login("bob")
+	assertCode(t, err, domain.CodeUserPendingApproval)
+	if got := pendingUsernames(t, s, adminToken); len(got) != 1 || got[0] != "bob" {
+		t.Fatalf("pending approvals = %v, want [bob]", got)
+	}
+
+	if err := s.ApproveUser(ctx, adminID, resp.User.ID, "LECTURER"); err != nil {
+		t.Fatalf("approve: %v", err)
+	}
+	login := s.mustLogin(t, "bob")
+	if login.User.RoleCode == nil || *login.User.RoleCode != "LECTURER" {
+		t.Fatalf("role after approval = %v, want LECTURER", login.User.RoleCode)
+	}
+	if got := pendingUsernames(t, s, adminToken); len(got) != 0 {
+		t.Fatalf("pending approvals after approval = %v, want none", got)
+	}
+
+	// An approved account is no longer pending
+	err = s.ApproveUser(ctx, adminID, resp.User.ID, "")
+	assertCode(t, err, domain.CodeUserNotPending)
+}
+
+func TestRejectUser(t *testing.T) {
+	s, adminID, adminToken := newApprovalTestService(t)
+	ctx := context.Background()
+	bob := s.register(t, "bob").User.ID
+
+	err := s.RejectUser(ctx, adminID, bob, "  ")
+	if !errors.Is(err, domain.ErrFieldRequired) {
+		t.Fatalf("reject without a reason = %v, want ErrFieldRequired", err)
+	}
+
+	if err := s.RejectUser(ctx, adminID, bob, "not a student of this university"); err != nil {
+		t.Fatalf("reject: %v", err)
+	}
+	user, err := s.userRepo.FindByID(ctx, bob)
+	if err != nil {
+		t.Fatalf("find rejected user: %v", err)
+	}
+	if !user.ScheduledDeletionAt.Valid {
+		t.Fatal("rejected user is not scheduled for deletion")
+	}
+	if got := pendingUsernames(t, s, adminToken); len(got) != 0 {
+		t.Fatalf("pending approvals after rejection = %v, want none", got)
+	}
+
+	// A rejected account can be neither approved nor rejected again
+	assertCode(t, s.ApproveUser(ctx, adminID, bob, ""), domain.CodeUserNotPending)
+	assertCode(t, s.RejectUser(ctx, adminID, bob, "again"), domain.CodeUserNotPending)
+}
+
+func TestApprovalRequiresPermission(t *testing.T) {
+	s, adminID, _ := newApprovalTestService(t)
+	ctx := context.Background()
+
+	carol := s.register(t, "carol").User.ID
+	if err := s.ApproveUser(ctx, adminID, carol, ""); err != nil {
+		t.Fatalf("approve carol: %v", err)
+	}
+	bob := s.register(t, "bob").User.ID
+
+	assertCode(t, s.ApproveUser(ctx, carol, bob, ""), domain.CodePermissionDenied)
+	assertCode(t, s.RejectUser(ctx, carol, bob, "spam"), domain.CodePermissionDenied)
+}
@@ -3,14 +3,22 @@ package services
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
-	"golang.org/x/crypto/bcrypt"
+	"go.uber.org/zap"
 
+	"worker/internal/adapter/logger"
 	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/common/emaildomain"
+	"worker/internal/common/locale"
+	"worker/internal/common/phone"
+	"worker/internal/common/textnorm"
 	"worker/internal/common/utils"
 	"worker/internal/config"
 	"worker/internal/core/domain"
@@ -23,47 +31,247 @@ var _ ports.AuthService = (*AuthService)(nil)
 // AuthService handles authentication business logic
 // Following Clean Architecture, this service only depends on abstractions (ports)
 type AuthService struct {
-	userRepo ports.UserRepository
-	roleRepo ports.RoleRepository
-	config   *config.JWTConfig
+	userRepo        ports.UserRepository
+	roleRepo        ports.RoleRepository
+	sessionRepo     ports.SessionRepository
+	accessTokens    ports.AccessTokenRepository       // Opaque access tokens, only used with AUTH_TOKEN_TYPE=opaque
+	resetCodes      ports.PasswordResetCodeRepository // One-time reset codes, only issued with AUTH_RESET_TOKEN_TYPE=otp
+	unitOfWork      ports.UnitOfWork
+	objectStorage   ports.ObjectStorage
+	permissionCache ports.PermissionCache
+	tokenVersions   ports.TokenVersionCache
+	revocations     ports.RevocationBroker
+	auditLog        ports.AuditLog
+	lastLogins      ports.LastLoginRecorder
+	riskEvaluator   ports.RiskEvaluator
+	loginThrottle   ports.LoginThrottle
+	claimsEnricher  ports.ClaimsEnricher
+	rateLimiter     ports.RateLimiter
+	captcha         ports.CaptchaVerifier
+	hasher          ports.PasswordHasher
+	notifier        ports.Notifier
+	dbStats         ports.DatabaseStats
+	stats           ports.ReadOnlyQuerier
+	clock           ports.Clock
+	config          *config.JWTConfig
+	authConfig      *config.AuthConfig
+	avatarConfig    *config.AvatarConfig
+	reloader        *config.Reloader    // Token expirations, reloadable at runtime
+	locales         *locale.Set         // AUTH_SUPPORTED_LOCALES
+	emailDomains    *emaildomain.Policy // AUTH_ALLOWED_EMAIL_DOMAINS and AUTH_BLOCKED_EMAIL_DOMAINS
+	logger          *zap.Logger
+
+	// Precomputed JWT material, built once and shared across requests.
+	// jwt.Parser and the key funcs are immutable, so they are safe for concurrent use.
+	accessKey         []byte
+	refreshKey        []byte
+	passwordChangeKey []byte
+	parser            *jwt.Parser
+	accessKeyFunc     jwt.Keyfunc
+	refreshKeyFunc    jwt.Keyfunc
+
+	// Password change tokens: signed with a derived key and scoped by audience
+	passwordChangeParser  *jwt.Parser
+	passwordChangeKeyFunc jwt.Keyfunc
+
+	// Password reset tokens: issued by ForcePasswordReset, signed with their own derived key
+	passwordResetKey     []byte
+	passwordResetParser  *jwt.Parser
+	passwordResetKeyFunc jwt.Keyfunc
+
+	// Password reset codes: stored as an HMAC under their own derived key
+	resetCodeKey []byte
+
+	// Hash compared against for unknown accounts with AUTH_HIDE_INACTIVE, made on first use
+	decoyOnce sync.Once
+	decoyHash string
 }
 
 // NewAuthService creates a new AuthService instance
 func NewAuthService(
 	userRepo ports.UserRepository,
 	roleRepo ports.RoleRepository,
+	sessionRepo ports.SessionRepository,
+	accessTokens ports.AccessTokenRepository,
+	resetCodes ports.PasswordResetCodeRepository,
+	unitOfWork ports.UnitOfWork,
+	objectStorage ports.ObjectStorage,
+	permissionCache ports.PermissionCache,
+	tokenVersions ports.TokenVersionCache,
+	revocations ports.RevocationBroker,
+	auditLog ports.AuditLog,
+	lastLogins ports.LastLoginRecorder,
+	riskEvaluator ports.RiskEvaluator,
+	loginThrottle ports.LoginThrottle,
+	claimsEnricher ports.ClaimsEnricher,
+	rateLimiter ports.RateLimiter,
+	captcha ports.CaptchaVerifier,
+	hasher ports.PasswordHasher,
+	notifier ports.Notifier,
+	dbStats ports.DatabaseStats,
+	stats ports.ReadOnlyQuerier,
+	clock ports.Clock,
 	jwtConfig *config.JWTConfig,
+	authConfig *config.AuthConfig,
+	avatarConfig *config.AvatarConfig,
+	reloader *config.Reloader,
+	logger *zap.Logger,
 ) *AuthService {
+	accessKey := []byte(jwtConfig.AccessSecret)
+	refreshKey := []byte(jwtConfig.RefreshSecret)
+	// Derived so password change tokens never validate as access or refresh tokens
+	passwordChangeKey := deriveKey(accessKey, passwordChangeAudience)
+	passwordResetKey := deriveKey(accessKey, passwordResetAudience)
+	accessKeyFunc := hmacKeyFunc(accessKey)
+	if jwtConfig.PreviousAccessSecret != "" {
+		accessKeyFunc = hmacKeySetFunc(accessKey, []byte(jwtConfig.PreviousAccessSecret))
+		logger.Info("Access tokens signed with JWT_PREVIOUS_ACCESS_SECRET are still accepted")
+	}
+
 	return &AuthService{
-		userRepo: userRepo,
-		roleRepo: roleRepo,
-		config:   jwtConfig,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		sessionRepo:       sessionRepo,
+		accessTokens:      accessTokens,
+		resetCodes:        resetCodes,
+		unitOfWork:        unitOfWork,
+		objectStorage:     objectStorage,
+		permissionCache:   permissionCache,
+		tokenVersions:     tokenVersions,
+		revocations:       revocations,
+		auditLog:          auditLog,
+		lastLogins:        lastLogins,
+		riskEvaluator:     riskEvaluator,
+		loginThrottle:     loginThrottle,
+		claimsEnricher:    claimsEnricher,
+		rateLimiter:       rateLimiter,
+		captcha:           captcha,
+		hasher:            hasher,
+		notifier:          notifier,
+		dbStats:           dbStats,
+		stats:             stats,
+		clock:             clock,
+		config:            jwtConfig,
+		authConfig:        authConfig,
+		avatarConfig:      avatarConfig,
+		reloader:          reloader,
+		locales:           locale.MustNewSet(authConfig.SupportedLocales),
+		emailDomains:      emaildomain.MustNewPolicy(authConfig.AllowedEmailDomains, authConfig.BlockedEmailDomains),
+		logger:            logger,
+		accessKey:         accessKey,
+		refreshKey:        refreshKey,
+		passwordChangeKey: passwordChangeKey,
+		parser:            jwt.NewParser(jwt.WithTimeFunc(clock.Now)),
+		accessKeyFunc:     accessKeyFunc,
+		refreshKeyFunc:    hmacKeyFunc(refreshKey),
+
+		passwordChangeParser:  jwt.NewParser(jwt.WithAudience(passwordChangeAudience), jwt.WithTimeFunc(clock.Now)),
+		passwordChangeKeyFunc: hmacKeyFunc(passwordChangeKey),
+
+		passwordResetKey:     passwordResetKey,
+		passwordResetParser:  jwt.NewParser(jwt.WithAudience(passwordResetAudience), jwt.WithTimeFunc(clock.Now)),
+		passwordResetKeyFunc: hmacKeyFunc(passwordResetKey),
+
+		resetCodeKey: deriveKey(accessKey, resetCodePurpose),
+	}
+}
+
+// hmacKeyFunc returns a jwt.Keyfunc that only accepts HMAC-signed tokens
+func hmacKeyFunc(key []byte) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, domain.ErrTokenMalformed
+		}
+		return key, nil
+	}
+}
+
+// hmacKeySetFunc is hmacKeyFunc accepting a signature by any of keys, tried in order
+func hmacKeySetFunc(keys ...[]byte) jwt.Keyfunc {
+	set := jwt.VerificationKeySet{}
+	for _, key := range keys {
+		set.Keys = append(set.Keys, key)
+	}
+	return func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, domain.ErrTokenMalformed
+		}
+		return set, nil
 	}
 }
 
 // AccessTokenClaims represents the claims in an access token
 type AccessTokenClaims struct {
 	jwt.RegisteredClaims
-	Username string `json:"username"`
-	Role     string `json:"role"`
+	Username string   `json:"username"`
+	Role     string   `json:"role"`                // Primary role (users.role_id), kept for backward compatibility
+	Roles    []string `json:"roles"`               // All role codes assigned to the user
+	TenantID string   `json:"tenant_id,omitempty"` // Only set in multi-tenant mode
+	Version  int32    `json:"ver,omitempty"`       // users.token_version at issue time, bumped to revoke the token
+	Locale   string   `json:"locale,omitempty"`    // Preferred BCP 47 tag, empty for users registered before locales
+	// SessionID is the session the token was issued with; validating the token marks the session as seen
+	SessionID string `json:"sid,omitempty"`
+	// Permissions at issue time with AUTH_EMBED_PERMISSIONS, absent when disabled, empty or over the cap
+	Permissions []string `json:"perms,omitempty"`
+	// Custom holds the claims added by the ports.ClaimsEnricher, encoded next to the standard ones
+	Custom map[string]any `json:"-"`
 }
 
 // RefreshTokenClaims represents the claims in a refresh token
 type RefreshTokenClaims struct {
 	jwt.RegisteredClaims
+	SessionID string `json:"sid"`
+	Nonce     string `json:"nonce"` // Rotated on every refresh, must match the session's current nonce
 }
 
 // Register creates a new user account
 func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest) (*ports.AuthResponse, error) {
-	// Step 1: Check if email already exists
-	emailExists, err := s.userRepo.ExistsByEmail(ctx, req.Email)
+	// Step 0: Reject bots before doing any work, then resolve the tenant; email and username are unique per tenant
+	if err := s.verifyCaptcha(ctx, req.CaptchaToken); err != nil {
+		return nil, err
+	}
+	tenantID, err := s.resolveTenant(ctx)
 	if err != nil {
-		return nil, domain.NewAuthError(
-			domain.ErrDatabaseOperation,
-			"failed to check email existence",
-			domain.CodeInternalError,
+		return nil, err
+	}
+
+	// Normalize user-entered text so look-alike usernames and emails collide instead of coexisting
+	normalized := *req
+	normalized.Username = s.normalizeUsername(req.Username)
+	normalized.Email = textnorm.Identifier(req.Email)
+	normalized.FullName = textnorm.Name(req.FullName)
+	req = &normalized
+	if err := s.checkRegistrationLengths(req); err != nil {
+		return nil, err
+	}
+	if emailDomain := emaildomain.Of(req.Email); !s.emailDomains.Allows(emailDomain) {
+		return nil, domain.NewFieldError(
+			domain.ErrEmailDomainNotAllowed,
+			"email",
+			fmt.Sprintf("email addresses at %q cannot be used to register", emailDomain),
 		)
 	}
+
+	// Normalize the optional phone number to E.164
+	var phoneNumber *string
+	if req.Phone != "" {
+		normalized, err := phone.Normalize(req.Phone, s.authConfig.PhoneDefaultRegion)
+		if err != nil {
+			return nil, domain.NewFieldError(domain.ErrInvalidPhone, "phone", fmt.Sprintf("invalid phone number: %v", err))
+		}
+		phoneNumber = &normalized
+	}
+
+	userLocale, err := s.resolveLocale(ctx, req.Locale)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 1: Check if email already exists
+	emailExists, err := s.userRepo.ExistsByEmail(ctx, tenantID, req.Email)
+	if err != nil {
+		return nil, databaseError(err, "failed to check email existence")
+	}
 	if emailExists {
 		return nil, domain.NewAuthError(
 			domain.ErrEmailAlreadyExists,
@@ -73,13 +281,9 @@ func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest)
 	}
 
 	// Step 2: Check if username already exists
-	usernameExists, err := s.userRepo.ExistsByUsername(ctx, req.Username)
+	usernameExists, err := s.usernameTaken(ctx, tenantID, req.Username)
 	if err != nil {
-		return nil, domain.NewAuthError(
-			domain.ErrDatabaseOperation,
-			"failed to check username existence",
-			domain.CodeInternalError,
-		)
+		return nil, databaseError(err, "failed to check username existence")
 	}
 	if usernameExists {
 		return nil, domain.NewAuthError(
@@ -89,8 +293,8 @@ func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest)
 		)
 	}
 
-	// Step 3: Hash the password using bcrypt with default cost
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	// Step 3: Hash the password with the AUTH_PASSWORD_HASHER algorithm
+	hashedPassword, err := s.hasher.Hash(req.Password)
 	if err != nil {
 		return nil, domain.NewAuthError(
 			domain.ErrHashingPassword,
@@ -110,38 +314,68 @@ func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest)
 	}
 
 	// Step 5: Get default role
-	defaultRole, err := s.roleRepo.GetDefaultRole(ctx)
+	defaultRole, err := s.resolveDefaultRole(ctx)
 	if err != nil {
-		return nil, domain.NewAuthError(
-			domain.ErrDefaultRoleNotFound,
-			"failed to assign default role",
-			domain.CodeInternalError,
-		)
+		return nil, err
 	}
 
 	// Step 6: Create user params for sqlc
-	now := time.Now()
-	isActive := true
+	now := s.clock.Now()
+	isActive := s.authConfig.NewUserActive
 	createParams := sqlc.CreateUserParams{
 		ID:        userID,
 		RoleID:    defaultRole.ID,
 		Email:     req.Email,
 		Username:  req.Username,
-		Password:  string(hashedPassword),
+		Password:  hashedPassword,
 		FullName:  req.FullName,
+		Phone:     phoneNumber,
 		IsActive:  &isActive,
 		CreatedAt: pgtype.Timestamp{Time: now, Valid: true},
 		UpdatedAt: pgtype.Timestamp{Time: now, Valid: true},
+		TenantID:  tenantID,
+		Locale:    &userLocale,
 	}
 
-	// Step 7: Save to database via repository
-	createdUser, err := s.userRepo.CreateUser(ctx, createParams)
+	// Step 7: Save to database in a transaction, unless the client already gave up
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var createdUser *sqlc.User
+	err = s.unitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		created, err := repos.Users.CreateUser(ctx, createParams)
+		if err != nil {
+			return err
+		}
+		createdUser = created
+		// Cancelled during the insert: roll back rather than leave an account the client never heard of
+		return ctx.Err()
+	})
 	if err != nil {
-		return nil, domain.NewAuthError(
-			domain.ErrDatabaseOperation,
-			"failed to create user account",
-			domain.CodeInternalError,
-		)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+		switch {
+		case errors.Is(err, domain.ErrEmailAlreadyExists):
+			return nil, domain.NewAuthError(
+				domain.ErrEmailAlreadyExists,
+				"email is already registered",
+				domain.CodeUserAlreadyExists,
+			)
+		case errors.Is(err, domain.ErrUsernameAlreadyExists):
+			return nil, domain.NewAuthError(
+				domain.ErrUsernameAlreadyExists,
+				"username is already taken",
+				domain.CodeUserAlreadyExists,
+			)
+		case errors.Is(err, domain.ErrUserAlreadyExists):
+			return nil, domain.NewAuthError(
+				domain.ErrUserAlreadyExists,
+				"user already exists",
+				domain.CodeUserAlreadyExists,
+			)
+		}
+		return nil, databaseError(err, "failed to create user account")
 	}
 
 	// Step 8: Build response with role info
@@ -159,116 +393,258 @@ func (s *AuthService) Register(ctx context.Context, req *domain.RegisterRequest)
 		LastLogin: createdUser.LastLogin,
 		CreatedAt: createdUser.CreatedAt,
 		UpdatedAt: createdUser.UpdatedAt,
+		TenantID:  createdUser.TenantID,
+		Locale:    createdUser.Locale,
 		RoleName:  &defaultRole.Name,
 		RoleCode:  &defaultRole.Code,
 	}
+	s.notifyRegistered(ctx, userWithRole)
+
+	// Step 9: With AUTH_NEW_USER_ACTIVE=false the account waits for an admin, no tokens are issued
+	if !isActive {
+		return &ports.AuthResponse{User: userWithRole, PendingApproval: true}, nil
+	}
 
-	// Step 9: Generate tokens
-	accessToken, err := s.generateAccessToken(userWithRole)
+	// Step 10: Start a session and generate its tokens
+	refreshToken, sessionID, err := s.startSession(ctx, userID)
 	if err != nil {
-		return nil, domain.NewAuthError(
-			domain.ErrGeneratingToken,
-			"failed to generate access token",
-			domain.CodeInternalError,
-		)
+		return nil, err
 	}
 
-	refreshToken, err := s.generateRefreshToken(userID.String())
+	accessToken, accessExpiresAt, err := s.generateAccessToken(ctx, userWithRole, []string{defaultRole.Code}, sessionID)
 	if err != nil {
 		return nil, domain.NewAuthError(
 			domain.ErrGeneratingToken,
-			"failed to generate refresh token",
+			"failed to generate access token",
 			domain.CodeInternalError,
 		)
 	}
 
 	return &ports.AuthResponse{
-		User:         userWithRole,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		User:                 userWithRole,
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessExpiresAt,
+		RefreshToken:         refreshToken,
 	}, nil
 }
 
 // Login authenticates a user and generates JWT tokens
 func (s *AuthService) Login(ctx context.Context, req *domain.LoginRequest) (*ports.AuthResponse, error) {
-	// Step 1: Fetch user from repository by email or username
-	user, err := s.userRepo.FindByEmailOrUsername(ctx, req.Identifier)
+	// Steps 0-3: Captcha, user lookup, active account and password
+	user, attempt, err := s.verifyCredentials(ctx, req)
 	if err != nil {
-		if errors.Is(err, domain.ErrUserNotFound) {
-			return nil, domain.NewAuthError(
-				domain.ErrUserNotFound,
-				"user not found with provided credentials",
-				domain.CodeUserNotFound,
-			)
+		// Also matches the errors AUTH_HIDE_INACTIVE masks, they wrap the underlying failure
+		if errors.Is(err, domain.ErrIncorrectPassword) || errors.Is(err, domain.ErrUserNotFound) {
+			s.throttleFailedLogin(ctx, req.Identifier)
 		}
+		return nil, err
+	}
+
+	// Step 3a: An admin forced a reset, the password is considered compromised
+	if user.MustResetPassword {
 		return nil, domain.NewAuthError(
-			domain.ErrDatabaseOperation,
-			"failed to fetch user",
-			domain.CodeInternalError,
+			domain.ErrPasswordResetRequired,
+			"password must be reset with the reset token provided by an administrator",
+			domain.CodePasswordResetRequired,
 		)
 	}
 
-	// Step 2: Check if user account is active
-	if !utils.PtrBoolValue(user.IsActive) {
+	// Step 3a: With AUTH_SCHEDULED_DELETION_LOGIN=block, the account only opens again once the deletion is cancelled
+	if user.ScheduledDeletionAt.Valid && s.authConfig.ScheduledDeletionLogin == config.ScheduledDeletionLoginBlock {
 		return nil, domain.NewAuthError(
-			domain.ErrUserInactive,
-			"user account is deactivated",
-			domain.CodeInvalidCredentials,
+			domain.ErrDeletionScheduled,
+			"account is scheduled for deletion, cancel the deletion to sign in",
+			domain.CodeDeletionScheduled,
 		)
 	}
 
-	// Step 3: Compare provided password with hashed password using bcrypt
-	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password))
-	if err != nil {
-		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+	// Step 3b: Correct credentials may still be challenged or denied by the risk evaluator
+	if err := s.evaluateLoginRisk(ctx, attempt); err != nil {
+		return nil, err
+	}
+
+	// Step 3c: An expired password only grants a short-lived password change token
+	if s.isPasswordExpired(user.PasswordChangedAt) {
+		changeToken, err := s.generatePasswordChangeToken(user.ID.String())
+		if err != nil {
 			return nil, domain.NewAuthError(
-				domain.ErrIncorrectPassword,
-				"incorrect password",
-				domain.CodeIncorrectPassword,
+				domain.ErrGeneratingToken,
+				"failed to generate password change token",
+				domain.CodeInternalError,
 			)
 		}
-		return nil, domain.NewAuthError(
-			domain.ErrInvalidCredentials,
-			"password verification failed",
-			domain.CodeInternalError,
+		user.Password = ""
+		return &ports.AuthResponse{
+			User:                user,
+			PasswordChangeToken: changeToken,
+		}, domain.NewAuthError(
+			domain.ErrPasswordExpired,
+			"password has expired and must be changed",
+			domain.CodePasswordExpired,
 		)
 	}
 
-	// Step 4: Generate Access Token
-	accessToken, err := s.generateAccessToken(user)
+	// Step 4: Resolve the roles of the Access Token
+	if err := s.ensureTokenRole(ctx, user); err != nil {
+		return nil, err
+	}
+	roleCodes, err := s.getRoleCodes(ctx, user.ID)
 	if err != nil {
-		return nil, domain.NewAuthError(
-			domain.ErrGeneratingToken,
-			"failed to generate access token",
-			domain.CodeInternalError,
-		)
+		return nil, err
 	}
 
-	// Step 5: Generate Refresh Token
-	refreshToken, err := s.generateRefreshToken(user.ID.String())
+	// Step 5: Start a session and generate its Refresh Token, then the Access Token tied to it
+	refreshToken, sessionID, err := s.startSession(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, accessExpiresAt, err := s.generateAccessToken(ctx, user, roleCodes, sessionID)
 	if err != nil {
 		return nil, domain.NewAuthError(
 			domain.ErrGeneratingToken,
-			"failed to generate refresh token",
+			"failed to generate access token",
 			domain.CodeInternalError,
 		)
 	}
 
-	// Step 6: Update last login timestamp (non-blocking)
-	go func() {
-		_ = s.userRepo.UpdateLastLogin(context.Background(), user.ID)
-	}()
+	// Step 6: Record the login time and client (non-blocking)
+	s.recordLogin(ctx, user)
+	s.riskEvaluator.RecordSuccess(ctx, attempt)
+	s.loginThrottle.Reset(ctx, s.loginThrottleKey(ctx, req.Identifier))
 
 	// Step 7: Clear password before returning
 	user.Password = ""
 
 	return &ports.AuthResponse{
-		User:         user,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
+		User:                 user,
+		AccessToken:          accessToken,
+		AccessTokenExpiresAt: accessExpiresAt,
+		RefreshToken:         refreshToken,
 	}, nil
 }
 
+// verifyCredentials runs the credential checks of Login: captcha, user lookup, active account and password
+// A wrong password counts towards the risk evaluator's limits
+// With AUTH_HIDE_INACTIVE an unknown account, a wrong password and a deactivated account all fail alike,
+// after a password comparison, so neither the answer nor its timing reveals which; only the log tells why
+func (s *AuthService) verifyCredentials(ctx context.Context, req *domain.LoginRequest) (*sqlc.GetUserByEmailOrUsernameRow, domain.LoginAttempt, error) {
+	// Step 0: Reject bots before looking the user up
+	if err := s.verifyCaptcha(ctx, req.CaptchaToken); err != nil {
+		return nil, domain.LoginAttempt{}, err
+	}
+
+	// Step 1: Fetch user from repository by email or username within the tenant, as AUTH_LOGIN_IDENTIFIER allows
+	tenantID, err := s.resolveTenant(ctx)
+	if err != nil {
+		return nil, domain.LoginAttempt{}, err
+	}
+
+	user, err := s.findLoginUser(ctx, tenantID, s.normalizeLoginIdentifier(req.Identifier))
+	if err != nil {
+		if errors.Is(err, domain.ErrLoginIdentifier) {
+			return nil, domain.LoginAttempt{}, err
+		}
+		if errors.Is(err, domain.ErrUserNotFound) {
+			if s.authConfig.HideInactive {
+				// Spend the time a password comparison takes, as for an existing account
+				_ = s.hasher.Compare(s.decoyPasswordHash(), req.Password)
+				return nil, domain.LoginAttempt{}, s.hiddenLoginFailure(ctx, domain.ErrUserNotFound)
+			}
+			return nil, domain.LoginAttempt{}, domain.NewAuthError(
+				domain.ErrUserNotFound,
+				"user not found with provided credentials",
+				domain.CodeUserNotFound,
+			)
+		}
+		return nil, domain.LoginAttempt{}, databaseError(err, "failed to fetch user")
+	}
+
+	// Step 2: Check if user account is active; with AUTH_HIDE_INACTIVE only once the password matched
+	if !s.authConfig.HideInactive {
+		if err := inactiveAccountError(user); err != nil {
+			return nil, domain.LoginAttempt{}, err
+		}
+	}
+
+	// Step 3: Compare provided password with the stored hash, whichever algorithm made it
+	attempt := loginAttempt(ctx, user)
+	err = s.hasher.Compare(user.Password, req.Password)
+	if err != nil {
+		if errors.Is(err, domain.ErrIncorrectPassword) {
+			s.riskEvaluator.RecordFailure(ctx, attempt)
+			if s.authConfig.HideInactive {
+				return nil, domain.LoginAttempt{}, s.hiddenLoginFailure(ctx, domain.ErrIncorrectPassword,
+					zap.String("user_id", user.ID.String()))
+			}
+			return nil, domain.LoginAttempt{}, domain.NewAuthError(
+				domain.ErrIncorrectPassword,
+				"incorrect password",
+				domain.CodeIncorrectPassword,
+			)
+		}
+		return nil, domain.LoginAttempt{}, domain.NewAuthError(
+			domain.ErrInvalidCredentials,
+			"password verification failed",
+			domain.CodeInternalError,
+		)
+	}
+	if s.authConfig.HideInactive {
+		if err := inactiveAccountError(user); err != nil {
+			return nil, domain.LoginAttempt{}, s.hiddenLoginFailure(ctx, err,
+				zap.String("user_id", user.ID.String()))
+		}
+	}
+	s.upgradePasswordHash(ctx, user, req.Password)
+	return user, attempt, nil
+}
+
+// inactiveAccountError returns why a deactivated or unapproved account may not log in, nil for an active one
+func inactiveAccountError(user *sqlc.GetUserByEmailOrUsernameRow) error {
+	if utils.PtrBoolValue(user.IsActive) {
+		return nil
+	}
+	if awaitingApproval(user.IsActive, user.LastLogin, user.ScheduledDeletionAt) {
+		return domain.NewAuthError(
+			domain.ErrUserPendingApproval,
+			"user account is waiting for an administrator to approve it",
+			domain.CodeUserPendingApproval,
+		)
+	}
+	return domain.NewAuthError(
+		domain.ErrUserInactive,
+		"user account is deactivated",
+		domain.CodeUserInactive,
+	)
+}
+
+// hiddenLoginFailure logs why a login failed and returns the generic error AUTH_HIDE_INACTIVE answers with
+// The error still wraps cause, so Login throttles the failures it would throttle without AUTH_HIDE_INACTIVE;
+// clients only see its code and message
+func (s *AuthService) hiddenLoginFailure(ctx context.Context, cause error, fields ...zap.Field) error {
+	logger.FromContext(ctx, s.logger).Info("Login refused, answered as invalid credentials",
+		append(fields, zap.String("reason", cause.Error()))...)
+	return domain.NewAuthError(
+		fmt.Errorf("%w: %w", domain.ErrInvalidCredentials, cause),
+		"invalid credentials",
+		domain.CodeInvalidCredentials,
+	)
+}
+
+// decoyPasswordHash returns a hash of a random password, made once with the configured hasher
+// Comparing against it costs what comparing against a real hash does
+func (s *AuthService) decoyPasswordHash() string {
+	s.decoyOnce.Do(func() {
+		hash, err := s.hasher.Hash(uuid.NewString())
+		if err != nil {
+			s.logger.Warn("Failed to hash the decoy password", zap.Error(err))
+			return
+		}
+		s.decoyHash = hash
+	})
+	return s.decoyHash
+}
+
 // RefreshAccessToken generates a new access token using a valid refresh token
 func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshToken string) (*ports.TokenResponse, error) {
 	// Step 1: Parse and validate the refresh token
@@ -287,7 +663,7 @@ func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshToken strin
 		)
 	}
 
-	userID, err := uuid.Parse(userIDStr)
+	userID, err := s.subjectUserID(userIDStr)
 	if err != nil {
 		return nil, domain.NewAuthError(
 			domain.ErrInvalidToken,
@@ -306,33 +682,52 @@ func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshToken strin
 				domain.CodeUserNotFound,
 			)
 		}
-		return nil, domain.NewAuthError(
-			domain.ErrDatabaseOperation,
-			"failed to verify user",
-			domain.CodeInternalError,
-		)
+		return nil, databaseError(err, "failed to verify user")
 	}
 
 	if !utils.PtrBoolValue(user.IsActive) {
 		return nil, domain.NewAuthError(
 			domain.ErrUserInactive,
 			"user account is deactivated",
-			domain.CodeInvalidCredentials,
+			domain.CodeUserInactive,
 		)
 	}
 
+	if err := s.checkTenant(ctx, user.TenantID); err != nil {
+		return nil, err
+	}
+
 	// Step 4: Convert GetUserByIDRow to GetUserByEmailOrUsernameRow for token generation
 	userForToken := &sqlc.GetUserByEmailOrUsernameRow{
-		ID:        user.ID,
-		RoleID:    user.RoleID,
-		Email:     user.Email,
-		Username:  user.Username,
-		RoleName:  user.RoleName,
-		RoleCode:  user.RoleCode,
+		ID:           user.ID,
+		RoleID:       user.RoleID,
+		Email:        user.Email,
+		Username:     user.Username,
+		TenantID:     user.TenantID,
+		TokenVersion: user.TokenVersion,
+		Locale:       user.Locale,
+		RoleName:     user.RoleName,
+		RoleCode:     user.RoleCode,
+	}
+
+	// Step 5: Resolve the roles of the new access token
+	if err := s.ensureTokenRole(ctx, userForToken); err != nil {
+		return nil, err
+	}
+	roleCodes, err := s.getRoleCodes(ctx, user.ID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Step 5: Generate new access token
-	newAccessToken, err := s.generateAccessToken(userForToken)
+	// Step 6: Rotate the session nonce; a stale nonce revokes the session
+	// Rotation comes first so a replayed refresh token never gets an access token, not even a stored opaque one
+	newRefreshToken, err := s.rotateSession(ctx, claims, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Step 7: Generate new access token
+	newAccessToken, accessExpiresAt, err := s.generateAccessToken(ctx, userForToken, roleCodes, claims.SessionID)
 	if err != nil {
 		return nil, domain.NewAuthError(
 			domain.ErrGeneratingToken,
@@ -342,126 +737,694 @@ func (s *AuthService) RefreshAccessToken(ctx context.Context, refreshToken strin
 	}
 
 	return &ports.TokenResponse{
-		AccessToken: newAccessToken,
+		AccessToken:          newAccessToken,
+		AccessTokenExpiresAt: accessExpiresAt,
+		RefreshToken:         newRefreshToken,
 	}, nil
 }
 
 // ValidateAccessToken validates an access token and returns the claims
 func (s *AuthService) ValidateAccessToken(ctx context.Context, tokenString string) (*domain.ValidateTokenResult, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &AccessTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, domain.ErrTokenMalformed
-		}
-		return []byte(s.config.AccessSecret), nil
-	})
-
+	claims, err := s.parseAccessToken(ctx, tokenString)
 	if err != nil {
-		if errors.Is(err, jwt.ErrTokenExpired) {
-			return nil, domain.NewAuthError(
-				domain.ErrTokenExpired,
-				"access token has expired",
-				domain.CodeTokenExpired,
-			)
-		}
-		return nil, domain.NewAuthError(
-			domain.ErrInvalidToken,
-			"invalid access token",
-			domain.CodeInvalidToken,
-		)
+		return nil, err
 	}
 
-	claims, ok := token.Claims.(*AccessTokenClaims)
-	if !ok || !token.Valid {
-		return nil, domain.NewAuthError(
-			domain.ErrInvalidToken,
-			"invalid token claims",
-			domain.CodeInvalidToken,
-		)
-	}
+	custom := s.claimsEnricher.Surface(ctx, claims.Custom)
 
 	// Parse user ID
-	userID, err := uuid.Parse(claims.Subject)
+	userID, err := s.subjectUserID(claims.Subject)
 	if err != nil {
 		return &domain.ValidateTokenResult{
 			Valid:       true,
 			UserID:      claims.Subject,
 			Email:       "",
 			Permissions: []string{},
+			Claims:      custom,
+			Role:        claims.Role,
+			ExpiresAt:   claims.expiry(),
 		}, nil
 	}
 
+	if err := s.checkTokenVersion(ctx, userID, claims); err != nil {
+		return nil, err
+	}
+	s.markSessionSeen(ctx, claims)
+
 	// Fetch user to get email and permissions
 	user, err := s.userRepo.FindByID(ctx, userID)
 	if err != nil {
+		if !errors.Is(err, domain.ErrUserNotFound) {
+			if err := s.permissionLookupFailed(ctx, zap.String("user_id", userID.String()), err); err != nil {
+				return nil, err
+			}
+		}
 		return &domain.ValidateTokenResult{
 			Valid:       true,
-			UserID:      claims.Subject,
+			UserID:      userID.String(),
 			Email:       "",
 			Permissions: []string{},
+			Claims:      custom,
+			Role:        claims.Role,
+			ExpiresAt:   claims.expiry(),
 		}, nil
 	}
 
-	permissions, _ := s.roleRepo.GetPermissionsByRoleID(ctx, user.RoleID)
+	permissions, err := s.tokenPermissions(ctx, user.ID, claims)
+	if err != nil {
+		if err := s.permissionLookupFailed(ctx, zap.String("user_id", user.ID.String()), err); err != nil {
+			return nil, err
+		}
+		permissions = []string{}
+	}
 
 	return &domain.ValidateTokenResult{
 		Valid:       true,
-		UserID:      claims.Subject,
+		UserID:      userID.String(),
 		Email:       user.Email,
 		Permissions: permissions,
+		Claims:      custom,
+		Role:        claims.Role,
+		ExpiresAt:   claims.expiry(),
 	}, nil
 }
 
-// generateAccessToken creates a new JWT access token
-func (s *AuthService) generateAccessToken(user *sqlc.GetUserByEmailOrUsernameRow) (string, error) {
-	now := time.Now()
-	expirationTime := now.Add(s.config.AccessExpiration)
+// GetMyPermissions returns the effective permissions of the access token's user
+func (s *AuthService) GetMyPermissions(ctx context.Context, accessToken string) ([]string, error) {
+	claims, err := s.parseAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	_, permissions, err := s.claimsPermissions(ctx, claims)
+	return permissions, err
+}
 
+// claimsPermissions returns the user ID and effective permissions of already parsed access token claims,
+// once the token version shows the token was not revoked
+func (s *AuthService) claimsPermissions(ctx context.Context, claims *AccessTokenClaims) (uuid.UUID, []string, error) {
+	userID, err := s.subjectUserID(claims.Subject)
+	if err != nil {
+		return uuid.Nil, nil, domain.NewAuthError(
+			domain.ErrInvalidToken,
+			"invalid token subject",
+			domain.CodeInvalidToken,
+		)
+	}
+
+	if err := s.checkTokenVersion(ctx, userID, claims); err != nil {
+		return uuid.Nil, nil, err
+	}
+
+	permissions, err := s.tokenPermissions(ctx, userID, claims)
+	if err != nil {
+		return uuid.Nil, nil, databaseError(err, "failed to resolve permissions")
+	}
+	return userID, permissions, nil
+}
+
+// tokenPermissions returns the permissions embedded in an access token, or resolves them when it carries none
+func (s *AuthService) tokenPermissions(ctx context.Context, userID uuid.UUID, claims *AccessTokenClaims) ([]string, error) {
+	if claims.Permissions != nil {
+		return claims.Permissions, nil
+	}
+	return s.resolvePermissions(ctx, userID)
+}
+
+// embeddedPermissions returns the permissions to embed in a new access token of the user (AUTH_EMBED_PERMISSIONS)
+// It returns nil, leaving validators to resolve them, when embedding is disabled, the user holds more than
+// AUTH_EMBED_PERMISSIONS_MAX or they cannot be resolved; a token is never refused over its permissions claim
+func (s *AuthService) embeddedPermissions(ctx context.Context, userID uuid.UUID) []string {
+	if !s.authConfig.EmbedPermissions {
+		return nil
+	}
+	permissions, err := s.resolvePermissions(ctx, userID)
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Warn("Failed to resolve permissions to embed, issuing the token without them",
+			zap.String("user_id", userID.String()),
+			zap.Error(err),
+		)
+		return nil
+	}
+	if len(permissions) > s.authConfig.EmbedPermissionsMax {
+		logger.FromContext(ctx, s.logger).Warn("User holds more permissions than AUTH_EMBED_PERMISSIONS_MAX, issuing the token without them",
+			zap.String("user_id", userID.String()),
+			zap.Int("permissions", len(permissions)),
+			zap.Int("max", s.authConfig.EmbedPermissionsMax),
+		)
+		return nil
+	}
+	return permissions
+}
+
+// resolvePermissions returns the permissions granted by all roles of a user, using the permission cache
+func (s *AuthService) resolvePermissions(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	if permissions, ok := s.permissionCache.Get(ctx, userID); ok {
+		return permissions, nil
+	}
+
+	permissions, err := s.roleRepo.GetPermissionsByUserID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	s.permissionCache.Set(ctx, userID, permissions)
+	return permissions, nil
+}
+
+// permissionLookupFailed applies AUTH_PERMISSIONS_FAIL_MODE when a valid token's permissions cannot be resolved
+// It returns the error to fail with, or nil when the token is reported valid with no permissions
+// users names the affected user or users in the log
+func (s *AuthService) permissionLookupFailed(ctx context.Context, users zap.Field, err error) error {
+	failClosed := s.authConfig.PermissionsFailMode == config.PermissionsFailClosed
+	logger.FromContext(ctx, s.logger).Error("Failed to resolve permissions of a valid access token",
+		users,
+		zap.Bool("fail_closed", failClosed),
+		zap.Error(err),
+	)
+	if !failClosed {
+		return nil
+	}
+	return databaseError(err, "failed to resolve permissions")
+}
+
+// resolveDefaultRole returns the role assigned to newly registered users.
+// The configured AUTH_DEFAULT_ROLE_CODE takes precedence; when it is unset
+// we fall back to the GetDefaultRole query.
+func (s *AuthService) resolveDefaultRole(ctx context.Context) (*sqlc.Role, error) {
+	if s.authConfig.DefaultRoleCode == "" {
+		role, err := s.roleRepo.GetDefaultRole(ctx)
+		if err != nil {
+			// A missing role is a deployment problem the operator has to fix,
+			// not a transient failure worth retrying
+			if errors.Is(err, domain.ErrDefaultRoleNotFound) {
+				return nil, domain.NewAuthError(
+					domain.ErrDefaultRoleNotFound,
+					"no default role is configured, seed the roles table or set AUTH_DEFAULT_ROLE_CODE",
+					domain.CodeDefaultRoleNotFound,
+				)
+			}
+			return nil, databaseError(err, "failed to assign default role")
+		}
+		return role, nil
+	}
+
+	role, err := s.roleRepo.FindByCode(ctx, s.authConfig.DefaultRoleCode)
+	if err != nil {
+		if errors.Is(err, domain.ErrRoleNotFound) {
+			return nil, domain.NewAuthError(
+				domain.ErrDefaultRoleNotFound,
+				fmt.Sprintf("configured default role %q does not exist, seed it or change AUTH_DEFAULT_ROLE_CODE", s.authConfig.DefaultRoleCode),
+				domain.CodeDefaultRoleNotFound,
+			)
+		}
+		return nil, databaseError(err, "failed to assign default role")
+	}
+	return role, nil
+}
+
+// IntrospectToken returns the claim set of an access or refresh token (RFC 7662 style)
+// Expired, invalid or otherwise unusable tokens return Active=false instead of an error;
+// only infrastructure failures are reported as errors
+func (s *AuthService) IntrospectToken(ctx context.Context, tokenString, tokenTypeHint string) (*domain.IntrospectionResult, error) {
+	inactive := &domain.IntrospectionResult{Active: false}
+
+	// Try the hinted token type first, then fall back to the other one
+	tokenTypes := []string{domain.TokenTypeAccess, domain.TokenTypeRefresh}
+	if tokenTypeHint == domain.TokenTypeRefresh {
+		tokenTypes = []string{domain.TokenTypeRefresh, domain.TokenTypeAccess}
+	}
+
+	var result *domain.IntrospectionResult
+	var access *AccessTokenClaims
+	for _, tokenType := range tokenTypes {
+		var claims *jwt.RegisteredClaims
+		switch tokenType {
+		case domain.TokenTypeAccess:
+			accessClaims, err := s.parseAccessToken(ctx, tokenString)
+			if err != nil {
+				// Opaque tokens are looked up in the database, whose failures must not read as logged out
+				if !isTokenRejection(err) {
+					return nil, err
+				}
+				continue
+			}
+			access = accessClaims
+			claims = &accessClaims.RegisteredClaims
+			result = &domain.IntrospectionResult{
+				Username: accessClaims.Username,
+				Role:     accessClaims.Role,
+				Roles:    accessClaims.Roles,
+				TenantID: accessClaims.TenantID,
+			}
+		case domain.TokenTypeRefresh:
+			refreshClaims, err := s.parseRefreshToken(tokenString)
+			if err != nil {
+				continue
+			}
+			// A refresh token is only usable while its session holds the same nonce
+			active, err := s.isRefreshSessionActive(ctx, refreshClaims)
+			if err != nil {
+				return nil, err
+			}
+			if !active {
+				return inactive, nil
+			}
+			claims = &refreshClaims.RegisteredClaims
+			result = &domain.IntrospectionResult{}
+		}
+
+		result.Active = true
+		result.TokenType = tokenType
+		result.Subject = claims.Subject
+		result.Issuer = claims.Issuer
+		if claims.IssuedAt != nil {
+			result.IssuedAt = claims.IssuedAt.Time
+		}
+		if claims.ExpiresAt != nil {
+			result.ExpiresAt = claims.ExpiresAt.Time
+		}
+		break
+	}
+	if result == nil {
+		return inactive, nil
+	}
+
+	// The token is only active if its subject is still an active user
+	userID, err := s.subjectUserID(result.Subject)
+	if err != nil {
+		return inactive, nil
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return inactive, nil
+		}
+		return nil, databaseError(err, "failed to verify token subject")
+	}
+	if !utils.PtrBoolValue(user.IsActive) || s.checkTenant(ctx, user.TenantID) != nil {
+		return inactive, nil
+	}
+	// Like ValidateAccessToken, an access token issued before the user's token version was bumped is revoked
+	if access != nil {
+		if err := s.checkTokenVersion(ctx, userID, access); err != nil {
+			if errors.Is(err, domain.ErrSessionRevoked) || errors.Is(err, domain.ErrUserNotFound) {
+				return inactive, nil
+			}
+			return nil, err
+		}
+	}
+
+	// Refresh tokens carry no profile claims, fill them from the user record
+	if result.TokenType == domain.TokenTypeRefresh {
+		roleCodes, err := s.getRoleCodes(ctx, user.ID)
+		if err != nil {
+			return nil, err
+		}
+		result.Username = user.Username
+		result.Role = utils.PtrStringValue(user.RoleCode)
+		result.Roles = roleCodes
+		if s.authConfig.MultiTenant {
+			result.TenantID = user.TenantID
+		}
+	}
+
+	permissions, err := s.resolvePermissions(ctx, user.ID)
+	if err != nil {
+		return nil, databaseError(err, "failed to fetch permissions")
+	}
+	result.Permissions = permissions
+
+	return result, nil
+}
+
+// AddRole assigns an additional role to a user
+// Assigning the user's primary role is a no-op; users of another tenant than x-tenant-id names are reported as missing
+func (s *AuthService) AddRole(ctx context.Context, userID uuid.UUID, roleCode string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return mapUserLookupError(err)
+	}
+	if s.checkTenant(ctx, user.TenantID) != nil {
+		return mapUserLookupError(domain.ErrUserNotFound)
+	}
+
+	role, err := s.findRoleByCode(ctx, roleCode)
+	if err != nil {
+		return err
+	}
+
+	if user.RoleID == role.ID {
+		return nil
+	}
+
+	// The role and the token revocation are committed together: a role granted while
+	// issued access tokens keep validating with the old role claims must not be observable
+	var version int32
+	err = s.unitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		if err := repos.Users.AddRole(ctx, userID, role.ID); err != nil {
+			return databaseError(err, "failed to assign role")
+		}
+		var err error
+		version, err = incrementTokenVersion(ctx, repos.Users, userID)
+		return err
+	})
+	if err != nil {
+		return mapUnitOfWorkError(err, "failed to assign role")
+	}
+	s.permissionCache.Invalidate(ctx, userID)
+	return s.tokenVersionBumped(ctx, userID, version)
+}
+
+// RemoveRole removes a role from a user
+// Removing the primary role promotes one of the remaining roles to primary,
+// and a user must always keep at least one role. Like AddRole, users of another tenant are reported as missing
+func (s *AuthService) RemoveRole(ctx context.Context, userID uuid.UUID, roleCode string) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return mapUserLookupError(err)
+	}
+	if s.checkTenant(ctx, user.TenantID) != nil {
+		return mapUserLookupError(domain.ErrUserNotFound)
+	}
+
+	role, err := s.findRoleByCode(ctx, roleCode)
+	if err != nil {
+		return err
+	}
+
+	roles, err := s.roleRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return databaseError(err, "failed to fetch user roles")
+	}
+
+	var nextPrimary *sqlc.Role
+	assigned := false
+	for i := range roles {
+		if roles[i].ID == role.ID {
+			assigned = true
+		} else if nextPrimary == nil {
+			nextPrimary = &roles[i]
+		}
+	}
+
+	if !assigned {
+		return domain.NewAuthError(
+			domain.ErrRoleNotAssigned,
+			fmt.Sprintf("role %q is not assigned to user", roleCode),
+			domain.CodeRoleNotAssigned,
+		)
+	}
+	if nextPrimary == nil {
+		return domain.NewAuthError(
+			domain.ErrLastRole,
+			"cannot remove the last role of a user",
+			domain.CodeLastRole,
+		)
+	}
+
+	// Promoting the next primary role, removing the role and revoking issued access tokens
+	// (which carry the old role claims) succeed or fail together
+	var version int32
+	err = s.unitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		// Promote another role when removing the primary one
+		if user.RoleID == role.ID {
+			if err := repos.Users.SetPrimaryRole(ctx, userID, nextPrimary.ID); err != nil {
+				return databaseError(err, "failed to update primary role")
+			}
+			if _, err := repos.Users.RemoveRole(ctx, userID, nextPrimary.ID); err != nil {
+				return databaseError(err, "failed to remove role")
+			}
+		}
+
+		if _, err := repos.Users.RemoveRole(ctx, userID, role.ID); err != nil {
+			return databaseError(err, "failed to remove role")
+		}
+		var err error
+		version, err = incrementTokenVersion(ctx, repos.Users, userID)
+		return err
+	})
+	if err != nil {
+		return mapUnitOfWorkError(err, "failed to remove role")
+	}
+	s.permissionCache.Invalidate(ctx, userID)
+	return s.tokenVersionBumped(ctx, userID, version)
+}
+
+// mapUnitOfWorkError returns the domain error of a failed unit of work unchanged,
+// and reports transaction failures (begin or commit) as database errors
+func mapUnitOfWorkError(err error, message string) error {
+	var authErr *domain.AuthError
+	if errors.As(err, &authErr) {
+		return err
+	}
+	return databaseError(err, message)
+}
+
+// databaseError converts a failed repository call into an AuthError
+// Connection failures the repositories classified as domain.ErrDatabaseUnavailable stay retryable,
+// anything else is a database operation failure the client should not retry
+func databaseError(err error, message string) *domain.AuthError {
+	// A query cut short by the request's deadline or cancellation is not a database failure
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return domain.NewAuthError(
+			context.DeadlineExceeded,
+			message+": deadline exceeded",
+			domain.CodeDeadlineExceeded,
+		)
+	case errors.Is(err, context.Canceled):
+		return domain.NewAuthError(
+			context.Canceled,
+			message+": request cancelled",
+			domain.CodeCanceled,
+		)
+	}
+	if errors.Is(err, domain.ErrDatabaseUnavailable) {
+		return domain.NewAuthError(
+			domain.ErrDatabaseUnavailable,
+			message+": database unavailable",
+			domain.CodeDatabaseUnavailable,
+		)
+	}
+	return domain.NewAuthError(
+		domain.ErrDatabaseOperation,
+		message,
+		domain.CodeInternalError,
+	)
+}
+
+// findRoleByCode looks up a role by code and maps repository errors to domain errors
+func (s *AuthService) findRoleByCode(ctx context.Context, code string) (*sqlc.Role, error) {
+	role, err := s.roleRepo.FindByCode(ctx, code)
+	if err != nil {
+		if errors.Is(err, domain.ErrRoleNotFound) {
+			return nil, domain.NewAuthError(
+				domain.ErrRoleNotFound,
+				fmt.Sprintf("role %q not found", code),
+				domain.CodeRoleNotFound,
+			)
+		}
+		return nil, databaseError(err, "failed to fetch role")
+	}
+	return role, nil
+}
+
+// getRoleCodes returns the codes of all roles assigned to a user
+func (s *AuthService) getRoleCodes(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	roles, err := s.roleRepo.FindByUserID(ctx, userID)
+	if err != nil {
+		return nil, databaseError(err, "failed to fetch user roles")
+	}
+
+	codes := make([]string, 0, len(roles))
+	for _, role := range roles {
+		codes = append(codes, role.Code)
+	}
+	return codes, nil
+}
+
+// mapUserLookupError converts a repository user lookup error into an AuthError
+func mapUserLookupError(err error) error {
+	if errors.Is(err, domain.ErrUserNotFound) {
+		return domain.NewAuthError(
+			domain.ErrUserNotFound,
+			"user not found",
+			domain.CodeUserNotFound,
+		)
+	}
+	return databaseError(err, "failed to fetch user")
+}
+
+// generateAccessToken creates a new access token of the session and returns it with its expiry
+// The token is a JWT, or with AUTH_TOKEN_TYPE=opaque a reference to the claims stored server-side
+func (s *AuthService) generateAccessToken(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow, roles []string, sessionID string) (string, time.Time, error) {
 	roleCode := ""
 	if user.RoleCode != nil {
 		roleCode = *user.RoleCode
 	}
 
+	now := s.clock.Now()
+	expirationTime := now.Add(accessExpiration(s.reloader.Current(), append([]string{roleCode}, roles...)))
+
 	claims := &AccessTokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   user.ID.String(),
+			Subject:   s.tokenSubject(user.ID.String()),
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			Issuer:    "worker-auth-service",
+			Issuer:    s.accessTokenIssuer(user.TenantID),
 		},
-		Username: user.Username,
-		Role:     roleCode,
+		Username:  user.Username,
+		Role:      roleCode,
+		Roles:     roles,
+		Version:   user.TokenVersion,
+		Locale:    utils.PtrStringValue(user.Locale),
+		SessionID: sessionID,
+	}
+	if s.authConfig.MultiTenant {
+		claims.TenantID = user.TenantID
+	}
+	claims.Permissions = s.embeddedPermissions(ctx, user.ID)
+	custom, err := s.claimsEnricher.Enrich(ctx, user, roles)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	claims.Custom = s.customClaims(ctx, custom)
+
+	if s.authConfig.TokenType == config.TokenTypeOpaque {
+		token, err := s.issueOpaqueToken(ctx, user.ID, claims)
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		return token, expirationTime, nil
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.config.AccessSecret))
+	signed, err := token.SignedString(s.accessKey)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return signed, expirationTime, nil
+}
+
+// accessExpiration returns the access token lifetime for a user holding roles
+// The shortest per-role override wins, so a privileged role always shortens the token;
+// without any override the global AccessExpiration applies
+func accessExpiration(dynamic *config.DynamicConfig, roles []string) time.Duration {
+	expiration := time.Duration(0)
+	for _, role := range roles {
+		if d, ok := dynamic.AccessExpirationByRole[strings.ToUpper(role)]; ok && (expiration == 0 || d < expiration) {
+			expiration = d
+		}
+	}
+	if expiration == 0 {
+		return dynamic.AccessExpiration
+	}
+	return expiration
 }
 
 // generateRefreshToken creates a new JWT refresh token
-func (s *AuthService) generateRefreshToken(userID string) (string, error) {
-	now := time.Now()
-	expirationTime := now.Add(s.config.RefreshExpiration)
+func (s *AuthService) generateRefreshToken(userID, sessionID, nonce string) (string, error) {
+	now := s.clock.Now()
+	expirationTime := now.Add(s.reloader.Current().RefreshExpiration)
 
 	claims := &RefreshTokenClaims{
 		RegisteredClaims: jwt.RegisteredClaims{
-			Subject:   userID,
+			Subject:   s.tokenSubject(userID),
 			IssuedAt:  jwt.NewNumericDate(now),
 			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			Issuer:    "worker-auth-service",
+			Issuer:    tokenIssuer,
 		},
+		SessionID: sessionID,
+		Nonce:     nonce,
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString([]byte(s.config.RefreshSecret))
+	return token.SignedString(s.refreshKey)
+}
+
+// parseAccessToken parses and validates an access token
+// The issuer must match the token's tenant, and the tenant the tenant named by the request
+func (s *AuthService) parseAccessToken(ctx context.Context, tokenString string) (*AccessTokenClaims, error) {
+	var claims *AccessTokenClaims
+	var err error
+	if s.authConfig.TokenType == config.TokenTypeOpaque {
+		claims, err = s.lookupOpaqueToken(ctx, tokenString)
+	} else {
+		claims, err = s.parseJWTAccessToken(tokenString)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidToken) && s.isRefreshToken(tokenString) {
+			return nil, domain.NewAuthError(
+				domain.ErrWrongTokenType,
+				"a refresh token was sent where an access token is expected",
+				domain.CodeWrongTokenType,
+			)
+		}
+		return nil, err
+	}
+
+	if claims.Issuer != s.accessTokenIssuer(claims.TenantID) {
+		return nil, domain.NewAuthError(
+			domain.ErrInvalidToken,
+			"invalid token issuer",
+			domain.CodeInvalidToken,
+		)
+	}
+	if err := s.checkTenant(ctx, claims.TenantID); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// isTokenRejection reports whether err rejects the token itself rather than reporting a failure to check it
+func isTokenRejection(err error) bool {
+	return errors.Is(err, domain.ErrInvalidToken) ||
+		errors.Is(err, domain.ErrTokenExpired) ||
+		errors.Is(err, domain.ErrWrongTokenType) ||
+		errors.Is(err, domain.ErrTenantMismatch)
+}
+
+// parseJWTAccessToken verifies the signature and expiry of a JWT access token
+func (s *AuthService) parseJWTAccessToken(tokenString string) (*AccessTokenClaims, error) {
+	token, err := s.parser.ParseWithClaims(tokenString, &AccessTokenClaims{}, s.accessKeyFunc)
+
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, domain.NewAuthError(
+				domain.ErrTokenExpired,
+				"access token has expired",
+				domain.CodeTokenExpired,
+			)
+		}
+		return nil, domain.NewAuthError(
+			domain.ErrInvalidToken,
+			"invalid access token",
+			domain.CodeInvalidToken,
+		)
+	}
+
+	claims, ok := token.Claims.(*AccessTokenClaims)
+	if !ok || !token.Valid {
+		return nil, domain.NewAuthError(
+			domain.ErrInvalidToken,
+			"invalid token claims",
+			domain.CodeInvalidToken,
+		)
+	}
+	return claims, nil
+}
+
+// isRefreshToken reports whether tokenString is a refresh token signed by this worker, expired or not
+// Callers that mix up their tokens then get a clear error instead of a bare "invalid access token"
+func (s *AuthService) isRefreshToken(tokenString string) bool {
+	_, err := s.parseRefreshToken(tokenString)
+	return err == nil || errors.Is(err, domain.ErrTokenExpired)
 }
 
 // parseRefreshToken parses and validates a refresh token
 func (s *AuthService) parseRefreshToken(tokenString string) (*RefreshTokenClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &RefreshTokenClaims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, domain.ErrTokenMalformed
-		}
-		return []byte(s.config.RefreshSecret), nil
-	})
+	token, err := s.parser.ParseWithClaims(tokenString, &RefreshTokenClaims{}, s.refreshKeyFunc)
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
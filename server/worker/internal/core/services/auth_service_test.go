@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+func TestRegisterNormalizesInput(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+
+	resp, err := s.Register(ctx, &domain.RegisterRequest{
+		Username: "  alice\t",
+		Email:    " alice@example.com ",
+		Password: testPassword,
+		FullName: "  Nguye\u0302\u0303n \t Van\n A ",
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	user, err := s.userRepo.FindByID(ctx, resp.User.ID)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if user.Username != "alice" || user.Email != "alice@example.com" || user.FullName != "Nguy\u1ec5n Van A" {
+		t.Fatalf("stored %q, %q, %q; want the normalized forms", user.Username, user.Email, user.FullName)
+	}
+
+	// Logins normalize the identifier the same way
+	if _, err := s.Login(ctx, &domain.LoginRequest{Identifier: " alice@example.com\n", Password: testPassword}); err != nil {
+		t.Fatalf("login with surrounding whitespace: %v", err)
+	}
+	_, err = s.Register(ctx, &domain.RegisterRequest{
+		Username: "alice",
+		Email:    "other@example.com",
+		Password: testPassword,
+		FullName: "Other Alice",
+	})
+	assertCode(t, err, domain.CodeUserAlreadyExists)
+}
+
+// BenchmarkParseJWTAccessToken compares the parser and key function built once in NewAuthService
+// with building them on every call, as validation did before they were stored on AuthService
+func BenchmarkParseJWTAccessToken(b *testing.B) {
+	s := newTestService(b, nil)
+	token := s.register(b, "alice").AccessToken
+
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := s.parseJWTAccessToken(token); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("per call", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			parser := jwt.NewParser(jwt.WithTimeFunc(s.clock.Now))
+			if _, err := parser.ParseWithClaims(token, &AccessTokenClaims{}, hmacKeyFunc([]byte(s.config.AccessSecret))); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkValidateAccessTokenParallel validates one token from every benchmark goroutine,
+// sharing the parser and key function; run it with -race to check they are safe for concurrent use
+func BenchmarkValidateAccessTokenParallel(b *testing.B) {
+	s := newTestService(b, nil)
+	ctx := context.Background()
+	token := s.register(b, "alice").AccessToken
+
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			if _, err := s.ValidateAccessToken(ctx, token); err != nil {
+				b.Error(err)
+				return
+			}
+		}
+	})
+}
+
+// unreachableRoles is a role repository whose default role lookups fail like a dropped connection
+type unreachableRoles struct {
+	ports.RoleRepository
+}
+
+var errConnectionReset = errors.New("read tcp: connection reset by peer")
+
+func (unreachableRoles) GetDefaultRole(ctx context.Context) (*sqlc.Role, error) {
+	return nil, errConnectionReset
+}
+
+func (unreachableRoles) FindByCode(ctx context.Context, code string) (*sqlc.Role, error) {
+	return nil, errConnectionReset
+}
+
+func TestRegisterWithoutDefaultRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		roleCode string // AUTH_DEFAULT_ROLE_CODE
+		roles    func(ports.RoleRepository) ports.RoleRepository
+		code     string
+	}{
+		{
+			name:  "roles never seeded",
+			roles: func(r ports.RoleRepository) ports.RoleRepository { return unseededRoles{RoleRepository: r} },
+			code:  domain.CodeDefaultRoleNotFound,
+		},
+		{
+			name:     "configured role missing",
+			roleCode: "DEAN",
+			code:     domain.CodeDefaultRoleNotFound,
+		},
+		{
+			name:  "database error on the default role query",
+			roles: func(r ports.RoleRepository) ports.RoleRepository { return unreachableRoles{RoleRepository: r} },
+			code:  domain.CodeInternalError,
+		},
+		{
+			name:     "database error on the configured role",
+			roleCode: "STUDENT",
+			roles:    func(r ports.RoleRepository) ports.RoleRepository { return unreachableRoles{RoleRepository: r} },
+			code:     domain.CodeInternalError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, nil)
+			s.authConfig.DefaultRoleCode = tt.roleCode
+			if tt.roles != nil {
+				s.roleRepo = tt.roles(s.roleRepo)
+			}
+
+			_, err := s.Register(context.Background(), &domain.RegisterRequest{
+				Username: "alice",
+				Email:    "alice@example.com",
+				Password: testPassword,
+				FullName: "Alice",
+			})
+			assertCode(t, err, tt.code)
+			if _, err := s.userRepo.FindByEmailOrUsername(context.Background(), domain.DefaultTenantID, "alice"); !errors.Is(err, domain.ErrUserNotFound) {
+				t.Fatalf("find alice after the refused registration: got %v, want ErrUserNotFound", err)
+			}
+		})
+	}
+}
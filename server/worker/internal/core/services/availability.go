@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"worker/internal/common/textnorm"
+	"worker/internal/core/domain"
+)
+
+// maxAvailabilityBatchSize caps how many emails, and how many usernames, one CheckAvailabilityBatch call may carry
+const maxAvailabilityBatchSize = 100
+
+// CheckAvailabilityBatch reports for each email and username whether it is still free in the caller's tenant
+// Inputs are normalized the way Register normalizes them, and each list is checked with one query
+// however long it is. Every identifier counts towards the caller's AUTH_AVAILABILITY_CHECK_LIMIT
+func (s *AuthService) CheckAvailabilityBatch(ctx context.Context, accessToken string, emails, usernames []string) (*domain.AvailabilityBatch, error) {
+	if len(emails) > maxAvailabilityBatchSize {
+		return nil, availabilityBatchTooLarge("emails")
+	}
+	if len(usernames) > maxAvailabilityBatchSize {
+		return nil, availabilityBatchTooLarge("usernames")
+	}
+
+	callerID, err := s.authorize(ctx, accessToken, domain.PermissionUsersRead)
+	if err != nil {
+		return nil, err
+	}
+	if !s.rateLimiter.Allow(ctx, "availability:"+callerID.String(), len(emails)+len(usernames)) {
+		return nil, domain.NewAuthError(
+			domain.ErrRateLimited,
+			"too many availability checks, try again later",
+			domain.CodeRateLimited,
+		)
+	}
+
+	tenantID, err := s.resolveTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	emails = normalizeValues(emails, textnorm.Identifier)
+	takenEmails, err := s.userRepo.ExistingEmails(ctx, tenantID, emails)
+	if err != nil {
+		return nil, databaseError(err, "failed to check email availability")
+	}
+	usernames = normalizeValues(usernames, s.normalizeUsername)
+	takenUsernames, err := s.takenUsernames(ctx, tenantID, usernames)
+	if err != nil {
+		return nil, databaseError(err, "failed to check username availability")
+	}
+
+	return &domain.AvailabilityBatch{
+		Emails:    availability(emails, takenEmails),
+		Usernames: availability(usernames, takenUsernames),
+	}, nil
+}
+
+func availabilityBatchTooLarge(field string) error {
+	return domain.NewFieldError(
+		domain.ErrBatchTooLarge,
+		field,
+		fmt.Sprintf("at most %d %s can be checked at once", maxAvailabilityBatchSize, field),
+	)
+}
+
+// normalizeValues returns a copy of values passed through normalize
+func normalizeValues(values []string, normalize func(string) string) []string {
+	normalized := make([]string, len(values))
+	for i, value := range values {
+		normalized[i] = normalize(value)
+	}
+	return normalized
+}
+
+// availability pairs every value with whether it is missing from taken
+// An empty value can never be registered, so it is reported unavailable
+func availability(values, taken []string) []domain.Availability {
+	used := make(map[string]bool, len(taken))
+	for _, value := range taken {
+		used[value] = true
+	}
+	results := make([]domain.Availability, len(values))
+	for i, value := range values {
+		results[i] = domain.Availability{Value: value, Available: value != "" && !used[value]}
+	}
+	return results
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+func TestCheckAvailabilityBatchMatchesSingleLookups(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.AvailabilityCheckLimit = 0
+	})
+	ctx := context.Background()
+	s.registerAdmin(t)
+	s.register(t, "alice")
+	accessToken := s.mustLogin(t, "admin").AccessToken
+
+	emails := []string{"alice@example.com", " ALICE@example.com", "carol@example.com", "admin@example.com", ""}
+	usernames := []string{"alice", "carol", "admin", "Alice", ""}
+
+	batch, err := s.CheckAvailabilityBatch(ctx, accessToken, emails, usernames)
+	if err != nil {
+		t.Fatalf("check batch: %v", err)
+	}
+
+	// Checking every value on its own gives the same answers as the batch
+	var single domain.AvailabilityBatch
+	for _, email := range emails {
+		result, err := s.CheckAvailabilityBatch(ctx, accessToken, []string{email}, nil)
+		if err != nil {
+			t.Fatalf("check %q: %v", email, err)
+		}
+		single.Emails = append(single.Emails, result.Emails...)
+	}
+	for _, username := range usernames {
+		result, err := s.CheckAvailabilityBatch(ctx, accessToken, nil, []string{username})
+		if err != nil {
+			t.Fatalf("check %q: %v", username, err)
+		}
+		single.Usernames = append(single.Usernames, result.Usernames...)
+	}
+	if !slices.Equal(batch.Emails, single.Emails) {
+		t.Fatalf("batched emails = %v, single lookups = %v", batch.Emails, single.Emails)
+	}
+	if !slices.Equal(batch.Usernames, single.Usernames) {
+		t.Fatalf("batched usernames = %v, single lookups = %v", batch.Usernames, single.Usernames)
+	}
+
+	// And the answers agree with the repository
+	tenantID, err := s.resolveTenant(ctx)
+	if err != nil {
+		t.Fatalf("resolve tenant: %v", err)
+	}
+	for _, result := range batch.Emails[:4] {
+		exists, err := s.userRepo.ExistsByEmail(ctx, tenantID, result.Value)
+		if err != nil || exists == result.Available {
+			t.Fatalf("%s available = %t, but exists = %t, %v", result.Value, result.Available, exists, err)
+		}
+	}
+}
+
+func TestCheckAvailabilityBatchSpendsOneUnitPerValue(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.AvailabilityCheckLimit = 4
+	})
+	ctx := context.Background()
+	s.registerAdmin(t)
+	accessToken := s.mustLogin(t, "admin").AccessToken
+
+	if _, err := s.CheckAvailabilityBatch(ctx, accessToken, []string{"a@example.com", "b@example.com"}, []string{"c"}); err != nil {
+		t.Fatalf("check a batch of 3: %v", err)
+	}
+	if _, err := s.CheckAvailabilityBatch(ctx, accessToken, nil, []string{"d"}); err != nil {
+		t.Fatalf("check the 4th value: %v", err)
+	}
+	_, err := s.CheckAvailabilityBatch(ctx, accessToken, nil, []string{"e"})
+	assertCode(t, err, domain.CodeRateLimited)
+
+	// The window ends and the caller may check again
+	s.clock.Advance(s.authConfig.AvailabilityCheckWindow)
+	if _, err := s.CheckAvailabilityBatch(ctx, accessToken, nil, []string{"e"}); err != nil {
+		t.Fatalf("check after the window: %v", err)
+	}
+}
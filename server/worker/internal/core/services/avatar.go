@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"worker/internal/core/domain"
+)
+
+// avatarExtensions maps allowed avatar content types to object key extensions
+var avatarExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/webp": ".webp",
+	"image/gif":  ".gif",
+}
+
+// GetAvatarUploadURL returns a presigned URL the client uploads the avatar to directly
+// The URL is bound to the declared content length, so an oversized file is refused by the storage itself.
+// The upload must be confirmed with ConfirmAvatar before the avatar is used
+func (s *AuthService) GetAvatarUploadURL(ctx context.Context, userID uuid.UUID, contentType string, contentLength int64) (*domain.AvatarUpload, error) {
+	if !s.isAllowedAvatarType(contentType) {
+		return nil, domain.NewAuthError(
+			domain.ErrInvalidContentType,
+			fmt.Sprintf("content type '%s' is not allowed for avatars", contentType),
+			domain.CodeInvalidArgument,
+		)
+	}
+	if contentLength <= 0 {
+		return nil, domain.NewFieldError(
+			domain.ErrInvalidFileSize,
+			"content_length",
+			"content_length must be the size of the file in bytes",
+		)
+	}
+	if contentLength > s.avatarConfig.MaxSize {
+		return nil, domain.NewFieldError(
+			domain.ErrFileTooLarge,
+			"content_length",
+			fmt.Sprintf("avatar exceeds the maximum size of %d bytes", s.avatarConfig.MaxSize),
+		)
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return nil, mapUserLookupError(err)
+	}
+
+	objectID, err := uuid.NewV7()
+	if err != nil {
+		return nil, domain.NewAuthError(err, "failed to generate object key", domain.CodeInternalError)
+	}
+	objectKey := avatarKeyPrefix(userID) + objectID.String() + avatarExtensions[contentType]
+
+	expiration := s.avatarConfig.UploadExpiration
+	uploadURL, err := s.objectStorage.PresignPut(ctx, objectKey, contentType, contentLength, expiration)
+	if err != nil {
+		return nil, domain.NewAuthError(
+			domain.ErrStorageOperation,
+			"failed to create upload URL",
+			domain.CodeInternalError,
+		)
+	}
+
+	return &domain.AvatarUpload{
+		UploadURL: uploadURL,
+		ObjectKey: objectKey,
+		PublicURL: s.objectStorage.PublicURL(objectKey),
+		ExpiresAt: s.clock.Now().Add(expiration),
+		MaxSize:   s.avatarConfig.MaxSize,
+	}, nil
+}
+
+// ConfirmAvatar verifies an uploaded avatar object and stores its public URL on the user
+// Objects that violate the type or size constraints are deleted, and so are the user's other objects
+// once the avatar is set: the avatar it replaces and uploads that were never confirmed
+func (s *AuthService) ConfirmAvatar(ctx context.Context, userID uuid.UUID, objectKey string) (string, error) {
+	if !strings.HasPrefix(objectKey, avatarKeyPrefix(userID)) || strings.Contains(objectKey, "..") {
+		return "", domain.NewAuthError(
+			domain.ErrInvalidObjectKey,
+			"object key does not belong to this user",
+			domain.CodeInvalidArgument,
+		)
+	}
+
+	if _, err := s.userRepo.FindByID(ctx, userID); err != nil {
+		return "", mapUserLookupError(err)
+	}
+
+	info, err := s.objectStorage.Stat(ctx, objectKey)
+	if err != nil {
+		if errors.Is(err, domain.ErrObjectNotFound) {
+			return "", domain.NewAuthError(
+				domain.ErrObjectNotFound,
+				"avatar has not been uploaded",
+				domain.CodeObjectNotFound,
+			)
+		}
+		return "", domain.NewAuthError(
+			domain.ErrStorageOperation,
+			"failed to verify uploaded avatar",
+			domain.CodeInternalError,
+		)
+	}
+
+	if !s.isAllowedAvatarType(info.ContentType) {
+		_ = s.objectStorage.Delete(ctx, objectKey)
+		return "", domain.NewAuthError(
+			domain.ErrInvalidContentType,
+			fmt.Sprintf("content type '%s' is not allowed for avatars", info.ContentType),
+			domain.CodeInvalidArgument,
+		)
+	}
+
+	if info.Size > s.avatarConfig.MaxSize {
+		_ = s.objectStorage.Delete(ctx, objectKey)
+		return "", domain.NewAuthError(
+			domain.ErrFileTooLarge,
+			fmt.Sprintf("avatar exceeds the maximum size of %d bytes", s.avatarConfig.MaxSize),
+			domain.CodeInvalidArgument,
+		)
+	}
+
+	avatarURL := s.objectStorage.PublicURL(objectKey)
+	if err := s.userRepo.UpdateAvatar(ctx, userID, avatarURL); err != nil {
+		return "", databaseError(err, "failed to update avatar")
+	}
+	s.deleteStaleAvatars(ctx, userID, objectKey)
+
+	return avatarURL, nil
+}
+
+// deleteStaleAvatars deletes the user's avatar objects other than current
+// The avatar is already set, so failures are only logged; the next confirmed upload retries them
+func (s *AuthService) deleteStaleAvatars(ctx context.Context, userID uuid.UUID, current string) {
+	keys, err := s.objectStorage.List(ctx, avatarKeyPrefix(userID))
+	if err != nil {
+		s.logger.Warn("Failed to list stale avatars", zap.String("user_id", userID.String()), zap.Error(err))
+		return
+	}
+	for _, key := range keys {
+		if key == current {
+			continue
+		}
+		if err := s.objectStorage.Delete(ctx, key); err != nil {
+			s.logger.Warn("Failed to delete stale avatar", zap.String("object_key", key), zap.Error(err))
+		}
+	}
+}
+
+func (s *AuthService) isAllowedAvatarType(contentType string) bool {
+	return slices.Contains(s.avatarConfig.AllowedContentTypes, contentType)
+}
+
+// avatarKeyPrefix scopes avatar objects per user so one user cannot confirm another's upload
+func avatarKeyPrefix(userID uuid.UUID) string {
+	return "avatars/" + userID.String() + "/"
+}
@@ -0,0 +1,169 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/memory"
+	"worker/internal/core/domain"
+)
+
+// uploadAvatar requests a PNG upload slot and stores an object of contentType and size at its key
+// The memory storage enforces nothing, so the stored object may differ from what was requested
+func (s *testService) uploadAvatar(tb testing.TB, userID uuid.UUID, contentType string, size int64) string {
+	tb.Helper()
+	upload, err := s.GetAvatarUploadURL(context.Background(), userID, "image/png", s.avatarConfig.MaxSize)
+	if err != nil {
+		tb.Fatalf("get upload URL: %v", err)
+	}
+	s.objectStorage.(*memory.ObjectStorage).Put(upload.ObjectKey, contentType, size)
+	return upload.ObjectKey
+}
+
+// assertStored fails unless the object under key is stored, or gone when stored is false
+func (s *testService) assertStored(tb testing.TB, key string, stored bool) {
+	tb.Helper()
+	_, err := s.objectStorage.Stat(context.Background(), key)
+	if stored && err != nil {
+		tb.Fatalf("stat %s: %v, want it stored", key, err)
+	}
+	if !stored && !errors.Is(err, domain.ErrObjectNotFound) {
+		tb.Fatalf("stat %s: %v, want it deleted", key, err)
+	}
+}
+
+func TestGetAvatarUploadURL(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	userID := s.register(t, "alice").User.ID
+
+	upload, err := s.GetAvatarUploadURL(ctx, userID, "image/png", 1024)
+	if err != nil {
+		t.Fatalf("get upload URL: %v", err)
+	}
+	if !strings.HasPrefix(upload.ObjectKey, avatarKeyPrefix(userID)) {
+		t.Fatalf("object key %s is outside the user's prefix", upload.ObjectKey)
+	}
+	if want := s.clock.Now().Add(s.avatarConfig.UploadExpiration); !upload.ExpiresAt.Equal(want) {
+		t.Fatalf("expires at %v, want %v", upload.ExpiresAt, want)
+	}
+}
+
+func TestGetAvatarUploadURLRejects(t *testing.T) {
+	tests := []struct {
+		name          string
+		contentType   string
+		contentLength func(maxSize int64) int64
+		want          error
+	}{
+		{name: "content type", contentType: "text/html", contentLength: func(int64) int64 { return 1024 }, want: domain.ErrInvalidContentType},
+		{name: "empty file", contentType: "image/png", contentLength: func(int64) int64 { return 0 }, want: domain.ErrInvalidFileSize},
+		{name: "negative length", contentType: "image/png", contentLength: func(int64) int64 { return -1 }, want: domain.ErrInvalidFileSize},
+		{name: "oversized file", contentType: "image/png", contentLength: func(maxSize int64) int64 { return maxSize + 1 }, want: domain.ErrFileTooLarge},
+	}
+
+	s := newTestService(t, nil)
+	userID := s.register(t, "alice").User.ID
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := s.GetAvatarUploadURL(context.Background(), userID, tt.contentType, tt.contentLength(s.avatarConfig.MaxSize))
+			assertCode(t, err, domain.CodeInvalidArgument)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfirmAvatar(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	userID := s.register(t, "alice").User.ID
+
+	key := s.uploadAvatar(t, userID, "image/png", 1024)
+	avatarURL, err := s.ConfirmAvatar(ctx, userID, key)
+	if err != nil {
+		t.Fatalf("confirm: %v", err)
+	}
+	if avatarURL != s.objectStorage.PublicURL(key) {
+		t.Fatalf("avatar URL = %s, want %s", avatarURL, s.objectStorage.PublicURL(key))
+	}
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if user.Avatar == nil || *user.Avatar != avatarURL {
+		t.Fatalf("stored avatar = %v, want %s", user.Avatar, avatarURL)
+	}
+}
+
+func TestConfirmAvatarDeletesReplacedAndAbandonedUploads(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	userID := s.register(t, "alice").User.ID
+	bobID := s.register(t, "bob").User.ID
+
+	previous := s.uploadAvatar(t, userID, "image/png", 1024)
+	if _, err := s.ConfirmAvatar(ctx, userID, previous); err != nil {
+		t.Fatalf("confirm previous avatar: %v", err)
+	}
+	abandoned := s.uploadAvatar(t, userID, "image/png", 1024)
+	current := s.uploadAvatar(t, userID, "image/jpeg", 2048)
+	bobs := s.uploadAvatar(t, bobID, "image/png", 1024)
+
+	if _, err := s.ConfirmAvatar(ctx, userID, current); err != nil {
+		t.Fatalf("confirm avatar: %v", err)
+	}
+	s.assertStored(t, current, true)
+	s.assertStored(t, previous, false)
+	s.assertStored(t, abandoned, false)
+	s.assertStored(t, bobs, true)
+}
+
+func TestConfirmAvatarRejectsForeignKeys(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	aliceID := s.register(t, "alice").User.ID
+	bobID := s.register(t, "bob").User.ID
+
+	bobs := s.uploadAvatar(t, bobID, "image/png", 1024)
+	for _, key := range []string{
+		bobs,
+		"avatars/" + aliceID.String() + "/../" + bobID.String() + "/x.png",
+		"elsewhere/x.png",
+	} {
+		_, err := s.ConfirmAvatar(ctx, aliceID, key)
+		if !errors.Is(err, domain.ErrInvalidObjectKey) {
+			t.Fatalf("confirm %s as alice: %v, want %v", key, err, domain.ErrInvalidObjectKey)
+		}
+	}
+	s.assertStored(t, bobs, true)
+}
+
+func TestConfirmAvatarRejectsInvalidUploads(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	userID := s.register(t, "alice").User.ID
+
+	// The storage records what was actually uploaded, which is checked again on confirmation
+	wrongType := s.uploadAvatar(t, userID, "text/html", 1024)
+	_, err := s.ConfirmAvatar(ctx, userID, wrongType)
+	if !errors.Is(err, domain.ErrInvalidContentType) {
+		t.Fatalf("confirm wrong content type: %v, want %v", err, domain.ErrInvalidContentType)
+	}
+	s.assertStored(t, wrongType, false)
+
+	oversized := s.uploadAvatar(t, userID, "image/png", s.avatarConfig.MaxSize+1)
+	_, err = s.ConfirmAvatar(ctx, userID, oversized)
+	if !errors.Is(err, domain.ErrFileTooLarge) {
+		t.Fatalf("confirm oversized upload: %v, want %v", err, domain.ErrFileTooLarge)
+	}
+	s.assertStored(t, oversized, false)
+
+	_, err = s.ConfirmAvatar(ctx, userID, avatarKeyPrefix(userID)+"missing.png")
+	assertCode(t, err, domain.CodeObjectNotFound)
+}
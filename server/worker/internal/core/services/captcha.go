@@ -0,0 +1,29 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"worker/internal/core/domain"
+)
+
+// verifyCaptcha checks the CAPTCHA token sent with Register or Login
+// The verifier is a no-op unless CAPTCHA_PROVIDER is configured
+func (s *AuthService) verifyCaptcha(ctx context.Context, token string) error {
+	err := s.captcha.Verify(ctx, token, domain.ClientInfoFromContext(ctx).IP)
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, domain.ErrCaptchaInvalid) {
+		return domain.NewAuthError(
+			domain.ErrCaptchaInvalid,
+			"captcha verification failed, solve the captcha again",
+			domain.CodeCaptchaInvalid,
+		).WithField("captcha_token")
+	}
+	return domain.NewAuthError(
+		domain.ErrCaptchaInvalid,
+		"failed to verify captcha",
+		domain.CodeInternalError,
+	)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"worker/internal/core/domain"
+)
+
+// stubCaptcha accepts only the token "solved" and records the client IPs it was given
+type stubCaptcha struct {
+	err error // Returned for every token when set, like an unreachable provider
+	ips []string
+}
+
+func (c *stubCaptcha) Verify(ctx context.Context, token, remoteIP string) error {
+	c.ips = append(c.ips, remoteIP)
+	if c.err != nil {
+		return c.err
+	}
+	if token != "solved" {
+		return domain.ErrCaptchaInvalid
+	}
+	return nil
+}
+
+func TestRegisterCaptcha(t *testing.T) {
+	s := newTestService(t, nil)
+	captcha := &stubCaptcha{}
+	s.captcha = captcha
+	ctx := domain.WithClientInfo(context.Background(), domain.ClientInfo{IP: "10.0.0.1"})
+	req := func(token string) *domain.RegisterRequest {
+		return &domain.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: testPassword, FullName: "Alice", CaptchaToken: token}
+	}
+
+	for _, token := range []string{"", "unsolved"} {
+		_, err := s.Register(ctx, req(token))
+		assertCode(t, err, domain.CodeCaptchaInvalid)
+		var authErr *domain.AuthError
+		if !errors.As(err, &authErr) || authErr.Field != "captcha_token" {
+			t.Fatalf("register with token %q = %v, want an error on captcha_token", token, err)
+		}
+	}
+	if _, err := s.userRepo.FindByEmailOrUsername(ctx, "", "alice"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("rejected registration created a user: %v", err)
+	}
+
+	if _, err := s.Register(ctx, req("solved")); err != nil {
+		t.Fatalf("register with a solved captcha: %v", err)
+	}
+	if len(captcha.ips) != 3 || captcha.ips[2] != "10.0.0.1" {
+		t.Fatalf("client IPs given to the verifier = %v, want 10.0.0.1 each time", captcha.ips)
+	}
+}
+
+func TestLoginCaptcha(t *testing.T) {
+	s := newTestService(t, nil)
+	s.register(t, "alice")
+	s.captcha = &stubCaptcha{}
+	login := func(token, password string) error {
+		_, err := s.Login(context.Background(), &domain.LoginRequest{Identifier: "alice", Password: password, CaptchaToken: token})
+		return err
+	}
+
+	// The captcha is checked first: a bot learns nothing about the account or the password
+	assertCode(t, login("unsolved", testPassword), domain.CodeCaptchaInvalid)
+	assertCode(t, login("unsolved", "Wrong-Horse-9-Battery"), domain.CodeCaptchaInvalid)
+	_, err := s.Login(context.Background(), &domain.LoginRequest{Identifier: "nobody", Password: testPassword})
+	assertCode(t, err, domain.CodeCaptchaInvalid)
+
+	if err := login("solved", testPassword); err != nil {
+		t.Fatalf("login with a solved captcha: %v", err)
+	}
+}
+
+func TestCaptchaProviderUnavailable(t *testing.T) {
+	s := newTestService(t, nil)
+	s.register(t, "alice")
+	s.captcha = &stubCaptcha{err: errors.New("captcha siteverify: connection refused")}
+
+	_, err := s.Login(context.Background(), &domain.LoginRequest{Identifier: "alice", Password: testPassword, CaptchaToken: "solved"})
+	assertCode(t, err, domain.CodeInternalError)
+	_, err = s.Register(context.Background(), &domain.RegisterRequest{
+		Username: "bob", Email: "bob@example.com", Password: testPassword, FullName: "Bob", CaptchaToken: "solved",
+	})
+	assertCode(t, err, domain.CodeInternalError)
+}
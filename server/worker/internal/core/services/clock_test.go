@@ -0,0 +1,69 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"worker/internal/core/domain"
+)
+
+func TestAccessTokenExpiresWithTheClock(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	issuedAt := time.Date(2031, time.June, 1, 12, 0, 0, 0, time.UTC) // Far from the wall clock, which must not matter
+	s.clock.Set(issuedAt)
+	alice := s.register(t, "alice")
+
+	expiration := s.cfg.JWT.AccessExpiration
+	if !alice.AccessTokenExpiresAt.Equal(issuedAt.Add(expiration)) {
+		t.Fatalf("access token expires at %v, want %v", alice.AccessTokenExpiresAt, issuedAt.Add(expiration))
+	}
+	claims, err := s.parseAccessToken(ctx, alice.AccessToken)
+	if err != nil {
+		t.Fatalf("parse access token: %v", err)
+	}
+	if !claims.IssuedAt.Time.Equal(issuedAt) {
+		t.Fatalf("issued at %v, want %v", claims.IssuedAt.Time, issuedAt)
+	}
+
+	s.clock.Advance(expiration - time.Second)
+	if _, err := s.ValidateAccessToken(ctx, alice.AccessToken); err != nil {
+		t.Fatalf("validate a second before expiry: %v", err)
+	}
+	s.clock.Advance(2 * time.Second)
+	_, err = s.ValidateAccessToken(ctx, alice.AccessToken)
+	assertCode(t, err, domain.CodeTokenExpired)
+}
+
+func TestRefreshTokenExpiresWithTheClock(t *testing.T) {
+	s := newTestService(t, nil)
+	alice := s.register(t, "alice")
+
+	s.clock.Advance(s.cfg.JWT.RefreshExpiration + time.Second)
+	_, err := s.RefreshAccessToken(context.Background(), alice.RefreshToken)
+	assertCode(t, err, domain.CodeTokenExpired)
+}
+
+func TestRefreshRotationExtendsTheSession(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	alice := s.register(t, "alice")
+	expiration := s.cfg.JWT.RefreshExpiration
+
+	// Each rotation restarts the refresh window, so a session in use outlives the first token
+	refreshToken := alice.RefreshToken
+	for range 3 {
+		s.clock.Advance(expiration - time.Hour)
+		refreshed, err := s.RefreshAccessToken(ctx, refreshToken)
+		if err != nil {
+			t.Fatalf("refresh at %v: %v", s.clock.Now(), err)
+		}
+		refreshToken = refreshed.RefreshToken
+	}
+
+	// An unused session still ends one window after its last rotation
+	s.clock.Advance(expiration + time.Second)
+	_, err := s.RefreshAccessToken(ctx, refreshToken)
+	assertCode(t, err, domain.CodeTokenExpired)
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+func TestDatabaseErrorContextErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		wantCode  string
+		wantCause error
+	}{
+		{name: "deadline exceeded", err: context.DeadlineExceeded, wantCode: domain.CodeDeadlineExceeded, wantCause: context.DeadlineExceeded},
+		{name: "wrapped deadline exceeded", err: fmt.Errorf("query users: %w", context.DeadlineExceeded), wantCode: domain.CodeDeadlineExceeded, wantCause: context.DeadlineExceeded},
+		{name: "canceled", err: context.Canceled, wantCode: domain.CodeCanceled, wantCause: context.Canceled},
+		{name: "wrapped canceled", err: fmt.Errorf("query users: %w", context.Canceled), wantCode: domain.CodeCanceled, wantCause: context.Canceled},
+		{name: "unavailable", err: fmt.Errorf("dial: %w", domain.ErrDatabaseUnavailable), wantCode: domain.CodeDatabaseUnavailable, wantCause: domain.ErrDatabaseUnavailable},
+		{name: "other failure", err: errors.New("syntax error"), wantCode: domain.CodeInternalError, wantCause: domain.ErrDatabaseOperation},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := databaseError(tt.err, "failed to find user")
+			if err.Code != tt.wantCode || !errors.Is(err, tt.wantCause) {
+				t.Fatalf("databaseError(%v) = %s %v, want %s caused by %v", tt.err, err.Code, err, tt.wantCode, tt.wantCause)
+			}
+		})
+	}
+}
+
+// cutShortPermissions is a role repository whose permission lookups are cut short by the context with err
+type cutShortPermissions struct {
+	ports.RoleRepository
+	err error
+}
+
+func (r cutShortPermissions) GetPermissionsByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return nil, fmt.Errorf("get permissions: %w", r.err)
+}
+
+func TestValidateAccessTokenCutShort(t *testing.T) {
+	tests := []struct {
+		err      error
+		wantCode string
+	}{
+		{err: context.DeadlineExceeded, wantCode: domain.CodeDeadlineExceeded},
+		{err: context.Canceled, wantCode: domain.CodeCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.wantCode, func(t *testing.T) {
+			s := newTestService(t, func(cfg *config.Config) {
+				cfg.Auth.PermissionsFailMode = config.PermissionsFailClosed
+			})
+			alice := s.register(t, "alice")
+			s.permissionCache.InvalidateAll(context.Background())
+			s.roleRepo = cutShortPermissions{RoleRepository: s.roleRepo, err: tt.err}
+
+			// Reported as the context error rather than as a database failure
+			_, err := s.ValidateAccessToken(context.Background(), alice.AccessToken)
+			assertCode(t, err, tt.wantCode)
+			if !errors.Is(err, tt.err) || errors.Is(err, domain.ErrDatabaseOperation) {
+				t.Fatalf("validate = %v, want it caused by %v", err, tt.err)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/logger"
+)
+
+// standardClaims are the access token claims set by the worker; custom claims never override them
+var standardClaims = map[string]bool{
+	"iss": true, "sub": true, "aud": true, "exp": true, "nbf": true, "iat": true, "jti": true,
+	"username": true, "role": true, "roles": true, "tenant_id": true, "ver": true, "locale": true, "perms": true, "sid": true,
+}
+
+// plainAccessTokenClaims encodes the standard claims of AccessTokenClaims only
+type plainAccessTokenClaims AccessTokenClaims
+
+// MarshalJSON encodes the standard claims, with the custom claims merged in at the top level
+func (c *AccessTokenClaims) MarshalJSON() ([]byte, error) {
+	encoded, err := json.Marshal((*plainAccessTokenClaims)(c))
+	if err != nil || len(c.Custom) == 0 {
+		return encoded, err
+	}
+
+	merged := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(encoded, &merged); err != nil {
+		return nil, err
+	}
+	for name, value := range c.Custom {
+		if standardClaims[name] {
+			continue
+		}
+		if merged[name], err = json.Marshal(value); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(merged)
+}
+
+// UnmarshalJSON decodes the standard claims, and every other claim into Custom
+func (c *AccessTokenClaims) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*plainAccessTokenClaims)(c)); err != nil {
+		return err
+	}
+
+	var all map[string]any
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	c.Custom = nil
+	for name, value := range all {
+		if standardClaims[name] {
+			continue
+		}
+		if c.Custom == nil {
+			c.Custom = make(map[string]any)
+		}
+		c.Custom[name] = value
+	}
+	return nil
+}
+
+// customClaims returns the claims the ClaimsEnricher adds to a token, without those overriding a standard claim
+func (s *AuthService) customClaims(ctx context.Context, enriched map[string]any) map[string]any {
+	var custom map[string]any
+	var dropped []string
+	for name, value := range enriched {
+		if standardClaims[name] {
+			dropped = append(dropped, name)
+			continue
+		}
+		if custom == nil {
+			custom = make(map[string]any, len(enriched))
+		}
+		custom[name] = value
+	}
+	if len(dropped) > 0 {
+		slices.Sort(dropped)
+		logger.FromContext(ctx, s.logger).Warn("Dropped custom claims overriding standard claims",
+			zap.Strings("claims", dropped))
+	}
+	return custom
+}
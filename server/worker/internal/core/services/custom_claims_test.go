@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// orgEnricher adds an org_id and feature flags claim, and tries to override standard claims
+// It surfaces only the claims listed in surfaced, or all of them when surfaced is nil
+type orgEnricher struct {
+	surfaced []string
+	err      error
+	users    []string // Usernames enriched, in order
+}
+
+func (e *orgEnricher) Enrich(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow, roles []string) (map[string]any, error) {
+	e.users = append(e.users, user.Username)
+	if e.err != nil {
+		return nil, e.err
+	}
+	return map[string]any{
+		"org_id": "org-" + user.Username,
+		"flags":  []any{"beta", "dark-mode"},
+		"sub":    "someone-else",
+		"perms":  []any{"system:ADMIN"},
+	}, nil
+}
+
+func (e *orgEnricher) Surface(ctx context.Context, claims map[string]any) map[string]any {
+	if e.surfaced == nil {
+		return claims
+	}
+	surfaced := make(map[string]any)
+	for _, name := range e.surfaced {
+		if value, ok := claims[name]; ok {
+			surfaced[name] = value
+		}
+	}
+	return surfaced
+}
+
+func TestCustomClaimsRoundTrip(t *testing.T) {
+	for _, tokenType := range []string{config.TokenTypeJWT, config.TokenTypeOpaque} {
+		t.Run(tokenType, func(t *testing.T) {
+			s := newTokenTypeTestService(t, tokenType)
+			core, logs := observer.New(zapcore.WarnLevel)
+			s.logger = zap.New(core)
+			enricher := &orgEnricher{}
+			s.claimsEnricher = enricher
+			ctx := context.Background()
+			alice := s.register(t, "alice")
+
+			if !slices.Equal(enricher.users, []string{"alice"}) {
+				t.Fatalf("enriched %v, want alice's token", enricher.users)
+			}
+			result, err := s.ValidateAccessToken(ctx, alice.AccessToken)
+			if err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			want := map[string]any{"org_id": "org-alice", "flags": []any{"beta", "dark-mode"}}
+			if len(result.Claims) != len(want) || result.Claims["org_id"] != want["org_id"] ||
+				!slices.Equal(result.Claims["flags"].([]any), want["flags"].([]any)) {
+				t.Fatalf("custom claims = %v, want %v", result.Claims, want)
+			}
+
+			// The standard claims keep their values, and the attempt to override them is logged
+			if result.UserID != alice.User.ID.String() || len(result.Permissions) != 0 {
+				t.Fatalf("result = %+v, want alice without permissions", result)
+			}
+			entries := logs.FilterMessage("Dropped custom claims overriding standard claims").All()
+			if len(entries) != 1 || !slices.Equal(entries[0].ContextMap()["claims"].([]any), []any{"perms", "sub"}) {
+				t.Fatalf("logged %v, want perms and sub dropped", logs.All())
+			}
+
+			// Batch validation surfaces the same claims
+			batch, err := s.ValidateAccessTokens(ctx, []string{alice.AccessToken})
+			if err != nil || batch[0].Err != nil {
+				t.Fatalf("validate batch = %v, %v", batch, err)
+			}
+			if claims := batch[0].Result.Claims; len(claims) != len(want) || claims["org_id"] != want["org_id"] {
+				t.Fatalf("batch claims = %v, want %v", batch[0].Result.Claims, result.Claims)
+			}
+		})
+	}
+}
+
+func TestCustomClaimsSurface(t *testing.T) {
+	s := newTestService(t, nil)
+	s.claimsEnricher = &orgEnricher{surfaced: []string{"org_id"}}
+	alice := s.register(t, "alice")
+
+	result, err := s.ValidateAccessToken(context.Background(), alice.AccessToken)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if len(result.Claims) != 1 || result.Claims["org_id"] != "org-alice" {
+		t.Fatalf("custom claims = %v, want only org_id surfaced", result.Claims)
+	}
+	// The token still carries the claims that were not surfaced
+	if flags := s.tokenClaims(t, alice.AccessToken).Custom["flags"]; flags == nil {
+		t.Fatal("flags claim missing from the token")
+	}
+}
+
+func TestCustomClaimsEnrichFailure(t *testing.T) {
+	s := newTestService(t, nil)
+	s.register(t, "alice")
+	s.claimsEnricher = &orgEnricher{err: errors.New("organization directory unavailable")}
+
+	// No token is issued without the claims the integrator requires
+	_, err := s.login("alice")
+	assertCode(t, err, domain.CodeInternalError)
+	if !errors.Is(err, domain.ErrGeneratingToken) {
+		t.Fatalf("login = %v, want a token generation error", err)
+	}
+}
+
+func TestCustomClaimsNoop(t *testing.T) {
+	s := newTestService(t, nil)
+	alice := s.register(t, "alice")
+
+	result, err := s.ValidateAccessToken(context.Background(), alice.AccessToken)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if result.Claims != nil {
+		t.Fatalf("custom claims = %v, want none from the default enricher", result.Claims)
+	}
+}
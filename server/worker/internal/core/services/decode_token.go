@@ -0,0 +1,71 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"worker/internal/core/domain"
+)
+
+// DecodeToken reads the claims of a JWT without verifying its signature, expiry or revocation
+// It is a support tool gated behind system:READ; anyone can forge an unverified token, so nothing
+// it returns may be used to make an authorization decision
+func (s *AuthService) DecodeToken(ctx context.Context, accessToken, token string) (*domain.DecodedToken, error) {
+	if _, err := s.authorize(ctx, accessToken, domain.PermissionSystemRead); err != nil {
+		return nil, err
+	}
+
+	claims := jwt.MapClaims{}
+	parsed, _, err := jwt.NewParser().ParseUnverified(token, claims)
+	if err != nil {
+		return nil, domain.NewFieldError(domain.ErrTokenMalformed, "token", "token is not a well-formed JWT")
+	}
+
+	// Claims of an unexpected type are left empty rather than failing the whole decode
+	decoded := &domain.DecodedToken{
+		Subject:   stringClaim(claims, "sub"),
+		Username:  stringClaim(claims, "username"),
+		Role:      stringClaim(claims, "role"),
+		Roles:     stringListClaim(claims, "roles"),
+		Issuer:    stringClaim(claims, "iss"),
+		ID:        stringClaim(claims, "jti"),
+		TenantID:  stringClaim(claims, "tenant_id"),
+		SessionID: stringClaim(claims, "sid"),
+		IssuedAt:  timeClaim(claims.GetIssuedAt),
+		ExpiresAt: timeClaim(claims.GetExpirationTime),
+		NotBefore: timeClaim(claims.GetNotBefore),
+	}
+	if alg, ok := parsed.Header["alg"].(string); ok {
+		decoded.Algorithm = alg
+	}
+	if audience, err := claims.GetAudience(); err == nil {
+		decoded.Audience = audience
+	}
+	return decoded, nil
+}
+
+func stringClaim(claims jwt.MapClaims, name string) string {
+	value, _ := claims[name].(string)
+	return value
+}
+
+func stringListClaim(claims jwt.MapClaims, name string) []string {
+	values, _ := claims[name].([]any)
+	list := make([]string, 0, len(values))
+	for _, value := range values {
+		if s, ok := value.(string); ok {
+			list = append(list, s)
+		}
+	}
+	return list
+}
+
+func timeClaim(get func() (*jwt.NumericDate, error)) time.Time {
+	date, err := get()
+	if err != nil || date == nil {
+		return time.Time{}
+	}
+	return date.Time
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"worker/internal/core/domain"
+)
+
+func TestDecodeExpiredToken(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.registerAdmin(t)
+	issuedAt := s.clock.Now()
+	alice := s.register(t, "alice")
+	s.clock.Advance(s.cfg.JWT.AccessExpiration + time.Minute)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	if _, err := s.ValidateAccessToken(ctx, alice.AccessToken); err == nil {
+		t.Fatal("expired token validates")
+	}
+	decoded, err := s.DecodeToken(ctx, adminToken, alice.AccessToken)
+	if err != nil {
+		t.Fatalf("decode an expired token: %v", err)
+	}
+	if decoded.Algorithm != "HS256" || decoded.Subject != alice.User.ID.String() || decoded.Username != "alice" ||
+		decoded.Role != s.authConfig.DefaultRoleCode || decoded.Issuer != tokenIssuer {
+		t.Fatalf("decoded = %+v, want alice's claims", decoded)
+	}
+	if !decoded.IssuedAt.Equal(issuedAt) || !decoded.ExpiresAt.Equal(issuedAt.Add(s.cfg.JWT.AccessExpiration)) {
+		t.Fatalf("issued at %v, expires at %v; want issued at %v for %s",
+			decoded.IssuedAt, decoded.ExpiresAt, issuedAt, s.cfg.JWT.AccessExpiration)
+	}
+	if !decoded.NotBefore.IsZero() || decoded.ID != "" || decoded.Audience != nil {
+		t.Fatalf("decoded = %+v, want no nbf, jti or aud for claims the token lacks", decoded)
+	}
+}
+
+func TestDecodeTokenWithInvalidSignature(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	// Signed with a key the worker does not know
+	now := s.clock.Now()
+	forged, err := jwt.NewWithClaims(jwt.SigningMethodHS512, jwt.MapClaims{
+		"sub":   "someone",
+		"roles": []string{"ADMIN", "LECTURER"},
+		"iss":   "elsewhere",
+		"aud":   []string{"gateway", "worker"},
+		"jti":   "token-1",
+		"iat":   now.Unix(),
+		"nbf":   now.Add(time.Hour).Unix(),
+		"exp":   now.Add(2 * time.Hour).Unix(),
+		"sid":   "session-1",
+	}).SignedString([]byte("some-other-secret"))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if _, err := s.ValidateAccessToken(ctx, forged); err == nil {
+		t.Fatal("token with a foreign signature validates")
+	}
+
+	decoded, err := s.DecodeToken(ctx, adminToken, forged)
+	if err != nil {
+		t.Fatalf("decode a token with an invalid signature: %v", err)
+	}
+	if decoded.Algorithm != "HS512" || decoded.Subject != "someone" || decoded.Issuer != "elsewhere" ||
+		decoded.ID != "token-1" || decoded.SessionID != "session-1" {
+		t.Fatalf("decoded = %+v, want the forged claims", decoded)
+	}
+	if !slices.Equal(decoded.Roles, []string{"ADMIN", "LECTURER"}) || !slices.Equal(decoded.Audience, []string{"gateway", "worker"}) {
+		t.Fatalf("roles %v, audience %v; want the forged ones", decoded.Roles, decoded.Audience)
+	}
+	if !decoded.NotBefore.Equal(now.Add(time.Hour)) || !decoded.ExpiresAt.Equal(now.Add(2*time.Hour)) {
+		t.Fatalf("not before %v, expires at %v; want the forged times", decoded.NotBefore, decoded.ExpiresAt)
+	}
+
+	// The signature is dropped altogether, so it decodes the same
+	unsigned := forged[:strings.LastIndexByte(forged, '.')+1]
+	if again, err := s.DecodeToken(ctx, adminToken, unsigned); err != nil || again.Subject != "someone" {
+		t.Fatalf("decode without a signature = %+v, %v", again, err)
+	}
+}
+
+func TestDecodeMalformedToken(t *testing.T) {
+	s := newTestService(t, nil)
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	for _, token := range []string{"", "not-a-jwt", "a.b.c", "eyJhbGciOiJIUzI1NiJ9.bm90IGpzb24.sig"} {
+		_, err := s.DecodeToken(context.Background(), adminToken, token)
+		assertCode(t, err, domain.CodeInvalidArgument)
+		var authErr *domain.AuthError
+		if !errors.As(err, &authErr) || authErr.Field != "token" {
+			t.Fatalf("decode %q = %v, want the token field rejected", token, err)
+		}
+	}
+}
+
+func TestDecodeTokenRequiresSystemRead(t *testing.T) {
+	s := newTestService(t, nil)
+	alice := s.register(t, "alice")
+
+	_, err := s.DecodeToken(context.Background(), alice.AccessToken, alice.AccessToken)
+	assertCode(t, err, domain.CodePermissionDenied)
+	_, err = s.DecodeToken(context.Background(), "", alice.AccessToken)
+	if err == nil {
+		t.Fatal("decode without an access token succeeded")
+	}
+}
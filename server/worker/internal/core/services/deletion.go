@@ -0,0 +1,156 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/logger"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// deletionSweepBatch bounds how many accounts one sweep erases, the rest wait for the next tick
+const deletionSweepBatch = 100
+
+// deletionSweepTimeout bounds a single sweep
+const deletionSweepTimeout = time.Minute
+
+// ScheduleDeletion schedules the erasure of the user's account once AUTH_DELETION_GRACE_PERIOD is over
+// Until then CancelDeletion restores the account. With AUTH_SCHEDULED_DELETION_LOGIN=block the user is
+// also signed out everywhere, since Login refuses them from now on
+func (s *AuthService) ScheduleDeletion(ctx context.Context, userID uuid.UUID) (time.Time, error) {
+	deletionAt, err := s.userRepo.ScheduleDeletion(ctx, userID, s.clock.Now().Add(s.authConfig.DeletionGracePeriod))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return time.Time{}, mapUserLookupError(err)
+		}
+		return time.Time{}, databaseError(err, "failed to schedule account deletion")
+	}
+
+	if s.authConfig.ScheduledDeletionLogin == config.ScheduledDeletionLoginBlock {
+		if err := s.revokeAccessTokens(ctx, userID); err != nil {
+			return time.Time{}, err
+		}
+		if err := s.sessionRepo.RevokeAllForUser(ctx, userID); err != nil {
+			return time.Time{}, databaseError(err, "failed to revoke sessions")
+		}
+		s.revocations.Publish(domain.RevocationEvent{
+			UserID:    userID.String(),
+			Reason:    domain.RevocationReasonDeletion,
+			RevokedAt: s.clock.Now(),
+		})
+	}
+	return deletionAt, nil
+}
+
+// CancelDeletion restores an account scheduled for deletion
+// The user proves who they are with their credentials, as Login may refuse them while the deletion is scheduled
+func (s *AuthService) CancelDeletion(ctx context.Context, req *domain.LoginRequest) error {
+	user, _, err := s.verifyCredentials(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	cancelled, err := s.userRepo.CancelDeletion(ctx, user.ID)
+	if err != nil {
+		return databaseError(err, "failed to cancel account deletion")
+	}
+	if !cancelled {
+		return domain.NewAuthError(
+			domain.ErrDeletionNotScheduled,
+			"account is not scheduled for deletion",
+			domain.CodeDeletionNotScheduled,
+		)
+	}
+	return nil
+}
+
+// SweepScheduledDeletions erases up to deletionSweepBatch accounts whose grace period is over
+// and returns how many were erased; accounts restored meanwhile are skipped
+// An account that fails to erase is logged and left for the next sweep, so it does not hold up the others;
+// the failures are returned together once the batch is done
+func (s *AuthService) SweepScheduledDeletions(ctx context.Context) (int, error) {
+	now := s.clock.Now()
+	due, err := s.userRepo.ListDueForDeletion(ctx, now, deletionSweepBatch)
+	if err != nil {
+		return 0, err
+	}
+
+	log := logger.FromContext(ctx, s.logger)
+	erased := 0
+	var errs []error
+	for _, user := range due {
+		err := s.eraseUser(ctx, user.ID, func(users ports.UserRepository) error {
+			return users.DeleteIfDue(ctx, user.ID, now)
+		})
+		if errors.Is(err, domain.ErrUserNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Warn("Failed to erase an account scheduled for deletion",
+				zap.String("user_id", user.ID.String()), zap.Error(err))
+			errs = append(errs, fmt.Errorf("user %s: %w", user.ID, err))
+			continue
+		}
+		s.auditLog.Record(ctx, domain.AuditEvent{
+			Type:     domain.AuditUserPurged,
+			UserID:   user.ID.String(),
+			TenantID: user.TenantID,
+			At:       now,
+		})
+		erased++
+	}
+	return erased, errors.Join(errs...)
+}
+
+// startDeletionSweeper runs SweepScheduledDeletions every AUTH_DELETION_SWEEP_INTERVAL
+func startDeletionSweeper(lc fx.Lifecycle, cfg *config.AuthConfig, authService ports.AuthService, logger *zap.Logger) {
+	logger = logger.Named("deletion_sweeper")
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	sweep := func() {
+		sweepCtx, cancelSweep := context.WithTimeout(ctx, deletionSweepTimeout)
+		defer cancelSweep()
+		erased, err := authService.SweepScheduledDeletions(sweepCtx)
+		if err != nil {
+			logger.Error("Failed to erase accounts scheduled for deletion", zap.Int("erased", erased), zap.Error(err))
+			return
+		}
+		if erased > 0 {
+			logger.Info("Erased accounts scheduled for deletion", zap.Int("erased", erased))
+		}
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(cfg.DeletionSweepInterval)
+				defer ticker.Stop()
+
+				for {
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+						sweep()
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			<-done
+			return nil
+		},
+	})
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// failingUnitOfWork fails the erasure of one user, like a row the database refuses to delete
+type failingUnitOfWork struct {
+	ports.UnitOfWork
+	userID uuid.UUID
+}
+
+func (u failingUnitOfWork) Do(ctx context.Context, fn func(repos ports.Repositories) error) error {
+	return u.UnitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		repos.Users = failingUserRepository{UserRepository: repos.Users, userID: u.userID}
+		return fn(repos)
+	})
+}
+
+type failingUserRepository struct {
+	ports.UserRepository
+	userID uuid.UUID
+}
+
+var errEraseFailed = errors.New("erase failed")
+
+func (r failingUserRepository) DeleteIfDue(ctx context.Context, userID uuid.UUID, dueBy time.Time) error {
+	if userID == r.userID {
+		return errEraseFailed
+	}
+	return r.UserRepository.DeleteIfDue(ctx, userID, dueBy)
+}
+
+func newDeletionTestService(t *testing.T) *testService {
+	return newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.DeletionGracePeriod = 24 * time.Hour
+	})
+}
+
+func TestSweepScheduledDeletions(t *testing.T) {
+	s := newDeletionTestService(t)
+	ctx := context.Background()
+
+	alice := s.register(t, "alice").User.ID
+	bob := s.register(t, "bob").User.ID
+	carol := s.register(t, "carol").User.ID
+	for _, id := range []uuid.UUID{alice, bob} {
+		if _, err := s.ScheduleDeletion(ctx, id); err != nil {
+			t.Fatalf("schedule deletion: %v", err)
+		}
+	}
+	if err := s.CancelDeletion(ctx, &domain.LoginRequest{Identifier: "bob", Password: testPassword}); err != nil {
+		t.Fatalf("cancel deletion: %v", err)
+	}
+
+	s.clock.Advance(23 * time.Hour)
+	if erased, err := s.SweepScheduledDeletions(ctx); err != nil || erased != 0 {
+		t.Fatalf("sweep within the grace period = %d, %v; want 0, nil", erased, err)
+	}
+
+	s.clock.Advance(2 * time.Hour)
+	if erased, err := s.SweepScheduledDeletions(ctx); err != nil || erased != 1 {
+		t.Fatalf("sweep after the grace period = %d, %v; want 1, nil", erased, err)
+	}
+	if _, err := s.userRepo.FindByID(ctx, alice); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("find erased user: got %v, want ErrUserNotFound", err)
+	}
+	for name, id := range map[string]uuid.UUID{"cancelled": bob, "unscheduled": carol} {
+		if _, err := s.userRepo.FindByID(ctx, id); err != nil {
+			t.Fatalf("find %s user: %v", name, err)
+		}
+	}
+
+	if erased, err := s.SweepScheduledDeletions(ctx); err != nil || erased != 0 {
+		t.Fatalf("second sweep = %d, %v; want 0, nil", erased, err)
+	}
+}
+
+func TestSweepScheduledDeletionsSkipsFailures(t *testing.T) {
+	s := newDeletionTestService(t)
+	ctx := context.Background()
+
+	var ids []uuid.UUID
+	for _, name := range []string{"alice", "bob", "carol"} {
+		id := s.register(t, name).User.ID
+		if _, err := s.ScheduleDeletion(ctx, id); err != nil {
+			t.Fatalf("schedule deletion: %v", err)
+		}
+		ids = append(ids, id)
+		s.clock.Advance(time.Minute)
+	}
+	// The account due first fails, the others must still be erased
+	s.unitOfWork = failingUnitOfWork{UnitOfWork: s.unitOfWork, userID: ids[0]}
+
+	s.clock.Advance(25 * time.Hour)
+	erased, err := s.SweepScheduledDeletions(ctx)
+	if !errors.Is(err, errEraseFailed) {
+		t.Fatalf("sweep error = %v, want it to report the failed erasure", err)
+	}
+	if erased != 2 {
+		t.Fatalf("erased = %d, want 2", erased)
+	}
+	if _, err := s.userRepo.FindByID(ctx, ids[0]); err != nil {
+		t.Fatalf("find the user that failed to erase: %v", err)
+	}
+}
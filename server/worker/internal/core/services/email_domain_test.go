@@ -0,0 +1,56 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+func TestRegisterEmailDomains(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.AllowedEmailDomains = []string{"university.edu", "*.university.edu"}
+		cfg.Auth.BlockedEmailDomains = []string{"guest.university.edu"}
+	})
+
+	tests := []struct {
+		username string
+		email    string
+		allowed  bool
+	}{
+		{username: "alice", email: "alice@university.edu", allowed: true},
+		{username: "bob", email: "bob@cs.university.edu", allowed: true},
+		// Matched after lowercasing the domain
+		{username: "carol", email: "carol@Math.University.EDU", allowed: true},
+		{username: "dave", email: "dave@gmail.com"},
+		{username: "erin", email: "erin@guest.university.edu"},
+		{username: "frank", email: "frank@university.edu.evil.com"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.email, func(t *testing.T) {
+			_, err := s.Register(context.Background(), &domain.RegisterRequest{
+				Username: tt.username,
+				Email:    tt.email,
+				Password: testPassword,
+				FullName: "Test " + tt.username,
+			})
+			if tt.allowed {
+				if err != nil {
+					t.Fatalf("register: %v", err)
+				}
+				return
+			}
+			assertCode(t, err, domain.CodeInvalidArgument)
+			var authErr *domain.AuthError
+			if !errors.As(err, &authErr) || authErr.Field != "email" || !errors.Is(err, domain.ErrEmailDomainNotAllowed) {
+				t.Fatalf("register = %v, want the email domain refused", err)
+			}
+			// The refused account was not created
+			if _, err := s.login(tt.username); err == nil {
+				t.Fatalf("%s logged in after a refused registration", tt.username)
+			}
+		})
+	}
+}
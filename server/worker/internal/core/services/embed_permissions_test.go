@@ -0,0 +1,162 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// newEmbedService returns a service embedding up to max permissions in access tokens, failing closed
+// so that a validation reaching the unreachable permission lookups shows as an error
+func newEmbedService(t *testing.T, embed bool, max int) *testService {
+	return newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.EmbedPermissions = embed
+		cfg.Auth.EmbedPermissionsMax = max
+		cfg.Auth.PermissionsFailMode = config.PermissionsFailClosed
+	})
+}
+
+// tokenClaims returns the claims of an access token
+func (s *testService) tokenClaims(tb testing.TB, accessToken string) *AccessTokenClaims {
+	tb.Helper()
+	claims, err := s.parseAccessToken(context.Background(), accessToken)
+	if err != nil {
+		tb.Fatalf("parse access token: %v", err)
+	}
+	return claims
+}
+
+// registerEmbedAdmin registers the admin and returns its ID and the permissions the ADMIN role grants
+func (s *testService) registerEmbedAdmin(t *testing.T) (uuid.UUID, []string) {
+	t.Helper()
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	// Embedding at registration cached the default role's permissions, which promoting in the store bypasses
+	s.permissionCache.Invalidate(ctx, adminID)
+	permissions, err := s.resolvePermissions(ctx, adminID)
+	if err != nil {
+		t.Fatalf("resolve admin permissions: %v", err)
+	}
+	return adminID, permissions
+}
+
+func TestEmbedPermissions(t *testing.T) {
+	s := newEmbedService(t, true, 50)
+	ctx := context.Background()
+	adminID, want := s.registerEmbedAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	if embedded := s.tokenClaims(t, adminToken).Permissions; !slices.Equal(embedded, want) {
+		t.Fatalf("perms claim = %v, want %v", embedded, want)
+	}
+
+	// A restarted worker has no cached permissions and cannot look any up, so they can only come from the token
+	restarted := s.restart(t)
+	restarted.roleRepo = unreachablePermissions{RoleRepository: restarted.roleRepo}
+	result, err := restarted.ValidateAccessToken(ctx, adminToken)
+	if err != nil {
+		t.Fatalf("validate a token with embedded permissions: %v", err)
+	}
+	if !slices.Equal(result.Permissions, want) {
+		t.Fatalf("validated permissions = %v, want the embedded %v", result.Permissions, want)
+	}
+	mine, err := restarted.GetMyPermissions(ctx, adminToken)
+	if err != nil || !slices.Equal(mine, want) {
+		t.Fatalf("my permissions = %v, %v; want the embedded %v", mine, err, want)
+	}
+	batch, err := restarted.ValidateAccessTokens(ctx, []string{adminToken})
+	if err != nil {
+		t.Fatalf("validate batch: %v", err)
+	}
+	if batch[0].Err != nil || !slices.Equal(batch[0].Result.Permissions, want) {
+		t.Fatalf("batch result = %+v, %v; want the embedded %v", batch[0].Result, batch[0].Err, want)
+	}
+
+	// The token version is still checked, so revoking the user's tokens still takes effect
+	if err := s.RevokeAllUserTokens(ctx, adminID, adminID); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	restarted = s.restart(t)
+	restarted.roleRepo = unreachablePermissions{RoleRepository: restarted.roleRepo}
+	if _, err := restarted.ValidateAccessToken(ctx, adminToken); err == nil {
+		t.Fatal("revoked token with embedded permissions validates")
+	}
+}
+
+func TestEmbedPermissionsDisabled(t *testing.T) {
+	s := newEmbedService(t, false, 50)
+	s.registerEmbedAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	if embedded := s.tokenClaims(t, adminToken).Permissions; embedded != nil {
+		t.Fatalf("perms claim = %v with embedding disabled, want none", embedded)
+	}
+
+	// Without a claim the permissions are looked up, which fails here
+	restarted := s.restart(t)
+	restarted.roleRepo = unreachablePermissions{RoleRepository: restarted.roleRepo}
+	_, err := restarted.ValidateAccessToken(context.Background(), adminToken)
+	assertCode(t, err, domain.CodeInternalError)
+}
+
+func TestEmbedPermissionsOverCap(t *testing.T) {
+	s := newEmbedService(t, true, 2)
+	core, logs := observer.New(zapcore.WarnLevel)
+	s.logger = zap.New(core)
+	_, want := s.registerEmbedAdmin(t)
+	if len(want) <= 2 {
+		t.Fatalf("ADMIN grants %v, want more than the cap", want)
+	}
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	// The token falls back to a reference whose permissions are resolved on validation
+	if embedded := s.tokenClaims(t, adminToken).Permissions; embedded != nil {
+		t.Fatalf("perms claim = %v over the cap, want none", embedded)
+	}
+	if logs.FilterMessage("User holds more permissions than AUTH_EMBED_PERMISSIONS_MAX, issuing the token without them").Len() == 0 {
+		t.Fatalf("logged %v, want the cap warning", logs.All())
+	}
+	result, err := s.restart(t).ValidateAccessToken(context.Background(), adminToken)
+	if err != nil || !slices.Equal(result.Permissions, want) {
+		t.Fatalf("validate = %+v, %v; want the resolved %v", result, err, want)
+	}
+
+	// Users within the cap still get the claim, which omits an empty list
+	alice := s.register(t, "alice")
+	if embedded := s.tokenClaims(t, alice.AccessToken).Permissions; embedded != nil {
+		t.Fatalf("perms claim of a user without permissions = %#v, want none", embedded)
+	}
+}
+
+func TestEmbedPermissionsLookupFailure(t *testing.T) {
+	s := newEmbedService(t, true, 50)
+	core, logs := observer.New(zapcore.WarnLevel)
+	s.logger = zap.New(core)
+	adminID, _ := s.registerEmbedAdmin(t)
+	s.permissionCache.Invalidate(context.Background(), adminID)
+	roles := s.roleRepo
+	s.roleRepo = unreachablePermissions{RoleRepository: roles}
+
+	// Issuing is never refused over the claim; the token is issued without it
+	adminToken := s.mustLogin(t, "admin").AccessToken
+	if embedded := s.tokenClaims(t, adminToken).Permissions; embedded != nil {
+		t.Fatalf("perms claim = %v after a failed lookup, want none", embedded)
+	}
+	if logs.FilterMessage("Failed to resolve permissions to embed, issuing the token without them").Len() != 1 {
+		t.Fatalf("logged %v, want the failed lookup", logs.All())
+	}
+
+	s.roleRepo = roles
+	result, err := s.ValidateAccessToken(context.Background(), adminToken)
+	if err != nil || result.UserID != adminID.String() || len(result.Permissions) == 0 {
+		t.Fatalf("validate = %+v, %v; want the admin's resolved permissions", result, err)
+	}
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"worker/internal/config"
+)
+
+func TestAccessExpirationByRole(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.JWT.AccessExpiration = 30 * time.Minute
+		cfg.JWT.AccessExpirationByRole = map[string]time.Duration{"ADMIN": 5 * time.Minute, "LECTURER": 10 * time.Minute}
+	})
+	ctx := context.Background()
+	now := s.clock.Now()
+	s.registerAdmin(t)
+	student := s.register(t, "student")
+
+	admin := s.mustLogin(t, "admin")
+	if want := now.Add(5 * time.Minute); !admin.AccessTokenExpiresAt.Equal(want) {
+		t.Fatalf("admin token expires at %v, want %v", admin.AccessTokenExpiresAt, want)
+	}
+	// Roles without an override get the global expiration
+	if want := now.Add(30 * time.Minute); !student.AccessTokenExpiresAt.Equal(want) {
+		t.Fatalf("student token expires at %v, want %v", student.AccessTokenExpiresAt, want)
+	}
+	if !admin.AccessTokenExpiresAt.Before(student.AccessTokenExpiresAt) {
+		t.Fatal("admin token does not expire before the student token")
+	}
+
+	// The expiry reported is the one in the token
+	result, err := s.ValidateAccessToken(ctx, admin.AccessToken)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if !result.ExpiresAt.Equal(admin.AccessTokenExpiresAt) {
+		t.Fatalf("token expiry = %v, response said %v", result.ExpiresAt, admin.AccessTokenExpiresAt)
+	}
+	refreshed, err := s.RefreshAccessToken(ctx, admin.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if want := now.Add(5 * time.Minute); !refreshed.AccessTokenExpiresAt.Equal(want) {
+		t.Fatalf("refreshed admin token expires at %v, want %v", refreshed.AccessTokenExpiresAt, want)
+	}
+
+	// With several overridden roles the shortest lifetime wins
+	s.promote(t, student.User.ID, "LECTURER")
+	if err := s.AddRole(ctx, student.User.ID, "ADMIN"); err != nil {
+		t.Fatalf("add role: %v", err)
+	}
+	if got, want := s.mustLogin(t, "student").AccessTokenExpiresAt, now.Add(5*time.Minute); !got.Equal(want) {
+		t.Fatalf("lecturer and admin token expires at %v, want %v", got, want)
+	}
+}
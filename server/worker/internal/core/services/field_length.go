@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+	"unicode/utf8"
+
+	"worker/internal/core/domain"
+)
+
+// maxPasswordBytes is the longest password bcrypt accepts; longer ones fail to hash
+// It applies with argon2id too, so AUTH_PASSWORD_HASHER can be switched back to bcrypt
+const maxPasswordBytes = 72
+
+// maxPrehashedPasswordBytes bounds passwords once AUTH_BCRYPT_PREHASH lifts bcrypt's limit,
+// so a caller cannot make every hash work on megabytes of input
+const maxPrehashedPasswordBytes = 1024
+
+// checkFieldLength rejects a value longer than max characters as an invalid field
+// Characters are counted like Postgres counts VARCHAR(n), so a value that passes fits its column
+func checkFieldLength(field, value string, max int) error {
+	if utf8.RuneCountInString(value) > max {
+		return domain.NewFieldError(
+			domain.ErrFieldTooLong,
+			field,
+			fmt.Sprintf("%s must be at most %d characters", field, max),
+		)
+	}
+	return nil
+}
+
+// checkPasswordLength rejects a password bcrypt cannot hash, which would otherwise fail as an internal error
+// With AUTH_BCRYPT_PREHASH long passwords are pre-hashed for bcrypt, so only maxPrehashedPasswordBytes applies
+func (s *AuthService) checkPasswordLength(field, password string) error {
+	limit := maxPasswordBytes
+	if s.authConfig.BcryptPrehash {
+		limit = maxPrehashedPasswordBytes
+	}
+	if len(password) > limit {
+		return domain.NewFieldError(
+			domain.ErrFieldTooLong,
+			field,
+			fmt.Sprintf("%s must be at most %d bytes", field, limit),
+		)
+	}
+	return nil
+}
+
+// checkRegistrationLengths applies the configured maximums to the normalized fields of a registration
+func (s *AuthService) checkRegistrationLengths(req *domain.RegisterRequest) error {
+	if err := checkFieldLength("email", req.Email, s.authConfig.MaxEmailLength); err != nil {
+		return err
+	}
+	if err := checkFieldLength("username", req.Username, s.authConfig.MaxUsernameLength); err != nil {
+		return err
+	}
+	if err := checkFieldLength("full_name", req.FullName, s.authConfig.MaxFullNameLength); err != nil {
+		return err
+	}
+	return s.checkPasswordLength("password", req.Password)
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// assertFieldTooLong fails unless err rejects field as too long
+func assertFieldTooLong(t *testing.T, err error, field string) {
+	t.Helper()
+	assertCode(t, err, domain.CodeInvalidArgument)
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) || authErr.Field != field || !errors.Is(err, domain.ErrFieldTooLong) {
+		t.Fatalf("error = %v, want %s rejected as too long", err, field)
+	}
+}
+
+// emailOfLength returns a valid email address of n characters
+func emailOfLength(n int) string {
+	const domainPart = "@example.com"
+	return strings.Repeat("a", n-len(domainPart)) + domainPart
+}
+
+func TestRegisterFieldLengths(t *testing.T) {
+	const maxEmail, maxUsername, maxFullName = 30, 12, 20
+	tests := []struct {
+		field   string
+		request func(n int) *domain.RegisterRequest // A registration whose field is n characters long
+		max     int
+	}{
+		{
+			field: "email",
+			request: func(n int) *domain.RegisterRequest {
+				return &domain.RegisterRequest{Username: "alice", Email: emailOfLength(n), Password: testPassword, FullName: "Alice"}
+			},
+			max: maxEmail,
+		},
+		{
+			field: "username",
+			request: func(n int) *domain.RegisterRequest {
+				return &domain.RegisterRequest{Username: strings.Repeat("a", n), Email: "alice@example.com", Password: testPassword, FullName: "Alice"}
+			},
+			max: maxUsername,
+		},
+		{
+			// Counted in characters like VARCHAR, so a Vietnamese name is not cut short by its bytes
+			field: "full_name",
+			request: func(n int) *domain.RegisterRequest {
+				return &domain.RegisterRequest{Username: "alice", Email: "alice@example.com", Password: testPassword, FullName: strings.Repeat("Ư", n)}
+			},
+			max: maxFullName,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.field, func(t *testing.T) {
+			s := newTestService(t, func(cfg *config.Config) {
+				cfg.Auth.MaxEmailLength = maxEmail
+				cfg.Auth.MaxUsernameLength = maxUsername
+				cfg.Auth.MaxFullNameLength = maxFullName
+			})
+
+			_, err := s.Register(context.Background(), tt.request(tt.max+1))
+			assertFieldTooLong(t, err, tt.field)
+			if _, err := s.Register(context.Background(), tt.request(tt.max)); err != nil {
+				t.Fatalf("register with a %s of %d characters: %v", tt.field, tt.max, err)
+			}
+		})
+	}
+}
+
+func TestPasswordLength(t *testing.T) {
+	// The limit is bcrypt's 72 bytes, or the prehash's 1024 when passwords are prehashed
+	for _, prehash := range []bool{false, true} {
+		limit := maxPasswordBytes
+		if prehash {
+			limit = maxPrehashedPasswordBytes
+		}
+		s := newTestService(t, func(cfg *config.Config) {
+			cfg.Auth.BcryptPrehash = prehash
+		})
+		// Multi-byte characters count by their bytes, as bcrypt sees them
+		atLimit := "Aa1-" + strings.Repeat("é", (limit-4)/2)
+		if len(atLimit) != limit {
+			t.Fatalf("test password is %d bytes, want %d", len(atLimit), limit)
+		}
+
+		err := registerWithPassword(s, "alice", atLimit+"x")
+		assertFieldTooLong(t, err, "password")
+		if err := registerWithPassword(s, "alice", atLimit); err != nil {
+			t.Fatalf("register with a %d-byte password (prehash=%v): %v", limit, prehash, err)
+		}
+
+		resp, err := s.Login(context.Background(), &domain.LoginRequest{Identifier: "alice", Password: atLimit})
+		if err != nil {
+			t.Fatalf("login: %v", err)
+		}
+		err = s.ChangePassword(context.Background(), "", resp.AccessToken, atLimit, atLimit+"x")
+		assertFieldTooLong(t, err, "new_password")
+	}
+}
+
+func TestChangeUsernameLength(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.MaxUsernameLength = 12
+	})
+	alice := s.register(t, "alice")
+
+	_, err := s.ChangeUsername(context.Background(), alice.User.ID, strings.Repeat("b", 13))
+	assertFieldTooLong(t, err, "new_username")
+	if _, err := s.ChangeUsername(context.Background(), alice.User.ID, strings.Repeat("b", 12)); err != nil {
+		t.Fatalf("change to a 12-character username: %v", err)
+	}
+}
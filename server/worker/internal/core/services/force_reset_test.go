@@ -0,0 +1,127 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/domain"
+)
+
+// newPassword is the password set with a reset token in these tests
+const newPassword = "Another-Horse-7-Battery"
+
+func TestForcePasswordReset(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+	audit := &recordingAuditLog{}
+	s.auditLog = audit
+	revocations := s.WatchRevocations(ctx)
+
+	reset, err := s.ForcePasswordReset(ctx, adminID, alice.User.ID)
+	if err != nil {
+		t.Fatalf("force password reset: %v", err)
+	}
+	if reset.Token == "" || !reset.ExpiresAt.Equal(s.clock.Now().Add(s.authConfig.PasswordResetTokenExpiration)) {
+		t.Fatalf("reset token = %+v", reset)
+	}
+
+	// Every token of the account is revoked, and watchers are told
+	if _, err := s.ValidateAccessToken(ctx, alice.AccessToken); err == nil {
+		t.Fatal("access token validates after a forced reset")
+	}
+	_, err = s.RefreshAccessToken(ctx, alice.RefreshToken)
+	assertCode(t, err, domain.CodeSessionRevoked)
+	select {
+	case event := <-revocations:
+		if event.UserID != alice.User.ID.String() || event.Reason != domain.RevocationReasonPasswordReset {
+			t.Fatalf("revocation event = %+v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no revocation event was published")
+	}
+	if len(audit.events) != 1 || audit.events[0].Type != domain.AuditPasswordResetForced ||
+		audit.events[0].UserID != alice.User.ID.String() || audit.events[0].ActorID != adminID.String() {
+		t.Fatalf("audit events = %+v, want the forced reset by the admin", audit.events)
+	}
+
+	// The old password no longer logs in, nor replaces itself
+	_, err = s.login("alice")
+	assertCode(t, err, domain.CodePasswordResetRequired)
+
+	// The reset token sets a new password without the current one, once
+	if err := s.ChangePassword(ctx, "", reset.Token, "", newPassword); err != nil {
+		t.Fatalf("change password with the reset token: %v", err)
+	}
+	err = s.ChangePassword(ctx, "", reset.Token, "", "Third-Horse-5-Battery")
+	assertCode(t, err, domain.CodeInvalidToken)
+
+	if _, err := s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: newPassword}); err != nil {
+		t.Fatalf("login with the new password: %v", err)
+	}
+}
+
+func TestForcePasswordResetBlocksChangePassword(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+
+	// A change token obtained before the reset, here from an expired password, cannot bypass it
+	s.authConfig.PasswordMaxAge = time.Hour
+	s.clock.Advance(2 * time.Hour)
+	expired, err := s.login("alice")
+	assertCode(t, err, domain.CodePasswordExpired)
+	if _, err := s.ForcePasswordReset(ctx, adminID, alice.User.ID); err != nil {
+		t.Fatalf("force password reset: %v", err)
+	}
+
+	err = s.ChangePassword(ctx, "", expired.PasswordChangeToken, testPassword, newPassword)
+	assertCode(t, err, domain.CodePasswordResetRequired)
+}
+
+func TestForcePasswordResetTokenExpires(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+
+	reset, err := s.ForcePasswordReset(ctx, adminID, alice.User.ID)
+	if err != nil {
+		t.Fatalf("force password reset: %v", err)
+	}
+	s.clock.Advance(s.authConfig.PasswordResetTokenExpiration + time.Second)
+
+	err = s.ChangePassword(ctx, "", reset.Token, "", newPassword)
+	assertCode(t, err, domain.CodeTokenExpired)
+
+	// A new reset replaces the expired token
+	reset, err = s.ForcePasswordReset(ctx, adminID, alice.User.ID)
+	if err != nil {
+		t.Fatalf("force password reset again: %v", err)
+	}
+	if err := s.ChangePassword(ctx, "", reset.Token, "", newPassword); err != nil {
+		t.Fatalf("change password with the new reset token: %v", err)
+	}
+}
+
+func TestForcePasswordResetRequiresPermission(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+	bob := s.register(t, "bob")
+
+	_, err := s.ForcePasswordReset(ctx, bob.User.ID, alice.User.ID)
+	assertCode(t, err, domain.CodePermissionDenied)
+	_, err = s.ForcePasswordReset(ctx, adminID, uuid.New())
+	assertCode(t, err, domain.CodeUserNotFound)
+
+	// The refused request changed nothing
+	s.mustLogin(t, "alice")
+}
@@ -0,0 +1,114 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/storage/memory"
+	"worker/internal/common/buildinfo"
+	"worker/internal/common/clock"
+	"worker/internal/core/domain"
+)
+
+// failingDatabase is a ports.DatabaseHealth whose database refuses connections,
+// with a driver error naming the host and user like pgx does
+type failingDatabase struct{}
+
+func (failingDatabase) Ping(ctx context.Context) error {
+	return errors.New(`failed to connect to host=db.internal user=worker database=auth: connection refused`)
+}
+
+func (failingDatabase) CheckSchema(ctx context.Context) error {
+	return errors.New(`relation "sessions" does not exist`)
+}
+
+// hungDatabase is a ports.DatabaseHealth whose probes only return once their context is done
+type hungDatabase struct{}
+
+func (hungDatabase) Ping(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+func (hungDatabase) CheckSchema(ctx context.Context) error {
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// dependency returns the named dependency of report
+func dependency(t *testing.T, report *domain.HealthReport, name string) domain.DependencyHealth {
+	t.Helper()
+	for _, dep := range report.Dependencies {
+		if dep.Name == name {
+			return dep
+		}
+	}
+	t.Fatalf("report has no %s dependency", name)
+	return domain.DependencyHealth{}
+}
+
+func TestGetHealthUp(t *testing.T) {
+	s := NewHealthService(memory.NewHealth(), clock.NewFake(time.Now()), zap.NewNop())
+
+	report := s.GetHealth(context.Background())
+	if report.Status != domain.HealthUp {
+		t.Fatalf("status = %s, want up: %+v", report.Status, report.Dependencies)
+	}
+	for _, name := range []string{"database", "schema", "cache"} {
+		if dep := dependency(t, report, name); dep.Status != domain.HealthUp {
+			t.Fatalf("%s = %+v, want up", name, dep)
+		}
+	}
+}
+
+func TestGetHealthDatabaseDown(t *testing.T) {
+	s := NewHealthService(failingDatabase{}, clock.NewFake(time.Now()), zap.NewNop())
+
+	report := s.GetHealth(context.Background())
+	if report.Status != domain.HealthDown {
+		t.Fatalf("status = %s, want down", report.Status)
+	}
+	for _, name := range []string{"database", "schema"} {
+		dep := dependency(t, report, name)
+		if dep.Status != domain.HealthDown || dep.Detail != healthUnavailable {
+			t.Fatalf("%s = %+v, want down and %s", name, dep, healthUnavailable)
+		}
+	}
+	// The in-process cache is up whatever the database does
+	if dep := dependency(t, report, "cache"); dep.Status != domain.HealthUp {
+		t.Fatalf("cache = %+v, want up", dep)
+	}
+	for _, dep := range report.Dependencies {
+		if strings.Contains(dep.Detail, "db.internal") || strings.Contains(dep.Detail, "sessions") {
+			t.Fatalf("%s detail %q leaks the driver error", dep.Name, dep.Detail)
+		}
+	}
+}
+
+func TestGetHealthDatabaseTimeout(t *testing.T) {
+	s := NewHealthService(hungDatabase{}, clock.NewFake(time.Now()), zap.NewNop())
+
+	// The caller's deadline is shorter than healthProbeTimeout, so the probes give up with it
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	report := s.GetHealth(ctx)
+	if dep := dependency(t, report, "database"); dep.Status != domain.HealthDown || dep.Detail != healthTimeout {
+		t.Fatalf("database = %+v, want down and %s", dep, healthTimeout)
+	}
+}
+
+func TestPingSkipsDependencies(t *testing.T) {
+	// The database is down and probes would hang, yet Ping answers at once
+	fake := clock.NewFake(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC))
+	s := NewHealthService(hungDatabase{}, fake, zap.NewNop())
+
+	pong := s.Ping(context.Background())
+	if !pong.ServerTime.Equal(fake.Now()) || pong.Version != buildinfo.Version() {
+		t.Fatalf("pong = %+v, want the server time %v and version %s", pong, fake.Now(), buildinfo.Version())
+	}
+}
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/fx/fxtest"
+	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
+
+	"worker/internal/adapter/captcha"
+	"worker/internal/adapter/claims"
+	"worker/internal/adapter/hasher"
+	"worker/internal/adapter/logger"
+	"worker/internal/adapter/notifier"
+	"worker/internal/adapter/storage/memory"
+	"worker/internal/common/clock"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// testPassword satisfies the default password policy
+const testPassword = "Correct-Horse-9-Battery"
+
+// testService is an AuthService over the in-memory storage (STORAGE_BACKEND=memory) and a fake clock
+type testService struct {
+	*AuthService
+	clock *clock.Fake
+	store *memory.Store
+	cfg   *config.Config
+}
+
+// newTestService loads the default configuration, lets configure adjust it, and wires an AuthService
+// the way services.Module does, with in-memory adapters and a clock that only moves when advanced
+func newTestService(tb testing.TB, configure func(*config.Config)) *testService {
+	tb.Helper()
+	tb.Setenv("STORAGE_BACKEND", config.StorageBackendMemory)
+	tb.Setenv("JWT_ACCESS_SECRET", "test-access-secret")
+	tb.Setenv("JWT_REFRESH_SECRET", "test-refresh-secret")
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		tb.Fatalf("load config: %v", err)
+	}
+	cfg.Auth.BcryptCost = bcrypt.MinCost // Keeps logins fast; the cost is not under test
+	if configure != nil {
+		configure(cfg)
+	}
+
+	fake := clock.NewFake(time.Date(2026, time.January, 1, 9, 0, 0, 0, time.UTC))
+	store := memory.NewStore(fake)
+	store.Seed(cfg.Auth.DefaultRoleCode)
+	return wireTestService(tb, cfg, store, fake)
+}
+
+// restart wires a new AuthService over the same store and clock, like a worker restarting against
+// the same database: every cache, queue and subscription of the process starts empty
+func (s *testService) restart(tb testing.TB) *testService {
+	tb.Helper()
+	return wireTestService(tb, s.cfg, s.store, s.clock)
+}
+
+// wireTestService creates an AuthService over store with fresh in-process adapters
+func wireTestService(tb testing.TB, cfg *config.Config, store *memory.Store, fake *clock.Fake) *testService {
+	log := zap.NewNop()
+	svc := NewAuthService(
+		memory.NewUserRepository(store),
+		memory.NewRoleRepository(store),
+		memory.NewSessionRepository(store),
+		memory.NewAccessTokenRepository(store),
+		memory.NewPasswordResetCodeRepository(store),
+		memory.NewUnitOfWork(store),
+		memory.NewObjectStorage(""),
+		memory.NewPermissionCache(cfg.Auth.PermissionCacheTTL),
+		memory.NewTokenVersionCache(cfg.Auth.TokenVersionCacheTTL),
+		memory.NewRevocationBroker(16),
+		logger.NewAuditLog(log),
+		memory.NewLastLoginRecorder(store),
+		memory.NewRiskEvaluator(cfg.Auth.RiskFailureWindow, cfg.Auth.RiskChallengeFailures, cfg.Auth.RiskDenyFailures, fake),
+		memory.NewLoginThrottle(cfg.Auth.LoginDelayBase, cfg.Auth.LoginDelayMax, cfg.Auth.RiskFailureWindow, fake),
+		claims.NewEnricher(),
+		memory.NewRateLimiter(cfg.Auth.AvailabilityCheckLimit, cfg.Auth.AvailabilityCheckWindow, fake),
+		captcha.NewVerifier(&cfg.Captcha, log),
+		hasher.NewHasher(&cfg.Auth, log),
+		notifier.NewNotifier(&cfg.Notifier, log),
+		memory.NewPoolStats(),
+		memory.NewStatsQuerier(store),
+		fake,
+		&cfg.JWT,
+		&cfg.Auth,
+		&cfg.Avatar,
+		config.NewReloader(fxtest.NewLifecycle(tb), cfg, log),
+		log,
+	)
+	return &testService{AuthService: svc, clock: fake, store: store, cfg: cfg}
+}
+
+// register creates an account named username and returns its tokens
+func (s *testService) register(tb testing.TB, username string) *ports.AuthResponse {
+	tb.Helper()
+	resp, err := s.Register(context.Background(), &domain.RegisterRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: testPassword,
+		FullName: "Test " + username,
+	})
+	if err != nil {
+		tb.Fatalf("register %s: %v", username, err)
+	}
+	return resp
+}
+
+// login logs username in with testPassword
+func (s *testService) login(username string) (*ports.AuthResponse, error) {
+	return s.Login(context.Background(), &domain.LoginRequest{Identifier: username, Password: testPassword})
+}
+
+// mustLogin is login for logins expected to succeed
+func (s *testService) mustLogin(tb testing.TB, username string) *ports.AuthResponse {
+	tb.Helper()
+	resp, err := s.login(username)
+	if err != nil {
+		tb.Fatalf("login %s: %v", username, err)
+	}
+	return resp
+}
+
+// promote makes roleCode the user's primary role
+func (s *testService) promote(tb testing.TB, userID uuid.UUID, roleCode string) {
+	tb.Helper()
+	ctx := context.Background()
+	role, err := s.roleRepo.FindByCode(ctx, roleCode)
+	if err != nil {
+		tb.Fatalf("find role %s: %v", roleCode, err)
+	}
+	if err := s.userRepo.SetPrimaryRole(ctx, userID, role.ID); err != nil {
+		tb.Fatalf("set role %s: %v", roleCode, err)
+	}
+}
+
+// registerAdmin registers an active account named admin with the ADMIN role and returns its ID
+func (s *testService) registerAdmin(tb testing.TB) uuid.UUID {
+	tb.Helper()
+	adminID := s.register(tb, "admin").User.ID
+	if err := s.userRepo.SetActive(context.Background(), adminID, true); err != nil {
+		tb.Fatalf("activate admin: %v", err)
+	}
+	s.promote(tb, adminID, "ADMIN")
+	return adminID
+}
+
+// assertCode fails unless err is an AuthError with code
+func assertCode(tb testing.TB, err error, code string) {
+	tb.Helper()
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) {
+		tb.Fatalf("got error %v, want an AuthError with code %s", err, code)
+	}
+	if authErr.Code != code {
+		tb.Fatalf("got code %s (%v), want %s", authErr.Code, err, code)
+	}
+}
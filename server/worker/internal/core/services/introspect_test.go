@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"worker/internal/core/domain"
+)
+
+func TestIntrospectAccessToken(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	// The hint only decides which type is tried first
+	for _, hint := range []string{domain.TokenTypeAccess, domain.TokenTypeRefresh, ""} {
+		introspection, err := s.IntrospectToken(ctx, resp.AccessToken, hint)
+		if err != nil {
+			t.Fatalf("introspect with hint %q: %v", hint, err)
+		}
+		if !introspection.Active || introspection.TokenType != domain.TokenTypeAccess {
+			t.Fatalf("introspect with hint %q = %+v, want an active access token", hint, introspection)
+		}
+		if introspection.Subject != resp.User.ID.String() || introspection.Username != "alice" {
+			t.Fatalf("introspect with hint %q = %+v, want alice's claims", hint, introspection)
+		}
+		if !introspection.ExpiresAt.After(introspection.IssuedAt) {
+			t.Fatalf("introspect with hint %q: exp %v is not after iat %v", hint, introspection.ExpiresAt, introspection.IssuedAt)
+		}
+	}
+}
+
+func TestIntrospectRefreshToken(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	introspection, err := s.IntrospectToken(ctx, resp.RefreshToken, domain.TokenTypeRefresh)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	if !introspection.Active || introspection.TokenType != domain.TokenTypeRefresh {
+		t.Fatalf("introspect = %+v, want an active refresh token", introspection)
+	}
+	// Refresh tokens carry no profile claims, they come from the user record
+	if introspection.Username != "alice" || introspection.Role != s.authConfig.DefaultRoleCode {
+		t.Fatalf("introspect = %+v, want alice's profile", introspection)
+	}
+
+	// A rotated refresh token is no longer usable, so no longer active
+	if _, err := s.RefreshAccessToken(ctx, resp.RefreshToken); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	introspection, err = s.IntrospectToken(ctx, resp.RefreshToken, domain.TokenTypeRefresh)
+	if err != nil || introspection.Active {
+		t.Fatalf("introspect rotated refresh token = %+v, %v; want inactive", introspection, err)
+	}
+}
+
+func TestIntrospectInactiveTokens(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	for _, token := range []string{"", "garbage", "a.b.c", resp.AccessToken + "x"} {
+		introspection, err := s.IntrospectToken(ctx, token, domain.TokenTypeAccess)
+		if err != nil || introspection.Active {
+			t.Fatalf("introspect %q = %+v, %v; want inactive", token, introspection, err)
+		}
+	}
+
+	s.clock.Advance(s.config.AccessExpiration)
+	introspection, err := s.IntrospectToken(ctx, resp.AccessToken, domain.TokenTypeAccess)
+	if err != nil || introspection.Active {
+		t.Fatalf("introspect expired access token = %+v, %v; want inactive", introspection, err)
+	}
+
+	s.clock.Advance(s.config.RefreshExpiration)
+	introspection, err = s.IntrospectToken(ctx, resp.RefreshToken, domain.TokenTypeRefresh)
+	if err != nil || introspection.Active {
+		t.Fatalf("introspect expired refresh token = %+v, %v; want inactive", introspection, err)
+	}
+}
+
+func TestIntrospectRevokedAccessToken(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	introspection, err := s.IntrospectToken(ctx, resp.AccessToken, domain.TokenTypeAccess)
+	if err != nil || !introspection.Active {
+		t.Fatalf("introspect fresh access token = %+v, %v; want active", introspection, err)
+	}
+
+	// Bumping the token version revokes every access token issued before
+	if err := s.revokeAccessTokens(ctx, resp.User.ID); err != nil {
+		t.Fatalf("revoke access tokens: %v", err)
+	}
+	introspection, err = s.IntrospectToken(ctx, resp.AccessToken, domain.TokenTypeAccess)
+	if err != nil || introspection.Active {
+		t.Fatalf("introspect revoked access token = %+v, %v; want inactive", introspection, err)
+	}
+}
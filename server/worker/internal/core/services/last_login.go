@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/common/utils"
+	"worker/internal/core/domain"
+)
+
+// recordLogin stores the time and client of a successful login, and audits logins
+// from a client different from the previous login
+// The update is queued for the background writer so a slow write never delays the login
+func (s *AuthService) recordLogin(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow) {
+	client := domain.ClientInfoFromContext(ctx)
+	previousIP := utils.PtrStringValue(user.LastLoginIp)
+	previousUA := utils.PtrStringValue(user.LastLoginUserAgent)
+
+	if user.LastLogin.Valid && (changed(previousIP, client.IP) || changed(previousUA, client.UserAgent)) {
+		s.auditLog.Record(ctx, domain.AuditEvent{
+			Type:       domain.AuditLoginNewClient,
+			UserID:     user.ID.String(),
+			TenantID:   user.TenantID,
+			Client:     client,
+			PreviousIP: previousIP,
+			PreviousUA: previousUA,
+			At:         s.clock.Now(),
+		})
+	}
+
+	s.lastLogins.Record(domain.LastLogin{
+		UserID: user.ID,
+		Client: client,
+		At:     s.clock.Now(),
+	})
+}
+
+// changed reports whether a client field differs from the previous login
+// Unknown values on either side never count as a change
+func changed(previous, current string) bool {
+	return previous != "" && current != "" && previous != current
+}
@@ -0,0 +1,30 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"worker/internal/core/domain"
+)
+
+// resolveLocale returns the canonical form of the requested locale
+// An empty request falls back to the client's accept-language, then to the first supported locale
+func (s *AuthService) resolveLocale(ctx context.Context, requested string) (string, error) {
+	if requested == "" {
+		if matched := s.locales.FromAcceptLanguage(domain.ClientInfoFromContext(ctx).AcceptLanguage); matched != "" {
+			return matched, nil
+		}
+		return s.locales.Default(), nil
+	}
+
+	canonical, ok := s.locales.Normalize(requested)
+	if !ok {
+		return "", domain.NewFieldError(
+			domain.ErrInvalidLocale,
+			"locale",
+			fmt.Sprintf("locale must be one of %s", strings.Join(s.locales.Supported(), ", ")),
+		)
+	}
+	return canonical, nil
+}
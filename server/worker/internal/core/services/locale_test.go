@@ -0,0 +1,74 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+func TestRegisterLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		locale         string // Requested locale
+		acceptLanguage string
+		want           string
+	}{
+		{name: "requested", locale: "vi", acceptLanguage: "en", want: "vi"},
+		{name: "requested in another case", locale: "EN-us", want: "en-US"},
+		{name: "from accept-language", acceptLanguage: "vi-VN,vi;q=0.9,en;q=0.8", want: "vi"},
+		{name: "accept-language without a match", acceptLanguage: "fr-FR,de;q=0.9", want: "en"},
+		{name: "default", want: "en"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, func(cfg *config.Config) {
+				cfg.Auth.SupportedLocales = []string{"en", "vi", "en-US"}
+			})
+			ctx := domain.WithClientInfo(context.Background(), domain.ClientInfo{AcceptLanguage: tt.acceptLanguage})
+
+			resp, err := s.Register(ctx, &domain.RegisterRequest{
+				Username: "alice",
+				Email:    "alice@example.com",
+				Password: testPassword,
+				FullName: "Alice",
+				Locale:   tt.locale,
+			})
+			if err != nil {
+				t.Fatalf("register: %v", err)
+			}
+			if resp.User.Locale == nil || *resp.User.Locale != tt.want {
+				t.Fatalf("locale = %v, want %s", resp.User.Locale, tt.want)
+			}
+
+			// The stored locale is the one issued in later tokens
+			claims, err := s.parseAccessToken(ctx, s.mustLogin(t, "alice").AccessToken)
+			if err != nil {
+				t.Fatalf("parse access token: %v", err)
+			}
+			if claims.Locale != tt.want {
+				t.Fatalf("locale claim = %q, want %s", claims.Locale, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegisterRejectsUnsupportedLocale(t *testing.T) {
+	s := newTestService(t, nil)
+	for _, requested := range []string{"fr", "vi-VN", "not a locale"} {
+		_, err := s.Register(context.Background(), &domain.RegisterRequest{
+			Username: "alice",
+			Email:    "alice@example.com",
+			Password: testPassword,
+			FullName: "Alice",
+			Locale:   requested,
+		})
+		assertCode(t, err, domain.CodeInvalidArgument)
+		var authErr *domain.AuthError
+		if !errors.As(err, &authErr) || authErr.Field != "locale" || !errors.Is(err, domain.ErrInvalidLocale) {
+			t.Fatalf("register with locale %q = %v, want an invalid locale error", requested, err)
+		}
+	}
+}
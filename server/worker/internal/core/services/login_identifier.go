@@ -0,0 +1,50 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// findLoginUser looks up the user a login identifier names, as allowed by AUTH_LOGIN_IDENTIFIER
+// An identifier containing "@" is an email address, anything else a username
+func (s *AuthService) findLoginUser(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error) {
+	isEmail := strings.Contains(identifier, "@")
+
+	switch s.authConfig.LoginIdentifier {
+	case config.LoginIdentifierEmail:
+		if !isEmail {
+			return nil, loginIdentifierNotAccepted("sign in with your email address")
+		}
+		user, err := s.userRepo.FindByEmail(ctx, tenantID, identifier)
+		if err != nil {
+			return nil, err
+		}
+		row := sqlc.GetUserByEmailOrUsernameRow(*user)
+		return &row, nil
+	case config.LoginIdentifierUsername:
+		if isEmail {
+			return nil, loginIdentifierNotAccepted("sign in with your username")
+		}
+		user, err := s.findByUsername(ctx, tenantID, identifier)
+		if err != nil {
+			return nil, err
+		}
+		row := sqlc.GetUserByEmailOrUsernameRow(*user)
+		return &row, nil
+	default:
+		return s.findByEmailOrUsername(ctx, tenantID, identifier)
+	}
+}
+
+// loginIdentifierNotAccepted reports an identifier of a kind AUTH_LOGIN_IDENTIFIER rules out
+func loginIdentifierNotAccepted(message string) error {
+	return domain.NewAuthError(
+		domain.ErrLoginIdentifier,
+		message,
+		domain.CodeLoginIdentifier,
+	).WithField("username")
+}
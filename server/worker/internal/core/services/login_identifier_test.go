@@ -0,0 +1,96 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// lookupRecorder records which user lookup Login used
+type lookupRecorder struct {
+	ports.UserRepository
+	lookups []string
+}
+
+func (r *lookupRecorder) FindByEmail(ctx context.Context, tenantID, email string) (*sqlc.GetUserByEmailRow, error) {
+	r.lookups = append(r.lookups, "email")
+	return r.UserRepository.FindByEmail(ctx, tenantID, email)
+}
+
+func (r *lookupRecorder) FindByUsername(ctx context.Context, tenantID, username string) (*sqlc.GetUserByUsernameRow, error) {
+	r.lookups = append(r.lookups, "username")
+	return r.UserRepository.FindByUsername(ctx, tenantID, username)
+}
+
+func (r *lookupRecorder) FindByEmailOrUsername(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error) {
+	r.lookups = append(r.lookups, "both")
+	return r.UserRepository.FindByEmailOrUsername(ctx, tenantID, identifier)
+}
+
+func TestLoginIdentifierPolicy(t *testing.T) {
+	tests := []struct {
+		policy     string
+		identifier string
+		lookup     string // Lookup used, empty when the identifier is refused before any
+	}{
+		{policy: config.LoginIdentifierEmail, identifier: "alice@example.com", lookup: "email"},
+		{policy: config.LoginIdentifierEmail, identifier: "alice"},
+		{policy: config.LoginIdentifierUsername, identifier: "alice", lookup: "username"},
+		{policy: config.LoginIdentifierUsername, identifier: "alice@example.com"},
+		{policy: config.LoginIdentifierBoth, identifier: "alice", lookup: "both"},
+		{policy: config.LoginIdentifierBoth, identifier: "alice@example.com", lookup: "both"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.policy+"/"+tt.identifier, func(t *testing.T) {
+			s := newTestService(t, func(cfg *config.Config) {
+				cfg.Auth.LoginIdentifier = tt.policy
+			})
+			s.register(t, "alice")
+			users := &lookupRecorder{UserRepository: s.userRepo}
+			s.userRepo = users
+
+			resp, err := s.Login(context.Background(), &domain.LoginRequest{Identifier: tt.identifier, Password: testPassword})
+			if tt.lookup == "" {
+				assertCode(t, err, domain.CodeLoginIdentifier)
+				var authErr *domain.AuthError
+				if !errors.As(err, &authErr) || authErr.Field != "username" {
+					t.Fatalf("login = %v, want an error on the username field", err)
+				}
+				if len(users.lookups) != 0 {
+					t.Fatalf("refused identifier was looked up with %v", users.lookups)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("login: %v", err)
+			}
+			if resp.User.Username != "alice" {
+				t.Fatalf("logged in as %s, want alice", resp.User.Username)
+			}
+			if len(users.lookups) != 1 || users.lookups[0] != tt.lookup {
+				t.Fatalf("lookups = %v, want one %s lookup", users.lookups, tt.lookup)
+			}
+		})
+	}
+}
+
+func TestLoginIdentifierPolicyUnknownUser(t *testing.T) {
+	// An accepted identifier that matches no one fails like it does with both kinds allowed
+	for _, policy := range []string{config.LoginIdentifierEmail, config.LoginIdentifierUsername} {
+		s := newTestService(t, func(cfg *config.Config) {
+			cfg.Auth.LoginIdentifier = policy
+		})
+		identifier := "nobody"
+		if policy == config.LoginIdentifierEmail {
+			identifier = "nobody@example.com"
+		}
+		_, err := s.login(identifier)
+		assertCode(t, err, domain.CodeUserNotFound)
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+)
+
+// loginAttempt describes a login of user for the risk evaluator
+func loginAttempt(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow) domain.LoginAttempt {
+	return domain.LoginAttempt{
+		UserID:   user.ID.String(),
+		TenantID: user.TenantID,
+		Client:   domain.ClientInfoFromContext(ctx),
+	}
+}
+
+// evaluateLoginRisk asks the risk evaluator whether a login with correct credentials may proceed
+// Challenged and denied logins are audited; evaluator failures refuse the login
+func (s *AuthService) evaluateLoginRisk(ctx context.Context, attempt domain.LoginAttempt) error {
+	assessment, err := s.riskEvaluator.Evaluate(ctx, attempt)
+	if err != nil {
+		return domain.NewAuthError(
+			domain.ErrLoginDenied,
+			"failed to evaluate login risk",
+			domain.CodeInternalError,
+		)
+	}
+
+	switch assessment.Decision {
+	case domain.RiskAllow:
+		return nil
+	case domain.RiskChallenge:
+		s.auditRisk(ctx, domain.AuditLoginChallenged, attempt, assessment)
+		return domain.NewAuthError(
+			domain.ErrLoginChallenged,
+			"additional verification is required to log in",
+			domain.CodeLoginChallenged,
+		)
+	default:
+		// Unknown decisions from a custom evaluator are treated as a denial
+		s.auditRisk(ctx, domain.AuditLoginDenied, attempt, assessment)
+		return domain.NewAuthError(
+			domain.ErrLoginDenied,
+			"login was denied, try again later or contact an administrator",
+			domain.CodeLoginDenied,
+		)
+	}
+}
+
+func (s *AuthService) auditRisk(ctx context.Context, eventType string, attempt domain.LoginAttempt, assessment domain.RiskAssessment) {
+	s.auditLog.Record(ctx, domain.AuditEvent{
+		Type:     eventType,
+		UserID:   attempt.UserID,
+		TenantID: attempt.TenantID,
+		Client:   attempt.Client,
+		Reason:   assessment.Reason,
+		At:       s.clock.Now(),
+	})
+}
+
+// loginThrottleKey identifies the failed logins that delay each other: one identifier from one client IP
+// Keying on both keeps an attacker elsewhere from slowing down the account owner's own logins
+func (s *AuthService) loginThrottleKey(ctx context.Context, identifier string) string {
+	return s.normalizeLoginIdentifier(identifier) + "|" + domain.ClientInfoFromContext(ctx).IP
+}
+
+// throttleFailedLogin counts a failed login and holds its response back for the throttle's delay
+// Unknown identifiers are delayed like wrong passwords, so the delay does not reveal which users exist
+func (s *AuthService) throttleFailedLogin(ctx context.Context, identifier string) {
+	delay := s.loginThrottle.RecordFailure(ctx, s.loginThrottleKey(ctx, identifier))
+	if delay <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+	case <-ctx.Done():
+	}
+}
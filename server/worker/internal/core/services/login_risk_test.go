@@ -0,0 +1,197 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// stubRiskEvaluator decides every login the same way and records the outcomes Login reports to it
+type stubRiskEvaluator struct {
+	assessment domain.RiskAssessment
+	err        error
+	failures   []domain.LoginAttempt
+	successes  []domain.LoginAttempt
+}
+
+func (e *stubRiskEvaluator) Evaluate(ctx context.Context, attempt domain.LoginAttempt) (domain.RiskAssessment, error) {
+	return e.assessment, e.err
+}
+
+func (e *stubRiskEvaluator) RecordFailure(ctx context.Context, attempt domain.LoginAttempt) {
+	e.failures = append(e.failures, attempt)
+}
+
+func (e *stubRiskEvaluator) RecordSuccess(ctx context.Context, attempt domain.LoginAttempt) {
+	e.successes = append(e.successes, attempt)
+}
+
+var _ ports.RiskEvaluator = (*stubRiskEvaluator)(nil)
+
+// riskEvents returns the challenged and denied logins among the recorded audit events
+func riskEvents(audit *recordingAuditLog) []domain.AuditEvent {
+	var events []domain.AuditEvent
+	for _, event := range audit.events {
+		if event.Type == domain.AuditLoginChallenged || event.Type == domain.AuditLoginDenied {
+			events = append(events, event)
+		}
+	}
+	return events
+}
+
+func TestLoginRiskDecisions(t *testing.T) {
+	tests := []struct {
+		name      string
+		evaluator stubRiskEvaluator
+		code      string // Empty when the login succeeds
+		event     string // Audit event of the refused login
+	}{
+		{name: "allow", evaluator: stubRiskEvaluator{assessment: domain.RiskAssessment{Decision: domain.RiskAllow}}},
+		{
+			name:      "challenge",
+			evaluator: stubRiskEvaluator{assessment: domain.RiskAssessment{Decision: domain.RiskChallenge, Reason: "login from a new country"}},
+			code:      domain.CodeLoginChallenged,
+			event:     domain.AuditLoginChallenged,
+		},
+		{
+			name:      "deny",
+			evaluator: stubRiskEvaluator{assessment: domain.RiskAssessment{Decision: domain.RiskDeny, Reason: "too many recent failed logins"}},
+			code:      domain.CodeLoginDenied,
+			event:     domain.AuditLoginDenied,
+		},
+		{
+			// Decisions a custom evaluator made up are not trusted to mean allow
+			name:      "unknown decision",
+			evaluator: stubRiskEvaluator{assessment: domain.RiskAssessment{Decision: "maybe", Reason: "unsure"}},
+			code:      domain.CodeLoginDenied,
+			event:     domain.AuditLoginDenied,
+		},
+		{
+			name:      "evaluator failure",
+			evaluator: stubRiskEvaluator{err: errors.New("risk service unavailable")},
+			code:      domain.CodeInternalError,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, nil)
+			alice := s.register(t, "alice")
+			evaluator := &tt.evaluator
+			s.riskEvaluator = evaluator
+			audit := &recordingAuditLog{}
+			s.auditLog = audit
+
+			ctx := domain.WithClientInfo(context.Background(), domain.ClientInfo{IP: "10.0.0.1", Country: "VN"})
+			resp, err := s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: testPassword})
+			if tt.code == "" {
+				if err != nil {
+					t.Fatalf("login: %v", err)
+				}
+				if resp.AccessToken == "" || len(evaluator.successes) != 1 {
+					t.Fatalf("allowed login issued no tokens or was not recorded: %+v, %d successes", resp, len(evaluator.successes))
+				}
+				if got := evaluator.successes[0]; got.UserID != alice.User.ID.String() || got.Client.Country != "VN" {
+					t.Fatalf("recorded attempt = %+v, want alice from VN", got)
+				}
+				if events := riskEvents(audit); len(events) != 0 {
+					t.Fatalf("allowed login audited as %+v", events)
+				}
+				return
+			}
+
+			assertCode(t, err, tt.code)
+			if resp != nil {
+				t.Fatalf("refused login returned %+v", resp)
+			}
+			if len(evaluator.successes) != 0 {
+				t.Fatal("refused login was recorded as a success")
+			}
+			// No session was started for the refused login
+			sessions, err := s.sessionRepo.ListActiveIDsForUser(context.Background(), alice.User.ID, s.clock.Now(), time.Time{})
+			if err != nil {
+				t.Fatalf("list sessions: %v", err)
+			}
+			if len(sessions) != 1 {
+				t.Fatalf("%d active sessions after a refused login, want only the registration's", len(sessions))
+			}
+
+			events := riskEvents(audit)
+			if tt.event == "" {
+				if len(events) != 0 {
+					t.Fatalf("evaluator failure audited as %+v", events)
+				}
+				return
+			}
+			if len(events) != 1 || events[0].Type != tt.event {
+				t.Fatalf("audit events = %+v, want one %s", events, tt.event)
+			}
+			if got := events[0]; got.UserID != alice.User.ID.String() || got.Reason != tt.evaluator.assessment.Reason || got.Client.IP != "10.0.0.1" {
+				t.Fatalf("audit event = %+v, want alice's attempt with the evaluator's reason", got)
+			}
+		})
+	}
+}
+
+func TestLoginRiskRecordsFailures(t *testing.T) {
+	s := newTestService(t, nil)
+	s.register(t, "alice")
+	evaluator := &stubRiskEvaluator{assessment: domain.RiskAssessment{Decision: domain.RiskAllow}}
+	s.riskEvaluator = evaluator
+
+	_, err := s.Login(context.Background(), &domain.LoginRequest{Identifier: "alice", Password: "Wrong-Horse-9-Battery"})
+	assertCode(t, err, domain.CodeIncorrectPassword)
+	// Unknown users have no one to count the failure against
+	_, err = s.login("nobody")
+	assertCode(t, err, domain.CodeUserNotFound)
+
+	if len(evaluator.failures) != 1 || len(evaluator.successes) != 0 {
+		t.Fatalf("recorded %d failures and %d successes, want the wrong password only", len(evaluator.failures), len(evaluator.successes))
+	}
+}
+
+func TestLoginDefaultRiskEvaluator(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.LoginDelayBase = 0 // The failures below are not meant to slow the test down
+		cfg.Auth.RiskChallengeFailures = 2
+		cfg.Auth.RiskDenyFailures = 4
+	})
+	s.register(t, "alice")
+	wrong := &domain.LoginRequest{Identifier: "alice", Password: "Wrong-Horse-9-Battery"}
+	fail := func(n int) {
+		t.Helper()
+		for range n {
+			_, err := s.Login(context.Background(), wrong)
+			assertCode(t, err, domain.CodeIncorrectPassword)
+		}
+	}
+
+	fail(2)
+	_, err := s.login("alice")
+	assertCode(t, err, domain.CodeLoginChallenged)
+	fail(2)
+	_, err = s.login("alice")
+	assertCode(t, err, domain.CodeLoginDenied)
+
+	// Failures expire with the window, and the next correct login is allowed
+	s.clock.Advance(s.authConfig.RiskFailureWindow + 1)
+	s.mustLogin(t, "alice")
+
+	// A country the user never logged in from is challenged once another is known
+	from := func(country string) (*ports.AuthResponse, error) {
+		ctx := domain.WithClientInfo(context.Background(), domain.ClientInfo{IP: "10.0.0.1", Country: country})
+		return s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: testPassword})
+	}
+	if _, err := from("VN"); err != nil {
+		t.Fatalf("login from the first country: %v", err)
+	}
+	_, err = from("DE")
+	assertCode(t, err, domain.CodeLoginChallenged)
+	if _, err := from("VN"); err != nil {
+		t.Fatalf("login from a known country: %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+func TestLoginHideInactive(t *testing.T) {
+	tests := []struct {
+		name   string
+		login  func(s *testService) error
+		shown  string // Code with AUTH_HIDE_INACTIVE off
+		hidden string // Code with AUTH_HIDE_INACTIVE on
+	}{
+		{
+			name: "unknown account",
+			login: func(s *testService) error {
+				_, err := s.login("nobody")
+				return err
+			},
+			shown:  domain.CodeUserNotFound,
+			hidden: domain.CodeInvalidCredentials,
+		},
+		{
+			name: "wrong password",
+			login: func(s *testService) error {
+				_, err := s.Login(context.Background(), &domain.LoginRequest{Identifier: "alice", Password: "Wrong-Horse-9-Battery"})
+				return err
+			},
+			shown:  domain.CodeIncorrectPassword,
+			hidden: domain.CodeInvalidCredentials,
+		},
+		{
+			name: "deactivated account",
+			login: func(s *testService) error {
+				_, err := s.login("bob")
+				return err
+			},
+			shown:  domain.CodeUserInactive,
+			hidden: domain.CodeInvalidCredentials,
+		},
+		{
+			name: "deactivated account with a wrong password",
+			login: func(s *testService) error {
+				_, err := s.Login(context.Background(), &domain.LoginRequest{Identifier: "bob", Password: "Wrong-Horse-9-Battery"})
+				return err
+			},
+			shown:  domain.CodeUserInactive,
+			hidden: domain.CodeInvalidCredentials,
+		},
+	}
+
+	for _, hide := range []bool{false, true} {
+		for _, tt := range tests {
+			t.Run(fmt.Sprintf("hide=%t/%s", hide, tt.name), func(t *testing.T) {
+				s := newTestService(t, func(cfg *config.Config) {
+					cfg.Auth.HideInactive = hide
+				})
+				s.register(t, "alice")
+				bob := s.register(t, "bob")
+				s.mustLogin(t, "bob") // A deactivated account is one that was in use, not one awaiting approval
+				if err := s.userRepo.SetActive(context.Background(), bob.User.ID, false); err != nil {
+					t.Fatalf("deactivate: %v", err)
+				}
+
+				want := tt.shown
+				if hide {
+					want = tt.hidden
+				}
+				assertCode(t, tt.login(s), want)
+			})
+		}
+	}
+}
+
+func TestLoginHideInactiveAllowsActiveAccounts(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.HideInactive = true
+	})
+	s.register(t, "alice")
+	s.mustLogin(t, "alice")
+}
+
+// countingThrottle counts the failures Login reports without delaying them
+type countingThrottle struct {
+	failures int
+}
+
+func (t *countingThrottle) RecordFailure(ctx context.Context, key string) time.Duration {
+	t.failures++
+	return 0
+}
+
+func (t *countingThrottle) Reset(ctx context.Context, key string) {}
+
+func TestLoginThrottle(t *testing.T) {
+	for _, hide := range []bool{false, true} {
+		t.Run(fmt.Sprintf("hide=%t", hide), func(t *testing.T) {
+			s := newTestService(t, func(cfg *config.Config) {
+				cfg.Auth.HideInactive = hide
+			})
+			throttle := &countingThrottle{}
+			s.loginThrottle = throttle
+			ctx := context.Background()
+			s.register(t, "alice")
+			bob := s.register(t, "bob")
+			s.mustLogin(t, "bob")
+			if err := s.userRepo.SetActive(ctx, bob.User.ID, false); err != nil {
+				t.Fatalf("deactivate: %v", err)
+			}
+
+			if _, err := s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: "Wrong-Horse-9-Battery"}); err == nil {
+				t.Fatal("login with a wrong password succeeded")
+			}
+			if _, err := s.login("nobody"); err == nil {
+				t.Fatal("login of an unknown account succeeded")
+			}
+			if throttle.failures != 2 {
+				t.Fatalf("throttled failures = %d, want 2", throttle.failures)
+			}
+
+			// The right password of a deactivated account is no guess to slow down
+			if _, err := s.login("bob"); err == nil {
+				t.Fatal("login of a deactivated account succeeded")
+			}
+			if throttle.failures != 2 {
+				t.Fatalf("throttled failures after a deactivated login = %d, want 2", throttle.failures)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"go.uber.org/fx"
 
+	"worker/internal/common/clock"
 	"worker/internal/core/ports"
 )
 
@@ -14,5 +15,15 @@ var Module = fx.Module("services",
 			NewAuthService,
 			fx.As(new(ports.AuthService)),
 		),
+		fx.Annotate(
+			NewHealthService,
+			fx.As(new(ports.HealthService)),
+		),
+		// Time source of the services, replaced by clock.Fake in tests
+		fx.Annotate(
+			clock.NewReal,
+			fx.As(new(ports.Clock)),
+		),
 	),
+	fx.Invoke(startDeletionSweeper),
 )
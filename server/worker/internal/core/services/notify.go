@@ -0,0 +1,43 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/logger"
+	"worker/internal/adapter/storage/postgres/sqlc"
+)
+
+// notifyRegistered announces a committed registration, best-effort
+// The account exists whatever happens here, so a failure is only logged and a client
+// hanging up does not cancel the delivery; the notifier bounds it by NOTIFY_TIMEOUT
+func (s *AuthService) notifyRegistered(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow) {
+	if err := s.notifier.UserRegistered(context.WithoutCancel(ctx), user); err != nil {
+		logger.FromContext(ctx, s.logger).Warn("Failed to announce registration",
+			zap.String("user_id", user.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// notifyApproved announces an approved account, best-effort like notifyRegistered
+func (s *AuthService) notifyApproved(ctx context.Context, user *sqlc.GetUserByIDRow, at time.Time) {
+	if err := s.notifier.UserApproved(context.WithoutCancel(ctx), user, at); err != nil {
+		logger.FromContext(ctx, s.logger).Warn("Failed to announce approval",
+			zap.String("user_id", user.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
+
+// notifyRejected announces a rejected account, best-effort like notifyRegistered
+func (s *AuthService) notifyRejected(ctx context.Context, user *sqlc.GetUserByIDRow, reason string, at time.Time) {
+	if err := s.notifier.UserRejected(context.WithoutCancel(ctx), user, reason, at); err != nil {
+		logger.FromContext(ctx, s.logger).Warn("Failed to announce rejection",
+			zap.String("user_id", user.ID.String()),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"worker/internal/adapter/notifier"
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+)
+
+// fakeNotifier records the events it is told about and fails them with err
+type fakeNotifier struct {
+	notifier.NoopNotifier
+	registered []*sqlc.GetUserByEmailOrUsernameRow
+	contexts   []context.Context
+	err        error
+}
+
+func (n *fakeNotifier) UserRegistered(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow) error {
+	n.registered = append(n.registered, user)
+	n.contexts = append(n.contexts, ctx)
+	return n.err
+}
+
+func TestRegisterNotifies(t *testing.T) {
+	s := newTestService(t, nil)
+	notified := &fakeNotifier{}
+	s.notifier = notified
+
+	alice := s.register(t, "alice")
+	if len(notified.registered) != 1 {
+		t.Fatalf("notified %d registrations, want 1", len(notified.registered))
+	}
+	user := notified.registered[0]
+	if user.ID != alice.User.ID || user.Username != "alice" || user.Email != "alice@example.com" {
+		t.Fatalf("notified user = %s %s %s, want alice", user.ID, user.Username, user.Email)
+	}
+	if user.Password != "" {
+		t.Fatal("notified user carries the password hash")
+	}
+	if user.RoleCode == nil || *user.RoleCode != s.authConfig.DefaultRoleCode {
+		t.Fatalf("notified role = %v, want %s", user.RoleCode, s.authConfig.DefaultRoleCode)
+	}
+
+	// A registration that fails is not announced
+	_, err := s.Register(context.Background(), &domain.RegisterRequest{
+		Username: "alice", Email: "alice2@example.com", Password: testPassword, FullName: "Alice",
+	})
+	assertCode(t, err, domain.CodeUserAlreadyExists)
+	if len(notified.registered) != 1 {
+		t.Fatalf("notified %d registrations after a failed one, want 1", len(notified.registered))
+	}
+}
+
+func TestRegisterNotifierFailure(t *testing.T) {
+	s := newTestService(t, nil)
+	core, logs := observer.New(zapcore.WarnLevel)
+	s.logger = zap.New(core)
+	s.notifier = &fakeNotifier{err: errors.New("webhook unreachable")}
+
+	// The account exists whether or not the announcement went out
+	alice := s.register(t, "alice")
+	if _, err := s.login("alice"); err != nil {
+		t.Fatalf("login after an undelivered announcement: %v", err)
+	}
+	entries := logs.FilterMessage("Failed to announce registration").All()
+	if len(entries) != 1 || entries[0].ContextMap()["user_id"] != alice.User.ID.String() {
+		t.Fatalf("logged %v, want one warning naming alice", entries)
+	}
+}
+
+func TestRegisterNotifiesDetachedFromClient(t *testing.T) {
+	s := newTestService(t, nil)
+	notified := &fakeNotifier{}
+	s.notifier = notified
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	_, err := s.Register(ctx, &domain.RegisterRequest{
+		Username: "alice", Email: "alice@example.com", Password: testPassword, FullName: "Alice",
+	})
+	if err != nil {
+		t.Fatalf("register: %v", err)
+	}
+	cancel()
+
+	// A client hanging up does not cut the delivery short
+	if len(notified.contexts) != 1 {
+		t.Fatalf("notified %d registrations, want 1", len(notified.contexts))
+	}
+	if err := notified.contexts[0].Err(); err != nil {
+		t.Fatalf("notifier context ended with the client's: %v", err)
+	}
+	if _, ok := notified.contexts[0].Deadline(); ok {
+		t.Fatal("notifier context carries the client's deadline")
+	}
+}
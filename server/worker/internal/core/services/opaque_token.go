@@ -0,0 +1,106 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// opaqueTokenBytes is the entropy of an opaque access token
+const opaqueTokenBytes = 32
+
+// opaqueTokenLength is the length of an encoded opaque access token
+var opaqueTokenLength = base64.RawURLEncoding.EncodedLen(opaqueTokenBytes)
+
+// issueOpaqueToken stores claims server-side and returns a random reference token for them
+func (s *AuthService) issueOpaqueToken(ctx context.Context, userID uuid.UUID, claims *AccessTokenClaims) (string, error) {
+	raw := make([]byte, opaqueTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	encoded, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+	err = s.accessTokens.Create(ctx, sqlc.CreateAccessTokenParams{
+		TokenHash: hashOpaqueToken(token),
+		UserID:    userID,
+		Claims:    encoded,
+		ExpiresAt: pgtype.Timestamp{Time: claims.ExpiresAt.Time, Valid: true},
+	})
+	if err != nil {
+		return "", err
+	}
+	return token, nil
+}
+
+// lookupOpaqueToken returns the claims stored for an opaque access token
+func (s *AuthService) lookupOpaqueToken(ctx context.Context, tokenString string) (*AccessTokenClaims, error) {
+	invalid := domain.NewAuthError(
+		domain.ErrInvalidToken,
+		"invalid access token",
+		domain.CodeInvalidToken,
+	)
+	// JWTs and other malformed values never reach the database
+	if len(tokenString) != opaqueTokenLength {
+		return nil, invalid
+	}
+
+	stored, err := s.accessTokens.FindByHash(ctx, hashOpaqueToken(tokenString))
+	if err != nil {
+		if errors.Is(err, domain.ErrTokenNotFound) {
+			return nil, invalid
+		}
+		return nil, databaseError(err, "failed to look up access token")
+	}
+
+	claims := &AccessTokenClaims{}
+	if err := json.Unmarshal(stored.Claims, claims); err != nil || claims.ExpiresAt == nil {
+		return nil, domain.NewAuthError(
+			domain.ErrInvalidToken,
+			"invalid token claims",
+			domain.CodeInvalidToken,
+		)
+	}
+	if !s.clock.Now().Before(claims.ExpiresAt.Time) {
+		return nil, domain.NewAuthError(
+			domain.ErrTokenExpired,
+			"access token has expired",
+			domain.CodeTokenExpired,
+		)
+	}
+	return claims, nil
+}
+
+// tokenVersionBumped caches the user's new token version and, with opaque tokens, deletes the stored ones
+// so the revocation reaches every replica at once rather than when their version caches expire
+func (s *AuthService) tokenVersionBumped(ctx context.Context, userID uuid.UUID, version int32) error {
+	s.tokenVersions.Set(ctx, userID, version)
+	if s.authConfig.TokenType != config.TokenTypeOpaque {
+		return nil
+	}
+	if err := s.accessTokens.DeleteAllForUser(ctx, userID); err != nil {
+		return databaseError(err, "failed to revoke access tokens")
+	}
+	return nil
+}
+
+// hashOpaqueToken returns the key an opaque access token is stored under
+// Tokens are random, so an unsalted hash is enough to keep a database dump from yielding usable tokens
+func hashOpaqueToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// unavailableAccessTokens fails every lookup, like an opaque token store whose database is down
+type unavailableAccessTokens struct {
+	ports.AccessTokenRepository
+}
+
+func (unavailableAccessTokens) FindByHash(ctx context.Context, tokenHash string) (*sqlc.AccessToken, error) {
+	return nil, fmt.Errorf("find access token: %w", domain.ErrDatabaseUnavailable)
+}
+
+func newTokenTypeTestService(t *testing.T, tokenType string) *testService {
+	return newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.TokenType = tokenType
+	})
+}
+
+func TestAccessTokenTypes(t *testing.T) {
+	for _, tokenType := range []string{config.TokenTypeJWT, config.TokenTypeOpaque} {
+		t.Run(tokenType, func(t *testing.T) {
+			s := newTokenTypeTestService(t, tokenType)
+			ctx := context.Background()
+			resp := s.register(t, "alice")
+
+			isJWT := strings.Count(resp.AccessToken, ".") == 2
+			if isJWT != (tokenType == config.TokenTypeJWT) {
+				t.Fatalf("access token %q is not a %s token", resp.AccessToken, tokenType)
+			}
+
+			result, err := s.ValidateAccessToken(ctx, resp.AccessToken)
+			if err != nil {
+				t.Fatalf("validate: %v", err)
+			}
+			if result.UserID != resp.User.ID.String() || result.Email != resp.User.Email {
+				t.Fatalf("validate = %+v, want alice's claims", result)
+			}
+
+			introspection, err := s.IntrospectToken(ctx, resp.AccessToken, domain.TokenTypeAccess)
+			if err != nil || !introspection.Active || introspection.Username != "alice" {
+				t.Fatalf("introspect = %+v, %v; want alice active", introspection, err)
+			}
+
+			// A refresh token is not an access token in either mode
+			_, err = s.ValidateAccessToken(ctx, resp.RefreshToken)
+			assertCode(t, err, domain.CodeWrongTokenType)
+		})
+	}
+}
+
+func TestAccessTokenTypesRevocation(t *testing.T) {
+	for _, tokenType := range []string{config.TokenTypeJWT, config.TokenTypeOpaque} {
+		t.Run(tokenType, func(t *testing.T) {
+			s := newTokenTypeTestService(t, tokenType)
+			ctx := context.Background()
+			resp := s.register(t, "alice")
+
+			if err := s.revokeAccessTokens(ctx, resp.User.ID); err != nil {
+				t.Fatalf("revoke access tokens: %v", err)
+			}
+			if _, err := s.ValidateAccessToken(ctx, resp.AccessToken); err == nil {
+				t.Fatal("revoked access token still validates")
+			}
+
+			// Tokens issued after the revocation are unaffected
+			fresh := s.mustLogin(t, "alice")
+			if _, err := s.ValidateAccessToken(ctx, fresh.AccessToken); err != nil {
+				t.Fatalf("validate token issued after the revocation: %v", err)
+			}
+		})
+	}
+}
+
+func TestOpaqueTokenRevocationDeletesStoredTokens(t *testing.T) {
+	s := newTokenTypeTestService(t, config.TokenTypeOpaque)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	if err := s.revokeAccessTokens(ctx, resp.User.ID); err != nil {
+		t.Fatalf("revoke access tokens: %v", err)
+	}
+	// Other replicas see the revocation at once, without waiting on their token version caches
+	if _, err := s.accessTokens.FindByHash(ctx, hashOpaqueToken(resp.AccessToken)); err == nil {
+		t.Fatal("revoked opaque token is still stored")
+	}
+}
+
+func TestOpaqueTokenExpiry(t *testing.T) {
+	s := newTokenTypeTestService(t, config.TokenTypeOpaque)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	s.clock.Advance(s.config.AccessExpiration)
+	_, err := s.ValidateAccessToken(ctx, resp.AccessToken)
+	assertCode(t, err, domain.CodeTokenExpired)
+}
+
+func TestIntrospectOpaqueTokenReportsDatabaseFailure(t *testing.T) {
+	s := newTokenTypeTestService(t, config.TokenTypeOpaque)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	s.accessTokens = unavailableAccessTokens{AccessTokenRepository: s.accessTokens}
+
+	// An outage must not make a valid session look logged out
+	_, err := s.IntrospectToken(ctx, resp.AccessToken, domain.TokenTypeAccess)
+	assertCode(t, err, domain.CodeDatabaseUnavailable)
+
+	// Values that never reach the store are still reported inactive
+	introspection, err := s.IntrospectToken(ctx, "garbage", domain.TokenTypeAccess)
+	if err != nil || introspection.Active {
+		t.Fatalf("introspect garbage = %+v, %v; want inactive", introspection, err)
+	}
+}
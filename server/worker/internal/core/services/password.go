@@ -0,0 +1,323 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/logger"
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/common/utils"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// minPasswordLength is the minimum length accepted for new passwords
+const minPasswordLength = 8
+
+// passwordChangeAudience marks tokens that only allow changing an expired password
+const passwordChangeAudience = "password-change"
+
+// passwordResetAudience marks tokens that allow setting a new password without the current one
+const passwordResetAudience = "password-reset"
+
+// ChangePassword verifies the current password, stores the new one and revokes all sessions
+// token is an access token, a password change token issued by Login for an expired password,
+// or a password reset token issued by ForcePasswordReset; the latter skips the current password
+// A reset code (AUTH_RESET_TOKEN_TYPE=otp) is accepted in place of the token when identifier names its account
+func (s *AuthService) ChangePassword(ctx context.Context, identifier, token, currentPassword, newPassword string) error {
+	var (
+		userID uuid.UUID
+		reset  bool
+		err    error
+	)
+	if identifier != "" {
+		userID, err = s.resetCodeSubject(ctx, identifier, token)
+		reset = true
+	} else {
+		userID, reset, err = s.passwordChangeSubject(ctx, token)
+	}
+	if err != nil {
+		return err
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return mapUserLookupError(err)
+	}
+	if !utils.PtrBoolValue(user.IsActive) {
+		return domain.NewAuthError(
+			domain.ErrUserInactive,
+			"user account is deactivated",
+			domain.CodeUserInactive,
+		)
+	}
+
+	if reset {
+		// The flag is lifted by the password update, so a reset token works once
+		if !user.MustResetPassword {
+			return domain.NewAuthError(
+				domain.ErrInvalidToken,
+				"password reset token has already been used",
+				domain.CodeInvalidToken,
+			)
+		}
+		currentPassword = ""
+	} else {
+		// The current password is compromised, only the reset token may replace it
+		if user.MustResetPassword {
+			return domain.NewAuthError(
+				domain.ErrPasswordResetRequired,
+				"password must be reset with the reset token provided by an administrator",
+				domain.CodePasswordResetRequired,
+			)
+		}
+		if err := s.hasher.Compare(user.Password, currentPassword); err != nil {
+			return domain.NewAuthError(
+				domain.ErrIncorrectPassword,
+				"incorrect password",
+				domain.CodeIncorrectPassword,
+			)
+		}
+	}
+
+	if len(newPassword) < minPasswordLength {
+		return domain.NewAuthError(
+			domain.ErrWeakPassword,
+			fmt.Sprintf("password must be at least %d characters", minPasswordLength),
+			domain.CodeWeakPassword,
+		).WithField("new_password")
+	}
+	if err := s.checkPasswordLength("new_password", newPassword); err != nil {
+		return err
+	}
+	if newPassword == currentPassword ||
+		(reset && s.hasher.Compare(user.Password, newPassword) == nil) {
+		return domain.NewAuthError(
+			domain.ErrPasswordReused,
+			"new password must differ from the current one",
+			domain.CodePasswordReused,
+		).WithField("new_password")
+	}
+
+	hashedPassword, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return domain.NewAuthError(
+			domain.ErrHashingPassword,
+			"failed to secure password",
+			domain.CodeInternalError,
+		)
+	}
+
+	if err := s.userRepo.UpdatePassword(ctx, userID, hashedPassword); err != nil {
+		return databaseError(err, "failed to update password")
+	}
+
+	// Existing tokens were issued under the old password
+	if err := s.revokeAccessTokens(ctx, userID); err != nil {
+		return err
+	}
+	if err := s.sessionRepo.RevokeAllForUser(ctx, userID); err != nil {
+		return databaseError(err, "failed to revoke sessions")
+	}
+	s.revocations.Publish(domain.RevocationEvent{
+		UserID:    userID.String(),
+		Reason:    domain.RevocationReasonPasswordChanged,
+		RevokedAt: s.clock.Now(),
+	})
+
+	// A used code no longer works once the flag is lifted, deleting it only keeps the table small
+	if reset && identifier != "" {
+		if err := s.resetCodes.Delete(ctx, userID); err != nil {
+			logger.FromContext(ctx, s.logger).Warn("Failed to delete used password reset code",
+				zap.String("user_id", userID.String()),
+				zap.Error(err),
+			)
+		}
+	}
+	return nil
+}
+
+// passwordChangeSubject returns the user ID of an access token, password change token or password reset token
+// reset reports a password reset token
+func (s *AuthService) passwordChangeSubject(ctx context.Context, tokenString string) (userID uuid.UUID, reset bool, err error) {
+	var subject string
+	claims, accessErr := s.parseAccessToken(ctx, tokenString)
+	if accessErr == nil {
+		subject = claims.Subject
+	} else {
+		// Each kind is signed with its own derived key, so at most one parser accepts the signature
+		scoped := &jwt.RegisteredClaims{}
+		_, changeErr := s.passwordChangeParser.ParseWithClaims(tokenString, scoped, s.passwordChangeKeyFunc)
+		var resetErr error
+		if changeErr != nil {
+			scoped = &jwt.RegisteredClaims{}
+			_, resetErr = s.passwordResetParser.ParseWithClaims(tokenString, scoped, s.passwordResetKeyFunc)
+			reset = resetErr == nil
+		}
+		if changeErr != nil && resetErr != nil {
+			if errors.Is(changeErr, jwt.ErrTokenExpired) || errors.Is(resetErr, jwt.ErrTokenExpired) {
+				return uuid.Nil, false, domain.NewAuthError(
+					domain.ErrTokenExpired,
+					"password change token has expired",
+					domain.CodeTokenExpired,
+				)
+			}
+			// Report the access token error, the common case
+			return uuid.Nil, false, accessErr
+		}
+		subject = scoped.Subject
+	}
+
+	userID, err = s.subjectUserID(subject)
+	if err != nil {
+		return uuid.Nil, false, domain.NewAuthError(
+			domain.ErrInvalidToken,
+			"invalid token subject",
+			domain.CodeInvalidToken,
+		)
+	}
+	return userID, reset, nil
+}
+
+// ForcePasswordReset locks a compromised account until its password is reset, requires domain.PermissionUsersUpdate
+// Login is refused, sessions and access tokens are revoked, and the returned reset token is
+// the only way to set a new password; the caller hands it to the user out-of-band
+// With AUTH_RESET_TOKEN_TYPE=otp the token is a numeric code replacing any code issued before
+func (s *AuthService) ForcePasswordReset(ctx context.Context, callerID, targetID uuid.UUID) (*domain.PasswordResetToken, error) {
+	if err := s.requirePermission(ctx, callerID, domain.PermissionUsersUpdate); err != nil {
+		return nil, err
+	}
+
+	// Users of other tenants are reported as missing
+	caller, err := s.userRepo.FindByID(ctx, callerID)
+	if err != nil {
+		return nil, mapUserLookupError(err)
+	}
+	target, err := s.userRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return nil, mapUserLookupError(err)
+	}
+	if target.TenantID != caller.TenantID {
+		return nil, mapUserLookupError(domain.ErrUserNotFound)
+	}
+
+	version, err := s.userRepo.RequirePasswordReset(ctx, targetID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return nil, mapUserLookupError(err)
+		}
+		return nil, databaseError(err, "failed to require a password reset")
+	}
+	if err := s.tokenVersionBumped(ctx, targetID, version); err != nil {
+		return nil, err
+	}
+
+	if err := s.sessionRepo.RevokeAllForUser(ctx, targetID); err != nil {
+		return nil, databaseError(err, "failed to revoke sessions")
+	}
+	now := s.clock.Now()
+	s.revocations.Publish(domain.RevocationEvent{
+		UserID:    targetID.String(),
+		Reason:    domain.RevocationReasonPasswordReset,
+		RevokedAt: now,
+	})
+	s.auditLog.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditPasswordResetForced,
+		UserID:   targetID.String(),
+		TenantID: target.TenantID,
+		Client:   domain.ClientInfoFromContext(ctx),
+		ActorID:  callerID.String(),
+		At:       now,
+	})
+
+	if s.authConfig.ResetTokenType == config.ResetTokenTypeOTP {
+		return s.issueResetCode(ctx, targetID, now)
+	}
+	reset, err := s.generatePasswordResetToken(targetID.String(), now)
+	if err != nil {
+		return nil, domain.NewAuthError(
+			domain.ErrGeneratingToken,
+			"failed to generate password reset token",
+			domain.CodeInternalError,
+		)
+	}
+	return reset, nil
+}
+
+// isPasswordExpired reports whether a password set at changedAt exceeds AUTH_PASSWORD_MAX_AGE
+func (s *AuthService) isPasswordExpired(changedAt pgtype.Timestamp) bool {
+	if s.authConfig.PasswordMaxAge <= 0 || !changedAt.Valid {
+		return false
+	}
+	return s.clock.Now().Sub(changedAt.Time) > s.authConfig.PasswordMaxAge
+}
+
+// generatePasswordChangeToken creates a short-lived token that only allows ChangePassword
+func (s *AuthService) generatePasswordChangeToken(userID string) (string, error) {
+	now := s.clock.Now()
+	claims := &jwt.RegisteredClaims{
+		Subject:   s.tokenSubject(userID),
+		Audience:  jwt.ClaimStrings{passwordChangeAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(s.reloader.Current().PasswordChangeTokenExpiration)),
+		Issuer:    tokenIssuer,
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	return token.SignedString(s.passwordChangeKey)
+}
+
+// generatePasswordResetToken creates a token that lets ChangePassword set a new password without the current one
+func (s *AuthService) generatePasswordResetToken(userID string, now time.Time) (*domain.PasswordResetToken, error) {
+	expiresAt := now.Add(s.authConfig.PasswordResetTokenExpiration)
+	claims := &jwt.RegisteredClaims{
+		Subject:   s.tokenSubject(userID),
+		Audience:  jwt.ClaimStrings{passwordResetAudience},
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(expiresAt),
+		Issuer:    tokenIssuer,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(s.passwordResetKey)
+	if err != nil {
+		return nil, err
+	}
+	return &domain.PasswordResetToken{Token: token, ExpiresAt: expiresAt}, nil
+}
+
+// deriveKey derives a purpose-specific HMAC key from a secret
+func deriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// upgradePasswordHash rehashes a verified password whose hash is of another algorithm or older parameters
+// than AUTH_PASSWORD_HASHER uses, so users migrate as they log in. Failures are logged: the login stands
+// and the next one tries again; a password changed meanwhile is left as it is
+func (s *AuthService) upgradePasswordHash(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow, password string) {
+	if !s.hasher.NeedsRehash(user.Password, password) {
+		return
+	}
+
+	log := logger.FromContext(ctx, s.logger).With(zap.String("user_id", user.ID.String()))
+	newHash, err := s.hasher.Hash(password)
+	if err != nil {
+		log.Warn("Failed to rehash password", zap.Error(err))
+		return
+	}
+	if _, err := s.userRepo.RehashPassword(ctx, user.ID, user.Password, newHash); err != nil {
+		log.Warn("Failed to store rehashed password", zap.Error(err))
+		return
+	}
+	user.Password = newHash
+}
@@ -0,0 +1,170 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"golang.org/x/crypto/bcrypt"
+
+	"worker/internal/adapter/hasher"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// longTestPassword is over bcrypt's 72-byte limit; longTestPasswordTwin shares its first 72 bytes
+var (
+	longTestPassword     = strings.Repeat("Correct-Horse-9-", 5) + "one"
+	longTestPasswordTwin = strings.Repeat("Correct-Horse-9-", 5) + "two"
+)
+
+func registerWithPassword(s *testService, username, password string) error {
+	_, err := s.Register(context.Background(), &domain.RegisterRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: password,
+		FullName: "Test " + username,
+	})
+	return err
+}
+
+func TestLongPasswordsWithPrehash(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.BcryptPrehash = true
+	})
+	ctx := context.Background()
+
+	if err := registerWithPassword(s, "alice", longTestPassword); err != nil {
+		t.Fatalf("register with a %d-byte password: %v", len(longTestPassword), err)
+	}
+	if _, err := s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: longTestPassword}); err != nil {
+		t.Fatalf("login: %v", err)
+	}
+	_, err := s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: longTestPasswordTwin})
+	assertCode(t, err, domain.CodeIncorrectPassword)
+}
+
+func TestLongPasswordsWithoutPrehash(t *testing.T) {
+	s := newTestService(t, nil)
+
+	err := registerWithPassword(s, "alice", longTestPassword)
+	if !errors.Is(err, domain.ErrFieldTooLong) {
+		t.Fatalf("register with a %d-byte password = %v, want ErrFieldTooLong", len(longTestPassword), err)
+	}
+}
+
+func TestLoginRehashesLegacyLongPassword(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.BcryptPrehash = true
+	})
+	ctx := context.Background()
+	s.register(t, "alice")
+
+	// Store the hash a long password got before AUTH_BCRYPT_PREHASH: bcrypt of its first 72 bytes
+	user, err := s.userRepo.FindByEmailOrUsername(ctx, domain.DefaultTenantID, "alice")
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	legacy, err := bcrypt.GenerateFromPassword([]byte(longTestPassword[:72]), bcrypt.MinCost)
+	if err != nil {
+		t.Fatalf("legacy hash: %v", err)
+	}
+	if _, err := s.userRepo.RehashPassword(ctx, user.ID, user.Password, string(legacy)); err != nil {
+		t.Fatalf("store legacy hash: %v", err)
+	}
+
+	// The legacy hash only checks the first 72 bytes, until the login rehashes it
+	if _, err := s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: longTestPassword}); err != nil {
+		t.Fatalf("login with the legacy hash: %v", err)
+	}
+	user, err = s.userRepo.FindByEmailOrUsername(ctx, domain.DefaultTenantID, "alice")
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if user.Password == string(legacy) {
+		t.Fatal("login did not rehash the legacy hash")
+	}
+	_, err = s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: longTestPasswordTwin})
+	assertCode(t, err, domain.CodeIncorrectPassword)
+}
+
+func TestLoginUpgradesHashAlgorithm(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.register(t, "alice")
+	before, err := s.userRepo.FindByEmailOrUsername(ctx, domain.DefaultTenantID, "alice")
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if !strings.HasPrefix(before.Password, "$2") {
+		t.Fatalf("hash = %q, want bcrypt", before.Password)
+	}
+
+	// Switching AUTH_PASSWORD_HASHER keeps bcrypt users signing in, and moves them over as they do
+	s.authConfig.PasswordHasher = config.PasswordHasherArgon2id
+	s.authConfig.Argon2Memory, s.authConfig.Argon2Iterations, s.authConfig.Argon2Parallelism = 1024, 1, 1
+	s.hasher = hasher.NewMultiHasher(s.authConfig)
+	s.clock.Advance(time.Hour)
+	s.mustLogin(t, "alice")
+
+	after, err := s.userRepo.FindByEmailOrUsername(ctx, domain.DefaultTenantID, "alice")
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if !strings.HasPrefix(after.Password, "$argon2id$") {
+		t.Fatalf("hash after login = %q, want argon2id", after.Password)
+	}
+	if after.PasswordChangedAt != before.PasswordChangedAt {
+		t.Fatalf("password changed at %v after the rehash, want %v kept", after.PasswordChangedAt, before.PasswordChangedAt)
+	}
+	_, err = s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: "Wrong-Horse-9-Battery"})
+	assertCode(t, err, domain.CodeIncorrectPassword)
+
+	// Switching back verifies the argon2id hash as well
+	s.authConfig.PasswordHasher = config.PasswordHasherBcrypt
+	s.hasher = hasher.NewMultiHasher(s.authConfig)
+	s.mustLogin(t, "alice")
+}
+
+// rehashFailingUsers fails to store rehashed passwords
+type rehashFailingUsers struct {
+	ports.UserRepository
+}
+
+func (rehashFailingUsers) RehashPassword(ctx context.Context, userID uuid.UUID, oldHash, newHash string) (bool, error) {
+	return false, domain.ErrDatabaseOperation
+}
+
+func TestLoginSurvivesFailedRehash(t *testing.T) {
+	s := newTestService(t, nil)
+	core, logs := observer.New(zapcore.WarnLevel)
+	s.logger = zap.New(core)
+	s.register(t, "alice")
+	s.authConfig.PasswordHasher = config.PasswordHasherArgon2id
+	s.authConfig.Argon2Memory, s.authConfig.Argon2Iterations, s.authConfig.Argon2Parallelism = 1024, 1, 1
+	s.hasher = hasher.NewMultiHasher(s.authConfig)
+	users := s.userRepo
+	s.userRepo = rehashFailingUsers{UserRepository: users}
+
+	// The login stands, and the next one tries again
+	s.mustLogin(t, "alice")
+	if logs.FilterMessage("Failed to store rehashed password").Len() != 1 {
+		t.Fatalf("logged %v, want the failed rehash", logs.All())
+	}
+	s.userRepo = users
+	s.mustLogin(t, "alice")
+	user, err := s.userRepo.FindByEmailOrUsername(context.Background(), domain.DefaultTenantID, "alice")
+	if err != nil {
+		t.Fatalf("find user: %v", err)
+	}
+	if !strings.HasPrefix(user.Password, "$argon2id$") {
+		t.Fatalf("hash after the retried login = %q, want argon2id", user.Password)
+	}
+}
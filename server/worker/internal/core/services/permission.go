@@ -0,0 +1,193 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/domain"
+)
+
+// CheckPermission decides whether the access token's user holds the required permission
+// The required permission uses the "resource:ACTION" format; granted permissions may use
+// "*" for the resource, the action or both, matching the gateway's PermissionGuard
+func (s *AuthService) CheckPermission(ctx context.Context, accessToken, permission string) (*domain.PermissionDecision, error) {
+	if err := validateRequiredPermission(permission); err != nil {
+		return nil, err
+	}
+
+	claims, err := s.parseAccessToken(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+	_, decision, err := s.decidePermission(ctx, claims, permission)
+	return decision, err
+}
+
+// authorize validates the access token and requires the caller to hold permission
+// Returns the caller's user ID
+func (s *AuthService) authorize(ctx context.Context, accessToken, permission string) (uuid.UUID, error) {
+	if err := validateRequiredPermission(permission); err != nil {
+		return uuid.Nil, err
+	}
+
+	claims, err := s.parseAccessToken(ctx, accessToken)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	userID, decision, err := s.decidePermission(ctx, claims, permission)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if !decision.Allowed {
+		return uuid.Nil, domain.NewAuthError(
+			domain.ErrPermissionDenied,
+			fmt.Sprintf("missing permission %s", permission),
+			domain.CodePermissionDenied,
+		)
+	}
+	return userID, nil
+}
+
+// validateRequiredPermission rejects required permissions that are not "resource:ACTION"; wildcards
+// are only meaningful in grants, so a request for one is malformed rather than a superadmin probe
+func validateRequiredPermission(permission string) error {
+	resource, action, ok := strings.Cut(permission, ":")
+	if !ok || resource == "" || action == "" || strings.Contains(permission, "*") {
+		return domain.NewFieldError(
+			domain.ErrInvalidPermission,
+			"permission",
+			"permission must have the form resource:ACTION without wildcards",
+		)
+	}
+	return nil
+}
+
+// decidePermission decides whether the user of already parsed access token claims holds a valid
+// required permission; it returns the user's ID along with the decision
+func (s *AuthService) decidePermission(ctx context.Context, claims *AccessTokenClaims, permission string) (uuid.UUID, *domain.PermissionDecision, error) {
+	userID, permissions, err := s.claimsPermissions(ctx, claims)
+	if err != nil {
+		return uuid.Nil, nil, err
+	}
+	if decision := s.superadminDecision(claims.Roles, permissions); decision != nil {
+		return userID, decision, nil
+	}
+
+	resource, action, _ := strings.Cut(permission, ":")
+	for _, granted := range permissions {
+		if matchPermission(granted, resource, action) {
+			return userID, &domain.PermissionDecision{
+				Allowed:           true,
+				Reason:            fmt.Sprintf("granted by %s", granted),
+				MatchedPermission: granted,
+			}, nil
+		}
+	}
+
+	return userID, &domain.PermissionDecision{
+		Allowed: false,
+		Reason:  fmt.Sprintf("no role grants %s", permission),
+	}, nil
+}
+
+// requirePermission fails unless the user holds permission
+// Unlike authorize it starts from an already authenticated user ID
+func (s *AuthService) requirePermission(ctx context.Context, userID uuid.UUID, permission string) error {
+	resource, action, _ := strings.Cut(permission, ":")
+	permissions, err := s.resolvePermissions(ctx, userID)
+	if err != nil {
+		return databaseError(err, "failed to resolve permissions")
+	}
+	var roles []string
+	if len(s.authConfig.SuperadminRoles) > 0 {
+		assigned, err := s.roleRepo.FindByUserID(ctx, userID)
+		if err != nil {
+			return databaseError(err, "failed to resolve roles")
+		}
+		for _, role := range assigned {
+			roles = append(roles, role.Code)
+		}
+	}
+	if s.superadminDecision(roles, permissions) != nil {
+		return nil
+	}
+	for _, granted := range permissions {
+		if matchPermission(granted, resource, action) {
+			return nil
+		}
+	}
+	return domain.NewAuthError(
+		domain.ErrPermissionDenied,
+		fmt.Sprintf("missing permission %s", permission),
+		domain.CodePermissionDenied,
+	)
+}
+
+// superadminDecision grants everything to a user holding an AUTH_SUPERADMIN_PERMISSIONS permission
+// or an AUTH_SUPERADMIN_ROLES role; it returns nil for anyone else, whose grants are then matched one by one
+func (s *AuthService) superadminDecision(roles, permissions []string) *domain.PermissionDecision {
+	for _, granted := range permissions {
+		if slices.Contains(s.authConfig.SuperadminPermissions, granted) {
+			return &domain.PermissionDecision{
+				Allowed:           true,
+				Reason:            fmt.Sprintf("superadmin by %s", granted),
+				MatchedPermission: granted,
+			}
+		}
+	}
+	for _, role := range roles {
+		if slices.Contains(s.authConfig.SuperadminRoles, strings.ToUpper(role)) {
+			return &domain.PermissionDecision{
+				Allowed: true,
+				Reason:  fmt.Sprintf("superadmin by role %s", role),
+			}
+		}
+	}
+	return nil
+}
+
+// matchPermission reports whether a granted permission covers resource:action
+func matchPermission(granted, resource, action string) bool {
+	grantedResource, grantedAction, ok := strings.Cut(granted, ":")
+	if !ok {
+		return false
+	}
+	return (grantedResource == "*" || grantedResource == resource) &&
+		(grantedAction == "*" || grantedAction == action)
+}
+
+// ListMyPermissions returns one page of the caller's permissions in sorted order
+// The cursor is the last permission of the previous page, so pages stay consistent when
+// grants change in between; a page is never cut short without a next cursor saying so
+func (s *AuthService) ListMyPermissions(ctx context.Context, accessToken, cursor string, pageSize int32) (*domain.PermissionPage, error) {
+	permissions, err := s.GetMyPermissions(ctx, accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := s.authConfig.PermissionsPageMax
+	if pageSize > 0 {
+		limit = min(int(pageSize), limit)
+	}
+	sorted := slices.Sorted(slices.Values(permissions))
+	start := 0
+	if cursor != "" {
+		start, _ = slices.BinarySearch(sorted, cursor)
+		if start < len(sorted) && sorted[start] == cursor {
+			start++
+		}
+	}
+
+	page := &domain.PermissionPage{
+		Permissions: sorted[start:min(start+limit, len(sorted))],
+		Total:       len(sorted),
+	}
+	if start+limit < len(sorted) {
+		page.NextCursor = page.Permissions[len(page.Permissions)-1]
+	}
+	return page, nil
+}
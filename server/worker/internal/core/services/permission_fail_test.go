@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// unreachablePermissions is a role repository whose permission lookups fail like a dropped connection
+type unreachablePermissions struct {
+	ports.RoleRepository
+}
+
+func (unreachablePermissions) GetPermissionsByUserID(ctx context.Context, userID uuid.UUID) ([]string, error) {
+	return nil, errConnectionReset
+}
+
+func (unreachablePermissions) GetPermissionsByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	return nil, errConnectionReset
+}
+
+// unreachableUsers is a user repository whose user lookups by ID fail like a dropped connection
+type unreachableUsers struct {
+	ports.UserRepository
+}
+
+func (unreachableUsers) FindByID(ctx context.Context, id uuid.UUID) (*sqlc.GetUserByIDRow, error) {
+	return nil, errConnectionReset
+}
+
+// newFailModeService returns a service with AUTH_PERMISSIONS_FAIL_MODE set to mode whose error logs are observed
+func newFailModeService(t *testing.T, mode string) (*testService, *observer.ObservedLogs) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.PermissionsFailMode = mode
+	})
+	core, logs := observer.New(zapcore.ErrorLevel)
+	s.logger = zap.New(core)
+	return s, logs
+}
+
+// assertLookupFailure checks the outcome of a validation whose lookup failed under AUTH_PERMISSIONS_FAIL_MODE=mode
+func assertLookupFailure(t *testing.T, mode string, result *domain.ValidateTokenResult, err error, logs *observer.ObservedLogs) {
+	t.Helper()
+	if mode == config.PermissionsFailClosed {
+		assertCode(t, err, domain.CodeInternalError)
+		if !errors.Is(err, domain.ErrDatabaseOperation) {
+			t.Fatalf("validate = %v, want a database error", err)
+		}
+	} else {
+		if err != nil {
+			t.Fatalf("validate: %v", err)
+		}
+		if !result.Valid || result.Permissions == nil || len(result.Permissions) != 0 {
+			t.Fatalf("result = %+v, want a valid token with no permissions", result)
+		}
+	}
+
+	// Either way, the failure is logged
+	entries := logs.FilterMessage("Failed to resolve permissions of a valid access token").All()
+	if len(entries) != 1 {
+		t.Fatalf("logged %d permission lookup failures, want 1", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	if fields["fail_closed"] != (mode == config.PermissionsFailClosed) || fields["error"] != errConnectionReset.Error() {
+		t.Fatalf("logged fields = %v", fields)
+	}
+}
+
+func TestValidateAccessTokenLookupFailure(t *testing.T) {
+	lookups := []struct {
+		name string
+		fail func(s *testService)
+	}{
+		{name: "permissions", fail: func(s *testService) { s.roleRepo = unreachablePermissions{RoleRepository: s.roleRepo} }},
+		{name: "user", fail: func(s *testService) { s.userRepo = unreachableUsers{UserRepository: s.userRepo} }},
+	}
+	for _, mode := range []string{config.PermissionsFailOpen, config.PermissionsFailClosed} {
+		for _, lookup := range lookups {
+			t.Run(mode+"/"+lookup.name, func(t *testing.T) {
+				s, logs := newFailModeService(t, mode)
+				alice := s.register(t, "alice")
+				lookup.fail(s)
+
+				result, err := s.ValidateAccessToken(context.Background(), alice.AccessToken)
+				assertLookupFailure(t, mode, result, err, logs)
+			})
+		}
+	}
+}
+
+func TestValidateAccessTokensLookupFailure(t *testing.T) {
+	for _, mode := range []string{config.PermissionsFailOpen, config.PermissionsFailClosed} {
+		t.Run(mode, func(t *testing.T) {
+			s, logs := newFailModeService(t, mode)
+			alice := s.register(t, "alice")
+			s.roleRepo = unreachablePermissions{RoleRepository: s.roleRepo}
+
+			validations, err := s.ValidateAccessTokens(context.Background(), []string{alice.AccessToken})
+			var result *domain.ValidateTokenResult
+			if err == nil {
+				if validations[0].Err != nil {
+					t.Fatalf("validate: %v", validations[0].Err)
+				}
+				result = validations[0].Result
+			}
+			assertLookupFailure(t, mode, result, err, logs)
+		})
+	}
+}
+
+func TestValidateAccessTokenServesCachedPermissions(t *testing.T) {
+	// Failing closed only applies when the permissions cannot be resolved at all
+	s, logs := newFailModeService(t, config.PermissionsFailClosed)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+	if _, err := s.ValidateAccessToken(ctx, adminToken); err != nil {
+		t.Fatalf("warm up: %v", err)
+	}
+	s.roleRepo = unreachablePermissions{RoleRepository: s.roleRepo}
+
+	result, err := s.ValidateAccessToken(ctx, adminToken)
+	if err != nil {
+		t.Fatalf("validate with cached permissions: %v", err)
+	}
+	if result.UserID != adminID.String() || len(result.Permissions) == 0 {
+		t.Fatalf("result = %+v, want the admin's cached permissions", result)
+	}
+	if logs.Len() != 0 {
+		t.Fatalf("logged %v for a cache hit", logs.All())
+	}
+}
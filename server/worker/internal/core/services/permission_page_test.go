@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"worker/internal/config"
+)
+
+// newPageService returns a service whose permission pages hold at most pageMax entries, with the admin
+// logged in, along with the admin's access token and sorted permissions
+func newPageService(t *testing.T, pageMax int) (*testService, string, []string) {
+	t.Helper()
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.PermissionsPageMax = pageMax
+	})
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+	permissions, err := s.GetMyPermissions(context.Background(), adminToken)
+	if err != nil {
+		t.Fatalf("admin permissions: %v", err)
+	}
+	return s, adminToken, slices.Sorted(slices.Values(permissions))
+}
+
+// allPages follows the cursors from the first page, failing if a page comes back empty with a cursor
+func (s *testService) allPages(t *testing.T, accessToken string, pageSize int32) ([]string, int) {
+	t.Helper()
+	var permissions []string
+	pages, cursor := 0, ""
+	for {
+		page, err := s.ListMyPermissions(context.Background(), accessToken, cursor, pageSize)
+		if err != nil {
+			t.Fatalf("page %d: %v", pages, err)
+		}
+		pages++
+		permissions = append(permissions, page.Permissions...)
+		if page.NextCursor == "" {
+			return permissions, pages
+		}
+		if len(page.Permissions) == 0 {
+			t.Fatalf("page %d is empty with cursor %q", pages, page.NextCursor)
+		}
+		cursor = page.NextCursor
+	}
+}
+
+func TestListMyPermissionsAtCap(t *testing.T) {
+	_, _, want := newPageService(t, 500)
+	total := len(want)
+	if total < 3 {
+		t.Fatalf("ADMIN grants %v, want enough permissions to page", want)
+	}
+
+	tests := []struct {
+		name      string
+		pageMax   int
+		pageSize  int32
+		wantPages int
+	}{
+		{"cap equals total", total, 0, 1},
+		{"cap one below total", total - 1, 0, 2},
+		{"cap one above total", total + 1, 0, 1},
+		{"size equals total", 500, int32(total), 1},
+		{"size one below total", 500, int32(total - 1), 2},
+		{"size above cap", total - 1, int32(total), 2},
+		{"size of one", 500, 1, total},
+		{"cap of one", 1, 0, total},
+		{"negative size", total - 1, -1, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, adminToken, _ := newPageService(t, tt.pageMax)
+
+			first, err := s.ListMyPermissions(context.Background(), adminToken, "", tt.pageSize)
+			if err != nil {
+				t.Fatalf("first page: %v", err)
+			}
+			if first.Total != total {
+				t.Fatalf("total = %d, want %d", first.Total, total)
+			}
+			if (first.NextCursor == "") != (tt.wantPages == 1) {
+				t.Fatalf("first page %v has cursor %q, want %d pages", first.Permissions, first.NextCursor, tt.wantPages)
+			}
+
+			got, pages := s.allPages(t, adminToken, tt.pageSize)
+			if pages != tt.wantPages {
+				t.Fatalf("pages = %d, want %d", pages, tt.wantPages)
+			}
+			if !slices.Equal(got, want) {
+				t.Fatalf("paged permissions = %v, want each of %v once in order", got, want)
+			}
+		})
+	}
+}
+
+func TestListMyPermissionsCursor(t *testing.T) {
+	s, adminToken, want := newPageService(t, 500)
+	ctx := context.Background()
+
+	// A cursor resumes after itself, whether or not the user still holds it
+	page, err := s.ListMyPermissions(ctx, adminToken, want[0], 1)
+	if err != nil || !slices.Equal(page.Permissions, want[1:2]) {
+		t.Fatalf("page after %q = %+v, %v; want %v", want[0], page, err, want[1:2])
+	}
+	page, err = s.ListMyPermissions(ctx, adminToken, want[0]+"~", 1)
+	if err != nil || !slices.Equal(page.Permissions, want[1:2]) {
+		t.Fatalf("page after a revoked permission = %+v, %v; want %v", page, err, want[1:2])
+	}
+
+	// The last permission ends the listing with an empty page rather than an error
+	for _, cursor := range []string{want[len(want)-1], "~"} {
+		page, err := s.ListMyPermissions(ctx, adminToken, cursor, 0)
+		if err != nil || len(page.Permissions) != 0 || page.NextCursor != "" || page.Total != len(want) {
+			t.Fatalf("page after %q = %+v, %v; want an empty last page", cursor, page, err)
+		}
+	}
+}
+
+func TestEmbedPermissionsAtCap(t *testing.T) {
+	_, want := newEmbedService(t, true, 50).registerEmbedAdmin(t)
+
+	tests := []struct {
+		name      string
+		max       int
+		wantEmbed bool
+	}{
+		{"cap equals count", len(want), true},
+		{"cap one below count", len(want) - 1, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newEmbedService(t, true, tt.max)
+			core, logs := observer.New(zapcore.WarnLevel)
+			s.logger = zap.New(core)
+			s.registerEmbedAdmin(t)
+			adminToken := s.mustLogin(t, "admin").AccessToken
+
+			embedded := s.tokenClaims(t, adminToken).Permissions
+			warned := logs.FilterMessage("User holds more permissions than AUTH_EMBED_PERMISSIONS_MAX, issuing the token without them").Len() > 0
+			if tt.wantEmbed && (!slices.Equal(embedded, want) || warned) {
+				t.Fatalf("perms claim = %v, warned %t; want %v embedded without a warning", embedded, warned, want)
+			}
+			if !tt.wantEmbed && (embedded != nil || !warned) {
+				t.Fatalf("perms claim = %v, warned %t; want none and the cap warning", embedded, warned)
+			}
+
+			// Either way the token validates to the full set
+			result, err := s.restart(t).ValidateAccessToken(context.Background(), adminToken)
+			if err != nil || !slices.Equal(result.Permissions, want) {
+				t.Fatalf("validate = %+v, %v; want %v", result, err, want)
+			}
+		})
+	}
+}
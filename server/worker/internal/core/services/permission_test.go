@@ -0,0 +1,221 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+func TestMatchPermission(t *testing.T) {
+	tests := []struct {
+		granted  string
+		required string
+		want     bool
+	}{
+		{granted: "users:READ", required: "users:READ", want: true},
+		{granted: "users:*", required: "users:UPDATE", want: true},
+		{granted: "*:READ", required: "roles:READ", want: true},
+		{granted: "*:*", required: "system:READ", want: true},
+		{granted: "users:READ", required: "users:UPDATE", want: false},
+		{granted: "users:READ", required: "roles:READ", want: false},
+		{granted: "users:*", required: "roles:READ", want: false},
+		{granted: "*:READ", required: "users:UPDATE", want: false},
+		// Matching is exact: no prefixes, no case folding, no wildcards inside names
+		{granted: "users:READ", required: "users:read", want: false},
+		{granted: "user:READ", required: "users:READ", want: false},
+		{granted: "users*:READ", required: "users:READ", want: false},
+		{granted: "*", required: "users:READ", want: false},
+		{granted: "", required: "users:READ", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.granted+" "+tt.required, func(t *testing.T) {
+			resource, action, _ := strings.Cut(tt.required, ":")
+			if got := matchPermission(tt.granted, resource, action); got != tt.want {
+				t.Fatalf("matchPermission(%q, %q) = %t, want %t", tt.granted, tt.required, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckPermissionWildcardGrant(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	// The default role holds nothing until it is granted every action on users
+	decision, err := s.CheckPermission(ctx, resp.AccessToken, domain.PermissionUsersUpdate)
+	if err != nil || decision.Allowed {
+		t.Fatalf("check before the grant = %+v, %v; want denied", decision, err)
+	}
+
+	role, err := s.roleRepo.FindByCode(ctx, s.authConfig.DefaultRoleCode)
+	if err != nil {
+		t.Fatalf("find role: %v", err)
+	}
+	resource, err := s.roleRepo.FindResourceByCode(ctx, "users")
+	if err != nil {
+		t.Fatalf("find resource: %v", err)
+	}
+	if err := s.roleRepo.GrantAction(ctx, role.ID, resource.ID, "*"); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	s.permissionCache.InvalidateAll(ctx)
+
+	for permission, allowed := range map[string]bool{
+		domain.PermissionUsersUpdate: true,
+		domain.PermissionUsersRead:   true,
+		domain.PermissionRolesUpdate: false,
+	} {
+		decision, err := s.CheckPermission(ctx, resp.AccessToken, permission)
+		if err != nil {
+			t.Fatalf("check %s: %v", permission, err)
+		}
+		if decision.Allowed != allowed {
+			t.Fatalf("check %s = %+v, want allowed %t", permission, decision, allowed)
+		}
+		if allowed && decision.MatchedPermission != "users:*" {
+			t.Fatalf("check %s matched %q, want users:*", permission, decision.MatchedPermission)
+		}
+	}
+}
+
+func TestCheckPermissionRejectsMalformedPermissions(t *testing.T) {
+	s := newTestService(t, nil)
+	accessToken := s.register(t, "alice").AccessToken
+
+	for _, permission := range []string{"", "users", "users:", ":READ", "users:*", "*:READ"} {
+		_, err := s.CheckPermission(context.Background(), accessToken, permission)
+		assertCode(t, err, domain.CodeInvalidArgument)
+	}
+}
+
+// allPermissions are the permissions the worker's method guards require
+var allPermissions = []string{
+	domain.PermissionSystemRead,
+	domain.PermissionRolesRead,
+	domain.PermissionRolesUpdate,
+	domain.PermissionUsersRead,
+	domain.PermissionUsersUpdate,
+	domain.PermissionUsersDelete,
+}
+
+func TestSuperadminBypass(t *testing.T) {
+	tests := []struct {
+		name      string
+		configure func(*config.Config)
+		// grant makes the lecturer role a superadmin once the service runs
+		grant func(t *testing.T, s *testService, lecturer uuid.UUID)
+	}{
+		{
+			name: "permission",
+			configure: func(cfg *config.Config) {
+				cfg.Auth.SuperadminPermissions = []string{"system:*"}
+			},
+			grant: func(t *testing.T, s *testService, lecturer uuid.UUID) {
+				system, err := s.roleRepo.FindResourceByCode(context.Background(), "system")
+				if err != nil {
+					t.Fatalf("find resource: %v", err)
+				}
+				if err := s.roleRepo.GrantAction(context.Background(), lecturer, system.ID, "*"); err != nil {
+					t.Fatalf("grant: %v", err)
+				}
+			},
+		},
+		{
+			name: "role",
+			configure: func(cfg *config.Config) {
+				cfg.Auth.SuperadminRoles = []string{"LECTURER"}
+			},
+			grant: func(t *testing.T, s *testService, lecturer uuid.UUID) {},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, tt.configure)
+			ctx := context.Background()
+			lecturer, err := s.roleRepo.FindByCode(ctx, "LECTURER")
+			if err != nil {
+				t.Fatalf("find role: %v", err)
+			}
+			tt.grant(t, s, lecturer.ID)
+			s.promote(t, s.register(t, "root").User.ID, "LECTURER")
+			s.register(t, "alice")
+			rootToken := s.mustLogin(t, "root").AccessToken
+			aliceToken := s.mustLogin(t, "alice").AccessToken
+
+			// The lecturer role holds none of these, so only the bypass can allow them
+			for _, permission := range allPermissions {
+				decision, err := s.CheckPermission(ctx, rootToken, permission)
+				if err != nil || !decision.Allowed || !strings.HasPrefix(decision.Reason, "superadmin by") {
+					t.Fatalf("superadmin check %s = %+v, %v; want allowed as superadmin", permission, decision, err)
+				}
+				decision, err = s.CheckPermission(ctx, aliceToken, permission)
+				if err != nil || decision.Allowed {
+					t.Fatalf("normal user check %s = %+v, %v; want denied", permission, decision, err)
+				}
+			}
+
+			// Method guards go through the same decision
+			if _, err := s.ListUsers(ctx, rootToken, &domain.UserListQuery{}); err != nil {
+				t.Fatalf("superadmin list users: %v", err)
+			}
+			_, err = s.ListUsers(ctx, aliceToken, &domain.UserListQuery{})
+			assertCode(t, err, domain.CodePermissionDenied)
+		})
+	}
+}
+
+func TestSuperadminCannotRequestWildcards(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.SuperadminRoles = []string{"ADMIN"}
+	})
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	// A wildcard in the required permission is malformed, whoever asks
+	for _, permission := range []string{"*:*", "users:*", "*:READ"} {
+		_, err := s.CheckPermission(context.Background(), adminToken, permission)
+		assertCode(t, err, domain.CodeInvalidArgument)
+	}
+}
+
+func TestPermissionChecksParseTheTokenOnce(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.TokenType = config.TokenTypeOpaque
+		cfg.Auth.SuperadminRoles = []string{"LECTURER"}
+	})
+	ctx := context.Background()
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+	accessTokens := &countingAccessTokens{AccessTokenRepository: s.accessTokens}
+	s.accessTokens = accessTokens
+
+	checks := map[string]func() error{
+		"CheckPermission": func() error {
+			_, err := s.CheckPermission(ctx, adminToken, domain.PermissionUsersRead)
+			return err
+		},
+		"GetMyPermissions": func() error {
+			_, err := s.GetMyPermissions(ctx, adminToken)
+			return err
+		},
+		"authorize": func() error {
+			_, err := s.authorize(ctx, adminToken, domain.PermissionUsersRead)
+			return err
+		},
+	}
+	for name, check := range checks {
+		accessTokens.found = 0
+		if err := check(); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if accessTokens.found != 1 {
+			t.Fatalf("%s looked the opaque token up %d times, want once", name, accessTokens.found)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"worker/internal/common/textnorm"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// PurgeUser permanently erases the target and their sessions and roles, requires domain.PermissionUsersDelete
+// confirmation must echo the target's email; the audit event is recorded before the erasure
+// since the user row it refers to is gone afterwards
+func (s *AuthService) PurgeUser(ctx context.Context, callerID, targetID uuid.UUID, confirmation string) error {
+	if err := s.requirePermission(ctx, callerID, domain.PermissionUsersDelete); err != nil {
+		return err
+	}
+
+	// Users of other tenants are reported as missing
+	caller, err := s.userRepo.FindByID(ctx, callerID)
+	if err != nil {
+		return mapUserLookupError(err)
+	}
+	target, err := s.userRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return mapUserLookupError(err)
+	}
+	if target.TenantID != caller.TenantID {
+		return mapUserLookupError(domain.ErrUserNotFound)
+	}
+
+	if !strings.EqualFold(textnorm.Identifier(confirmation), target.Email) {
+		return domain.NewAuthError(
+			domain.ErrConfirmationMismatch,
+			"confirmation must be the email address of the user to purge",
+			domain.CodeConfirmationMismatch,
+		).WithField("confirmation")
+	}
+
+	s.auditLog.Record(ctx, domain.AuditEvent{
+		Type:     domain.AuditUserPurged,
+		UserID:   targetID.String(),
+		TenantID: target.TenantID,
+		Client:   domain.ClientInfoFromContext(ctx),
+		ActorID:  callerID.String(),
+		At:       s.clock.Now(),
+	})
+
+	err = s.eraseUser(ctx, targetID, func(users ports.UserRepository) error {
+		return users.Delete(ctx, targetID)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return mapUserLookupError(err)
+		}
+		return mapUnitOfWorkError(err, "failed to purge user")
+	}
+	return nil
+}
+
+// eraseUser deletes the user's sessions, then the user through deleteUser, in one unit of work
+// Once committed, cached state of the user is dropped and other replicas are told about the revocation
+func (s *AuthService) eraseUser(ctx context.Context, userID uuid.UUID, deleteUser func(ports.UserRepository) error) error {
+	err := s.unitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		if err := repos.Sessions.DeleteAllForUser(ctx, userID); err != nil {
+			return err
+		}
+		return deleteUser(repos.Users)
+	})
+	if err != nil {
+		if errors.Is(err, domain.ErrUserReferenced) {
+			return domain.NewAuthError(
+				domain.ErrUserReferenced,
+				"user cannot be erased while other records still reference them",
+				domain.CodeUserReferenced,
+			)
+		}
+		return err
+	}
+
+	s.tokenVersions.Invalidate(ctx, userID)
+	s.permissionCache.Invalidate(ctx, userID)
+	s.revocations.Publish(domain.RevocationEvent{
+		UserID:    userID.String(),
+		Reason:    domain.RevocationReasonUserPurged,
+		RevokedAt: s.clock.Now(),
+	})
+	return nil
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// recordingAuditLog keeps the recorded audit events
+type recordingAuditLog struct {
+	events []domain.AuditEvent
+}
+
+func (l *recordingAuditLog) Record(ctx context.Context, event domain.AuditEvent) {
+	l.events = append(l.events, event)
+}
+
+// referencedUsers refuses to delete users, like a database where rows without a cascading foreign key
+// still reference them
+type referencedUsers struct {
+	ports.UserRepository
+}
+
+func (referencedUsers) Delete(ctx context.Context, userID uuid.UUID) error {
+	return domain.ErrUserReferenced
+}
+
+// referencedUnitOfWork runs its units of work on referencedUsers
+type referencedUnitOfWork struct {
+	ports.UnitOfWork
+}
+
+func (u referencedUnitOfWork) Do(ctx context.Context, fn func(repos ports.Repositories) error) error {
+	return u.UnitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		repos.Users = referencedUsers{UserRepository: repos.Users}
+		return fn(repos)
+	})
+}
+
+func TestPurgeUserConfirmation(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+	audit := &recordingAuditLog{}
+	s.auditLog = audit
+
+	for _, confirmation := range []string{"", "alice", "bob@example.com"} {
+		err := s.PurgeUser(ctx, adminID, alice.User.ID, confirmation)
+		assertCode(t, err, domain.CodeConfirmationMismatch)
+	}
+	if _, err := s.userRepo.FindByID(ctx, alice.User.ID); err != nil {
+		t.Fatalf("user after refused purges: %v", err)
+	}
+	if len(audit.events) != 0 {
+		t.Fatalf("refused purges recorded %v", audit.events)
+	}
+
+	// The email is compared like a login identifier, ignoring case and surrounding space
+	if err := s.PurgeUser(ctx, adminID, alice.User.ID, " Alice@Example.com "); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if _, err := s.userRepo.FindByID(ctx, alice.User.ID); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("find purged user: got %v, want ErrUserNotFound", err)
+	}
+	_, err := s.RefreshAccessToken(ctx, alice.RefreshToken)
+	if err == nil {
+		t.Fatal("refresh token of a purged user still works")
+	}
+	if len(audit.events) != 1 || audit.events[0].Type != domain.AuditUserPurged || audit.events[0].ActorID != adminID.String() {
+		t.Fatalf("audit events = %+v, want one user_purged by the admin", audit.events)
+	}
+
+	err = s.PurgeUser(ctx, adminID, alice.User.ID, alice.User.Email)
+	assertCode(t, err, domain.CodeUserNotFound)
+}
+
+func TestPurgeUserRequiresPermission(t *testing.T) {
+	s := newTestService(t, nil)
+	alice := s.register(t, "alice")
+	bob := s.register(t, "bob")
+
+	err := s.PurgeUser(context.Background(), alice.User.ID, bob.User.ID, bob.User.Email)
+	assertCode(t, err, domain.CodePermissionDenied)
+	if _, err := s.userRepo.FindByID(context.Background(), bob.User.ID); err != nil {
+		t.Fatalf("user after a refused purge: %v", err)
+	}
+}
+
+func TestPurgeUserStillReferenced(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+	s.unitOfWork = referencedUnitOfWork{UnitOfWork: s.unitOfWork}
+
+	err := s.PurgeUser(ctx, adminID, alice.User.ID, alice.User.Email)
+	assertCode(t, err, domain.CodeUserReferenced)
+	// The unit of work rolled back, so the user and their sessions are untouched
+	if _, err := s.RefreshAccessToken(ctx, alice.RefreshToken); err != nil {
+		t.Fatalf("refresh after a refused purge: %v", err)
+	}
+}
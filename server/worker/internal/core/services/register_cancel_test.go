@@ -0,0 +1,85 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// cancellingUnitOfWork cancels the request right after the user row is inserted, like a client
+// hanging up while the insert is in flight
+type cancellingUnitOfWork struct {
+	ports.UnitOfWork
+	cancel context.CancelFunc
+}
+
+func (u cancellingUnitOfWork) Do(ctx context.Context, fn func(repos ports.Repositories) error) error {
+	return u.UnitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		repos.Users = cancellingUsers{UserRepository: repos.Users, cancel: u.cancel}
+		return fn(repos)
+	})
+}
+
+type cancellingUsers struct {
+	ports.UserRepository
+	cancel context.CancelFunc
+}
+
+func (r cancellingUsers) CreateUser(ctx context.Context, params sqlc.CreateUserParams) (*sqlc.User, error) {
+	user, err := r.UserRepository.CreateUser(ctx, params)
+	r.cancel()
+	return user, err
+}
+
+// registerAlice registers alice with ctx
+func registerAlice(s *testService, ctx context.Context) (*ports.AuthResponse, error) {
+	return s.Register(ctx, &domain.RegisterRequest{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: testPassword,
+		FullName: "Alice",
+	})
+}
+
+// assertNotRegistered fails when an account named alice exists
+func assertNotRegistered(t *testing.T, s *testService) {
+	t.Helper()
+	if _, err := s.userRepo.FindByEmailOrUsername(context.Background(), "", "alice"); !errors.Is(err, domain.ErrUserNotFound) {
+		t.Fatalf("cancelled registration left an account behind: %v", err)
+	}
+}
+
+func TestRegisterCancelledBeforeInsert(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	resp, err := registerAlice(s, ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("register = %+v, %v; want context.Canceled", resp, err)
+	}
+	assertNotRegistered(t, s)
+}
+
+func TestRegisterCancelledDuringInsert(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	unitOfWork := s.unitOfWork
+	s.unitOfWork = cancellingUnitOfWork{UnitOfWork: unitOfWork, cancel: cancel}
+
+	resp, err := registerAlice(s, ctx)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("register = %+v, %v; want context.Canceled", resp, err)
+	}
+	// The insert was rolled back, so the client can simply try again
+	assertNotRegistered(t, s)
+	s.unitOfWork = unitOfWork
+	if _, err := registerAlice(s, context.Background()); err != nil {
+		t.Fatalf("register again: %v", err)
+	}
+}
@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+)
+
+// resetCodePurpose derives the key reset codes are hashed with
+const resetCodePurpose = "password-reset-code"
+
+// issueResetCode stores a new numeric reset code for the user, replacing a pending one, and returns it
+// Only the code's HMAC is stored, the code itself is handed to the user out-of-band
+func (s *AuthService) issueResetCode(ctx context.Context, userID uuid.UUID, now time.Time) (*domain.PasswordResetToken, error) {
+	code, err := randomDigits(s.authConfig.ResetOTPLength)
+	if err != nil {
+		return nil, domain.NewAuthError(
+			domain.ErrGeneratingToken,
+			"failed to generate password reset code",
+			domain.CodeInternalError,
+		)
+	}
+
+	expiresAt := now.Add(s.authConfig.ResetOTPExpiration)
+	err = s.resetCodes.Save(ctx, sqlc.UpsertPasswordResetCodeParams{
+		UserID:    userID,
+		CodeHash:  s.hashResetCode(userID, code),
+		ExpiresAt: pgtype.Timestamp{Time: expiresAt, Valid: true},
+	})
+	if err != nil {
+		return nil, databaseError(err, "failed to store password reset code")
+	}
+	return &domain.PasswordResetToken{Token: code, ExpiresAt: expiresAt}, nil
+}
+
+// resetCodeSubject returns the ID of the user identified by email or username once code matches their pending code
+// Every call counts as an attempt; after AUTH_RESET_OTP_MAX_ATTEMPTS the code is locked until a new one is issued
+func (s *AuthService) resetCodeSubject(ctx context.Context, identifier, code string) (uuid.UUID, error) {
+	invalid := domain.NewAuthError(
+		domain.ErrInvalidToken,
+		"invalid password reset code",
+		domain.CodeInvalidToken,
+	)
+	locked := domain.NewAuthError(
+		domain.ErrResetCodeLocked,
+		"password reset code is locked after too many attempts, ask an administrator for a new one",
+		domain.CodeResetCodeLocked,
+	)
+
+	tenantID, err := s.resolveTenant(ctx)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	// Unknown accounts and accounts without a pending code are reported like a wrong code
+	user, err := s.findByEmailOrUsername(ctx, tenantID, s.normalizeLoginIdentifier(identifier))
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return uuid.Nil, invalid
+		}
+		return uuid.Nil, databaseError(err, "failed to fetch user")
+	}
+	pending, err := s.resetCodes.RecordAttempt(ctx, user.ID)
+	if err != nil {
+		if errors.Is(err, domain.ErrTokenNotFound) {
+			return uuid.Nil, invalid
+		}
+		return uuid.Nil, databaseError(err, "failed to verify password reset code")
+	}
+
+	maxAttempts := int32(s.authConfig.ResetOTPMaxAttempts)
+	if pending.Attempts > maxAttempts {
+		return uuid.Nil, locked
+	}
+	if !s.clock.Now().Before(pending.ExpiresAt.Time) {
+		return uuid.Nil, domain.NewAuthError(
+			domain.ErrTokenExpired,
+			"password reset code has expired",
+			domain.CodeTokenExpired,
+		)
+	}
+	if !hmac.Equal([]byte(pending.CodeHash), []byte(s.hashResetCode(user.ID, code))) {
+		if pending.Attempts == maxAttempts {
+			return uuid.Nil, locked
+		}
+		return uuid.Nil, invalid
+	}
+	return user.ID, nil
+}
+
+// hashResetCode returns the hex HMAC a reset code is stored as
+// Codes are short, so they are keyed rather than plainly hashed, and bound to the user they were issued to
+func (s *AuthService) hashResetCode(userID uuid.UUID, code string) string {
+	mac := hmac.New(sha256.New, s.resetCodeKey)
+	mac.Write(userID[:])
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// randomDigits returns n uniformly random decimal digits
+func randomDigits(n int) (string, error) {
+	limit := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+	v, err := rand.Int(rand.Reader, limit)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%0*d", n, v), nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// newResetCodeService returns a service issuing 6-digit reset codes, entered at most 3 times within 15 minutes
+func newResetCodeService(t *testing.T) *testService {
+	return newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.ResetTokenType = config.ResetTokenTypeOTP
+		cfg.Auth.ResetOTPLength = 6
+		cfg.Auth.ResetOTPExpiration = 15 * time.Minute
+		cfg.Auth.ResetOTPMaxAttempts = 3
+	})
+}
+
+// issueResetCode forces a password reset of userID and returns the code issued for it
+func (s *testService) issueResetCode(tb testing.TB, adminID, userID uuid.UUID) string {
+	tb.Helper()
+	reset, err := s.ForcePasswordReset(context.Background(), adminID, userID)
+	if err != nil {
+		tb.Fatalf("force password reset: %v", err)
+	}
+	return reset.Token
+}
+
+// wrongCode returns a code of the same length differing from code in its last digit
+func wrongCode(code string) string {
+	last := (code[len(code)-1]-'0'+1)%10 + '0'
+	return code[:len(code)-1] + string(rune(last))
+}
+
+func TestResetCode(t *testing.T) {
+	s := newResetCodeService(t)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+
+	reset, err := s.ForcePasswordReset(ctx, adminID, alice.User.ID)
+	if err != nil {
+		t.Fatalf("force password reset: %v", err)
+	}
+	if len(reset.Token) != 6 || strings.Trim(reset.Token, "0123456789") != "" {
+		t.Fatalf("reset code = %q, want 6 digits", reset.Token)
+	}
+	if !reset.ExpiresAt.Equal(s.clock.Now().Add(15 * time.Minute)) {
+		t.Fatalf("reset code expires at %v, want in 15 minutes", reset.ExpiresAt)
+	}
+
+	// The code is entered with the account it was issued to, by email or username
+	if err := s.ChangePassword(ctx, "alice@example.com", reset.Token, "", newPassword); err != nil {
+		t.Fatalf("change password with the reset code: %v", err)
+	}
+	if _, err := s.Login(ctx, &domain.LoginRequest{Identifier: "alice", Password: newPassword}); err != nil {
+		t.Fatalf("login with the new password: %v", err)
+	}
+	// A used code is gone
+	err = s.ChangePassword(ctx, "alice", reset.Token, "", "Third-Horse-5-Battery")
+	assertCode(t, err, domain.CodeInvalidToken)
+}
+
+func TestResetCodeExpiry(t *testing.T) {
+	s := newResetCodeService(t)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+
+	code := s.issueResetCode(t, adminID, alice.User.ID)
+	s.clock.Advance(15 * time.Minute)
+	err := s.ChangePassword(ctx, "alice", code, "", newPassword)
+	assertCode(t, err, domain.CodeTokenExpired)
+
+	// A new code starts a new lifetime
+	code = s.issueResetCode(t, adminID, alice.User.ID)
+	s.clock.Advance(15*time.Minute - time.Second)
+	if err := s.ChangePassword(ctx, "alice", code, "", newPassword); err != nil {
+		t.Fatalf("change password a second before the code expires: %v", err)
+	}
+}
+
+func TestResetCodeAttemptLimit(t *testing.T) {
+	s := newResetCodeService(t)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+	code := s.issueResetCode(t, adminID, alice.User.ID)
+
+	for attempt := 1; attempt < 3; attempt++ {
+		err := s.ChangePassword(ctx, "alice", wrongCode(code), "", newPassword)
+		assertCode(t, err, domain.CodeInvalidToken)
+	}
+	// The last allowed attempt locks the code when wrong, and then even the right code is refused
+	err := s.ChangePassword(ctx, "alice", wrongCode(code), "", newPassword)
+	assertCode(t, err, domain.CodeResetCodeLocked)
+	err = s.ChangePassword(ctx, "alice", code, "", newPassword)
+	assertCode(t, err, domain.CodeResetCodeLocked)
+
+	// Until an administrator issues a new one, with its own attempts
+	code = s.issueResetCode(t, adminID, alice.User.ID)
+	err = s.ChangePassword(ctx, "alice", wrongCode(code), "", newPassword)
+	assertCode(t, err, domain.CodeInvalidToken)
+	if err := s.ChangePassword(ctx, "alice", code, "", newPassword); err != nil {
+		t.Fatalf("change password with the new code: %v", err)
+	}
+}
+
+func TestResetCodeRightOnLastAttempt(t *testing.T) {
+	s := newResetCodeService(t)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+	code := s.issueResetCode(t, adminID, alice.User.ID)
+
+	for attempt := 1; attempt < 3; attempt++ {
+		err := s.ChangePassword(ctx, "alice", wrongCode(code), "", newPassword)
+		assertCode(t, err, domain.CodeInvalidToken)
+	}
+	if err := s.ChangePassword(ctx, "alice", code, "", newPassword); err != nil {
+		t.Fatalf("change password on the last attempt: %v", err)
+	}
+}
+
+func TestResetCodeBoundToAccount(t *testing.T) {
+	s := newResetCodeService(t)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+	bob := s.register(t, "bob")
+	aliceCode := s.issueResetCode(t, adminID, alice.User.ID)
+	s.issueResetCode(t, adminID, bob.User.ID)
+
+	// Unknown accounts, accounts without a code and other accounts' codes fail alike
+	for _, identifier := range []string{"bob", "carol", "admin"} {
+		err := s.ChangePassword(ctx, identifier, aliceCode, "", newPassword)
+		assertCode(t, err, domain.CodeInvalidToken)
+	}
+
+	// A new code replaces the pending one
+	replacement := s.issueResetCode(t, adminID, alice.User.ID)
+	if replacement != aliceCode {
+		err := s.ChangePassword(ctx, "alice", aliceCode, "", newPassword)
+		assertCode(t, err, domain.CodeInvalidToken)
+	}
+	if err := s.ChangePassword(ctx, "alice", replacement, "", newPassword); err != nil {
+		t.Fatalf("change password with the replacement code: %v", err)
+	}
+}
+
+func TestResetCodeModeAcceptsResetTokens(t *testing.T) {
+	s := newResetCodeService(t)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	alice := s.register(t, "alice")
+	s.issueResetCode(t, adminID, alice.User.ID)
+
+	// Reset tokens issued before the switch to codes still work
+	reset, err := s.generatePasswordResetToken(alice.User.ID.String(), s.clock.Now())
+	if err != nil {
+		t.Fatalf("generate reset token: %v", err)
+	}
+	if err := s.ChangePassword(ctx, "", reset.Token, "", newPassword); err != nil {
+		t.Fatalf("change password with a reset token: %v", err)
+	}
+}
@@ -0,0 +1,59 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+func TestRevocationSurvivesRestart(t *testing.T) {
+	for _, tokenType := range []string{config.TokenTypeJWT, config.TokenTypeOpaque} {
+		t.Run(tokenType, func(t *testing.T) {
+			s := newTokenTypeTestService(t, tokenType)
+			ctx := context.Background()
+			adminID := s.registerAdmin(t)
+			alice := s.register(t, "alice")
+
+			// Warm the caches, so the revocation has cached state to leave behind
+			if _, err := s.ValidateAccessToken(ctx, alice.AccessToken); err != nil {
+				t.Fatalf("validate before the revocation: %v", err)
+			}
+			if err := s.RevokeAllUserTokens(ctx, adminID, alice.User.ID); err != nil {
+				t.Fatalf("revoke: %v", err)
+			}
+
+			// A restarted worker only has the database to go on, and the revocation is in it
+			restarted := s.restart(t)
+			if _, err := restarted.ValidateAccessToken(ctx, alice.AccessToken); err == nil {
+				t.Fatal("revoked access token validates after a restart")
+			}
+			_, err := restarted.RefreshAccessToken(ctx, alice.RefreshToken)
+			assertCode(t, err, domain.CodeSessionRevoked)
+
+			// Signing in again still works
+			fresh := restarted.mustLogin(t, "alice")
+			if _, err := restarted.ValidateAccessToken(ctx, fresh.AccessToken); err != nil {
+				t.Fatalf("validate a token issued after the restart: %v", err)
+			}
+		})
+	}
+}
+
+func TestPasswordChangeRevocationSurvivesRestart(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	alice := s.register(t, "alice")
+
+	if err := s.ChangePassword(ctx, "", alice.AccessToken, testPassword, "Another-Horse-7-Battery"); err != nil {
+		t.Fatalf("change password: %v", err)
+	}
+
+	restarted := s.restart(t)
+	if _, err := restarted.ValidateAccessToken(ctx, alice.AccessToken); err == nil {
+		t.Fatal("access token issued before the password change validates after a restart")
+	}
+	_, err := restarted.RefreshAccessToken(ctx, alice.RefreshToken)
+	assertCode(t, err, domain.CodeSessionRevoked)
+}
@@ -0,0 +1,206 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/logger"
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// ListRoles returns every role with its permissions, requires domain.PermissionRolesRead
+func (s *AuthService) ListRoles(ctx context.Context, accessToken string) ([]ports.RoleDetails, error) {
+	if _, err := s.authorize(ctx, accessToken, domain.PermissionRolesRead); err != nil {
+		return nil, err
+	}
+
+	roles, err := s.roleRepo.List(ctx)
+	if err != nil {
+		return nil, databaseError(err, "failed to list roles")
+	}
+	grants, err := s.roleRepo.ListGrants(ctx)
+	if err != nil {
+		return nil, databaseError(err, "failed to fetch role permissions")
+	}
+	defaultCode, err := s.defaultRoleCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	details := make([]ports.RoleDetails, 0, len(roles))
+	for _, role := range roles {
+		permissions := grants[role.ID]
+		if permissions == nil {
+			permissions = []domain.PermissionGrant{}
+		}
+		details = append(details, ports.RoleDetails{
+			Role:        role,
+			Permissions: permissions,
+			IsDefault:   role.Code == defaultCode,
+		})
+	}
+	return details, nil
+}
+
+// GetRole returns the role with the given code and its permissions, requires domain.PermissionRolesRead
+func (s *AuthService) GetRole(ctx context.Context, accessToken, code string) (*ports.RoleDetails, error) {
+	if _, err := s.authorize(ctx, accessToken, domain.PermissionRolesRead); err != nil {
+		return nil, err
+	}
+
+	role, err := s.findRoleByCode(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := s.roleRepo.GetGrantsByRoleID(ctx, role.ID)
+	if err != nil {
+		return nil, databaseError(err, "failed to fetch role permissions")
+	}
+	defaultCode, err := s.defaultRoleCode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ports.RoleDetails{
+		Role:        *role,
+		Permissions: permissions,
+		IsDefault:   role.Code == defaultCode,
+	}, nil
+}
+
+// defaultRoleCode returns the code of the role Register assigns, or "" when none is configured
+// Unlike resolveDefaultRole a missing default role is not an error, listing roles still works
+func (s *AuthService) defaultRoleCode(ctx context.Context) (string, error) {
+	if s.authConfig.DefaultRoleCode != "" {
+		return s.authConfig.DefaultRoleCode, nil
+	}
+
+	role, err := s.roleRepo.GetDefaultRole(ctx)
+	if err != nil {
+		if errors.Is(err, domain.ErrDefaultRoleNotFound) {
+			return "", nil
+		}
+		return "", databaseError(err, "failed to fetch default role")
+	}
+	return role.Code, nil
+}
+
+// ensureTokenRole makes sure the user's primary role code can go into an access token
+// A user row whose role cannot be resolved would otherwise be issued a token with an empty role;
+// AUTH_MISSING_ROLE decides whether it falls back to the default role or the login is refused
+func (s *AuthService) ensureTokenRole(ctx context.Context, user *sqlc.GetUserByEmailOrUsernameRow) error {
+	if user.RoleCode != nil && *user.RoleCode != "" {
+		return nil
+	}
+
+	log := logger.FromContext(ctx, s.logger).With(
+		zap.String("user_id", user.ID.String()),
+		zap.String("role_id", user.RoleID.String()),
+	)
+	missing := domain.NewAuthError(
+		domain.ErrRoleMissing,
+		"user role cannot be resolved, contact an administrator",
+		domain.CodeRoleMissing,
+	)
+	if s.authConfig.MissingRole == config.MissingRoleReject {
+		log.Warn("Refusing to issue a token to a user whose role cannot be resolved")
+		return missing
+	}
+
+	defaultCode, err := s.defaultRoleCode(ctx)
+	if err != nil {
+		return err
+	}
+	if defaultCode == "" {
+		log.Warn("Refusing to issue a token to a user whose role cannot be resolved, no default role is configured")
+		return missing
+	}
+	log.Warn("User role cannot be resolved, issuing the token with the default role",
+		zap.String("default_role", defaultCode),
+	)
+	user.RoleCode = &defaultCode
+	return nil
+}
+
+// GrantPermission grants a permission to a role, requires domain.PermissionRolesUpdate
+// The resource must exist; cached permissions are dropped since any user may hold the role
+func (s *AuthService) GrantPermission(ctx context.Context, accessToken, roleCode, permission string) error {
+	if _, err := s.authorize(ctx, accessToken, domain.PermissionRolesUpdate); err != nil {
+		return err
+	}
+
+	role, resource, action, err := s.resolveGrantTarget(ctx, roleCode, permission)
+	if err != nil {
+		return err
+	}
+	if err := s.roleRepo.GrantAction(ctx, role.ID, resource.ID, action); err != nil {
+		return databaseError(err, "failed to grant permission")
+	}
+
+	s.permissionCache.InvalidateAll(ctx)
+	return nil
+}
+
+// RevokePermission revokes a permission from a role, requires domain.PermissionRolesUpdate
+// Only the exact grant is revoked: revoking users:READ leaves a users:* grant in place
+func (s *AuthService) RevokePermission(ctx context.Context, accessToken, roleCode, permission string) error {
+	if _, err := s.authorize(ctx, accessToken, domain.PermissionRolesUpdate); err != nil {
+		return err
+	}
+
+	role, resource, action, err := s.resolveGrantTarget(ctx, roleCode, permission)
+	if err != nil {
+		return err
+	}
+	revoked, err := s.roleRepo.RevokeAction(ctx, role.ID, resource.ID, action)
+	if err != nil {
+		return databaseError(err, "failed to revoke permission")
+	}
+	if !revoked {
+		return domain.NewAuthError(
+			domain.ErrPermissionNotGranted,
+			fmt.Sprintf("role %q does not hold permission %s", roleCode, permission),
+			domain.CodePermissionNotGranted,
+		).WithField("permission")
+	}
+
+	s.permissionCache.InvalidateAll(ctx)
+	return nil
+}
+
+// resolveGrantTarget validates a granted permission and looks up its role and resource
+// Unlike CheckPermission, the action may be the "*" wildcard; the resource must be a known resource code
+func (s *AuthService) resolveGrantTarget(ctx context.Context, roleCode, permission string) (*sqlc.Role, *sqlc.Resource, string, error) {
+	resourceCode, action, ok := strings.Cut(permission, ":")
+	if !ok || resourceCode == "" || action == "" || strings.ContainsAny(permission, " \t\n") || strings.Contains(action, ":") {
+		return nil, nil, "", domain.NewFieldError(
+			domain.ErrInvalidPermission,
+			"permission",
+			"permission must have the form resource:ACTION",
+		)
+	}
+
+	role, err := s.findRoleByCode(ctx, roleCode)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	resource, err := s.roleRepo.FindResourceByCode(ctx, resourceCode)
+	if err != nil {
+		if errors.Is(err, domain.ErrResourceNotFound) {
+			return nil, nil, "", domain.NewAuthError(
+				domain.ErrResourceNotFound,
+				fmt.Sprintf("resource %q not found", resourceCode),
+				domain.CodeResourceNotFound,
+			).WithField("permission")
+		}
+		return nil, nil, "", databaseError(err, "failed to fetch resource")
+	}
+	return role, resource, action, nil
+}
@@ -0,0 +1,130 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// defaultRoles returns the codes of the roles flagged as the default
+func defaultRoles(roles []ports.RoleDetails) []string {
+	var codes []string
+	for _, role := range roles {
+		if role.IsDefault {
+			codes = append(codes, role.Role.Code)
+		}
+	}
+	return codes
+}
+
+func TestListRoles(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	roles, err := s.ListRoles(ctx, adminToken)
+	if err != nil {
+		t.Fatalf("list roles: %v", err)
+	}
+	codes := make([]string, len(roles))
+	for i, role := range roles {
+		codes[i] = role.Role.Code
+	}
+	if want := []string{"ADMIN", "LECTURER", "STUDENT"}; !slices.Equal(codes, want) {
+		t.Fatalf("roles = %v, want %v in code order", codes, want)
+	}
+	if got := defaultRoles(roles); !slices.Equal(got, []string{s.authConfig.DefaultRoleCode}) {
+		t.Fatalf("default roles = %v, want only %s", got, s.authConfig.DefaultRoleCode)
+	}
+
+	// Permissions come grouped by resource; roles without any carry an empty list
+	byResource := map[string][]string{}
+	for _, grant := range roles[0].Permissions {
+		if grant.ResourceName == "" {
+			t.Fatalf("grant on %s has no resource name", grant.ResourceCode)
+		}
+		byResource[grant.ResourceCode] = grant.Actions
+	}
+	if actions := byResource["users"]; !slices.Contains(actions, "READ") || !slices.Contains(actions, "DELETE") {
+		t.Fatalf("ADMIN users actions = %v, want READ and DELETE among them", actions)
+	}
+	if permissions := roles[1].Permissions; permissions == nil || len(permissions) != 0 {
+		t.Fatalf("LECTURER permissions = %#v, want an empty list", permissions)
+	}
+}
+
+func TestGetRole(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	listed, err := s.ListRoles(ctx, adminToken)
+	if err != nil {
+		t.Fatalf("list roles: %v", err)
+	}
+	for _, want := range listed {
+		role, err := s.GetRole(ctx, adminToken, want.Role.Code)
+		if err != nil {
+			t.Fatalf("get role %s: %v", want.Role.Code, err)
+		}
+		if role.Role.ID != want.Role.ID || role.IsDefault != want.IsDefault || len(role.Permissions) != len(want.Permissions) {
+			t.Fatalf("get role %s = %+v, want it as listed: %+v", want.Role.Code, role, want)
+		}
+	}
+
+	_, err = s.GetRole(ctx, adminToken, "DEAN")
+	assertCode(t, err, domain.CodeRoleNotFound)
+}
+
+func TestRolesRequireRolesRead(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	studentToken := s.register(t, "student").AccessToken
+
+	_, err := s.ListRoles(ctx, studentToken)
+	assertCode(t, err, domain.CodePermissionDenied)
+	_, err = s.GetRole(ctx, studentToken, "ADMIN")
+	assertCode(t, err, domain.CodePermissionDenied)
+}
+
+func TestListRolesDefaultFlag(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	// The configured default role is flagged
+	s.authConfig.DefaultRoleCode = "LECTURER"
+	roles, err := s.ListRoles(ctx, adminToken)
+	if err != nil {
+		t.Fatalf("list roles: %v", err)
+	}
+	if got := defaultRoles(roles); !slices.Equal(got, []string{"LECTURER"}) {
+		t.Fatalf("default roles = %v, want the configured LECTURER", got)
+	}
+
+	// Without one, the repository's default is
+	s.authConfig.DefaultRoleCode = ""
+	role, err := s.GetRole(ctx, adminToken, "STUDENT")
+	if err != nil {
+		t.Fatalf("get role: %v", err)
+	}
+	if !role.IsDefault {
+		t.Fatal("STUDENT is not flagged as the repository's default role")
+	}
+
+	// And a missing default role leaves none flagged instead of failing
+	s.roleRepo = unseededRoles{RoleRepository: s.roleRepo}
+	roles, err = s.ListRoles(ctx, adminToken)
+	if err != nil {
+		t.Fatalf("list roles without a default role: %v", err)
+	}
+	if got := defaultRoles(roles); len(got) != 0 {
+		t.Fatalf("default roles = %v, want none", got)
+	}
+}
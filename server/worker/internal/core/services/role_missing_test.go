@@ -0,0 +1,124 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// roleDeletedUsers returns the user rows of orphan without role info, as the users-roles join does
+// once the user's role row has been deleted
+type roleDeletedUsers struct {
+	ports.UserRepository
+	orphan uuid.UUID
+}
+
+func (r roleDeletedUsers) FindByID(ctx context.Context, id uuid.UUID) (*sqlc.GetUserByIDRow, error) {
+	user, err := r.UserRepository.FindByID(ctx, id)
+	if err == nil && user.ID == r.orphan {
+		user.RoleName, user.RoleCode = nil, nil
+	}
+	return user, err
+}
+
+func (r roleDeletedUsers) FindByEmailOrUsername(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error) {
+	user, err := r.UserRepository.FindByEmailOrUsername(ctx, tenantID, identifier)
+	if err == nil && user.ID == r.orphan {
+		user.RoleName, user.RoleCode = nil, nil
+	}
+	return user, err
+}
+
+// unseededRoles is a role repository without a default role, like a database whose roles were never seeded
+type unseededRoles struct {
+	ports.RoleRepository
+}
+
+func (unseededRoles) GetDefaultRole(ctx context.Context) (*sqlc.Role, error) {
+	return nil, domain.ErrDefaultRoleNotFound
+}
+
+// newMissingRoleService returns a service with AUTH_MISSING_ROLE set to mode
+func newMissingRoleService(t *testing.T, mode string) *testService {
+	return newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.MissingRole = mode
+	})
+}
+
+// deleteRoleOf makes the role of userID unresolvable from now on
+func (s *testService) deleteRoleOf(userID uuid.UUID) {
+	s.userRepo = roleDeletedUsers{UserRepository: s.userRepo, orphan: userID}
+}
+
+// tokenRole returns the role claim of an access token
+func (s *testService) tokenRole(tb testing.TB, accessToken string) string {
+	tb.Helper()
+	return s.tokenClaims(tb, accessToken).Role
+}
+
+func TestLoginWithMissingRoleFallsBackToDefault(t *testing.T) {
+	s := newMissingRoleService(t, config.MissingRoleDefault)
+	s.deleteRoleOf(s.register(t, "alice").User.ID)
+
+	resp := s.mustLogin(t, "alice")
+	if role := s.tokenRole(t, resp.AccessToken); role != s.authConfig.DefaultRoleCode {
+		t.Fatalf("token role = %q, want the default role %s", role, s.authConfig.DefaultRoleCode)
+	}
+}
+
+func TestLoginWithMissingRoleRejected(t *testing.T) {
+	s := newMissingRoleService(t, config.MissingRoleReject)
+	s.deleteRoleOf(s.register(t, "alice").User.ID)
+
+	_, err := s.login("alice")
+	assertCode(t, err, domain.CodeRoleMissing)
+}
+
+func TestLoginWithoutDefaultRoleRejected(t *testing.T) {
+	s := newMissingRoleService(t, config.MissingRoleDefault)
+	s.deleteRoleOf(s.register(t, "alice").User.ID)
+	s.authConfig.DefaultRoleCode = ""
+	s.roleRepo = unseededRoles{RoleRepository: s.roleRepo}
+
+	// Falling back needs a default role to fall back to
+	_, err := s.login("alice")
+	assertCode(t, err, domain.CodeRoleMissing)
+}
+
+func TestRefreshWithDeletedRoleFallsBackToDefault(t *testing.T) {
+	s := newMissingRoleService(t, config.MissingRoleDefault)
+	resp := s.register(t, "alice")
+	s.promote(t, resp.User.ID, "ADMIN")
+	s.deleteRoleOf(resp.User.ID)
+
+	refreshed, err := s.RefreshAccessToken(context.Background(), resp.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if role := s.tokenRole(t, refreshed.AccessToken); role != s.authConfig.DefaultRoleCode {
+		t.Fatalf("refreshed token role = %q, want the default role %s", role, s.authConfig.DefaultRoleCode)
+	}
+}
+
+func TestRefreshWithDeletedRoleRejected(t *testing.T) {
+	s := newMissingRoleService(t, config.MissingRoleReject)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+	users := s.userRepo
+	s.deleteRoleOf(resp.User.ID)
+
+	_, err := s.RefreshAccessToken(ctx, resp.RefreshToken)
+	assertCode(t, err, domain.CodeRoleMissing)
+
+	// The refused refresh did not rotate the session, so the token works again once the role is restored
+	s.userRepo = users
+	if _, err := s.RefreshAccessToken(ctx, resp.RefreshToken); err != nil {
+		t.Fatalf("refresh after restoring the role: %v", err)
+	}
+}
@@ -0,0 +1,232 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// versionFailingUnitOfWork fails every unit after the token version bump, once the role write is done
+type versionFailingUnitOfWork struct {
+	ports.UnitOfWork
+}
+
+func (u versionFailingUnitOfWork) Do(ctx context.Context, fn func(repos ports.Repositories) error) error {
+	return u.UnitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		repos.Users = versionFailingUserRepository{UserRepository: repos.Users}
+		return fn(repos)
+	})
+}
+
+type versionFailingUserRepository struct {
+	ports.UserRepository
+}
+
+func (r versionFailingUserRepository) IncrementTokenVersion(ctx context.Context, userID uuid.UUID) (int32, error) {
+	if _, err := r.UserRepository.IncrementTokenVersion(ctx, userID); err != nil {
+		return 0, err
+	}
+	return 0, domain.ErrDatabaseOperation
+}
+
+// roleCodes returns the codes of the user's roles, sorted
+func (s *testService) roleCodes(tb testing.TB, userID uuid.UUID) []string {
+	tb.Helper()
+	roles, err := s.roleRepo.FindByUserID(context.Background(), userID)
+	if err != nil {
+		tb.Fatalf("find roles: %v", err)
+	}
+	codes := make([]string, 0, len(roles))
+	for _, role := range roles {
+		codes = append(codes, role.Code)
+	}
+	slices.Sort(codes)
+	return codes
+}
+
+// roleID returns the ID of the role with code
+func (s *testService) roleID(tb testing.TB, code string) uuid.UUID {
+	tb.Helper()
+	role, err := s.roleRepo.FindByCode(context.Background(), code)
+	if err != nil {
+		tb.Fatalf("find role %s: %v", code, err)
+	}
+	return role.ID
+}
+
+// resourceID returns the ID of the resource with code
+func (s *testService) resourceID(tb testing.TB, code string) uuid.UUID {
+	tb.Helper()
+	resource, err := s.roleRepo.FindResourceByCode(context.Background(), code)
+	if err != nil {
+		tb.Fatalf("find resource %s: %v", code, err)
+	}
+	return resource.ID
+}
+
+// tokenVersion returns the user's token version
+func (s *testService) tokenVersion(tb testing.TB, userID uuid.UUID) int32 {
+	tb.Helper()
+	version, err := s.userRepo.GetTokenVersion(context.Background(), userID)
+	if err != nil {
+		tb.Fatalf("get token version: %v", err)
+	}
+	return version
+}
+
+func TestRoleChangesRollBackTogether(t *testing.T) {
+	tests := []struct {
+		name   string
+		setup  func(s *testService, userID uuid.UUID) error
+		change func(s *testService, userID uuid.UUID) error
+	}{
+		{
+			name:   "add role",
+			change: func(s *testService, userID uuid.UUID) error { return s.AddRole(context.Background(), userID, "ADMIN") },
+		},
+		{
+			name:  "remove role",
+			setup: func(s *testService, userID uuid.UUID) error { return s.AddRole(context.Background(), userID, "ADMIN") },
+			change: func(s *testService, userID uuid.UUID) error {
+				return s.RemoveRole(context.Background(), userID, "ADMIN")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(t, nil)
+			userID := s.register(t, "alice").User.ID
+			if tt.setup != nil {
+				if err := tt.setup(s, userID); err != nil {
+					t.Fatalf("setup: %v", err)
+				}
+			}
+			accessToken := s.mustLogin(t, "alice").AccessToken
+			roles, version := s.roleCodes(t, userID), s.tokenVersion(t, userID)
+
+			s.unitOfWork = versionFailingUnitOfWork{UnitOfWork: s.unitOfWork}
+			assertCode(t, tt.change(s, userID), domain.CodeInternalError)
+
+			if got := s.roleCodes(t, userID); !slices.Equal(got, roles) {
+				t.Fatalf("roles after the failed change = %v, want %v", got, roles)
+			}
+			if got := s.tokenVersion(t, userID); got != version {
+				t.Fatalf("token version after the failed change = %d, want %d", got, version)
+			}
+			if _, err := s.ValidateAccessToken(context.Background(), accessToken); err != nil {
+				t.Fatalf("validate after the failed change: %v", err)
+			}
+		})
+	}
+}
+
+// allowed reports whether the access token's user holds permission
+func (s *testService) allowed(tb testing.TB, accessToken, permission string) bool {
+	tb.Helper()
+	decision, err := s.CheckPermission(context.Background(), accessToken, permission)
+	if err != nil {
+		tb.Fatalf("check %s: %v", permission, err)
+	}
+	return decision.Allowed
+}
+
+func TestGrantPermissionRequiresRolesUpdate(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	lecturer := s.register(t, "lecturer")
+	s.promote(t, lecturer.User.ID, "LECTURER")
+	studentToken := s.register(t, "student").AccessToken
+	// Reading roles is not enough to change them
+	if err := s.roleRepo.GrantAction(ctx, s.roleID(t, "LECTURER"), s.resourceID(t, "roles"), "READ"); err != nil {
+		t.Fatalf("grant roles:READ: %v", err)
+	}
+	s.permissionCache.InvalidateAll(ctx)
+	lecturerToken := s.mustLogin(t, "lecturer").AccessToken
+
+	for name, accessToken := range map[string]string{"student": studentToken, "roles:READ holder": lecturerToken} {
+		err := s.GrantPermission(ctx, accessToken, s.authConfig.DefaultRoleCode, domain.PermissionUsersRead)
+		assertCode(t, err, domain.CodePermissionDenied)
+		err = s.RevokePermission(ctx, accessToken, "ADMIN", domain.PermissionUsersRead)
+		assertCode(t, err, domain.CodePermissionDenied)
+		if s.allowed(t, studentToken, domain.PermissionUsersRead) {
+			t.Fatalf("grant by the %s went through", name)
+		}
+	}
+
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+	if !s.allowed(t, adminToken, domain.PermissionUsersRead) {
+		t.Fatal("revoke without roles:UPDATE went through")
+	}
+	if err := s.GrantPermission(ctx, adminToken, s.authConfig.DefaultRoleCode, domain.PermissionUsersRead); err != nil {
+		t.Fatalf("grant by the admin: %v", err)
+	}
+}
+
+func TestGrantPermissionInvalidatesCache(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+	aliceToken := s.register(t, "alice").AccessToken
+	studentRole := s.authConfig.DefaultRoleCode
+
+	// alice's permissions are cached: a grant behind the service's back goes unseen
+	if s.allowed(t, aliceToken, domain.PermissionSystemRead) {
+		t.Fatal("alice holds system:READ before any grant")
+	}
+	if err := s.roleRepo.GrantAction(ctx, s.roleID(t, studentRole), s.resourceID(t, "system"), "READ"); err != nil {
+		t.Fatalf("grant behind the service: %v", err)
+	}
+	if s.allowed(t, aliceToken, domain.PermissionSystemRead) {
+		t.Fatal("permissions are not cached, the test cannot tell whether grants invalidate them")
+	}
+
+	// Granting and revoking through the service take effect on the next check
+	if err := s.GrantPermission(ctx, adminToken, studentRole, domain.PermissionUsersRead); err != nil {
+		t.Fatalf("grant: %v", err)
+	}
+	if !s.allowed(t, aliceToken, domain.PermissionUsersRead) {
+		t.Fatal("granted permission is not seen")
+	}
+	if err := s.RevokePermission(ctx, adminToken, studentRole, domain.PermissionUsersRead); err != nil {
+		t.Fatalf("revoke: %v", err)
+	}
+	if s.allowed(t, aliceToken, domain.PermissionUsersRead) {
+		t.Fatal("revoked permission is still held")
+	}
+
+	err := s.RevokePermission(ctx, adminToken, studentRole, domain.PermissionUsersRead)
+	assertCode(t, err, domain.CodePermissionNotGranted)
+}
+
+func TestGrantPermissionValidation(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	tests := []struct {
+		roleCode, permission, code string
+	}{
+		{roleCode: "ADMIN", permission: "users", code: domain.CodeInvalidArgument},
+		{roleCode: "ADMIN", permission: "users:", code: domain.CodeInvalidArgument},
+		{roleCode: "ADMIN", permission: ":READ", code: domain.CodeInvalidArgument},
+		{roleCode: "ADMIN", permission: "users:READ:ALL", code: domain.CodeInvalidArgument},
+		{roleCode: "ADMIN", permission: "users: READ", code: domain.CodeInvalidArgument},
+		{roleCode: "ADMIN", permission: "grades:READ", code: domain.CodeResourceNotFound},
+		{roleCode: "DEAN", permission: "users:READ", code: domain.CodeRoleNotFound},
+	}
+	for _, tt := range tests {
+		err := s.GrantPermission(ctx, adminToken, tt.roleCode, tt.permission)
+		assertCode(t, err, tt.code)
+		err = s.RevokePermission(ctx, adminToken, tt.roleCode, tt.permission)
+		assertCode(t, err, tt.code)
+	}
+}
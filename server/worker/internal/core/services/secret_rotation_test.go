@@ -0,0 +1,60 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"worker/internal/core/domain"
+)
+
+// withAccessSecrets wires a service over the same store and clock, signing with current and
+// still accepting previous, like a worker restarted with a rotated JWT_ACCESS_SECRET
+func (s *testService) withAccessSecrets(t *testing.T, current, previous string) *testService {
+	t.Helper()
+	cfg := *s.cfg
+	cfg.JWT.AccessSecret = current
+	cfg.JWT.PreviousAccessSecret = previous
+	return wireTestService(t, &cfg, s.store, s.clock)
+}
+
+func TestAccessSecretRotation(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	oldToken := s.register(t, "alice").AccessToken
+
+	rotated := s.withAccessSecrets(t, "rotated-access-secret", s.cfg.JWT.AccessSecret)
+	// Tokens signed with the previous secret still verify during the window
+	result, err := rotated.ValidateAccessToken(ctx, oldToken)
+	if err != nil || !result.Valid {
+		t.Fatalf("validate a token signed with the previous secret = %+v, %v", result, err)
+	}
+
+	// New tokens are signed with the new secret only
+	newToken := rotated.mustLogin(t, "alice").AccessToken
+	if _, err := rotated.ValidateAccessToken(ctx, newToken); err != nil {
+		t.Fatalf("validate a token signed with the new secret: %v", err)
+	}
+	if _, err := s.ValidateAccessToken(ctx, newToken); err == nil {
+		t.Fatal("a token issued after the rotation verifies with the previous secret")
+	}
+
+	// Once the previous secret is removed, only the new tokens verify
+	settled := s.withAccessSecrets(t, "rotated-access-secret", "")
+	if _, err := settled.ValidateAccessToken(ctx, newToken); err != nil {
+		t.Fatalf("validate a new token after the window: %v", err)
+	}
+	_, err = settled.ValidateAccessToken(ctx, oldToken)
+	assertCode(t, err, domain.CodeInvalidToken)
+}
+
+func TestAccessSecretRotationRejectsOtherSecrets(t *testing.T) {
+	s := newTestService(t, nil)
+	// A token signed with a secret that is neither current nor previous
+	foreign := s.withAccessSecrets(t, "foreign-access-secret", "")
+	foreign.register(t, "alice")
+	foreignToken := foreign.mustLogin(t, "alice").AccessToken
+
+	rotated := s.withAccessSecrets(t, "rotated-access-secret", s.cfg.JWT.AccessSecret)
+	_, err := rotated.ValidateAccessToken(context.Background(), foreignToken)
+	assertCode(t, err, domain.CodeInvalidToken)
+}
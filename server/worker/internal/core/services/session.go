@@ -0,0 +1,310 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"go.uber.org/zap"
+
+	"worker/internal/adapter/logger"
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+const (
+	// refreshNonceBytes is the entropy of the per-session refresh nonce
+	refreshNonceBytes = 32
+	// sessionSeenInterval is how stale last_seen_at gets before a validation moves it again
+	sessionSeenInterval = time.Minute
+)
+
+// startSession creates a session for the user and returns its first refresh token and its ID
+func (s *AuthService) startSession(ctx context.Context, userID uuid.UUID) (refreshToken, sessionID string, err error) {
+	if err := s.enforceSessionLimit(ctx, userID); err != nil {
+		return "", "", err
+	}
+
+	id, err := uuid.NewV7()
+	if err != nil {
+		return "", "", domain.NewAuthError(
+			domain.ErrGeneratingUUID,
+			"failed to generate session ID",
+			domain.CodeInternalError,
+		)
+	}
+
+	nonce, err := newRefreshNonce()
+	if err != nil {
+		return "", "", domain.NewAuthError(
+			domain.ErrGeneratingToken,
+			"failed to generate refresh nonce",
+			domain.CodeInternalError,
+		)
+	}
+
+	_, err = s.sessionRepo.Create(ctx, sqlc.CreateSessionParams{
+		ID:           id,
+		UserID:       userID,
+		RefreshNonce: nonce,
+		ExpiresAt:    pgtype.Timestamp{Time: s.clock.Now().Add(s.reloader.Current().RefreshExpiration), Valid: true},
+	})
+	if err != nil {
+		return "", "", databaseError(err, "failed to create session")
+	}
+
+	refreshToken, err = s.generateRefreshToken(userID.String(), id.String(), nonce)
+	if err != nil {
+		return "", "", domain.NewAuthError(
+			domain.ErrGeneratingToken,
+			"failed to generate refresh token",
+			domain.CodeInternalError,
+		)
+	}
+	return refreshToken, id.String(), nil
+}
+
+// enforceSessionLimit makes room for one more session of the user under AUTH_MAX_SESSIONS
+// With AUTH_MAX_SESSIONS_POLICY=evict_oldest the oldest active sessions are revoked, with reject
+// the new session is refused; expired and idle sessions do not count
+func (s *AuthService) enforceSessionLimit(ctx context.Context, userID uuid.UUID) error {
+	limit := s.authConfig.MaxSessions
+	if limit <= 0 {
+		return nil
+	}
+	now := s.clock.Now()
+	active, err := s.sessionRepo.ListActiveIDsForUser(ctx, userID, now, s.authConfig.SessionIdleBefore(now))
+	if err != nil {
+		return databaseError(err, "failed to count sessions")
+	}
+	if len(active) < limit {
+		return nil
+	}
+
+	if s.authConfig.MaxSessionsPolicy == config.MaxSessionsPolicyReject {
+		return domain.NewAuthError(
+			domain.ErrTooManySessions,
+			fmt.Sprintf("too many active sessions (at most %d), log out on another device first", limit),
+			domain.CodeTooManySessions,
+		)
+	}
+	for _, sessionID := range active[:len(active)-limit+1] {
+		if err := s.sessionRepo.Revoke(ctx, sessionID); err != nil {
+			return databaseError(err, "failed to revoke session")
+		}
+		s.revocations.Publish(domain.RevocationEvent{
+			SessionID: sessionID.String(),
+			UserID:    userID.String(),
+			Reason:    domain.RevocationReasonSessionLimit,
+			RevokedAt: now,
+		})
+	}
+	logger.FromContext(ctx, s.logger).Info("Revoked the oldest sessions to stay under AUTH_MAX_SESSIONS",
+		zap.String("user_id", userID.String()),
+		zap.Int("revoked", len(active)-limit+1),
+	)
+	return nil
+}
+
+// rotateSession checks the refresh token's nonce against its session and returns a refresh
+// token carrying a fresh nonce. Presenting an already-rotated nonce means the token was
+// replayed, so the whole session is revoked.
+func (s *AuthService) rotateSession(ctx context.Context, claims *RefreshTokenClaims, userID uuid.UUID) (string, error) {
+	invalid := domain.NewAuthError(
+		domain.ErrInvalidToken,
+		"invalid refresh token",
+		domain.CodeInvalidToken,
+	)
+
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil || claims.Nonce == "" {
+		return "", invalid
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return "", invalid
+		}
+		return "", databaseError(err, "failed to load session")
+	}
+
+	if session.UserID != userID {
+		return "", invalid
+	}
+	if session.RevokedAt.Valid {
+		return "", domain.NewAuthError(
+			domain.ErrSessionRevoked,
+			"session has been revoked",
+			domain.CodeSessionRevoked,
+		)
+	}
+	if s.clock.Now().After(session.ExpiresAt.Time) {
+		return "", domain.NewAuthError(
+			domain.ErrTokenExpired,
+			"session has expired",
+			domain.CodeTokenExpired,
+		)
+	}
+	if s.sessionIdle(session) {
+		return "", domain.NewAuthError(
+			domain.ErrTokenExpired,
+			"session has expired after a period of inactivity, log in again",
+			domain.CodeTokenExpired,
+		)
+	}
+
+	if subtle.ConstantTimeCompare([]byte(session.RefreshNonce), []byte(claims.Nonce)) != 1 {
+		return "", s.revokeReplayedSession(ctx, sessionID, userID)
+	}
+
+	nonce, err := newRefreshNonce()
+	if err != nil {
+		return "", domain.NewAuthError(
+			domain.ErrGeneratingToken,
+			"failed to generate refresh nonce",
+			domain.CodeInternalError,
+		)
+	}
+
+	now := s.clock.Now()
+	rotated, err := s.sessionRepo.RotateNonce(ctx, sessionID, claims.Nonce, nonce, now.Add(s.reloader.Current().RefreshExpiration), now)
+	if err != nil {
+		return "", databaseError(err, "failed to rotate session")
+	}
+	if !rotated {
+		// A concurrent refresh redeemed the same nonce first
+		return "", s.revokeReplayedSession(ctx, sessionID, userID)
+	}
+
+	refreshToken, err := s.generateRefreshToken(userID.String(), sessionID.String(), nonce)
+	if err != nil {
+		return "", domain.NewAuthError(
+			domain.ErrGeneratingToken,
+			"failed to generate refresh token",
+			domain.CodeInternalError,
+		)
+	}
+	return refreshToken, nil
+}
+
+// isRefreshSessionActive reports whether the refresh token's session is live and still expects its nonce
+func (s *AuthService) isRefreshSessionActive(ctx context.Context, claims *RefreshTokenClaims) (bool, error) {
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return false, nil
+	}
+
+	session, err := s.sessionRepo.FindByID(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, domain.ErrSessionNotFound) {
+			return false, nil
+		}
+		return false, databaseError(err, "failed to load session")
+	}
+
+	return !session.RevokedAt.Valid &&
+		s.clock.Now().Before(session.ExpiresAt.Time) &&
+		!s.sessionIdle(session) &&
+		subtle.ConstantTimeCompare([]byte(session.RefreshNonce), []byte(claims.Nonce)) == 1, nil
+}
+
+// sessionIdle reports whether the session went unrefreshed for longer than AUTH_SESSION_IDLE_TIMEOUT
+func (s *AuthService) sessionIdle(session *sqlc.Session) bool {
+	idleBefore := s.authConfig.SessionIdleBefore(s.clock.Now())
+	return !idleBefore.IsZero() && session.LastSeenAt.Valid && !session.LastSeenAt.Time.After(idleBefore)
+}
+
+// markSessionSeen records that the session of a validated access token is in use, so an
+// AUTH_SESSION_IDLE_TIMEOUT counts from the last request rather than the last refresh
+// Sessions are written at most once per sessionSeenInterval; failures are logged, the token stays valid
+func (s *AuthService) markSessionSeen(ctx context.Context, claims *AccessTokenClaims) {
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		return // Issued before access tokens named their session, or by a service
+	}
+	now := s.clock.Now()
+	err = s.sessionRepo.MarkSeen(ctx, sessionID, now, now.Add(-sessionSeenInterval), s.authConfig.SessionIdleBefore(now))
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Warn("Failed to mark session as seen",
+			zap.String("session_id", claims.SessionID),
+			zap.Error(err),
+		)
+	}
+}
+
+// revokeReplayedSession revokes a session after refresh token reuse was detected
+func (s *AuthService) revokeReplayedSession(ctx context.Context, sessionID, userID uuid.UUID) error {
+	if err := s.sessionRepo.Revoke(ctx, sessionID); err != nil {
+		return databaseError(err, "failed to revoke session")
+	}
+	s.revocations.Publish(domain.RevocationEvent{
+		SessionID: sessionID.String(),
+		UserID:    userID.String(),
+		Reason:    domain.RevocationReasonTokenReuse,
+		RevokedAt: s.clock.Now(),
+	})
+	return domain.NewAuthError(
+		domain.ErrRefreshTokenReused,
+		"refresh token reuse detected, session revoked",
+		domain.CodeTokenReused,
+	)
+}
+
+// newRefreshNonce returns a random URL-safe nonce
+func newRefreshNonce() (string, error) {
+	b := make([]byte, refreshNonceBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// WatchRevocations streams session revocations until ctx is done
+// The channel closes early when the subscriber falls behind and missed events
+func (s *AuthService) WatchRevocations(ctx context.Context) <-chan domain.RevocationEvent {
+	return s.revocations.Subscribe(ctx)
+}
+
+// RevokeAllUserTokens signs the target out everywhere, requires domain.PermissionUsersUpdate
+// Bumping the token version invalidates access tokens issued before,
+// revoking the sessions stops their refresh tokens
+func (s *AuthService) RevokeAllUserTokens(ctx context.Context, callerID, targetID uuid.UUID) error {
+	if err := s.requirePermission(ctx, callerID, domain.PermissionUsersUpdate); err != nil {
+		return err
+	}
+
+	// Users of other tenants are reported as missing
+	caller, err := s.userRepo.FindByID(ctx, callerID)
+	if err != nil {
+		return mapUserLookupError(err)
+	}
+	target, err := s.userRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return mapUserLookupError(err)
+	}
+	if target.TenantID != caller.TenantID {
+		return mapUserLookupError(domain.ErrUserNotFound)
+	}
+
+	if err := s.revokeAccessTokens(ctx, targetID); err != nil {
+		return err
+	}
+
+	if err := s.sessionRepo.RevokeAllForUser(ctx, targetID); err != nil {
+		return databaseError(err, "failed to revoke sessions")
+	}
+	s.revocations.Publish(domain.RevocationEvent{
+		UserID:    targetID.String(),
+		Reason:    domain.RevocationReasonAdminRevoked,
+		RevokedAt: s.clock.Now(),
+	})
+	return nil
+}
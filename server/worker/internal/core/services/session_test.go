@@ -0,0 +1,298 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// racingSessions lets a concurrent refresh redeem the nonce just before every RotateNonce
+type racingSessions struct {
+	ports.SessionRepository
+}
+
+func (r racingSessions) RotateNonce(ctx context.Context, id uuid.UUID, currentNonce, newNonce string, expiresAt, seenAt time.Time) (bool, error) {
+	if _, err := r.SessionRepository.RotateNonce(ctx, id, currentNonce, "redeemed-elsewhere", expiresAt, seenAt); err != nil {
+		return false, err
+	}
+	return r.SessionRepository.RotateNonce(ctx, id, currentNonce, newNonce, expiresAt, seenAt)
+}
+
+// countingAccessTokens counts the opaque access tokens stored and looked up
+type countingAccessTokens struct {
+	ports.AccessTokenRepository
+	created, found int
+}
+
+func (r *countingAccessTokens) Create(ctx context.Context, params sqlc.CreateAccessTokenParams) error {
+	r.created++
+	return r.AccessTokenRepository.Create(ctx, params)
+}
+
+func (r *countingAccessTokens) FindByHash(ctx context.Context, tokenHash string) (*sqlc.AccessToken, error) {
+	r.found++
+	return r.AccessTokenRepository.FindByHash(ctx, tokenHash)
+}
+
+// sessionOf returns the session a refresh token belongs to
+func (s *testService) sessionOf(tb testing.TB, refreshToken string) uuid.UUID {
+	tb.Helper()
+	claims, err := s.parseRefreshToken(refreshToken)
+	if err != nil {
+		tb.Fatalf("parse refresh token: %v", err)
+	}
+	sessionID, err := uuid.Parse(claims.SessionID)
+	if err != nil {
+		tb.Fatalf("parse session ID: %v", err)
+	}
+	return sessionID
+}
+
+// assertSessionRevoked fails unless the session has been revoked
+func (s *testService) assertSessionRevoked(tb testing.TB, sessionID uuid.UUID) {
+	tb.Helper()
+	session, err := s.sessionRepo.FindByID(context.Background(), sessionID)
+	if err != nil {
+		tb.Fatalf("find session: %v", err)
+	}
+	if !session.RevokedAt.Valid {
+		tb.Fatal("session is still active, want it revoked")
+	}
+}
+
+func TestRefreshRotatesNonce(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	first := s.register(t, "alice").RefreshToken
+
+	resp, err := s.RefreshAccessToken(ctx, first)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if resp.RefreshToken == first {
+		t.Fatal("refresh returned the same refresh token, want a rotated one")
+	}
+	if s.sessionOf(t, resp.RefreshToken) != s.sessionOf(t, first) {
+		t.Fatal("rotation moved the refresh token to another session")
+	}
+	if _, err := s.ValidateAccessToken(ctx, resp.AccessToken); err != nil {
+		t.Fatalf("validate refreshed access token: %v", err)
+	}
+
+	// Only the rotated token introspects as active
+	for token, active := range map[string]bool{first: false, resp.RefreshToken: true} {
+		introspection, err := s.IntrospectToken(ctx, token, domain.TokenTypeRefresh)
+		if err != nil || introspection.Active != active {
+			t.Fatalf("introspect = %+v, %v; want active %t", introspection, err, active)
+		}
+	}
+}
+
+func TestRefreshTokenReuseRevokesSession(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	stolen := s.register(t, "alice").RefreshToken
+	sessionID := s.sessionOf(t, stolen)
+
+	resp, err := s.RefreshAccessToken(ctx, stolen)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	_, err = s.RefreshAccessToken(ctx, stolen)
+	assertCode(t, err, domain.CodeTokenReused)
+	s.assertSessionRevoked(t, sessionID)
+
+	// The legitimate holder of the rotated token is signed out too
+	_, err = s.RefreshAccessToken(ctx, resp.RefreshToken)
+	assertCode(t, err, domain.CodeSessionRevoked)
+}
+
+func TestRefreshTokenReuseIssuesNoAccessToken(t *testing.T) {
+	s := newTokenTypeTestService(t, config.TokenTypeOpaque)
+	ctx := context.Background()
+	stolen := s.register(t, "alice").RefreshToken
+	if _, err := s.RefreshAccessToken(ctx, stolen); err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+
+	accessTokens := &countingAccessTokens{AccessTokenRepository: s.accessTokens}
+	s.accessTokens = accessTokens
+
+	// The replay is detected before an opaque access token is stored for it
+	_, err := s.RefreshAccessToken(ctx, stolen)
+	assertCode(t, err, domain.CodeTokenReused)
+	if accessTokens.created != 0 {
+		t.Fatalf("replayed refresh stored %d access tokens, want none", accessTokens.created)
+	}
+}
+
+func TestConcurrentRefreshTreatedAsReplay(t *testing.T) {
+	s := newTestService(t, nil)
+	refreshToken := s.register(t, "alice").RefreshToken
+	sessionID := s.sessionOf(t, refreshToken)
+
+	s.sessionRepo = racingSessions{SessionRepository: s.sessionRepo}
+
+	// The nonce matched when the session was loaded, but another refresh redeemed it before the swap
+	_, err := s.RefreshAccessToken(context.Background(), refreshToken)
+	assertCode(t, err, domain.CodeTokenReused)
+	s.assertSessionRevoked(t, sessionID)
+}
+
+func TestSessionLimitEvictsOldest(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.MaxSessions = 2
+		cfg.Auth.MaxSessionsPolicy = config.MaxSessionsPolicyEvictOldest
+	})
+	ctx := context.Background()
+
+	oldest := s.register(t, "alice") // Registering starts the first session
+	s.clock.Advance(time.Minute)
+	second := s.mustLogin(t, "alice")
+	s.clock.Advance(time.Minute)
+	third := s.mustLogin(t, "alice")
+
+	if _, err := s.RefreshAccessToken(ctx, oldest.RefreshToken); err == nil {
+		t.Fatal("refresh of the oldest session succeeded after the limit was exceeded, want it revoked")
+	}
+	for name, resp := range map[string]string{"second": second.RefreshToken, "third": third.RefreshToken} {
+		if _, err := s.RefreshAccessToken(ctx, resp); err != nil {
+			t.Fatalf("refresh of the %s session: %v", name, err)
+		}
+	}
+}
+
+func TestSessionLimitRejects(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.MaxSessions = 2
+		cfg.Auth.MaxSessionsPolicy = config.MaxSessionsPolicyReject
+	})
+	ctx := context.Background()
+
+	first := s.register(t, "alice")
+	s.mustLogin(t, "alice")
+
+	_, err := s.login("alice")
+	assertCode(t, err, domain.CodeTooManySessions)
+
+	// The sessions in place are left alone
+	if _, err := s.RefreshAccessToken(ctx, first.RefreshToken); err != nil {
+		t.Fatalf("refresh of the first session: %v", err)
+	}
+}
+
+func TestSessionLimitIgnoresRevokedSessions(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.MaxSessions = 1
+		cfg.Auth.MaxSessionsPolicy = config.MaxSessionsPolicyReject
+	})
+
+	first := s.register(t, "alice")
+	if err := s.sessionRepo.RevokeAllForUser(context.Background(), first.User.ID); err != nil {
+		t.Fatalf("revoke sessions: %v", err)
+	}
+	s.mustLogin(t, "alice")
+}
+
+func TestSessionIdleTimeout(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.SessionIdleTimeout = time.Hour
+	})
+	ctx := context.Background()
+
+	refreshToken := s.register(t, "alice").RefreshToken
+
+	// Each refresh moves last_seen_at, so a session in use outlives the timeout
+	for range 3 {
+		s.clock.Advance(50 * time.Minute)
+		resp, err := s.RefreshAccessToken(ctx, refreshToken)
+		if err != nil {
+			t.Fatalf("refresh within the idle timeout: %v", err)
+		}
+		refreshToken = resp.RefreshToken
+	}
+
+	s.clock.Advance(time.Hour)
+	_, err := s.RefreshAccessToken(ctx, refreshToken)
+	assertCode(t, err, domain.CodeTokenExpired)
+
+	introspection, err := s.IntrospectToken(ctx, refreshToken, domain.TokenTypeRefresh)
+	if err != nil {
+		t.Fatalf("introspect: %v", err)
+	}
+	if introspection.Active {
+		t.Fatal("idle session's refresh token introspects as active")
+	}
+}
+
+func TestSessionIdleTimeoutCountsValidations(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.SessionIdleTimeout = time.Hour
+		cfg.JWT.AccessExpiration = time.Hour
+	})
+	ctx := context.Background()
+	alice := s.register(t, "alice")
+	bob := s.register(t, "bob")
+
+	// Only alice uses her access token; validating it marks her session as seen
+	s.clock.Advance(50 * time.Minute)
+	if _, err := s.ValidateAccessToken(ctx, alice.AccessToken); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	s.clock.Advance(50 * time.Minute)
+	if _, err := s.RefreshAccessToken(ctx, alice.RefreshToken); err != nil {
+		t.Fatalf("refresh of a session in use: %v", err)
+	}
+	_, err := s.RefreshAccessToken(ctx, bob.RefreshToken)
+	assertCode(t, err, domain.CodeTokenExpired)
+}
+
+func TestAccessTokenNamesItsSession(t *testing.T) {
+	for _, tokenType := range []string{config.TokenTypeJWT, config.TokenTypeOpaque} {
+		t.Run(tokenType, func(t *testing.T) {
+			s := newTokenTypeTestService(t, tokenType)
+			ctx := context.Background()
+			resp := s.register(t, "alice")
+			login := s.mustLogin(t, "alice")
+			refreshed, err := s.RefreshAccessToken(ctx, login.RefreshToken)
+			if err != nil {
+				t.Fatalf("refresh: %v", err)
+			}
+
+			for name, tokens := range map[string][2]string{
+				"register": {resp.AccessToken, resp.RefreshToken},
+				"login":    {login.AccessToken, login.RefreshToken},
+				"refresh":  {refreshed.AccessToken, refreshed.RefreshToken},
+			} {
+				claims, err := s.parseAccessToken(ctx, tokens[0])
+				if err != nil {
+					t.Fatalf("parse %s access token: %v", name, err)
+				}
+				if want := s.sessionOf(t, tokens[1]).String(); claims.SessionID != want {
+					t.Fatalf("%s access token names session %q, want %s", name, claims.SessionID, want)
+				}
+			}
+		})
+	}
+}
+
+func TestSessionLimitIgnoresIdleSessions(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.SessionIdleTimeout = time.Hour
+		cfg.Auth.MaxSessions = 1
+		cfg.Auth.MaxSessionsPolicy = config.MaxSessionsPolicyReject
+	})
+
+	s.register(t, "alice")
+	s.clock.Advance(2 * time.Hour)
+	s.mustLogin(t, "alice")
+}
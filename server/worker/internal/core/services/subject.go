@@ -0,0 +1,28 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+
+	"worker/internal/config"
+)
+
+// tokenSubject returns the sub claim of the tokens of a user, formatted by AUTH_SUBJECT_FORMAT
+func (s *AuthService) tokenSubject(userID string) string {
+	return strings.Replace(s.authConfig.SubjectFormat, config.SubjectUserIDPlaceholder, userID, 1)
+}
+
+// subjectUserID returns the user ID of a sub claim formatted by AUTH_SUBJECT_FORMAT
+// A bare user ID is accepted as well, so tokens issued before the format was changed keep working
+func (s *AuthService) subjectUserID(subject string) (uuid.UUID, error) {
+	prefix, suffix, _ := strings.Cut(s.authConfig.SubjectFormat, config.SubjectUserIDPlaceholder)
+	if id, ok := strings.CutPrefix(subject, prefix); ok {
+		if id, ok := strings.CutSuffix(id, suffix); ok {
+			if userID, err := uuid.Parse(id); err == nil {
+				return userID, nil
+			}
+		}
+	}
+	return uuid.Parse(subject)
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+const urnSubjectFormat = "urn:worker:user:{user_id}"
+
+func TestCustomSubjectFormatRoundTrip(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.SubjectFormat = urnSubjectFormat
+	})
+	ctx := context.Background()
+	alice := s.register(t, "alice")
+	userID := alice.User.ID.String()
+
+	// Issued tokens carry the formatted subject
+	claims, err := s.parseAccessToken(ctx, alice.AccessToken)
+	if err != nil {
+		t.Fatalf("parse access token: %v", err)
+	}
+	if claims.Subject != "urn:worker:user:"+userID {
+		t.Fatalf("access token subject = %q, want the URN of %s", claims.Subject, userID)
+	}
+	introspection, err := s.IntrospectToken(ctx, alice.RefreshToken, domain.TokenTypeRefresh)
+	if err != nil {
+		t.Fatalf("introspect refresh token: %v", err)
+	}
+	if !introspection.Active || introspection.Subject != "urn:worker:user:"+userID {
+		t.Fatalf("introspection = %+v, want the refresh token active with the URN subject", introspection)
+	}
+
+	// Validation reports the user ID the subject stands for
+	result, err := s.ValidateAccessToken(ctx, alice.AccessToken)
+	if err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+	if result.UserID != userID {
+		t.Fatalf("validated user = %q, want %s", result.UserID, userID)
+	}
+	batch, err := s.ValidateAccessTokens(ctx, []string{alice.AccessToken})
+	if err != nil || batch[0].Err != nil || batch[0].Result.UserID != userID {
+		t.Fatalf("batch validation = %+v, %v; want %s", batch, err, userID)
+	}
+
+	// And every flow reading a subject back resolves it
+	refreshed, err := s.RefreshAccessToken(ctx, alice.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh: %v", err)
+	}
+	if err := s.ChangePassword(ctx, "", refreshed.AccessToken, testPassword, "Another-Horse-7-Battery"); err != nil {
+		t.Fatalf("change password: %v", err)
+	}
+}
+
+func TestSubjectFormatChangeKeepsIssuedTokens(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	alice := s.register(t, "alice")
+
+	// Tokens issued with the bare user ID keep working once a format is configured
+	s.authConfig.SubjectFormat = urnSubjectFormat
+	result, err := s.ValidateAccessToken(ctx, alice.AccessToken)
+	if err != nil {
+		t.Fatalf("validate a token issued before the change: %v", err)
+	}
+	if result.UserID != alice.User.ID.String() {
+		t.Fatalf("validated user = %q, want %s", result.UserID, alice.User.ID)
+	}
+	refreshed, err := s.RefreshAccessToken(ctx, alice.RefreshToken)
+	if err != nil {
+		t.Fatalf("refresh a token issued before the change: %v", err)
+	}
+	claims, err := s.parseAccessToken(ctx, refreshed.AccessToken)
+	if err != nil {
+		t.Fatalf("parse refreshed access token: %v", err)
+	}
+	if claims.Subject != "urn:worker:user:"+alice.User.ID.String() {
+		t.Fatalf("refreshed subject = %q, want the new format", claims.Subject)
+	}
+}
+
+func TestSubjectUserID(t *testing.T) {
+	userID := uuid.New()
+	tests := []struct {
+		format, subject string
+		wantErr         bool
+	}{
+		{format: config.SubjectUserIDPlaceholder, subject: userID.String()},
+		{format: urnSubjectFormat, subject: "urn:worker:user:" + userID.String()},
+		{format: "{user_id}@worker", subject: userID.String() + "@worker"},
+		{format: "user/{user_id}/v1", subject: "user/" + userID.String() + "/v1"},
+		// A bare user ID is accepted whatever the format
+		{format: urnSubjectFormat, subject: userID.String()},
+		{format: urnSubjectFormat, subject: "urn:other:user:" + userID.String(), wantErr: true},
+		{format: urnSubjectFormat, subject: "urn:worker:user:alice", wantErr: true},
+		{format: "{user_id}@worker", subject: userID.String() + "@other", wantErr: true},
+	}
+	for _, tt := range tests {
+		s := &AuthService{authConfig: &config.AuthConfig{SubjectFormat: tt.format}}
+		got, err := s.subjectUserID(tt.subject)
+		if tt.wantErr {
+			if err == nil {
+				t.Fatalf("subject %q with format %q = %s, want an error", tt.subject, tt.format, got)
+			}
+			continue
+		}
+		if err != nil || got != userID {
+			t.Fatalf("subject %q with format %q = %s, %v; want %s", tt.subject, tt.format, got, err, userID)
+		}
+		if tt.subject != userID.String() && s.tokenSubject(userID.String()) != tt.subject {
+			t.Fatalf("format %q makes subject %q, want %q", tt.format, s.tokenSubject(userID.String()), tt.subject)
+		}
+	}
+}
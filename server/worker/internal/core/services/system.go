@@ -0,0 +1,63 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+const (
+	// defaultStatsDays is the registration window used when GetStats is called without one
+	defaultStatsDays = 30
+	// maxStatsDays caps the registration window a caller may request
+	maxStatsDays = 366
+)
+
+// GetDBStats returns database pool statistics, requires domain.PermissionSystemRead
+func (s *AuthService) GetDBStats(ctx context.Context, accessToken string) (*domain.DBStats, error) {
+	if _, err := s.authorize(ctx, accessToken, domain.PermissionSystemRead); err != nil {
+		return nil, err
+	}
+	return s.dbStats.Stats(), nil
+}
+
+// GetStats returns user aggregates of the caller's tenant, requires domain.PermissionSystemRead
+// Registrations are counted per day over the last days, today included
+func (s *AuthService) GetStats(ctx context.Context, accessToken string, days int32) (*ports.UserStats, error) {
+	if _, err := s.authorize(ctx, accessToken, domain.PermissionSystemRead); err != nil {
+		return nil, err
+	}
+
+	tenantID, err := s.resolveTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if days <= 0 {
+		days = defaultStatsDays
+	}
+	days = min(days, maxStatsDays)
+	now := s.clock.Now()
+	since := time.Date(now.Year(), now.Month(), now.Day()-int(days-1), 0, 0, 0, 0, now.Location())
+
+	byRole, err := s.stats.CountUsersByRole(ctx, tenantID)
+	if err != nil {
+		return nil, databaseError(err, "failed to count users by role")
+	}
+	perDay, err := s.stats.CountRegistrationsPerDay(ctx, tenantID, since)
+	if err != nil {
+		return nil, databaseError(err, "failed to count registrations")
+	}
+
+	stats := &ports.UserStats{
+		UsersByRole:         byRole,
+		RegistrationsPerDay: perDay,
+		Since:               since,
+	}
+	for _, row := range byRole {
+		stats.TotalUsers += row.UserCount
+	}
+	return stats, nil
+}
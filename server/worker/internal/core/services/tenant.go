@@ -0,0 +1,53 @@
+package services
+
+import (
+	"context"
+
+	"worker/internal/core/domain"
+)
+
+// tokenIssuer is the issuer of tokens in single-tenant mode and the prefix of per-tenant issuers
+const tokenIssuer = "worker-auth-service"
+
+// resolveTenant returns the tenant a request operates in
+// In multi-tenant mode it comes from the x-tenant-id metadata and is required;
+// otherwise every request belongs to AUTH_DEFAULT_TENANT
+func (s *AuthService) resolveTenant(ctx context.Context) (string, error) {
+	if !s.authConfig.MultiTenant {
+		return s.authConfig.DefaultTenant, nil
+	}
+
+	tenantID, ok := domain.TenantFromContext(ctx)
+	if !ok {
+		return "", domain.NewAuthError(
+			domain.ErrTenantRequired,
+			"x-tenant-id metadata is required",
+			domain.CodeTenantRequired,
+		).WithField("x-tenant-id")
+	}
+	return tenantID, nil
+}
+
+// accessTokenIssuer returns the issuer of access tokens for a tenant
+func (s *AuthService) accessTokenIssuer(tenantID string) string {
+	if !s.authConfig.MultiTenant {
+		return tokenIssuer
+	}
+	return tokenIssuer + "/tenants/" + tenantID
+}
+
+// checkTenant rejects tokens and users of another tenant than the one named by the request
+// Requests without x-tenant-id are not restricted, so tenant-unaware callers keep working
+func (s *AuthService) checkTenant(ctx context.Context, tenantID string) error {
+	if !s.authConfig.MultiTenant {
+		return nil
+	}
+	if requested, ok := domain.TenantFromContext(ctx); ok && requested != tenantID {
+		return domain.NewAuthError(
+			domain.ErrTenantMismatch,
+			"token was issued for a different tenant",
+			domain.CodeInvalidToken,
+		)
+	}
+	return nil
+}
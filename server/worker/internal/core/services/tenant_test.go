@@ -0,0 +1,202 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+func newMultiTenantService(t *testing.T, configure func(*config.Config)) *testService {
+	return newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.MultiTenant = true
+		if configure != nil {
+			configure(cfg)
+		}
+	})
+}
+
+// inTenant returns a context carrying x-tenant-id, as the tenant interceptor resolves it
+func inTenant(tenantID string) context.Context {
+	return domain.WithTenant(context.Background(), tenantID)
+}
+
+// registerIn creates an account named username in a tenant and returns its tokens
+func (s *testService) registerIn(tb testing.TB, tenantID, username string) *ports.AuthResponse {
+	tb.Helper()
+	resp, err := s.Register(inTenant(tenantID), &domain.RegisterRequest{
+		Username: username,
+		Email:    username + "@example.com",
+		Password: testPassword,
+		FullName: "Test " + username,
+	})
+	if err != nil {
+		tb.Fatalf("register %s in %s: %v", username, tenantID, err)
+	}
+	return resp
+}
+
+// registerAdminIn registers an active ADMIN account named admin in a tenant and returns its ID
+func (s *testService) registerAdminIn(tb testing.TB, tenantID string) uuid.UUID {
+	tb.Helper()
+	adminID := s.registerIn(tb, tenantID, "admin").User.ID
+	if err := s.userRepo.SetActive(context.Background(), adminID, true); err != nil {
+		tb.Fatalf("activate admin: %v", err)
+	}
+	s.promote(tb, adminID, "ADMIN")
+	return adminID
+}
+
+func TestTenantsShareEmailAndUsername(t *testing.T) {
+	s := newMultiTenantService(t, nil)
+
+	inA := s.registerIn(t, "a", "alice")
+	inB := s.registerIn(t, "b", "alice")
+	if inA.User.ID == inB.User.ID {
+		t.Fatal("both tenants got the same account")
+	}
+
+	// Uniqueness still holds within a tenant
+	_, err := s.Register(inTenant("a"), &domain.RegisterRequest{
+		Username: "alice",
+		Email:    "other@example.com",
+		Password: testPassword,
+		FullName: "Other Alice",
+	})
+	assertCode(t, err, domain.CodeUserAlreadyExists)
+
+	for tenantID, want := range map[string]uuid.UUID{"a": inA.User.ID, "b": inB.User.ID} {
+		resp, err := s.Login(inTenant(tenantID), &domain.LoginRequest{Identifier: "alice@example.com", Password: testPassword})
+		if err != nil {
+			t.Fatalf("login in %s: %v", tenantID, err)
+		}
+		if resp.User.ID != want {
+			t.Fatalf("login in %s signed in %s, want %s", tenantID, resp.User.ID, want)
+		}
+
+		introspection, err := s.IntrospectToken(inTenant(tenantID), resp.AccessToken, domain.TokenTypeAccess)
+		if err != nil || !introspection.Active {
+			t.Fatalf("introspect in %s = %+v, %v; want active", tenantID, introspection, err)
+		}
+		if introspection.TenantID != tenantID || introspection.Issuer != tokenIssuer+"/tenants/"+tenantID {
+			t.Fatalf("introspect in %s = tenant %q issuer %q", tenantID, introspection.TenantID, introspection.Issuer)
+		}
+	}
+}
+
+func TestTenantRequired(t *testing.T) {
+	s := newMultiTenantService(t, nil)
+
+	_, err := s.Register(context.Background(), &domain.RegisterRequest{
+		Username: "alice",
+		Email:    "alice@example.com",
+		Password: testPassword,
+		FullName: "Alice",
+	})
+	assertCode(t, err, domain.CodeTenantRequired)
+
+	s.registerIn(t, "a", "alice")
+	_, err = s.login("alice")
+	assertCode(t, err, domain.CodeTenantRequired)
+}
+
+func TestTenantTokenRejectedInOtherTenant(t *testing.T) {
+	s := newMultiTenantService(t, nil)
+	accessToken := s.registerIn(t, "a", "alice").AccessToken
+
+	if _, err := s.ValidateAccessToken(inTenant("a"), accessToken); err != nil {
+		t.Fatalf("validate in the issuing tenant: %v", err)
+	}
+	// Callers that do not name a tenant are not restricted
+	if _, err := s.ValidateAccessToken(context.Background(), accessToken); err != nil {
+		t.Fatalf("validate without x-tenant-id: %v", err)
+	}
+
+	_, err := s.ValidateAccessToken(inTenant("b"), accessToken)
+	assertCode(t, err, domain.CodeInvalidToken)
+
+	introspection, err := s.IntrospectToken(inTenant("b"), accessToken, domain.TokenTypeAccess)
+	if err != nil || introspection.Active {
+		t.Fatalf("introspect in another tenant = %+v, %v; want inactive", introspection, err)
+	}
+}
+
+func TestRevokeAllUserTokensRefusesOtherTenant(t *testing.T) {
+	s := newMultiTenantService(t, nil)
+	adminID := s.registerAdminIn(t, "a")
+	target := s.registerIn(t, "b", "bob")
+
+	err := s.RevokeAllUserTokens(inTenant("a"), adminID, target.User.ID)
+	assertCode(t, err, domain.CodeUserNotFound)
+
+	if _, err := s.ValidateAccessToken(inTenant("b"), target.AccessToken); err != nil {
+		t.Fatalf("the other tenant's token was revoked: %v", err)
+	}
+
+	// The same admin may revoke within its tenant
+	local := s.registerIn(t, "a", "carol")
+	if err := s.RevokeAllUserTokens(inTenant("a"), adminID, local.User.ID); err != nil {
+		t.Fatalf("revoke in the admin's tenant: %v", err)
+	}
+}
+
+func TestApproveUserRefusesOtherTenant(t *testing.T) {
+	s := newMultiTenantService(t, func(cfg *config.Config) {
+		cfg.Auth.NewUserActive = false
+	})
+	adminID := s.registerAdminIn(t, "a")
+	targetID := s.registerIn(t, "b", "bob").User.ID
+
+	err := s.ApproveUser(inTenant("a"), adminID, targetID, "ADMIN")
+	assertCode(t, err, domain.CodeUserNotFound)
+
+	target, err := s.userRepo.FindByID(context.Background(), targetID)
+	if err != nil {
+		t.Fatalf("find target: %v", err)
+	}
+	if target.RoleCode == nil || *target.RoleCode != s.authConfig.DefaultRoleCode {
+		t.Fatalf("role of the other tenant's user = %v, want it unchanged", target.RoleCode)
+	}
+}
+
+func TestRoleChangesRefuseOtherTenant(t *testing.T) {
+	s := newMultiTenantService(t, nil)
+	resp := s.registerIn(t, "a", "alice")
+	userID := resp.User.ID
+
+	assertCode(t, s.AddRole(inTenant("b"), userID, "ADMIN"), domain.CodeUserNotFound)
+	assertCode(t, s.RemoveRole(inTenant("b"), userID, s.authConfig.DefaultRoleCode), domain.CodeUserNotFound)
+
+	roles, err := s.roleRepo.FindByUserID(context.Background(), userID)
+	if err != nil {
+		t.Fatalf("find roles: %v", err)
+	}
+	if len(roles) != 1 || roles[0].Code != s.authConfig.DefaultRoleCode {
+		t.Fatalf("roles = %v, want only the default role", roles)
+	}
+	// Refused changes do not revoke the user's tokens either
+	if _, err := s.ValidateAccessToken(inTenant("a"), resp.AccessToken); err != nil {
+		t.Fatalf("validate after refused role changes: %v", err)
+	}
+
+	if err := s.AddRole(inTenant("a"), userID, "ADMIN"); err != nil {
+		t.Fatalf("add role in the user's tenant: %v", err)
+	}
+}
+
+func TestForcePasswordResetRefusesOtherTenant(t *testing.T) {
+	s := newMultiTenantService(t, nil)
+	adminID := s.registerAdminIn(t, "a")
+	target := s.registerIn(t, "b", "bob")
+
+	_, err := s.ForcePasswordReset(inTenant("a"), adminID, target.User.ID)
+	assertCode(t, err, domain.CodeUserNotFound)
+
+	if _, err := s.ValidateAccessToken(inTenant("b"), target.AccessToken); err != nil {
+		t.Fatalf("the other tenant's token was revoked: %v", err)
+	}
+}
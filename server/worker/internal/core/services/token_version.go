@@ -0,0 +1,57 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// checkTokenVersion rejects an access token issued before the user's token version was last bumped
+// The stored version is cached; a bump on this replica replaces the cached value immediately
+func (s *AuthService) checkTokenVersion(ctx context.Context, userID uuid.UUID, claims *AccessTokenClaims) error {
+	version, ok := s.tokenVersions.Get(ctx, userID)
+	if !ok {
+		var err error
+		version, err = s.userRepo.GetTokenVersion(ctx, userID)
+		if err != nil {
+			return mapUserLookupError(err)
+		}
+		s.tokenVersions.Set(ctx, userID, version)
+	}
+
+	if claims.Version != version {
+		return domain.NewAuthError(
+			domain.ErrSessionRevoked,
+			"token has been revoked",
+			domain.CodeSessionRevoked,
+		)
+	}
+	return nil
+}
+
+// revokeAccessTokens bumps the user's token version, invalidating every access token issued before
+// Refresh tokens stay valid, so clients obtain a token with the current claims on their next refresh
+func (s *AuthService) revokeAccessTokens(ctx context.Context, userID uuid.UUID) error {
+	version, err := incrementTokenVersion(ctx, s.userRepo, userID)
+	if err != nil {
+		return err
+	}
+	return s.tokenVersionBumped(ctx, userID, version)
+}
+
+// incrementTokenVersion bumps the user's token version through users, which may be bound to a unit of work
+// The caller caches the returned version once the change is committed
+func incrementTokenVersion(ctx context.Context, users ports.UserRepository, userID uuid.UUID) (int32, error) {
+	version, err := users.IncrementTokenVersion(ctx, userID)
+	if err != nil {
+		if errors.Is(err, domain.ErrUserNotFound) {
+			return 0, mapUserLookupError(err)
+		}
+		return 0, databaseError(err, "failed to invalidate access tokens")
+	}
+	return version, nil
+}
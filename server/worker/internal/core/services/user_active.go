@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// SetUserActive activates or deactivates the target, requires domain.PermissionUsersUpdate
+// Activating approves an account Register created inactive with AUTH_NEW_USER_ACTIVE=false;
+// deactivating also revokes the target's access tokens and sessions, like RevokeAllUserTokens
+func (s *AuthService) SetUserActive(ctx context.Context, callerID, targetID uuid.UUID, active bool) error {
+	if err := s.requirePermission(ctx, callerID, domain.PermissionUsersUpdate); err != nil {
+		return err
+	}
+
+	// Users of other tenants are reported as missing
+	caller, err := s.userRepo.FindByID(ctx, callerID)
+	if err != nil {
+		return mapUserLookupError(err)
+	}
+	target, err := s.userRepo.FindByID(ctx, targetID)
+	if err != nil {
+		return mapUserLookupError(err)
+	}
+	if target.TenantID != caller.TenantID {
+		return mapUserLookupError(domain.ErrUserNotFound)
+	}
+
+	// The status and the revocations are committed together: a deactivated user
+	// keeping live access tokens or sessions must not be observable
+	var version int32
+	err = s.unitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		if err := repos.Users.SetActive(ctx, targetID, active); err != nil {
+			if errors.Is(err, domain.ErrUserNotFound) {
+				return mapUserLookupError(err)
+			}
+			return databaseError(err, "failed to update user")
+		}
+		if active {
+			return nil
+		}
+		var err error
+		if version, err = incrementTokenVersion(ctx, repos.Users, targetID); err != nil {
+			return err
+		}
+		if err := repos.Sessions.RevokeAllForUser(ctx, targetID); err != nil {
+			return databaseError(err, "failed to revoke sessions")
+		}
+		return nil
+	})
+	if err != nil {
+		return mapUnitOfWorkError(err, "failed to update user")
+	}
+
+	now := s.clock.Now()
+	event := domain.AuditEvent{
+		Type:     domain.AuditUserActivated,
+		UserID:   targetID.String(),
+		TenantID: target.TenantID,
+		Client:   domain.ClientInfoFromContext(ctx),
+		ActorID:  callerID.String(),
+		At:       now,
+	}
+	if !active {
+		if err := s.tokenVersionBumped(ctx, targetID, version); err != nil {
+			return err
+		}
+		s.revocations.Publish(domain.RevocationEvent{
+			UserID:    targetID.String(),
+			Reason:    domain.RevocationReasonUserDeactivated,
+			RevokedAt: now,
+		})
+		event.Type = domain.AuditUserDeactivated
+	}
+	s.auditLog.Record(ctx, event)
+	return nil
+}
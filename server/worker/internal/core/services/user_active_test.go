@@ -0,0 +1,89 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/google/uuid"
+
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// failingSessionsUnitOfWork fails session revocations inside a unit of work, after the other changes were made
+type failingSessionsUnitOfWork struct {
+	ports.UnitOfWork
+}
+
+func (u failingSessionsUnitOfWork) Do(ctx context.Context, fn func(repos ports.Repositories) error) error {
+	return u.UnitOfWork.Do(ctx, func(repos ports.Repositories) error {
+		repos.Sessions = failingSessionRepository{SessionRepository: repos.Sessions}
+		return fn(repos)
+	})
+}
+
+type failingSessionRepository struct {
+	ports.SessionRepository
+}
+
+var errRevokeFailed = errors.New("revoke failed")
+
+func (r failingSessionRepository) RevokeAllForUser(ctx context.Context, userID uuid.UUID) error {
+	return errRevokeFailed
+}
+
+func TestSetUserActive(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	s.register(t, "bob")
+	bob := s.mustLogin(t, "bob") // A deactivated account is one that was in use, not one awaiting approval
+
+	if err := s.SetUserActive(ctx, adminID, bob.User.ID, false); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	_, err := s.ValidateAccessToken(ctx, bob.AccessToken)
+	assertCode(t, err, domain.CodeSessionRevoked)
+	_, err = s.login("bob")
+	assertCode(t, err, domain.CodeUserInactive)
+
+	if err := s.SetUserActive(ctx, adminID, bob.User.ID, true); err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	// Activating again does not bring back the revoked session
+	_, err = s.RefreshAccessToken(ctx, bob.RefreshToken)
+	assertCode(t, err, domain.CodeSessionRevoked)
+	s.mustLogin(t, "bob")
+}
+
+func TestSetUserActiveRollsBackOnFailure(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	bob := s.register(t, "bob")
+	s.unitOfWork = failingSessionsUnitOfWork{UnitOfWork: s.unitOfWork}
+
+	if err := s.SetUserActive(ctx, adminID, bob.User.ID, false); err == nil {
+		t.Fatal("deactivate succeeded although the session revocation failed")
+	}
+
+	// Neither the status, the token version nor the session changed
+	if _, err := s.ValidateAccessToken(ctx, bob.AccessToken); err != nil {
+		t.Fatalf("validate after the failed deactivation: %v", err)
+	}
+	if _, err := s.RefreshAccessToken(ctx, bob.RefreshToken); err != nil {
+		t.Fatalf("refresh after the failed deactivation: %v", err)
+	}
+	s.mustLogin(t, "bob")
+}
+
+func TestSetUserActiveRequiresPermission(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	alice := s.register(t, "alice").User.ID
+	bob := s.register(t, "bob").User.ID
+
+	assertCode(t, s.SetUserActive(ctx, alice, bob, false), domain.CodePermissionDenied)
+	s.mustLogin(t, "bob")
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"slices"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+const (
+	// defaultUserPageSize is used when ListUsers is called without a page size
+	defaultUserPageSize = 50
+	// maxUserPageSize caps the page size a caller may request
+	maxUserPageSize = 200
+)
+
+// userSortFields is the allowlist of ListUsers sort fields; each field and direction has its own query
+var userSortFields = map[string]bool{
+	domain.UserSortCreatedAt: true,
+	domain.UserSortLastLogin: true,
+	domain.UserSortUsername:  true,
+}
+
+// neverLoggedIn is the last_login sort key of users without a login, matching 'epoch' in the ListUsersByLastLogin queries
+var neverLoggedIn = time.Unix(0, 0).UTC()
+
+// lastSortTime and lastSortText sort after every created_at, last_login and username, starting descending listings
+var (
+	lastSortTime = time.Date(9999, time.December, 31, 0, 0, 0, 0, time.UTC)
+	lastSortText = string(utf8.MaxRune)
+)
+
+// userCursor is the keyset position after the last user of a page
+// Clients receive it base64url-encoded and must treat it as opaque
+type userCursor struct {
+	SortField  string    `json:"s"`
+	Descending bool      `json:"d,omitempty"`
+	Time       time.Time `json:"t,omitzero"`  // created_at or last_login sort key
+	Text       string    `json:"x,omitempty"` // username sort key
+	ID         uuid.UUID `json:"i"`
+}
+
+// ListUsers returns one page of the caller's tenant users ordered by the requested field, then ID
+// Keyset pagination keeps pages stable while users are inserted: no user is skipped or repeated,
+// although users inserted behind the cursor only show up in a new listing
+func (s *AuthService) ListUsers(ctx context.Context, accessToken string, query *domain.UserListQuery) (*ports.UserPage, error) {
+	if _, err := s.authorize(ctx, accessToken, domain.PermissionUsersRead); err != nil {
+		return nil, err
+	}
+
+	tenantID, err := s.resolveTenant(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	sortField := query.SortField
+	if sortField == "" {
+		sortField = domain.UserSortCreatedAt
+	}
+	if !userSortFields[sortField] {
+		return nil, domain.NewFieldError(
+			domain.ErrInvalidSortField,
+			"sort_field",
+			"sort field must be one of created_at, last_login or username",
+		)
+	}
+
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultUserPageSize
+	}
+	pageSize = min(pageSize, maxUserPageSize)
+
+	after := firstUserPosition(sortField, query.Descending)
+	if query.Cursor != "" {
+		if after, err = decodeUserCursor(query.Cursor); err != nil {
+			return nil, err
+		}
+		if after.SortField != sortField || after.Descending != query.Descending {
+			return nil, domain.NewFieldError(
+				domain.ErrInvalidCursor,
+				"cursor",
+				"cursor was issued for a different sort order",
+			)
+		}
+	}
+
+	// One extra row tells whether another page follows
+	users, err := s.listUsersAfter(ctx, tenantID, query, after, pageSize+1)
+	if err != nil {
+		return nil, databaseError(err, "failed to list users")
+	}
+
+	page := &ports.UserPage{Users: users}
+	if len(users) > int(pageSize) {
+		page.Users = users[:pageSize]
+		page.NextCursor = encodeUserCursor(cursorAfter(&page.Users[pageSize-1], sortField, query.Descending))
+	}
+	roleIDs := make([]uuid.UUID, 0, len(page.Users))
+	for i := range page.Users {
+		page.Users[i].Password = ""
+		if !slices.Contains(roleIDs, page.Users[i].RoleID) {
+			roleIDs = append(roleIDs, page.Users[i].RoleID)
+		}
+	}
+
+	// One query for the whole page rather than one per user
+	page.RolePermissions, err = s.roleRepo.GetPermissionsByRoleIDs(ctx, roleIDs)
+	if err != nil {
+		return nil, databaseError(err, "failed to load role permissions")
+	}
+	return page, nil
+}
+
+// listUsersAfter runs the query of after's sort order, each of which has an index over its keyset
+func (s *AuthService) listUsersAfter(ctx context.Context, tenantID string, query *domain.UserListQuery, after userCursor, limit int32) ([]sqlc.ListUsersRow, error) {
+	params := sqlc.ListUsersParams{
+		TenantID:        tenantID,
+		IsActive:        query.IsActive,
+		AfterTime:       after.Time,
+		AfterID:         after.ID,
+		PendingApproval: query.PendingApproval,
+		PageSize:        limit,
+	}
+	byUsername := sqlc.ListUsersByUsernameParams{
+		TenantID:        tenantID,
+		IsActive:        query.IsActive,
+		AfterText:       after.Text,
+		AfterID:         after.ID,
+		PendingApproval: query.PendingApproval,
+		PageSize:        limit,
+	}
+	switch {
+	case after.SortField == domain.UserSortUsername && after.Descending:
+		return s.userRepo.ListUsersByUsernameDesc(ctx, sqlc.ListUsersByUsernameDescParams(byUsername))
+	case after.SortField == domain.UserSortUsername:
+		return s.userRepo.ListUsersByUsername(ctx, byUsername)
+	case after.SortField == domain.UserSortLastLogin && after.Descending:
+		return s.userRepo.ListUsersByLastLoginDesc(ctx, sqlc.ListUsersByLastLoginDescParams(params))
+	case after.SortField == domain.UserSortLastLogin:
+		return s.userRepo.ListUsersByLastLogin(ctx, sqlc.ListUsersByLastLoginParams(params))
+	case after.Descending:
+		return s.userRepo.ListUsersDesc(ctx, sqlc.ListUsersDescParams(params))
+	default:
+		return s.userRepo.ListUsers(ctx, params)
+	}
+}
+
+// firstUserPosition returns the keyset position before the first user of an order,
+// so the first page runs the same query as the pages after it
+func firstUserPosition(sortField string, descending bool) userCursor {
+	if !descending {
+		// The zero time, the empty string and the nil UUID sort before every user
+		return userCursor{SortField: sortField}
+	}
+	return userCursor{SortField: sortField, Descending: true, Time: lastSortTime, Text: lastSortText, ID: uuid.Max}
+}
+
+// cursorAfter returns the cursor positioned after user in the given order
+func cursorAfter(user *sqlc.ListUsersRow, sortField string, descending bool) userCursor {
+	c := userCursor{SortField: sortField, Descending: descending, ID: user.ID}
+	switch sortField {
+	case domain.UserSortUsername:
+		c.Text = user.Username
+	case domain.UserSortLastLogin:
+		c.Time = neverLoggedIn
+		if user.LastLogin.Valid {
+			c.Time = user.LastLogin.Time
+		}
+	default:
+		c.Time = user.CreatedAt.Time
+	}
+	return c
+}
+
+func encodeUserCursor(c userCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeUserCursor(cursor string) (userCursor, error) {
+	var c userCursor
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err == nil {
+		err = json.Unmarshal(raw, &c)
+	}
+	if err != nil || c.ID == uuid.Nil || !userSortFields[c.SortField] {
+		return userCursor{}, domain.NewFieldError(domain.ErrInvalidCursor, "cursor", "cursor is malformed")
+	}
+	return c, nil
+}
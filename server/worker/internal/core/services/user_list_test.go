@@ -0,0 +1,210 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// countingRoles counts the permission lookups of a role repository
+type countingRoles struct {
+	ports.RoleRepository
+	single, batch int
+}
+
+func (r *countingRoles) GetPermissionsByRoleID(ctx context.Context, roleID uuid.UUID) ([]string, error) {
+	r.single++
+	return r.RoleRepository.GetPermissionsByRoleID(ctx, roleID)
+}
+
+func (r *countingRoles) GetPermissionsByRoleIDs(ctx context.Context, roleIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	r.batch++
+	return r.RoleRepository.GetPermissionsByRoleIDs(ctx, roleIDs)
+}
+
+func TestListUsersRolePermissions(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+	s.promote(t, s.register(t, "lecturer").User.ID, "LECTURER")
+	s.register(t, "student")
+	s.register(t, "student2")
+
+	// Checking the caller's own permission is allowed one lookup; the listing itself must batch
+	if _, err := s.ListUsers(ctx, adminToken, &domain.UserListQuery{}); err != nil {
+		t.Fatalf("warm up: %v", err)
+	}
+	roles := &countingRoles{RoleRepository: s.roleRepo}
+	s.roleRepo = roles
+
+	page, err := s.ListUsers(ctx, adminToken, &domain.UserListQuery{})
+	if err != nil {
+		t.Fatalf("list users: %v", err)
+	}
+	if roles.batch != 1 || roles.single != 0 {
+		t.Fatalf("permission lookups = %d batched, %d single; want one batched", roles.batch, roles.single)
+	}
+	if len(page.Users) != 4 {
+		t.Fatalf("listed %d users, want 4", len(page.Users))
+	}
+	if len(page.RolePermissions) != 3 {
+		t.Fatalf("permissions for %d roles, want one per distinct role: %v", len(page.RolePermissions), page.RolePermissions)
+	}
+
+	byRole := map[string][]string{}
+	for _, user := range page.Users {
+		permissions, ok := page.RolePermissions[user.RoleID]
+		if !ok {
+			t.Fatalf("no permissions for the role of %s", user.Username)
+		}
+		byRole[*user.RoleCode] = permissions
+	}
+	if !slices.Contains(byRole["ADMIN"], domain.PermissionUsersRead) {
+		t.Fatalf("ADMIN permissions = %v, want %s among them", byRole["ADMIN"], domain.PermissionUsersRead)
+	}
+	// Roles without permissions are present with an empty list
+	for _, code := range []string{"LECTURER", s.authConfig.DefaultRoleCode} {
+		if permissions := byRole[code]; permissions == nil || len(permissions) != 0 {
+			t.Fatalf("%s permissions = %#v, want an empty list", code, permissions)
+		}
+	}
+}
+
+// listAll pages through ListUsers with pageSize and returns the usernames in listing order
+func listAll(t *testing.T, s *testService, accessToken string, query domain.UserListQuery, pageSize int32) []string {
+	t.Helper()
+	var names []string
+	query.PageSize = pageSize
+	for {
+		page, err := s.ListUsers(context.Background(), accessToken, &query)
+		if err != nil {
+			t.Fatalf("list users after %v: %v", names, err)
+		}
+		for _, user := range page.Users {
+			names = append(names, user.Username)
+		}
+		if page.NextCursor == "" {
+			return names
+		}
+		query.Cursor = page.NextCursor
+	}
+}
+
+func TestListUsersSortOrders(t *testing.T) {
+	s := newTestService(t, nil)
+	s.registerAdmin(t)
+	// Registered in this order, one minute apart; carol and alice log in, carol last
+	for _, name := range []string{"carol", "Bob", "alice", "dave"} {
+		s.clock.Advance(time.Minute)
+		s.register(t, name)
+	}
+	s.clock.Advance(time.Minute)
+	s.mustLogin(t, "alice")
+	s.clock.Advance(time.Minute)
+	s.mustLogin(t, "carol")
+	s.clock.Advance(time.Minute)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+
+	tests := []struct {
+		sortField string
+		want      []string // Ascending; "*" matches any user, as users who never logged in tie at the epoch
+	}{
+		{domain.UserSortCreatedAt, []string{"admin", "carol", "Bob", "alice", "dave"}},
+		{domain.UserSortLastLogin, []string{"*", "*", "alice", "carol", "admin"}},
+		// Bytewise, so upper case sorts first
+		{domain.UserSortUsername, []string{"Bob", "admin", "alice", "carol", "dave"}},
+	}
+	for _, tt := range tests {
+		for _, descending := range []bool{false, true} {
+			query := domain.UserListQuery{SortField: tt.sortField, Descending: descending}
+			all := listAll(t, s, adminToken, query, 0)
+			want := slices.Clone(tt.want)
+			if descending {
+				slices.Reverse(want)
+			}
+			if len(all) != len(want) {
+				t.Fatalf("%s descending=%v = %v, want %v", tt.sortField, descending, all, want)
+			}
+			for i := range want {
+				if want[i] != "*" && all[i] != want[i] {
+					t.Fatalf("%s descending=%v = %v, want %v", tt.sortField, descending, all, want)
+				}
+			}
+			// Walking the same order a page at a time gives the same listing
+			for _, pageSize := range []int32{1, 2} {
+				if paged := listAll(t, s, adminToken, query, pageSize); !slices.Equal(paged, all) {
+					t.Fatalf("%s descending=%v in pages of %d = %v, want %v", tt.sortField, descending, pageSize, paged, all)
+				}
+			}
+		}
+	}
+}
+
+func TestListUsersStableAcrossInserts(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	s.registerAdmin(t)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+	// Registered at the same instant, so created_at ties and the ID decides their order
+	original := map[string]bool{"admin": true}
+	for _, name := range []string{"alice", "bob", "carol", "dave", "erin"} {
+		s.register(t, name)
+		original[name] = true
+	}
+
+	seen := map[string]bool{}
+	var listed []sqlc.ListUsersRow
+	query := domain.UserListQuery{PageSize: 2}
+	for pages := 0; ; pages++ {
+		page, err := s.ListUsers(ctx, adminToken, &query)
+		if err != nil {
+			t.Fatalf("list page %d: %v", pages, err)
+		}
+		for _, user := range page.Users {
+			if seen[user.Username] {
+				t.Fatalf("%s listed twice", user.Username)
+			}
+			seen[user.Username] = true
+			listed = append(listed, user)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.Cursor = page.NextCursor
+
+		// Between the first pages, users join both at the tied instant and later
+		if pages < 2 {
+			s.register(t, fmt.Sprintf("tied%d", pages))
+			s.clock.Advance(time.Second)
+			s.register(t, fmt.Sprintf("later%d", pages))
+		}
+	}
+
+	for name := range original {
+		if !seen[name] {
+			t.Fatalf("%s was skipped; listed %v", name, seen)
+		}
+	}
+	// Users inserted ahead of the cursor are picked up by the pages still to come
+	if !seen["later0"] || !seen["later1"] {
+		t.Fatalf("users were inserted ahead of the cursor but not listed; listed %v", seen)
+	}
+	for i := 1; i < len(listed); i++ {
+		prev, cur := listed[i-1], listed[i]
+		if order := prev.CreatedAt.Time.Compare(cur.CreatedAt.Time); order > 0 ||
+			order == 0 && bytes.Compare(prev.ID[:], cur.ID[:]) >= 0 {
+			t.Fatalf("%s (%v, %s) listed before %s (%v, %s)",
+				prev.Username, prev.CreatedAt.Time, prev.ID, cur.Username, cur.CreatedAt.Time, cur.ID)
+		}
+	}
+}
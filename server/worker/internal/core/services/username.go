@@ -0,0 +1,98 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+
+	"worker/internal/core/domain"
+)
+
+// ChangeUsername renames the user within their tenant and returns the normalized username
+// A rename is refused while the previous one is younger than AUTH_USERNAME_CHANGE_COOLDOWN;
+// the repository re-checks the cooldown atomically, so concurrent renames cannot both succeed
+func (s *AuthService) ChangeUsername(ctx context.Context, userID uuid.UUID, newUsername string) (string, error) {
+	newUsername = s.normalizeUsername(newUsername)
+	if err := checkFieldLength("new_username", newUsername, s.authConfig.MaxUsernameLength); err != nil {
+		return "", err
+	}
+	if !domain.IsValidUsername(newUsername) {
+		return "", domain.NewFieldError(
+			domain.ErrInvalidUsername,
+			"new_username",
+			"username must be 3-50 letters, digits, dots, underscores or hyphens and start with a letter or digit",
+		)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "", mapUserLookupError(err)
+	}
+	if user.Username == newUsername {
+		return "", domain.NewFieldError(
+			domain.ErrInvalidUsername,
+			"new_username",
+			"new username must differ from the current one",
+		)
+	}
+
+	cooldown := s.authConfig.UsernameChangeCooldown
+	if remaining := usernameCooldownRemaining(s.clock.Now(), user.UsernameChangedAt, cooldown); remaining > 0 {
+		return "", usernameChangeTooSoon(remaining)
+	}
+
+	// A name stored with capitals may be lowercased in place; the case-insensitive check would find its owner
+	exists := false
+	if !strings.EqualFold(user.Username, newUsername) {
+		exists, err = s.usernameTaken(ctx, user.TenantID, newUsername)
+		if err != nil {
+			return "", databaseError(err, "failed to check username existence")
+		}
+	}
+	if exists {
+		return "", domain.NewAuthError(
+			domain.ErrUsernameAlreadyExists,
+			"username is already taken",
+			domain.CodeUserAlreadyExists,
+		).WithField("new_username")
+	}
+
+	renamed, err := s.userRepo.UpdateUsername(ctx, userID, newUsername, cooldown)
+	if err != nil {
+		// Another user can take the name between the existence check and the update
+		if errors.Is(err, domain.ErrUsernameAlreadyExists) {
+			return "", domain.NewAuthError(
+				domain.ErrUsernameAlreadyExists,
+				"username is already taken",
+				domain.CodeUserAlreadyExists,
+			).WithField("new_username")
+		}
+		return "", databaseError(err, "failed to change username")
+	}
+	if !renamed {
+		// A concurrent rename of the same user won the race and started a new cooldown
+		return "", usernameChangeTooSoon(cooldown)
+	}
+	return newUsername, nil
+}
+
+// usernameCooldownRemaining returns how long until the user may rename again (0 when allowed)
+func usernameCooldownRemaining(now time.Time, changedAt pgtype.Timestamp, cooldown time.Duration) time.Duration {
+	if cooldown <= 0 || !changedAt.Valid {
+		return 0
+	}
+	return max(cooldown-now.Sub(changedAt.Time), 0)
+}
+
+func usernameChangeTooSoon(remaining time.Duration) error {
+	return domain.NewAuthError(
+		domain.ErrUsernameChangeTooSoon,
+		fmt.Sprintf("username was changed recently, try again in %s", remaining.Round(time.Minute)),
+		domain.CodeUsernameChangeTooSoon,
+	)
+}
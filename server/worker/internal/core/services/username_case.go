@@ -0,0 +1,64 @@
+package services
+
+import (
+	"context"
+	"strings"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/common/textnorm"
+	"worker/internal/config"
+)
+
+// normalizeUsername normalizes a typed username like textnorm.Identifier and, with AUTH_USERNAME_CASE=lower,
+// lowercases it so usernames differing only in case name the same account
+func (s *AuthService) normalizeUsername(username string) string {
+	username = textnorm.Identifier(username)
+	if s.authConfig.UsernameCase == config.UsernameCaseLower {
+		return strings.ToLower(username)
+	}
+	return username
+}
+
+// normalizeLoginIdentifier normalizes an email-or-username identifier; only a username is lowercased,
+// since the local part of an email address may be case-sensitive
+func (s *AuthService) normalizeLoginIdentifier(identifier string) string {
+	if strings.Contains(identifier, "@") {
+		return textnorm.Identifier(identifier)
+	}
+	return s.normalizeUsername(identifier)
+}
+
+// usernameTaken reports whether a user of the tenant already holds username
+// With AUTH_USERNAME_CASE=lower the check ignores case, so names stored with capitals still collide
+func (s *AuthService) usernameTaken(ctx context.Context, tenantID, username string) (bool, error) {
+	if s.authConfig.UsernameCase == config.UsernameCaseLower {
+		return s.userRepo.ExistsByUsernameIgnoringCase(ctx, tenantID, username)
+	}
+	return s.userRepo.ExistsByUsername(ctx, tenantID, username)
+}
+
+// takenUsernames returns which of the normalized usernames are held by a user of the tenant
+func (s *AuthService) takenUsernames(ctx context.Context, tenantID string, usernames []string) ([]string, error) {
+	if s.authConfig.UsernameCase == config.UsernameCaseLower {
+		return s.userRepo.ExistingUsernamesIgnoringCase(ctx, tenantID, usernames)
+	}
+	return s.userRepo.ExistingUsernames(ctx, tenantID, usernames)
+}
+
+// findByUsername looks up a user of the tenant by username
+// With AUTH_USERNAME_CASE=lower the lookup ignores case, so accounts stored with capitals can still sign in
+func (s *AuthService) findByUsername(ctx context.Context, tenantID, username string) (*sqlc.GetUserByUsernameRow, error) {
+	if s.authConfig.UsernameCase == config.UsernameCaseLower {
+		return s.userRepo.FindByUsernameIgnoringCase(ctx, tenantID, username)
+	}
+	return s.userRepo.FindByUsername(ctx, tenantID, username)
+}
+
+// findByEmailOrUsername looks up a user of the tenant by email or username, ignoring the username's case
+// like findByUsername
+func (s *AuthService) findByEmailOrUsername(ctx context.Context, tenantID, identifier string) (*sqlc.GetUserByEmailOrUsernameRow, error) {
+	if s.authConfig.UsernameCase == config.UsernameCaseLower {
+		return s.userRepo.FindByEmailOrUsernameIgnoringCase(ctx, tenantID, identifier)
+	}
+	return s.userRepo.FindByEmailOrUsername(ctx, tenantID, identifier)
+}
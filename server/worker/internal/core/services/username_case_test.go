@@ -0,0 +1,87 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// registerAs registers username with its own email address, so only the username can collide
+func (s *testService) registerAs(username, email string) error {
+	_, err := s.Register(context.Background(), &domain.RegisterRequest{
+		Username: username,
+		Email:    email,
+		Password: testPassword,
+		FullName: "Test " + username,
+	})
+	return err
+}
+
+func TestUsernameCasePreserve(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.UsernameCase = config.UsernameCasePreserve
+	})
+
+	if err := s.registerAs("Bob", "bob1@example.com"); err != nil {
+		t.Fatalf("register Bob: %v", err)
+	}
+	// Names differing only in case are different accounts
+	if err := s.registerAs("bob", "bob2@example.com"); err != nil {
+		t.Fatalf("register bob next to Bob: %v", err)
+	}
+	assertCode(t, s.registerAs("Bob", "bob3@example.com"), domain.CodeUserAlreadyExists)
+
+	for _, username := range []string{"Bob", "bob"} {
+		resp := s.mustLogin(t, username)
+		if resp.User.Username != username {
+			t.Fatalf("login as %s signed in %s", username, resp.User.Username)
+		}
+	}
+	_, err := s.login("BOB")
+	assertCode(t, err, domain.CodeUserNotFound)
+}
+
+func TestUsernameCaseLower(t *testing.T) {
+	s := newTestService(t, func(cfg *config.Config) {
+		cfg.Auth.UsernameCase = config.UsernameCaseLower
+	})
+
+	if err := s.registerAs("Bob", "bob1@example.com"); err != nil {
+		t.Fatalf("register Bob: %v", err)
+	}
+	for _, username := range []string{"bob", "BOB", "Bob"} {
+		assertCode(t, s.registerAs(username, "other@example.com"), domain.CodeUserAlreadyExists)
+	}
+
+	for _, username := range []string{"bob", "BOB", "Bob"} {
+		resp := s.mustLogin(t, username)
+		if resp.User.Username != "bob" {
+			t.Fatalf("login as %s signed in %s, want the lowercased bob", username, resp.User.Username)
+		}
+	}
+}
+
+func TestUsernameCaseLowerFindsNamesStoredWithCapitals(t *testing.T) {
+	s := newTestService(t, nil)
+	if err := s.registerAs("Bob", "bob@example.com"); err != nil {
+		t.Fatalf("register Bob: %v", err)
+	}
+
+	// Switching the mode on an existing deployment leaves "Bob" stored as typed
+	s.authConfig.UsernameCase = config.UsernameCaseLower
+
+	assertCode(t, s.registerAs("bob", "other@example.com"), domain.CodeUserAlreadyExists)
+	for _, username := range []string{"Bob", "bob", "BOB"} {
+		resp := s.mustLogin(t, username)
+		if resp.User.Username != "Bob" {
+			t.Fatalf("login as %s signed in %s, want Bob", username, resp.User.Username)
+		}
+	}
+
+	s.authConfig.LoginIdentifier = config.LoginIdentifierUsername
+	if _, err := s.login("bob"); err != nil {
+		t.Fatalf("username-only login as bob: %v", err)
+	}
+}
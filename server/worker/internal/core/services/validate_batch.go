@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"worker/internal/core/domain"
+)
+
+// maxValidateBatchSize caps how many tokens one ValidateAccessTokens call may carry
+const maxValidateBatchSize = 100
+
+// ValidateAccessTokens validates several access tokens at once, for gateways fronting many requests
+// Tokens failing verification are settled without a database call; the users of the others are
+// checked and their permissions resolved with one query each, however many tokens there are
+// Permissions embedded in a token (AUTH_EMBED_PERMISSIONS) are used as they are
+// A failed lookup fails the whole batch, as it would fail ValidateAccessToken
+func (s *AuthService) ValidateAccessTokens(ctx context.Context, tokens []string) ([]domain.TokenValidation, error) {
+	if len(tokens) > maxValidateBatchSize {
+		return nil, domain.NewFieldError(
+			domain.ErrBatchTooLarge,
+			"access_tokens",
+			fmt.Sprintf("at most %d tokens can be validated at once", maxValidateBatchSize),
+		)
+	}
+
+	// Step 1: Verify every token on its own and collect the users of the valid ones
+	results := make([]domain.TokenValidation, len(tokens))
+	claims := make([]*AccessTokenClaims, len(tokens))
+	subjects := make([]uuid.UUID, len(tokens))
+	var userIDs []uuid.UUID
+	seen := make(map[uuid.UUID]bool)
+	needsPermissions := make(map[uuid.UUID]bool)
+	for i, token := range tokens {
+		c, err := s.parseAccessToken(ctx, token)
+		if err != nil {
+			results[i].Err = err
+			continue
+		}
+		userID, err := s.subjectUserID(c.Subject)
+		if err != nil {
+			// Like ValidateAccessToken, a subject that is not a user ID is valid without user info
+			results[i].Result = &domain.ValidateTokenResult{
+				Valid:       true,
+				UserID:      c.Subject,
+				Permissions: []string{},
+				Claims:      s.claimsEnricher.Surface(ctx, c.Custom),
+				Role:        c.Role,
+				ExpiresAt:   c.expiry(),
+			}
+			continue
+		}
+		claims[i], subjects[i] = c, userID
+		if c.Permissions == nil {
+			needsPermissions[userID] = true
+		}
+		if !seen[userID] {
+			seen[userID] = true
+			userIDs = append(userIDs, userID)
+		}
+	}
+	if len(userIDs) == 0 {
+		return results, nil
+	}
+
+	// Step 2: Fetch the email and current token version of every user
+	rows, err := s.userRepo.GetTokenSubjects(ctx, userIDs)
+	if err != nil {
+		return nil, databaseError(err, "failed to fetch users")
+	}
+	emails := make(map[uuid.UUID]string, len(rows))
+	versions := make(map[uuid.UUID]int32, len(rows))
+	for _, row := range rows {
+		emails[row.ID] = row.Email
+		versions[row.ID] = row.TokenVersion
+		s.tokenVersions.Set(ctx, row.ID, row.TokenVersion)
+	}
+
+	// Step 3: Resolve the permissions of the users whose tokens carry none, those not cached in one query
+	permissions := make(map[uuid.UUID][]string, len(rows))
+	var uncached []uuid.UUID
+	for _, row := range rows {
+		if !needsPermissions[row.ID] {
+			continue
+		}
+		if cached, ok := s.permissionCache.Get(ctx, row.ID); ok {
+			permissions[row.ID] = cached
+		} else {
+			uncached = append(uncached, row.ID)
+		}
+	}
+	if len(uncached) > 0 {
+		fetched, err := s.roleRepo.GetPermissionsByUserIDs(ctx, uncached)
+		if err != nil {
+			if err := s.permissionLookupFailed(ctx, zap.Stringers("user_ids", uncached), err); err != nil {
+				return nil, err
+			}
+		}
+		for userID, userPermissions := range fetched {
+			s.permissionCache.Set(ctx, userID, userPermissions)
+			permissions[userID] = userPermissions
+		}
+	}
+
+	// Step 4: Apply the token version check and fill in the results of the valid tokens
+	for i, c := range claims {
+		if c == nil {
+			continue
+		}
+		userID := subjects[i]
+		version, ok := versions[userID]
+		if !ok {
+			results[i].Err = mapUserLookupError(domain.ErrUserNotFound)
+			continue
+		}
+		if c.Version != version {
+			results[i].Err = domain.NewAuthError(
+				domain.ErrSessionRevoked,
+				"token has been revoked",
+				domain.CodeSessionRevoked,
+			)
+			continue
+		}
+		s.markSessionSeen(ctx, c)
+		userPermissions, ok := c.Permissions, c.Permissions != nil
+		if !ok {
+			userPermissions, ok = permissions[userID]
+		}
+		if !ok {
+			userPermissions = []string{} // AUTH_PERMISSIONS_FAIL_MODE=open
+		}
+		results[i].Result = &domain.ValidateTokenResult{
+			Valid:       true,
+			UserID:      userID.String(),
+			Email:       emails[userID],
+			Permissions: userPermissions,
+			Claims:      s.claimsEnricher.Surface(ctx, c.Custom),
+			Role:        c.Role,
+			ExpiresAt:   c.expiry(),
+		}
+	}
+	return results, nil
+}
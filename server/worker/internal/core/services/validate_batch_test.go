@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"worker/internal/adapter/storage/postgres/sqlc"
+	"worker/internal/core/domain"
+	"worker/internal/core/ports"
+)
+
+// batchUsers counts the token subject lookups of a user repository
+type batchUsers struct {
+	ports.UserRepository
+	lookups int
+}
+
+func (r *batchUsers) GetTokenSubjects(ctx context.Context, userIDs []uuid.UUID) ([]sqlc.GetTokenSubjectsByIDsRow, error) {
+	r.lookups++
+	return r.UserRepository.GetTokenSubjects(ctx, userIDs)
+}
+
+// batchRoles counts the permission lookups by user of a role repository
+type batchRoles struct {
+	ports.RoleRepository
+	lookups int
+}
+
+func (r *batchRoles) GetPermissionsByUserIDs(ctx context.Context, userIDs []uuid.UUID) (map[uuid.UUID][]string, error) {
+	r.lookups++
+	return r.RoleRepository.GetPermissionsByUserIDs(ctx, userIDs)
+}
+
+// countBatchLookups makes s count its batched user and permission lookups
+func (s *testService) countBatchLookups() (*batchUsers, *batchRoles) {
+	users, roles := &batchUsers{UserRepository: s.userRepo}, &batchRoles{RoleRepository: s.roleRepo}
+	s.userRepo, s.roleRepo = users, roles
+	return users, roles
+}
+
+func TestValidateAccessTokensMixedBatch(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	adminID := s.registerAdmin(t)
+	expired := s.register(t, "carol").AccessToken
+	s.clock.Advance(s.cfg.JWT.AccessExpiration + time.Second)
+	adminToken := s.mustLogin(t, "admin").AccessToken
+	alice := s.register(t, "alice")
+	bob := s.register(t, "bob")
+	dave := s.register(t, "dave")
+	if err := s.RevokeAllUserTokens(ctx, adminID, dave.User.ID); err != nil {
+		t.Fatalf("revoke dave's tokens: %v", err)
+	}
+	users, roles := s.countBatchLookups()
+
+	tokens := []string{alice.AccessToken, "not-a-token", expired, bob.AccessToken, dave.AccessToken, alice.AccessToken, adminToken}
+	results, err := s.ValidateAccessTokens(ctx, tokens)
+	if err != nil {
+		t.Fatalf("validate batch: %v", err)
+	}
+	if len(results) != len(tokens) {
+		t.Fatalf("got %d results for %d tokens", len(results), len(tokens))
+	}
+
+	// Results come back in request order
+	for i, want := range map[int]string{0: "alice@example.com", 3: "bob@example.com", 5: "alice@example.com", 6: "admin@example.com"} {
+		result := results[i]
+		if result.Err != nil || result.Result == nil || !result.Result.Valid || result.Result.Email != want {
+			t.Fatalf("result %d = %+v, want %s valid", i, result, want)
+		}
+	}
+	assertCode(t, results[1].Err, domain.CodeInvalidToken)
+	assertCode(t, results[2].Err, domain.CodeTokenExpired)
+	assertCode(t, results[4].Err, domain.CodeSessionRevoked)
+	if !slices.Contains(results[6].Result.Permissions, domain.PermissionUsersRead) {
+		t.Fatalf("admin permissions = %v, want %s among them", results[6].Result.Permissions, domain.PermissionUsersRead)
+	}
+
+	// The users of every valid token are looked up together, whatever their number
+	if users.lookups != 1 || roles.lookups > 1 {
+		t.Fatalf("lookups = %d users, %d permissions; want one of each at most", users.lookups, roles.lookups)
+	}
+
+	// The batch agrees with validating each token on its own
+	for i, token := range tokens {
+		single, err := s.ValidateAccessToken(ctx, token)
+		if (err == nil) != (results[i].Err == nil) {
+			t.Fatalf("token %d: single validation error %v, batch error %v", i, err, results[i].Err)
+		}
+		if err == nil && (single.UserID != results[i].Result.UserID || !slices.Equal(single.Permissions, results[i].Result.Permissions)) {
+			t.Fatalf("token %d: single validation %+v, batch %+v", i, single, results[i].Result)
+		}
+	}
+}
+
+func TestValidateAccessTokensInvalidOnly(t *testing.T) {
+	s := newTestService(t, nil)
+	expired := s.register(t, "alice").AccessToken
+	s.clock.Advance(s.cfg.JWT.AccessExpiration + time.Second)
+	users, roles := s.countBatchLookups()
+
+	// Tokens failing verification are settled without touching the database
+	results, err := s.ValidateAccessTokens(context.Background(), []string{"", "not-a-token", expired})
+	if err != nil {
+		t.Fatalf("validate batch: %v", err)
+	}
+	for i, result := range results {
+		if result.Err == nil || result.Result != nil {
+			t.Fatalf("result %d = %+v, want an error", i, result)
+		}
+	}
+	if users.lookups != 0 || roles.lookups != 0 {
+		t.Fatalf("lookups = %d users, %d permissions; want none", users.lookups, roles.lookups)
+	}
+}
+
+func TestValidateAccessTokensUsesPermissionCache(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	alice := s.register(t, "alice")
+	if _, err := s.ValidateAccessTokens(ctx, []string{alice.AccessToken}); err != nil {
+		t.Fatalf("validate batch: %v", err)
+	}
+	_, roles := s.countBatchLookups()
+
+	if _, err := s.ValidateAccessTokens(ctx, []string{alice.AccessToken}); err != nil {
+		t.Fatalf("validate batch again: %v", err)
+	}
+	if roles.lookups != 0 {
+		t.Fatalf("permission lookups = %d with alice's permissions cached, want none", roles.lookups)
+	}
+}
+
+func TestValidateAccessTokensBatchSize(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	token := s.register(t, "alice").AccessToken
+
+	results, err := s.ValidateAccessTokens(ctx, nil)
+	if err != nil || len(results) != 0 {
+		t.Fatalf("empty batch = %v, %v; want no results", results, err)
+	}
+	if _, err := s.ValidateAccessTokens(ctx, slices.Repeat([]string{token}, maxValidateBatchSize)); err != nil {
+		t.Fatalf("validate %d tokens: %v", maxValidateBatchSize, err)
+	}
+
+	_, err = s.ValidateAccessTokens(ctx, slices.Repeat([]string{token}, maxValidateBatchSize+1))
+	assertCode(t, err, domain.CodeInvalidArgument)
+	var authErr *domain.AuthError
+	if !errors.As(err, &authErr) || authErr.Field != "access_tokens" || !errors.Is(err, domain.ErrBatchTooLarge) {
+		t.Fatalf("error = %v, want access_tokens rejected as too large a batch", err)
+	}
+}
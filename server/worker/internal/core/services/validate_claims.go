@@ -0,0 +1,47 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"worker/internal/core/domain"
+)
+
+// ValidateAccessTokenClaims validates an access token from its verified claims alone, for gateways that only
+// need to know a token is genuine and unexpired; the user is not looked up
+// The tradeoff: a token revoked by a token version bump (logout everywhere, password change, deactivation) or
+// whose user's role changed keeps validating until it expires, and the result carries no email
+// Permissions are only reported when embedded in the token (AUTH_EMBED_PERMISSIONS)
+// Opaque tokens (AUTH_TOKEN_TYPE=opaque) are still looked up in the token store, since they carry no claims
+func (s *AuthService) ValidateAccessTokenClaims(ctx context.Context, tokenString string) (*domain.ValidateTokenResult, error) {
+	claims, err := s.parseAccessToken(ctx, tokenString)
+	if err != nil {
+		return nil, err
+	}
+
+	// Like ValidateAccessToken, a subject that is not a user ID is reported as it is
+	subject := claims.Subject
+	if userID, err := s.subjectUserID(claims.Subject); err == nil {
+		subject = userID.String()
+	}
+	permissions := claims.Permissions
+	if permissions == nil {
+		permissions = []string{}
+	}
+	return &domain.ValidateTokenResult{
+		Valid:       true,
+		UserID:      subject,
+		Permissions: permissions,
+		Claims:      s.claimsEnricher.Surface(ctx, claims.Custom),
+		Role:        claims.Role,
+		ExpiresAt:   claims.expiry(),
+	}, nil
+}
+
+// expiry returns when the token expires, zero when it has no expiry
+func (c *AccessTokenClaims) expiry() time.Time {
+	if c.ExpiresAt == nil {
+		return time.Time{}
+	}
+	return c.ExpiresAt.Time
+}
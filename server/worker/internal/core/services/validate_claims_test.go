@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"worker/internal/core/domain"
+)
+
+func TestValidateAccessTokenClaims(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	full, err := s.ValidateAccessToken(ctx, resp.AccessToken)
+	if err != nil {
+		t.Fatalf("full validation: %v", err)
+	}
+	light, err := s.ValidateAccessTokenClaims(ctx, resp.AccessToken)
+	if err != nil {
+		t.Fatalf("claims-only validation: %v", err)
+	}
+
+	if !light.Valid || light.UserID != full.UserID || light.Role != full.Role || !light.ExpiresAt.Equal(full.ExpiresAt) {
+		t.Fatalf("claims-only result %+v does not match the full result %+v", light, full)
+	}
+	if light.Role == "" || light.ExpiresAt.IsZero() {
+		t.Fatalf("claims-only result %+v lacks the role or expiry", light)
+	}
+	if full.Email == "" || light.Email != "" {
+		t.Fatalf("email: full %q, claims-only %q; want it only from the full validation", full.Email, light.Email)
+	}
+}
+
+// The documented tradeoff: revocations after issue go unnoticed without the user lookup
+func TestValidateAccessTokenClaimsMissesRevocation(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	if err := s.revokeAccessTokens(ctx, resp.User.ID); err != nil {
+		t.Fatalf("revoke access tokens: %v", err)
+	}
+
+	_, err := s.ValidateAccessToken(ctx, resp.AccessToken)
+	assertCode(t, err, domain.CodeSessionRevoked)
+	if _, err := s.ValidateAccessTokenClaims(ctx, resp.AccessToken); err != nil {
+		t.Fatalf("claims-only validation of a revoked token: %v, want it still valid", err)
+	}
+}
+
+func TestValidateAccessTokenClaimsRejectsExpiredTokens(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+	resp := s.register(t, "alice")
+
+	s.clock.Set(resp.AccessTokenExpiresAt.Add(time.Second))
+	_, err := s.ValidateAccessTokenClaims(ctx, resp.AccessToken)
+	assertCode(t, err, domain.CodeTokenExpired)
+	_, err = s.ValidateAccessTokenClaims(ctx, resp.AccessToken+"x")
+	assertCode(t, err, domain.CodeInvalidToken)
+}
+
+// The full path's lookups are answered by the in-memory storage and the token version and permission
+// caches without a round trip, so against PostgreSQL the gap between the two is wider than measured here
+func BenchmarkValidateAccessToken(b *testing.B) {
+	benchmarkValidate(b, (*AuthService).ValidateAccessToken)
+}
+
+func BenchmarkValidateAccessTokenClaims(b *testing.B) {
+	benchmarkValidate(b, (*AuthService).ValidateAccessTokenClaims)
+}
+
+func benchmarkValidate(b *testing.B, validate func(*AuthService, context.Context, string) (*domain.ValidateTokenResult, error)) {
+	s := newTestService(b, nil)
+	ctx := context.Background()
+	token := s.register(b, "alice").AccessToken
+
+	b.ReportAllocs()
+	for b.Loop() {
+		if _, err := validate(s.AuthService, ctx, token); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"worker/internal/config"
+	"worker/internal/core/domain"
+)
+
+// assertWrongTokenType fails unless err reports a refresh token sent as an access token
+func assertWrongTokenType(t *testing.T, err error) {
+	t.Helper()
+	assertCode(t, err, domain.CodeWrongTokenType)
+	if !errors.Is(err, domain.ErrWrongTokenType) {
+		t.Fatalf("error = %v, want ErrWrongTokenType", err)
+	}
+}
+
+func TestRefreshTokenSentAsAccessToken(t *testing.T) {
+	for _, tokenType := range []string{config.TokenTypeJWT, config.TokenTypeOpaque} {
+		t.Run(tokenType, func(t *testing.T) {
+			s := newTokenTypeTestService(t, tokenType)
+			ctx := context.Background()
+			alice := s.register(t, "alice")
+
+			_, err := s.ValidateAccessToken(ctx, alice.RefreshToken)
+			assertWrongTokenType(t, err)
+			_, err = s.ValidateAccessTokenClaims(ctx, alice.RefreshToken)
+			assertWrongTokenType(t, err)
+			_, err = s.GetMyPermissions(ctx, alice.RefreshToken)
+			assertWrongTokenType(t, err)
+
+			// A batch reports the mix-up for that token only
+			batch, err := s.ValidateAccessTokens(ctx, []string{alice.AccessToken, alice.RefreshToken})
+			if err != nil {
+				t.Fatalf("validate batch: %v", err)
+			}
+			if batch[0].Err != nil {
+				t.Fatalf("access token in the batch: %v", batch[0].Err)
+			}
+			assertWrongTokenType(t, batch[1].Err)
+		})
+	}
+}
+
+func TestExpiredRefreshTokenSentAsAccessToken(t *testing.T) {
+	s := newTestService(t, nil)
+	alice := s.register(t, "alice")
+	s.clock.Advance(s.cfg.JWT.RefreshExpiration + s.cfg.JWT.AccessExpiration)
+
+	// Still recognizably a refresh token, whether or not it could still be used
+	_, err := s.ValidateAccessToken(context.Background(), alice.RefreshToken)
+	assertWrongTokenType(t, err)
+}
+
+func TestOtherInvalidTokensAreNotWrongType(t *testing.T) {
+	s := newTestService(t, nil)
+	alice := s.register(t, "alice")
+	// A refresh token of another worker, i.e. signed with another refresh secret
+	other := s.withRefreshSecret(t, "other-refresh-secret")
+	foreign := other.mustLogin(t, "alice").RefreshToken
+
+	for name, token := range map[string]string{
+		"garbage":                "not-a-token",
+		"foreign refresh token":  foreign,
+		"tampered refresh token": alice.RefreshToken[:len(alice.RefreshToken)-2] + "xx",
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := s.ValidateAccessToken(context.Background(), token)
+			if errors.Is(err, domain.ErrWrongTokenType) {
+				t.Fatalf("validate = %v, want an invalid token rather than the wrong type", err)
+			}
+			assertCode(t, err, domain.CodeInvalidToken)
+		})
+	}
+}
+
+// withRefreshSecret wires a service over the same store and clock with another JWT_REFRESH_SECRET
+func (s *testService) withRefreshSecret(t *testing.T, secret string) *testService {
+	t.Helper()
+	cfg := *s.cfg
+	cfg.JWT.RefreshSecret = secret
+	return wireTestService(t, &cfg, s.store, s.clock)
+}